@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"vibe-tracker/constants"
+	"vibe-tracker/utils"
 )
 
 // AppConfig holds all application configuration
@@ -17,6 +18,17 @@ type AppConfig struct {
 
 	// Database configuration
 	Automigrate bool
+	SQLite      utils.SQLitePragmas
+
+	// Read-only mode rejects all writes, e.g. during deploys and restores
+	ReadOnly        bool
+	ReadOnlyMessage string
+
+	// Serve the GraphQL facade of the read API
+	GraphQLEnabled bool
+
+	// Delete the orphaned files found by the daily file storage scan instead of only reporting them
+	DeleteOrphanedFiles bool
 
 	// Pagination settings
 	DefaultPage    int
@@ -28,6 +40,24 @@ type AppConfig struct {
 
 	// Health check configuration
 	Health HealthConfig
+
+	// Location tracking configuration
+	Tracking TrackingConfig
+
+	// Error reporting configuration
+	ErrorReporting ErrorReportingConfig
+
+	// Log format, outputs and levels
+	Logging utils.LogOptions
+
+	// Latency budget monitoring
+	Monitoring MonitoringConfig
+
+	// Home Assistant MQTT discovery publisher
+	HomeAssistant HomeAssistantConfig
+
+	// Instance name, logo and map defaults shown by clients
+	Branding BrandingConfig
 }
 
 // SecurityConfig holds security-related configuration
@@ -46,6 +76,22 @@ type SecurityConfig struct {
 	FailedLoginThreshold       int
 	AccountLockoutDuration     time.Duration
 
+	// Login anomaly detection
+	EnableLoginAnomalyDetection bool
+	GeoIPLookupURL              string        // Lookup URL with an {ip} placeholder; empty disables geo-IP lookups
+	SuspiciousLoginReauthWindow time.Duration // Re-authentication window for sensitive actions after a suspicious login (0 disables)
+
+	// CAPTCHA on registration, password reset and repeated failed logins
+	CaptchaProvider      string // hcaptcha, turnstile, pow or empty to disable
+	CaptchaSiteKey       string
+	CaptchaSecret        string
+	CaptchaAfterFailures int // Failed logins from a client before a CAPTCHA is required
+	PoWDifficulty        int // Leading zero bits required by proof-of-work solutions
+
+	// Virus scanning of uploaded files with ClamAV
+	ClamAVAddress  string // clamd socket, e.g. unix:/run/clamav/clamd.ctl or tcp:127.0.0.1:3310; empty disables scanning
+	ClamAVFailOpen bool   // Accept uploads when clamd cannot be reached instead of rejecting them
+
 	// CORS configuration
 	CORSAllowedOrigins []string
 	CORSAllowAll       bool
@@ -79,19 +125,155 @@ type HealthConfig struct {
 	AllowedIPs []string
 }
 
+// MonitoringConfig holds the latency budgets routes are monitored against
+type MonitoringConfig struct {
+	LatencyBudget       time.Duration // p95 budget of every route (0 disables monitoring of routes without their own budget)
+	LatencyRouteBudgets string        // Comma-separated "METHOD /route=duration" overrides
+	LatencyAlertAfter   time.Duration // How long a route must stay over budget before an alert
+}
+
+// HomeAssistantConfig holds the settings of publishing users' latest locations to Home Assistant
+// through MQTT discovery
+type HomeAssistantConfig struct {
+	MQTTURL         string   // mqtt:// or mqtts:// broker URL with optional credentials; empty disables publishing
+	Users           []string // Usernames whose locations are published
+	DiscoveryPrefix string   // Discovery topic prefix Home Assistant subscribes to
+	TopicPrefix     string   // Prefix of the state and availability topics
+}
+
+// TrackingConfig holds location tracking configuration
+type TrackingConfig struct {
+	// Waypoint check-off radius in meters (0 disables automatic check-off)
+	WaypointVisitRadius float64
+
+	// Accepted age and future skew of explicit point timestamps (0 disables the respective check)
+	MaxTimestampAge  time.Duration
+	MaxTimestampSkew time.Duration
+
+	// Time and distance between consecutive points that start a new track segment (0 disables the respective check)
+	GapMaxInterval time.Duration
+	GapMaxDistance float64
+
+	// Whether points repeating a stored point of their session are skipped, and how far apart in
+	// time and meters they may be (0 matches identical timestamps and coordinates only)
+	Deduplicate       bool
+	DuplicateInterval time.Duration
+	DuplicateDistance float64
+
+	// Speed in km/h above which a point is too far from the previous point of its session to be
+	// real (0 disables the outlier filter)
+	OutlierMaxSpeed float64
+
+	// Whether points without speed or bearing get those of the travel from the previous point
+	DeriveMotion bool
+
+	// Simplification of planned tracks imported from GPX, uploads can override it
+	Simplification utils.SimplificationOptions
+
+	// Inactivity after which open sessions are ended (0 disables automatic ending)
+	SessionInactivityTimeout time.Duration
+
+	// Battery percentage below which owners of live trackers are alerted (0 disables alerts)
+	LowBatteryThreshold float64
+
+	// Reverse geocoding lookup URL with {lat} and {lon} placeholders; empty disables country statistics
+	ReverseGeocodeURL string
+
+	// Map matching backend (valhalla or osrm) snapping tracks to OSM ways, its base URL and costing
+	// model or profile; an empty URL disables map matching. Surface statistics require Valhalla.
+	MapMatchProvider string
+	MapMatchURL      string
+	MapMatchCosting  string
+
+	// Routing engine (valhalla or osrm) for route planning and its base URL, and the base URL of a
+	// Valhalla instance providing elevations; empty URLs disable planning and elevation respectively
+	RoutingProvider string
+	RoutingURL      string
+	ElevationURL    string
+
+	// Open-Meteo compatible forecast URL for weather along planned routes; empty disables forecasts
+	WeatherURL string
+
+	// Endpoints notified about tracking events such as ended sessions, and the optional signing secret
+	WebhookURLs   []string
+	WebhookSecret string
+
+	// Write batching of tracked points (off, sync or async); a batch is written when it reaches the
+	// batch size or the interval has passed. The queue holds at most IngestQueueSize points, which
+	// IngestWorkers workers write.
+	IngestMode          string
+	IngestBatchInterval time.Duration
+	IngestBatchSize     int
+	IngestQueueSize     int
+	IngestWorkers       int
+
+	// Listen address of the gRPC ingestion service (empty disables it) and the optional TLS
+	// certificate and key files; without them the service accepts plaintext connections
+	GRPCAddress string
+	GRPCTLSCert string
+	GRPCTLSKey  string
+
+	// Listen addresses of the hardware tracker protocols by protocol name, see package protocols
+	TrackerAddresses map[string]string
+
+	// APRS-IS server and login, and the followed callsigns as "CALLSIGN=username/session"; no
+	// followed callsigns disables the APRS bridge
+	APRSServer string
+	APRSLogin  string
+	APRSFollow []string
+
+	// How often the MapShare feeds of inReach devices are polled; zero disables the import
+	MapSharePollInterval time.Duration
+}
+
+// BrandingConfig holds the instance branding and the map defaults of clients
+type BrandingConfig struct {
+	InstanceName string
+	LogoURL      string
+
+	// Initial map view before anything is loaded
+	MapCenterLat float64
+	MapCenterLon float64
+	MapZoom      int
+
+	// Raster tile layer as a Leaflet URL template, e.g. with {s}, {z}, {x} and {y}
+	TileURL         string
+	TileAttribution string
+	TileMaxZoom     int
+}
+
+// ErrorReportingConfig holds the settings of reporting incidents to Sentry or GlitchTip
+type ErrorReportingConfig struct {
+	SentryDSN   string // Empty disables error reporting
+	Environment string // Environment name reports are tagged with
+}
+
 // NewAppConfig creates a new configuration instance with values from environment variables
 func NewAppConfig() *AppConfig {
 	isProd := isProductionMode()
 
 	return &AppConfig{
-		Port:           getEnvOrDefault(constants.EnvPort, constants.DefaultPort),
-		Host:           getEnvOrDefault(constants.EnvHost, constants.DefaultHost),
-		Automigrate:    getBoolEnvOrDefault(constants.EnvAutomigrate, true),
+		Port:            getEnvOrDefault(constants.EnvPort, constants.DefaultPort),
+		Host:            getEnvOrDefault(constants.EnvHost, constants.DefaultHost),
+		Automigrate:     getBoolEnvOrDefault(constants.EnvAutomigrate, true),
+		SQLite:          newSQLiteConfig(),
+		ReadOnly:        getBoolEnvOrDefault(constants.EnvReadOnly, false),
+		ReadOnlyMessage: getEnvOrDefault(constants.EnvReadOnlyMessage, constants.DefaultReadOnlyMessage),
+		GraphQLEnabled:  getBoolEnvOrDefault(constants.EnvGraphQLEnabled, false),
+
+		DeleteOrphanedFiles: getBoolEnvOrDefault(constants.EnvDeleteOrphanedFiles, false),
+
 		DefaultPage:    constants.DefaultPage,
 		DefaultPerPage: constants.DefaultPerPage,
 		MaxPerPage:     constants.MaxPerPageLimit,
 		Security:       newSecurityConfig(isProd),
 		Health:         newHealthConfig(isProd),
+		Tracking:       newTrackingConfig(),
+		ErrorReporting: newErrorReportingConfig(isProd),
+		Logging:        newLoggingConfig(isProd),
+		Monitoring:     newMonitoringConfig(),
+		HomeAssistant:  newHomeAssistantConfig(),
+		Branding:       newBrandingConfig(),
 	}
 }
 
@@ -119,6 +301,19 @@ func newSecurityConfig(isProduction bool) SecurityConfig {
 		FailedLoginThreshold:       getIntEnvOrDefault("FAILED_LOGIN_THRESHOLD", constants.MaxFailedLoginAttempts),
 		AccountLockoutDuration:     getDurationEnvOrDefault("ACCOUNT_LOCKOUT_DURATION", constants.LoginLockoutDuration),
 
+		EnableLoginAnomalyDetection: getBoolEnvOrDefault("ENABLE_LOGIN_ANOMALY_DETECTION", true),
+		GeoIPLookupURL:              getEnvOrDefault("GEOIP_LOOKUP_URL", ""),
+		SuspiciousLoginReauthWindow: getDurationEnvOrDefault("SUSPICIOUS_LOGIN_REAUTH_WINDOW", 0),
+
+		CaptchaProvider:      strings.ToLower(getEnvOrDefault("CAPTCHA_PROVIDER", "")),
+		CaptchaSiteKey:       getEnvOrDefault("CAPTCHA_SITE_KEY", ""),
+		CaptchaSecret:        getEnvOrDefault("CAPTCHA_SECRET", ""),
+		CaptchaAfterFailures: getIntEnvOrDefault("CAPTCHA_AFTER_FAILED_LOGINS", constants.DefaultCaptchaFailures),
+		PoWDifficulty:        getIntEnvOrDefault("POW_DIFFICULTY", constants.DefaultPoWDifficulty),
+
+		ClamAVAddress:  getEnvOrDefault("CLAMAV_ADDRESS", ""),
+		ClamAVFailOpen: getBoolEnvOrDefault("CLAMAV_FAIL_OPEN", false),
+
 		CORSAllowedOrigins: corsOrigins,
 		CORSAllowAll:       getBoolEnvOrDefault("CORS_ALLOW_ALL", !isProduction),
 
@@ -154,6 +349,183 @@ func newHealthConfig(isProduction bool) HealthConfig {
 	}
 }
 
+// newTrackingConfig creates location tracking configuration
+func newTrackingConfig() TrackingConfig {
+	webhookURLs := []string{}
+	if urlsEnv := os.Getenv(constants.EnvWebhookURLs); urlsEnv != "" {
+		webhookURLs = strings.Split(urlsEnv, ",")
+	}
+
+	mapMatchProvider := getEnvOrDefault(constants.EnvMapMatchProvider, constants.DefaultMapMatchProvider)
+	defaultCosting := constants.DefaultMapMatchCosting
+	if mapMatchProvider == constants.MapMatchProviderOSRM {
+		defaultCosting = constants.DefaultOSRMProfile
+	}
+
+	routingProvider := getEnvOrDefault(constants.EnvRoutingProvider, constants.DefaultRoutingProvider)
+	routingURL := strings.TrimSuffix(getEnvOrDefault(constants.EnvRoutingURL, ""), "/")
+	elevationURL := ""
+	if routingProvider == constants.RoutingProviderValhalla {
+		elevationURL = routingURL
+	}
+
+	trackerAddresses := map[string]string{}
+	for protocol, env := range map[string]string{
+		constants.TrackerProtocolGT06: constants.EnvTrackerGT06Address,
+		constants.TrackerProtocolH02:  constants.EnvTrackerH02Address,
+	} {
+		if address := os.Getenv(env); address != "" {
+			trackerAddresses[protocol] = address
+		}
+	}
+
+	aprsFollow := []string{}
+	if followEnv := os.Getenv(constants.EnvAPRSFollow); followEnv != "" {
+		aprsFollow = strings.Split(followEnv, ",")
+	}
+
+	return TrackingConfig{
+		WaypointVisitRadius: getFloatEnvOrDefault(constants.EnvWaypointVisitRadius, constants.DefaultWaypointVisitRadius),
+		MaxTimestampAge:     getDurationEnvOrDefault(constants.EnvMaxTimestampAge, constants.DefaultMaxTimestampAge),
+		MaxTimestampSkew:    getDurationEnvOrDefault(constants.EnvMaxTimestampSkew, constants.DefaultMaxTimestampSkew),
+		GapMaxInterval:      getDurationEnvOrDefault(constants.EnvGapMaxInterval, constants.DefaultGapMaxInterval),
+		GapMaxDistance:      getFloatEnvOrDefault(constants.EnvGapMaxDistance, constants.DefaultGapMaxDistance),
+
+		Deduplicate:       getBoolEnvOrDefault(constants.EnvDeduplicate, constants.DefaultDeduplicate),
+		DuplicateInterval: getDurationEnvOrDefault(constants.EnvDuplicateInterval, constants.DefaultDuplicateInterval),
+		DuplicateDistance: getFloatEnvOrDefault(constants.EnvDuplicateDistance, constants.DefaultDuplicateDistance),
+		OutlierMaxSpeed:   getFloatEnvOrDefault(constants.EnvOutlierMaxSpeed, constants.DefaultOutlierMaxSpeed),
+		DeriveMotion:      getBoolEnvOrDefault(constants.EnvDeriveMotion, constants.DefaultDeriveMotion),
+
+		Simplification: utils.SimplificationOptions{
+			Enabled:           getBoolEnvOrDefault(constants.EnvSimplifyTracks, constants.DefaultSimplifyTracks),
+			MinPoints:         getIntEnvOrDefault(constants.EnvSimplifyMinPoints, constants.DefaultSimplifyMinPoints),
+			MaxPoints:         getIntEnvOrDefault(constants.EnvSimplifyMaxPoints, constants.DefaultSimplifyMaxPoints),
+			EpsilonMultiplier: getFloatEnvOrDefault(constants.EnvSimplifyEpsilonMultiplier, constants.DefaultSimplifyEpsilonMultiplier),
+		},
+
+		SessionInactivityTimeout: getDurationEnvOrDefault(constants.EnvSessionInactivity, constants.DefaultSessionInactivityTimeout),
+		LowBatteryThreshold:      getFloatEnvOrDefault(constants.EnvLowBatteryThreshold, constants.DefaultLowBatteryThreshold),
+		ReverseGeocodeURL:        getEnvOrDefault(constants.EnvReverseGeocodeURL, ""),
+		MapMatchProvider:         mapMatchProvider,
+		MapMatchURL:              strings.TrimSuffix(getEnvOrDefault(constants.EnvMapMatchURL, ""), "/"),
+		MapMatchCosting:          getEnvOrDefault(constants.EnvMapMatchCosting, defaultCosting),
+		RoutingProvider:          routingProvider,
+		RoutingURL:               routingURL,
+		ElevationURL:             strings.TrimSuffix(getEnvOrDefault(constants.EnvElevationURL, elevationURL), "/"),
+		WeatherURL:               getEnvOrDefault(constants.EnvWeatherURL, ""),
+
+		WebhookURLs:   webhookURLs,
+		WebhookSecret: getEnvOrDefault(constants.EnvWebhookSecret, ""),
+
+		IngestMode:          strings.ToLower(getEnvOrDefault(constants.EnvIngestMode, constants.DefaultIngestMode)),
+		IngestBatchInterval: getDurationEnvOrDefault(constants.EnvIngestBatchInterval, constants.DefaultIngestBatchInterval),
+		IngestBatchSize:     getIntEnvOrDefault(constants.EnvIngestBatchSize, constants.DefaultIngestBatchSize),
+		IngestQueueSize:     getIntEnvOrDefault(constants.EnvIngestQueueSize, constants.DefaultIngestQueueSize),
+		IngestWorkers:       getIntEnvOrDefault(constants.EnvIngestWorkers, constants.DefaultIngestWorkers),
+
+		GRPCAddress: getEnvOrDefault(constants.EnvGRPCAddress, ""),
+		GRPCTLSCert: getEnvOrDefault(constants.EnvGRPCTLSCert, ""),
+		GRPCTLSKey:  getEnvOrDefault(constants.EnvGRPCTLSKey, ""),
+
+		TrackerAddresses: trackerAddresses,
+
+		APRSServer: getEnvOrDefault(constants.EnvAPRSServer, constants.DefaultAPRSServer),
+		APRSLogin:  getEnvOrDefault(constants.EnvAPRSLogin, constants.DefaultAPRSLogin),
+		APRSFollow: aprsFollow,
+
+		MapSharePollInterval: getDurationEnvOrDefault(constants.EnvMapSharePollInterval, constants.DefaultMapSharePollInterval),
+	}
+}
+
+// newErrorReportingConfig creates error reporting configuration
+func newErrorReportingConfig(isProduction bool) ErrorReportingConfig {
+	environment := "development"
+	if isProduction {
+		environment = "production"
+	}
+
+	return ErrorReportingConfig{
+		SentryDSN:   getEnvOrDefault(constants.EnvSentryDSN, ""),
+		Environment: getEnvOrDefault(constants.EnvSentryEnvironment, environment),
+	}
+}
+
+// newMonitoringConfig creates latency budget monitoring configuration
+func newMonitoringConfig() MonitoringConfig {
+	return MonitoringConfig{
+		LatencyBudget:       getDurationEnvOrDefault(constants.EnvLatencyBudget, constants.DefaultLatencyBudget),
+		LatencyRouteBudgets: getEnvOrDefault(constants.EnvLatencyRouteBudgets, ""),
+		LatencyAlertAfter:   getDurationEnvOrDefault(constants.EnvLatencyAlertAfter, constants.DefaultLatencyAlertFor),
+	}
+}
+
+// newHomeAssistantConfig creates Home Assistant MQTT configuration
+func newHomeAssistantConfig() HomeAssistantConfig {
+	users := []string{}
+	if usersEnv := os.Getenv(constants.EnvHAMQTTUsers); usersEnv != "" {
+		for _, username := range strings.Split(usersEnv, ",") {
+			if username = strings.TrimSpace(username); username != "" {
+				users = append(users, username)
+			}
+		}
+	}
+
+	return HomeAssistantConfig{
+		MQTTURL:         getEnvOrDefault(constants.EnvHAMQTTURL, ""),
+		Users:           users,
+		DiscoveryPrefix: strings.Trim(getEnvOrDefault(constants.EnvHADiscoveryPrefix, constants.DefaultHADiscoveryPrefix), "/"),
+		TopicPrefix:     strings.Trim(getEnvOrDefault(constants.EnvHATopicPrefix, constants.DefaultHATopicPrefix), "/"),
+	}
+}
+
+// newBrandingConfig creates the instance branding configuration
+func newBrandingConfig() BrandingConfig {
+	return BrandingConfig{
+		InstanceName:    getEnvOrDefault(constants.EnvInstanceName, constants.DefaultInstanceName),
+		LogoURL:         getEnvOrDefault(constants.EnvLogoURL, constants.DefaultLogoURL),
+		MapCenterLat:    getFloatEnvOrDefault(constants.EnvMapCenterLat, constants.DefaultMapCenterLat),
+		MapCenterLon:    getFloatEnvOrDefault(constants.EnvMapCenterLon, constants.DefaultMapCenterLon),
+		MapZoom:         getIntEnvOrDefault(constants.EnvMapZoom, constants.DefaultMapZoom),
+		TileURL:         getEnvOrDefault(constants.EnvTileURL, constants.DefaultTileURL),
+		TileAttribution: getEnvOrDefault(constants.EnvTileAttribution, constants.DefaultTileAttribution),
+		TileMaxZoom:     getIntEnvOrDefault(constants.EnvTileMaxZoom, constants.DefaultTileMaxZoom),
+	}
+}
+
+// newSQLiteConfig creates the SQLite tuning applied to database connections
+func newSQLiteConfig() utils.SQLitePragmas {
+	return utils.SQLitePragmas{
+		JournalMode: getEnvOrDefault(constants.EnvSQLiteJournalMode, constants.DefaultSQLiteJournalMode),
+		Synchronous: getEnvOrDefault(constants.EnvSQLiteSynchronous, constants.DefaultSQLiteSynchronous),
+		CacheSize:   getIntEnvOrDefault(constants.EnvSQLiteCacheSize, constants.DefaultSQLiteCacheSize),
+		BusyTimeout: getDurationEnvOrDefault(constants.EnvSQLiteBusyTimeout, constants.DefaultSQLiteBusyTimeout),
+	}
+}
+
+// newLoggingConfig creates log output configuration
+func newLoggingConfig(isProduction bool) utils.LogOptions {
+	format := constants.LogFormatPretty
+	if isProduction {
+		format = constants.LogFormatJSON
+	}
+
+	return utils.LogOptions{
+		Format:       strings.ToLower(getEnvOrDefault(constants.EnvLogFormat, format)),
+		Outputs:      strings.Split(getEnvOrDefault(constants.EnvLogOutputs, constants.LogOutputStdout), ","),
+		Level:        getEnvOrDefault(constants.EnvLogLevel, ""),
+		ModuleLevels: getEnvOrDefault(constants.EnvLogModuleLevels, ""),
+
+		FilePath:       getEnvOrDefault(constants.EnvLogFilePath, constants.DefaultLogFilePath),
+		FileMaxSizeMB:  getIntEnvOrDefault(constants.EnvLogFileMaxSizeMB, constants.DefaultLogFileMaxSizeMB),
+		FileMaxAgeDays: getIntEnvOrDefault(constants.EnvLogFileMaxAgeDays, constants.DefaultLogFileMaxAgeDays),
+		FileMaxBackups: getIntEnvOrDefault(constants.EnvLogFileMaxBackups, constants.DefaultLogFileMaxBackups),
+
+		SyslogAddress: getEnvOrDefault(constants.EnvLogSyslogAddress, ""),
+		SyslogTag:     getEnvOrDefault(constants.EnvLogSyslogTag, constants.DefaultLogSyslogTag),
+	}
+}
+
 // GetServerAddress returns the full server address
 func (c *AppConfig) GetServerAddress() string {
 	return c.Host + ":" + c.Port
@@ -169,6 +541,11 @@ func (c *AppConfig) IsDevelopmentMode() bool {
 	return !c.IsProductionMode()
 }
 
+// LogOptions returns the log output configuration
+func (c *AppConfig) LogOptions() utils.LogOptions {
+	return c.Logging
+}
+
 // isProductionMode checks environment for production mode
 func isProductionMode() bool {
 	env := strings.ToLower(os.Getenv("GO_ENV"))
@@ -217,6 +594,16 @@ func getInt64EnvOrDefault(key string, defaultValue int64) int64 {
 	return defaultValue
 }
 
+// getFloatEnvOrDefault returns float64 environment variable value or default if not set/invalid
+func getFloatEnvOrDefault(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 // getDurationEnvOrDefault returns duration environment variable value or default if not set/invalid
 func getDurationEnvOrDefault(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,20 @@
+package constants
+
+// Session activity types, describing what a session records
+const (
+	ActivityWalking  = "walking"
+	ActivityRunning  = "running"
+	ActivityHiking   = "hiking"
+	ActivityCycling  = "cycling"
+	ActivitySkiing   = "skiing"
+	ActivityPaddling = "paddling"
+	ActivityDriving  = "driving"
+	ActivityFlying   = "flying"
+	ActivityOther    = "other"
+)
+
+// SessionActivities lists the valid session activity types
+var SessionActivities = []string{
+	ActivityWalking, ActivityRunning, ActivityHiking, ActivityCycling, ActivitySkiing,
+	ActivityPaddling, ActivityDriving, ActivityFlying, ActivityOther,
+}
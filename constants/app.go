@@ -12,6 +12,7 @@ const (
 	CollectionUsers     = "users"
 	CollectionSessions  = "sessions"
 	CollectionLocations = "locations"
+	CollectionWaypoints = "waypoints"
 )
 
 // API Pagination constants
@@ -24,13 +25,108 @@ const (
 
 	// Location query limits
 	PublicLocationsLimit = 50
+
+	// Public locations feed sort orders
+	PublicFeedSortRecent   = "recent"   // Most recent location first, the default
+	PublicFeedSortDistance = "distance" // Closest to the center of the bbox first
+	PublicFeedSortUsername = "username"
+)
+
+// Avatar constants
+const (
+	AvatarSize         = 512  // Edge length of the square avatar stored after processing
+	AvatarMinDimension = 64   // Smallest accepted width and height of an uploaded avatar
+	AvatarMaxDimension = 6000 // Largest accepted width and height, larger images are not decoded
+
+	// Resized variants created on upload, also requestable with ?thumb= on the files API
+	AvatarThumbSmall  = "64x64"   // Map markers
+	AvatarThumbMedium = "128x128" // Profile and session views
+)
+
+// Waypoint constants
+const (
+	// WaypointOrderSort sorts waypoints by their explicit order, falling back to creation time
+	WaypointOrderSort = "order,created"
+
+	// Community waypoint layer limits
+	CommunityWaypointsLimit    = 500 // Maximum features returned for a bbox query
+	CommunityFlagHideThreshold = 3   // Flags after which a community waypoint is hidden
+)
+
+// Session replay constants
+const (
+	DefaultReplaySpeed = 60    // Recorded seconds per playback second
+	MaxReplaySpeed     = 86400 // One day per playback second
+	MaxReplayFrames    = 10000 // Upper bound on frames returned by a replay request
+)
+
+// Live session stream constants
+const (
+	LiveStreamHeartbeat = 30 * time.Second // Interval of keep-alive comments on idle streams
+)
+
+// Device session constants
+const (
+	CollectionAuthSessions   = "auth_sessions"
+	AuthSessionTouchInterval = time.Minute // Minimum time between last-seen updates of a device session
+	AuthSessionHistoryLimit  = 50          // Maximum device sessions returned in the login history
+)
+
+// Login anomaly detection constants
+const (
+	LoginAnomalyNewCountry = "new_country" // Login from a country not seen in the login history
+	LoginAnomalyNewNetwork = "new_network" // Login from an autonomous system not seen in the login history
+
+	GeoIPLookupTimeout = 3 * time.Second // Upper bound on the geo-IP lookup during login
+	GeoIPCacheTTL      = 24 * time.Hour  // Lifetime of cached geo-IP lookups
+	GeoIPCacheSize     = 1000            // Cached addresses before expired entries are purged
+)
+
+// CAPTCHA and proof-of-work challenge constants
+const (
+	CaptchaProviderHCaptcha  = "hcaptcha"
+	CaptchaProviderTurnstile = "turnstile"
+	CaptchaProviderPoW       = "pow" // Built-in proof-of-work challenge, no third party involved
+
+	CaptchaTokenHeader     = "X-Captcha-Token"
+	HCaptchaVerifyURL      = "https://api.hcaptcha.com/siteverify"
+	TurnstileVerifyURL     = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	CaptchaVerifyTimeout   = 5 * time.Second
+	DefaultCaptchaFailures = 3 // Failed logins from a client before a CAPTCHA is required
+
+	PoWChallengeTTL      = 5 * time.Minute // Time to solve and submit a proof-of-work challenge
+	DefaultPoWDifficulty = 20              // Leading zero bits, about a million hashes on average
+)
+
+// Guest viewer token constants
+const (
+	GuestTokenHeader     = "X-Guest-Token"
+	GuestTokenQueryParam = "guest_token"
+	GuestTokenPrefix     = "vtguest_"
+	GuestTokenTTL        = 12 * time.Hour // Lifetime of a guest token minted from a share link
+)
+
+// Signed download URLs of private files
+const (
+	SignedFilesPath        = "/signed-files" // Below the API prefix: /signed-files/:collection/:record/:filename
+	SignedFileURLTTL       = 15 * time.Minute
+	SignedFileExpiresParam = "expires"
+	SignedFileSigParam     = "signature"
+)
+
+// Read-only maintenance mode
+const (
+	DefaultReadOnlyMessage = "The service is in read-only maintenance mode, please try again later"
 )
 
 // Environment variables
 const (
-	EnvAutomigrate = "PB_AUTOMIGRATE"
-	EnvPort        = "PORT"
-	EnvHost        = "HOST"
+	EnvAutomigrate     = "PB_AUTOMIGRATE"
+	EnvPort            = "PORT"
+	EnvHost            = "HOST"
+	EnvReadOnly        = "READ_ONLY"
+	EnvReadOnlyMessage = "READ_ONLY_MESSAGE"
+	EnvGraphQLEnabled  = "GRAPHQL_ENABLED"
 )
 
 // API paths and endpoints
@@ -46,7 +142,11 @@ const (
 	EndpointTrack          = "/track"
 
 	// Session endpoints
-	EndpointSessions = "/sessions"
+	EndpointSessions      = "/sessions"
+	EndpointSessionGeotag = "/sessions/:username/:name/geotag"
+
+	// Optional GraphQL endpoint
+	EndpointGraphQL = "/graphql"
 )
 
 // Default values for location tracking
@@ -64,6 +164,7 @@ const (
 	SessionRateLimit  = 30  // Moderate for sessions
 	PublicRateLimit   = 100 // Generous for public endpoints
 	DocsRateLimit     = 10  // Low for documentation
+	GuestRateLimit    = 60  // Per guest viewer of shared sessions
 
 	// Rate limiting burst sizes
 	AuthBurstSize     = 2
@@ -71,6 +172,7 @@ const (
 	SessionBurstSize  = 5
 	PublicBurstSize   = 20
 	DocsBurstSize     = 3
+	GuestBurstSize    = 10
 
 	// Request size limits (in bytes)
 	MaxJSONRequestSize = 1024 * 1024      // 1MB for JSON requests
@@ -97,3 +199,10 @@ const (
 	CSPConnectSrc = "'self'"
 	CSPFontSrc    = "'self' https://unpkg.com"
 )
+
+// Compact encodings of tracked points for cellular trackers, accepted by POST /api/track
+const (
+	ContentTypeCBOR         = "application/cbor"
+	ContentTypeProtobuf     = "application/x-protobuf"
+	ContentTypeProtobufIETF = "application/protobuf"
+)
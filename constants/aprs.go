@@ -0,0 +1,20 @@
+package constants
+
+import "time"
+
+// APRS-IS bridge for amateur radio trackers
+const (
+	DefaultAPRSServer = "rotate.aprs2.net:14580"
+	DefaultAPRSLogin  = "N0CALL" // Receive-only logins need no verified callsign
+
+	APRSPasscode       = "-1"             // Unverified, receive-only login
+	APRSReadTimeout    = 2 * time.Minute  // Servers send a keep-alive comment every 20 seconds
+	APRSReconnectDelay = 30 * time.Second // Wait between connection attempts
+	APRSMaxLineSize    = 512              // Longer packets are dropped and end the connection
+	APRSSpeedUnit      = "knots"
+
+	// Environment variable names
+	EnvAPRSServer = "APRS_SERVER"
+	EnvAPRSLogin  = "APRS_LOGIN"
+	EnvAPRSFollow = "APRS_FOLLOW"
+)
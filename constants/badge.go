@@ -0,0 +1,18 @@
+package constants
+
+import "time"
+
+// Status badges
+const (
+	BadgeShowLastSeen      = "last_seen"      // Time since the latest location
+	BadgeShowDistanceToday = "distance_today" // Distance tracked since midnight in the user's time zone
+	BadgeCacheMaxAge       = 5 * time.Minute  // How long browsers and proxies may serve a rendered badge
+	BadgeMaxLabelLength    = 40
+
+	// Badge colors
+	BadgeColorActive   = "#4c1"    // Seen within BadgeActiveWindow
+	BadgeColorRecent   = "#dfb317" // Seen within a day
+	BadgeColorInactive = "#9f9f9f"
+	BadgeColorDistance = "#007ec6"
+	BadgeActiveWindow  = 15 * time.Minute
+)
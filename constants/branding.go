@@ -0,0 +1,25 @@
+package constants
+
+// Instance branding, returned to clients configuring themselves per instance
+const (
+	EndpointBranding = "/config/branding"
+
+	DefaultInstanceName    = "Vibe Tracker"
+	DefaultLogoURL         = "/favicon.svg"
+	DefaultMapCenterLat    = 20.0
+	DefaultMapCenterLon    = 0.0
+	DefaultMapZoom         = 2
+	DefaultTileURL         = "https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png"
+	DefaultTileAttribution = `&copy; <a href="https://www.openstreetmap.org/copyright">OpenStreetMap</a> contributors`
+	DefaultTileMaxZoom     = 19
+
+	// Environment variable names
+	EnvInstanceName    = "INSTANCE_NAME"
+	EnvLogoURL         = "INSTANCE_LOGO_URL"
+	EnvMapCenterLat    = "MAP_CENTER_LAT"
+	EnvMapCenterLon    = "MAP_CENTER_LON"
+	EnvMapZoom         = "MAP_ZOOM"
+	EnvTileURL         = "MAP_TILE_URL"
+	EnvTileAttribution = "MAP_TILE_ATTRIBUTION"
+	EnvTileMaxZoom     = "MAP_TILE_MAX_ZOOM"
+)
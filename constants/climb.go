@@ -0,0 +1,25 @@
+package constants
+
+// Climb categories, from the easiest to the hardest, as used by cycling apps
+const (
+	ClimbCategory4  = "4"
+	ClimbCategory3  = "3"
+	ClimbCategory2  = "2"
+	ClimbCategory1  = "1"
+	ClimbCategoryHC = "HC" // Hors catégorie, beyond categorization
+
+	// A climb's score is its length in meters times its average gradient in percent; a climb is
+	// categorized by the highest of these minimum scores it reaches
+	ClimbScoreCategory4  = 8000.0
+	ClimbScoreCategory3  = 16000.0
+	ClimbScoreCategory2  = 32000.0
+	ClimbScoreCategory1  = 64000.0
+	ClimbScoreCategoryHC = 80000.0
+)
+
+// Climb detection
+const (
+	ClimbMinGradient      = 3.0   // Percent; flatter ascents are not reported
+	ClimbDescentTolerance = 10.0  // Meters an ascent may drop below its top before the climb ends
+	ClimbMaxGradientSpan  = 100.0 // Meters over which the steepest gradient of a climb is measured
+)
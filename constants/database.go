@@ -0,0 +1,17 @@
+package constants
+
+import "time"
+
+// SQLite tuning, applied to every database connection
+const (
+	DefaultSQLiteJournalMode = "WAL"    // Readers do not block the writer and the other way around
+	DefaultSQLiteSynchronous = "NORMAL" // Safe with WAL, a power loss may only roll back the last commits
+	DefaultSQLiteCacheSize   = -64000   // Negative values are KiB, i.e. 64 MB page cache per connection
+	DefaultSQLiteBusyTimeout = 10 * time.Second
+
+	// Environment variable names for SQLite tuning
+	EnvSQLiteJournalMode = "SQLITE_JOURNAL_MODE"
+	EnvSQLiteSynchronous = "SQLITE_SYNCHRONOUS"
+	EnvSQLiteCacheSize   = "SQLITE_CACHE_SIZE"
+	EnvSQLiteBusyTimeout = "SQLITE_BUSY_TIMEOUT"
+)
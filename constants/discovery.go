@@ -0,0 +1,29 @@
+package constants
+
+import "time"
+
+// Client auto-configuration document, letting mobile clients set up from just the server URL
+const (
+	WellKnownClientConfig = "/.well-known/vibe-tracker.json"
+	ClientConfigVersion   = 1 // Version of the document format, raised on incompatible changes
+	ClientConfigMaxAge    = time.Hour
+
+	// Server version, modules and limits clients adapt to
+	EndpointCapabilities = "/capabilities"
+	APIVersion           = 1 // Raised on incompatible API changes
+
+	// Tracking protocols
+	ProtocolQuery         = "query"          // GET /api/track with query parameters
+	ProtocolGeoJSON       = "geojson"        // POST /api/track with a GeoJSON Point Feature
+	ProtocolHomeAssistant = "home_assistant" // Home Assistant mobile_app webhook
+	ProtocolOwnTracks     = "owntracks"      // OwnTracks apps in HTTP mode
+	ProtocolOsmAnd        = "osmand"         // osmAnd/Traccar HTTP protocol with query parameters
+	ProtocolWebSocket     = "websocket"      // JSON points streamed over a WebSocket
+	ProtocolGRPC          = "grpc"           // vibetracker.v1.TrackingService stream
+
+	// Authentication methods
+	AuthMethodPassword = "password" // Login returning a JWT sent as "Authorization: Bearer <jwt>"
+	AuthMethodToken    = "token"    // The user's tracking token, for devices that cannot log in
+
+	GRPCTrackingService = "vibetracker.v1.TrackingService"
+)
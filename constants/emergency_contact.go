@@ -0,0 +1,22 @@
+package constants
+
+import "time"
+
+// Emergency contact collection names
+const (
+	CollectionEmergencyContacts = "emergency_contacts"
+)
+
+// Channels emergency contacts are notified on
+const (
+	ContactChannelEmail = "email"
+	ContactChannelSMS   = "sms" // Delivered by an SMS gateway subscribed to the alert webhooks
+)
+
+// Emergency contact limits
+const (
+	MaxEmergencyContacts              = 5
+	ContactVerificationTTL            = 7 * 24 * time.Hour // Lifetime of an email verification link
+	ContactVerificationResendInterval = 5 * time.Minute    // Minimum time between verification emails to a contact
+	ContactVerificationTokenLength    = 40
+)
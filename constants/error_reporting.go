@@ -0,0 +1,19 @@
+package constants
+
+import "time"
+
+// Incident and error reporting constants
+const (
+	IncidentIDHeader = "X-Incident-ID" // Response header carrying the incident ID of a recovered panic
+	IncidentIDLength = 12
+
+	RequestIDHeader    = "X-Request-ID" // Request header with a caller supplied ID, echoed in the response
+	RequestIDLength    = 16             // Length of generated request IDs
+	RequestIDMaxLength = 64             // Longer caller supplied IDs are replaced
+
+	SentryFlushTimeout = 2 * time.Second // Time given to deliver queued reports on shutdown
+
+	// Environment variable names for error reporting configuration
+	EnvSentryDSN         = "SENTRY_DSN" // Sentry or GlitchTip DSN; empty disables reporting
+	EnvSentryEnvironment = "SENTRY_ENVIRONMENT"
+)
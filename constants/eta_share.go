@@ -0,0 +1,27 @@
+package constants
+
+import "time"
+
+// ETA share collection names
+const (
+	CollectionETAShares = "eta_shares"
+)
+
+// ETA share states
+const (
+	ETAShareStatusActive  = "active"
+	ETAShareStatusArrived = "arrived" // The owner came within the arrival radius, the position is no longer shared
+	ETAShareStatusExpired = "expired"
+)
+
+// ETA share limits and estimation
+const (
+	ETAShareTokenLength  = 32
+	MaxActiveETAShares   = 10
+	DefaultArrivalRadius = 100.0 // Meters from the destination that count as arrived
+
+	ETASpeedWindow  = 10 * time.Minute // Recent movement the travel speed is estimated from
+	ETAMinSpeedSpan = 30 * time.Second // Shortest movement span that gives a usable speed
+	ETAMinSpeed     = 0.5              // Meters per second; slower movement gives no ETA
+	ETARecentPoints = 100              // Upper bound on points read for the speed estimate
+)
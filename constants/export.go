@@ -0,0 +1,18 @@
+package constants
+
+// Session export formats
+const (
+	ExportFormatGPX     = "gpx"
+	ExportFormatGeoJSON = "geojson"
+	DefaultExportFormat = ExportFormatGPX
+)
+
+// XML namespaces of the GPX extensions session exports carry their measures in. Heart rate,
+// cadence, temperature and speed use Garmin's TrackPointExtension, power the PowerExtension
+// read by Strava and most analysis tools, and the fix accuracy, which neither covers, our own.
+const (
+	GPXNamespace              = "http://www.topografix.com/GPX/1/1"
+	GPXTrackPointExtensionNS  = "http://www.garmin.com/xmlschemas/TrackPointExtension/v2"
+	GPXPowerExtensionNS       = "http://www.garmin.com/xmlschemas/PowerExtension/v1"
+	GPXVibeTrackerExtensionNS = "https://github.com/dyuri/vibe-tracker/xmlschemas/TrackPointExtension/v1"
+)
@@ -0,0 +1,17 @@
+package constants
+
+import "time"
+
+// Cleanup of stored files that no record refers to anymore
+const (
+	OrphanScanInterval = 24 * time.Hour
+	OrphanFileMinAge   = time.Hour // Younger files may belong to an upload whose record is not saved yet
+
+	// Why a stored file is reported as orphaned
+	OrphanReasonCollectionDeleted = "collection_deleted"
+	OrphanReasonRecordDeleted     = "record_deleted"
+	OrphanReasonUnreferenced      = "unreferenced" // The record exists but no longer lists the file
+
+	// Environment variable names
+	EnvDeleteOrphanedFiles = "DELETE_ORPHANED_FILES"
+)
@@ -0,0 +1,13 @@
+package constants
+
+import "time"
+
+// Reverse geocoding of locations to countries and regions
+const (
+	GeocodeJobInterval  = 10 * time.Minute // How often locations without a country are geocoded
+	GeocodeBatchSize    = 500              // Locations geocoded per run
+	GeocodeRequestDelay = time.Second      // Pause between lookups, public services such as Nominatim allow one per second
+	GeocodeTimeout      = 10 * time.Second
+	GeocodeCellSize     = 0.05 // Degrees; locations in the same grid cell share one lookup
+	GeocodeCacheSize    = 10000
+)
@@ -0,0 +1,35 @@
+package constants
+
+import "time"
+
+// Duplicate detection of imported GPX activities. An imported point is compared with the position
+// another session recorded nearest in time; the import conflicts with a session when enough
+// compared points lie close to its track.
+const (
+	DuplicateMaxTimeOffset = 2 * time.Minute // Recorded positions further away in time are not compared
+	DuplicateMaxDistance   = 150.0           // Meters between compared positions for a point to match
+	DuplicateMinSimilarity = 0.5             // Share of matching compared points
+	DuplicateMinPoints     = 5               // Compared points needed to call a session a duplicate
+)
+
+// Simplification of planned tracks imported from GPX. The full-resolution plan is stored too;
+// the simplified plan keeps the points of tracks longer than the minimum that are needed to stay
+// within the suggested tolerance times the multiplier, and at most the maximum (0 = no limit).
+const (
+	DefaultSimplifyTracks            = true
+	DefaultSimplifyMinPoints         = 100
+	DefaultSimplifyMaxPoints         = 0
+	DefaultSimplifyEpsilonMultiplier = 1.0
+
+	// Environment variable names for track simplification
+	EnvSimplifyTracks            = "GPX_SIMPLIFY"
+	EnvSimplifyMinPoints         = "GPX_SIMPLIFY_MIN_POINTS"
+	EnvSimplifyMaxPoints         = "GPX_SIMPLIFY_MAX_POINTS"
+	EnvSimplifyEpsilonMultiplier = "GPX_SIMPLIFY_EPSILON_MULTIPLIER"
+)
+
+// Kinds of the planned tracks of a session, the tracks and routes of its GPX file
+const (
+	PlannedTrackTypeTrack = "track"
+	PlannedTrackTypeRoute = "route"
+)
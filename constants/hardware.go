@@ -0,0 +1,21 @@
+package constants
+
+import "time"
+
+// Dedicated hardware trackers reporting over socket protocols
+const (
+	CollectionHardwareTrackers = "hardware_trackers"
+
+	TrackerProtocolGT06 = "gt06"
+	TrackerProtocolH02  = "h02"
+
+	TrackerEventAlarm   = "alarm"          // Event of points reported with an alarm, e.g. SOS
+	IMEILength          = 15               // Digits of the IMEI devices identify themselves with
+	TrackerIdleTimeout  = 30 * time.Minute // Connections without a frame for this long are closed
+	TrackerMaxFrameSize = 1024             // Longer frames are dropped and end the connection
+	TrackerSeenInterval = time.Minute      // Minimum time between last-seen updates of a device
+
+	// Environment variable names of the listen addresses; an empty address disables the protocol
+	EnvTrackerGT06Address = "TRACKER_GT06_ADDRESS"
+	EnvTrackerH02Address  = "TRACKER_H02_ADDRESS"
+)
@@ -0,0 +1,22 @@
+package constants
+
+import "time"
+
+// Home Assistant integration
+const (
+	EndpointHomeAssistantWebhook = "/integrations/home-assistant/webhook"
+	HomeAssistantSource          = "home-assistant"
+
+	DefaultHADiscoveryPrefix = "homeassistant"
+	DefaultHATopicPrefix     = "vibetracker"
+
+	HAMQTTKeepAlive      = 60 * time.Second
+	HAMQTTReconnectDelay = 30 * time.Second
+	HAMQTTQueueSize      = 256 // Location updates buffered while the broker is slow or away; further updates are dropped
+
+	// Environment variable names; no MQTT URL or no exposed users disables the publisher
+	EnvHAMQTTURL         = "HA_MQTT_URL"
+	EnvHAMQTTUsers       = "HA_MQTT_USERS"
+	EnvHADiscoveryPrefix = "HA_MQTT_DISCOVERY_PREFIX"
+	EnvHATopicPrefix     = "HA_MQTT_TOPIC_PREFIX"
+)
@@ -0,0 +1,46 @@
+package constants
+
+import "time"
+
+// Write batching of tracked locations
+const (
+	IngestModeOff   = "off"   // Every point is written in its own transaction
+	IngestModeSync  = "sync"  // Points are written in batches, senders wait until their batch is committed
+	IngestModeAsync = "async" // Points are acknowledged once queued and written in batches
+
+	DefaultIngestMode          = IngestModeOff
+	DefaultIngestBatchInterval = 100 * time.Millisecond
+	DefaultIngestBatchSize     = 200
+	DefaultIngestQueueSize     = 10000 // Points queued at most, further points are refused
+	DefaultIngestWorkers       = 2
+
+	IngestRetryAfter = time.Second // Sent as Retry-After when the queue is full
+
+	// Environment variable names for write batching configuration
+	EnvIngestMode          = "INGEST_BATCH_MODE"
+	EnvIngestBatchInterval = "INGEST_BATCH_INTERVAL"
+	EnvIngestBatchSize     = "INGEST_BATCH_SIZE"
+	EnvIngestQueueSize     = "INGEST_QUEUE_SIZE"
+	EnvIngestWorkers       = "INGEST_WORKERS"
+)
+
+// gRPC ingestion service for embedded trackers
+const (
+	GRPCAuthMetadata    = "authorization" // Metadata key of the JWT ("Bearer <jwt>") or tracking token
+	GRPCShutdownTimeout = 5 * time.Second // Time open streams get to finish on shutdown
+
+	// Environment variable names for the gRPC listener; an empty address disables the service
+	EnvGRPCAddress = "GRPC_ADDRESS"
+	EnvGRPCTLSCert = "GRPC_TLS_CERT"
+	EnvGRPCTLSKey  = "GRPC_TLS_KEY"
+)
+
+// WebSocket channel streaming tracked points over one connection
+const (
+	EndpointTrackWebSocket = "/track/ws"
+	TrackingSocketSource   = "websocket" // Token of the validated parameters of streamed points
+
+	TrackingSocketMaxMessageSize = 4 * 1024         // One JSON point per message
+	TrackingSocketIdleTimeout    = 5 * time.Minute  // Connections without messages are closed
+	TrackingSocketWriteTimeout   = 10 * time.Second // Time to send an acknowledgement
+)
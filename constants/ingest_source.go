@@ -0,0 +1,17 @@
+package constants
+
+// Webhook ingest sources collection names
+const (
+	CollectionIngestSources = "ingest_sources"
+)
+
+// Webhook ingest source limits
+const (
+	MaxIngestSources         = 20
+	IngestSourceSecretLength = 32
+	IngestWebhookMaxBodySize = 64 << 10 // Larger webhook bodies are rejected
+
+	// Webhooks authenticate with the source secret in this header or query parameter
+	IngestWebhookSecretHeader = "X-Webhook-Secret"
+	IngestWebhookSecretParam  = "secret"
+)
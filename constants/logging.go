@@ -0,0 +1,35 @@
+package constants
+
+// Logging constants
+const (
+	LogFormatJSON   = "json"
+	LogFormatPretty = "pretty"
+
+	LogOutputStdout = "stdout"
+	LogOutputFile   = "file"
+	LogOutputSyslog = "syslog"
+
+	// Modules whose level can be set apart from the default level
+	LogModuleHTTP     = "http"     // Request logs
+	LogModuleSecurity = "security" // Security events such as failed logins and blocked requests
+
+	// Defaults of the rotating log file
+	DefaultLogFilePath       = "pb_data/logs/vibe-tracker.log"
+	DefaultLogFileMaxSizeMB  = 100
+	DefaultLogFileMaxAgeDays = 30
+	DefaultLogFileMaxBackups = 10
+
+	DefaultLogSyslogTag = "vibe-tracker"
+
+	// Environment variable names for logging configuration
+	EnvLogFormat         = "LOG_FORMAT"
+	EnvLogOutputs        = "LOG_OUTPUTS" // Comma-separated list of stdout, file and syslog
+	EnvLogLevel          = "LOG_LEVEL"
+	EnvLogModuleLevels   = "LOG_MODULE_LEVELS" // Comma-separated module=level pairs, e.g. security=debug,http=warn
+	EnvLogFilePath       = "LOG_FILE_PATH"
+	EnvLogFileMaxSizeMB  = "LOG_FILE_MAX_SIZE_MB"
+	EnvLogFileMaxAgeDays = "LOG_FILE_MAX_AGE_DAYS"
+	EnvLogFileMaxBackups = "LOG_FILE_MAX_BACKUPS"
+	EnvLogSyslogAddress  = "LOG_SYSLOG_ADDRESS" // network:address of a remote syslog server, e.g. udp:logs.example.com:514
+	EnvLogSyslogTag      = "LOG_SYSLOG_TAG"
+)
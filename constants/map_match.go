@@ -0,0 +1,26 @@
+package constants
+
+import "time"
+
+// Map matching backends snapping tracks to OSM ways
+const (
+	MapMatchProviderValhalla = "valhalla"
+	MapMatchProviderOSRM     = "osrm"
+	DefaultMapMatchProvider  = MapMatchProviderValhalla
+
+	// Costing model (Valhalla) or profile (OSRM) used when none is configured; the pedestrian
+	// costing matches roads as well as trails
+	DefaultMapMatchCosting = "pedestrian"
+	DefaultOSRMProfile     = "driving"
+
+	ValhallaMatchChunkSize = 1000 // Points per request, Valhalla limits trace sizes
+	OSRMMatchChunkSize     = 100  // Points per request, osrm-routed's default --max-matching-size
+	MapMatchTimeout        = 60 * time.Second
+)
+
+// Track variants of session data
+const (
+	TrackVariantParam   = "track"
+	TrackVariantRaw     = "raw"     // Recorded positions
+	TrackVariantMatched = "matched" // Positions snapped to roads where the session was map matched
+)
@@ -0,0 +1,21 @@
+package constants
+
+import "time"
+
+// Import of Garmin inReach positions from MapShare KML feeds
+const (
+	CollectionMapShareFeeds = "mapshare_feeds"
+
+	DefaultMapSharePollInterval = 5 * time.Minute  // inReach devices send a position every 10 minutes by default
+	MapShareTimeout             = 30 * time.Second // Timeout of a feed request
+	MapShareMaxFeedSize         = 5 << 20          // Larger feeds are rejected
+	MapShareMergeWindow         = 2 * time.Hour    // Positions join the session of phone points this close in time
+	MapShareSpeedUnit           = "km/h"
+
+	// Environment variable names; a zero interval disables the import
+	EnvMapSharePollInterval = "MAPSHARE_POLL_INTERVAL"
+)
+
+// MapShareHosts are the Garmin hosts serving MapShare feeds; feeds and their redirects are only
+// fetched from these
+var MapShareHosts = []string{"share.garmin.com", "explore.garmin.com", "eur.explore.garmin.com"}
@@ -0,0 +1,26 @@
+package constants
+
+// Moderation collection names
+const (
+	CollectionReports = "reports"
+)
+
+// Content types that can be reported
+const (
+	ReportTargetSession           = "session"
+	ReportTargetWaypoint          = "waypoint"
+	ReportTargetCommunityWaypoint = "community_waypoint"
+)
+
+// Report review states
+const (
+	ReportStatusPending   = "pending"
+	ReportStatusDismissed = "dismissed"
+	ReportStatusActioned  = "actioned"
+)
+
+// Moderation limits
+const (
+	ReportHideThreshold = 1  // Pending reports after which content is hidden until reviewed
+	ReportQueueLimit    = 50 // Default page size of the review queue
+)
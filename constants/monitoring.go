@@ -0,0 +1,19 @@
+package constants
+
+import "time"
+
+// Latency budget monitoring constants
+const (
+	LatencyWindow          = time.Minute // Interval over which the p95 latency of a route is computed
+	LatencyPercentile      = 0.95
+	LatencySampleLimit     = 1000 // Durations kept per route and window, larger windows are sampled
+	DefaultLatencyBudget   = 2 * time.Second
+	DefaultLatencyAlertFor = 5 * time.Minute
+
+	// Environment variable names for latency budget configuration
+	EnvLatencyBudget       = "LATENCY_BUDGET" // p95 budget of every route (0 = off)
+	EnvLatencyRouteBudgets = "LATENCY_ROUTE_BUDGETS"
+	EnvLatencyAlertAfter   = "LATENCY_ALERT_AFTER"
+
+	WebhookEventLatencyBudget = "latency.budget_exceeded"
+)
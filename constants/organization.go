@@ -0,0 +1,22 @@
+package constants
+
+// Organization collection names
+const (
+	CollectionOrganizations       = "organizations"
+	CollectionOrganizationMembers = "organization_members"
+	CollectionOrganizationAPIKeys = "organization_api_keys"
+)
+
+// Organization member roles, from most to least privileged
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// Organization API keys
+const (
+	OrgAPIKeyHeader = "X-Org-Api-Key" // Request header carrying an organization API key
+	OrgAPIKeyPrefix = "vtorg_"        // Prefix that makes organization keys recognizable
+	OrgAPIKeyLength = 40              // Random characters following the prefix
+)
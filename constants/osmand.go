@@ -0,0 +1,11 @@
+package constants
+
+// osmAnd/Traccar HTTP protocol, used by the Traccar Client apps, the OsmAnd tracking plugin and
+// many GPS trackers
+const (
+	EndpointOsmAnd = "/integrations/osmand"
+	OsmAndSource   = "osmand"
+
+	OsmAndDeviceIDParam = "id"    // Device identifier, the tracking token when nothing else authenticates
+	OsmAndSpeedUnit     = "knots" // Unit of the speed parameter unless speed_unit says otherwise
+)
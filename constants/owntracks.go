@@ -0,0 +1,12 @@
+package constants
+
+// OwnTracks HTTP mode integration
+const (
+	EndpointOwnTracks = "/integrations/owntracks"
+	OwnTracksSource   = "owntracks"
+
+	OwnTracksTypeLocation  = "location"
+	OwnTracksTypeEncrypted = "encrypted"
+	OwnTracksSpeedUnit     = "km/h"
+	OwnTracksDeviceHeader  = "X-Limit-D" // Device ID configured in the app, sent with every request
+)
@@ -0,0 +1,25 @@
+package constants
+
+// User roles stored on the users collection
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleUser      = "user"
+	RoleReadonly  = "readonly"
+)
+
+// Permissions checked by the access control layer
+const (
+	PermSessionsRead        = "sessions:read"
+	PermSessionsWrite       = "sessions:write"
+	PermWaypointsRead       = "waypoints:read"
+	PermWaypointsWrite      = "waypoints:write"
+	PermTrackingWrite       = "tracking:write"
+	PermCommunityPublish    = "community:publish"
+	PermCommunityModerate   = "community:moderate"
+	PermOrganizationsCreate = "organizations:create"
+	PermUsersManage         = "users:manage"
+	PermContentReport       = "content:report"
+	PermReportsReview       = "reports:review"
+	PermSystemManage        = "system:manage"
+)
@@ -0,0 +1,26 @@
+package constants
+
+import "time"
+
+// Routing engines and route planning profiles
+const (
+	RoutingProviderValhalla = "valhalla"
+	RoutingProviderOSRM     = "osrm"
+	DefaultRoutingProvider  = RoutingProviderValhalla
+
+	RouteProfileFoot    = "foot"
+	RouteProfileBike    = "bike"
+	DefaultRouteProfile = RouteProfileFoot
+)
+
+// Route planning response formats
+const (
+	RouteFormatGeoJSON = "geojson"
+	RouteFormatGPX     = "gpx" // Ready to upload as the planned route of a session
+)
+
+// Route planning proxy
+const (
+	RoutingTimeout = 30 * time.Second
+	RouteGPXName   = "Planned route"
+)
@@ -0,0 +1,14 @@
+package constants
+
+// Session media collection names
+const (
+	CollectionSessionMedia = "session_media"
+)
+
+// Session media types and limits
+const (
+	SessionMediaImage = "image"
+	SessionMediaVideo = "video"
+
+	MaxSessionMedia = 30 // Media files per session, besides the cover
+)
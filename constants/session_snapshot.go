@@ -0,0 +1,16 @@
+package constants
+
+// Session snapshot collection names
+const (
+	CollectionSessionSnapshots = "session_snapshots"
+)
+
+// Static bundles of finished sessions
+const (
+	SnapshotFormatVersion = 1 // Version of the manifest format
+	SnapshotManifestName  = "snapshot.json"
+	SnapshotTrackName     = "track.geojson"
+
+	MaxSnapshotFiles    = 100      // Manifest, track and photos of a bundle
+	MaxSnapshotFileSize = 50 << 20 // Long tracks make the largest files
+)
@@ -0,0 +1,16 @@
+package constants
+
+import "time"
+
+// Surface types that Valhalla's way surfaces are grouped into for paved vs trail statistics
+const (
+	SurfaceTypePaved   = "paved"
+	SurfaceTypeUnpaved = "unpaved"
+	SurfaceTypeUnknown = "unknown"
+)
+
+// Surface enrichment of ended sessions
+const (
+	SurfaceJobInterval = 15 * time.Minute
+	SurfaceBatchSize   = 10 // Sessions matched per run
+)
@@ -0,0 +1,34 @@
+package constants
+
+import "time"
+
+// Timeline collection names
+const (
+	CollectionTimelines = "timelines"
+)
+
+// Timeline entry types
+const (
+	TimelineEntryStay     = "stay"     // Time spent within the stay radius of one place
+	TimelineEntryMovement = "movement" // Travel between stays
+)
+
+// Transport modes detected for movements from their average speed
+const (
+	TransportModeWalking = "walking"
+	TransportModeCycling = "cycling"
+	TransportModeDriving = "driving"
+	TransportModeFlying  = "flying"
+
+	MaxWalkingSpeed = 2.5  // Meters per second, about 9 km/h
+	MaxCyclingSpeed = 8.0  // Meters per second, about 29 km/h
+	MaxDrivingSpeed = 55.0 // Meters per second, about 200 km/h
+)
+
+// Timeline detection and computation
+const (
+	TimelineStayRadius      = 100.0           // Meters a stay's points stay within from its first point
+	TimelineStayMinDuration = 5 * time.Minute // Shortest time at one place that counts as a stay
+	TimelineDateFormat      = "2006-01-02"
+	TimelineJobInterval     = time.Hour // How often timelines of days with new locations are recomputed
+)
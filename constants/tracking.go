@@ -0,0 +1,128 @@
+package constants
+
+import "time"
+
+// Tracking configuration defaults
+const (
+	// Distance in meters within which a tracked location marks a waypoint as visited
+	DefaultWaypointVisitRadius = 50.0
+
+	// Per-user tracking defaults used until the user changes them
+	DefaultAutoSessionGap = 0  // Minutes; automatic sessions are disabled by default
+	DefaultPointInterval  = 30 // Seconds between points suggested to tracking clients
+	DefaultTimezone       = "UTC"
+
+	// Accepted range of explicit point timestamps; clock-broken devices report 1970 or future times
+	DefaultMaxTimestampAge  = 7 * 24 * time.Hour // Oldest accepted point unless historical imports are requested
+	DefaultMaxTimestampSkew = 5 * time.Minute    // Tolerated device clock drift into the future
+
+	// Consecutive points further apart than either limit are split into separate track segments,
+	// so tunnels, flights and tracker outages are not drawn or measured as straight lines
+	DefaultGapMaxInterval = 10 * time.Minute
+	DefaultGapMaxDistance = 5000.0 // Meters
+
+	// Speed units tracking clients may report in; speeds are stored in m/s
+	SpeedUnitMetersPerSecond = "m/s"
+	SpeedUnitKilometersPerHr = "km/h"
+	SpeedUnitMilesPerHour    = "mph"
+	SpeedUnitKnots           = "knots"
+	DefaultSpeedUnit         = SpeedUnitMetersPerSecond
+
+	// Policies choosing a user's current position when several devices report
+	LocationPolicyNewest       = "newest"        // Most recent fix
+	LocationPolicyMostAccurate = "most_accurate" // Most accurate fix within the accuracy window of the newest
+	LocationPolicyPerDevice    = "per_device"    // Most accurate of each device's latest fix within the window
+	DefaultLocationPolicy      = LocationPolicyNewest
+	DefaultAccuracyWindow      = 120 // Seconds
+	LatestPositionCandidates   = 50  // Recent fixes considered by accuracy-based policies
+
+	// How a historical position was derived from the recorded points around the requested time
+	PositionMethodExact        = "exact"        // A point was recorded at that time
+	PositionMethodInterpolated = "interpolated" // Between two points of the same track segment
+	PositionMethodNearest      = "nearest"      // The closest point, across a gap or at the end of a track
+	DefaultPositionMaxOffset   = 5 * time.Minute
+
+	// Stored coordinates are rounded; 7 decimal degrees are about 1 cm at the equator
+	CoordinatePrecision = 7
+	AltitudePrecision   = 2 // Decimal places of meters
+
+	// Sessions without new points for this long are ended by a background job
+	DefaultSessionInactivityTimeout = 24 * time.Hour
+	SessionCloseCheckInterval       = 15 * time.Minute
+
+	// Battery level in percent below which live trackers trigger a low battery alert
+	DefaultLowBatteryThreshold = 15.0
+
+	// Missed check-in alerts escalate from the session owner to the emergency contacts when the
+	// next check-in interval passes without a point as well
+	CheckInAlertNone     = 0
+	CheckInAlertOwner    = 1
+	CheckInAlertContacts = 2
+	CheckInCheckInterval = time.Minute
+	MinCheckInInterval   = 5    // Minutes
+	MaxCheckInInterval   = 1440 // Minutes
+	MaxCheckInContacts   = 5
+	MapLinkFormat        = "https://www.openstreetmap.org/?mlat=%[1]f&mlon=%[2]f#map=15/%[1]f/%[2]f"
+
+	// Tracked points repeating a stored point of their session within these limits are not
+	// stored again; zero limits only match identical timestamps and coordinates
+	DefaultDeduplicate       = true
+	DefaultDuplicateInterval = time.Duration(0)
+	DefaultDuplicateDistance = 0.0 // Meters
+	DuplicateCandidates      = 50  // Stored points within the interval compared with a new point
+
+	// Tracked points the previous point of their session could only reach faster than this are
+	// rejected as GPS glitches
+	DefaultOutlierMaxSpeed = 300.0 // km/h
+
+	// Points reported without speed or bearing get those of the travel from the previous point of
+	// their session, unless it is a gap away; closer points than this give no bearing
+	DefaultDeriveMotion = true
+	MinBearingDistance  = 5.0 // Meters
+
+	// AllowHistoricalParam is the /track query flag that accepts points older than the maximum age
+	AllowHistoricalParam = "allow_historical"
+
+	// IdempotencyKeyHeader identifies the point of a /track request, so a retried upload is stored once
+	IdempotencyKeyHeader = "Idempotency-Key"
+	MaxClientIDLength    = 100
+
+	// AutoSessionNameFormat names sessions started automatically after a tracking gap
+	AutoSessionNameFormat = "auto-20060102-1504"
+
+	// Environment variable names for tracking configuration
+	EnvWaypointVisitRadius = "WAYPOINT_VISIT_RADIUS"
+	EnvMaxTimestampAge     = "TRACKING_MAX_TIMESTAMP_AGE"
+	EnvMaxTimestampSkew    = "TRACKING_MAX_TIMESTAMP_SKEW"
+	EnvGapMaxInterval      = "TRACKING_GAP_MAX_INTERVAL"
+	EnvGapMaxDistance      = "TRACKING_GAP_MAX_DISTANCE"
+	EnvDeduplicate         = "TRACKING_DEDUPLICATE"
+	EnvDuplicateInterval   = "TRACKING_DUPLICATE_INTERVAL"
+	EnvDuplicateDistance   = "TRACKING_DUPLICATE_DISTANCE"
+	EnvOutlierMaxSpeed     = "TRACKING_OUTLIER_MAX_SPEED"
+	EnvDeriveMotion        = "TRACKING_DERIVE_MOTION"
+	EnvSessionInactivity   = "TRACKING_SESSION_INACTIVITY_TIMEOUT"
+	EnvLowBatteryThreshold = "TRACKING_LOW_BATTERY_THRESHOLD"
+	EnvReverseGeocodeURL   = "TRACKING_REVERSE_GEOCODE_URL"
+	EnvMapMatchURL         = "TRACKING_MAP_MATCH_URL"
+	EnvMapMatchProvider    = "TRACKING_MAP_MATCH_PROVIDER"
+	EnvMapMatchCosting     = "TRACKING_MAP_MATCH_COSTING"
+	EnvRoutingProvider     = "TRACKING_ROUTING_PROVIDER"
+	EnvRoutingURL          = "TRACKING_ROUTING_URL"
+	EnvElevationURL        = "TRACKING_ELEVATION_URL"
+	EnvWeatherURL          = "TRACKING_WEATHER_URL"
+	EnvWebhookURLs         = "WEBHOOK_URLS"
+	EnvWebhookSecret       = "WEBHOOK_SECRET"
+)
+
+// Outgoing webhooks
+const (
+	WebhookEventSessionEnded     = "session.ended"
+	WebhookEventSessionPublished = "session.published"
+	WebhookEventLowBattery       = "tracker.low_battery"
+	WebhookEventCheckInMissed    = "checkin.missed"
+
+	WebhookSignatureHeader = "X-Vibe-Signature" // "sha256=<hex HMAC of the body>" when a secret is configured
+	WebhookEventHeader     = "X-Vibe-Event"
+	WebhookTimeout         = 10 * time.Second
+)
@@ -0,0 +1,10 @@
+package constants
+
+import "time"
+
+// ClamAV virus scanning of uploaded files
+const (
+	ClamAVDialTimeout = 5 * time.Second
+	ClamAVScanTimeout = 60 * time.Second // Per file, including streaming it to clamd
+	ClamAVChunkSize   = 64 * 1024
+)
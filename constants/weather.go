@@ -0,0 +1,15 @@
+package constants
+
+import "time"
+
+// Weather forecasts along planned routes
+const (
+	WeatherSampleSpacing   = 10000.0 // Meters between forecast points along the route
+	WeatherMaxSamples      = 25      // Longer routes get wider spaced forecast points
+	DefaultPlanningSpeed   = 5.0     // Kilometers per hour assumed when none is given, a walking pace
+	WeatherForecastHorizon = 16 * 24 * time.Hour
+	WeatherTimeout         = 15 * time.Second
+
+	// Hourly Open-Meteo variables requested for each forecast point
+	WeatherHourlyVariables = "temperature_2m,precipitation_probability,precipitation,wind_speed_10m,wind_direction_10m,weather_code"
+)
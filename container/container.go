@@ -5,10 +5,14 @@ import (
 	"github.com/pocketbase/pocketbase"
 
 	"vibe-tracker/config"
+	"vibe-tracker/constants"
 	"vibe-tracker/handlers"
 	"vibe-tracker/middleware"
+	"vibe-tracker/models"
+	"vibe-tracker/plugins"
 	"vibe-tracker/repositories"
 	"vibe-tracker/services"
+	"vibe-tracker/utils"
 )
 
 // Container holds all application dependencies
@@ -28,25 +32,85 @@ type Container struct {
 	SessionService  *services.SessionService
 	LocationService *services.LocationService
 	HealthService   *services.HealthService
+	LiveService     *services.LiveService
+	TokenBlacklist  *middleware.TokenBlacklist
+	CaptchaVerifier *middleware.CaptchaVerifier
+
+	GeoIPService        *services.GeoIPService
+	LoginAnomalyService *services.LoginAnomalyService
+
+	WebhookService          *services.WebhookService
+	SessionCloserService    *services.SessionCloserService
+	CheckInService          *services.CheckInService
+	EmergencyContactService *services.EmergencyContactService
+	BatteryAlertService     *services.BatteryAlertService
+	ETAShareService         *services.ETAShareService
+	IngestSourceService     *services.IngestSourceService
+	TimelineService         *services.TimelineService
+	GeocodingService        *services.GeocodingService
+	SurfaceService          *services.SurfaceService
+	MapMatchService         *services.MapMatchService
+	RoutingService          *services.RoutingService
+	WeatherService          *services.WeatherService
+	FileCleanupService      *services.FileCleanupService
+	IngestService           *services.IngestService        // nil when write batching is off
+	HomeAssistantService    *services.HomeAssistantService // nil unless an MQTT broker and users are set
+
+	// Extension hooks of the registered plugins
+	PluginHooks *plugins.Hooks
 
 	// Handlers
-	AuthHandler     *handlers.AuthHandler
-	SessionHandler  *handlers.SessionHandler
-	TrackingHandler *handlers.TrackingHandler
-	PublicHandler   *handlers.PublicHandler
-	WaypointHandler *handlers.WaypointHandler
-	DocsHandler     *handlers.DocsHandler
-	HealthHandler   *handlers.HealthHandler
+	AuthHandler             *handlers.AuthHandler
+	SessionHandler          *handlers.SessionHandler
+	TrackingHandler         *handlers.TrackingHandler
+	PublicHandler           *handlers.PublicHandler
+	WaypointHandler         *handlers.WaypointHandler
+	CommunityHandler        *handlers.CommunityHandler
+	LiveHandler             *handlers.LiveHandler
+	OrganizationHandler     *handlers.OrganizationHandler
+	EmergencyContactHandler *handlers.EmergencyContactHandler
+	ETAShareHandler         *handlers.ETAShareHandler
+	IngestSourceHandler     *handlers.IngestSourceHandler
+	TimelineHandler         *handlers.TimelineHandler
+	CountryHandler          *handlers.CountryHandler
+	RouteHandler            *handlers.RouteHandler
+	FileHandler             *handlers.FileHandler
+	WeatherHandler          *handlers.WeatherHandler
+	ModerationHandler       *handlers.ModerationHandler
+	CaptchaHandler          *handlers.CaptchaHandler
+	BrandingHandler         *handlers.BrandingHandler
+	DiscoveryHandler        *handlers.DiscoveryHandler
+	LoggingHandler          *handlers.LoggingHandler
+	GraphQLHandler          *handlers.GraphQLHandler // nil unless the GraphQL endpoint is enabled
+	DocsHandler             *handlers.DocsHandler
+	HealthHandler           *handlers.HealthHandler
 
 	// Middleware
 	AuthMiddleware         *middleware.AuthMiddleware
 	UserMiddleware         *middleware.UserMiddleware
+	OrgMiddleware          *middleware.OrgMiddleware
+	GuestMiddleware        *middleware.GuestMiddleware
 	ErrorHandler           *middleware.ErrorHandler
+	ErrorReporter          *middleware.ErrorReporter  // nil when error reporting is disabled
+	LatencyMonitor         *middleware.LatencyMonitor // nil when no latency budget is set
 	ValidationMiddleware   *middleware.ValidationMiddleware
 	RateLimitMiddleware    *middleware.RateLimitMiddleware
 	SecurityMiddleware     *middleware.SecurityMiddleware
 	AuthSecurityMiddleware *middleware.AuthSecurityMiddleware
 	NotFoundProtection     *middleware.NotFoundProtection
+	ReadOnlyMiddleware     *middleware.ReadOnlyMiddleware // nil unless in read-only mode
+
+	// gRPC ingestion service on its own listener, nil unless a gRPC address is set
+	TrackingGRPCServer *handlers.TrackingGRPCServer
+
+	// Socket listeners of hardware trackers, nil unless a protocol address is set
+	HardwareTrackerListener *handlers.HardwareTrackerListener
+
+	// APRS-IS client tracking followed callsigns, nil unless callsigns are followed
+	APRSBridge *handlers.APRSBridge
+
+	// Poller of the users' Garmin MapShare feeds, nil when polling is disabled
+	MapShareImporter *handlers.MapShareImporter
 }
 
 // NewContainer creates a new dependency injection container
@@ -62,6 +126,16 @@ func NewContainer(app *pocketbase.PocketBase, cfg *config.AppConfig) *Container
 	container.initHandlers()
 	container.initMiddleware()
 
+	// The gRPC service stores points through the tracking handler and authenticates like it
+	container.TrackingGRPCServer = newTrackingGRPCServer(container.TrackingHandler, container.AuthMiddleware, cfg)
+	if len(cfg.Tracking.TrackerAddresses) > 0 {
+		container.HardwareTrackerListener = handlers.NewHardwareTrackerListener(container.TrackingHandler, cfg.Tracking.TrackerAddresses)
+	}
+	container.APRSBridge = newAPRSBridge(container.TrackingHandler, cfg)
+	if cfg.Tracking.MapSharePollInterval > 0 {
+		container.MapShareImporter = handlers.NewMapShareImporter(container.TrackingHandler, cfg.Tracking.MapSharePollInterval)
+	}
+
 	return container
 }
 
@@ -83,6 +157,51 @@ func (c *Container) initServices() {
 		c.SessionRepository,
 		c.SessionService,
 	)
+	if c.Config.Tracking.Deduplicate {
+		c.LocationService.WithDeduplication(utils.DuplicateThresholds{
+			MaxInterval: c.Config.Tracking.DuplicateInterval,
+			MaxDistance: c.Config.Tracking.DuplicateDistance,
+		})
+	}
+	if c.Config.Tracking.OutlierMaxSpeed > 0 {
+		maxSpeed, _ := utils.SpeedToMetersPerSecond(c.Config.Tracking.OutlierMaxSpeed, constants.SpeedUnitKilometersPerHr)
+		c.LocationService.WithOutlierFilter(utils.OutlierThresholds{MaxSpeed: maxSpeed})
+	}
+	if c.Config.Tracking.DeriveMotion {
+		c.LocationService.WithDerivedMotion(utils.GapThresholds{MaxInterval: c.Config.Tracking.GapMaxInterval, MaxDistance: c.Config.Tracking.GapMaxDistance})
+	}
+	c.LiveService = services.NewLiveService()
+	// Revoked device sessions, shared by the auth handlers and the auth middleware
+	c.TokenBlacklist = middleware.NewTokenBlacklist()
+	c.CaptchaVerifier = newCaptchaVerifier(c.Config.Security)
+	c.GeoIPService = services.NewGeoIPService(c.Config.Security.GeoIPLookupURL)
+	if c.Config.Security.EnableLoginAnomalyDetection {
+		c.LoginAnomalyService = services.NewLoginAnomalyService(c.App, c.GeoIPService)
+	}
+	c.WebhookService = services.NewWebhookService(c.Config.Tracking.WebhookURLs, c.Config.Tracking.WebhookSecret)
+	c.PluginHooks = plugins.NewHooks()
+	c.SessionCloserService = services.NewSessionCloserService(c.App, &c.Config.Tracking, c.WebhookService).
+		WithPluginHooks(c.PluginHooks)
+	c.EmergencyContactService = services.NewEmergencyContactService(c.App)
+	c.CheckInService = services.NewCheckInService(c.App, c.WebhookService, c.EmergencyContactService)
+	c.BatteryAlertService = services.NewBatteryAlertService(c.App, &c.Config.Tracking, c.WebhookService)
+	c.ETAShareService = services.NewETAShareService(c.App)
+	c.IngestSourceService = services.NewIngestSourceService(c.App)
+	if c.Config.ReadOnly {
+		c.IngestSourceService.WithReadOnly()
+	}
+	c.TimelineService = services.NewTimelineService(c.App)
+	c.GeocodingService = services.NewGeocodingService(c.App, c.Config.Tracking.ReverseGeocodeURL)
+	c.SurfaceService = services.NewSurfaceService(c.App, &c.Config.Tracking)
+	c.MapMatchService = services.NewMapMatchService(c.App, &c.Config.Tracking)
+	c.RoutingService = services.NewRoutingService(&c.Config.Tracking)
+	c.WeatherService = services.NewWeatherService(c.App, c.Config.Tracking.WeatherURL)
+	c.FileCleanupService = services.NewFileCleanupService(c.App, c.Config.DeleteOrphanedFiles)
+	c.IngestService = newIngestService(c.App, &c.Config.Tracking)
+	if c.IngestService != nil {
+		c.LocationService.WithPendingWritesFlusher(c.IngestService.FlushUser)
+	}
+	c.HomeAssistantService = newHomeAssistantService(c.App, c.Config.HomeAssistant)
 	c.HealthService = services.NewHealthService(
 		c.App,
 		c.UserRepository,
@@ -94,27 +213,73 @@ func (c *Container) initServices() {
 		c.LocationService,
 		c.Config.Health.CacheTTL,
 		c.Config.Health.DBTimeout,
-	)
+	).WithIngestService(c.IngestService)
 }
 
 // initHandlers initializes all handler dependencies
 func (c *Container) initHandlers() {
-	c.AuthHandler = handlers.NewAuthHandler(c.App, c.AuthService)
-	c.SessionHandler = handlers.NewSessionHandler(c.App, c.SessionService)
-	c.TrackingHandler = handlers.NewTrackingHandler(c.App, c.LocationService)
-	c.PublicHandler = handlers.NewPublicHandler(c.App, c.LocationService, c.UserService)
-	c.WaypointHandler = handlers.NewWaypointHandler(c.App)
+	c.AuthHandler = handlers.NewAuthHandler(c.App, c.AuthService, c.UserService, c.LoginAnomalyService, c.TokenBlacklist)
+	c.SessionHandler = handlers.NewSessionHandler(c.App, c.SessionService, c.CheckInService, c.MapMatchService).
+		WithWebhooks(c.WebhookService).
+		WithPluginHooks(c.PluginHooks).
+		WithGapThresholds(utils.GapThresholds{MaxInterval: c.Config.Tracking.GapMaxInterval, MaxDistance: c.Config.Tracking.GapMaxDistance}).
+		WithSimplification(c.Config.Tracking.Simplification)
+	c.TrackingHandler = handlers.NewTrackingHandler(c.App, c.LocationService, c.BatteryAlertService, &c.Config.Tracking).
+		WithIngestService(c.IngestService)
+	c.PublicHandler = handlers.NewPublicHandler(c.App, c.LocationService, c.UserService, &c.Config.Tracking)
+	c.WaypointHandler = handlers.NewWaypointHandler(c.App).
+		WithGapThresholds(utils.GapThresholds{MaxInterval: c.Config.Tracking.GapMaxInterval, MaxDistance: c.Config.Tracking.GapMaxDistance})
+	c.CommunityHandler = handlers.NewCommunityHandler(c.App)
+	c.LiveHandler = handlers.NewLiveHandler(c.App, c.LiveService)
+	c.OrganizationHandler = handlers.NewOrganizationHandler(c.App)
+	c.EmergencyContactHandler = handlers.NewEmergencyContactHandler(c.App, c.EmergencyContactService)
+	c.ETAShareHandler = handlers.NewETAShareHandler(c.App, c.ETAShareService)
+	c.IngestSourceHandler = handlers.NewIngestSourceHandler(c.App, c.IngestSourceService, c.TrackingHandler)
+	c.TimelineHandler = handlers.NewTimelineHandler(c.App, c.TimelineService).
+		WithGapThresholds(utils.GapThresholds{MaxInterval: c.Config.Tracking.GapMaxInterval, MaxDistance: c.Config.Tracking.GapMaxDistance})
+	c.CountryHandler = handlers.NewCountryHandler(c.App, c.GeocodingService)
+	c.RouteHandler = handlers.NewRouteHandler(c.App, c.RoutingService)
+	c.FileHandler = handlers.NewFileHandler(c.App).
+		WithFileCleanupService(c.FileCleanupService)
+	c.WeatherHandler = handlers.NewWeatherHandler(c.App, c.WeatherService)
+	c.ModerationHandler = handlers.NewModerationHandler(c.App)
+	c.CaptchaHandler = handlers.NewCaptchaHandler(c.CaptchaVerifier)
+	c.BrandingHandler = handlers.NewBrandingHandler(c.Config)
+	c.DiscoveryHandler = handlers.NewDiscoveryHandler(c.Config)
+	c.LoggingHandler = handlers.NewLoggingHandler()
+	c.GraphQLHandler = newGraphQLHandler(c.App, c.Config)
 	c.DocsHandler = handlers.NewDocsHandler(c.App)
 	c.HealthHandler = handlers.NewHealthHandler(c.App, c.HealthService, &c.Config.Health)
 }
 
 // initMiddleware initializes all middleware dependencies
 func (c *Container) initMiddleware() {
-	c.AuthMiddleware = middleware.NewAuthMiddleware(c.App)
+	c.AuthMiddleware = middleware.NewAuthMiddleware(c.App, c.TokenBlacklist)
 	c.UserMiddleware = middleware.NewUserMiddleware(c.App)
-	c.ErrorHandler = middleware.NewErrorHandler()
+	if c.IngestService != nil {
+		c.UserMiddleware.WithPendingWritesFlusher(c.IngestService.FlushUser)
+	}
+	c.OrgMiddleware = middleware.NewOrgMiddleware(c.App)
+	c.GuestMiddleware = middleware.NewGuestMiddleware(c.App)
+	c.ErrorReporter = newErrorReporter(c.Config.ErrorReporting)
+	c.ErrorHandler = middleware.NewErrorHandler().WithErrorReporter(c.ErrorReporter)
 	c.ValidationMiddleware = middleware.NewValidationMiddleware()
 
+	// Routes staying over their latency budget are reported to the webhook endpoints
+	c.LatencyMonitor = newLatencyMonitor(c.Config.Monitoring)
+	if c.LatencyMonitor != nil {
+		c.LatencyMonitor.WithAlertHandler(func(event models.LatencyBudgetEvent) {
+			c.WebhookService.Send(constants.WebhookEventLatencyBudget, event)
+		})
+	}
+
+	if c.Config.ReadOnly {
+		c.ReadOnlyMiddleware = middleware.NewReadOnlyMiddleware(c.Config.ReadOnlyMessage)
+		// Bookkeeping writes of reads are skipped as well
+		c.AuthMiddleware.WithReadOnly()
+		c.OrgMiddleware.WithReadOnly()
+	}
+
 	// Security middleware
 	if c.Config.Security.EnableRateLimiting {
 		c.RateLimitMiddleware = middleware.NewRateLimitMiddleware()
@@ -124,14 +289,20 @@ func (c *Container) initMiddleware() {
 		c.Config.Security.MaxRequestSize,
 		c.Config.Security.RequestTimeout,
 		c.Config.Security.EnableRequestLogs,
-	)
+	).WithVirusScanner(newVirusScanner(c.Config.Security))
 
-	if c.Config.Security.EnableBruteForceProtection {
+	// The CAPTCHA is enforced by the auth security middleware, which then counts failed logins even
+	// when lockouts are disabled
+	if c.Config.Security.EnableBruteForceProtection || c.CaptchaVerifier != nil {
+		failedLoginThreshold := c.Config.Security.FailedLoginThreshold
+		if !c.Config.Security.EnableBruteForceProtection {
+			failedLoginThreshold = 0
+		}
 		c.AuthSecurityMiddleware = middleware.NewAuthSecurityMiddleware(
-			c.Config.Security.FailedLoginThreshold,
+			failedLoginThreshold,
 			c.Config.Security.AccountLockoutDuration,
 			c.Config.Security.EnableRequestLogs,
-		)
+		).WithCaptcha(c.CaptchaVerifier, c.Config.Security.CaptchaAfterFailures)
 	}
 
 	// 404 Protection middleware
@@ -148,6 +319,101 @@ func (c *Container) initMiddleware() {
 	}
 }
 
+// newCaptchaVerifier creates the CAPTCHA verifier for the configured provider, or nil when disabled
+func newCaptchaVerifier(cfg config.SecurityConfig) *middleware.CaptchaVerifier {
+	switch cfg.CaptchaProvider {
+	case "":
+		return nil
+	case constants.CaptchaProviderHCaptcha, constants.CaptchaProviderTurnstile, constants.CaptchaProviderPoW:
+		return middleware.NewCaptchaVerifier(middleware.CaptchaConfig{
+			Provider:      cfg.CaptchaProvider,
+			SiteKey:       cfg.CaptchaSiteKey,
+			Secret:        cfg.CaptchaSecret,
+			PoWDifficulty: cfg.PoWDifficulty,
+		})
+	default:
+		utils.LogWarn().Str("provider", cfg.CaptchaProvider).Msg("Unknown CAPTCHA provider, CAPTCHA disabled")
+		return nil
+	}
+}
+
+// newVirusScanner creates the ClamAV scanner for uploaded files, or nil when scanning is disabled
+func newVirusScanner(cfg config.SecurityConfig) *middleware.VirusScanner {
+	if cfg.ClamAVAddress == "" {
+		return nil
+	}
+
+	scanner, err := middleware.NewVirusScanner(cfg.ClamAVAddress, cfg.ClamAVFailOpen)
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Invalid ClamAV address, upload virus scanning disabled")
+		return nil
+	}
+	return scanner
+}
+
+// newErrorReporter creates the error reporter, or returns nil when error reporting is disabled or
+// the DSN is invalid
+func newErrorReporter(cfg config.ErrorReportingConfig) *middleware.ErrorReporter {
+	if cfg.SentryDSN == "" {
+		return nil
+	}
+
+	reporter, err := middleware.NewErrorReporter(cfg.SentryDSN, cfg.Environment)
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Invalid Sentry DSN, error reporting disabled")
+		return nil
+	}
+	return reporter
+}
+
+// newLatencyMonitor creates the latency monitor, or returns nil when no route has a budget.
+// Invalid route budgets are skipped.
+func newLatencyMonitor(cfg config.MonitoringConfig) *middleware.LatencyMonitor {
+	routeBudgets, err := utils.ParseRouteBudgets(cfg.LatencyRouteBudgets)
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Invalid route latency budgets ignored")
+		routeBudgets = nil
+	}
+	if cfg.LatencyBudget <= 0 && len(routeBudgets) == 0 {
+		return nil
+	}
+
+	return middleware.NewLatencyMonitor(middleware.LatencyBudgetConfig{
+		DefaultBudget: cfg.LatencyBudget,
+		RouteBudgets:  routeBudgets,
+		AlertAfter:    cfg.LatencyAlertAfter,
+	})
+}
+
+// newGraphQLHandler creates the GraphQL handler, or returns nil when the endpoint is disabled or its
+// schema cannot be built
+func newGraphQLHandler(app *pocketbase.PocketBase, cfg *config.AppConfig) *handlers.GraphQLHandler {
+	if !cfg.GraphQLEnabled {
+		return nil
+	}
+
+	handler, err := handlers.NewGraphQLHandler(app, &cfg.Tracking)
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Invalid GraphQL schema, GraphQL endpoint disabled")
+		return nil
+	}
+	return handler
+}
+
+// newIngestService creates the write batching service, or returns nil when batching is off or the
+// mode is unknown
+func newIngestService(app *pocketbase.PocketBase, cfg *config.TrackingConfig) *services.IngestService {
+	switch cfg.IngestMode {
+	case constants.IngestModeOff, "":
+		return nil
+	case constants.IngestModeSync, constants.IngestModeAsync:
+		return services.NewIngestService(app, cfg)
+	default:
+		utils.LogWarn().Str("mode", cfg.IngestMode).Msg("Unknown ingest batch mode, write batching disabled")
+		return nil
+	}
+}
+
 // GetRepositories returns all repositories for testing purposes
 func (c *Container) GetRepositories() (repositories.UserRepository, repositories.SessionRepository, repositories.LocationRepository) {
 	return c.UserRepository, c.SessionRepository, c.LocationRepository
@@ -179,3 +445,48 @@ func (c *Container) InjectMiddleware() echo.MiddlewareFunc {
 		}
 	}
 }
+
+// newTrackingGRPCServer creates the gRPC ingestion server, or returns nil when no address is set or
+// the TLS files cannot be loaded
+func newTrackingGRPCServer(tracking *handlers.TrackingHandler, auth *middleware.AuthMiddleware, cfg *config.AppConfig) *handlers.TrackingGRPCServer {
+	if cfg.Tracking.GRPCAddress == "" {
+		return nil
+	}
+
+	server, err := handlers.NewTrackingGRPCServer(tracking, auth, &cfg.Tracking)
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Failed to load gRPC TLS certificate, gRPC ingestion disabled")
+		return nil
+	}
+	if cfg.ReadOnly {
+		server.WithReadOnly(cfg.ReadOnlyMessage)
+	}
+	return server
+}
+
+// newAPRSBridge creates the APRS-IS bridge of the followed callsigns; invalid entries disable it
+func newAPRSBridge(tracking *handlers.TrackingHandler, cfg *config.AppConfig) *handlers.APRSBridge {
+	follows, err := utils.ParseAPRSFollows(cfg.Tracking.APRSFollow)
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Invalid APRS_FOLLOW, APRS bridge disabled")
+		return nil
+	}
+	if len(follows) == 0 {
+		return nil
+	}
+	return handlers.NewAPRSBridge(tracking, cfg.Tracking.APRSServer, cfg.Tracking.APRSLogin, follows)
+}
+
+// newHomeAssistantService creates the Home Assistant MQTT publisher, or nil when no broker or no
+// users are configured; an invalid broker URL disables it
+func newHomeAssistantService(app *pocketbase.PocketBase, cfg config.HomeAssistantConfig) *services.HomeAssistantService {
+	if cfg.MQTTURL == "" || len(cfg.Users) == 0 {
+		return nil
+	}
+	broker, err := utils.ParseMQTTURL(cfg.MQTTURL)
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Invalid HA_MQTT_URL, Home Assistant publisher disabled")
+		return nil
+	}
+	return services.NewHomeAssistantService(app, broker, cfg)
+}
@@ -21,45 +21,74 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/refresh": {
-            "post": {
-                "description": "Refresh an expired JWT token using refresh token",
-                "consumes": [
-                    "application/json"
-                ],
+        "/.well-known/vibe-tracker.json": {
+            "get": {
+                "description": "Describes the API base, the supported tracking protocols, authentication methods and server version, so mobile clients can set up from the server URL alone. Served outside the API prefix without the response envelope.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Public"
                 ],
-                "summary": "Refresh JWT token",
-                "parameters": [
-                    {
-                        "description": "Refresh token request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                "summary": "Get client configuration",
+                "responses": {
+                    "200": {
+                        "description": "Client configuration",
                         "schema": {
-                            "$ref": "#/definitions/models.RefreshTokenRequest"
+                            "$ref": "#/definitions/models.ClientConfigDocument"
                         }
                     }
+                }
+            }
+        },
+        "/admin/files/orphans": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Scans the file storage (local or S3) for files that no record refers to anymore: files of deleted collections or records, files their record no longer lists and GPX uploads of deleted sessions. Files uploaded within the last hour are skipped. Nothing is deleted; the daily scan deletes orphans when DELETE_ORPHANED_FILES is set. Requires the system:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
                 ],
+                "summary": "List orphaned files",
                 "responses": {
                     "200": {
-                        "description": "Token refreshed successfully",
+                        "description": "Orphaned files retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.OrphanedFilesReport"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "401": {
+                        "description": "Authentication required",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Invalid refresh token",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -67,62 +96,61 @@ const docTemplate = `{
                 }
             }
         },
-        "/location/{username}": {
+        "/admin/log-levels": {
             "get": {
-                "description": "Returns the latest location data for the specified user",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the default log level and the level overrides of modules; requires the system:manage permission",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Public"
-                ],
-                "summary": "Get user location",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Username",
-                        "name": "username",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Session name filter",
-                        "name": "session",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of locations to return (default: 50)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "ISO timestamp to filter locations since",
-                        "name": "since",
-                        "in": "query"
-                    }
+                    "Admin"
                 ],
+                "summary": "Get log levels",
                 "responses": {
                     "200": {
-                        "description": "Location data retrieved successfully",
+                        "description": "Log levels retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.LogLevels"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/login": {
-            "post": {
-                "description": "Authenticate user with email and password",
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the default log level, or the level of a module such as http or security. Changes are not persisted, the configured levels apply again after a restart. Requires the system:manage permission.",
                 "consumes": [
                     "application/json"
                 ],
@@ -130,25 +158,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin"
                 ],
-                "summary": "User login",
+                "summary": "Change log level",
                 "parameters": [
                     {
-                        "description": "Login credentials",
+                        "description": "Module and level",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LoginRequest"
+                            "$ref": "#/definitions/models.UpdateLogLevelRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login successful",
+                        "description": "Log level updated successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.LogLevels"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -158,7 +198,13 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Invalid credentials",
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -166,26 +212,73 @@ const docTemplate = `{
                 }
             }
         },
-        "/me": {
+        "/admin/reports": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Returns the authenticated user's profile information",
+                "description": "Returns reports for review, oldest first; requires the reports:review permission",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Moderation"
+                ],
+                "summary": "List reports",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report status (pending, dismissed, actioned; default: pending)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content type filter (session, waypoint, community_waypoint)",
+                        "name": "target_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 50, max: 100)",
+                        "name": "perPage",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get current user profile",
                 "responses": {
                     "200": {
-                        "description": "User profile retrieved successfully",
+                        "description": "Reports retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.PaginatedResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Report"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "401": {
@@ -193,18 +286,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/profile": {
+        "/admin/reports/{id}": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates the authenticated user's profile information",
+                "description": "Dismisses the reports and restores the content, or confirms them and keeps the content hidden from public feeds; requires the reports:review permission",
                 "consumes": [
                     "application/json"
                 ],
@@ -212,25 +311,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Moderation"
                 ],
-                "summary": "Update user profile",
+                "summary": "Review report",
                 "parameters": [
                     {
-                        "description": "Profile update data",
+                        "type": "string",
+                        "description": "Report ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review decision",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateProfileRequest"
+                            "$ref": "#/definitions/models.ReviewReportRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Profile updated successfully",
+                        "description": "Report reviewed successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Report"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -244,20 +362,64 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/profile/avatar": {
+        "/auth/captcha": {
+            "get": {
+                "description": "Returns the CAPTCHA provider and site key for hCaptcha or Turnstile, or a new challenge for the built-in proof-of-work. The solution is sent in the X-Captcha-Token header on registration, password reset and logins after repeated failures.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Get CAPTCHA challenge",
+                "responses": {
+                    "200": {
+                        "description": "CAPTCHA challenge",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.CaptchaChallenge"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reauth": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Uploads a new avatar image for the authenticated user",
+                "description": "Confirms the password for the requesting device session. Sensitive actions such as token regeneration are refused with 403 and reauth_required for a while after a login from a new country or network until the password is confirmed.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
@@ -265,31 +427,33 @@ const docTemplate = `{
                 "tags": [
                     "Authentication"
                 ],
-                "summary": "Upload user avatar",
+                "summary": "Re-authenticate",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Avatar image file",
-                        "name": "avatar",
-                        "in": "formData",
-                        "required": true
+                        "description": "Current password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReauthRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Avatar uploaded successfully",
+                        "description": "Re-authentication successful",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid file or request",
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Authentication required",
+                        "description": "Invalid credentials",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -297,36 +461,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/profile/regenerate-token": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/refresh": {
+            "post": {
+                "description": "Refresh an expired JWT token using refresh token",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Generates a new custom token for location tracking",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Authentication"
                 ],
-                "summary": "Regenerate custom token",
+                "summary": "Refresh JWT token",
+                "parameters": [
+                    {
+                        "description": "Refresh token request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshTokenRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Token regenerated successfully",
+                        "description": "Token refreshed successfully",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
-                    "401": {
-                        "description": "Authentication required",
+                    "400": {
+                        "description": "Invalid request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Invalid refresh token",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -334,90 +507,85 @@ const docTemplate = `{
                 }
             }
         },
-        "/public-location": {
+        "/capabilities": {
             "get": {
-                "description": "Returns public location data from all users in GeoJSON format",
+                "description": "Returns the server and API version, the newest schema migration, the optional modules enabled by the configuration and the limits enforced on requests: request and upload sizes, page size, the oldest accepted timestamp and the rate limits per endpoint group. Tools such as uploaders can adapt their behavior instead of hard-coding assumptions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Public"
                 ],
-                "summary": "Get public locations",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Number of locations to return (default: 1000)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "ISO timestamp to filter locations since",
-                        "name": "since",
-                        "in": "query"
-                    }
-                ],
+                "summary": "Get server capabilities",
                 "responses": {
                     "200": {
-                        "description": "Public locations retrieved successfully",
+                        "description": "Server capabilities",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.CapabilitiesResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/session/{username}/{session}": {
+        "/community/waypoints": {
             "get": {
-                "description": "Returns location data for a specific user session in GeoJSON format",
+                "description": "Returns visible community waypoints inside the bounding box as a GeoJSON FeatureCollection",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Public"
+                    "Community"
                 ],
-                "summary": "Get session data",
+                "summary": "List community waypoints",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Username",
-                        "name": "username",
-                        "in": "path",
+                        "description": "Bounding box as minLon,minLat,maxLon,maxLat",
+                        "name": "bbox",
+                        "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Session name",
-                        "name": "session",
-                        "in": "path",
-                        "required": true
+                        "description": "Waypoint type filter",
+                        "name": "type",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Session data retrieved successfully",
+                        "description": "Community waypoints retrieved successfully",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
-                    "404": {
-                        "description": "User or session not found",
+                    "400": {
+                        "description": "Invalid bounding box",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/sessions": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Creates a new tracking session for the authenticated user",
+                "description": "Publishes a copy of an owned waypoint to the community layer with attribution to the user",
                 "consumes": [
                     "application/json"
                 ],
@@ -425,23 +593,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Sessions"
+                    "Community"
                 ],
-                "summary": "Create session",
+                "summary": "Publish community waypoint",
                 "parameters": [
                     {
-                        "description": "Session data",
+                        "description": "Waypoint to publish",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateSessionRequest"
+                            "$ref": "#/definitions/models.PublishCommunityWaypointRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Session created successfully",
+                        "description": "Waypoint published successfully",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
@@ -458,8 +626,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Session already exists",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Waypoint not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -467,101 +641,66 @@ const docTemplate = `{
                 }
             }
         },
-        "/sessions/{username}": {
-            "get": {
-                "description": "Returns a paginated list of sessions for the specified user",
+        "/community/waypoints/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a published waypoint from the community layer; moderators may remove any entry",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Sessions"
+                    "Community"
                 ],
-                "summary": "List user sessions",
+                "summary": "Unpublish community waypoint",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Username",
-                        "name": "username",
+                        "description": "Community waypoint ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page (default: 20, max: 100)",
-                        "name": "per_page",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Sessions retrieved successfully",
+                        "description": "Waypoint unpublished successfully",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "401": {
+                        "description": "Authentication required",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/sessions/{username}/{name}": {
-            "get": {
-                "description": "Returns a specific session by username and session name",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Sessions"
-                ],
-                "summary": "Get user session",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Username",
-                        "name": "username",
-                        "in": "path",
-                        "required": true
                     },
-                    {
-                        "type": "string",
-                        "description": "Session name",
-                        "name": "name",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Session retrieved successfully",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Session not found",
+                        "description": "Community waypoint not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/community/waypoints/{id}/flag": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates an existing session for the authenticated user",
+                "description": "Flags a community waypoint for moderation; waypoints with enough flags are hidden automatically",
                 "consumes": [
                     "application/json"
                 ],
@@ -569,37 +708,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Sessions"
+                    "Community"
                 ],
-                "summary": "Update session",
+                "summary": "Flag community waypoint",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Username",
-                        "name": "username",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Session name",
-                        "name": "name",
+                        "description": "Community waypoint ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated session data",
+                        "description": "Flag reason",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateSessionRequest"
+                            "$ref": "#/definitions/models.FlagCommunityWaypointRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Session updated successfully",
+                        "description": "Waypoint flagged successfully",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
@@ -616,71 +748,87 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Community waypoint not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Session not found",
+                    "409": {
+                        "description": "Already flagged",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+            }
+        },
+        "/config/branding": {
+            "get": {
+                "description": "Returns the instance name, logo, initial map view, tile provider and the optional features enabled by the server configuration, so clients can configure themselves per instance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Public"
                 ],
-                "description": "Deletes an existing session for the authenticated user",
+                "summary": "Get instance branding",
+                "responses": {
+                    "200": {
+                        "description": "Instance branding",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.BrandingResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/emergency-contacts/verify/{token}": {
+            "get": {
+                "description": "Confirms the email address of an emergency contact with the token from its verification email, after which it is alerted",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Sessions"
+                    "Emergency Contacts"
                 ],
-                "summary": "Delete session",
+                "summary": "Verify emergency contact",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Username",
-                        "name": "username",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Session name",
-                        "name": "name",
+                        "description": "Verification token",
+                        "name": "token",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Session deleted successfully",
+                        "description": "Emergency contact verified",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
-                    "401": {
-                        "description": "Authentication required",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
+                    "400": {
+                        "description": "Verification link expired",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Session not found",
+                        "description": "Verification link not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -688,100 +836,62 @@ const docTemplate = `{
                 }
             }
         },
-        "/track": {
+        "/eta/{token}": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    },
-                    {
-                        "TokenAuth": []
-                    }
-                ],
-                "description": "Tracks user location using GET request with query parameters",
+                "description": "Returns the latest position of the sharing user, the remaining distance to the destination and the estimated arrival while the share is active. Arrived shares only report the arrival time, expired shares respond with 410.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tracking"
+                    "ETA Shares"
                 ],
-                "summary": "Track location (GET)",
+                "summary": "View ETA share",
                 "parameters": [
-                    {
-                        "type": "number",
-                        "format": "float64",
-                        "description": "Latitude (-90 to 90)",
-                        "name": "lat",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "format": "float64",
-                        "description": "Longitude (-180 to 180)",
-                        "name": "lon",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "number",
-                        "format": "float64",
-                        "description": "Altitude in meters",
-                        "name": "alt",
-                        "in": "query"
-                    },
-                    {
-                        "type": "number",
-                        "format": "float64",
-                        "description": "Speed in m/s",
-                        "name": "speed",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "format": "int64",
-                        "description": "Unix timestamp",
-                        "name": "timestamp",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Session name",
-                        "name": "session",
-                        "in": "query"
+                        "description": "ETA share token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Location tracked successfully",
+                        "description": "ETA share retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ETAShareView"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "404": {
+                        "description": "ETA share not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Authentication required",
+                    "410": {
+                        "description": "ETA share expired",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/graphql": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    },
-                    {
-                        "TokenAuth": []
-                    }
-                ],
-                "description": "Tracks user location using POST request with JSON payload",
+                "description": "Executes a GraphQL query against sessions with their locations, waypoints and statistics. Access rules are the same as for the REST endpoints; errors are returned in the \"errors\" member of the GraphQL response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -789,18 +899,239 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tracking"
+                    "Public"
                 ],
-                "summary": "Track location (POST)",
+                "summary": "GraphQL query",
                 "parameters": [
                     {
-                        "description": "Location data",
+                        "description": "GraphQL query, variables and operation name",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LocationRequest"
+                            "$ref": "#/definitions/models.GraphQLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Query executed, possibly with errors",
+                        "schema": {
+                            "$ref": "#/definitions/models.GraphQLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing query",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Returns comprehensive health information including component status and metrics",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Get detailed application health status",
+                "responses": {
+                    "200": {
+                        "description": "Detailed health information",
+                        "schema": {
+                            "$ref": "#/definitions/models.DetailedHealthResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Application has health issues",
+                        "schema": {
+                            "$ref": "#/definitions/models.DetailedHealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Returns basic liveness information indicating the application is running",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Get application liveness status",
+                "responses": {
+                    "200": {
+                        "description": "Application is alive",
+                        "schema": {
+                            "$ref": "#/definitions/models.LivenessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Returns readiness information indicating the application is ready to serve traffic",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Get application readiness status",
+                "responses": {
+                    "200": {
+                        "description": "Application is ready",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Application is not ready",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReadinessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ingest/webhook/{source_id}": {
+            "post": {
+                "description": "Tracks the location in a JSON body posted by a third-party service such as IFTTT or Home Assistant. The point is built with the field mapping of the source; points without a mapped session go to the source's session, or the automatic session. The source secret is sent in the X-Webhook-Secret header or the secret query parameter.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tracking"
+                ],
+                "summary": "Receive ingest webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ingest source ID",
+                        "name": "source_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Source secret, unless sent in the X-Webhook-Secret header",
+                        "name": "secret",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Accept timestamps older than the configured maximum age",
+                        "name": "allow_historical",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Location tracked successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body or mapped point",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid ingest source or secret",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The owner may not track",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Body too large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/integrations/home-assistant/webhook": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "TokenAuth": []
+                    }
+                ],
+                "description": "Tracks a location sent in the format of Home Assistant's mobile_app \"update_location\" webhooks, so a Home Assistant automation can forward device_tracker updates unchanged. Updates carry no timestamp and are recorded at the time they are received; the zone in location_name is tracked as the point status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tracking"
+                ],
+                "summary": "Track location (Home Assistant)",
+                "parameters": [
+                    {
+                        "description": "Location update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.HomeAssistantWebhookRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "session",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reporting device identifier",
+                        "name": "device",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -824,197 +1155,9037 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CreateSessionRequest": {
+        },
+        "/integrations/osmand": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "TokenAuth": []
+                    }
+                ],
+                "description": "Tracks a position sent with the osmAnd protocol of Traccar, as the Traccar Client apps, the OsmAnd online tracking plugin and many GPS trackers send it, as GET or POST query parameters. Devices that can only be configured with a server URL and a device ID use the tracking token as the id; otherwise id is stored as the device and the request is authenticated like /api/track. The speed is in knots unless speed_unit is set; an alarm is tracked as an alarm event.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tracking"
+                ],
+                "summary": "Track location (osmAnd/Traccar)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID, or the tracking token",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Position as latitude,longitude, instead of lat and lon",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unix seconds or milliseconds, or a UTC date",
+                        "name": "timestamp",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Altitude in meters",
+                        "name": "altitude",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Speed",
+                        "name": "speed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit of the speed (default: knots)",
+                        "name": "speed_unit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Accuracy in meters",
+                        "name": "accuracy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Battery level in percent",
+                        "name": "batt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Alarm reported by the device",
+                        "name": "alarm",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "session",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Accept timestamps older than the configured maximum age",
+                        "name": "allow_historical",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Location tracked successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/integrations/owntracks": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "TokenAuth": []
+                    }
+                ],
+                "description": "Accepts the messages the OwnTracks apps post in HTTP mode, so they can report to the tracker without a translation proxy. Location messages are tracked, other messages such as transitions and waypoints are acknowledged and ignored. The tracking token is sent as the token query parameter, the Authorization header or the password of HTTP basic authentication. Points go to the session query parameter, else to a session named after the device (the X-Limit-D header, the last level of the topic or the tracker ID). The response is the empty JSON array the apps expect.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tracking"
+                ],
+                "summary": "Track location (OwnTracks)",
+                "parameters": [
+                    {
+                        "description": "OwnTracks message",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OwnTracksMessage"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "session",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Accept timestamps older than the configured maximum age",
+                        "name": "allow_historical",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Message accepted",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or encrypted message",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/location/{username}": {
+            "get": {
+                "description": "Returns the current location of the specified user, chosen among recent fixes by the user's location policy (newest, most_accurate or per_device)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "Get user location",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name filter",
+                        "name": "session",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of locations to return (default: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO timestamp to filter locations since",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Location data retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Authenticate user with email and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the authenticated user's profile information",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Get current user profile",
+                "responses": {
+                    "200": {
+                        "description": "User profile retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the organizations the authenticated user is a member of, with their role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List my organizations",
+                "responses": {
+                    "200": {
+                        "description": "Organizations retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates an organization; the authenticated user becomes its owner",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create organization",
+                "parameters": [
+                    {
+                        "description": "Organization data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Organization created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug already taken",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns an organization the caller belongs to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Organization retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient organization role",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org}/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the organization's API keys without their secret values (admin or owner required, not available to organization API keys)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List organization API keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "API keys retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient organization role",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates an API key scoped to the organization; the key is only returned once (admin or owner required, not available to organization API keys)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create organization API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "API key data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateOrganizationAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "API key created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient organization role",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org}/api-keys/{keyId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes an organization API key (admin or owner required, not available to organization API keys)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Revoke organization API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "API key revoked successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient organization role",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "API key not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org}/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the members of an organization with their roles",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List organization members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Members retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient organization role",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a user to the organization with the given role (admin or owner required, not available to organization API keys; only owners can add owners)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Add organization member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddOrganizationMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Member added successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient organization role",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already a member",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org}/members/{userId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes a member's role (admin or owner required, not available to organization API keys; only owners can grant or revoke ownership)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Update organization member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member user ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateOrganizationMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Member updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient organization role",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Member not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a member from the organization (admin or owner required, not available to organization API keys; only owners can remove owners)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Remove organization member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member user ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Member removed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Cannot remove the last owner",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient organization role",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Member not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orgs/{org}/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the sessions (events) run by the organization, including private ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "List organization sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sessions retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient organization role",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a session owned by the organization and recorded by the given member's tracker (admin or owner required)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create organization session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization slug",
+                        "name": "org",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Session data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateOrganizationSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Session created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Insufficient organization role",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Member not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the authenticated user's profile information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Update user profile",
+                "parameters": [
+                    {
+                        "description": "Profile update data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/avatar": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Uploads a new avatar image for the authenticated user. The image is cropped to a square, scaled down and stored as PNG; only the avatar field of the user is changed.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Upload user avatar",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Avatar image file",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Avatar uploaded successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid file, image dimensions or request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/emergency-contacts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the authenticated user's emergency contacts and whether they confirmed their email address",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emergency Contacts"
+                ],
+                "summary": "List emergency contacts",
+                "responses": {
+                    "200": {
+                        "description": "Emergency contacts retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.EmergencyContact"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds an emergency contact alerted by missed check-ins. The contact is emailed a verification link and is only alerted after confirming it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emergency Contacts"
+                ],
+                "summary": "Add emergency contact",
+                "parameters": [
+                    {
+                        "description": "Contact data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmergencyContactRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Emergency contact added successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.EmergencyContact"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or too many contacts",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Contact already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/emergency-contacts/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates an emergency contact; changing the email address requires the contact to verify it again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emergency Contacts"
+                ],
+                "summary": "Update emergency contact",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Emergency contact ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Contact data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmergencyContactRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Emergency contact updated successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.EmergencyContact"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Emergency contact not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes an emergency contact; it is no longer alerted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emergency Contacts"
+                ],
+                "summary": "Delete emergency contact",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Emergency contact ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Emergency contact deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Emergency contact not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/emergency-contacts/{id}/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sends a new verification email to an emergency contact that has not confirmed its email address yet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emergency Contacts"
+                ],
+                "summary": "Resend emergency contact verification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Emergency contact ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verification email sent",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Emergency contact not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Emergency contact already verified",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Verification email sent recently",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/eta-shares": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the authenticated user's ETA share links with their state: active, arrived or expired",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ETA Shares"
+                ],
+                "summary": "List ETA shares",
+                "responses": {
+                    "200": {
+                        "description": "ETA shares retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ETAShare"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a temporary link anyone can open to follow the user's live position and estimated arrival at the destination. The link stops sharing the position once the user comes within the arrival radius or when it expires.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ETA Shares"
+                ],
+                "summary": "Create ETA share",
+                "parameters": [
+                    {
+                        "description": "Destination and duration",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateETAShareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ETA share created successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ETAShare"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or too many active shares",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/eta-shares/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ends an ETA share; its link stops working immediately",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ETA Shares"
+                ],
+                "summary": "Delete ETA share",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETA share ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ETA share deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "ETA share not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/ingest-sources": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the authenticated user's webhook ingest sources with their webhook URLs and secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ingest Sources"
+                ],
+                "summary": "List ingest sources",
+                "responses": {
+                    "200": {
+                        "description": "Ingest sources retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.IngestSource"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a webhook ingest source for a third-party service that can POST JSON. The mapping maps point fields (latitude, longitude, timestamp, altitude, speed, speed_unit, accuracy, device, battery, heart_rate, cadence, power, temperature, session, status, event) to JSONPath-like paths into the webhook body such as $.attributes.latitude, or to constants; latitude and longitude are required.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ingest Sources"
+                ],
+                "summary": "Add ingest source",
+                "parameters": [
+                    {
+                        "description": "Source data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.IngestSourceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Ingest source added successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.IngestSource"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, mapping or too many sources",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/ingest-sources/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the name, default session and mapping of an ingest source; its webhook URL and secret stay the same",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ingest Sources"
+                ],
+                "summary": "Update ingest source",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ingest source ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Source data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.IngestSourceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ingest source updated successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.IngestSource"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or mapping",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ingest source not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes an ingest source; its webhook URL stops accepting points",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ingest Sources"
+                ],
+                "summary": "Delete ingest source",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ingest source ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ingest source deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Ingest source not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/regenerate-token": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generates a new custom token for location tracking",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Regenerate custom token",
+                "responses": {
+                    "200": {
+                        "description": "Token regenerated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the authenticated user's active logins per device with user agent, IP address and last activity. With history=true revoked and expired logins are included as login history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "List device sessions",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Include revoked and expired sessions",
+                        "name": "history",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Device sessions retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.DeviceSession"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes all of the user's device sessions except the one of the requesting token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Revoke other device sessions",
+                "responses": {
+                    "200": {
+                        "description": "Other device sessions revoked successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes a device session; tokens issued to it are rejected immediately",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Revoke device session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Device session revoked successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Device session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/tracking-defaults": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the authenticated user's default session privacy, automatic session gap, point frequency hint, privacy zones toggle and notification preferences",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Get tracking defaults",
+                "responses": {
+                    "200": {
+                        "description": "Tracking defaults retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the authenticated user's tracking defaults; omitted fields are left unchanged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Update tracking defaults",
+                "parameters": [
+                    {
+                        "description": "Tracking defaults",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTrackingDefaultsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tracking defaults updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/public-locations": {
+            "get": {
+                "description": "Returns the latest location of each user's latest public session in GeoJSON format, optionally filtered by session activity, freshness and region",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "Get public locations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated session activities, e.g. running,walking",
+                        "name": "activity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only users whose latest location is at most this old, e.g. 15m or 2h",
+                        "name": "active_within",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only locations inside the bounding box minLon,minLat,maxLon,maxLat",
+                        "name": "bbox",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order: recent (default), distance from the center of the bbox, or username",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Public locations retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/report": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reports a public session, waypoint or community waypoint. Once enough reports are pending the content is hidden from public feeds until a moderator reviews it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Moderation"
+                ],
+                "summary": "Report content",
+                "parameters": [
+                    {
+                        "description": "Report details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateReportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Report submitted successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Report"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Content not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Already reported",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/route": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Plans a route with the configured routing engine and returns it as a GeoJSON Feature with elevations, an elevation profile, ascent, descent and climbs. With format=gpx the route is returned as a GPX file that can be uploaded as the planned route of a session.",
+                "produces": [
+                    "application/json",
+                    "application/gpx+xml"
+                ],
+                "tags": [
+                    "Routing"
+                ],
+                "summary": "Plan route",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start position as lat,lon",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Destination as lat,lon",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Routing profile: foot (default) or bike",
+                        "name": "profile",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Response format: geojson (default) or gpx",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Route planned successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.RouteFeature"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid positions or profile",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No route found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Routing engine unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Route planning not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{username}/{session}": {
+            "get": {
+                "description": "Returns location data for a specific user session in GeoJSON format. Each point carries a segment index; points of different segments are separated by a time or distance gap and should not be connected",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "Get session data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "session",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Track variant: raw (default) or matched, positions snapped to roads where the session was map matched",
+                        "name": "track",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session data retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid track variant",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{username}/{session}/coloring": {
+            "get": {
+                "description": "Returns per-segment values of the chosen metric normalized to 0..1, with min/max bounds and a suggested color ramp",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "Get session track coloring",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "session",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Metric to color by (speed, heart_rate, elevation)",
+                        "name": "metric",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for private sessions",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Guest viewer token",
+                        "name": "guest_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Track coloring retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.TrackColoringResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid metric",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{username}/{session}/export": {
+            "get": {
+                "description": "Returns the session track as a GPX 1.1 file (default) or a GeoJSON FeatureCollection, one track segment or feature per segment of the track. GPX files carry heart rate, cadence, temperature and speed in Garmin's TrackPointExtension, power in its PowerExtension and the fix accuracy in a vibe-tracker extension. GeoJSON features carry them in a \"measures\" foreign member with arrays aligned with the coordinates.",
+                "produces": [
+                    "application/gpx+xml",
+                    "application/geo+json"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "Export session track",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "session",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Export format: gpx (default) or geojson",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for private sessions",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Guest viewer token",
+                        "name": "guest_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session track exported successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.TrackExportCollection"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or session not found, or session has no locations",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{username}/{session}/guest-token": {
+            "post": {
+                "description": "Mints a short-lived token identifying an anonymous viewer of a shared session. The token grants read access to the session while it is shared the same way, is counted once in live viewer counts and is rate limited per viewer on the session's routes. Renewing it with the share link and a valid guest token keeps the same viewer; a guest token alone does not mint another one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "Create guest viewer token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "session",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for private sessions",
+                        "name": "share_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Guest token created successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.GuestTokenResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{username}/{session}/stats": {
+            "get": {
+                "description": "Splits the session track into segments wherever consecutive points are too far apart in time or distance (tunnels, flights, tracker outages) and returns the gaps with distance and duration measured within segments only, along with the categorized climbs of the track, sunrise and sunset at its start and, once matched, its paved and unpaved distance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "Get session track statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "session",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for private sessions",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Guest viewer token",
+                        "name": "guest_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session statistics retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.SessionStatsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{username}/{session}/stream": {
+            "get": {
+                "description": "Streams new locations of a session as Server-Sent Events (\"location\" events). The owner always receives \"viewers\" events with the live viewer count; other viewers receive them only if the session has show_viewer_count enabled.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "Stream session updates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "session",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for private sessions",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Guest viewer token",
+                        "name": "guest_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream"
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/session/{username}/{session}/surface": {
+            "get": {
+                "description": "Returns the session track matched to OSM ways, split into segments with their surface and road type, and the distance on paved and unpaved ways. Sessions are matched in the background after they ended, when a map matching service is configured.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "Get session track surface",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "session",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for private sessions",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Guest viewer token",
+                        "name": "guest_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session surface retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.SessionSurface"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or session not found, or surface not matched yet",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new tracking session for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Create session",
+                "parameters": [
+                    {
+                        "description": "Session data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Session created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Session already exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}": {
+            "get": {
+                "description": "Returns a paginated list of sessions for the specified user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "List user sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sessions retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}": {
+            "get": {
+                "description": "Returns a specific session by username and session name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get user session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates an existing session for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Update session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated session data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes an existing session for the authenticated user together with its tracked locations",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Delete session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/checkin": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns whether missed check-in alerts are armed for the session, the deadline of the next check-in and the current alert level",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get session check-in",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Check-in retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.CheckInStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Expects a location at least every interval_minutes. When none arrives in time the owner is emailed, after another interval the listed and the verified profile emergency contacts are alerted with the last known position and a map link.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Arm session check-in",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Check-in interval and emergency contacts",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ArmCheckInRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Check-in armed successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.CheckInStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or session ended",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turns off missed check-in alerts for the session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Disarm session check-in",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Check-in disarmed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/cover": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets the cover photo shown for the session in session lists and previews, replacing the previous cover",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Upload session cover",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Cover photo",
+                        "name": "photo",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cover uploaded successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.SessionMedia"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid file",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes the cover photo of the session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Delete session cover",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cover deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session or cover not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/geotag": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Positions a photo without GPS data at its capture time (EXIF DateTimeOriginal, in the camera time zone) on the session track, interpolating between recorded points like the position-at endpoint. Returns a copy of the JPEG with the position embedded as GPS EXIF, keeping the other metadata, or with format=json only the position, which also works for PNG, WebP and HEIC photos. Nothing is stored.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "image/jpeg",
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Geotag photo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Photo to geotag",
+                        "name": "photo",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "jpeg (default) or json",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seconds to the closest recorded point when the position cannot be interpolated (default: 300)",
+                        "name": "max_offset",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time zone of the camera clock, an IANA name or UTC offset (default: UTC)",
+                        "name": "timezone",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Position of the photo (format=json)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.HistoricalPosition"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid photo, photo already geotagged or without capture time",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found or no position recorded near the capture time",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/gpx": {
+            "get": {
+                "description": "Downloads the GPX file uploaded to a session as it was uploaded, named after the session",
+                "produces": [
+                    "application/gpx+xml"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Download GPX track",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "GPX file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session or GPX file not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Uploads a GPX file to a session and processes track points and waypoints. With as_recorded the timed track points are stored in one transaction as recorded locations of the session, like tracked points, instead of as its planned track; points without a time or at the time of a stored location of the session are skipped, and the file is not kept. An activity with timestamps that overlaps the recorded track of another session of the user is rejected with 409 listing the overlapping sessions, unless confirm_duplicate is set.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Upload GPX track",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "GPX file to upload",
+                        "name": "gpx_file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Keep the session a draft until it is published",
+                        "name": "draft",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Import the activity although it overlaps other sessions",
+                        "name": "confirm_duplicate",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Read files that are not quite valid XML or GPX, e.g. from older devices, and list the repairs in warnings",
+                        "name": "lenient",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Store the points as recorded locations with their original times, heart rate and speed instead of as the planned track; also accepted as form field",
+                        "name": "as_recorded",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Simplify the planned track (default: instance setting)",
+                        "name": "simplify",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Most points of the simplified plan, 0 for no limit (default: instance setting)",
+                        "name": "max_points",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Scales the simplification tolerance (default: instance setting)",
+                        "name": "epsilon_multiplier",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "GPX track uploaded successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or file",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The activity overlaps other sessions",
+                        "schema": {
+                            "$ref": "#/definitions/models.DuplicateActivityResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/gpx/reprocess": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Parses the GPX file stored with a session again, e.g. after a parser improvement, and replaces its planned track points, simplified with the current settings, and the waypoints imported from GPX. Other waypoints are kept; the track and waypoints are replaced together or not at all.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Reprocess GPX track",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Read files that are not quite valid XML or GPX and list the repairs in warnings",
+                        "name": "lenient",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "GPX track reprocessed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Stored GPX file cannot be parsed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session or GPX file not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/map-match": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Snaps the session's locations to roads with the configured Valhalla or OSRM backend and stores the snapped positions next to the recorded ones, replacing an earlier match. Session data returns the snapped track with track=matched.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Map match session track",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session track map matched successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.MapMatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Session has no locations",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Map matching backend unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Map matching not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/media": {
+            "get": {
+                "description": "Returns the photos and videos attached to the session, oldest first, with signed download URLs. The cover photo is not included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "List session media",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Media retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.SessionMedia"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Attaches a photo or short video to the session, independent of its waypoints",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Upload session media",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Photo (JPEG, PNG, WebP, HEIC) or video (MP4, QuickTime)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Caption",
+                        "name": "caption",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Media uploaded successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.SessionMedia"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid file or too many media",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/media/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a photo or video attached to the session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Delete session media",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Media ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Media deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session or media not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/progress": {
+            "get": {
+                "description": "Returns visited and remaining waypoint counts and the next expected waypoint of a session. Live sessions also get the sunrise and sunset at the latest position, the daylight remaining and how far the current pace gets before sunset.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get session progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for private sessions",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Guest viewer token",
+                        "name": "guest_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session progress retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.SessionProgressResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/publish": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Publishes a draft session, e.g. an imported one after reviewing its title, photos and track, so a public session appears in public feeds. Sends the session.published webhook.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Publish draft session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session published successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Session is not a draft",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/rename": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the name of a session, e.g. to fix a typo, together with the references to it by name of the ingest sources, MapShare feeds and hardware trackers tracking into it. A title generated from the old name is regenerated. Links to the old name stop working; snapshots keep the name they were taken with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Rename session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New session name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RenameSessionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session renamed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid name or a session with the name exists",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/replay": {
+            "get": {
+                "description": "Returns tracked positions resampled to one frame per playback second, where each second covers ` + "`" + `speed` + "`" + ` seconds of recorded time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get session replay",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Time compression factor (default: 60, max: 86400)",
+                        "name": "speed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for private sessions",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Guest viewer token",
+                        "name": "guest_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session replay retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.SessionReplayResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid speed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/snapshot": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Renders the session track as GeoJSON, its statistics, waypoints and photos into a static bundle in the file storage (local or S3) and returns the permanent URL of its manifest, snapshot.json. The manifest references the other files by name, relative to itself. The bundle is a copy: it stays available when points or media are later pruned or the session is deleted, and anyone with the URL can read it, even for private sessions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Create session snapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Snapshot created successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.SessionSnapshot"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found or session has no locations",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/track": {
+            "get": {
+                "description": "Returns the planned track points from uploaded GPX for a session with the categorized climbs along the route. Every point names its track and segment; tracks lists the tracks and routes of the file, in the order of the points, with the sequence range of each segment.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get session track data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the simplified plan, or the full-resolution one with false (default: true)",
+                        "name": "simplified",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Track data retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session or track not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/viewers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the number of viewers currently streaming the session (owner only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get live viewer count",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Viewer count retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{username}/{name}/weather": {
+            "get": {
+                "description": "Samples the planned route of the session about every 10 km and returns the forecasted weather at each point for the hour it is reached when starting at the given time and moving at the given speed. Points reached in the past or more than 16 days ahead have no forecast.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get weather along planned route",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected start time, RFC 3339 or Unix timestamp",
+                        "name": "start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Expected average speed in km/h (default: 5)",
+                        "name": "speed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token for private sessions",
+                        "name": "share_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Guest viewer token",
+                        "name": "guest_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Route weather retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.RouteWeatherResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid start time or speed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Access denied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session or planned route not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Forecast API unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Weather forecasts not configured",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signed-files/{collection}/{record}/{filename}": {
+            "get": {
+                "description": "Serves a waypoint photo, the uploaded GPX file of a session or a photo or video attached to a session. Links are signed and expire after a short time; they are included as photo_url, gpx_track_url, cover_url and media url in API responses of users who may view the file.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Files"
+                ],
+                "summary": "Download file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection: waypoints, sessions or session_media",
+                        "name": "collection",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Record ID",
+                        "name": "record",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File name",
+                        "name": "filename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Link expiry as a Unix timestamp",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link signature",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid or expired link",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/swagger": {
+            "get": {
+                "description": "Serves the Swagger UI for interactive API exploration and testing",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Documentation"
+                ],
+                "summary": "Interactive API documentation",
+                "responses": {
+                    "200": {
+                        "description": "Swagger UI HTML page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/swagger/json": {
+            "get": {
+                "description": "Returns the OpenAPI 3.0 specification in JSON format",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Documentation"
+                ],
+                "summary": "Get OpenAPI specification",
+                "responses": {
+                    "200": {
+                        "description": "OpenAPI specification",
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                }
+            }
+        },
+        "/track": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "TokenAuth": []
+                    }
+                ],
+                "description": "Tracks user location using GET request with query parameters. A retried request with the client_id or Idempotency-Key of a point that was already tracked is answered with the stored point instead of storing it again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tracking"
+                ],
+                "summary": "Track location (GET)",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Latitude (-90 to 90)",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Longitude (-180 to 180)",
+                        "name": "lon",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Altitude in meters",
+                        "name": "alt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Speed, stored in m/s",
+                        "name": "speed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit of speed (m/s, km/h, mph, knots); defaults to the user's tracking setting",
+                        "name": "speed_unit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "format": "int64",
+                        "description": "Unix timestamp; rejected when too old or in the future",
+                        "name": "timestamp",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Accept timestamps older than the configured maximum age",
+                        "name": "allow_historical",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Horizontal accuracy in meters",
+                        "name": "accuracy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reporting device identifier",
+                        "name": "device",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Tracker battery level in percent",
+                        "name": "battery",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Vertical accuracy in meters",
+                        "name": "vertical_accuracy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Direction of travel in degrees clockwise from true north (0-360)",
+                        "name": "bearing",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Heart rate in beats per minute",
+                        "name": "heart_rate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Cadence in revolutions or steps per minute",
+                        "name": "cadence",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Power output in watts",
+                        "name": "power",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "format": "float64",
+                        "description": "Ambient temperature in degrees Celsius",
+                        "name": "temperature",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name",
+                        "name": "session",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status information",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event information",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique ID of the point, e.g. a UUID, so retries are stored once",
+                        "name": "client_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID of the point when client_id is not set",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Location tracked successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many points are waiting to be saved, retry after Retry-After seconds",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "TokenAuth": []
+                    }
+                ],
+                "description": "Tracks user location using POST request with a GeoJSON Point Feature. Coordinates must be [longitude, latitude] or [longitude, latitude, altitude] with finite values in range; other geometry types are rejected with field-level errors. Cellular trackers may send the compact CBOR (application/cbor) or protobuf (application/x-protobuf) encoding of the point instead. Bodies may be sent with Content-Encoding: gzip; the inflated body is held to the request size limit. A retried request with the client_id property or Idempotency-Key of a point that was already tracked is answered with the stored point instead of storing it again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tracking"
+                ],
+                "summary": "Track location (POST)",
+                "parameters": [
+                    {
+                        "description": "Location data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LocationRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Accept timestamps older than the configured maximum age",
+                        "name": "allow_historical",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID of the point when the client_id property is not set",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "gzip for a compressed body",
+                        "name": "Content-Encoding",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Location tracked successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or GeoJSON validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Content-Encoding",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many points are waiting to be saved, retry after Retry-After seconds",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/track/ws": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "TokenAuth": []
+                    }
+                ],
+                "description": "Upgrades to a WebSocket on which the client streams points instead of sending a request per point, e.g. when tracking every second. Every text message is one JSON point with the fields of the GET /api/track query parameters; it is validated and stored like a GET /api/track request and acknowledged in order with its index, id and session, or the reason it was rejected. Rejected points do not close the connection. The session and device query parameters apply to points that do not set them. Connections without messages for 5 minutes are closed.",
+                "tags": [
+                    "Tracking"
+                ],
+                "summary": "Track locations (WebSocket)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session of points without one",
+                        "name": "session",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device of points without one",
+                        "name": "device",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Accept timestamps older than the configured maximum age",
+                        "name": "allow_historical",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switched to the WebSocket protocol, one acknowledgement is sent per point",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TrackingSocketAck"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Not a WebSocket request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{username}/badge.svg": {
+            "get": {
+                "description": "Renders a small SVG badge with the time since the user's latest location (\"last seen 5 min ago\") or the distance tracked today in the user's time zone (\"today 12.3 km\"), for embedding in READMEs and blogs. Badges are only served for users who enabled public_badge in their tracking defaults.",
+                "produces": [
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "Public"
+                ],
+                "summary": "Get status badge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "last_seen (default) or distance_today",
+                        "name": "show",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text of the left part instead of the default",
+                        "name": "label",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "SVG badge",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Badge not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid show or label",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found or badge not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{username}/countries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the countries and regions found in the user's location history, with first and last visit, days spent and entry and exit of each visit. Locations are geocoded by a background job when a reverse geocoding service is configured; pending_locations counts those not included yet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Statistics"
+                ],
+                "summary": "Get visited countries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Countries retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.CountriesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{username}/countries/geojson": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a GeoJSON FeatureCollection with one feature per visited country: a point at the center of the user's locations there with their bounding box. Clients shade the country boundaries matching the country_code property.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Statistics"
+                ],
+                "summary": "Get visited countries layer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Visited countries layer",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.CountryFeatureCollection"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{username}/position-at": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the user's position at an arbitrary time, e.g. to geotag photos. Between two recorded points of the same track segment the position is interpolated; across a gap in tracking or before the first and after the last point, the closest point is returned when it was recorded within max_offset seconds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timeline"
+                ],
+                "summary": "Get historical position",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time or Unix timestamp",
+                        "name": "timestamp",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seconds to the closest recorded point when the position cannot be interpolated (default: 300)",
+                        "name": "max_offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Position retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.HistoricalPosition"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid timestamp",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found or no position recorded near that time",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{username}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes a user's role (admin, moderator, user or readonly); requires the users:manage permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Update user role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateUserRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{username}/timeline": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the stays and movements, with detected transport mode, of one day in the user's time zone. Timelines are computed from all of the user's locations by a background job; recent days may lag behind by up to an hour.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timeline"
+                ],
+                "summary": "Get daily timeline",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Day as YYYY-MM-DD (default: today in the user's time zone)",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Timeline retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.TimelineResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid date",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/waypoints": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new waypoint for a session, or a user-level waypoint when no session is given",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waypoints"
+                ],
+                "summary": "Create waypoint",
+                "parameters": [
+                    {
+                        "description": "Waypoint data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWaypointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Waypoint created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/waypoints/by-session/{sessionId}": {
+            "get": {
+                "description": "Returns waypoints for the specified session ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waypoints"
+                ],
+                "summary": "List waypoints by session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Waypoint type filter",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Waypoints retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/waypoints/photo": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Uploads a photo and creates a waypoint with GPS data from EXIF or intelligent fallback positioning",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waypoints"
+                ],
+                "summary": "Upload photo waypoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Waypoint name (optional, will be generated if not provided)",
+                        "name": "name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Waypoint type (default: generic)",
+                        "name": "type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Waypoint description (optional)",
+                        "name": "description",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Photo file to upload",
+                        "name": "photo",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time zone of the camera clock, an IANA name or UTC offset (default: UTC)",
+                        "name": "timezone",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Photo waypoint created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or file",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/waypoints/reorder": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reorders the waypoints of a session; the request must list every waypoint of the session exactly once",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waypoints"
+                ],
+                "summary": "Reorder waypoints",
+                "parameters": [
+                    {
+                        "description": "Session ID and ordered waypoint IDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReorderWaypointsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Waypoints reordered successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/waypoints/{id}": {
+            "get": {
+                "description": "Returns a specific waypoint by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waypoints"
+                ],
+                "summary": "Get waypoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Waypoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Waypoint retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Waypoint not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates an existing waypoint",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waypoints"
+                ],
+                "summary": "Update waypoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Waypoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated waypoint data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateWaypointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Waypoint updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Waypoint not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes an existing waypoint",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waypoints"
+                ],
+                "summary": "Delete waypoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Waypoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Waypoint deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Waypoint not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/waypoints/{id}/visited": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marks a waypoint as visited (optionally at a given Unix timestamp) or clears the visited state",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waypoints"
+                ],
+                "summary": "Set waypoint visited state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Waypoint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Visited state",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetWaypointVisitedRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Waypoint visited state updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Waypoint not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/waypoints/{username}": {
+            "get": {
+                "description": "Returns a paginated list of waypoints for the specified user or session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waypoints"
+                ],
+                "summary": "List waypoints",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session name filter",
+                        "name": "session",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Limit to user-level (user) or session-linked (session) waypoints",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Waypoint type filter",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Waypoints retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AddOrganizationMemberRequest": {
+            "type": "object",
+            "required": [
+                "role",
+                "username"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "owner",
+                        "admin",
+                        "member"
+                    ]
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ArmCheckInRequest": {
+            "type": "object",
+            "required": [
+                "interval_minutes"
+            ],
+            "properties": {
+                "contacts": {
+                    "description": "Alerted along with the verified emergency contacts when the owner does not react",
+                    "type": "array",
+                    "maxItems": 5,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "interval_minutes": {
+                    "type": "integer",
+                    "maximum": 1440,
+                    "minimum": 5
+                }
+            }
+        },
+        "models.AuthMethod": {
+            "type": "object",
+            "properties": {
+                "header": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "login_url": {
+                    "type": "string"
+                },
+                "query_param": {
+                    "type": "string"
+                },
+                "refresh_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BrandingResponse": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "$ref": "#/definitions/models.InstanceFeatures"
+                },
+                "logo_url": {
+                    "type": "string"
+                },
+                "map": {
+                    "$ref": "#/definitions/models.MapDefaults"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CapabilitiesResponse": {
+            "type": "object",
+            "properties": {
+                "api_version": {
+                    "type": "integer"
+                },
+                "limits": {
+                    "$ref": "#/definitions/models.CapabilityLimits"
+                },
+                "modules": {
+                    "$ref": "#/definitions/models.InstanceFeatures"
+                },
+                "schema_version": {
+                    "description": "Newest database migration",
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CapabilityLimits": {
+            "type": "object",
+            "properties": {
+                "max_page_size": {
+                    "description": "Largest per_page of paginated lists",
+                    "type": "integer"
+                },
+                "max_request_size": {
+                    "description": "Bytes",
+                    "type": "integer"
+                },
+                "max_timestamp_age": {
+                    "description": "Seconds; older points need allow_historical",
+                    "type": "integer"
+                },
+                "rate_limits": {
+                    "description": "Keyed by endpoint group, null when rate limiting is disabled",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.RateLimit"
+                    }
+                },
+                "uploads": {
+                    "description": "Keyed by upload form",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.UploadLimit"
+                    }
+                }
+            }
+        },
+        "models.CaptchaChallenge": {
+            "type": "object",
+            "properties": {
+                "challenge": {
+                    "description": "Proof-of-work challenge, solved as \"\u003cchallenge\u003e:\u003cnonce\u003e\"",
+                    "type": "string"
+                },
+                "difficulty": {
+                    "description": "Leading zero bits of SHA-256(\"\u003cchallenge\u003e:\u003cnonce\u003e\")",
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "hcaptcha, turnstile or pow; empty when no CAPTCHA is configured",
+                    "type": "string"
+                },
+                "site_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CheckInStatus": {
+            "type": "object",
+            "properties": {
+                "alert_level": {
+                    "description": "0 none, 1 owner alerted, 2 contacts alerted",
+                    "type": "integer"
+                },
+                "armed": {
+                    "type": "boolean"
+                },
+                "armed_at": {
+                    "type": "string"
+                },
+                "contacts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "deadline": {
+                    "description": "A point must arrive before this time",
+                    "type": "string"
+                },
+                "interval_minutes": {
+                    "type": "integer"
+                },
+                "last_point_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ClientConfigDocument": {
+            "type": "object",
+            "properties": {
+                "api_base": {
+                    "type": "string"
+                },
+                "auth": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuthMethod"
+                    }
+                },
+                "branding": {
+                    "description": "URL of the branding and feature document",
+                    "type": "string"
+                },
+                "capabilities": {
+                    "description": "URL of the version, modules and limits",
+                    "type": "string"
+                },
+                "config_version": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "protocols": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrackingProtocol"
+                    }
+                },
+                "read_only": {
+                    "type": "boolean"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Climb": {
+            "type": "object",
+            "properties": {
+                "average_gradient": {
+                    "description": "Percent",
+                    "type": "number"
+                },
+                "category": {
+                    "description": "4, 3, 2, 1 or HC",
+                    "type": "string"
+                },
+                "elevation_gain": {
+                    "description": "Meters from the start to the top",
+                    "type": "number"
+                },
+                "end_altitude": {
+                    "description": "Meters",
+                    "type": "number"
+                },
+                "end_distance": {
+                    "type": "number"
+                },
+                "end_index": {
+                    "description": "Index of the top of the climb",
+                    "type": "integer"
+                },
+                "from": {
+                    "description": "[lon, lat]",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "length": {
+                    "description": "Meters",
+                    "type": "number"
+                },
+                "max_gradient": {
+                    "description": "Percent, steepest stretch of at least 100 m",
+                    "type": "number"
+                },
+                "start_altitude": {
+                    "description": "Meters",
+                    "type": "number"
+                },
+                "start_distance": {
+                    "type": "number"
+                },
+                "start_index": {
+                    "description": "Index of the first point of the climb",
+                    "type": "integer"
+                },
+                "to": {
+                    "description": "[lon, lat]",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "models.ColoredSegment": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "description": "[[lon, lat], [lon, lat]]",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "normalized": {
+                    "description": "Value scaled to 0..1 between Min and Max",
+                    "type": "number"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ComponentHealth": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "last_checked": {
+                    "type": "string"
+                },
+                "response_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.HealthStatus"
+                }
+            }
+        },
+        "models.CountriesResponse": {
+            "type": "object",
+            "properties": {
+                "countries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CountryStats"
+                    }
+                },
+                "country_count": {
+                    "type": "integer"
+                },
+                "pending_locations": {
+                    "description": "Locations not geocoded yet, the statistics do not include them",
+                    "type": "integer"
+                },
+                "region_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CountryFeature": {
+            "type": "object",
+            "properties": {
+                "bbox": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "geometry": {
+                    "$ref": "#/definitions/models.Geometry"
+                },
+                "properties": {
+                    "$ref": "#/definitions/models.CountryFeatureProperties"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CountryFeatureCollection": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CountryFeature"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CountryFeatureProperties": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string"
+                },
+                "country_code": {
+                    "type": "string"
+                },
+                "days": {
+                    "type": "integer"
+                },
+                "first_visit": {
+                    "type": "string"
+                },
+                "last_visit": {
+                    "type": "string"
+                },
+                "visit_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CountryStats": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string"
+                },
+                "country_code": {
+                    "description": "ISO 3166-1 alpha-2",
+                    "type": "string"
+                },
+                "days": {
+                    "description": "Distinct UTC days with points in the country",
+                    "type": "integer"
+                },
+                "first_visit": {
+                    "type": "string"
+                },
+                "last_visit": {
+                    "type": "string"
+                },
+                "regions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RegionStats"
+                    }
+                },
+                "visits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CountryVisit"
+                    }
+                }
+            }
+        },
+        "models.CountryVisit": {
+            "type": "object",
+            "properties": {
+                "entry_at": {
+                    "type": "string"
+                },
+                "exit_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateETAShareRequest": {
+            "type": "object",
+            "required": [
+                "duration_minutes"
+            ],
+            "properties": {
+                "arrival_radius": {
+                    "description": "Meters, default 100",
+                    "type": "number",
+                    "maximum": 5000,
+                    "minimum": 10
+                },
+                "duration_minutes": {
+                    "description": "The share expires after this time at the latest",
+                    "type": "integer",
+                    "maximum": 1440,
+                    "minimum": 5
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "description": "Destination name shown to viewers",
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "models.CreateOrganizationAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "role"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "member"
+                    ]
+                }
+            }
+        },
+        "models.CreateOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 1
+                },
+                "slug": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                }
+            }
+        },
+        "models.CreateOrganizationSessionRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "username"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "username": {
+                    "description": "Member whose tracker records the session",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateReportRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "target_id",
+                "target_type"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "reason": {
+                    "type": "string",
+                    "enum": [
+                        "spam",
+                        "inappropriate",
+                        "harassment",
+                        "privacy",
+                        "other"
+                    ]
+                },
+                "target_id": {
+                    "type": "string",
+                    "maxLength": 15
+                },
+                "target_type": {
+                    "type": "string",
+                    "enum": [
+                        "session",
+                        "waypoint",
+                        "community_waypoint"
+                    ]
+                }
+            }
+        },
+        "models.CreateSessionRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "activity": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "draft": {
+                    "description": "Optional - keeps the session out of public feeds until published",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "public": {
+                    "description": "Optional - uses user's default if not specified",
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "models.CreateWaypointRequest": {
+            "type": "object",
+            "required": [
+                "latitude",
+                "longitude",
+                "name",
+                "position_confidence",
+                "source",
+                "type"
+            ],
+            "properties": {
+                "altitude": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 1
+                },
+                "order": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "position_confidence": {
+                    "type": "string",
+                    "enum": [
+                        "gps",
+                        "time_matched",
+                        "tracked",
+                        "gpx_track",
+                        "last_known",
+                        "manual"
+                    ]
+                },
+                "session_id": {
+                    "description": "Optional - empty creates a user-level waypoint",
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string",
+                    "enum": [
+                        "gpx",
+                        "manual",
+                        "photo"
+                    ]
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "generic",
+                        "food",
+                        "water",
+                        "shelter",
+                        "transition",
+                        "viewpoint",
+                        "camping",
+                        "parking",
+                        "danger",
+                        "medical",
+                        "fuel"
+                    ]
+                }
+            }
+        },
+        "models.DaylightProgress": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Local solar date, YYYY-MM-DD",
+                    "type": "string"
+                },
+                "day_length": {
+                    "description": "Seconds between sunrise and sunset",
+                    "type": "integer"
+                },
+                "distance_before_sunset": {
+                    "description": "Meters at the current pace",
+                    "type": "number"
+                },
+                "next_waypoint_before_sunset": {
+                    "description": "Whether the next waypoint is reached in daylight at the current pace",
+                    "type": "boolean"
+                },
+                "pace": {
+                    "description": "Meters per second over the recent movement",
+                    "type": "number"
+                },
+                "polar_day": {
+                    "type": "boolean"
+                },
+                "polar_night": {
+                    "type": "boolean"
+                },
+                "remaining": {
+                    "description": "Seconds until sunset, 0 after it",
+                    "type": "integer"
+                },
+                "sunrise": {
+                    "description": "Null during polar day and night",
+                    "type": "string"
+                },
+                "sunset": {
+                    "description": "Null during polar day and night",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DetailedHealthResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.ComponentHealth"
+                    }
+                },
+                "ingest": {
+                    "description": "Only when write batching is on",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.IngestQueueStats"
+                        }
+                    ]
+                },
+                "migrations": {
+                    "$ref": "#/definitions/models.MigrationHealth"
+                },
+                "resources": {
+                    "$ref": "#/definitions/models.ResourceHealth"
+                },
+                "services": {
+                    "$ref": "#/definitions/models.ServiceHealth"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.HealthStatus"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "uptime": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DeviceSession": {
+            "type": "object",
+            "properties": {
+                "asn": {
+                    "description": "Autonomous system number from geo-IP lookup",
+                    "type": "integer"
+                },
+                "country": {
+                    "description": "ISO country code from geo-IP lookup",
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Session of the requesting token",
+                    "type": "boolean"
+                },
+                "device": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "suspicious": {
+                    "description": "Reasons the login was flagged, e.g. new_country",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DownsamplingPolicy": {
+            "type": "object",
+            "properties": {
+                "min_distance": {
+                    "description": "Meters",
+                    "type": "number",
+                    "maximum": 1000,
+                    "minimum": 0
+                },
+                "min_interval": {
+                    "description": "Seconds",
+                    "type": "integer",
+                    "maximum": 3600,
+                    "minimum": 0
+                }
+            }
+        },
+        "models.DuplicateActivityResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "duplicates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DuplicateSession"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DuplicateSession": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "similarity": {
+                    "description": "Share of the compared imported points close to the session's track, 0-1",
+                    "type": "number"
+                },
+                "start": {
+                    "description": "Shared time window",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ETADestination": {
+            "type": "object",
+            "properties": {
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ETAPosition": {
+            "type": "object",
+            "properties": {
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ETAShare": {
+            "type": "object",
+            "properties": {
+                "arrival_radius": {
+                    "type": "number"
+                },
+                "arrived_at": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "destination": {
+                    "$ref": "#/definitions/models.ETADestination"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ETAShareView": {
+            "type": "object",
+            "properties": {
+                "arrived_at": {
+                    "type": "string"
+                },
+                "destination": {
+                    "$ref": "#/definitions/models.ETADestination"
+                },
+                "distance": {
+                    "description": "Meters in a straight line",
+                    "type": "number"
+                },
+                "eta": {
+                    "type": "string"
+                },
+                "eta_seconds": {
+                    "description": "Travel time from the latest position",
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "position": {
+                    "$ref": "#/definitions/models.ETAPosition"
+                },
+                "speed": {
+                    "description": "Meters per second over the recent movement",
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EmergencyContact": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "verified": {
+                    "type": "boolean"
+                },
+                "verified_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EmergencyContactRequest": {
+            "type": "object",
+            "required": [
+                "channel",
+                "email",
+                "name"
+            ],
+            "properties": {
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "email",
+                        "sms"
+                    ]
+                },
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FlagCommunityWaypointRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "reason": {
+                    "type": "string",
+                    "enum": [
+                        "incorrect",
+                        "unavailable",
+                        "duplicate",
+                        "inappropriate",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "models.Geometry": {
+            "type": "object",
+            "required": [
+                "coordinates",
+                "type"
+            ],
+            "properties": {
+                "coordinates": {
+                    "type": "array",
+                    "maxItems": 3,
+                    "minItems": 2,
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "Point"
+                    ]
+                }
+            }
+        },
+        "models.GraphQLRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "models.GraphQLResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "message": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "models.GuestTokenResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "guest_token": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "viewer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HealthStatus": {
+            "type": "string",
+            "enum": [
+                "healthy",
+                "unhealthy",
+                "warning"
+            ],
+            "x-enum-varnames": [
+                "HealthStatusHealthy",
+                "HealthStatusUnhealthy",
+                "HealthStatusWarning"
+            ]
+        },
+        "models.HistoricalPosition": {
+            "type": "object",
+            "properties": {
+                "altitude": {
+                    "type": "number"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "method": {
+                    "description": "exact, interpolated or nearest",
+                    "type": "string"
+                },
+                "offset": {
+                    "description": "Seconds between the requested time and the closest recorded point",
+                    "type": "integer"
+                },
+                "session": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "Requested time",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.HomeAssistantLocation": {
+            "type": "object",
+            "required": [
+                "gps"
+            ],
+            "properties": {
+                "altitude": {
+                    "type": "number"
+                },
+                "battery": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "course": {
+                    "type": "number"
+                },
+                "gps": {
+                    "description": "[latitude, longitude]",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "gps_accuracy": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "location_name": {
+                    "description": "Zone the device is in, tracked as the point status",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "speed": {
+                    "description": "m/s, negative when unknown",
+                    "type": "number"
+                },
+                "vertical_accuracy": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.HomeAssistantWebhookRequest": {
+            "type": "object",
+            "required": [
+                "data"
+            ],
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.HomeAssistantLocation"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.IngestQueueStats": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer"
+                },
+                "batches": {
+                    "type": "integer"
+                },
+                "capacity": {
+                    "description": "Points queued at most",
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "last_batch_latency": {
+                    "description": "From queuing to commit of its oldest point",
+                    "type": "string"
+                },
+                "last_batch_size": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "queued": {
+                    "description": "Points accepted but not written yet",
+                    "type": "integer"
+                },
+                "rejected": {
+                    "description": "Points refused because the queue was full",
+                    "type": "integer"
+                },
+                "workers": {
+                    "type": "integer"
+                },
+                "written": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.IngestSource": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_received_at": {
+                    "type": "string"
+                },
+                "mapping": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Sent in the X-Webhook-Secret header or the secret query parameter",
+                    "type": "string"
+                },
+                "session": {
+                    "type": "string"
+                },
+                "webhook_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.IngestSourceRequest": {
+            "type": "object",
+            "required": [
+                "mapping",
+                "name"
+            ],
+            "properties": {
+                "mapping": {
+                    "description": "Point field to JSONPath-like path or constant",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "session": {
+                    "description": "Default session of the points",
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "models.InstanceFeatures": {
+            "type": "object",
+            "properties": {
+                "aprs": {
+                    "type": "boolean"
+                },
+                "country_stats": {
+                    "type": "boolean"
+                },
+                "elevation": {
+                    "type": "boolean"
+                },
+                "graphql": {
+                    "type": "boolean"
+                },
+                "grpc_ingest": {
+                    "type": "boolean"
+                },
+                "hardware_trackers": {
+                    "type": "boolean"
+                },
+                "home_assistant": {
+                    "type": "boolean"
+                },
+                "low_battery_alerts": {
+                    "type": "boolean"
+                },
+                "map_matching": {
+                    "type": "boolean"
+                },
+                "mapshare": {
+                    "type": "boolean"
+                },
+                "read_only": {
+                    "description": "Writes are rejected during maintenance",
+                    "type": "boolean"
+                },
+                "route_planning": {
+                    "type": "boolean"
+                },
+                "waypoint_check_off": {
+                    "type": "boolean"
+                },
+                "weather": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.LivenessResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "$ref": "#/definitions/models.HealthStatus"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "uptime": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LocationProperties": {
+            "type": "object",
+            "required": [
+                "timestamp"
+            ],
+            "properties": {
+                "accuracy": {
+                    "description": "Horizontal accuracy radius in meters",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "battery": {
+                    "description": "Tracker battery level in percent",
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "bearing": {
+                    "description": "Direction of travel in degrees clockwise from true north",
+                    "type": "number",
+                    "maximum": 360,
+                    "minimum": 0
+                },
+                "cadence": {
+                    "description": "Readings of cycling computers and sports watches",
+                    "type": "number",
+                    "maximum": 300,
+                    "minimum": 0
+                },
+                "client_id": {
+                    "description": "Unique per point of the user; a point with a known ID is not stored again",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "device": {
+                    "description": "Identifies the reporting device when a user tracks with several",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "event": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "heart_rate": {
+                    "type": "number",
+                    "maximum": 300,
+                    "minimum": 0
+                },
+                "power": {
+                    "description": "Watts",
+                    "type": "number",
+                    "maximum": 5000,
+                    "minimum": 0
+                },
+                "session": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "session_title": {
+                    "type": "string"
+                },
+                "speed": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "speed_unit": {
+                    "description": "Overrides the user's default speed unit",
+                    "type": "string",
+                    "enum": [
+                        "m/s",
+                        "km/h",
+                        "mph",
+                        "knots"
+                    ]
+                },
+                "status": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "temperature": {
+                    "description": "Ambient, degrees Celsius",
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": -100
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "username": {
+                    "type": "string"
+                },
+                "vertical_accuracy": {
+                    "description": "Altitude accuracy in meters",
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.LocationRequest": {
+            "type": "object",
+            "required": [
+                "geometry",
+                "properties",
+                "type"
+            ],
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/models.Geometry"
+                },
+                "properties": {
+                    "$ref": "#/definitions/models.LocationProperties"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "Feature"
+                    ]
+                }
+            }
+        },
+        "models.LogLevels": {
+            "type": "object",
+            "properties": {
+                "level": {
+                    "type": "string"
+                },
+                "modules": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 6
+                }
+            }
+        },
+        "models.MapDefaults": {
+            "type": "object",
+            "properties": {
+                "center": {
+                    "description": "[latitude, longitude]",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "tiles": {
+                    "$ref": "#/definitions/models.TileProvider"
+                },
+                "zoom": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MapMatchResponse": {
+            "type": "object",
+            "properties": {
+                "matched_at": {
+                    "type": "string"
+                },
+                "matched_count": {
+                    "description": "Locations snapped to a way",
+                    "type": "integer"
+                },
+                "point_count": {
+                    "description": "Locations of the session",
+                    "type": "integer"
+                },
+                "provider": {
+                    "description": "valhalla or osrm",
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MigrationHealth": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "integer"
+                },
+                "backfills": {
+                    "description": "Data backfills still running",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.HealthStatus"
+                },
+                "version": {
+                    "description": "Latest applied migration",
+                    "type": "string"
+                }
+            }
+        },
+        "models.NotificationPreferences": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "boolean"
+                },
+                "low_battery": {
+                    "type": "boolean"
+                },
+                "push": {
+                    "type": "boolean"
+                },
+                "waypoint_visited": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.OrphanedFile": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "description": "Storage key, relative to the storage root or S3 bucket",
+                    "type": "string"
+                },
+                "modified": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "collection_deleted, record_deleted or unreferenced",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Bytes",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrphanedFilesReport": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "description": "Orphans deleted by the scan",
+                    "type": "integer"
+                },
+                "orphaned_size": {
+                    "description": "Bytes",
+                    "type": "integer"
+                },
+                "orphans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrphanedFile"
+                    }
+                },
+                "scanned_at": {
+                    "type": "string"
+                },
+                "scanned_files": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OwnTracksMessage": {
+            "type": "object",
+            "required": [
+                "_type"
+            ],
+            "properties": {
+                "_type": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "acc": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "alt": {
+                    "type": "number"
+                },
+                "batt": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0
+                },
+                "cog": {
+                    "description": "Course over ground in degrees",
+                    "type": "number"
+                },
+                "inregions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "lat": {
+                    "type": "number"
+                },
+                "lon": {
+                    "type": "number"
+                },
+                "tid": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "topic": {
+                    "description": "owntracks/\u003cuser\u003e/\u003cdevice\u003e",
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "tst": {
+                    "description": "Unix seconds of the fix",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "vac": {
+                    "description": "Vertical accuracy in meters",
+                    "type": "number"
+                },
+                "vel": {
+                    "description": "km/h",
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.PaginatedResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "pagination": {
+                    "$ref": "#/definitions/models.PaginationMeta"
+                }
+            }
+        },
+        "models.PaginationMeta": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer"
+                },
+                "perPage": {
+                    "type": "integer"
+                },
+                "totalItems": {
+                    "type": "integer"
+                },
+                "totalPages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PublishCommunityWaypointRequest": {
+            "type": "object",
+            "required": [
+                "waypoint_id"
+            ],
+            "properties": {
+                "waypoint_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RateLimit": {
+            "type": "object",
+            "properties": {
+                "burst": {
+                    "type": "integer"
+                },
+                "requests_per_minute": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.HealthStatus"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/models.HealthStatus"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReauthRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refreshToken"
+            ],
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RegionStats": {
+            "type": "object",
+            "properties": {
+                "first_visit": {
+                    "type": "string"
+                },
+                "last_visit": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RenameSessionRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                }
+            }
+        },
+        "models.ReorderWaypointsRequest": {
+            "type": "object",
+            "required": [
+                "session_id",
+                "waypoint_ids"
+            ],
+            "properties": {
+                "session_id": {
+                    "type": "string"
+                },
+                "waypoint_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ReplayFrame": {
+            "type": "object",
+            "properties": {
+                "altitude": {
+                    "type": "number"
+                },
+                "frame": {
+                    "description": "Playback second, starting at 0",
+                    "type": "integer"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "description": "Unix timestamp of the recorded time this frame represents",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Report": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reporter": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_owner": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResourceHealth": {
+            "type": "object",
+            "properties": {
+                "disk_space": {
+                    "type": "string"
+                },
+                "file_descriptors": {
+                    "type": "string"
+                },
+                "goroutines": {
+                    "type": "integer"
+                },
+                "memory_usage": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReviewReportRequest": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "dismiss",
+                        "hide"
+                    ]
+                }
+            }
+        },
+        "models.RouteFeature": {
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/models.RouteGeometry"
+                },
+                "properties": {
+                    "$ref": "#/definitions/models.RouteProperties"
+                },
+                "type": {
+                    "description": "Feature",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RouteGeometry": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "description": "[[lon, lat, elevation], ...], elevation only when known",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "type": {
+                    "description": "LineString",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RouteProperties": {
+            "type": "object",
+            "properties": {
+                "ascent": {
+                    "description": "Meters",
+                    "type": "number"
+                },
+                "climbs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Climb"
+                    }
+                },
+                "descent": {
+                    "description": "Meters",
+                    "type": "number"
+                },
+                "distance": {
+                    "description": "Meters",
+                    "type": "number"
+                },
+                "duration": {
+                    "description": "Seconds estimated by the routing engine",
+                    "type": "number"
+                },
+                "elevation_profile": {
+                    "description": "[[distance from start, elevation], ...] in meters",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "profile": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RouteWeatherResponse": {
+            "type": "object",
+            "properties": {
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RouteWeatherSample"
+                    }
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "speed": {
+                    "description": "Kilometers per hour",
+                    "type": "number"
+                },
+                "start_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RouteWeatherSample": {
+            "type": "object",
+            "properties": {
+                "arrival_at": {
+                    "type": "string"
+                },
+                "distance": {
+                    "description": "Meters from the start of the route",
+                    "type": "number"
+                },
+                "forecast": {
+                    "description": "Null beyond the forecast horizon or in the past",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.WeatherForecast"
+                        }
+                    ]
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ServiceHealth": {
+            "type": "object",
+            "properties": {
+                "auth_service": {
+                    "$ref": "#/definitions/models.HealthStatus"
+                },
+                "location_service": {
+                    "$ref": "#/definitions/models.HealthStatus"
+                },
+                "session_service": {
+                    "$ref": "#/definitions/models.HealthStatus"
+                },
+                "user_service": {
+                    "$ref": "#/definitions/models.HealthStatus"
+                }
+            }
+        },
+        "models.SessionMedia": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "image or video",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Signed download URL",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SessionProgressResponse": {
+            "type": "object",
+            "properties": {
+                "daylight": {
+                    "description": "Daylight at the latest position of a live session",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DaylightProgress"
+                        }
+                    ]
+                },
+                "next_waypoint": {
+                    "$ref": "#/definitions/models.Waypoint"
+                },
+                "remaining_waypoints": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "total_waypoints": {
+                    "type": "integer"
+                },
+                "visited_waypoints": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SessionReplayResponse": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "integer"
+                },
+                "frames": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReplayFrame"
+                    }
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "speed": {
+                    "description": "Recorded seconds per playback second",
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SessionSnapshot": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "string"
+                },
+                "files": {
+                    "description": "Manifest, track and photos",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Public URL of the manifest, the other files are next to it",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SessionStatsResponse": {
+            "type": "object",
+            "properties": {
+                "climbs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Climb"
+                    }
+                },
+                "daylight": {
+                    "description": "Sunrise and sunset at the start of the track",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SunTimes"
+                        }
+                    ]
+                },
+                "distance": {
+                    "description": "Meters within segments",
+                    "type": "number"
+                },
+                "duration": {
+                    "description": "Seconds from the first to the last location",
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "integer"
+                },
+                "gap_duration": {
+                    "description": "Seconds spent in gaps",
+                    "type": "integer"
+                },
+                "gaps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrackGap"
+                    }
+                },
+                "point_count": {
+                    "type": "integer"
+                },
+                "segment_count": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "integer"
+                },
+                "surface": {
+                    "description": "Surface statistics, once the ended session was matched to OSM ways",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SurfaceSummary"
+                        }
+                    ]
+                },
+                "tracked_duration": {
+                    "description": "Seconds spent within segments",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SessionSurface": {
+            "type": "object",
+            "properties": {
+                "matched_at": {
+                    "type": "string"
+                },
+                "segments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SurfaceSegment"
+                    }
+                },
+                "summary": {
+                    "$ref": "#/definitions/models.SurfaceSummary"
+                }
+            }
+        },
+        "models.SetWaypointVisitedRequest": {
+            "type": "object",
+            "properties": {
+                "visited": {
+                    "type": "boolean"
+                },
+                "visited_at": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SunTimes": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Local solar date, YYYY-MM-DD",
+                    "type": "string"
+                },
+                "day_length": {
+                    "description": "Seconds between sunrise and sunset",
+                    "type": "integer"
+                },
+                "polar_day": {
+                    "type": "boolean"
+                },
+                "polar_night": {
+                    "type": "boolean"
+                },
+                "sunrise": {
+                    "description": "Null during polar day and night",
+                    "type": "string"
+                },
+                "sunset": {
+                    "description": "Null during polar day and night",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SurfaceSegment": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "description": "[[lon, lat], ...] snapped to the ways",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "distance": {
+                    "description": "Meters",
+                    "type": "number"
+                },
+                "highway": {
+                    "description": "Road class, e.g. residential, or way use such as track or footway",
+                    "type": "string"
+                },
+                "surface": {
+                    "description": "Valhalla surface, e.g. paved_smooth, gravel or path",
+                    "type": "string"
+                },
+                "surface_type": {
+                    "description": "paved, unpaved or unknown",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SurfaceSummary": {
+            "type": "object",
+            "properties": {
+                "highways": {
+                    "description": "Meters per road type",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                },
+                "matched_distance": {
+                    "description": "Meters",
+                    "type": "number"
+                },
+                "paved_distance": {
+                    "type": "number"
+                },
+                "paved_percent": {
+                    "type": "number"
+                },
+                "surfaces": {
+                    "description": "Meters per surface",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                },
+                "unknown_distance": {
+                    "type": "number"
+                },
+                "unpaved_distance": {
+                    "type": "number"
+                },
+                "unpaved_percent": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.TileProvider": {
+            "type": "object",
+            "properties": {
+                "attribution": {
+                    "description": "HTML",
+                    "type": "string"
+                },
+                "max_zoom": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TimelineEntry": {
+            "type": "object",
+            "properties": {
+                "average_speed": {
+                    "description": "Meters per second",
+                    "type": "number"
+                },
+                "distance": {
+                    "description": "Movements",
+                    "type": "number"
+                },
+                "duration": {
+                    "description": "Seconds",
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "description": "Stays",
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "max_speed": {
+                    "description": "Meters per second between consecutive points",
+                    "type": "number"
+                },
+                "mode": {
+                    "description": "Detected transport mode: walking, cycling, driving or flying",
+                    "type": "string"
+                },
+                "point_count": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "stay or movement",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TimelineResponse": {
+            "type": "object",
+            "properties": {
+                "computed_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TimelineEntry"
+                    }
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TrackColoringResponse": {
+            "type": "object",
+            "properties": {
+                "color_ramp": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "max": {
+                    "type": "number"
+                },
+                "metric": {
+                    "type": "string"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "segments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ColoredSegment"
+                    }
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TrackExportCollection": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrackExportFeature"
+                    }
+                },
+                "type": {
+                    "description": "FeatureCollection",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TrackExportFeature": {
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/models.TrackExportGeometry"
+                },
+                "measures": {
+                    "$ref": "#/definitions/models.TrackMeasures"
+                },
+                "properties": {
+                    "$ref": "#/definitions/models.TrackExportProperties"
+                },
+                "type": {
+                    "description": "Feature",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TrackExportGeometry": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "description": "[lon, lat, altitude] positions, altitude only when known"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TrackExportProperties": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "segment": {
+                    "type": "integer"
+                },
+                "session": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TrackGap": {
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "description": "Straight-line meters across the gap",
+                    "type": "number"
+                },
+                "duration": {
+                    "description": "Seconds",
+                    "type": "integer"
+                },
+                "end_time": {
+                    "description": "Unix timestamp of the first location after the gap",
+                    "type": "integer"
+                },
+                "from": {
+                    "description": "[lon, lat]",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "start_time": {
+                    "description": "Unix timestamp of the last location before the gap",
+                    "type": "integer"
+                },
+                "to": {
+                    "description": "[lon, lat]",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "models.TrackMeasures": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "description": "Meters",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "cadence": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "heart_rate": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "power": {
+                    "description": "Watts",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "speed": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "temperature": {
+                    "description": "°C",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "time": {
+                    "description": "RFC 3339",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.TrackingProtocol": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "description": {
-                    "type": "string",
-                    "maxLength": 1000
+                "address": {
+                    "description": "host:port of non-HTTP protocols",
+                    "type": "string"
                 },
-                "name": {
-                    "type": "string",
-                    "maxLength": 100,
-                    "minLength": 1
+                "auth": {
+                    "description": "IDs of the accepted authentication methods",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "public": {
+                "content_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "service": {
+                    "type": "string"
+                },
+                "tls": {
                     "type": "boolean"
                 },
-                "title": {
-                    "type": "string",
-                    "maxLength": 200
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.TrackingSocketAck": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer"
+                "discarded": {
+                    "description": "Dropped by the downsampling policy",
+                    "type": "boolean"
                 },
-                "details": {
+                "error": {
                     "type": "string"
                 },
-                "message": {
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "description": "Position of the point in the stream, starting at 0",
+                    "type": "integer"
+                },
+                "session": {
                     "type": "string"
                 }
             }
         },
-        "models.Geometry": {
+        "models.UpdateLogLevelRequest": {
             "type": "object",
             "required": [
-                "coordinates",
-                "type"
+                "level"
             ],
             "properties": {
-                "coordinates": {
-                    "type": "array",
-                    "maxItems": 3,
-                    "minItems": 2,
-                    "items": {
-                        "type": "number"
-                    }
-                },
-                "type": {
+                "level": {
                     "type": "string",
                     "enum": [
-                        "Point"
+                        "trace",
+                        "debug",
+                        "info",
+                        "warn",
+                        "error",
+                        "disabled",
+                        "reset"
                     ]
+                },
+                "module": {
+                    "type": "string",
+                    "maxLength": 50
                 }
             }
         },
-        "models.LocationProperties": {
+        "models.UpdateOrganizationMemberRequest": {
             "type": "object",
             "required": [
-                "timestamp"
+                "role"
             ],
             "properties": {
-                "heart_rate": {
-                    "type": "number",
-                    "maximum": 300,
-                    "minimum": 0
-                },
-                "session": {
+                "role": {
                     "type": "string",
-                    "maxLength": 100
+                    "enum": [
+                        "owner",
+                        "admin",
+                        "member"
+                    ]
+                }
+            }
+        },
+        "models.UpdateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "default_session_public": {
+                    "type": "boolean"
                 },
-                "session_title": {
+                "email": {
                     "type": "string"
                 },
-                "speed": {
-                    "type": "number",
-                    "minimum": 0
+                "oldPassword": {
+                    "type": "string"
                 },
-                "timestamp": {
-                    "type": "integer",
-                    "minimum": 0
+                "password": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 6
                 },
                 "username": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 3
+                }
+            }
+        },
+        "models.UpdateSessionRequest": {
+            "type": "object",
+            "properties": {
+                "activity": {
+                    "description": "Optional - an empty string clears the activity",
                     "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "downsampling": {
+                    "description": "Optional - overrides the downsampling policy of the user's tracking defaults",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DownsamplingPolicy"
+                        }
+                    ]
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "reset_downsampling": {
+                    "description": "Optional - removes the session's own policy so the tracking defaults apply again",
+                    "type": "boolean"
+                },
+                "show_viewer_count": {
+                    "description": "Optional - shows the live viewer count to viewers of the session stream",
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 200
                 }
             }
         },
-        "models.LocationRequest": {
+        "models.UpdateTrackingDefaultsRequest": {
             "type": "object",
-            "required": [
-                "geometry",
-                "properties",
-                "type"
-            ],
             "properties": {
-                "geometry": {
-                    "$ref": "#/definitions/models.Geometry"
+                "accuracy_window": {
+                    "type": "integer",
+                    "maximum": 3600,
+                    "minimum": 1
                 },
-                "properties": {
-                    "$ref": "#/definitions/models.LocationProperties"
+                "auto_session_gap": {
+                    "type": "integer",
+                    "maximum": 10080,
+                    "minimum": 0
                 },
-                "type": {
+                "default_session_public": {
+                    "type": "boolean"
+                },
+                "downsampling": {
+                    "$ref": "#/definitions/models.DownsamplingPolicy"
+                },
+                "location_policy": {
                     "type": "string",
                     "enum": [
-                        "Feature"
+                        "newest",
+                        "most_accurate",
+                        "per_device"
+                    ]
+                },
+                "notifications": {
+                    "$ref": "#/definitions/models.NotificationPreferences"
+                },
+                "point_interval": {
+                    "type": "integer",
+                    "maximum": 3600,
+                    "minimum": 1
+                },
+                "privacy_zones_enabled": {
+                    "type": "boolean"
+                },
+                "public_badge": {
+                    "type": "boolean"
+                },
+                "speed_unit": {
+                    "type": "string",
+                    "enum": [
+                        "m/s",
+                        "km/h",
+                        "mph",
+                        "knots"
                     ]
+                },
+                "timezone": {
+                    "type": "string"
                 }
             }
         },
-        "models.LoginRequest": {
+        "models.UpdateUserRoleRequest": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "role"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "password": {
+                "role": {
                     "type": "string",
-                    "maxLength": 128,
-                    "minLength": 6
+                    "enum": [
+                        "admin",
+                        "moderator",
+                        "user",
+                        "readonly"
+                    ]
                 }
             }
         },
-        "models.RefreshTokenRequest": {
+        "models.UpdateWaypointRequest": {
             "type": "object",
-            "required": [
-                "refreshToken"
-            ],
             "properties": {
-                "refreshToken": {
+                "altitude": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "minLength": 1
+                },
+                "order": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "session_id": {
+                    "description": "Empty string unlinks the waypoint from its session",
                     "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "generic",
+                        "food",
+                        "water",
+                        "shelter",
+                        "transition",
+                        "viewpoint",
+                        "camping",
+                        "parking",
+                        "danger",
+                        "medical",
+                        "fuel"
+                    ]
                 }
             }
         },
-        "models.SuccessResponse": {
+        "models.UploadLimit": {
             "type": "object",
             "properties": {
-                "data": {},
-                "message": {
-                    "type": "string"
+                "max_size": {
+                    "description": "Bytes",
+                    "type": "integer"
                 },
-                "status": {
-                    "type": "string"
+                "types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "models.UpdateProfileRequest": {
+        "models.Waypoint": {
             "type": "object",
             "properties": {
-                "email": {
+                "altitude": {
+                    "type": "number"
+                },
+                "created": {
                     "type": "string"
                 },
-                "oldPassword": {
+                "description": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "maxLength": 128,
-                    "minLength": 6
+                "description_html": {
+                    "description": "Description rendered from markdown, safe to embed",
+                    "type": "string"
                 },
-                "username": {
-                    "type": "string",
-                    "maxLength": 50,
-                    "minLength": 3
+                "id": {
+                    "type": "string"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "order": {
+                    "type": "integer"
+                },
+                "photo": {
+                    "type": "string"
+                },
+                "photo_url": {
+                    "description": "Signed download URL, expires after a few minutes",
+                    "type": "string"
+                },
+                "position_confidence": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "string"
+                },
+                "visited_at": {
+                    "type": "string"
                 }
             }
         },
-        "models.UpdateSessionRequest": {
+        "models.WeatherForecast": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string",
-                    "maxLength": 1000
+                "precipitation": {
+                    "description": "Millimeters",
+                    "type": "number"
                 },
-                "public": {
-                    "type": "boolean"
+                "precipitation_probability": {
+                    "description": "Percent",
+                    "type": "number"
                 },
-                "title": {
-                    "type": "string",
-                    "maxLength": 200
+                "temperature": {
+                    "description": "Degrees Celsius",
+                    "type": "number"
+                },
+                "time": {
+                    "description": "Start of the forecast hour",
+                    "type": "string"
+                },
+                "weather_code": {
+                    "description": "WMO weather interpretation code",
+                    "type": "integer"
+                },
+                "wind_direction": {
+                    "description": "Degrees",
+                    "type": "number"
+                },
+                "wind_speed": {
+                    "description": "Meters per second",
+                    "type": "number"
                 }
             }
         }
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// APRSBridge follows amateur radio stations on APRS-IS and tracks their position beacons, for
+// hikers carrying an APRS radio where there is no cellular coverage. It logs in receive-only with
+// a budlist filter of the followed callsigns and reconnects when the connection is lost.
+type APRSBridge struct {
+	tracking *TrackingHandler
+	server   string
+	login    string
+	follows  map[string]utils.APRSFollow // By upper-case callsign
+
+	mu     sync.Mutex
+	conn   net.Conn
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAPRSBridge creates a bridge following the given callsigns through an APRS-IS server
+func NewAPRSBridge(tracking *TrackingHandler, server, login string, follows []utils.APRSFollow) *APRSBridge {
+	bridge := &APRSBridge{
+		tracking: tracking,
+		server:   server,
+		login:    login,
+		follows:  map[string]utils.APRSFollow{},
+	}
+	for _, follow := range follows {
+		bridge.follows[follow.Callsign] = follow
+	}
+	return bridge
+}
+
+// Start connects to APRS-IS in the background
+func (b *APRSBridge) Start(e *core.ServeEvent) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.done = make(chan struct{})
+
+	go b.run(ctx)
+
+	utils.LogInfo().Str("server", b.server).Int("callsigns", len(b.follows)).Msg("APRS bridge started")
+	return nil
+}
+
+// Stop closes the connection and waits for the bridge to finish
+func (b *APRSBridge) Stop(e *core.TerminateEvent) error {
+	if b.cancel == nil {
+		return nil
+	}
+
+	b.cancel()
+	b.mu.Lock()
+	if b.conn != nil {
+		b.conn.Close()
+	}
+	b.mu.Unlock()
+
+	<-b.done
+	return nil
+}
+
+func (b *APRSBridge) run(ctx context.Context) {
+	defer close(b.done)
+
+	for {
+		err := b.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		utils.LogWarn().Err(err).Str("server", b.server).Msg("APRS-IS connection lost, reconnecting")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(constants.APRSReconnectDelay):
+		}
+	}
+}
+
+// session logs in and tracks the received packets until the connection fails
+func (b *APRSBridge) session(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: constants.APRSReadTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", b.server)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	b.mu.Lock()
+	b.conn = conn
+	b.mu.Unlock()
+	if ctx.Err() != nil {
+		return ctx.Err() // Stopped while connecting
+	}
+
+	callsigns := make([]string, 0, len(b.follows))
+	for callsign := range b.follows {
+		callsigns = append(callsigns, callsign)
+	}
+	loginLine := fmt.Sprintf("user %s pass %s vers vibe-tracker %s filter b/%s\r\n",
+		b.login, constants.APRSPasscode, constants.AppVersion, strings.Join(callsigns, "/"))
+	if _, err := conn.Write([]byte(loginLine)); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, constants.APRSMaxLineSize), constants.APRSMaxLineSize)
+	for {
+		conn.SetReadDeadline(time.Now().Add(constants.APRSReadTimeout))
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return err
+			}
+			return errors.New("connection closed by server")
+		}
+
+		position, err := utils.ParseAPRSPacket(scanner.Text(), time.Now())
+		if err != nil {
+			if !errors.Is(err, utils.ErrAPRSNoPosition) {
+				utils.LogDebug().Err(err).Msg("Dropped invalid APRS packet")
+			}
+			continue
+		}
+		b.store(position)
+	}
+}
+
+// store tracks a beacon of a followed callsign. APRS is unauthenticated, a callsign is only
+// followed on behalf of the user it is configured for.
+func (b *APRSBridge) store(position *utils.APRSPosition) {
+	follow, ok := b.follows[position.Source]
+	if !ok {
+		return // The server passed a station that is not followed
+	}
+	logger := utils.LogWarn().Str("callsign", follow.Callsign).Str("username", follow.Username)
+
+	dao := b.tracking.app.Dao()
+	user, err := dao.FindFirstRecordByFilter(constants.CollectionUsers, "username = {:username}", dbx.Params{"username": follow.Username})
+	if err != nil {
+		logger.Msg("Dropped APRS position of an unknown user")
+		return
+	}
+	if !utils.HasPermission(middleware.GetUserRole(user), constants.PermTrackingWrite) {
+		logger.Msg("Dropped APRS position of a user whose role may not track")
+		return
+	}
+
+	params := &appmodels.TrackingQueryParams{
+		Token:     follow.Callsign, // APRS stations are identified by their callsign, they have no token
+		Latitude:  position.Latitude,
+		Longitude: position.Longitude,
+		Altitude:  position.Altitude,
+		Speed:     position.Speed,
+		SpeedUnit: constants.APRSSpeedUnit,
+		Device:    follow.Callsign,
+		Session:   follow.Session,
+	}
+	if position.Course != nil && *position.Course >= 0 && *position.Course <= 360 {
+		params.Bearing = position.Course
+	}
+	if err := utils.ValidateStruct(params); err != nil {
+		logger.Err(err).Msg("Dropped invalid APRS position")
+		return
+	}
+	// Most beacons carry no timestamp and are tracked at the time they are received
+	if !position.Time.IsZero() {
+		params.Timestamp = position.Time.Unix()
+		if err := b.tracking.validateTimestamp(params.Timestamp, false); err != nil {
+			logger.Err(err).Msg("Dropped APRS position with an invalid timestamp")
+			return
+		}
+	}
+
+	if _, err := b.tracking.trackPoint(context.Background(), user, params); err != nil {
+		logger.Err(err).Msg("Failed to store APRS position")
+	}
+}
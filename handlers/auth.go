@@ -1,17 +1,21 @@
 package handlers
 
 import (
+	"io"
+	"mime/multipart"
 	"net/http"
 	"strings"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/models"
-	"github.com/pocketbase/pocketbase/tokens"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/security"
 
+	"vibe-tracker/constants"
 	"vibe-tracker/middleware"
 	appmodels "vibe-tracker/models"
 	"vibe-tracker/services"
@@ -19,14 +23,20 @@ import (
 )
 
 type AuthHandler struct {
-	app         *pocketbase.PocketBase
-	authService *services.AuthService
+	app            *pocketbase.PocketBase
+	authService    *services.AuthService
+	userService    *services.UserService
+	anomalyService *services.LoginAnomalyService // nil when login anomaly detection is disabled
+	blacklist      *middleware.TokenBlacklist
 }
 
-func NewAuthHandler(app *pocketbase.PocketBase, authService *services.AuthService) *AuthHandler {
+func NewAuthHandler(app *pocketbase.PocketBase, authService *services.AuthService, userService *services.UserService, anomalyService *services.LoginAnomalyService, blacklist *middleware.TokenBlacklist) *AuthHandler {
 	return &AuthHandler{
-		app:         app,
-		authService: authService,
+		app:            app,
+		authService:    authService,
+		userService:    userService,
+		anomalyService: anomalyService,
+		blacklist:      blacklist,
 	}
 }
 
@@ -50,11 +60,15 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return apis.NewBadRequestError("Invalid request data", nil)
 	}
 
-	response, err := h.authService.Login(*req)
+	response, err := h.authService.WithContext(c.Request().Context()).Login(*req, clientInfo(c))
 	if err != nil {
 		return err // Let middleware handle the structured error
 	}
 
+	if h.anomalyService != nil {
+		h.anomalyService.CheckLogin(utils.TokenSessionID(response.Token))
+	}
+
 	return utils.SendSuccess(c, http.StatusOK, response, "Login successful")
 }
 
@@ -84,10 +98,15 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 		return apis.NewUnauthorizedError("Invalid or expired token", err)
 	}
 
-	// Generate new token
-	newToken, err := tokens.NewRecordAuthToken(h.app, record)
+	sessionID := utils.TokenSessionID(token)
+	if sessionID == "" || h.blacklist.IsBlacklisted(sessionID) {
+		return apis.NewUnauthorizedError("Token has been revoked", nil)
+	}
+
+	// Generate new token for the same device session
+	newToken, err := h.authService.WithContext(c.Request().Context()).IssueToken(record, clientInfo(c), sessionID)
 	if err != nil {
-		return apis.NewApiError(http.StatusInternalServerError, "Failed to generate new token", err)
+		return apis.NewUnauthorizedError("Device session is no longer active", err)
 	}
 
 	userData := map[string]any{
@@ -98,12 +117,48 @@ func (h *AuthHandler) RefreshToken(c echo.Context) error {
 			"email":                  record.Email(),
 			"avatar":                 record.GetString("avatar"),
 			"default_session_public": record.GetBool("default_session_public"),
+			"role":                   middleware.GetUserRole(record),
 		},
 	}
 
 	return utils.SendSuccess(c, http.StatusOK, userData, "Token refreshed successfully")
 }
 
+// CheckAuthRefresh rejects refreshing a token of a revoked device session, or of none, with
+// PocketBase's auth-refresh API
+func (h *AuthHandler) CheckAuthRefresh(e *core.RecordAuthRefreshEvent) error {
+	token := strings.TrimPrefix(e.HttpContext.Request().Header.Get("Authorization"), "Bearer ")
+	sessionID := utils.TokenSessionID(token)
+	if sessionID == "" || h.blacklist.IsBlacklisted(sessionID) {
+		return apis.NewUnauthorizedError("Token has been revoked", nil)
+	}
+	return nil
+}
+
+// IssueDeviceSessionToken replaces the token of PocketBase's auth APIs with one bound to a device
+// session, like the tokens of Login: a refresh extends the device session of the refreshed token,
+// other logins start a new one.
+func (h *AuthHandler) IssueDeviceSessionToken(e *core.RecordAuthEvent) error {
+	sessionID := ""
+	if strings.HasSuffix(e.HttpContext.Request().URL.Path, "/auth-refresh") {
+		sessionID = utils.TokenSessionID(strings.TrimPrefix(e.HttpContext.Request().Header.Get("Authorization"), "Bearer "))
+		if sessionID == "" {
+			return apis.NewUnauthorizedError("Token has been revoked", nil)
+		}
+	}
+
+	token, err := h.authService.WithContext(e.HttpContext.Request().Context()).IssueToken(e.Record, clientInfo(e.HttpContext), sessionID)
+	if err != nil {
+		return apis.NewUnauthorizedError("Device session is no longer active", err)
+	}
+	e.Token = token
+
+	if sessionID == "" && h.anomalyService != nil {
+		h.anomalyService.CheckLogin(utils.TokenSessionID(token))
+	}
+	return nil
+}
+
 // GetMe returns the current authenticated user's profile
 //
 //	@Summary		Get current user profile
@@ -128,6 +183,7 @@ func (h *AuthHandler) GetMe(c echo.Context) error {
 		"avatar":                 info.GetString("avatar"),
 		"token":                  info.GetString("token"),
 		"default_session_public": info.GetBool("default_session_public"),
+		"role":                   middleware.GetUserRole(info),
 	}
 
 	return utils.SendSuccess(c, http.StatusOK, userData, "")
@@ -159,7 +215,7 @@ func (h *AuthHandler) UpdateProfile(c echo.Context) error {
 		return apis.NewBadRequestError("Invalid request data", nil)
 	}
 
-	err := h.authService.UpdateProfile(record, *req)
+	err := h.authService.WithContext(c.Request().Context()).UpdateProfile(record, *req)
 	if err != nil {
 		return err // Let middleware handle the structured error
 	}
@@ -171,6 +227,7 @@ func (h *AuthHandler) UpdateProfile(c echo.Context) error {
 		"avatar":                 record.GetString("avatar"),
 		"token":                  record.GetString("token"),
 		"default_session_public": record.GetBool("default_session_public"),
+		"role":                   middleware.GetUserRole(record),
 	}
 
 	return utils.SendSuccess(c, http.StatusOK, userData, "Profile updated successfully")
@@ -179,14 +236,14 @@ func (h *AuthHandler) UpdateProfile(c echo.Context) error {
 // UploadAvatar uploads a new avatar for the current user
 //
 //	@Summary		Upload user avatar
-//	@Description	Uploads a new avatar image for the authenticated user
+//	@Description	Uploads a new avatar image for the authenticated user. The image is cropped to a square, scaled down and stored as PNG; only the avatar field of the user is changed.
 //	@Tags			Authentication
 //	@Accept			multipart/form-data
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			avatar	formData	file					true	"Avatar image file"
 //	@Success		200		{object}	models.SuccessResponse	"Avatar uploaded successfully"
-//	@Failure		400		{object}	models.ErrorResponse		"Invalid file or request"
+//	@Failure		400		{object}	models.ErrorResponse		"Invalid file, image dimensions or request"
 //	@Failure		401		{object}	models.ErrorResponse		"Authentication required"
 //	@Router			/profile/avatar [post]
 func (h *AuthHandler) UploadAvatar(c echo.Context) error {
@@ -195,19 +252,29 @@ func (h *AuthHandler) UploadAvatar(c echo.Context) error {
 		return apis.NewUnauthorizedError("Authentication required", nil)
 	}
 
-	// Use PocketBase forms to handle file upload properly
-	form := forms.NewRecordUpsert(h.app, record)
+	data := middleware.GetValidatedData(c).(*appmodels.UploadAvatarRequest)
 
-	// Load the multipart form data
-	if err := form.LoadRequest(c.Request(), ""); err != nil {
-		return apis.NewBadRequestError("Failed to parse form data", err)
+	avatar, err := readAvatar(data.Avatar)
+	if err != nil {
+		return err
 	}
 
-	// Submit the form (this will handle file upload automatically)
+	file, err := filesystem.NewFileFromBytes(avatar, "avatar.png")
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to save avatar", err)
+	}
+
+	// Only the avatar is taken from the upload, other form values never reach the record
+	form := forms.NewRecordUpsert(h.app, record)
+	if err := form.AddFiles("avatar", file); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to save avatar", err)
+	}
 	if err := form.Submit(); err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to save avatar", err)
 	}
 
+	h.createAvatarThumbs(record)
+
 	// Return updated user data
 	userData := map[string]any{
 		"id":                     record.Id,
@@ -216,11 +283,55 @@ func (h *AuthHandler) UploadAvatar(c echo.Context) error {
 		"avatar":                 record.GetString("avatar"),
 		"token":                  record.GetString("token"),
 		"default_session_public": record.GetBool("default_session_public"),
+		"role":                   middleware.GetUserRole(record),
 	}
 
 	return utils.SendSuccess(c, http.StatusOK, userData, "Avatar updated successfully")
 }
 
+// readAvatar reads an uploaded avatar and returns it normalized by utils.ProcessAvatar
+func readAvatar(fileHeader *multipart.FileHeader) ([]byte, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, apis.NewBadRequestError("Failed to read avatar", err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, apis.NewBadRequestError("Failed to read avatar", err)
+	}
+
+	avatar, err := utils.ProcessAvatar(content)
+	if err != nil {
+		if _, invalid := err.(utils.ValidationErrors); invalid {
+			return nil, apis.NewBadRequestError("Validation failed", err)
+		}
+		return nil, apis.NewApiError(http.StatusInternalServerError, "Failed to process avatar", err)
+	}
+	return avatar, nil
+}
+
+// createAvatarThumbs creates the resized avatar variants right away, so the first map or profile
+// view does not wait for them. Failures are logged only, PocketBase creates missing thumbs on demand.
+func (h *AuthHandler) createAvatarThumbs(record *models.Record) {
+	fs, err := h.app.NewFilesystem()
+	if err != nil {
+		utils.LogError(err, "avatar thumbs").Str("user_id", record.Id).Msg("Failed to open file storage")
+		return
+	}
+	defer fs.Close()
+
+	filename := record.GetString("avatar")
+	original := record.BaseFilesPath() + "/" + filename
+	for _, size := range []string{constants.AvatarThumbSmall, constants.AvatarThumbMedium} {
+		thumb := record.BaseFilesPath() + "/thumbs_" + filename + "/" + size + "_" + filename
+		if err := fs.CreateThumb(original, thumb, size); err != nil {
+			utils.LogError(err, "avatar thumbs").Str("user_id", record.Id).Str("size", size).Msg("Failed to create avatar thumb")
+		}
+	}
+}
+
 // RegenerateToken generates a new custom token for the user
 //
 //	@Summary		Regenerate custom token
@@ -242,7 +353,7 @@ func (h *AuthHandler) RegenerateToken(c echo.Context) error {
 	newToken := security.RandomString(12)
 	record.Set("token", newToken)
 
-	if err := h.app.Dao().SaveRecord(record); err != nil {
+	if err := requestDao(h.app, c).SaveRecord(record); err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to regenerate token", err)
 	}
 
@@ -253,7 +364,254 @@ func (h *AuthHandler) RegenerateToken(c echo.Context) error {
 		"avatar":                 record.GetString("avatar"),
 		"token":                  newToken,
 		"default_session_public": record.GetBool("default_session_public"),
+		"role":                   middleware.GetUserRole(record),
 	}
 
 	return utils.SendSuccess(c, http.StatusOK, userData, "Token regenerated successfully")
 }
+
+// ListDeviceSessions lists the devices the current user is logged in on
+//
+//	@Summary		List device sessions
+//	@Description	Returns the authenticated user's active logins per device with user agent, IP address and last activity. With history=true revoked and expired logins are included as login history.
+//	@Tags			Authentication
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			history	query		bool	false	"Include revoked and expired sessions"
+//	@Success		200		{object}	models.SuccessResponse{data=[]models.DeviceSession}	"Device sessions retrieved successfully"
+//	@Failure		401		{object}	models.ErrorResponse								"Authentication required"
+//	@Router			/profile/sessions [get]
+func (h *AuthHandler) ListDeviceSessions(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	records, err := h.authService.WithContext(c.Request().Context()).ListDeviceSessions(record.Id, c.QueryParam("history") == "true")
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch device sessions", err)
+	}
+
+	currentSessionID := currentDeviceSessionID(c)
+	sessions := make([]appmodels.DeviceSession, len(records))
+	for i, session := range records {
+		sessions[i] = recordToDeviceSession(session, currentSessionID)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, sessions, "")
+}
+
+// RevokeDeviceSession logs the current user out on one device
+//
+//	@Summary		Revoke device session
+//	@Description	Revokes a device session; tokens issued to it are rejected immediately
+//	@Tags			Authentication
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Device session ID"
+//	@Success		200	{object}	models.SuccessResponse	"Device session revoked successfully"
+//	@Failure		401	{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		404	{object}	models.ErrorResponse	"Device session not found"
+//	@Router			/profile/sessions/{id} [delete]
+func (h *AuthHandler) RevokeDeviceSession(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	revoked, err := h.authService.WithContext(c.Request().Context()).RevokeDeviceSessions(record.Id, []string{c.PathParam("id")}, "")
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+	if len(revoked) == 0 {
+		return apis.NewNotFoundError("Device session not found", nil)
+	}
+
+	h.blacklistDeviceSessions(revoked)
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Device session revoked successfully")
+}
+
+// RevokeOtherDeviceSessions logs the current user out everywhere except on the requesting device
+//
+//	@Summary		Revoke other device sessions
+//	@Description	Revokes all of the user's device sessions except the one of the requesting token
+//	@Tags			Authentication
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.SuccessResponse	"Other device sessions revoked successfully"
+//	@Failure		401	{object}	models.ErrorResponse	"Authentication required"
+//	@Router			/profile/sessions [delete]
+func (h *AuthHandler) RevokeOtherDeviceSessions(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	revoked, err := h.authService.WithContext(c.Request().Context()).RevokeDeviceSessions(record.Id, nil, currentDeviceSessionID(c))
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	h.blacklistDeviceSessions(revoked)
+
+	return utils.SendSuccess(c, http.StatusOK, map[string]int{"revoked": len(revoked)}, "Other device sessions revoked successfully")
+}
+
+// RestoreRevokedDeviceSessions reloads the token blacklist with revoked device sessions that have not expired yet
+func (h *AuthHandler) RestoreRevokedDeviceSessions(e *core.ServeEvent) error {
+	revoked, err := h.authService.FindRevokedDeviceSessions()
+	if err != nil {
+		// The collection is missing until migrations have run
+		utils.LogWarn().Err(err).Msg("Failed to restore revoked device sessions")
+		return nil
+	}
+
+	h.blacklistDeviceSessions(revoked)
+	return nil
+}
+
+func (h *AuthHandler) blacklistDeviceSessions(sessions []*models.Record) {
+	for _, session := range sessions {
+		h.blacklist.BlacklistToken(session.GetString("jti"), session.GetDateTime("expires_at").Time())
+	}
+}
+
+// Reauthenticate confirms the user's password for the current device session
+//
+//	@Summary		Re-authenticate
+//	@Description	Confirms the password for the requesting device session. Sensitive actions such as token regeneration are refused with 403 and reauth_required for a while after a login from a new country or network until the password is confirmed.
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.ReauthRequest	true	"Current password"
+//	@Success		200		{object}	models.SuccessResponse	"Re-authentication successful"
+//	@Failure		400		{object}	models.ErrorResponse	"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse	"Invalid credentials"
+//	@Router			/auth/reauth [post]
+func (h *AuthHandler) Reauthenticate(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	data := middleware.GetValidatedData(c)
+	req, ok := data.(*appmodels.ReauthRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	if err := h.authService.WithContext(c.Request().Context()).Reauthenticate(record, currentDeviceSessionID(c), req.Password); err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Re-authentication successful")
+}
+
+// GetTrackingDefaults returns the current user's tracking defaults
+//
+//	@Summary		Get tracking defaults
+//	@Description	Returns the authenticated user's default session privacy, automatic session gap, point frequency hint, privacy zones toggle and notification preferences
+//	@Tags			Authentication
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.SuccessResponse	"Tracking defaults retrieved successfully"
+//	@Failure		401	{object}	models.ErrorResponse	"Authentication required"
+//	@Router			/profile/tracking-defaults [get]
+func (h *AuthHandler) GetTrackingDefaults(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, h.userService.GetTrackingDefaults(record), "")
+}
+
+// UpdateTrackingDefaults updates the current user's tracking defaults
+//
+//	@Summary		Update tracking defaults
+//	@Description	Updates the authenticated user's tracking defaults; omitted fields are left unchanged
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.UpdateTrackingDefaultsRequest	true	"Tracking defaults"
+//	@Success		200		{object}	models.SuccessResponse					"Tracking defaults updated successfully"
+//	@Failure		400		{object}	models.ErrorResponse					"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse					"Authentication required"
+//	@Router			/profile/tracking-defaults [put]
+func (h *AuthHandler) UpdateTrackingDefaults(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	data := middleware.GetValidatedData(c)
+	req, ok := data.(*appmodels.UpdateTrackingDefaultsRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	defaults, err := h.userService.WithContext(c.Request().Context()).UpdateTrackingDefaults(record, *req)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, defaults, "Tracking defaults updated successfully")
+}
+
+// UpdateUserRole changes the access control role of a user
+//
+//	@Summary		Update user role
+//	@Description	Changes a user's role (admin, moderator, user or readonly); requires the users:manage permission
+//	@Tags			Authentication
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string						true	"Username"
+//	@Param			request		body		models.UpdateUserRoleRequest	true	"New role"
+//	@Success		200			{object}	models.SuccessResponse		"Role updated successfully"
+//	@Failure		400			{object}	models.ErrorResponse			"Invalid request"
+//	@Failure		401			{object}	models.ErrorResponse			"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse			"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse			"User not found"
+//	@Router			/users/{username}/role [put]
+func (h *AuthHandler) UpdateUserRole(c echo.Context) error {
+	authUser, exists := GetAuthUser(c)
+	if !exists {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	// Get validated data from middleware
+	data := middleware.GetValidatedData(c)
+	req, ok := data.(*appmodels.UpdateUserRoleRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	// Prevent admins from locking themselves out
+	if user.Id == authUser.Id {
+		return apis.NewBadRequestError("Cannot change your own role", nil)
+	}
+
+	user.Set("role", req.Role)
+	if err := requestDao(h.app, c).SaveRecord(user); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to update role", err)
+	}
+
+	userData := map[string]any{
+		"id":       user.Id,
+		"username": user.Username(),
+		"role":     middleware.GetUserRole(user),
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, userData, "Role updated successfully")
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+
+	"vibe-tracker/config"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+type BrandingHandler struct {
+	config *config.AppConfig
+}
+
+func NewBrandingHandler(cfg *config.AppConfig) *BrandingHandler {
+	return &BrandingHandler{
+		config: cfg,
+	}
+}
+
+// GetBranding returns the instance branding, map defaults and available features
+//
+//	@Summary		Get instance branding
+//	@Description	Returns the instance name, logo, initial map view, tile provider and the optional features enabled by the server configuration, so clients can configure themselves per instance
+//	@Tags			Public
+//	@Produce		json
+//	@Success		200	{object}	models.SuccessResponse{data=models.BrandingResponse}	"Instance branding"
+//	@Router			/config/branding [get]
+func (h *BrandingHandler) GetBranding(c echo.Context) error {
+	branding := h.config.Branding
+
+	response := appmodels.BrandingResponse{
+		Name:    branding.InstanceName,
+		LogoURL: branding.LogoURL,
+		Map: appmodels.MapDefaults{
+			Center: [2]float64{branding.MapCenterLat, branding.MapCenterLon},
+			Zoom:   branding.MapZoom,
+			Tiles: appmodels.TileProvider{
+				URL:         branding.TileURL,
+				Attribution: branding.TileAttribution,
+				MaxZoom:     branding.TileMaxZoom,
+			},
+		},
+		Features: instanceFeatures(h.config),
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, response, "")
+}
+
+// instanceFeatures derives the optional features available on the instance from its configuration
+func instanceFeatures(cfg *config.AppConfig) appmodels.InstanceFeatures {
+	tracking := cfg.Tracking
+	return appmodels.InstanceFeatures{
+		ReadOnly:         cfg.ReadOnly,
+		GraphQL:          cfg.GraphQLEnabled,
+		RoutePlanning:    tracking.RoutingURL != "",
+		Elevation:        tracking.ElevationURL != "",
+		Weather:          tracking.WeatherURL != "",
+		MapMatching:      tracking.MapMatchURL != "",
+		CountryStats:     tracking.ReverseGeocodeURL != "",
+		WaypointCheckOff: tracking.WaypointVisitRadius > 0,
+		LowBatteryAlerts: tracking.LowBatteryThreshold > 0,
+		GRPCIngest:       tracking.GRPCAddress != "",
+		HardwareTrackers: len(tracking.TrackerAddresses) > 0,
+		APRS:             len(tracking.APRSFollow) > 0,
+		MapShare:         tracking.MapSharePollInterval > 0,
+		HomeAssistant:    cfg.HomeAssistant.MQTTURL != "" && len(cfg.HomeAssistant.Users) > 0,
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+type CaptchaHandler struct {
+	verifier *middleware.CaptchaVerifier // nil when no CAPTCHA is configured
+}
+
+func NewCaptchaHandler(verifier *middleware.CaptchaVerifier) *CaptchaHandler {
+	return &CaptchaHandler{
+		verifier: verifier,
+	}
+}
+
+// GetCaptchaChallenge returns the CAPTCHA configuration, or a fresh proof-of-work challenge
+//
+//	@Summary		Get CAPTCHA challenge
+//	@Description	Returns the CAPTCHA provider and site key for hCaptcha or Turnstile, or a new challenge for the built-in proof-of-work. The solution is sent in the X-Captcha-Token header on registration, password reset and logins after repeated failures.
+//	@Tags			Authentication
+//	@Produce		json
+//	@Success		200	{object}	models.SuccessResponse{data=models.CaptchaChallenge}	"CAPTCHA challenge"
+//	@Router			/auth/captcha [get]
+func (h *CaptchaHandler) GetCaptchaChallenge(c echo.Context) error {
+	if h.verifier == nil {
+		return utils.SendSuccess(c, http.StatusOK, appmodels.CaptchaChallenge{}, "")
+	}
+
+	response := appmodels.CaptchaChallenge{
+		Provider: h.verifier.Provider(),
+		SiteKey:  h.verifier.SiteKey(),
+	}
+
+	if response.Provider == constants.CaptchaProviderPoW {
+		challenge, expiresAt := h.verifier.NewPoWChallenge()
+		response.Challenge = challenge
+		response.Difficulty = h.verifier.PoWDifficulty()
+		response.ExpiresAt = &expiresAt
+	}
+
+	// Challenges are single use
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	return utils.SendSuccess(c, http.StatusOK, response, "")
+}
@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+type CommunityHandler struct {
+	app *pocketbase.PocketBase
+}
+
+func NewCommunityHandler(app *pocketbase.PocketBase) *CommunityHandler {
+	return &CommunityHandler{
+		app: app,
+	}
+}
+
+// ListCommunityWaypoints lists visible community waypoints inside a bounding box
+//
+//	@Summary		List community waypoints
+//	@Description	Returns visible community waypoints inside the bounding box as a GeoJSON FeatureCollection
+//	@Tags			Community
+//	@Produce		json
+//	@Param			bbox	query		string	true	"Bounding box as minLon,minLat,maxLon,maxLat"
+//	@Param			type	query		string	false	"Waypoint type filter"
+//	@Success		200		{object}	models.SuccessResponse	"Community waypoints retrieved successfully"
+//	@Failure		400		{object}	models.ErrorResponse	"Invalid bounding box"
+//	@Router			/community/waypoints [get]
+func (h *CommunityHandler) ListCommunityWaypoints(c echo.Context) error {
+	bbox, err := utils.ParseBoundingBox(c.QueryParam("bbox"))
+	if err != nil {
+		return apis.NewBadRequestError("Invalid bbox parameter", err)
+	}
+
+	filter := "status = 'visible' && latitude >= {:min_lat} && latitude <= {:max_lat} && " +
+		"longitude >= {:min_lon} && longitude <= {:max_lon}"
+	params := dbx.Params{
+		"min_lat": bbox.MinLat,
+		"max_lat": bbox.MaxLat,
+		"min_lon": bbox.MinLon,
+		"max_lon": bbox.MaxLon,
+	}
+
+	if waypointType := c.QueryParam("type"); waypointType != "" {
+		filter += " && type = {:type}"
+		params["type"] = waypointType
+	}
+
+	records, err := requestDao(h.app, c).FindRecordsByFilter(
+		"community_waypoints",
+		filter,
+		"-updated",
+		constants.CommunityWaypointsLimit,
+		0,
+		params,
+	)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch community waypoints", err)
+	}
+
+	features := make([]map[string]any, len(records))
+	for i, record := range records {
+		features[i] = communityWaypointToFeature(record)
+	}
+
+	response := map[string]any{
+		"type":      "FeatureCollection",
+		"features":  features,
+		"truncated": len(records) == constants.CommunityWaypointsLimit,
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, response, "")
+}
+
+// PublishCommunityWaypoint publishes one of the user's waypoints to the community layer
+//
+//	@Summary		Publish community waypoint
+//	@Description	Publishes a copy of an owned waypoint to the community layer with attribution to the user
+//	@Tags			Community
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.PublishCommunityWaypointRequest	true	"Waypoint to publish"
+//	@Success		201		{object}	models.SuccessResponse					"Waypoint published successfully"
+//	@Failure		400		{object}	models.ErrorResponse					"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse					"Authentication required"
+//	@Failure		403		{object}	models.ErrorResponse					"Forbidden"
+//	@Failure		404		{object}	models.ErrorResponse					"Waypoint not found"
+//	@Router			/community/waypoints [post]
+func (h *CommunityHandler) PublishCommunityWaypoint(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.PublishCommunityWaypointRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	waypoint, err := requestDao(h.app, c).FindRecordById("waypoints", data.WaypointID)
+	if err != nil {
+		return apis.NewNotFoundError("Waypoint not found", err)
+	}
+
+	if !canAccess(c, constants.PermCommunityPublish, waypoint.GetString("user")) {
+		return apis.NewForbiddenError("Cannot publish another user's waypoints", nil)
+	}
+
+	// Re-publishing refreshes the existing entry instead of creating a duplicate
+	published, err := requestDao(h.app, c).FindFirstRecordByFilter("community_waypoints", "waypoint = {:waypoint}",
+		dbx.Params{"waypoint": waypoint.Id})
+	status := http.StatusOK
+	if err != nil {
+		collection, err := requestDao(h.app, c).FindCollectionByNameOrId("community_waypoints")
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Community waypoints collection not found", err)
+		}
+		published = models.NewRecord(collection)
+		published.Set("waypoint", waypoint.Id)
+		published.Set("user", record.Id)
+		published.Set("status", "visible")
+		published.Set("flag_count", 0)
+		status = http.StatusCreated
+	} else if published.GetString("status") == "hidden" {
+		return apis.NewForbiddenError("This waypoint has been hidden by moderation", nil)
+	}
+
+	published.Set("attribution", record.GetString("username"))
+	published.Set("name", waypoint.GetString("name"))
+	published.Set("type", waypoint.GetString("type"))
+	published.Set("description", waypoint.GetString("description"))
+	published.Set("latitude", waypoint.GetFloat("latitude"))
+	published.Set("longitude", waypoint.GetFloat("longitude"))
+	utils.SetRecordAltitude(published, utils.RecordAltitude(waypoint))
+
+	if err := requestDao(h.app, c).SaveRecord(published); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to publish waypoint", err)
+	}
+
+	return utils.SendSuccess(c, status, communityWaypointToFeature(published), "Waypoint published successfully")
+}
+
+// UnpublishCommunityWaypoint removes a waypoint from the community layer
+//
+//	@Summary		Unpublish community waypoint
+//	@Description	Removes a published waypoint from the community layer; moderators may remove any entry
+//	@Tags			Community
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Community waypoint ID"
+//	@Success		200	{object}	models.SuccessResponse	"Waypoint unpublished successfully"
+//	@Failure		401	{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		403	{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404	{object}	models.ErrorResponse	"Community waypoint not found"
+//	@Router			/community/waypoints/{id} [delete]
+func (h *CommunityHandler) UnpublishCommunityWaypoint(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	published, err := requestDao(h.app, c).FindRecordById("community_waypoints", c.PathParam("id"))
+	if err != nil {
+		return apis.NewNotFoundError("Community waypoint not found", err)
+	}
+
+	// Moderators may remove any entry, other users only their own
+	if !canAccess(c, constants.PermCommunityModerate, published.GetString("user")) {
+		return apis.NewForbiddenError("Cannot unpublish another user's waypoints", nil)
+	}
+
+	if err := requestDao(h.app, c).DeleteRecord(published); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to unpublish waypoint", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Waypoint unpublished successfully")
+}
+
+// FlagCommunityWaypoint reports a community waypoint for moderation
+//
+//	@Summary		Flag community waypoint
+//	@Description	Flags a community waypoint for moderation; waypoints with enough flags are hidden automatically
+//	@Tags			Community
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string								true	"Community waypoint ID"
+//	@Param			request	body		models.FlagCommunityWaypointRequest	true	"Flag reason"
+//	@Success		200		{object}	models.SuccessResponse				"Waypoint flagged successfully"
+//	@Failure		400		{object}	models.ErrorResponse				"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse				"Authentication required"
+//	@Failure		404		{object}	models.ErrorResponse				"Community waypoint not found"
+//	@Failure		409		{object}	models.ErrorResponse				"Already flagged"
+//	@Router			/community/waypoints/{id}/flag [post]
+func (h *CommunityHandler) FlagCommunityWaypoint(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.FlagCommunityWaypointRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	published, err := requestDao(h.app, c).FindRecordById("community_waypoints", c.PathParam("id"))
+	if err != nil || published.GetString("status") != "visible" {
+		return apis.NewNotFoundError("Community waypoint not found", err)
+	}
+
+	if _, err := requestDao(h.app, c).FindFirstRecordByFilter("community_waypoint_flags",
+		"community_waypoint = {:community_waypoint} && user = {:user}",
+		dbx.Params{"community_waypoint": published.Id, "user": record.Id}); err == nil {
+		return apis.NewApiError(http.StatusConflict, "You have already flagged this waypoint", nil)
+	}
+
+	err = requestDao(h.app, c).RunInTransaction(func(txDao *daos.Dao) error {
+		collection, err := txDao.FindCollectionByNameOrId("community_waypoint_flags")
+		if err != nil {
+			return err
+		}
+
+		flag := models.NewRecord(collection)
+		flag.Set("community_waypoint", published.Id)
+		flag.Set("user", record.Id)
+		flag.Set("reason", data.Reason)
+		flag.Set("comment", data.Comment)
+		if err := txDao.SaveRecord(flag); err != nil {
+			return err
+		}
+
+		flagCount := published.GetInt("flag_count") + 1
+		published.Set("flag_count", flagCount)
+		if flagCount >= constants.CommunityFlagHideThreshold {
+			published.Set("status", "hidden")
+		}
+		return txDao.SaveRecord(published)
+	})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to flag waypoint", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Waypoint flagged successfully")
+}
+
+// communityWaypointToFeature formats a community waypoint record as a GeoJSON Feature
+func communityWaypointToFeature(record *models.Record) map[string]any {
+	properties := map[string]any{
+		"id":               record.Id,
+		"name":             record.GetString("name"),
+		"type":             record.GetString("type"),
+		"description":      record.GetString("description"),
+		"description_html": utils.RenderMarkdown(record.GetString("description")),
+		"attribution":      record.GetString("attribution"),
+		"flag_count":       record.GetInt("flag_count"),
+		"created":          record.GetDateTime("created").Time().Format(time.RFC3339),
+		"updated":          record.GetDateTime("updated").Time().Format(time.RFC3339),
+	}
+
+	if altitude := utils.RecordAltitude(record); altitude != nil {
+		properties["altitude"] = *altitude
+	}
+
+	return map[string]any{
+		"type": "Feature",
+		"id":   record.Id,
+		"geometry": map[string]any{
+			"type": "Point",
+			"coordinates": []float64{
+				record.GetFloat("longitude"),
+				record.GetFloat("latitude"),
+			},
+		},
+		"properties": properties,
+	}
+}
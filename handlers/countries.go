@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/services"
+	"vibe-tracker/utils"
+)
+
+type CountryHandler struct {
+	app       *pocketbase.PocketBase
+	geocoding *services.GeocodingService
+}
+
+func NewCountryHandler(app *pocketbase.PocketBase, geocoding *services.GeocodingService) *CountryHandler {
+	return &CountryHandler{
+		app:       app,
+		geocoding: geocoding,
+	}
+}
+
+// GetCountries returns the countries and regions a user visited
+//
+//	@Summary		Get visited countries
+//	@Description	Returns the countries and regions found in the user's location history, with first and last visit, days spent and entry and exit of each visit. Locations are geocoded by a background job when a reverse geocoding service is configured; pending_locations counts those not included yet.
+//	@Tags			Statistics
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Success		200			{object}	models.SuccessResponse{data=models.CountriesResponse}	"Countries retrieved successfully"
+//	@Failure		401			{object}	models.ErrorResponse									"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse									"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse									"User not found"
+//	@Router			/users/{username}/countries [get]
+func (h *CountryHandler) GetCountries(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	if !canAccess(c, constants.PermSessionsRead, user.Id) {
+		return apis.NewForbiddenError("Cannot view another user's countries", nil)
+	}
+
+	countries, err := h.geocoding.Countries(user.Id)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, countries, "")
+}
+
+// GetCountryLayer returns the countries a user visited as a GeoJSON layer for a scratch map
+//
+//	@Summary		Get visited countries layer
+//	@Description	Returns a GeoJSON FeatureCollection with one feature per visited country: a point at the center of the user's locations there with their bounding box. Clients shade the country boundaries matching the country_code property.
+//	@Tags			Statistics
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Success		200			{object}	models.SuccessResponse{data=models.CountryFeatureCollection}	"Visited countries layer"
+//	@Failure		401			{object}	models.ErrorResponse			"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse			"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse			"User not found"
+//	@Router			/users/{username}/countries/geojson [get]
+func (h *CountryHandler) GetCountryLayer(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	if !canAccess(c, constants.PermSessionsRead, user.Id) {
+		return apis.NewForbiddenError("Cannot view another user's countries", nil)
+	}
+
+	layer, err := h.geocoding.CountryLayer(user.Id)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendGeoJSON(c, http.StatusOK, layer, "")
+}
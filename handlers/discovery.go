@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	pbmigrations "github.com/pocketbase/pocketbase/migrations"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// uploadForms are the multipart forms whose file limits are published as capabilities
+var uploadForms = map[string]interface{}{
+	"avatar":         &appmodels.UploadAvatarRequest{},
+	"gpx":            &appmodels.UploadGPXTrackRequest{},
+	"session_cover":  &appmodels.UploadSessionCoverRequest{},
+	"session_media":  &appmodels.UploadSessionMediaRequest{},
+	"photo_waypoint": &appmodels.UploadPhotoWaypointRequest{},
+	"geotag":         &appmodels.GeotagPhotoRequest{},
+}
+
+type DiscoveryHandler struct {
+	config *config.AppConfig
+}
+
+func NewDiscoveryHandler(cfg *config.AppConfig) *DiscoveryHandler {
+	return &DiscoveryHandler{
+		config: cfg,
+	}
+}
+
+// GetClientConfig returns the client auto-configuration document
+//
+//	@Summary		Get client configuration
+//	@Description	Describes the API base, the supported tracking protocols, authentication methods and server version, so mobile clients can set up from the server URL alone. Served outside the API prefix without the response envelope.
+//	@Tags			Public
+//	@Produce		json
+//	@Success		200	{object}	models.ClientConfigDocument	"Client configuration"
+//	@Router			/.well-known/vibe-tracker.json [get]
+func (h *DiscoveryHandler) GetClientConfig(c echo.Context) error {
+	// Clients reach the server through the URL they were given, which may differ from the app URL
+	host := c.Request().Host
+	apiBase := c.Scheme() + "://" + host + constants.APIPrefix
+	socketBase := "ws://" + host + constants.APIPrefix
+	if c.Scheme() == "https" {
+		socketBase = "wss://" + host + constants.APIPrefix
+	}
+	flexibleAuth := []string{constants.AuthMethodPassword, constants.AuthMethodToken}
+
+	protocols := []appmodels.TrackingProtocol{
+		{
+			ID:     constants.ProtocolQuery,
+			Method: http.MethodGet,
+			URL:    apiBase + constants.EndpointTrack,
+			Auth:   flexibleAuth,
+		},
+		{
+			ID:           constants.ProtocolGeoJSON,
+			Method:       http.MethodPost,
+			URL:          apiBase + constants.EndpointTrack,
+			ContentTypes: []string{echo.MIMEApplicationJSON, constants.ContentTypeCBOR, constants.ContentTypeProtobuf},
+			Auth:         flexibleAuth,
+		},
+		{
+			ID:           constants.ProtocolHomeAssistant,
+			Method:       http.MethodPost,
+			URL:          apiBase + constants.EndpointHomeAssistantWebhook,
+			ContentTypes: []string{echo.MIMEApplicationJSON},
+			Auth:         flexibleAuth,
+		},
+		{
+			ID:           constants.ProtocolOwnTracks,
+			Method:       http.MethodPost,
+			URL:          apiBase + constants.EndpointOwnTracks,
+			ContentTypes: []string{echo.MIMEApplicationJSON},
+			Auth:         flexibleAuth,
+		},
+		{
+			ID:     constants.ProtocolOsmAnd,
+			Method: http.MethodGet,
+			URL:    apiBase + constants.EndpointOsmAnd,
+			Auth:   flexibleAuth,
+		},
+		{
+			ID:           constants.ProtocolWebSocket,
+			URL:          socketBase + constants.EndpointTrackWebSocket,
+			ContentTypes: []string{echo.MIMEApplicationJSON},
+			Auth:         flexibleAuth,
+		},
+	}
+
+	if address := h.config.Tracking.GRPCAddress; address != "" {
+		protocols = append(protocols, appmodels.TrackingProtocol{
+			ID:      constants.ProtocolGRPC,
+			Address: grpcClientAddress(address, host),
+			Service: constants.GRPCTrackingService,
+			TLS:     h.config.Tracking.GRPCTLSCert != "",
+			Auth:    flexibleAuth,
+		})
+	}
+
+	document := appmodels.ClientConfigDocument{
+		ConfigVersion: constants.ClientConfigVersion,
+		Name:          h.config.Branding.InstanceName,
+		Version:       constants.AppVersion,
+		APIBase:       apiBase,
+		Branding:      apiBase + constants.EndpointBranding,
+		Capabilities:  apiBase + constants.EndpointCapabilities,
+		ReadOnly:      h.config.ReadOnly,
+		Protocols:     protocols,
+		Auth: []appmodels.AuthMethod{
+			{
+				ID:         constants.AuthMethodPassword,
+				LoginURL:   apiBase + constants.EndpointLogin,
+				RefreshURL: apiBase + "/auth/refresh",
+				Header:     echo.HeaderAuthorization,
+			},
+			{
+				ID:         constants.AuthMethodToken,
+				QueryParam: "token",
+				Header:     echo.HeaderAuthorization,
+			},
+		},
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", int(constants.ClientConfigMaxAge.Seconds())))
+	return c.JSON(http.StatusOK, document)
+}
+
+// GetCapabilities returns the server version, enabled modules and limits
+//
+//	@Summary		Get server capabilities
+//	@Description	Returns the server and API version, the newest schema migration, the optional modules enabled by the configuration and the limits enforced on requests: request and upload sizes, page size, the oldest accepted timestamp and the rate limits per endpoint group. Tools such as uploaders can adapt their behavior instead of hard-coding assumptions.
+//	@Tags			Public
+//	@Produce		json
+//	@Success		200	{object}	models.SuccessResponse{data=models.CapabilitiesResponse}	"Server capabilities"
+//	@Router			/capabilities [get]
+func (h *DiscoveryHandler) GetCapabilities(c echo.Context) error {
+	limits := appmodels.CapabilityLimits{
+		MaxRequestSize:  h.config.Security.MaxRequestSize,
+		MaxPageSize:     h.config.MaxPerPage,
+		MaxTimestampAge: int64(h.config.Tracking.MaxTimestampAge.Seconds()),
+		Uploads:         map[string]appmodels.UploadLimit{},
+	}
+
+	for name, form := range uploadForms {
+		rules, err := utils.FileRules(form)
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Invalid upload form", err)
+		}
+		for _, rule := range rules {
+			limits.Uploads[name] = appmodels.UploadLimit{MaxSize: rule.MaxSize, Types: rule.Types}
+		}
+	}
+
+	if h.config.Security.EnableRateLimiting {
+		limits.RateLimits = map[string]appmodels.RateLimit{
+			"auth":     {RequestsPerMinute: constants.AuthRateLimit, Burst: constants.AuthBurstSize},
+			"tracking": {RequestsPerMinute: constants.TrackingRateLimit, Burst: constants.TrackingBurstSize},
+			"sessions": {RequestsPerMinute: constants.SessionRateLimit, Burst: constants.SessionBurstSize},
+			"public":   {RequestsPerMinute: constants.PublicRateLimit, Burst: constants.PublicBurstSize},
+			"docs":     {RequestsPerMinute: constants.DocsRateLimit, Burst: constants.DocsBurstSize},
+			"guest":    {RequestsPerMinute: constants.GuestRateLimit, Burst: constants.GuestBurstSize},
+		}
+	}
+
+	response := appmodels.CapabilitiesResponse{
+		Version:       constants.AppVersion,
+		APIVersion:    constants.APIVersion,
+		SchemaVersion: latestMigration(),
+		Modules:       instanceFeatures(h.config),
+		Limits:        limits,
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, response, "")
+}
+
+// latestMigration returns the newest registered migration, which is applied on startup
+func latestMigration() string {
+	migrations := pbmigrations.AppMigrations.Items()
+	if len(migrations) == 0 {
+		return ""
+	}
+	return migrations[len(migrations)-1].File
+}
+
+// grpcClientAddress completes a listen address such as ":9090" with the host clients reached the
+// server at
+func grpcClientAddress(listenAddress, requestHost string) string {
+	host, port, err := net.SplitHostPort(listenAddress)
+	if err != nil || (host != "" && host != "0.0.0.0" && host != "::") {
+		return listenAddress
+	}
+
+	if requestHostname, _, err := net.SplitHostPort(requestHost); err == nil {
+		requestHost = requestHostname
+	}
+	return net.JoinHostPort(requestHost, port)
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/services"
+	"vibe-tracker/utils"
+)
+
+type EmergencyContactHandler struct {
+	app      *pocketbase.PocketBase
+	contacts *services.EmergencyContactService
+}
+
+func NewEmergencyContactHandler(app *pocketbase.PocketBase, contacts *services.EmergencyContactService) *EmergencyContactHandler {
+	return &EmergencyContactHandler{
+		app:      app,
+		contacts: contacts,
+	}
+}
+
+// ListEmergencyContacts lists the emergency contacts of the current user
+//
+//	@Summary		List emergency contacts
+//	@Description	Returns the authenticated user's emergency contacts and whether they confirmed their email address
+//	@Tags			Emergency Contacts
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.SuccessResponse{data=[]models.EmergencyContact}	"Emergency contacts retrieved successfully"
+//	@Failure		401	{object}	models.ErrorResponse									"Authentication required"
+//	@Router			/profile/emergency-contacts [get]
+func (h *EmergencyContactHandler) ListEmergencyContacts(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	contacts, err := h.contacts.List(record.Id)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, contacts, "")
+}
+
+// CreateEmergencyContact adds an emergency contact for the current user
+//
+//	@Summary		Add emergency contact
+//	@Description	Adds an emergency contact alerted by missed check-ins. The contact is emailed a verification link and is only alerted after confirming it.
+//	@Tags			Emergency Contacts
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.EmergencyContactRequest							true	"Contact data"
+//	@Success		201		{object}	models.SuccessResponse{data=models.EmergencyContact}	"Emergency contact added successfully"
+//	@Failure		400		{object}	models.ErrorResponse									"Invalid request or too many contacts"
+//	@Failure		401		{object}	models.ErrorResponse									"Authentication required"
+//	@Failure		409		{object}	models.ErrorResponse									"Contact already exists"
+//	@Router			/profile/emergency-contacts [post]
+func (h *EmergencyContactHandler) CreateEmergencyContact(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	req, ok := middleware.GetValidatedData(c).(*appmodels.EmergencyContactRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	contact, err := h.contacts.Create(record, *req)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusCreated, contact, "Emergency contact added successfully, a verification email was sent")
+}
+
+// UpdateEmergencyContact changes an emergency contact of the current user
+//
+//	@Summary		Update emergency contact
+//	@Description	Updates an emergency contact; changing the email address requires the contact to verify it again
+//	@Tags			Emergency Contacts
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string													true	"Emergency contact ID"
+//	@Param			request	body		models.EmergencyContactRequest							true	"Contact data"
+//	@Success		200		{object}	models.SuccessResponse{data=models.EmergencyContact}	"Emergency contact updated successfully"
+//	@Failure		400		{object}	models.ErrorResponse									"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse									"Authentication required"
+//	@Failure		404		{object}	models.ErrorResponse									"Emergency contact not found"
+//	@Router			/profile/emergency-contacts/{id} [put]
+func (h *EmergencyContactHandler) UpdateEmergencyContact(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	req, ok := middleware.GetValidatedData(c).(*appmodels.EmergencyContactRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	contact, err := h.contacts.Update(record, c.PathParam("id"), *req)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, contact, "Emergency contact updated successfully")
+}
+
+// DeleteEmergencyContact removes an emergency contact of the current user
+//
+//	@Summary		Delete emergency contact
+//	@Description	Removes an emergency contact; it is no longer alerted
+//	@Tags			Emergency Contacts
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Emergency contact ID"
+//	@Success		200	{object}	models.SuccessResponse	"Emergency contact deleted successfully"
+//	@Failure		401	{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		404	{object}	models.ErrorResponse	"Emergency contact not found"
+//	@Router			/profile/emergency-contacts/{id} [delete]
+func (h *EmergencyContactHandler) DeleteEmergencyContact(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	if err := h.contacts.Delete(record.Id, c.PathParam("id")); err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Emergency contact deleted successfully")
+}
+
+// ResendEmergencyContactVerification emails a new verification link to an emergency contact
+//
+//	@Summary		Resend emergency contact verification
+//	@Description	Sends a new verification email to an emergency contact that has not confirmed its email address yet
+//	@Tags			Emergency Contacts
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Emergency contact ID"
+//	@Success		200	{object}	models.SuccessResponse	"Verification email sent"
+//	@Failure		401	{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		404	{object}	models.ErrorResponse	"Emergency contact not found"
+//	@Failure		409	{object}	models.ErrorResponse	"Emergency contact already verified"
+//	@Failure		429	{object}	models.ErrorResponse	"Verification email sent recently"
+//	@Router			/profile/emergency-contacts/{id}/verify [post]
+func (h *EmergencyContactHandler) ResendEmergencyContactVerification(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	if err := h.contacts.ResendVerification(record, c.PathParam("id")); err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Verification email sent")
+}
+
+// VerifyEmergencyContact confirms an emergency contact's email address
+//
+//	@Summary		Verify emergency contact
+//	@Description	Confirms the email address of an emergency contact with the token from its verification email, after which it is alerted
+//	@Tags			Emergency Contacts
+//	@Produce		json
+//	@Param			token	path		string	true	"Verification token"
+//	@Success		200		{object}	models.SuccessResponse	"Emergency contact verified"
+//	@Failure		400		{object}	models.ErrorResponse	"Verification link expired"
+//	@Failure		404		{object}	models.ErrorResponse	"Verification link not found"
+//	@Router			/emergency-contacts/verify/{token} [get]
+func (h *EmergencyContactHandler) VerifyEmergencyContact(c echo.Context) error {
+	if _, err := h.contacts.Verify(c.PathParam("token")); err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Thank you, your email address is confirmed as an emergency contact")
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/services"
+	"vibe-tracker/utils"
+)
+
+type ETAShareHandler struct {
+	app       *pocketbase.PocketBase
+	etaShares *services.ETAShareService
+}
+
+func NewETAShareHandler(app *pocketbase.PocketBase, etaShares *services.ETAShareService) *ETAShareHandler {
+	return &ETAShareHandler{
+		app:       app,
+		etaShares: etaShares,
+	}
+}
+
+// ListETAShares lists the ETA shares of the current user
+//
+//	@Summary		List ETA shares
+//	@Description	Returns the authenticated user's ETA share links with their state: active, arrived or expired
+//	@Tags			ETA Shares
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.SuccessResponse{data=[]models.ETAShare}	"ETA shares retrieved successfully"
+//	@Failure		401	{object}	models.ErrorResponse							"Authentication required"
+//	@Router			/profile/eta-shares [get]
+func (h *ETAShareHandler) ListETAShares(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	shares, err := h.etaShares.List(record.Id)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, shares, "")
+}
+
+// CreateETAShare creates a link sharing the current user's live position and ETA to a destination
+//
+//	@Summary		Create ETA share
+//	@Description	Creates a temporary link anyone can open to follow the user's live position and estimated arrival at the destination. The link stops sharing the position once the user comes within the arrival radius or when it expires.
+//	@Tags			ETA Shares
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.CreateETAShareRequest					true	"Destination and duration"
+//	@Success		201		{object}	models.SuccessResponse{data=models.ETAShare}	"ETA share created successfully"
+//	@Failure		400		{object}	models.ErrorResponse							"Invalid request or too many active shares"
+//	@Failure		401		{object}	models.ErrorResponse							"Authentication required"
+//	@Router			/profile/eta-shares [post]
+func (h *ETAShareHandler) CreateETAShare(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	req, ok := middleware.GetValidatedData(c).(*appmodels.CreateETAShareRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	share, err := h.etaShares.Create(record, *req)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusCreated, share, "ETA share created successfully")
+}
+
+// DeleteETAShare ends an ETA share of the current user
+//
+//	@Summary		Delete ETA share
+//	@Description	Ends an ETA share; its link stops working immediately
+//	@Tags			ETA Shares
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"ETA share ID"
+//	@Success		200	{object}	models.SuccessResponse	"ETA share deleted successfully"
+//	@Failure		401	{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		404	{object}	models.ErrorResponse	"ETA share not found"
+//	@Router			/profile/eta-shares/{id} [delete]
+func (h *ETAShareHandler) DeleteETAShare(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	if err := h.etaShares.Delete(record.Id, c.PathParam("id")); err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "ETA share deleted successfully")
+}
+
+// GetETAShare shows a shared live position with the estimated arrival
+//
+//	@Summary		View ETA share
+//	@Description	Returns the latest position of the sharing user, the remaining distance to the destination and the estimated arrival while the share is active. Arrived shares only report the arrival time, expired shares respond with 410.
+//	@Tags			ETA Shares
+//	@Produce		json
+//	@Param			token	path		string											true	"ETA share token"
+//	@Success		200		{object}	models.SuccessResponse{data=models.ETAShareView}	"ETA share retrieved successfully"
+//	@Failure		404		{object}	models.ErrorResponse							"ETA share not found"
+//	@Failure		410		{object}	models.ErrorResponse							"ETA share expired"
+//	@Router			/eta/{token} [get]
+func (h *ETAShareHandler) GetETAShare(c echo.Context) error {
+	view, err := h.etaShares.View(c.PathParam("token"))
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	if view.Status == constants.ETAShareStatusExpired {
+		return apis.NewApiError(http.StatusGone, "ETA share expired", nil)
+	}
+
+	// Positions change with every update, viewers poll this endpoint
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return utils.SendSuccess(c, http.StatusOK, view, "")
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/services"
+	"vibe-tracker/utils"
+)
+
+// signedFileFields lists the file field served with signed URLs per collection
+var signedFileFields = map[string]string{
+	"waypoints":     "photo",
+	"sessions":      "gpx_track",
+	"session_media": "file",
+}
+
+type FileHandler struct {
+	app     *pocketbase.PocketBase
+	cleanup *services.FileCleanupService
+}
+
+func NewFileHandler(app *pocketbase.PocketBase) *FileHandler {
+	return &FileHandler{
+		app: app,
+	}
+}
+
+// WithFileCleanupService enables the orphaned files report
+func (h *FileHandler) WithFileCleanupService(cleanup *services.FileCleanupService) *FileHandler {
+	h.cleanup = cleanup
+	return h
+}
+
+// ServeSignedFile serves a waypoint photo, session GPX file or session media from a signed download URL
+//
+//	@Summary		Download file
+//	@Description	Serves a waypoint photo, the uploaded GPX file of a session or a photo or video attached to a session. Links are signed and expire after a short time; they are included as photo_url, gpx_track_url, cover_url and media url in API responses of users who may view the file.
+//	@Tags			Files
+//	@Produce		octet-stream
+//	@Param			collection	path		string	true	"Collection: waypoints, sessions or session_media"
+//	@Param			record		path		string	true	"Record ID"
+//	@Param			filename	path		string	true	"File name"
+//	@Param			expires		query		int		true	"Link expiry as a Unix timestamp"
+//	@Param			signature	query		string	true	"Link signature"
+//	@Success		200			{file}		file					"File content"
+//	@Failure		403			{object}	models.ErrorResponse	"Invalid or expired link"
+//	@Failure		404			{object}	models.ErrorResponse	"File not found"
+//	@Router			/signed-files/{collection}/{record}/{filename} [get]
+func (h *FileHandler) ServeSignedFile(c echo.Context) error {
+	collection := c.PathParam("collection")
+	recordID := c.PathParam("record")
+	filename := c.PathParam("filename")
+
+	err := utils.VerifyFileSignature(fileSigningSecret(h.app), collection, recordID, filename,
+		c.QueryParam(constants.SignedFileExpiresParam), c.QueryParam(constants.SignedFileSigParam), time.Now())
+	if err != nil {
+		return apis.NewForbiddenError("Invalid or expired download link", nil)
+	}
+
+	field, ok := signedFileFields[collection]
+	if !ok {
+		return apis.NewNotFoundError("File not found", nil)
+	}
+
+	record, err := requestDao(h.app, c).FindRecordById(collection, recordID)
+	if err != nil || record.GetString(field) != filename {
+		return apis.NewNotFoundError("File not found", err)
+	}
+
+	fs, err := h.app.NewFilesystem()
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to initialize filesystem", err)
+	}
+	defer fs.Close()
+
+	// The link expires soon, caches must not keep the file longer
+	c.Response().Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(constants.SignedFileURLTTL.Seconds())))
+	if err := fs.Serve(c.Response(), c.Request(), signedFileKey(fs, record, field), filename); err != nil {
+		return apis.NewNotFoundError("File not found", err)
+	}
+	return nil
+}
+
+// ListOrphanedFiles scans the file storage for files of deleted records
+//
+//	@Summary		List orphaned files
+//	@Description	Scans the file storage (local or S3) for files that no record refers to anymore: files of deleted collections or records, files their record no longer lists and GPX uploads of deleted sessions. Files uploaded within the last hour are skipped. Nothing is deleted; the daily scan deletes orphans when DELETE_ORPHANED_FILES is set. Requires the system:manage permission.
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.SuccessResponse{data=models.OrphanedFilesReport}	"Orphaned files retrieved successfully"
+//	@Failure		401	{object}	models.ErrorResponse									"Authentication required"
+//	@Failure		403	{object}	models.ErrorResponse									"Forbidden"
+//	@Failure		500	{object}	models.ErrorResponse									"Internal server error"
+//	@Router			/admin/files/orphans [get]
+func (h *FileHandler) ListOrphanedFiles(c echo.Context) error {
+	if h.cleanup == nil {
+		return apis.NewNotFoundError("Orphaned files report is not available", nil)
+	}
+
+	report, err := h.cleanup.ScanOrphanedFiles(time.Now(), false)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to scan the file storage", err)
+	}
+	return utils.SendSuccess(c, http.StatusOK, report, "Orphaned files retrieved successfully")
+}
+
+// signedFileKey returns the storage key of a record's file. GPX uploads made before they were
+// stored in the session's files path are found at the root of the storage.
+func signedFileKey(fs *filesystem.System, record *models.Record, field string) string {
+	key := record.BaseFilesPath() + "/" + record.GetString(field)
+	if field != "gpx_track" {
+		return key
+	}
+
+	legacyKey := utils.LegacyGPXTrackKey(record.Id, record.GetString(field))
+	if legacyKey == "" {
+		return key
+	}
+	if exists, err := fs.Exists(key); err == nil && exists {
+		return key
+	}
+	return legacyKey
+}
+
+// fileSigningSecret returns the key used to sign download URLs
+func fileSigningSecret(app *pocketbase.PocketBase) string {
+	return app.Settings().RecordAuthToken.Secret
+}
+
+// signedFileURL returns a signed download URL for a file of a record, or "" when there is no file
+func signedFileURL(app *pocketbase.PocketBase, record *models.Record, field string) string {
+	filename := record.GetString(field)
+	if filename == "" {
+		return ""
+	}
+	return utils.SignedFileURL(fileSigningSecret(app), record.Collection().Name, record.Id, filename,
+		time.Now().Add(constants.SignedFileURLTTL))
+}
@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+type GraphQLHandler struct {
+	app    *pocketbase.PocketBase
+	config *config.TrackingConfig
+	schema graphql.Schema
+}
+
+// graphqlRequestKey is the context key of the echo context resolvers check access with
+type graphqlRequestKey struct{}
+
+// graphqlJSON passes maps and other free-form values through unchanged
+var graphqlJSON = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Free-form JSON value",
+	Serialize:   func(value any) any { return value },
+	ParseValue:  func(value any) any { return value },
+	ParseLiteral: func(valueAST ast.Value) any {
+		return valueAST.GetValue()
+	},
+})
+
+func NewGraphQLHandler(app *pocketbase.PocketBase, trackingConfig *config.TrackingConfig) (*GraphQLHandler, error) {
+	h := &GraphQLHandler{app: app, config: trackingConfig}
+
+	schema, err := h.buildSchema()
+	if err != nil {
+		return nil, err
+	}
+	h.schema = schema
+
+	return h, nil
+}
+
+// Query executes a GraphQL query
+//
+//	@Summary		GraphQL query
+//	@Description	Executes a GraphQL query against sessions with their locations, waypoints and statistics. Access rules are the same as for the REST endpoints; errors are returned in the "errors" member of the GraphQL response.
+//	@Tags			Public
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		models.GraphQLRequest	true	"GraphQL query, variables and operation name"
+//	@Success		200		{object}	models.GraphQLResponse	"Query executed, possibly with errors"
+//	@Failure		400		{object}	models.ErrorResponse	"Missing query"
+//	@Router			/graphql [post]
+func (h *GraphQLHandler) Query(c echo.Context) error {
+	var req appmodels.GraphQLRequest
+	if c.Request().Method == http.MethodGet {
+		req.Query = c.QueryParam("query")
+		req.OperationName = c.QueryParam("operationName")
+	} else if err := c.Bind(&req); err != nil {
+		return apis.NewBadRequestError("Invalid GraphQL request", err)
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		return apis.NewBadRequestError("query is required", nil)
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        context.WithValue(c.Request().Context(), graphqlRequestKey{}, c),
+	})
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// buildSchema generates the GraphQL types from the API models, so fields are named and shaped
+// like the JSON of the REST endpoints, and adds the nested fields resolved from the database
+func (h *GraphQLHandler) buildSchema() (graphql.Schema, error) {
+	generated := newGraphQLTypes()
+
+	locationType := generated.object(reflect.TypeOf(appmodels.Location{}), nil)
+	waypointType := generated.object(reflect.TypeOf(appmodels.Waypoint{}), nil)
+	statsType := generated.object(reflect.TypeOf(appmodels.SessionStatsResponse{}), nil)
+
+	sessionType := generated.object(reflect.TypeOf(appmodels.Session{}), graphql.Fields{
+		"locations": &graphql.Field{
+			Type:        graphql.NewList(locationType),
+			Description: "Tracked locations, oldest first",
+			Args: graphql.FieldConfigArgument{
+				"since": &graphql.ArgumentConfig{Type: graphql.Int, Description: "Only locations after this Unix timestamp"},
+				"limit": &graphql.ArgumentConfig{Type: graphql.Int, Description: "Return at most this many locations"},
+			},
+			Resolve: h.resolveLocations,
+		},
+		"waypoints": &graphql.Field{
+			Type:        graphql.NewList(waypointType),
+			Description: "Waypoints in route order",
+			Resolve:     h.resolveWaypoints,
+		},
+		"stats": &graphql.Field{
+			Type:        statsType,
+			Description: "Track statistics",
+			Resolve:     h.resolveStats,
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"session": &graphql.Field{
+				Type:        sessionType,
+				Description: "A session of a user",
+				Args: graphql.FieldConfigArgument{
+					"username": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"name":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: h.resolveSession,
+			},
+			"sessions": &graphql.Field{
+				Type:        graphql.NewList(sessionType),
+				Description: "Sessions of a user visible to the requester, newest first",
+				Args: graphql.FieldConfigArgument{
+					"username": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"page":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: constants.DefaultPage},
+					"perPage":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: constants.DefaultPerPage},
+				},
+				Resolve: h.resolveSessions,
+			},
+		},
+	})
+
+	if generated.err != nil {
+		return graphql.Schema{}, generated.err
+	}
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+func (h *GraphQLHandler) resolveSession(p graphql.ResolveParams) (any, error) {
+	c, err := graphqlRequest(p)
+	if err != nil {
+		return nil, err
+	}
+
+	dao := requestDao(h.app, c)
+	user, err := dao.FindFirstRecordByFilter(constants.CollectionUsers, "username = {:username}",
+		dbx.Params{"username": p.Args["username"]})
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	session, err := findSessionByNameAndUser(dao, p.Args["name"].(string), user.Id)
+	if err != nil {
+		return nil, errors.New("session not found")
+	}
+	if !hasSessionAccess(c, session) {
+		return nil, errors.New("access denied")
+	}
+
+	return graphqlSession(c, session), nil
+}
+
+func (h *GraphQLHandler) resolveSessions(p graphql.ResolveParams) (any, error) {
+	c, err := graphqlRequest(p)
+	if err != nil {
+		return nil, err
+	}
+
+	dao := requestDao(h.app, c)
+	user, err := dao.FindFirstRecordByFilter(constants.CollectionUsers, "username = {:username}",
+		dbx.Params{"username": p.Args["username"]})
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	page, _ := p.Args["page"].(int)
+	perPage, _ := p.Args["perPage"].(int)
+	if page < 1 {
+		page = constants.DefaultPage
+	}
+	if perPage < constants.MinPerPageLimit || perPage > constants.MaxPerPageLimit {
+		perPage = constants.DefaultPerPage
+	}
+
+	// Other users only get public sessions, so the nested fields of every listed session are readable
+	filter := "user = {:user}"
+	if !canAccess(c, constants.PermSessionsRead, user.Id) {
+		filter += " && public = true && hidden = false && draft = false"
+	}
+
+	sessions, err := dao.FindRecordsByFilter(constants.CollectionSessions, filter, "-created",
+		perPage, (page-1)*perPage, dbx.Params{"user": user.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*appmodels.Session, len(sessions))
+	for i, session := range sessions {
+		result[i] = graphqlSession(c, session)
+	}
+	return result, nil
+}
+
+func (h *GraphQLHandler) resolveLocations(p graphql.ResolveParams) (any, error) {
+	c, err := graphqlRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	session := p.Source.(*appmodels.Session)
+
+	filter := "session = {:session}"
+	params := dbx.Params{"session": session.ID}
+	if since, ok := p.Args["since"].(int); ok {
+		sinceDateTime, _ := types.ParseDateTime(time.Unix(int64(since), 0))
+		filter += " && timestamp > {:since}"
+		params["since"] = sinceDateTime
+	}
+	limit, _ := p.Args["limit"].(int)
+	if limit < 0 {
+		limit = 0
+	}
+
+	records, err := requestDao(h.app, c).FindRecordsByFilter(constants.CollectionLocations, filter, "timestamp", limit, 0, params)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]appmodels.Location, len(records))
+	for i, record := range records {
+		locations[i] = appmodels.Location{
+			ID:        record.Id,
+			User:      record.GetString("user"),
+			Latitude:  record.GetFloat("latitude"),
+			Longitude: record.GetFloat("longitude"),
+			Altitude:  utils.RecordAltitude(record),
+			Speed:     record.GetFloat("speed"),
+			HeartRate: record.GetFloat("heart_rate"),
+			Accuracy:  record.GetFloat("accuracy"),
+			Device:    record.GetString("device"),
+			Battery:   record.GetFloat("battery"),
+
+			VerticalAccuracy: record.GetFloat("vertical_accuracy"),
+			Bearing:          record.GetFloat("bearing"),
+
+			Cadence:     record.GetFloat("cadence"),
+			Power:       record.GetFloat("power"),
+			Temperature: utils.RecordTemperature(record),
+
+			Session:   session.Name, // All locations belong to the resolved session
+			Status:    record.GetString("status"),
+			Event:     record.GetString("event"),
+			Timestamp: record.GetDateTime("timestamp").Time().Unix(),
+			Created:   record.GetDateTime("created").Time(),
+			Updated:   record.GetDateTime("updated").Time(),
+		}
+	}
+	return locations, nil
+}
+
+func (h *GraphQLHandler) resolveWaypoints(p graphql.ResolveParams) (any, error) {
+	c, err := graphqlRequest(p)
+	if err != nil {
+		return nil, err
+	}
+	session := p.Source.(*appmodels.Session)
+
+	// Waypoints hidden by moderation are only listed to users who may read them
+	filter := "session_id = {:session_id}"
+	if !canAccess(c, constants.PermWaypointsRead, session.User) {
+		filter += " && hidden = false"
+	}
+
+	records, err := requestDao(h.app, c).FindRecordsByFilter(constants.CollectionWaypoints, filter,
+		constants.WaypointOrderSort, 0, 0, dbx.Params{"session_id": session.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	waypoints := make([]*appmodels.Waypoint, len(records))
+	for i, record := range records {
+		waypoints[i] = recordToWaypoint(h.app, record)
+	}
+	return waypoints, nil
+}
+
+func (h *GraphQLHandler) resolveStats(p graphql.ResolveParams) (any, error) {
+	c, err := graphqlRequest(p)
+	if err != nil {
+		return nil, err
+	}
+
+	dao := requestDao(h.app, c)
+	session, err := dao.FindRecordById(constants.CollectionSessions, p.Source.(*appmodels.Session).ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionTrackStats(dao, session, utils.GapThresholds{
+		MaxInterval: h.config.GapMaxInterval,
+		MaxDistance: h.config.GapMaxDistance,
+	})
+}
+
+// graphqlRequest returns the echo context the query was sent with
+func graphqlRequest(p graphql.ResolveParams) (echo.Context, error) {
+	c, ok := p.Context.Value(graphqlRequestKey{}).(echo.Context)
+	if !ok {
+		return nil, errors.New("missing request context")
+	}
+	return c, nil
+}
+
+// graphqlSession converts a session record the requester may read to the API model; the share
+// token is only included for users managing the session
+func graphqlSession(c echo.Context, record *models.Record) *appmodels.Session {
+	session := &appmodels.Session{
+		ID:               record.Id,
+		Name:             record.GetString("name"),
+		Title:            record.GetString("title"),
+		Description:      record.GetString("description"),
+		DescriptionHTML:  utils.RenderMarkdown(record.GetString("description")),
+		Public:           record.GetBool("public"),
+		User:             record.GetString("user"),
+		Organization:     record.GetString("organization"),
+		GpxTrack:         record.GetString("gpx_track"),
+		TrackName:        record.GetString("track_name"),
+		TrackDescription: record.GetString("track_description"),
+		ShowViewerCount:  record.GetBool("show_viewer_count"),
+		Draft:            record.GetBool("draft"),
+		Activity:         record.GetString("activity"),
+		Created:          record.GetDateTime("created").Time(),
+		Updated:          record.GetDateTime("updated").Time(),
+	}
+	if canAccess(c, constants.PermSessionsWrite, session.User) {
+		session.ShareToken = record.GetString("share_token")
+	}
+	return session
+}
+
+// graphqlTypes generates GraphQL object types from Go structs, named after the struct and with
+// one field per JSON property. Embedded structs and fields without a JSON name are skipped.
+type graphqlTypes struct {
+	objects map[reflect.Type]*graphql.Object
+	err     error
+}
+
+func newGraphQLTypes() *graphqlTypes {
+	return &graphqlTypes{objects: make(map[reflect.Type]*graphql.Object)}
+}
+
+// object returns the object type of a struct, with extra fields resolved separately
+func (g *graphqlTypes) object(t reflect.Type, extra graphql.Fields) *graphql.Object {
+	if object, ok := g.objects[t]; ok {
+		return object
+	}
+
+	fields := graphql.Fields{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = &graphql.Field{Type: g.output(field.Type)}
+	}
+	for name, field := range extra {
+		fields[name] = field
+	}
+
+	object := graphql.NewObject(graphql.ObjectConfig{Name: t.Name(), Fields: fields})
+	g.objects[t] = object
+	return object
+}
+
+// output maps a Go type to a GraphQL output type; everything is nullable like the JSON fields
+func (g *graphqlTypes) output(t reflect.Type) graphql.Output {
+	if t == reflect.TypeOf(time.Time{}) {
+		return graphql.DateTime
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.output(t.Elem())
+	case reflect.String:
+		return graphql.String
+	case reflect.Bool:
+		return graphql.Boolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return graphql.Int
+	case reflect.Float32, reflect.Float64:
+		return graphql.Float
+	case reflect.Slice, reflect.Array:
+		return graphql.NewList(g.output(t.Elem()))
+	case reflect.Struct:
+		return g.object(t, nil)
+	case reflect.Map, reflect.Interface:
+		return graphqlJSON
+	default:
+		g.err = fmt.Errorf("unsupported GraphQL field type %s", t)
+		return graphqlJSON
+	}
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	trackingv1 "vibe-tracker/proto/vibetracker/v1"
+	"vibe-tracker/utils"
+)
+
+// TrackingGRPCServer serves the gRPC ingestion API for embedded trackers on its own listener.
+// Points are validated and stored like the ones sent to GET /api/track.
+type TrackingGRPCServer struct {
+	trackingv1.UnimplementedTrackingServiceServer
+
+	tracking        *TrackingHandler
+	auth            *middleware.AuthMiddleware
+	config          *config.TrackingConfig
+	readOnlyMessage string
+	server          *grpc.Server
+}
+
+// NewTrackingGRPCServer creates the gRPC ingestion server, with TLS when a certificate and key are
+// configured
+func NewTrackingGRPCServer(tracking *TrackingHandler, auth *middleware.AuthMiddleware, trackingConfig *config.TrackingConfig) (*TrackingGRPCServer, error) {
+	var options []grpc.ServerOption
+	if trackingConfig.GRPCTLSCert != "" || trackingConfig.GRPCTLSKey != "" {
+		creds, err := credentials.NewServerTLSFromFile(trackingConfig.GRPCTLSCert, trackingConfig.GRPCTLSKey)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+
+	s := &TrackingGRPCServer{
+		tracking: tracking,
+		auth:     auth,
+		config:   trackingConfig,
+		server:   grpc.NewServer(options...),
+	}
+	trackingv1.RegisterTrackingServiceServer(s.server, s)
+	return s, nil
+}
+
+// WithReadOnly rejects all streams with the maintenance message, see READ_ONLY
+func (s *TrackingGRPCServer) WithReadOnly(message string) *TrackingGRPCServer {
+	s.readOnlyMessage = message
+	return s
+}
+
+// Start listens on the configured address and serves gRPC requests in the background
+func (s *TrackingGRPCServer) Start(e *core.ServeEvent) error {
+	listener, err := net.Listen("tcp", s.config.GRPCAddress)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil {
+			utils.LogError(err, "grpc ingestion").Msg("gRPC ingestion service stopped")
+		}
+	}()
+
+	utils.LogInfo().Str("address", listener.Addr().String()).Msg("gRPC ingestion service started")
+	return nil
+}
+
+// Stop lets open streams finish for a short while before closing them
+func (s *TrackingGRPCServer) Stop(e *core.TerminateEvent) error {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(constants.GRPCShutdownTimeout):
+		s.server.Stop()
+	}
+	return nil
+}
+
+// Track stores the streamed points of the authenticated user. Rejected points are reported in the
+// summary instead of ending the stream, so one bad fix does not cost the rest of an upload.
+func (s *TrackingGRPCServer) Track(stream trackingv1.TrackingService_TrackServer) error {
+	if s.readOnlyMessage != "" {
+		return status.Error(codes.Unavailable, s.readOnlyMessage)
+	}
+
+	ctx := stream.Context()
+	user, token, err := s.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+
+	summary := &trackingv1.TrackSummary{}
+	var sessionIDs []string
+	seen := map[string]bool{}
+
+	for index := uint32(0); ; index++ {
+		point, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		record, err := s.trackPoint(ctx, user, token, point)
+		if err != nil {
+			summary.Rejected++
+			summary.Errors = append(summary.Errors, &trackingv1.PointError{Index: index, Message: err.Error()})
+			continue
+		}
+
+		summary.Accepted++
+		if sessionID := record.GetString("session"); sessionID != "" && !seen[sessionID] {
+			seen[sessionID] = true
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+	}
+
+	dao := utils.ContextDao(s.tracking.app.Dao(), ctx)
+	for _, sessionID := range sessionIDs {
+		session, err := dao.FindRecordById(constants.CollectionSessions, sessionID)
+		if err != nil {
+			continue
+		}
+		summary.Sessions = append(summary.Sessions, &trackingv1.Session{
+			Id:    session.Id,
+			Name:  session.GetString("name"),
+			Title: session.GetString("title"),
+		})
+	}
+
+	return stream.SendAndClose(summary)
+}
+
+// authenticate resolves the user from the authorization metadata and checks that its role may
+// track, returning the token for the validated parameters
+func (s *TrackingGRPCServer) authenticate(ctx context.Context) (*models.Record, string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(constants.GRPCAuthMetadata)
+	if len(values) == 0 || values[0] == "" {
+		return nil, "", status.Error(codes.Unauthenticated, "Authentication token required")
+	}
+
+	user, err := s.auth.AuthenticateToken(values[0])
+	if err != nil {
+		return nil, "", status.Error(codes.Unauthenticated, "Valid authentication required")
+	}
+	if !utils.HasPermission(middleware.GetUserRole(user), constants.PermTrackingWrite) {
+		return nil, "", status.Error(codes.PermissionDenied, "Your role does not allow this action")
+	}
+
+	return user, values[0], nil
+}
+
+// trackPoint validates a streamed point with the GET /api/track rules and stores it
+func (s *TrackingGRPCServer) trackPoint(ctx context.Context, user *models.Record, token string, point *trackingv1.LocationPoint) (*models.Record, error) {
+	params := &appmodels.TrackingQueryParams{
+		Token:     token,
+		Latitude:  point.GetLatitude(),
+		Longitude: point.GetLongitude(),
+		Timestamp: point.GetTimestamp(),
+		Altitude:  point.Altitude,
+		Speed:     point.Speed,
+		SpeedUnit: point.GetSpeedUnit(),
+		Accuracy:  point.Accuracy,
+		Device:    point.GetDevice(),
+		Battery:   point.Battery,
+		HeartRate: point.HeartRate,
+		Session:   point.GetSession(),
+		Status:    point.GetStatus(),
+		Event:     point.GetEvent(),
+		ClientID:  point.GetClientId(),
+
+		VerticalAccuracy: point.VerticalAccuracy,
+		Bearing:          point.Bearing,
+
+		Cadence:     point.Cadence,
+		Power:       point.Power,
+		Temperature: point.Temperature,
+	}
+	if err := utils.ValidateStruct(params); err != nil {
+		return nil, err
+	}
+
+	if params.Timestamp != constants.DefaultTimestamp {
+		if err := s.tracking.validateTimestamp(params.Timestamp, point.GetAllowHistorical()); err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := s.tracking.trackPoint(ctx, user, params)
+	if err != nil {
+		var apiErr *apis.ApiError
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusInternalServerError {
+			utils.LogError(err, "grpc ingestion").Str("user_id", user.Id).Msg("Failed to store streamed point")
+		}
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/protocols"
+	"vibe-tracker/utils"
+)
+
+// HardwareTrackerListener accepts dedicated GPS trackers speaking one of the protocols of package
+// protocols on raw TCP and UDP sockets. Devices are mapped to users by their IMEI through the
+// hardware_trackers collection and their positions are stored like GET /api/track points.
+type HardwareTrackerListener struct {
+	tracking  *TrackingHandler
+	addresses map[string]string
+
+	mu      sync.Mutex
+	closers []io.Closer
+	conns   map[net.Conn]struct{}
+	wg      sync.WaitGroup
+}
+
+// NewHardwareTrackerListener creates a listener for the given listen addresses by protocol name
+func NewHardwareTrackerListener(tracking *TrackingHandler, addresses map[string]string) *HardwareTrackerListener {
+	return &HardwareTrackerListener{
+		tracking:  tracking,
+		addresses: addresses,
+		conns:     map[net.Conn]struct{}{},
+	}
+}
+
+// Start opens the sockets of the configured protocols and serves them in the background
+func (l *HardwareTrackerListener) Start(e *core.ServeEvent) error {
+	for name, address := range l.addresses {
+		protocol, err := protocols.ByName(name)
+		if err != nil {
+			return err
+		}
+
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return err
+		}
+		l.closers = append(l.closers, listener)
+		l.wg.Add(1)
+		go l.accept(protocol, listener)
+
+		if protocol.Datagrams() {
+			packetConn, err := net.ListenPacket("udp", address)
+			if err != nil {
+				return err
+			}
+			l.closers = append(l.closers, packetConn)
+			l.wg.Add(1)
+			go l.servePackets(protocol, packetConn)
+		}
+
+		utils.LogInfo().Str("protocol", name).Str("address", listener.Addr().String()).Msg("Hardware tracker listener started")
+	}
+	return nil
+}
+
+// Stop closes the sockets and the open device connections
+func (l *HardwareTrackerListener) Stop(e *core.TerminateEvent) error {
+	l.mu.Lock()
+	for _, closer := range l.closers {
+		closer.Close()
+	}
+	for conn := range l.conns {
+		conn.Close()
+	}
+	l.mu.Unlock()
+
+	l.wg.Wait()
+	return nil
+}
+
+func (l *HardwareTrackerListener) accept(protocol protocols.Protocol, listener net.Listener) {
+	defer l.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				utils.LogError(err, "hardware tracker listener").Str("protocol", protocol.Name()).Msg("Failed to accept connection")
+			}
+			return
+		}
+
+		l.mu.Lock()
+		l.conns[conn] = struct{}{}
+		l.mu.Unlock()
+
+		l.wg.Add(1)
+		go l.serveConn(protocol, conn)
+	}
+}
+
+// serveConn reads the frames of a device connection. Protocols that only send the IMEI when
+// logging in rely on the connection to identify the device of later frames.
+func (l *HardwareTrackerListener) serveConn(protocol protocols.Protocol, conn net.Conn) {
+	defer l.wg.Done()
+	defer func() {
+		l.mu.Lock()
+		delete(l.conns, conn)
+		l.mu.Unlock()
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 256), constants.TrackerMaxFrameSize)
+	scanner.Split(protocol.Split)
+
+	imei := ""
+	for {
+		conn.SetReadDeadline(time.Now().Add(constants.TrackerIdleTimeout))
+		if !scanner.Scan() {
+			break
+		}
+
+		message, err := protocol.Decode(scanner.Bytes())
+		if err != nil {
+			utils.LogWarn().Err(err).Str("protocol", protocol.Name()).Str("client_ip", conn.RemoteAddr().String()).Msg("Dropped invalid tracker frame")
+			continue
+		}
+		if message.IMEI != "" {
+			imei = message.IMEI
+		}
+		if message.Reply != nil {
+			if _, err := conn.Write(message.Reply); err != nil {
+				return
+			}
+		}
+		if message.Position != nil {
+			l.store(protocol, imei, message.Position)
+		}
+	}
+}
+
+// servePackets reads UDP datagrams, each holding a single frame with the IMEI
+func (l *HardwareTrackerListener) servePackets(protocol protocols.Protocol, conn net.PacketConn) {
+	defer l.wg.Done()
+
+	buffer := make([]byte, constants.TrackerMaxFrameSize)
+	for {
+		n, addr, err := conn.ReadFrom(buffer)
+		if err != nil {
+			return // Closed by Stop
+		}
+
+		message, err := protocol.Decode(buffer[:n])
+		if err != nil {
+			utils.LogWarn().Err(err).Str("protocol", protocol.Name()).Str("client_ip", addr.String()).Msg("Dropped invalid tracker frame")
+			continue
+		}
+		if message.Reply != nil {
+			conn.WriteTo(message.Reply, addr)
+		}
+		if message.Position != nil {
+			l.store(protocol, message.IMEI, message.Position)
+		}
+	}
+}
+
+// store tracks a decoded position for the user the device is registered to. Positions of unknown
+// devices and positions without a GPS fix are dropped.
+func (l *HardwareTrackerListener) store(protocol protocols.Protocol, imei string, position *protocols.Position) {
+	logger := utils.LogWarn().Str("protocol", protocol.Name()).Str("imei", imei)
+	if imei == "" {
+		logger.Msg("Dropped position of a device that did not log in")
+		return
+	}
+	if !position.Valid {
+		return
+	}
+
+	dao := l.tracking.app.Dao()
+	tracker, err := dao.FindFirstRecordByFilter(constants.CollectionHardwareTrackers, "imei = {:imei}", dbx.Params{"imei": imei})
+	if err != nil {
+		logger.Msg("Dropped position of an unregistered hardware tracker")
+		return
+	}
+	user, err := dao.FindRecordById(constants.CollectionUsers, tracker.GetString("user"))
+	if err != nil {
+		logger.Err(err).Msg("Failed to find the owner of a hardware tracker")
+		return
+	}
+	if !utils.HasPermission(middleware.GetUserRole(user), constants.PermTrackingWrite) {
+		logger.Str("user_id", user.Id).Msg("Dropped position of a user whose role may not track")
+		return
+	}
+
+	device := tracker.GetString("name")
+	if device == "" {
+		device = imei
+	}
+	params := &appmodels.TrackingQueryParams{
+		Token:     imei, // Hardware trackers are identified by their IMEI, they have no token
+		Latitude:  position.Latitude,
+		Longitude: position.Longitude,
+		Timestamp: position.Time.Unix(),
+		Speed:     &position.Speed,
+		SpeedUnit: position.SpeedUnit,
+		Device:    device,
+		Session:   tracker.GetString("session"),
+		Event:     position.Event,
+	}
+	if position.Course >= 0 && position.Course <= 360 {
+		params.Bearing = &position.Course // Course fields are wider than the range of valid degrees
+	}
+	if err := utils.ValidateStruct(params); err != nil {
+		logger.Err(err).Msg("Dropped invalid hardware tracker position")
+		return
+	}
+	if err := l.tracking.validateTimestamp(params.Timestamp, false); err != nil {
+		logger.Err(err).Msg("Dropped hardware tracker position with an invalid timestamp")
+		return
+	}
+
+	if _, err := l.tracking.trackPoint(context.Background(), user, params); err != nil {
+		logger.Err(err).Msg("Failed to store hardware tracker position")
+		return
+	}
+
+	l.touch(tracker)
+}
+
+// touch records when the device last reported a position
+func (l *HardwareTrackerListener) touch(tracker *models.Record) {
+	now := time.Now()
+	if now.Sub(tracker.GetDateTime("last_seen_at").Time()) < constants.TrackerSeenInterval {
+		return
+	}
+
+	lastSeenAt, _ := types.ParseDateTime(now)
+	tracker.Set("last_seen_at", lastSeenAt)
+	if err := l.tracking.app.Dao().SaveRecord(tracker); err != nil {
+		utils.LogWarn().Err(err).Str("imei", tracker.GetString("imei")).Msg("Failed to update hardware tracker activity")
+	}
+}
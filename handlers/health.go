@@ -36,7 +36,7 @@ func NewHealthHandler(app *pocketbase.PocketBase, healthService *services.Health
 // @Accept json
 // @Produce json
 // @Success 200 {object} models.LivenessResponse "Application is alive"
-// @Failure 503 {object} utils.ErrorResponse "Service unavailable"
+// @Failure 503 {object} models.ErrorResponse "Service unavailable"
 // @Router /health/live [get]
 func (h *HealthHandler) GetLiveness(c echo.Context) error {
 	if !h.config.Enabled {
@@ -79,7 +79,7 @@ func (h *HealthHandler) GetReadiness(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Success 200 {object} models.DetailedHealthResponse "Detailed health information"
-// @Failure 403 {object} utils.ErrorResponse "Access denied"
+// @Failure 403 {object} models.ErrorResponse "Access denied"
 // @Failure 503 {object} models.DetailedHealthResponse "Application has health issues"
 // @Router /health [get]
 func (h *HealthHandler) GetDetailedHealth(c echo.Context) error {
@@ -3,13 +3,21 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
 	"vibe-tracker/utils"
 )
 
@@ -24,6 +32,12 @@ func getAuthRecordFromToken(app *pocketbase.PocketBase, token string) (*models.R
 	return record, nil
 }
 
+// requestDao returns the app's Dao bound to the request context, so queries of a request that was
+// cancelled by the client or the timeout middleware are aborted instead of running to completion
+func requestDao(app *pocketbase.PocketBase, c echo.Context) *daos.Dao {
+	return utils.ContextDao(app.Dao(), c.Request().Context())
+}
+
 func findUserByUsername(dao *daos.Dao, username string) (*models.Record, error) {
 	if username == "" {
 		return nil, errors.New("username is missing")
@@ -76,6 +90,445 @@ func findOrCreateSession(dao *daos.Dao, sessionName string, user *models.Record)
 	return session, nil
 }
 
+// reopenSession clears the end of a session that was ended after inactivity but receives new
+// locations again, e.g. on a multi-day trip
+func reopenSession(dao *daos.Dao, session *models.Record) {
+	if session.GetString("ended_at") == "" {
+		return
+	}
+
+	session.Set("ended_at", "")
+	session.Set("stats", nil)
+	session.Set("surface", nil)
+	session.Set("surface_matched_at", "")
+	if err := dao.SaveRecord(session); err != nil {
+		utils.LogWarn().Err(err).Str("session_id", session.Id).Msg("Failed to reopen ended session")
+	}
+}
+
+// recomputeSessionStats ends an ended session again at its last location, with statistics that
+// include locations imported after it ended. Its surface is matched again along the new track.
+func recomputeSessionStats(dao *daos.Dao, session *models.Record, thresholds utils.GapThresholds) error {
+	if session.GetString("ended_at") == "" {
+		return nil
+	}
+
+	locations, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+		"session = {:session}", "timestamp", 0, 0, dbx.Params{"session": session.Id})
+	if err != nil {
+		return err
+	}
+	if len(locations) == 0 {
+		return nil
+	}
+
+	session.Set("ended_at", locations[len(locations)-1].GetDateTime("timestamp"))
+	session.Set("stats", utils.ComputeTrackStats(locationsToTimedPoints(locations), thresholds))
+	session.Set("surface", nil)
+	session.Set("surface_matched_at", "")
+	return dao.SaveRecord(session)
+}
+
+// setLocationSession links a location to its session and expands it, so the session name is in
+// the response and available to location hooks without another lookup
+func setLocationSession(location, session *models.Record) {
+	location.Set("session", session.Id)
+	location.SetExpand(map[string]any{"session": session})
+}
+
+// expandLocationSessions loads the sessions of location records, whose names are then read with
+// utils.LocationSessionName
+func expandLocationSessions(dao *daos.Dao, locations ...*models.Record) {
+	for path, err := range dao.ExpandRecords(locations, []string{"session"}, nil) {
+		utils.LogWarn().Err(err).Str("expand", path).Msg("Failed to expand location sessions")
+	}
+}
+
+// formatOptionalDate formats a date field as RFC 3339, or returns an empty string when it is unset
+func formatOptionalDate(record *models.Record, field string) string {
+	value := record.GetDateTime(field)
+	if value.IsZero() {
+		return ""
+	}
+	return value.Time().Format(time.RFC3339)
+}
+
+// clientInfo describes the client of the current request for device session tracking
+func clientInfo(c echo.Context) appmodels.ClientInfo {
+	userAgent := c.Request().UserAgent()
+	if len(userAgent) > 500 {
+		userAgent = userAgent[:500]
+	}
+	return appmodels.ClientInfo{
+		UserAgent: userAgent,
+		IP:        c.RealIP(),
+	}
+}
+
+// currentDeviceSessionID returns the device session of the request's bearer token
+func currentDeviceSessionID(c echo.Context) string {
+	authHeader := c.Request().Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return ""
+	}
+	return utils.TokenSessionID(authHeader[7:])
+}
+
+// recordToDeviceSession converts an auth session record to the API model
+func recordToDeviceSession(record *models.Record, currentSessionID string) appmodels.DeviceSession {
+	session := appmodels.DeviceSession{
+		ID:         record.Id,
+		Device:     utils.DescribeUserAgent(record.GetString("user_agent")),
+		UserAgent:  record.GetString("user_agent"),
+		IP:         record.GetString("ip"),
+		Country:    record.GetString("country"),
+		ASN:        record.GetInt("asn"),
+		Current:    currentSessionID != "" && record.GetString("jti") == currentSessionID,
+		LastSeenAt: record.GetDateTime("last_seen_at").Time(),
+		ExpiresAt:  record.GetDateTime("expires_at").Time(),
+		Created:    record.GetDateTime("created").Time(),
+	}
+
+	if reasons := record.GetString("suspicious_reasons"); reasons != "" {
+		session.Suspicious = strings.Split(reasons, ",")
+	}
+
+	if revokedAt := record.GetDateTime("revoked_at"); !revokedAt.IsZero() {
+		revokedTime := revokedAt.Time()
+		session.RevokedAt = &revokedTime
+	}
+
+	return session
+}
+
+// locationsToTimedPoints converts location records (ordered by timestamp) for track analysis
+func locationsToTimedPoints(records []*models.Record) []utils.TimedPoint {
+	points := make([]utils.TimedPoint, len(records))
+	for i, record := range records {
+		points[i] = utils.TimedPoint{
+			Timestamp: record.GetDateTime("timestamp").Time(),
+			Latitude:  record.GetFloat("latitude"),
+			Longitude: record.GetFloat("longitude"),
+			Altitude:  utils.RecordAltitude(record),
+		}
+	}
+	return points
+}
+
+// locationsToExportPoints converts location records (ordered by timestamp) for session exports.
+// Number fields store a missing measure as zero, so zero measures are exported as not recorded,
+// except for speeds and temperatures, whose presence is recorded.
+func locationsToExportPoints(records []*models.Record) []utils.ExportPoint {
+	timed := locationsToTimedPoints(records)
+	points := make([]utils.ExportPoint, len(records))
+	for i, record := range records {
+		points[i] = utils.ExportPoint{
+			TimedPoint:  timed[i],
+			Speed:       utils.RecordSpeed(record),
+			HeartRate:   recordedMeasure(record, "heart_rate"),
+			Cadence:     recordedMeasure(record, "cadence"),
+			Power:       recordedMeasure(record, "power"),
+			Temperature: utils.RecordTemperature(record),
+			Accuracy:    recordedMeasure(record, "accuracy"),
+		}
+	}
+	return points
+}
+
+func recordedMeasure(record *models.Record, field string) *float64 {
+	if value := record.GetFloat(field); value != 0 {
+		return &value
+	}
+	return nil
+}
+
+// sessionTrackStats computes the statistics of a session track, with the surface statistics once
+// the session was matched to OSM ways
+func sessionTrackStats(dao *daos.Dao, session *models.Record, thresholds utils.GapThresholds) (*appmodels.SessionStatsResponse, error) {
+	records, err := dao.FindRecordsByFilter(
+		"locations",
+		"session = {:session}",
+		"timestamp",
+		0,
+		0,
+		dbx.Params{"session": session.Id},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return trackStatsOf(session, records, thresholds), nil
+}
+
+// photoCaptureTime returns when a photo was taken from its EXIF wall clock time and the time zone
+// the camera clock was set to, UTC when not given
+func photoCaptureTime(exifData *utils.PhotoEXIFData, timezone string) *time.Time {
+	if exifData.Timestamp == nil {
+		return nil
+	}
+
+	location := time.UTC
+	if timezone != "" {
+		if parsed, err := utils.ParseCameraTimezone(timezone); err == nil {
+			location = parsed
+		}
+	}
+	at := utils.CameraTime(*exifData.Timestamp, location)
+	return &at
+}
+
+// findPositionAt estimates the position at a time from the locations matching filter (see
+// utils.PositionAt). It returns nil when no point was recorded near that time.
+func findPositionAt(dao *daos.Dao, filter string, params dbx.Params, at time.Time, thresholds utils.GapThresholds, maxOffset time.Duration) (*appmodels.HistoricalPosition, error) {
+	queryParams := dbx.Params{"at": at.UTC().Format(types.DefaultDateLayout)}
+	for key, value := range params {
+		queryParams[key] = value
+	}
+
+	before, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+		filter+" && timestamp <= {:at}", "-timestamp", 1, 0, queryParams)
+	if err != nil {
+		return nil, err
+	}
+	after, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+		filter+" && timestamp > {:at}", "timestamp", 1, 0, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var beforePoint, afterPoint *utils.TimedPoint
+	var closest *models.Record
+	if len(before) > 0 {
+		beforePoint = &locationsToTimedPoints(before)[0]
+		closest = before[0]
+	}
+	if len(after) > 0 {
+		afterPoint = &locationsToTimedPoints(after)[0]
+		if closest == nil || afterPoint.Timestamp.Sub(at) < at.Sub(beforePoint.Timestamp) {
+			closest = after[0]
+		}
+	}
+
+	position, method, ok := utils.PositionAt(beforePoint, afterPoint, at, thresholds, maxOffset)
+	if !ok {
+		return nil, nil
+	}
+	expandLocationSessions(dao, closest)
+
+	return &appmodels.HistoricalPosition{
+		Timestamp: at.Unix(),
+		Latitude:  position.Latitude,
+		Longitude: position.Longitude,
+		Altitude:  position.Altitude,
+		Session:   utils.LocationSessionName(closest),
+		Method:    method,
+		Offset:    int64(closest.GetDateTime("timestamp").Time().Sub(at).Abs().Seconds()),
+	}, nil
+}
+
+// trackStatsOf computes the statistics of a session track from its location records, ordered by
+// timestamp
+func trackStatsOf(session *models.Record, records []*models.Record, thresholds utils.GapThresholds) *appmodels.SessionStatsResponse {
+	stats := utils.ComputeTrackStats(locationsToTimedPoints(records), thresholds)
+
+	if !session.GetDateTime("surface_matched_at").IsZero() {
+		var surface appmodels.SessionSurface
+		if err := session.UnmarshalJSONField("surface", &surface); err == nil {
+			stats.Surface = &surface.Summary
+		}
+	}
+
+	return stats
+}
+
+// canAccess reports whether the requesting user's role grants the permission on a resource owned by ownerID
+func canAccess(c echo.Context, permission, ownerID string) bool {
+	authRecord, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if authRecord == nil {
+		authRecord, _ = GetAuthUser(c)
+	}
+	return middleware.CanAccess(authRecord, permission, ownerID)
+}
+
+// isPublicSession reports whether a session is public, has been published and has not been
+// hidden by moderation
+func isPublicSession(session *models.Record) bool {
+	return session.GetBool("public") && !session.GetBool("hidden") && !session.GetBool("draft")
+}
+
+// hasSessionAccess reports whether the current request may read a session: it is public,
+// the requester may read the owner's sessions, a matching share_token query parameter is
+// provided, or a guest token was minted for the session as it is still shared
+func hasSessionAccess(c echo.Context, session *models.Record) bool {
+	if hasShareAccess(c, session) {
+		return true
+	}
+
+	_, exists := sessionGuest(c, session)
+	return exists
+}
+
+// hasShareAccess reports whether the current request may read a session without a guest token
+func hasShareAccess(c echo.Context, session *models.Record) bool {
+	if isPublicSession(session) {
+		return true
+	}
+
+	if canAccess(c, constants.PermSessionsRead, session.GetString("user")) {
+		return true
+	}
+
+	shareToken := c.QueryParam("share_token")
+	storedShareToken := session.GetString("share_token")
+	return shareToken != "" && storedShareToken != "" && shareToken == storedShareToken
+}
+
+// sessionGuest returns the guest viewer of the request when its guest token was minted for the
+// session and the session is still shared the same way
+func sessionGuest(c echo.Context, session *models.Record) (*utils.GuestClaims, bool) {
+	guest, exists := middleware.GetGuestViewer(c)
+	if !exists || guest.SessionID != session.Id || guest.ShareFingerprint == "" ||
+		guest.ShareFingerprint != utils.GuestShareFingerprint(session) {
+		return nil, false
+	}
+	return guest, true
+}
+
+// nextWaypointOrder returns the order value that places a new waypoint at the end of the session
+func nextWaypointOrder(dao *daos.Dao, sessionID string) int {
+	last, err := dao.FindRecordsByFilter("waypoints", "session_id = {:session_id}", "-order", 1, 0,
+		dbx.Params{"session_id": sessionID})
+	if err != nil || len(last) == 0 {
+		return 0
+	}
+	return last[0].GetInt("order") + 1
+}
+
+// findNextWaypoint returns the first unvisited waypoint of a session according to the waypoint order;
+// waypoints hidden by moderation are skipped unless includeHidden is set
+func findNextWaypoint(dao *daos.Dao, sessionID string, includeHidden bool) (*models.Record, error) {
+	filter := "session_id = {:session_id} && visited_at = ''"
+	if !includeHidden {
+		filter += " && hidden = false"
+	}
+	waypoints, err := dao.FindRecordsByFilter("waypoints", filter,
+		constants.WaypointOrderSort, 1, 0, dbx.Params{"session_id": sessionID})
+	if err != nil {
+		return nil, err
+	}
+	if len(waypoints) == 0 {
+		return nil, errors.New("session has no remaining waypoints")
+	}
+	return waypoints[0], nil
+}
+
+// markVisitedWaypoints checks off the unvisited waypoints of a session that lie within
+// radius meters of the given position and returns how many were marked
+func markVisitedWaypoints(dao *daos.Dao, sessionID string, latitude, longitude float64, visitedAt types.DateTime, radius float64) (int, error) {
+	if sessionID == "" || radius <= 0 {
+		return 0, nil
+	}
+
+	waypoints, err := dao.FindRecordsByFilter("waypoints", "session_id = {:session_id} && visited_at = ''",
+		constants.WaypointOrderSort, 0, 0, dbx.Params{"session_id": sessionID})
+	if err != nil {
+		return 0, err
+	}
+
+	marked := 0
+	for _, waypoint := range waypoints {
+		distance := utils.HaversineDistance(latitude, longitude,
+			waypoint.GetFloat("latitude"), waypoint.GetFloat("longitude"))
+		if distance > radius {
+			continue
+		}
+
+		waypoint.Set("visited_at", visitedAt)
+		if err := dao.SaveRecord(waypoint); err != nil {
+			return marked, fmt.Errorf("failed to mark waypoint %s as visited: %v", waypoint.Id, err)
+		}
+		marked++
+	}
+
+	return marked, nil
+}
+
+// recordToWaypoint converts a waypoint record to the API model
+func recordToWaypoint(app *pocketbase.PocketBase, record *models.Record) *appmodels.Waypoint {
+	waypoint := &appmodels.Waypoint{
+		ID:                 record.Id,
+		Name:               record.GetString("name"),
+		Type:               record.GetString("type"),
+		Description:        record.GetString("description"),
+		DescriptionHTML:    utils.RenderMarkdown(record.GetString("description")),
+		Latitude:           record.GetFloat("latitude"),
+		Longitude:          record.GetFloat("longitude"),
+		Photo:              record.GetString("photo"),
+		PhotoURL:           signedFileURL(app, record, "photo"),
+		SessionID:          record.GetString("session_id"),
+		UserID:             record.GetString("user"),
+		Source:             record.GetString("source"),
+		PositionConfidence: record.GetString("position_confidence"),
+		Order:              record.GetInt("order"),
+		Created:            record.GetDateTime("created").Time(),
+		Updated:            record.GetDateTime("updated").Time(),
+	}
+
+	waypoint.Altitude = utils.RecordAltitude(record)
+
+	if visitedAt := record.GetDateTime("visited_at"); !visitedAt.IsZero() {
+		visitedTime := visitedAt.Time()
+		waypoint.VisitedAt = &visitedTime
+	}
+
+	return waypoint
+}
+
+// waypointToFeature formats a waypoint record as a GeoJSON Feature
+func waypointToFeature(app *pocketbase.PocketBase, waypoint *models.Record) map[string]any {
+	properties := map[string]any{
+		"id":                  waypoint.Id,
+		"name":                waypoint.GetString("name"),
+		"type":                waypoint.GetString("type"),
+		"description":         waypoint.GetString("description"),
+		"description_html":    utils.RenderMarkdown(waypoint.GetString("description")),
+		"session_id":          waypoint.GetString("session_id"),
+		"user":                waypoint.GetString("user"),
+		"source":              waypoint.GetString("source"),
+		"position_confidence": waypoint.GetString("position_confidence"),
+		"order":               waypoint.GetInt("order"),
+		"created":             waypoint.GetDateTime("created").Time().Format(time.RFC3339),
+		"updated":             waypoint.GetDateTime("updated").Time().Format(time.RFC3339),
+	}
+
+	// Add optional fields
+	if altitude := utils.RecordAltitude(waypoint); altitude != nil {
+		properties["altitude"] = *altitude
+	}
+
+	if photo := waypoint.GetString("photo"); photo != "" {
+		properties["photo"] = photo
+		properties["photo_url"] = signedFileURL(app, waypoint, "photo")
+	}
+
+	if visitedAt := waypoint.GetDateTime("visited_at"); !visitedAt.IsZero() {
+		properties["visited_at"] = visitedAt.Time().Format(time.RFC3339)
+	}
+
+	return map[string]any{
+		"type": "Feature",
+		"id":   waypoint.Id,
+		"geometry": map[string]any{
+			"type": "Point",
+			"coordinates": []float64{
+				waypoint.GetFloat("longitude"),
+				waypoint.GetFloat("latitude"),
+			},
+		},
+		"properties": properties,
+	}
+}
+
 // GenerateSessionTitle is deprecated, use utils.GenerateSessionTitle instead
 func GenerateSessionTitle(sessionName string) string {
 	return utils.GenerateSessionTitle(sessionName)
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// TrackHomeAssistantLocation tracks a location posted in Home Assistant's webhook format
+//
+//	@Summary		Track location (Home Assistant)
+//	@Description	Tracks a location sent in the format of Home Assistant's mobile_app "update_location" webhooks, so a Home Assistant automation can forward device_tracker updates unchanged. Updates carry no timestamp and are recorded at the time they are received; the zone in location_name is tracked as the point status.
+//	@Tags			Tracking
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		TokenAuth
+//	@Param			request	body		models.HomeAssistantWebhookRequest	true	"Location update"
+//	@Param			session	query		string	false	"Session name"
+//	@Param			device	query		string	false	"Reporting device identifier"
+//	@Success		200		{object}	models.SuccessResponse	"Location tracked successfully"
+//	@Failure		400		{object}	models.ErrorResponse	"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse	"Authentication required"
+//	@Router			/integrations/home-assistant/webhook [post]
+func (h *TrackingHandler) TrackHomeAssistantLocation(c echo.Context) error {
+	user, exists := GetAuthUser(c)
+	if !exists {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	req, ok := middleware.GetValidatedData(c).(*appmodels.HomeAssistantWebhookRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	data := req.Data
+	params := &appmodels.TrackingQueryParams{
+		Token:     constants.HomeAssistantSource, // Authenticated already, the token is only an identifier here
+		Latitude:  data.GPS[0],
+		Longitude: data.GPS[1],
+		Altitude:  data.Altitude,
+		Accuracy:  data.GPSAccuracy,
+		Battery:   data.Battery,
+		SpeedUnit: "m/s",
+		Device:    c.QueryParam("device"),
+		Session:   c.QueryParam("session"),
+		Status:    data.LocationName,
+	}
+	if data.Speed != nil && *data.Speed >= 0 {
+		params.Speed = data.Speed // Companion apps report -1 when the speed is unknown
+	}
+	if data.Course != nil && *data.Course >= 0 {
+		params.Bearing = data.Course
+	}
+	if data.VerticalAccuracy != nil && *data.VerticalAccuracy >= 0 {
+		params.VerticalAccuracy = data.VerticalAccuracy
+	}
+	if err := utils.ValidateStruct(params); err != nil {
+		return apis.NewBadRequestError("Validation failed", err)
+	}
+
+	record, err := h.trackPoint(c.Request().Context(), user, params)
+	if err != nil {
+		return err
+	}
+
+	return sendTrackedPoint(c, record)
+}
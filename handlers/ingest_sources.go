@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/services"
+	"vibe-tracker/utils"
+)
+
+type IngestSourceHandler struct {
+	app      *pocketbase.PocketBase
+	sources  *services.IngestSourceService
+	tracking *TrackingHandler
+}
+
+func NewIngestSourceHandler(app *pocketbase.PocketBase, sources *services.IngestSourceService, tracking *TrackingHandler) *IngestSourceHandler {
+	return &IngestSourceHandler{
+		app:      app,
+		sources:  sources,
+		tracking: tracking,
+	}
+}
+
+// ListIngestSources lists the webhook ingest sources of the current user
+//
+//	@Summary		List ingest sources
+//	@Description	Returns the authenticated user's webhook ingest sources with their webhook URLs and secrets
+//	@Tags			Ingest Sources
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.SuccessResponse{data=[]models.IngestSource}	"Ingest sources retrieved successfully"
+//	@Failure		401	{object}	models.ErrorResponse								"Authentication required"
+//	@Router			/profile/ingest-sources [get]
+func (h *IngestSourceHandler) ListIngestSources(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	sources, err := h.sources.List(record.Id)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, sources, "")
+}
+
+// CreateIngestSource adds a webhook ingest source for the current user
+//
+//	@Summary		Add ingest source
+//	@Description	Adds a webhook ingest source for a third-party service that can POST JSON. The mapping maps point fields (latitude, longitude, timestamp, altitude, speed, speed_unit, accuracy, device, battery, heart_rate, cadence, power, temperature, session, status, event) to JSONPath-like paths into the webhook body such as $.attributes.latitude, or to constants; latitude and longitude are required.
+//	@Tags			Ingest Sources
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.IngestSourceRequest							true	"Source data"
+//	@Success		201		{object}	models.SuccessResponse{data=models.IngestSource}	"Ingest source added successfully"
+//	@Failure		400		{object}	models.ErrorResponse								"Invalid request, mapping or too many sources"
+//	@Failure		401		{object}	models.ErrorResponse								"Authentication required"
+//	@Router			/profile/ingest-sources [post]
+func (h *IngestSourceHandler) CreateIngestSource(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	req, ok := middleware.GetValidatedData(c).(*appmodels.IngestSourceRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	source, err := h.sources.Create(record.Id, *req)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusCreated, source, "Ingest source added successfully")
+}
+
+// UpdateIngestSource changes a webhook ingest source of the current user
+//
+//	@Summary		Update ingest source
+//	@Description	Updates the name, default session and mapping of an ingest source; its webhook URL and secret stay the same
+//	@Tags			Ingest Sources
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string												true	"Ingest source ID"
+//	@Param			request	body		models.IngestSourceRequest							true	"Source data"
+//	@Success		200		{object}	models.SuccessResponse{data=models.IngestSource}	"Ingest source updated successfully"
+//	@Failure		400		{object}	models.ErrorResponse								"Invalid request or mapping"
+//	@Failure		401		{object}	models.ErrorResponse								"Authentication required"
+//	@Failure		404		{object}	models.ErrorResponse								"Ingest source not found"
+//	@Router			/profile/ingest-sources/{id} [put]
+func (h *IngestSourceHandler) UpdateIngestSource(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	req, ok := middleware.GetValidatedData(c).(*appmodels.IngestSourceRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	source, err := h.sources.Update(record.Id, c.PathParam("id"), *req)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, source, "Ingest source updated successfully")
+}
+
+// DeleteIngestSource removes a webhook ingest source of the current user
+//
+//	@Summary		Delete ingest source
+//	@Description	Removes an ingest source; its webhook URL stops accepting points
+//	@Tags			Ingest Sources
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Ingest source ID"
+//	@Success		200	{object}	models.SuccessResponse	"Ingest source deleted successfully"
+//	@Failure		401	{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		404	{object}	models.ErrorResponse	"Ingest source not found"
+//	@Router			/profile/ingest-sources/{id} [delete]
+func (h *IngestSourceHandler) DeleteIngestSource(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	if err := h.sources.Delete(record.Id, c.PathParam("id")); err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Ingest source deleted successfully")
+}
+
+// ReceiveWebhook tracks a location posted by a third-party service to its ingest source
+//
+//	@Summary		Receive ingest webhook
+//	@Description	Tracks the location in a JSON body posted by a third-party service such as IFTTT or Home Assistant. The point is built with the field mapping of the source; points without a mapped session go to the source's session, or the automatic session. The source secret is sent in the X-Webhook-Secret header or the secret query parameter.
+//	@Tags			Tracking
+//	@Accept			json
+//	@Produce		json
+//	@Param			source_id			path		string	true	"Ingest source ID"
+//	@Param			secret				query		string	false	"Source secret, unless sent in the X-Webhook-Secret header"
+//	@Param			allow_historical	query		bool	false	"Accept timestamps older than the configured maximum age"
+//	@Success		200					{object}	models.SuccessResponse	"Location tracked successfully"
+//	@Failure		400					{object}	models.ErrorResponse	"Invalid body or mapped point"
+//	@Failure		401					{object}	models.ErrorResponse	"Invalid ingest source or secret"
+//	@Failure		403					{object}	models.ErrorResponse	"The owner may not track"
+//	@Failure		413					{object}	models.ErrorResponse	"Body too large"
+//	@Router			/ingest/webhook/{source_id} [post]
+func (h *IngestSourceHandler) ReceiveWebhook(c echo.Context) error {
+	secret := c.Request().Header.Get(constants.IngestWebhookSecretHeader)
+	if secret == "" {
+		secret = c.QueryParam(constants.IngestWebhookSecretParam)
+	}
+	source, err := h.sources.Authenticate(c.PathParam("source_id"), secret)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, constants.IngestWebhookMaxBodySize+1))
+	if err != nil {
+		return apis.NewBadRequestError("Failed to read request body", err)
+	}
+	if len(body) > constants.IngestWebhookMaxBodySize {
+		return apis.NewApiError(http.StatusRequestEntityTooLarge, "Request body too large", nil)
+	}
+
+	params, err := utils.MapWebhookLocation(body, h.sources.Mapping(source))
+	if err != nil {
+		return apis.NewBadRequestError("Validation failed", err)
+	}
+	params.Token = source.Id // Sources are identified by their record, they have no token
+	if params.Session == "" {
+		params.Session = source.GetString("session")
+	}
+	if err := utils.ValidateStruct(params); err != nil {
+		return apis.NewBadRequestError("Validation failed", err)
+	}
+	if err := h.tracking.checkTimestamp(c, params.Timestamp); err != nil {
+		return err
+	}
+
+	user, err := requestDao(h.app, c).FindRecordById(constants.CollectionUsers, source.GetString("user"))
+	if err != nil {
+		return apis.NewNotFoundError("User not found", err)
+	}
+	if !utils.HasPermission(middleware.GetUserRole(user), constants.PermTrackingWrite) {
+		return apis.NewForbiddenError("Your role does not allow this action", nil)
+	}
+
+	record, err := h.tracking.trackPoint(c.Request().Context(), user, params)
+	if err != nil {
+		return err
+	}
+	h.sources.Touch(source)
+
+	return sendTrackedPoint(c, record)
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/services"
+	"vibe-tracker/utils"
+)
+
+type LiveHandler struct {
+	app         *pocketbase.PocketBase
+	liveService *services.LiveService
+}
+
+func NewLiveHandler(app *pocketbase.PocketBase, liveService *services.LiveService) *LiveHandler {
+	return &LiveHandler{
+		app:         app,
+		liveService: liveService,
+	}
+}
+
+// StreamSession streams live updates of a session as Server-Sent Events
+//
+//	@Summary		Stream session updates
+//	@Description	Streams new locations of a session as Server-Sent Events ("location" events). The owner always receives "viewers" events with the live viewer count; other viewers receive them only if the session has show_viewer_count enabled.
+//	@Tags			Public
+//	@Produce		text/event-stream
+//	@Param			username	path	string	true	"Username"
+//	@Param			session		path	string	true	"Session name"
+//	@Param			share_token	query	string	false	"Share token for private sessions"
+//	@Param			guest_token	query	string	false	"Guest viewer token"
+//	@Success		200			"Event stream"
+//	@Failure		403			{object}	models.ErrorResponse	"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse	"User or session not found"
+//	@Router			/session/{username}/{session}/stream [get]
+func (h *LiveHandler) StreamSession(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("session"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	if !hasSessionAccess(c, session) {
+		return apis.NewForbiddenError("Access denied", nil)
+	}
+
+	canManage := canAccess(c, constants.PermSessionsWrite, user.Id)
+	showViewerCount := canManage || session.GetBool("show_viewer_count")
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	// The owner (or an admin) watching the session does not count as a viewer,
+	// guests are counted once per viewer token
+	var sub *services.LiveSubscription
+	if guest, exists := sessionGuest(c, session); exists && !canManage {
+		sub = h.liveService.SubscribeGuest(session.Id, guest.ViewerID)
+	} else {
+		sub = h.liveService.Subscribe(session.Id, !canManage)
+	}
+	defer h.liveService.Unsubscribe(session.Id, sub)
+
+	heartbeat := time.NewTicker(constants.LiveStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	ctx := c.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case event := <-sub.Events:
+			if event.Type == services.LiveEventViewers && !showViewerCount {
+				continue
+			}
+
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				utils.LogError(err, "failed to encode live event").Str("session_id", session.Id).Send()
+				continue
+			}
+			if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// GetViewerCount returns the number of live viewers of one of the user's sessions
+//
+//	@Summary		Get live viewer count
+//	@Description	Returns the number of viewers currently streaming the session (owner only)
+//	@Tags			Sessions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Success		200			{object}	models.SuccessResponse	"Viewer count retrieved successfully"
+//	@Failure		401			{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse	"Session not found"
+//	@Router			/sessions/{username}/{name}/viewers [get]
+func (h *LiveHandler) GetViewerCount(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	response := map[string]any{
+		"session_id":        session.Id,
+		"viewers":           h.liveService.ViewerCount(session.Id),
+		"show_viewer_count": session.GetBool("show_viewer_count"),
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, response, "")
+}
+
+// BroadcastLocation publishes newly created locations to the streams of their session
+func (h *LiveHandler) BroadcastLocation(e *core.ModelEvent) error {
+	record, ok := e.Model.(*models.Record)
+	if !ok {
+		return nil
+	}
+
+	sessionID := record.GetString("session")
+	if sessionID == "" || !h.liveService.HasSubscribers(sessionID) {
+		return nil
+	}
+	if record.ExpandedOne("session") == nil {
+		expandLocationSessions(h.app.Dao(), record) // Created without the tracking handlers
+	}
+
+	properties := map[string]any{
+		"timestamp":  record.GetDateTime("timestamp").Time().Unix(),
+		"speed":      record.GetFloat("speed"),
+		"heart_rate": record.GetFloat("heart_rate"),
+		"session":    utils.LocationSessionName(record),
+	}
+	if status := record.GetString("status"); status != "" {
+		properties["status"] = status
+	}
+	if event := record.GetString("event"); event != "" {
+		properties["event"] = event
+	}
+	utils.AddSensorProperties(properties, record)
+
+	h.liveService.Publish(sessionID, services.LiveEvent{
+		Type: services.LiveEventLocation,
+		Data: map[string]any{
+			"type": "Feature",
+			"geometry": map[string]any{
+				"type":        "Point",
+				"coordinates": utils.RecordCoordinates(record),
+			},
+			"properties": properties,
+		},
+	})
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/rs/zerolog"
+
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+type LoggingHandler struct{}
+
+func NewLoggingHandler() *LoggingHandler {
+	return &LoggingHandler{}
+}
+
+// GetLogLevels returns the current log levels
+//
+//	@Summary		Get log levels
+//	@Description	Returns the default log level and the level overrides of modules; requires the system:manage permission
+//	@Tags			Admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.SuccessResponse{data=models.LogLevels}	"Log levels retrieved successfully"
+//	@Failure		401	{object}	models.ErrorResponse						"Authentication required"
+//	@Failure		403	{object}	models.ErrorResponse						"Forbidden"
+//	@Router			/admin/log-levels [get]
+func (h *LoggingHandler) GetLogLevels(c echo.Context) error {
+	return utils.SendSuccess(c, http.StatusOK, currentLogLevels(), "Log levels retrieved successfully")
+}
+
+// UpdateLogLevel changes the default log level or the level of a module until the next restart
+//
+//	@Summary		Change log level
+//	@Description	Changes the default log level, or the level of a module such as http or security. Changes are not persisted, the configured levels apply again after a restart. Requires the system:manage permission.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.UpdateLogLevelRequest					true	"Module and level"
+//	@Success		200		{object}	models.SuccessResponse{data=models.LogLevels}	"Log level updated successfully"
+//	@Failure		400		{object}	models.ErrorResponse							"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse							"Authentication required"
+//	@Failure		403		{object}	models.ErrorResponse							"Forbidden"
+//	@Router			/admin/log-levels [put]
+func (h *LoggingHandler) UpdateLogLevel(c echo.Context) error {
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.UpdateLogLevelRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	if data.Level == "reset" {
+		if data.Module == "" {
+			return apis.NewBadRequestError("Only module levels can be reset", nil)
+		}
+		utils.ResetLogLevel(data.Module)
+	} else {
+		level, err := zerolog.ParseLevel(data.Level)
+		if err != nil {
+			return apis.NewBadRequestError("Invalid log level", nil)
+		}
+		utils.SetLogLevel(data.Module, level)
+	}
+
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	event := utils.LogInfo().
+		Str("module", data.Module).
+		Str("level", data.Level)
+	if record != nil {
+		event = event.Str("changed_by", record.Id)
+	}
+	event.Msg("Log level changed")
+
+	return utils.SendSuccess(c, http.StatusOK, currentLogLevels(), "Log level updated successfully")
+}
+
+func currentLogLevels() appmodels.LogLevels {
+	base, modules := utils.LogLevels()
+
+	levels := appmodels.LogLevels{Level: base.String(), Modules: make(map[string]string, len(modules))}
+	for module, level := range modules {
+		levels.Modules[module] = level.String()
+	}
+	return levels
+}
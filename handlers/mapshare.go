@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// MapShareImporter polls the Garmin MapShare KML feeds users configured in the mapshare_feeds
+// collection and tracks the positions of their inReach satellite trackers. Positions are merged
+// into the session the user tracked into with the phone around the same time.
+type MapShareImporter struct {
+	tracking *TrackingHandler
+	interval time.Duration
+	client   *http.Client
+	stop     chan struct{}
+}
+
+// NewMapShareImporter creates an importer polling the feeds at the given interval
+func NewMapShareImporter(tracking *TrackingHandler, interval time.Duration) *MapShareImporter {
+	return &MapShareImporter{
+		tracking: tracking,
+		interval: interval,
+		client: &http.Client{
+			Timeout: constants.MapShareTimeout,
+			// Feeds are only fetched from Garmin, also when Garmin redirects
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if req.URL.Scheme != "https" || !utils.IsMapShareHost(req.URL.Host) {
+					return fmt.Errorf("MapShare feed redirected to %s", req.URL.Host)
+				}
+				if len(via) >= 10 {
+					return errors.New("stopped after 10 redirects")
+				}
+				return nil
+			},
+		},
+		stop: make(chan struct{}),
+	}
+}
+
+// Start runs the periodic import while the server is running
+func (i *MapShareImporter) Start(e *core.ServeEvent) error {
+	go func() {
+		ticker := time.NewTicker(i.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				i.PollFeeds()
+			case <-i.stop:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop ends the periodic import
+func (i *MapShareImporter) Stop(e *core.TerminateEvent) error {
+	close(i.stop)
+	return nil
+}
+
+// PollFeeds imports the new positions of all feeds. A failing feed is logged and retried next
+// run, it does not keep the other feeds from being imported.
+func (i *MapShareImporter) PollFeeds() {
+	feeds, err := i.tracking.app.Dao().FindRecordsByFilter(constants.CollectionMapShareFeeds, "id != ''", "created", 0, 0)
+	if err != nil {
+		utils.LogError(err, "mapshare import").Msg("Failed to list MapShare feeds")
+		return
+	}
+
+	for _, feed := range feeds {
+		imported, err := i.importFeed(feed)
+		if err != nil {
+			utils.LogWarn().Err(err).Str("feed_id", feed.Id).Str("user_id", feed.GetString("user")).Msg("Failed to import MapShare feed")
+		}
+		if imported > 0 {
+			utils.LogInfo().Str("feed_id", feed.Id).Int("positions", imported).Msg("Imported MapShare positions")
+		}
+	}
+}
+
+// importFeed tracks the positions of a feed newer than the last imported one and returns how
+// many were imported
+func (i *MapShareImporter) importFeed(feed *models.Record) (int, error) {
+	dao := i.tracking.app.Dao()
+	user, err := dao.FindRecordById(constants.CollectionUsers, feed.GetString("user"))
+	if err != nil {
+		return 0, err
+	}
+	if !utils.HasPermission(middleware.GetUserRole(user), constants.PermTrackingWrite) {
+		return 0, nil // The user's role may not track
+	}
+
+	// Positions sent before the feed was configured are not imported
+	since := feed.GetDateTime("last_point_at").Time()
+	if since.IsZero() {
+		since = feed.Created.Time()
+	}
+	points, err := i.fetch(feed, since)
+	if err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	lastPointAt := since
+	var importErr error
+	for _, point := range points {
+		if !point.Time.After(lastPointAt) {
+			continue
+		}
+		if err := i.importPoint(feed, user, point); err != nil {
+			importErr = err
+			break // Retried next run
+		}
+		lastPointAt = point.Time
+		if point.Valid {
+			imported++
+		}
+	}
+
+	if lastPointAt.After(since) || feed.GetDateTime("last_point_at").IsZero() {
+		value, _ := types.ParseDateTime(lastPointAt)
+		feed.Set("last_point_at", value)
+		if err := dao.SaveRecord(feed); err != nil && importErr == nil {
+			importErr = err
+		}
+	}
+	return imported, importErr
+}
+
+// importPoint tracks a feed position. Positions without a GPS fix and invalid positions are
+// dropped, only a failure to store the position is returned.
+func (i *MapShareImporter) importPoint(feed, user *models.Record, point utils.MapSharePoint) error {
+	if !point.Valid {
+		return nil
+	}
+
+	params := &appmodels.TrackingQueryParams{
+		Token:     feed.Id, // Feeds are identified by their record, they have no token
+		Latitude:  point.Latitude,
+		Longitude: point.Longitude,
+		Timestamp: point.Time.Unix(),
+		Altitude:  point.Altitude,
+		Speed:     point.Speed,
+		SpeedUnit: constants.MapShareSpeedUnit,
+		Device:    point.Device,
+		Session:   feed.GetString("session"),
+	}
+	if params.Session == "" {
+		params.Session = i.sessionAt(user.Id, point.Time)
+	}
+	if err := utils.ValidateStruct(params); err != nil {
+		utils.LogWarn().Err(err).Str("feed_id", feed.Id).Msg("Dropped invalid MapShare position")
+		return nil
+	}
+	// Satellite messages can arrive late, only positions from the future are rejected
+	if err := i.tracking.validateTimestamp(params.Timestamp, true); err != nil {
+		utils.LogWarn().Err(err).Str("feed_id", feed.Id).Msg("Dropped MapShare position with an invalid timestamp")
+		return nil
+	}
+
+	_, err := i.tracking.trackPoint(context.Background(), user, params)
+	return err
+}
+
+// fetch downloads the feed positions since the given time
+func (i *MapShareImporter) fetch(feed *models.Record, since time.Time) ([]utils.MapSharePoint, error) {
+	feedURL, err := utils.MapShareFeedURL(feed.GetString("url"), since)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Password protected maps take the password with any user name
+	if password := feed.GetString("password"); password != "" {
+		req.SetBasicAuth("", password)
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("MapShare feed returned status %d", resp.StatusCode)
+	}
+	return utils.ParseMapShareKML(io.LimitReader(resp.Body, constants.MapShareMaxFeedSize))
+}
+
+// sessionAt returns the session of the user's location closest to the given time within the
+// merge window, preferring earlier locations; empty when the user did not track around then
+func (i *MapShareImporter) sessionAt(userID string, at time.Time) string {
+	dao := i.tracking.app.Dao()
+	params := dbx.Params{
+		"user": userID,
+		"at":   at.UTC().Format(types.DefaultDateLayout),
+		"from": at.Add(-constants.MapShareMergeWindow).UTC().Format(types.DefaultDateLayout),
+		"to":   at.Add(constants.MapShareMergeWindow).UTC().Format(types.DefaultDateLayout),
+	}
+
+	before, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+		"user = {:user} && session != '' && timestamp <= {:at} && timestamp >= {:from}", "-timestamp", 1, 0, params)
+	if err == nil && len(before) > 0 {
+		expandLocationSessions(dao, before[0])
+		return utils.LocationSessionName(before[0])
+	}
+	after, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+		"user = {:user} && session != '' && timestamp > {:at} && timestamp <= {:to}", "timestamp", 1, 0, params)
+	if err == nil && len(after) > 0 {
+		expandLocationSessions(dao, after[0])
+		return utils.LocationSessionName(after[0])
+	}
+	return ""
+}
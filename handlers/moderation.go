@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+type ModerationHandler struct {
+	app *pocketbase.PocketBase
+}
+
+func NewModerationHandler(app *pocketbase.PocketBase) *ModerationHandler {
+	return &ModerationHandler{
+		app: app,
+	}
+}
+
+// CreateReport reports public content for moderation
+//
+//	@Summary		Report content
+//	@Description	Reports a public session, waypoint or community waypoint. Once enough reports are pending the content is hidden from public feeds until a moderator reviews it.
+//	@Tags			Moderation
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.CreateReportRequest	true	"Report details"
+//	@Success		201		{object}	models.SuccessResponse{data=models.Report}	"Report submitted successfully"
+//	@Failure		400		{object}	models.ErrorResponse						"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse						"Authentication required"
+//	@Failure		404		{object}	models.ErrorResponse						"Content not found"
+//	@Failure		409		{object}	models.ErrorResponse						"Already reported"
+//	@Router			/report [post]
+func (h *ModerationHandler) CreateReport(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.CreateReportRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	// Only content that is currently public can be reported
+	target, err := findReportTarget(requestDao(h.app, c), data.TargetType, data.TargetID)
+	if err != nil || !isReportTargetPublic(requestDao(h.app, c), data.TargetType, target) {
+		return apis.NewNotFoundError("Content not found", err)
+	}
+
+	ownerID := target.GetString("user")
+	if ownerID == record.Id {
+		return apis.NewBadRequestError("You cannot report your own content", nil)
+	}
+
+	if _, err := requestDao(h.app, c).FindFirstRecordByFilter(constants.CollectionReports,
+		"target_type = {:type} && target_id = {:id} && reporter = {:reporter}",
+		dbx.Params{"type": data.TargetType, "id": target.Id, "reporter": record.Id}); err == nil {
+		return apis.NewApiError(http.StatusConflict, "You have already reported this content", nil)
+	}
+
+	var report *models.Record
+	err = requestDao(h.app, c).RunInTransaction(func(txDao *daos.Dao) error {
+		collection, err := txDao.FindCollectionByNameOrId(constants.CollectionReports)
+		if err != nil {
+			return err
+		}
+
+		report = models.NewRecord(collection)
+		report.Set("target_type", data.TargetType)
+		report.Set("target_id", target.Id)
+		report.Set("target_owner", ownerID)
+		report.Set("reporter", record.Id)
+		report.Set("reason", data.Reason)
+		report.Set("comment", data.Comment)
+		report.Set("status", constants.ReportStatusPending)
+		if err := txDao.SaveRecord(report); err != nil {
+			return err
+		}
+
+		pending, err := txDao.FindRecordsByFilter(constants.CollectionReports,
+			"target_type = {:type} && target_id = {:id} && status = {:status}", "", 0, 0,
+			dbx.Params{"type": data.TargetType, "id": target.Id, "status": constants.ReportStatusPending})
+		if err != nil {
+			return err
+		}
+		if len(pending) < constants.ReportHideThreshold {
+			return nil
+		}
+
+		return setReportTargetHidden(txDao, data.TargetType, target, true)
+	})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to submit report", err)
+	}
+
+	utils.LogInfo().
+		Str("report_id", report.Id).
+		Str("target_type", data.TargetType).
+		Str("target_id", target.Id).
+		Str("reporter", record.Id).
+		Msg("Content reported")
+
+	return utils.SendSuccess(c, http.StatusCreated, recordToReport(report), "Report submitted successfully")
+}
+
+// ListReports lists reports in the moderation review queue
+//
+//	@Summary		List reports
+//	@Description	Returns reports for review, oldest first; requires the reports:review permission
+//	@Tags			Moderation
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status		query		string	false	"Report status (pending, dismissed, actioned; default: pending)"
+//	@Param			target_type	query		string	false	"Content type filter (session, waypoint, community_waypoint)"
+//	@Param			page		query		int		false	"Page number (default: 1)"
+//	@Param			perPage		query		int		false	"Items per page (default: 50, max: 100)"
+//	@Success		200			{object}	models.PaginatedResponse{data=[]models.Report}	"Reports retrieved successfully"
+//	@Failure		400			{object}	models.ErrorResponse							"Invalid filter"
+//	@Failure		401			{object}	models.ErrorResponse							"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse							"Forbidden"
+//	@Router			/admin/reports [get]
+func (h *ModerationHandler) ListReports(c echo.Context) error {
+	status := c.QueryParam("status")
+	switch status {
+	case "":
+		status = constants.ReportStatusPending
+	case constants.ReportStatusPending, constants.ReportStatusDismissed, constants.ReportStatusActioned:
+	default:
+		return apis.NewBadRequestError("status must be one of: pending, dismissed, actioned", nil)
+	}
+
+	filter := "status = {:status}"
+	params := dbx.Params{"status": status}
+
+	switch targetType := c.QueryParam("target_type"); targetType {
+	case "":
+	case constants.ReportTargetSession, constants.ReportTargetWaypoint, constants.ReportTargetCommunityWaypoint:
+		filter += " && target_type = {:target_type}"
+		params["target_type"] = targetType
+	default:
+		return apis.NewBadRequestError("target_type must be one of: session, waypoint, community_waypoint", nil)
+	}
+
+	// Parse pagination parameters
+	page := constants.DefaultPage
+	perPage := constants.ReportQueueLimit
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	if perPageStr := c.QueryParam("perPage"); perPageStr != "" {
+		if pp, err := strconv.Atoi(perPageStr); err == nil && pp > 0 && pp <= constants.MaxPerPageLimit {
+			perPage = pp
+		}
+	}
+
+	records, err := requestDao(h.app, c).FindRecordsByFilter(constants.CollectionReports, filter, "created",
+		perPage, (page-1)*perPage, params)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch reports", err)
+	}
+
+	total, err := requestDao(h.app, c).FindRecordsByFilter(constants.CollectionReports, filter, "", 0, 0, params)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to count reports", err)
+	}
+
+	reports := make([]*appmodels.Report, len(records))
+	for i, record := range records {
+		reports[i] = recordToReport(record)
+	}
+
+	paginationMeta := appmodels.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		TotalItems: int64(len(total)),
+		TotalPages: (len(total) + perPage - 1) / perPage,
+	}
+
+	return utils.SendPaginated(c, http.StatusOK, reports, paginationMeta, "")
+}
+
+// ReviewReport resolves a report and all other pending reports of the same content
+//
+//	@Summary		Review report
+//	@Description	Dismisses the reports and restores the content, or confirms them and keeps the content hidden from public feeds; requires the reports:review permission
+//	@Tags			Moderation
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string						true	"Report ID"
+//	@Param			request	body		models.ReviewReportRequest	true	"Review decision"
+//	@Success		200		{object}	models.SuccessResponse{data=models.Report}	"Report reviewed successfully"
+//	@Failure		400		{object}	models.ErrorResponse						"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse						"Authentication required"
+//	@Failure		403		{object}	models.ErrorResponse						"Forbidden"
+//	@Failure		404		{object}	models.ErrorResponse						"Report not found"
+//	@Router			/admin/reports/{id} [put]
+func (h *ModerationHandler) ReviewReport(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.ReviewReportRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	report, err := requestDao(h.app, c).FindRecordById(constants.CollectionReports, c.PathParam("id"))
+	if err != nil {
+		return apis.NewNotFoundError("Report not found", err)
+	}
+
+	targetType := report.GetString("target_type")
+	status := constants.ReportStatusDismissed
+	if data.Action == "hide" {
+		status = constants.ReportStatusActioned
+	}
+
+	err = requestDao(h.app, c).RunInTransaction(func(txDao *daos.Dao) error {
+		// The content may have been deleted since it was reported
+		if target, err := findReportTarget(txDao, targetType, report.GetString("target_id")); err == nil {
+			if err := setReportTargetHidden(txDao, targetType, target, data.Action == "hide"); err != nil {
+				return err
+			}
+		}
+
+		reports, err := txDao.FindRecordsByFilter(constants.CollectionReports,
+			"target_type = {:type} && target_id = {:id} && (status = {:pending} || id = {:report})", "", 0, 0,
+			dbx.Params{
+				"type":    targetType,
+				"id":      report.GetString("target_id"),
+				"pending": constants.ReportStatusPending,
+				"report":  report.Id,
+			})
+		if err != nil {
+			return err
+		}
+
+		now := types.NowDateTime()
+		for _, pending := range reports {
+			pending.Set("status", status)
+			pending.Set("reviewed_by", record.Id)
+			pending.Set("reviewed_at", now)
+			if err := txDao.SaveRecord(pending); err != nil {
+				return err
+			}
+			if pending.Id == report.Id {
+				report = pending
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to review report", err)
+	}
+
+	utils.LogInfo().
+		Str("report_id", report.Id).
+		Str("target_type", targetType).
+		Str("target_id", report.GetString("target_id")).
+		Str("reviewer", record.Id).
+		Str("status", status).
+		Msg("Report reviewed")
+
+	return utils.SendSuccess(c, http.StatusOK, recordToReport(report), "Report reviewed successfully")
+}
+
+// findReportTarget loads the record a report points at
+func findReportTarget(dao *daos.Dao, targetType, targetID string) (*models.Record, error) {
+	switch targetType {
+	case constants.ReportTargetSession:
+		return dao.FindRecordById(constants.CollectionSessions, targetID)
+	case constants.ReportTargetWaypoint:
+		return dao.FindRecordById("waypoints", targetID)
+	case constants.ReportTargetCommunityWaypoint:
+		return dao.FindRecordById("community_waypoints", targetID)
+	default:
+		return nil, errors.New("unknown report target type")
+	}
+}
+
+// isReportTargetPublic reports whether the content is currently visible to everyone
+func isReportTargetPublic(dao *daos.Dao, targetType string, target *models.Record) bool {
+	switch targetType {
+	case constants.ReportTargetSession:
+		return isPublicSession(target)
+	case constants.ReportTargetWaypoint:
+		if target.GetBool("hidden") || target.GetString("session_id") == "" {
+			return false
+		}
+		session, err := dao.FindRecordById(constants.CollectionSessions, target.GetString("session_id"))
+		return err == nil && isPublicSession(session)
+	case constants.ReportTargetCommunityWaypoint:
+		return target.GetString("status") == "visible"
+	default:
+		return false
+	}
+}
+
+// setReportTargetHidden hides or restores reported content; community waypoints use their own moderation status
+func setReportTargetHidden(dao *daos.Dao, targetType string, target *models.Record, hidden bool) error {
+	if targetType == constants.ReportTargetCommunityWaypoint {
+		status := "visible"
+		if hidden {
+			status = "hidden"
+		}
+		target.Set("status", status)
+	} else {
+		target.Set("hidden", hidden)
+	}
+	return dao.SaveRecord(target)
+}
+
+// recordToReport converts a report record to the API model
+func recordToReport(record *models.Record) *appmodels.Report {
+	report := &appmodels.Report{
+		ID:          record.Id,
+		TargetType:  record.GetString("target_type"),
+		TargetID:    record.GetString("target_id"),
+		TargetOwner: record.GetString("target_owner"),
+		Reporter:    record.GetString("reporter"),
+		Reason:      record.GetString("reason"),
+		Comment:     record.GetString("comment"),
+		Status:      record.GetString("status"),
+		ReviewedBy:  record.GetString("reviewed_by"),
+		Created:     record.GetDateTime("created").Time(),
+	}
+
+	if reviewedAt := record.GetDateTime("reviewed_at"); !reviewedAt.IsZero() {
+		reviewedTime := reviewedAt.Time()
+		report.ReviewedAt = &reviewedTime
+	}
+
+	return report
+}
@@ -0,0 +1,668 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+type OrganizationHandler struct {
+	app *pocketbase.PocketBase
+}
+
+func NewOrganizationHandler(app *pocketbase.PocketBase) *OrganizationHandler {
+	return &OrganizationHandler{
+		app: app,
+	}
+}
+
+// CreateOrganization creates a new organization owned by the authenticated user
+//
+//	@Summary		Create organization
+//	@Description	Creates an organization; the authenticated user becomes its owner
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.CreateOrganizationRequest	true	"Organization data"
+//	@Success		201		{object}	models.SuccessResponse				"Organization created successfully"
+//	@Failure		400		{object}	models.ErrorResponse				"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse				"Authentication required"
+//	@Failure		409		{object}	models.ErrorResponse				"Slug already taken"
+//	@Router			/orgs [post]
+func (h *OrganizationHandler) CreateOrganization(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.CreateOrganizationRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	if _, err := requestDao(h.app, c).FindFirstRecordByFilter(constants.CollectionOrganizations, "slug = {:slug}",
+		dbx.Params{"slug": data.Slug}); err == nil {
+		return apis.NewApiError(http.StatusConflict, "Organization slug is already taken", nil)
+	}
+
+	var organization *models.Record
+	err := requestDao(h.app, c).RunInTransaction(func(txDao *daos.Dao) error {
+		collection, err := txDao.FindCollectionByNameOrId(constants.CollectionOrganizations)
+		if err != nil {
+			return err
+		}
+
+		organization = models.NewRecord(collection)
+		organization.Set("name", data.Name)
+		organization.Set("slug", data.Slug)
+		organization.Set("description", data.Description)
+		organization.Set("created_by", record.Id)
+		if err := txDao.SaveRecord(organization); err != nil {
+			return err
+		}
+
+		return saveOrganizationMember(txDao, organization.Id, record.Id, constants.OrgRoleOwner)
+	})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to create organization", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusCreated, recordToOrganization(organization, constants.OrgRoleOwner), "Organization created successfully")
+}
+
+// ListOrganizations lists the organizations the authenticated user belongs to
+//
+//	@Summary		List my organizations
+//	@Description	Returns the organizations the authenticated user is a member of, with their role
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	models.SuccessResponse	"Organizations retrieved successfully"
+//	@Failure		401	{object}	models.ErrorResponse	"Authentication required"
+//	@Router			/orgs [get]
+func (h *OrganizationHandler) ListOrganizations(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	memberships, err := requestDao(h.app, c).FindRecordsByFilter(constants.CollectionOrganizationMembers,
+		"user = {:user}", "created", 0, 0, dbx.Params{"user": record.Id})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch organizations", err)
+	}
+
+	organizations := make([]appmodels.Organization, 0, len(memberships))
+	for _, membership := range memberships {
+		organization, err := requestDao(h.app, c).FindRecordById(constants.CollectionOrganizations, membership.GetString("organization"))
+		if err != nil {
+			continue
+		}
+		organizations = append(organizations, recordToOrganization(organization, membership.GetString("role")))
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, organizations, "")
+}
+
+// GetOrganization returns an organization
+//
+//	@Summary		Get organization
+//	@Description	Returns an organization the caller belongs to
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			org	path		string	true	"Organization slug"
+//	@Success		200	{object}	models.SuccessResponse	"Organization retrieved successfully"
+//	@Failure		401	{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		403	{object}	models.ErrorResponse	"Insufficient organization role"
+//	@Failure		404	{object}	models.ErrorResponse	"Organization not found"
+//	@Router			/orgs/{org} [get]
+func (h *OrganizationHandler) GetOrganization(c echo.Context) error {
+	organization, exists := middleware.GetOrganization(c)
+	if !exists {
+		return apis.NewNotFoundError("Organization not found", nil)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, recordToOrganization(organization, middleware.GetOrgRole(c)), "")
+}
+
+// ListMembers lists the members of an organization
+//
+//	@Summary		List organization members
+//	@Description	Returns the members of an organization with their roles
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			org	path		string	true	"Organization slug"
+//	@Success		200	{object}	models.SuccessResponse	"Members retrieved successfully"
+//	@Failure		401	{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		403	{object}	models.ErrorResponse	"Insufficient organization role"
+//	@Failure		404	{object}	models.ErrorResponse	"Organization not found"
+//	@Router			/orgs/{org}/members [get]
+func (h *OrganizationHandler) ListMembers(c echo.Context) error {
+	organization, exists := middleware.GetOrganization(c)
+	if !exists {
+		return apis.NewNotFoundError("Organization not found", nil)
+	}
+
+	memberships, err := requestDao(h.app, c).FindRecordsByFilter(constants.CollectionOrganizationMembers,
+		"organization = {:organization}", "created", 0, 0, dbx.Params{"organization": organization.Id})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch members", err)
+	}
+
+	members := make([]appmodels.OrganizationMember, len(memberships))
+	for i, membership := range memberships {
+		members[i] = h.recordToMember(membership)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, members, "")
+}
+
+// AddMember adds a user to an organization
+//
+//	@Summary		Add organization member
+//	@Description	Adds a user to the organization with the given role (admin or owner required, not available to organization API keys; only owners can add owners)
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			org		path		string								true	"Organization slug"
+//	@Param			request	body		models.AddOrganizationMemberRequest	true	"Member data"
+//	@Success		201		{object}	models.SuccessResponse				"Member added successfully"
+//	@Failure		400		{object}	models.ErrorResponse				"Invalid request"
+//	@Failure		403		{object}	models.ErrorResponse				"Insufficient organization role"
+//	@Failure		404		{object}	models.ErrorResponse				"User not found"
+//	@Failure		409		{object}	models.ErrorResponse				"Already a member"
+//	@Router			/orgs/{org}/members [post]
+func (h *OrganizationHandler) AddMember(c echo.Context) error {
+	organization, exists := middleware.GetOrganization(c)
+	if !exists {
+		return apis.NewNotFoundError("Organization not found", nil)
+	}
+	if _, err := orgManager(c); err != nil {
+		return err
+	}
+
+	// Get validated data from middleware
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.AddOrganizationMemberRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	if data.Role == constants.OrgRoleOwner && !middleware.HasOrgRole(middleware.GetOrgRole(c), constants.OrgRoleOwner) {
+		return apis.NewForbiddenError("Only owners can add owners", nil)
+	}
+
+	user, err := findUserByUsername(requestDao(h.app, c), data.Username)
+	if err != nil {
+		return apis.NewNotFoundError("User not found", err)
+	}
+
+	if _, err := findOrganizationMember(requestDao(h.app, c), organization.Id, user.Id); err == nil {
+		return apis.NewApiError(http.StatusConflict, "User is already a member of this organization", nil)
+	}
+
+	if err := saveOrganizationMember(requestDao(h.app, c), organization.Id, user.Id, data.Role); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to add member", err)
+	}
+
+	membership, err := findOrganizationMember(requestDao(h.app, c), organization.Id, user.Id)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to load member", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusCreated, h.recordToMember(membership), "Member added successfully")
+}
+
+// UpdateMember changes the role of an organization member
+//
+//	@Summary		Update organization member
+//	@Description	Changes a member's role (admin or owner required, not available to organization API keys; only owners can grant or revoke ownership)
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			org		path		string									true	"Organization slug"
+//	@Param			userId	path		string									true	"Member user ID"
+//	@Param			request	body		models.UpdateOrganizationMemberRequest	true	"New role"
+//	@Success		200		{object}	models.SuccessResponse					"Member updated successfully"
+//	@Failure		400		{object}	models.ErrorResponse					"Invalid request"
+//	@Failure		403		{object}	models.ErrorResponse					"Insufficient organization role"
+//	@Failure		404		{object}	models.ErrorResponse					"Member not found"
+//	@Router			/orgs/{org}/members/{userId} [put]
+func (h *OrganizationHandler) UpdateMember(c echo.Context) error {
+	organization, exists := middleware.GetOrganization(c)
+	if !exists {
+		return apis.NewNotFoundError("Organization not found", nil)
+	}
+	if _, err := orgManager(c); err != nil {
+		return err
+	}
+
+	// Get validated data from middleware
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.UpdateOrganizationMemberRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	membership, err := findOrganizationMember(requestDao(h.app, c), organization.Id, c.PathParam("userId"))
+	if err != nil {
+		return apis.NewNotFoundError("Member not found", err)
+	}
+
+	if err := h.checkMemberChange(c, organization.Id, membership, data.Role); err != nil {
+		return err
+	}
+
+	membership.Set("role", data.Role)
+	if err := requestDao(h.app, c).SaveRecord(membership); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to update member", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, h.recordToMember(membership), "Member updated successfully")
+}
+
+// RemoveMember removes a user from an organization
+//
+//	@Summary		Remove organization member
+//	@Description	Removes a member from the organization (admin or owner required, not available to organization API keys; only owners can remove owners)
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			org		path		string	true	"Organization slug"
+//	@Param			userId	path		string	true	"Member user ID"
+//	@Success		200		{object}	models.SuccessResponse	"Member removed successfully"
+//	@Failure		400		{object}	models.ErrorResponse	"Cannot remove the last owner"
+//	@Failure		403		{object}	models.ErrorResponse	"Insufficient organization role"
+//	@Failure		404		{object}	models.ErrorResponse	"Member not found"
+//	@Router			/orgs/{org}/members/{userId} [delete]
+func (h *OrganizationHandler) RemoveMember(c echo.Context) error {
+	organization, exists := middleware.GetOrganization(c)
+	if !exists {
+		return apis.NewNotFoundError("Organization not found", nil)
+	}
+	if _, err := orgManager(c); err != nil {
+		return err
+	}
+
+	membership, err := findOrganizationMember(requestDao(h.app, c), organization.Id, c.PathParam("userId"))
+	if err != nil {
+		return apis.NewNotFoundError("Member not found", err)
+	}
+
+	if err := h.checkMemberChange(c, organization.Id, membership, ""); err != nil {
+		return err
+	}
+
+	if err := requestDao(h.app, c).DeleteRecord(membership); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to remove member", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Member removed successfully")
+}
+
+// ListAPIKeys lists the API keys of an organization
+//
+//	@Summary		List organization API keys
+//	@Description	Returns the organization's API keys without their secret values (admin or owner required, not available to organization API keys)
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			org	path		string	true	"Organization slug"
+//	@Success		200	{object}	models.SuccessResponse	"API keys retrieved successfully"
+//	@Failure		403	{object}	models.ErrorResponse	"Insufficient organization role"
+//	@Failure		404	{object}	models.ErrorResponse	"Organization not found"
+//	@Router			/orgs/{org}/api-keys [get]
+func (h *OrganizationHandler) ListAPIKeys(c echo.Context) error {
+	organization, exists := middleware.GetOrganization(c)
+	if !exists {
+		return apis.NewNotFoundError("Organization not found", nil)
+	}
+	if _, err := orgManager(c); err != nil {
+		return err
+	}
+
+	records, err := requestDao(h.app, c).FindRecordsByFilter(constants.CollectionOrganizationAPIKeys,
+		"organization = {:organization}", "-created", 0, 0, dbx.Params{"organization": organization.Id})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch API keys", err)
+	}
+
+	keys := make([]appmodels.OrganizationAPIKey, len(records))
+	for i, record := range records {
+		keys[i] = recordToOrganizationAPIKey(record)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, keys, "")
+}
+
+// CreateAPIKey creates an organization API key
+//
+//	@Summary		Create organization API key
+//	@Description	Creates an API key scoped to the organization; the key is only returned once (admin or owner required, not available to organization API keys)
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			org		path		string									true	"Organization slug"
+//	@Param			request	body		models.CreateOrganizationAPIKeyRequest	true	"API key data"
+//	@Success		201		{object}	models.SuccessResponse					"API key created successfully"
+//	@Failure		400		{object}	models.ErrorResponse					"Invalid request"
+//	@Failure		403		{object}	models.ErrorResponse					"Insufficient organization role"
+//	@Router			/orgs/{org}/api-keys [post]
+func (h *OrganizationHandler) CreateAPIKey(c echo.Context) error {
+	organization, exists := middleware.GetOrganization(c)
+	if !exists {
+		return apis.NewNotFoundError("Organization not found", nil)
+	}
+	authUser, err := orgManager(c)
+	if err != nil {
+		return err
+	}
+
+	// Get validated data from middleware
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.CreateOrganizationAPIKeyRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	collection, err := requestDao(h.app, c).FindCollectionByNameOrId(constants.CollectionOrganizationAPIKeys)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "API keys collection not found", err)
+	}
+
+	key := constants.OrgAPIKeyPrefix + security.RandomString(constants.OrgAPIKeyLength)
+
+	record := models.NewRecord(collection)
+	record.Set("organization", organization.Id)
+	record.Set("name", data.Name)
+	record.Set("role", data.Role)
+	record.Set("key_hash", utils.HashToken(key))
+	record.Set("key_prefix", key[:len(constants.OrgAPIKeyPrefix)+6])
+	record.Set("created_by", authUser.Id)
+
+	if err := requestDao(h.app, c).SaveRecord(record); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to create API key", err)
+	}
+
+	apiKey := recordToOrganizationAPIKey(record)
+	apiKey.Key = key
+
+	return utils.SendSuccess(c, http.StatusCreated, apiKey, "API key created successfully")
+}
+
+// RevokeAPIKey deletes an organization API key
+//
+//	@Summary		Revoke organization API key
+//	@Description	Revokes an organization API key (admin or owner required, not available to organization API keys)
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			org		path		string	true	"Organization slug"
+//	@Param			keyId	path		string	true	"API key ID"
+//	@Success		200		{object}	models.SuccessResponse	"API key revoked successfully"
+//	@Failure		403		{object}	models.ErrorResponse	"Insufficient organization role"
+//	@Failure		404		{object}	models.ErrorResponse	"API key not found"
+//	@Router			/orgs/{org}/api-keys/{keyId} [delete]
+func (h *OrganizationHandler) RevokeAPIKey(c echo.Context) error {
+	organization, exists := middleware.GetOrganization(c)
+	if !exists {
+		return apis.NewNotFoundError("Organization not found", nil)
+	}
+	if _, err := orgManager(c); err != nil {
+		return err
+	}
+
+	record, err := requestDao(h.app, c).FindRecordById(constants.CollectionOrganizationAPIKeys, c.PathParam("keyId"))
+	if err != nil || record.GetString("organization") != organization.Id {
+		return apis.NewNotFoundError("API key not found", err)
+	}
+
+	if err := requestDao(h.app, c).DeleteRecord(record); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to revoke API key", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "API key revoked successfully")
+}
+
+// ListOrganizationSessions lists the sessions run by an organization
+//
+//	@Summary		List organization sessions
+//	@Description	Returns the sessions (events) run by the organization, including private ones
+//	@Tags			Organizations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			org	path		string	true	"Organization slug"
+//	@Success		200	{object}	models.SuccessResponse	"Sessions retrieved successfully"
+//	@Failure		403	{object}	models.ErrorResponse	"Insufficient organization role"
+//	@Failure		404	{object}	models.ErrorResponse	"Organization not found"
+//	@Router			/orgs/{org}/sessions [get]
+func (h *OrganizationHandler) ListOrganizationSessions(c echo.Context) error {
+	organization, exists := middleware.GetOrganization(c)
+	if !exists {
+		return apis.NewNotFoundError("Organization not found", nil)
+	}
+
+	records, err := requestDao(h.app, c).FindRecordsByFilter(constants.CollectionSessions,
+		"organization = {:organization}", "-updated", 0, 0, dbx.Params{"organization": organization.Id})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch sessions", err)
+	}
+
+	sessions := make([]map[string]any, len(records))
+	for i, session := range records {
+		sessions[i] = h.sessionToResponse(session)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, sessions, "")
+}
+
+// CreateOrganizationSession creates a session run by the organization for one of its members
+//
+//	@Summary		Create organization session
+//	@Description	Creates a session owned by the organization and recorded by the given member's tracker (admin or owner required)
+//	@Tags			Organizations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			org		path		string									true	"Organization slug"
+//	@Param			request	body		models.CreateOrganizationSessionRequest	true	"Session data"
+//	@Success		201		{object}	models.SuccessResponse					"Session created successfully"
+//	@Failure		400		{object}	models.ErrorResponse					"Invalid request"
+//	@Failure		403		{object}	models.ErrorResponse					"Insufficient organization role"
+//	@Failure		404		{object}	models.ErrorResponse					"Member not found"
+//	@Router			/orgs/{org}/sessions [post]
+func (h *OrganizationHandler) CreateOrganizationSession(c echo.Context) error {
+	organization, exists := middleware.GetOrganization(c)
+	if !exists {
+		return apis.NewNotFoundError("Organization not found", nil)
+	}
+
+	// Get validated data from middleware
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.CreateOrganizationSessionRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	user, err := findUserByUsername(requestDao(h.app, c), data.Username)
+	if err != nil {
+		return apis.NewNotFoundError("Member not found", err)
+	}
+	if _, err := findOrganizationMember(requestDao(h.app, c), organization.Id, user.Id); err != nil {
+		return apis.NewNotFoundError("Member not found", err)
+	}
+
+	if existingSession, _ := findSessionByNameAndUser(requestDao(h.app, c), data.Name, user.Id); existingSession != nil {
+		return apis.NewBadRequestError("Session with this name already exists", nil)
+	}
+
+	sessionsCollection, err := requestDao(h.app, c).FindCollectionByNameOrId(constants.CollectionSessions)
+	if err != nil {
+		return apis.NewNotFoundError("sessions collection not found", err)
+	}
+
+	title := data.Title
+	if title == "" {
+		title = GenerateSessionTitle(data.Name)
+	}
+
+	session := models.NewRecord(sessionsCollection)
+	session.Set("name", data.Name)
+	session.Set("user", user.Id)
+	session.Set("organization", organization.Id)
+	session.Set("title", title)
+	session.Set("description", data.Description)
+	session.Set("public", data.Public)
+	session.Set("share_token", security.RandomString(32))
+
+	if err := requestDao(h.app, c).SaveRecord(session); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to create session", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusCreated, h.sessionToResponse(session), "Session created successfully")
+}
+
+// orgManager returns the signed-in user managing the members or API keys of an organization. API
+// keys cannot manage them, so a leaked key cannot create keys or members that outlive its revocation.
+func orgManager(c echo.Context) (*models.Record, error) {
+	if _, isAPIKey := c.Get(middleware.OrgAPIKeyContextKey).(*models.Record); isAPIKey {
+		return nil, apis.NewForbiddenError("Organization API keys cannot manage members or API keys", nil)
+	}
+	authUser, exists := GetAuthUser(c)
+	if !exists {
+		return nil, apis.NewUnauthorizedError("Authentication required", nil)
+	}
+	return authUser, nil
+}
+
+// checkMemberChange validates a role change (or removal when newRole is empty) against the caller's role
+func (h *OrganizationHandler) checkMemberChange(c echo.Context, organizationID string, membership *models.Record, newRole string) error {
+	currentRole := membership.GetString("role")
+	if (currentRole == constants.OrgRoleOwner || newRole == constants.OrgRoleOwner) &&
+		!middleware.HasOrgRole(middleware.GetOrgRole(c), constants.OrgRoleOwner) {
+		return apis.NewForbiddenError("Only owners can change ownership", nil)
+	}
+
+	// An organization must always keep at least one owner
+	if currentRole == constants.OrgRoleOwner && newRole != constants.OrgRoleOwner {
+		owners, err := requestDao(h.app, c).FindRecordsByFilter(constants.CollectionOrganizationMembers,
+			"organization = {:organization} && role = {:role}", "", 2, 0,
+			dbx.Params{"organization": organizationID, "role": constants.OrgRoleOwner})
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to check organization owners", err)
+		}
+		if len(owners) < 2 {
+			return apis.NewBadRequestError("An organization must keep at least one owner", nil)
+		}
+	}
+
+	return nil
+}
+
+func (h *OrganizationHandler) recordToMember(membership *models.Record) appmodels.OrganizationMember {
+	member := appmodels.OrganizationMember{
+		ID:      membership.Id,
+		UserID:  membership.GetString("user"),
+		Role:    membership.GetString("role"),
+		Created: membership.GetDateTime("created").Time(),
+	}
+
+	if user, err := h.app.Dao().FindRecordById(constants.CollectionUsers, member.UserID); err == nil {
+		member.Username = user.Username()
+	}
+
+	return member
+}
+
+func (h *OrganizationHandler) sessionToResponse(session *models.Record) map[string]any {
+	sessionData := map[string]any{
+		"id":               session.Id,
+		"name":             session.GetString("name"),
+		"title":            session.GetString("title"),
+		"description":      session.GetString("description"),
+		"description_html": utils.RenderMarkdown(session.GetString("description")),
+		"public":           session.GetBool("public"),
+		"activity":         session.GetString("activity"),
+		"share_token":      session.GetString("share_token"),
+		"organization":     session.GetString("organization"),
+		"user":             session.GetString("user"),
+		"created":          session.GetDateTime("created").Time().Format(time.RFC3339),
+		"updated":          session.GetDateTime("updated").Time().Format(time.RFC3339),
+	}
+
+	if user, err := h.app.Dao().FindRecordById(constants.CollectionUsers, session.GetString("user")); err == nil {
+		sessionData["username"] = user.Username()
+	}
+
+	return sessionData
+}
+
+// findOrganizationMember finds the membership of a user in an organization
+func findOrganizationMember(dao *daos.Dao, organizationID, userID string) (*models.Record, error) {
+	return dao.FindFirstRecordByFilter(constants.CollectionOrganizationMembers,
+		"organization = {:organization} && user = {:user}",
+		dbx.Params{"organization": organizationID, "user": userID})
+}
+
+// saveOrganizationMember creates a membership record with the given role
+func saveOrganizationMember(dao *daos.Dao, organizationID, userID, role string) error {
+	collection, err := dao.FindCollectionByNameOrId(constants.CollectionOrganizationMembers)
+	if err != nil {
+		return err
+	}
+
+	membership := models.NewRecord(collection)
+	membership.Set("organization", organizationID)
+	membership.Set("user", userID)
+	membership.Set("role", role)
+	return dao.SaveRecord(membership)
+}
+
+func recordToOrganization(record *models.Record, role string) appmodels.Organization {
+	return appmodels.Organization{
+		ID:          record.Id,
+		Name:        record.GetString("name"),
+		Slug:        record.GetString("slug"),
+		Description: record.GetString("description"),
+		Role:        role,
+		Created:     record.GetDateTime("created").Time(),
+		Updated:     record.GetDateTime("updated").Time(),
+	}
+}
+
+func recordToOrganizationAPIKey(record *models.Record) appmodels.OrganizationAPIKey {
+	apiKey := appmodels.OrganizationAPIKey{
+		ID:        record.Id,
+		Name:      record.GetString("name"),
+		Role:      record.GetString("role"),
+		KeyPrefix: record.GetString("key_prefix"),
+		Created:   record.GetDateTime("created").Time(),
+	}
+
+	if lastUsed := record.GetDateTime("last_used_at"); !lastUsed.IsZero() {
+		t := lastUsed.Time()
+		apiKey.LastUsedAt = &t
+	}
+
+	return apiKey
+}
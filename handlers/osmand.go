@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// TrackOsmAndLocation tracks a position sent with the osmAnd/Traccar HTTP protocol
+//
+//	@Summary		Track location (osmAnd/Traccar)
+//	@Description	Tracks a position sent with the osmAnd protocol of Traccar, as the Traccar Client apps, the OsmAnd online tracking plugin and many GPS trackers send it, as GET or POST query parameters. Devices that can only be configured with a server URL and a device ID use the tracking token as the id; otherwise id is stored as the device and the request is authenticated like /api/track. The speed is in knots unless speed_unit is set; an alarm is tracked as an alarm event.
+//	@Tags			Tracking
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		TokenAuth
+//	@Param			id					query		string	false	"Device ID, or the tracking token"
+//	@Param			lat					query		number	false	"Latitude"
+//	@Param			lon					query		number	false	"Longitude"
+//	@Param			location			query		string	false	"Position as latitude,longitude, instead of lat and lon"
+//	@Param			timestamp			query		string	false	"Unix seconds or milliseconds, or a UTC date"
+//	@Param			altitude			query		number	false	"Altitude in meters"
+//	@Param			speed				query		number	false	"Speed"
+//	@Param			speed_unit			query		string	false	"Unit of the speed (default: knots)"
+//	@Param			accuracy			query		number	false	"Accuracy in meters"
+//	@Param			batt				query		number	false	"Battery level in percent"
+//	@Param			alarm				query		string	false	"Alarm reported by the device"
+//	@Param			session				query		string	false	"Session name"
+//	@Param			allow_historical	query		bool	false	"Accept timestamps older than the configured maximum age"
+//	@Success		200					{object}	models.SuccessResponse	"Location tracked successfully"
+//	@Failure		400					{object}	models.ErrorResponse	"Invalid request"
+//	@Failure		401					{object}	models.ErrorResponse	"Authentication required"
+//	@Router			/integrations/osmand [get]
+func (h *TrackingHandler) TrackOsmAndLocation(c echo.Context) error {
+	user, exists := GetAuthUser(c)
+	if !exists {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	query, ok := middleware.GetValidatedQuery(c).(*appmodels.OsmAndQueryParams)
+	if !ok {
+		return apis.NewBadRequestError("Invalid query parameters", nil)
+	}
+
+	device := query.ID
+	if middleware.AuthenticatedByDeviceID(c) {
+		device = "" // The ID is the tracking token
+	}
+	params, err := utils.OsmAndLocation(query, device)
+	if err != nil {
+		return apis.NewBadRequestError("Validation failed", err)
+	}
+	if err := utils.ValidateStruct(params); err != nil {
+		return apis.NewBadRequestError("Validation failed", err)
+	}
+	if err := h.checkTimestamp(c, params.Timestamp); err != nil {
+		return err
+	}
+
+	record, err := h.trackPoint(c.Request().Context(), user, params)
+	if err != nil {
+		return err
+	}
+
+	return sendTrackedPoint(c, record)
+}
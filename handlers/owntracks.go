@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// TrackOwnTracksLocation tracks a location posted by the OwnTracks apps in HTTP mode
+//
+//	@Summary		Track location (OwnTracks)
+//	@Description	Accepts the messages the OwnTracks apps post in HTTP mode, so they can report to the tracker without a translation proxy. Location messages are tracked, other messages such as transitions and waypoints are acknowledged and ignored. The tracking token is sent as the token query parameter, the Authorization header or the password of HTTP basic authentication. Points go to the session query parameter, else to a session named after the device (the X-Limit-D header, the last level of the topic or the tracker ID). The response is the empty JSON array the apps expect.
+//	@Tags			Tracking
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		TokenAuth
+//	@Param			request				body		models.OwnTracksMessage	true	"OwnTracks message"
+//	@Param			session				query		string					false	"Session name"
+//	@Param			allow_historical	query		bool					false	"Accept timestamps older than the configured maximum age"
+//	@Success		200					{array}		object					"Message accepted"
+//	@Failure		400					{object}	models.ErrorResponse	"Invalid or encrypted message"
+//	@Failure		401					{object}	models.ErrorResponse	"Authentication required"
+//	@Router			/integrations/owntracks [post]
+func (h *TrackingHandler) TrackOwnTracksLocation(c echo.Context) error {
+	user, exists := GetAuthUser(c)
+	if !exists {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	message, ok := middleware.GetValidatedData(c).(*appmodels.OwnTracksMessage)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	switch message.Type {
+	case constants.OwnTracksTypeLocation:
+	case constants.OwnTracksTypeEncrypted:
+		return apis.NewBadRequestError("Encrypted OwnTracks payloads are not supported, disable encryption in the app", nil)
+	default:
+		return c.JSON(http.StatusOK, []any{}) // The apps keep resending messages that fail
+	}
+
+	device := utils.OwnTracksDevice(c.Request().Header.Get(constants.OwnTracksDeviceHeader), message)
+	params := utils.OwnTracksLocation(message, device, c.QueryParam("session"))
+	if err := utils.ValidateStruct(params); err != nil {
+		return apis.NewBadRequestError("Validation failed", err)
+	}
+	if err := h.checkTimestamp(c, params.Timestamp); err != nil {
+		return err
+	}
+
+	if _, err := h.trackPoint(c.Request().Context(), user, params); err != nil {
+		return err
+	}
+
+	// The apps read friends' locations and commands from the response, there are none
+	return c.JSON(http.StatusOK, []any{})
+}
@@ -1,8 +1,14 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v5"
@@ -10,8 +16,13 @@ import (
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/types"
 
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
 	"vibe-tracker/services"
 	"vibe-tracker/utils"
 )
@@ -20,20 +31,27 @@ type PublicHandler struct {
 	app             *pocketbase.PocketBase
 	locationService *services.LocationService
 	userService     *services.UserService
+	config          *config.TrackingConfig
 }
 
-func NewPublicHandler(app *pocketbase.PocketBase, locationService *services.LocationService, userService *services.UserService) *PublicHandler {
+func NewPublicHandler(app *pocketbase.PocketBase, locationService *services.LocationService, userService *services.UserService, trackingConfig *config.TrackingConfig) *PublicHandler {
 	return &PublicHandler{
 		app:             app,
 		locationService: locationService,
 		userService:     userService,
+		config:          trackingConfig,
 	}
 }
 
+// gapThresholds returns the configured limits for splitting tracks into segments
+func (h *PublicHandler) gapThresholds() utils.GapThresholds {
+	return utils.GapThresholds{MaxInterval: h.config.GapMaxInterval, MaxDistance: h.config.GapMaxDistance}
+}
+
 // GetLocation retrieves the latest location for a user
 //
 //	@Summary		Get user location
-//	@Description	Returns the latest location data for the specified user
+//	@Description	Returns the current location of the specified user, chosen among recent fixes by the user's location policy (newest, most_accurate or per_device)
 //	@Tags			Public
 //	@Produce		json
 //	@Param			username	path		string	true	"Username"
@@ -55,15 +73,16 @@ func (h *PublicHandler) GetLocation(c echo.Context) error {
 
 	session := c.QueryParam("session")
 	if session != "" {
-		filter += " && session = {:session}"
+		filter += " && session.name = {:session}"
 		params["session"] = session
 	}
 
-	records, _ := h.app.Dao().FindRecordsByFilter(
+	// Recent fixes let the user's location policy prefer an accurate fix over the newest one
+	records, _ := requestDao(h.app, c).FindRecordsByFilter(
 		"locations",
 		filter,
-		"-created",
-		1,
+		"-timestamp", // Historical imports are created after newer points
+		h.locationService.PositionCandidateLimit(user),
 		0,
 		params,
 	)
@@ -72,42 +91,53 @@ func (h *PublicHandler) GetLocation(c echo.Context) error {
 		return apis.NewNotFoundError("No location found for this user", nil)
 	}
 
-	latestRecord := records[0]
+	latestRecord := h.locationService.SelectCurrentPosition(user, records)
 
 	// Construct GeoJSON response
 	timestamp := latestRecord.GetDateTime("timestamp").Time()
 
 	// Get session metadata if available
-	sessionName := latestRecord.GetString("session")
-	sessionTitle := sessionName // fallback to session name
-	if sessionName != "" {
-		if sessionRecord, err := findSessionByNameAndUser(h.app.Dao(), sessionName, user.Id); err == nil && sessionRecord != nil {
-			if title := sessionRecord.GetString("title"); title != "" {
-				sessionTitle = title
-			}
+	var sessionName, sessionTitle string
+	expandLocationSessions(requestDao(h.app, c), latestRecord)
+	if sessionRecord := latestRecord.ExpandedOne("session"); sessionRecord != nil {
+		sessionName = sessionRecord.GetString("name")
+		sessionTitle = sessionName // fallback to session name
+		if title := sessionRecord.GetString("title"); title != "" {
+			sessionTitle = title
 		}
 	}
 
+	properties := map[string]any{
+		"timestamp":     timestamp.Unix(),
+		"speed":         latestRecord.GetFloat("speed"),
+		"heart_rate":    latestRecord.GetFloat("heart_rate"),
+		"session":       sessionName,
+		"session_title": sessionTitle,
+		"username":      user.Username(),
+		"user_id":       user.Id,
+		"avatar":        user.GetString("avatar"),
+	}
+	if accuracy := latestRecord.GetFloat("accuracy"); accuracy > 0 {
+		properties["accuracy"] = accuracy
+	}
+	if verticalAccuracy := latestRecord.GetFloat("vertical_accuracy"); verticalAccuracy > 0 {
+		properties["vertical_accuracy"] = verticalAccuracy
+	}
+	if bearing := utils.RecordBearing(latestRecord); bearing != nil {
+		properties["bearing"] = *bearing
+	}
+	if device := latestRecord.GetString("device"); device != "" {
+		properties["device"] = device
+	}
+	utils.AddSensorProperties(properties, latestRecord)
+
 	response := map[string]any{
 		"type": "Feature",
 		"geometry": map[string]any{
-			"type": "Point",
-			"coordinates": []float64{
-				latestRecord.GetFloat("longitude"),
-				latestRecord.GetFloat("latitude"),
-				latestRecord.GetFloat("altitude"),
-			},
-		},
-		"properties": map[string]any{
-			"timestamp":     timestamp.Unix(),
-			"speed":         latestRecord.GetFloat("speed"),
-			"heart_rate":    latestRecord.GetFloat("heart_rate"),
-			"session":       sessionName,
-			"session_title": sessionTitle,
-			"username":      user.Username(),
-			"user_id":       user.Id,
-			"avatar":        user.GetString("avatar"),
+			"type":        "Point",
+			"coordinates": utils.RecordCoordinates(latestRecord),
 		},
+		"properties": properties,
 		"when": map[string]any{
 			"start": timestamp.Format(time.RFC3339),
 			"type":  "Instant",
@@ -117,31 +147,78 @@ func (h *PublicHandler) GetLocation(c echo.Context) error {
 	return utils.SendGeoJSON(c, http.StatusOK, response, "")
 }
 
+// publicFeedEntry is a user's latest public location in the public locations feed
+type publicFeedEntry struct {
+	feature   map[string]interface{}
+	username  string
+	timestamp time.Time
+	distance  float64 // Meters from the center of the bbox, when one is given
+}
+
 // GetPublicLocations retrieves all public location data
 //
 //	@Summary		Get public locations
-//	@Description	Returns public location data from all users in GeoJSON format
+//	@Description	Returns the latest location of each user's latest public session in GeoJSON format, optionally filtered by session activity, freshness and region
 //	@Tags			Public
 //	@Produce		json
-//	@Param			limit		query		int		false	"Number of locations to return (default: 1000)"
-//	@Param			since		query		string	false	"ISO timestamp to filter locations since"
-//	@Success		200			{object}	models.SuccessResponse	"Public locations retrieved successfully"
-//	@Router			/public-location [get]
+//	@Param			activity		query		string	false	"Comma-separated session activities, e.g. running,walking"
+//	@Param			active_within	query		string	false	"Only users whose latest location is at most this old, e.g. 15m or 2h"
+//	@Param			bbox			query		string	false	"Only locations inside the bounding box minLon,minLat,maxLon,maxLat"
+//	@Param			sort			query		string	false	"Sort order: recent (default), distance from the center of the bbox, or username"
+//	@Success		200				{object}	models.SuccessResponse	"Public locations retrieved successfully"
+//	@Failure		400				{object}	models.ErrorResponse		"Invalid filter"
+//	@Router			/public-locations [get]
 func (h *PublicHandler) GetPublicLocations(c echo.Context) error {
+	params, ok := middleware.GetValidatedQuery(c).(*appmodels.PublicLocationsQueryParams)
+	if !ok {
+		return apis.NewBadRequestError("Invalid query parameters", nil)
+	}
+
+	var activities []string
+	if params.Activity != "" {
+		for _, activity := range strings.Split(params.Activity, ",") {
+			activity = strings.TrimSpace(activity)
+			if !slices.Contains(constants.SessionActivities, activity) {
+				return apis.NewBadRequestError("activity must be one of: "+strings.Join(constants.SessionActivities, ", "), nil)
+			}
+			activities = append(activities, activity)
+		}
+	}
+
+	var activeSince time.Time
+	if params.ActiveWithin != "" {
+		within, err := time.ParseDuration(params.ActiveWithin)
+		if err != nil || within <= 0 {
+			return apis.NewBadRequestError("active_within must be a positive duration, e.g. 15m or 2h", nil)
+		}
+		activeSince = time.Now().Add(-within)
+	}
+
+	var bbox *utils.BoundingBox
+	if params.BBox != "" {
+		var err error
+		if bbox, err = utils.ParseBoundingBox(params.BBox); err != nil {
+			return apis.NewBadRequestError("Invalid bbox parameter", err)
+		}
+	}
+	if params.Sort == constants.PublicFeedSortDistance && bbox == nil {
+		return apis.NewBadRequestError("sort=distance requires a bbox", nil)
+	}
+
 	// Get all users
-	users, err := h.app.Dao().FindRecordsByFilter("users", "id != ''", "", 0, 0, nil)
+	users, err := requestDao(h.app, c).FindRecordsByFilter("users", "id != ''", "", 0, 0, nil)
 	if err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch users", err)
 	}
 
-	var features []interface{}
+	var entries []publicFeedEntry
 
 	// For each user, find their latest public session and location
 	for _, user := range users {
 		// Get latest public session for this user
-		publicSessions, err := h.app.Dao().FindRecordsByFilter(
+		publicSessions, err := requestDao(h.app, c).FindRecordsByFilter(
 			"sessions",
-			"user = {:user} && public = true",
+			"user = {:user} && public = true && hidden = false && draft = false",
 			"-created", // Order by newest first
 			1,          // Limit to 1
 			0,
@@ -154,15 +231,18 @@ func (h *PublicHandler) GetPublicLocations(c echo.Context) error {
 
 		latestPublicSession := publicSessions[0]
 		sessionName := latestPublicSession.GetString("name")
+		if len(activities) > 0 && !slices.Contains(activities, latestPublicSession.GetString("activity")) {
+			continue
+		}
 
 		// Get latest location for this session
-		locations, err := h.app.Dao().FindRecordsByFilter(
+		locations, err := requestDao(h.app, c).FindRecordsByFilter(
 			"locations",
-			"user = {:user} && session = {:session}",
+			"session = {:session}",
 			"-timestamp", // Order by newest first
 			1,            // Limit to 1
 			0,
-			dbx.Params{"user": user.Id, "session": sessionName},
+			dbx.Params{"session": latestPublicSession.Id},
 		)
 
 		if err != nil || len(locations) == 0 {
@@ -171,17 +251,18 @@ func (h *PublicHandler) GetPublicLocations(c echo.Context) error {
 
 		latestLocation := locations[0]
 		timestamp := latestLocation.GetDateTime("timestamp").Time()
+		latitude := latestLocation.GetFloat("latitude")
+		longitude := latestLocation.GetFloat("longitude")
+		if timestamp.Before(activeSince) || (bbox != nil && !bbox.Contains(latitude, longitude)) {
+			continue
+		}
 
 		// Create GeoJSON feature for this user's latest location
 		feature := map[string]interface{}{
 			"type": "Feature",
 			"geometry": map[string]interface{}{
-				"type": "Point",
-				"coordinates": []float64{
-					latestLocation.GetFloat("longitude"),
-					latestLocation.GetFloat("latitude"),
-					latestLocation.GetFloat("altitude"),
-				},
+				"type":        "Point",
+				"coordinates": utils.RecordCoordinates(latestLocation),
 			},
 			"properties": map[string]interface{}{
 				"timestamp":     timestamp.Unix(),
@@ -189,13 +270,33 @@ func (h *PublicHandler) GetPublicLocations(c echo.Context) error {
 				"heart_rate":    latestLocation.GetFloat("heart_rate"),
 				"session":       sessionName,
 				"session_title": latestPublicSession.GetString("title"),
+				"activity":      latestPublicSession.GetString("activity"),
 				"username":      user.Username(),
 				"user_id":       user.Id,
 				"avatar":        user.GetString("avatar"),
 			},
 		}
 
-		features = append(features, feature)
+		entry := publicFeedEntry{feature: feature, username: user.Username(), timestamp: timestamp}
+		if bbox != nil {
+			centerLat, centerLon := bbox.Center()
+			entry.distance = utils.HaversineDistance(centerLat, centerLon, latitude, longitude)
+		}
+		entries = append(entries, entry)
+	}
+
+	switch params.Sort {
+	case constants.PublicFeedSortDistance:
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].distance < entries[j].distance })
+	case constants.PublicFeedSortUsername:
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].username < entries[j].username })
+	default:
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].timestamp.After(entries[j].timestamp) })
+	}
+
+	features := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		features[i] = entry.feature
 	}
 
 	// Return GeoJSON FeatureCollection
@@ -210,12 +311,14 @@ func (h *PublicHandler) GetPublicLocations(c echo.Context) error {
 // GetSessionData retrieves location data for a specific session
 //
 //	@Summary		Get session data
-//	@Description	Returns location data for a specific user session in GeoJSON format
+//	@Description	Returns location data for a specific user session in GeoJSON format. Each point carries a segment index; points of different segments are separated by a time or distance gap and should not be connected
 //	@Tags			Public
 //	@Produce		json
 //	@Param			username	path		string	true	"Username"
 //	@Param			session		path		string	true	"Session name"
+//	@Param			track		query		string	false	"Track variant: raw (default) or matched, positions snapped to roads where the session was map matched"
 //	@Success		200			{object}	models.SuccessResponse	"Session data retrieved successfully"
+//	@Failure		400			{object}	models.ErrorResponse		"Invalid track variant"
 //	@Failure		404			{object}	models.ErrorResponse		"User or session not found"
 //	@Router			/session/{username}/{session} [get]
 func (h *PublicHandler) GetSessionData(c echo.Context) error {
@@ -227,11 +330,16 @@ func (h *PublicHandler) GetSessionData(c echo.Context) error {
 
 	session := c.PathParam("session")
 
+	variant := c.QueryParam(constants.TrackVariantParam)
+	if variant != "" && variant != constants.TrackVariantRaw && variant != constants.TrackVariantMatched {
+		return apis.NewBadRequestError("track must be one of: raw, matched", nil)
+	}
+
 	if session == "_latest" {
 		// Find the most recently created public session for this user
-		latestSessions, err := h.app.Dao().FindRecordsByFilter(
+		latestSessions, err := requestDao(h.app, c).FindRecordsByFilter(
 			"sessions",
-			"user = {:user} && public = true",
+			"user = {:user} && public = true && hidden = false && draft = false",
 			"-created",
 			1,
 			0,
@@ -251,17 +359,10 @@ func (h *PublicHandler) GetSessionData(c echo.Context) error {
 	var sessionRecord *models.Record
 	if session != "" {
 		var err error
-		sessionRecord, err = findSessionByNameAndUser(h.app.Dao(), session, user.Id)
+		sessionRecord, err = findSessionByNameAndUser(requestDao(h.app, c), session, user.Id)
 		if err == nil && sessionRecord != nil {
-			// Check access: allow if public, or if owner, or if valid share_token
-			authRecord, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
-			isOwner := authRecord != nil && authRecord.Id == user.Id
-			isPublic := sessionRecord.GetBool("public")
-			shareToken := c.QueryParam("share_token")
-			storedShareToken := sessionRecord.GetString("share_token")
-			hasValidShareToken := shareToken != "" && storedShareToken != "" && shareToken == storedShareToken
-
-			if !isPublic && !isOwner && !hasValidShareToken {
+			// Check access: allow if public, readable by the requester, or if valid share_token
+			if !hasSessionAccess(c, sessionRecord) {
 				return apis.NewForbiddenError("Access denied", nil)
 			}
 
@@ -276,20 +377,30 @@ func (h *PublicHandler) GetSessionData(c echo.Context) error {
 
 			// Build complete session metadata
 			sessionMetadata = map[string]any{
-				"id":          sessionRecord.Id,
-				"name":        sessionRecord.GetString("name"),
-				"title":       sessionRecord.GetString("title"),
-				"description": sessionRecord.GetString("description"),
-				"public":      sessionRecord.GetBool("public"),
-				"created":     sessionRecord.GetDateTime("created").Time().Format(time.RFC3339),
-				"updated":     sessionRecord.GetDateTime("updated").Time().Format(time.RFC3339),
+				"id":               sessionRecord.Id,
+				"name":             sessionRecord.GetString("name"),
+				"title":            sessionRecord.GetString("title"),
+				"description":      sessionRecord.GetString("description"),
+				"description_html": utils.RenderMarkdown(sessionRecord.GetString("description")),
+				"public":           sessionRecord.GetBool("public"),
+				"created":          sessionRecord.GetDateTime("created").Time().Format(time.RFC3339),
+				"updated":          sessionRecord.GetDateTime("updated").Time().Format(time.RFC3339),
+
+				"map_matched_at": formatOptionalDate(sessionRecord, "map_matched_at"),
+			}
+			if coverURL, ok := sessionCoverURLs(h.app, requestDao(h.app, c), sessionRecord.Id)[sessionRecord.Id]; ok {
+				sessionMetadata["cover_url"] = coverURL
 			}
 		}
 	}
 
+	if sessionRecord == nil {
+		return apis.NewNotFoundError("Session not found", nil)
+	}
+
 	// Build filter and params
-	filter := "user = {:user} && session = {:session}"
-	params := dbx.Params{"user": user.Id, "session": session}
+	filter := "session = {:session}"
+	params := dbx.Params{"session": sessionRecordId}
 
 	// Add since parameter if provided for delta tracking
 	since := c.QueryParam("since")
@@ -302,7 +413,7 @@ func (h *PublicHandler) GetSessionData(c echo.Context) error {
 		}
 	}
 
-	records, err := h.app.Dao().FindRecordsByFilter(
+	records, err := requestDao(h.app, c).FindRecordsByFilter(
 		"locations",
 		filter,
 		"timestamp", // Order by timestamp to ensure correct LineString order
@@ -319,19 +430,30 @@ func (h *PublicHandler) GetSessionData(c echo.Context) error {
 		return apis.NewNotFoundError("No locations found for this session", nil)
 	}
 
+	// Clients draw a separate line per segment instead of bridging gaps
+	segments, _ := utils.SegmentTrack(locationsToTimedPoints(records), h.gapThresholds())
+
 	features := make([]interface{}, len(records))
 	for i, record := range records {
-		pointCoordinates := []float64{
-			record.GetFloat("longitude"),
-			record.GetFloat("latitude"),
-			record.GetFloat("altitude"),
-		}
+		pointCoordinates := utils.RecordCoordinates(record)
 		pointProperties := map[string]interface{}{
 			"timestamp":     record.GetDateTime("timestamp").Time().Unix(),
 			"speed":         record.GetFloat("speed"),
 			"heart_rate":    record.GetFloat("heart_rate"),
-			"session":       record.GetString("session"),
+			"session":       session,
 			"session_title": sessionTitle,
+			"segment":       segments[i],
+		}
+		utils.AddSensorProperties(pointProperties, record)
+
+		// Snapped positions replace the recorded ones, locations that were not matched stay raw
+		if variant == constants.TrackVariantMatched {
+			latitude, longitude := record.GetFloat("matched_latitude"), record.GetFloat("matched_longitude")
+			matched := latitude != 0 || longitude != 0
+			if matched {
+				pointCoordinates[0], pointCoordinates[1] = longitude, latitude
+			}
+			pointProperties["matched"] = matched
 		}
 
 		// Add status and event if available
@@ -375,15 +497,20 @@ func (h *PublicHandler) GetSessionData(c echo.Context) error {
 	if gpxTrackFile != "" || trackName != "" || trackDescription != "" || sessionRecordId != "" {
 		gpxData := map[string]interface{}{
 			"track_file":        gpxTrackFile,
+			"track_file_url":    "",
 			"track_name":        trackName,
 			"track_description": trackDescription,
 		}
 
+		if sessionRecord != nil {
+			gpxData["track_file_url"] = signedFileURL(h.app, sessionRecord, "gpx_track")
+		}
+
 		// Fetch GPX track points if session ID is available
 		if sessionRecordId != "" {
-			gpxRecords, err := h.app.Dao().FindRecordsByFilter(
+			gpxRecords, err := requestDao(h.app, c).FindRecordsByFilter(
 				"gpx_tracks",
-				"session_id = {:sessionId}",
+				"session_id = {:sessionId} && simplified = true",
 				"sequence", // Order by sequence to maintain track point order
 				0,          // No limit
 				0,          // No offset
@@ -393,13 +520,11 @@ func (h *PublicHandler) GetSessionData(c echo.Context) error {
 			if err == nil && len(gpxRecords) > 0 {
 				gpxFeatures := make([]interface{}, len(gpxRecords))
 				for i, record := range gpxRecords {
-					pointCoordinates := []float64{
-						record.GetFloat("longitude"),
-						record.GetFloat("latitude"),
-						record.GetFloat("altitude"),
-					}
+					pointCoordinates := utils.RecordCoordinates(record)
 					pointProperties := map[string]interface{}{
 						"sequence": record.GetFloat("sequence"),
+						"track":    record.GetInt("track"),
+						"segment":  record.GetInt("segment"),
 					}
 
 					gpxFeature := map[string]interface{}{
@@ -417,6 +542,7 @@ func (h *PublicHandler) GetSessionData(c echo.Context) error {
 					"type":     "FeatureCollection",
 					"features": gpxFeatures,
 				}
+				gpxData["tracks"] = summarizePlannedTracks(sessionRecord, gpxRecords)
 			}
 		}
 
@@ -425,49 +551,19 @@ func (h *PublicHandler) GetSessionData(c echo.Context) error {
 
 	// Fetch waypoints if session ID is available
 	if sessionRecordId != "" {
-		waypointRecords, err := h.app.Dao().FindRecordsByFilter(
+		waypointRecords, err := requestDao(h.app, c).FindRecordsByFilter(
 			"waypoints",
 			"session_id = {:sessionId}",
-			"-created", // Order by newest first
-			0,          // No limit
-			0,          // No offset
+			constants.WaypointOrderSort, // Order by explicit waypoint order
+			0,                           // No limit
+			0,                           // No offset
 			dbx.Params{"sessionId": sessionRecordId},
 		)
 
 		if err == nil && len(waypointRecords) > 0 {
 			waypointFeatures := make([]map[string]any, len(waypointRecords))
 			for i, waypoint := range waypointRecords {
-				waypointFeatures[i] = map[string]any{
-					"type": "Feature",
-					"id":   waypoint.Id,
-					"geometry": map[string]any{
-						"type": "Point",
-						"coordinates": []float64{
-							waypoint.GetFloat("longitude"),
-							waypoint.GetFloat("latitude"),
-						},
-					},
-					"properties": map[string]any{
-						"id":                  waypoint.Id,
-						"name":                waypoint.GetString("name"),
-						"type":                waypoint.GetString("type"),
-						"description":         waypoint.GetString("description"),
-						"session_id":          waypoint.GetString("session_id"),
-						"source":              waypoint.GetString("source"),
-						"position_confidence": waypoint.GetString("position_confidence"),
-						"created":             waypoint.GetDateTime("created").Time().Format(time.RFC3339),
-						"updated":             waypoint.GetDateTime("updated").Time().Format(time.RFC3339),
-					},
-				}
-
-				// Add optional fields
-				if altitude := waypoint.GetFloat("altitude"); altitude != 0 {
-					waypointFeatures[i]["properties"].(map[string]any)["altitude"] = altitude
-				}
-
-				if photo := waypoint.GetString("photo"); photo != "" {
-					waypointFeatures[i]["properties"].(map[string]any)["photo"] = photo
-				}
+				waypointFeatures[i] = waypointToFeature(h.app, waypoint)
 			}
 
 			// Add waypoints to response as GeoJSON FeatureCollection
@@ -480,3 +576,383 @@ func (h *PublicHandler) GetSessionData(c echo.Context) error {
 
 	return utils.SendGeoJSON(c, http.StatusOK, response, "")
 }
+
+// GetSessionColoring returns per-segment metric values for coloring a session track
+//
+//	@Summary		Get session track coloring
+//	@Description	Returns per-segment values of the chosen metric normalized to 0..1, with min/max bounds and a suggested color ramp
+//	@Tags			Public
+//	@Produce		json
+//	@Param			username	path		string	true	"Username"
+//	@Param			session		path		string	true	"Session name"
+//	@Param			metric		query		string	true	"Metric to color by (speed, heart_rate, elevation)"
+//	@Param			share_token	query		string	false	"Share token for private sessions"
+//	@Param			guest_token	query		string	false	"Guest viewer token"
+//	@Success		200			{object}	models.SuccessResponse{data=models.TrackColoringResponse}	"Track coloring retrieved successfully"
+//	@Failure		400			{object}	models.ErrorResponse										"Invalid metric"
+//	@Failure		403			{object}	models.ErrorResponse										"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse										"User or session not found"
+//	@Router			/session/{username}/{session}/coloring [get]
+func (h *PublicHandler) GetSessionColoring(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	metric := c.QueryParam("metric")
+	if !utils.IsValidColoringMetric(metric) {
+		return apis.NewBadRequestError("metric must be one of: speed, heart_rate, elevation", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("session"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	if !hasSessionAccess(c, session) {
+		return apis.NewForbiddenError("Access denied", nil)
+	}
+
+	records, err := requestDao(h.app, c).FindRecordsByFilter(
+		"locations",
+		"session = {:session}",
+		"timestamp", // Segments follow the recorded order
+		0,
+		0,
+		dbx.Params{"session": session.Id},
+	)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch session data", err)
+	}
+
+	segments, _ := utils.SegmentTrack(locationsToTimedPoints(records), h.gapThresholds())
+
+	points := make([]utils.MetricPoint, len(records))
+	for i, record := range records {
+		points[i] = utils.MetricPoint{
+			Latitude:  record.GetFloat("latitude"),
+			Longitude: record.GetFloat("longitude"),
+			Segment:   segments[i],
+		}
+
+		switch metric {
+		case utils.ColoringMetricSpeed:
+			// Zero speed is a real value
+			speed := record.GetFloat("speed")
+			points[i].Value = &speed
+		case utils.ColoringMetricHeartRate:
+			points[i].Value = recordedMeasure(record, "heart_rate")
+		case utils.ColoringMetricElevation:
+			points[i].Value = utils.RecordAltitude(record)
+		}
+	}
+
+	coloring, err := utils.BuildTrackColoring(metric, points)
+	if err != nil {
+		return apis.NewBadRequestError("Failed to build track coloring", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, coloring, "")
+}
+
+// GetSessionStats returns distance, duration and gap statistics of a session track
+//
+//	@Summary		Get session track statistics
+//	@Description	Splits the session track into segments wherever consecutive points are too far apart in time or distance (tunnels, flights, tracker outages) and returns the gaps with distance and duration measured within segments only, along with the categorized climbs of the track, sunrise and sunset at its start and, once matched, its paved and unpaved distance
+//	@Tags			Public
+//	@Produce		json
+//	@Param			username	path		string	true	"Username"
+//	@Param			session		path		string	true	"Session name"
+//	@Param			share_token	query		string	false	"Share token for private sessions"
+//	@Param			guest_token	query		string	false	"Guest viewer token"
+//	@Success		200			{object}	models.SuccessResponse{data=models.SessionStatsResponse}	"Session statistics retrieved successfully"
+//	@Failure		403			{object}	models.ErrorResponse										"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse										"User or session not found"
+//	@Router			/session/{username}/{session}/stats [get]
+func (h *PublicHandler) GetSessionStats(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("session"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	if !hasSessionAccess(c, session) {
+		return apis.NewForbiddenError("Access denied", nil)
+	}
+
+	stats, err := sessionTrackStats(requestDao(h.app, c), session, h.gapThresholds())
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch session data", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, stats, "")
+}
+
+// ExportSession exports a session track with all recorded measures
+//
+//	@Summary		Export session track
+//	@Description	Returns the session track as a GPX 1.1 file (default) or a GeoJSON FeatureCollection, one track segment or feature per segment of the track. GPX files carry heart rate, cadence, temperature and speed in Garmin's TrackPointExtension, power in its PowerExtension and the fix accuracy in a vibe-tracker extension. GeoJSON features carry them in a "measures" foreign member with arrays aligned with the coordinates.
+//	@Tags			Public
+//	@Produce		application/gpx+xml,application/geo+json
+//	@Param			username	path		string	true	"Username"
+//	@Param			session		path		string	true	"Session name"
+//	@Param			format		query		string	false	"Export format: gpx (default) or geojson"
+//	@Param			share_token	query		string	false	"Share token for private sessions"
+//	@Param			guest_token	query		string	false	"Guest viewer token"
+//	@Success		200			{object}	models.TrackExportCollection	"Session track exported successfully"
+//	@Failure		400			{object}	models.ErrorResponse			"Invalid format"
+//	@Failure		403			{object}	models.ErrorResponse			"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse			"User or session not found, or session has no locations"
+//	@Router			/session/{username}/{session}/export [get]
+func (h *PublicHandler) ExportSession(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	params := middleware.GetValidatedQuery(c).(*appmodels.ExportQueryParams)
+	format := params.Format
+	if format == "" {
+		format = constants.DefaultExportFormat
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("session"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	if !hasSessionAccess(c, session) {
+		return apis.NewForbiddenError("Access denied", nil)
+	}
+
+	records, err := requestDao(h.app, c).FindRecordsByFilter(
+		"locations",
+		"session = {:session}",
+		"timestamp",
+		0,
+		0,
+		dbx.Params{"session": session.Id},
+	)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch session data", err)
+	}
+
+	if len(records) == 0 {
+		return apis.NewNotFoundError("No locations found for this session", nil)
+	}
+
+	points := locationsToExportPoints(records)
+	segments, _ := utils.SegmentTrack(locationsToTimedPoints(records), h.gapThresholds())
+
+	title := session.GetString("title")
+	if title == "" {
+		title = session.GetString("name")
+	}
+
+	if format == constants.ExportFormatGeoJSON {
+		body, err := json.Marshal(utils.BuildSessionGeoJSON(session.GetString("name"), title, points, segments))
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to build GeoJSON file", err)
+		}
+		c.Response().Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.geojson"`, session.GetString("name")))
+		return c.Blob(http.StatusOK, "application/geo+json", body)
+	}
+
+	gpx, err := utils.BuildSessionGPX(title, session.GetString("description"), points, segments)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to build GPX file", err)
+	}
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.gpx"`, session.GetString("name")))
+	return c.Blob(http.StatusOK, "application/gpx+xml", gpx)
+}
+
+// GetSessionSurface returns the surface and road type of a session track
+//
+//	@Summary		Get session track surface
+//	@Description	Returns the session track matched to OSM ways, split into segments with their surface and road type, and the distance on paved and unpaved ways. Sessions are matched in the background after they ended, when a map matching service is configured.
+//	@Tags			Public
+//	@Produce		json
+//	@Param			username	path		string	true	"Username"
+//	@Param			session		path		string	true	"Session name"
+//	@Param			share_token	query		string	false	"Share token for private sessions"
+//	@Param			guest_token	query		string	false	"Guest viewer token"
+//	@Success		200			{object}	models.SuccessResponse{data=models.SessionSurface}	"Session surface retrieved successfully"
+//	@Failure		403			{object}	models.ErrorResponse								"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse								"User or session not found, or surface not matched yet"
+//	@Router			/session/{username}/{session}/surface [get]
+func (h *PublicHandler) GetSessionSurface(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("session"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	if !hasSessionAccess(c, session) {
+		return apis.NewForbiddenError("Access denied", nil)
+	}
+
+	if session.GetDateTime("surface_matched_at").IsZero() {
+		return apis.NewNotFoundError("Session surface not matched yet", nil)
+	}
+
+	var surface appmodels.SessionSurface
+	if err := session.UnmarshalJSONField("surface", &surface); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to read session surface", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, surface, "")
+}
+
+// CreateGuestToken mints a guest viewer token from a share link
+//
+//	@Summary		Create guest viewer token
+//	@Description	Mints a short-lived token identifying an anonymous viewer of a shared session. The token grants read access to the session while it is shared the same way, is counted once in live viewer counts and is rate limited per viewer on the session's routes. Renewing it with the share link and a valid guest token keeps the same viewer; a guest token alone does not mint another one.
+//	@Tags			Public
+//	@Produce		json
+//	@Param			username	path		string	true	"Username"
+//	@Param			session		path		string	true	"Session name"
+//	@Param			share_token	query		string	false	"Share token for private sessions"
+//	@Success		201			{object}	models.SuccessResponse{data=models.GuestTokenResponse}	"Guest token created successfully"
+//	@Failure		403			{object}	models.ErrorResponse									"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse									"User or session not found"
+//	@Router			/session/{username}/{session}/guest-token [post]
+func (h *PublicHandler) CreateGuestToken(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("session"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	// A guest token does not mint another one, so it cannot be renewed once the share link is gone
+	if !hasShareAccess(c, session) {
+		return apis.NewForbiddenError("Access denied", nil)
+	}
+
+	// Renewing keeps the viewer identity so the viewer is not counted twice
+	viewerID := security.RandomString(16)
+	if guest, exists := sessionGuest(c, session); exists {
+		viewerID = guest.ViewerID
+	}
+
+	expiresAt := time.Now().Add(constants.GuestTokenTTL)
+	token, err := utils.SignGuestToken(middleware.GuestTokenSecret(h.app), utils.GuestClaims{
+		ViewerID:         viewerID,
+		SessionID:        session.Id,
+		Username:         user.Username(),
+		SessionName:      session.GetString("name"),
+		ShareFingerprint: utils.GuestShareFingerprint(session),
+		ExpiresAt:        expiresAt.Unix(),
+	})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to create guest token", err)
+	}
+
+	utils.LogInfo().
+		Str("viewer_id", viewerID).
+		Str("session_id", session.Id).
+		Msg("Guest viewer token issued")
+
+	response := appmodels.GuestTokenResponse{
+		GuestToken: token,
+		ViewerID:   viewerID,
+		SessionID:  session.Id,
+		ExpiresAt:  expiresAt.UTC().Truncate(time.Second),
+	}
+
+	return utils.SendSuccess(c, http.StatusCreated, response, "Guest token created successfully")
+}
+
+// GetBadge renders a user's status badge
+//
+//	@Summary		Get status badge
+//	@Description	Renders a small SVG badge with the time since the user's latest location ("last seen 5 min ago") or the distance tracked today in the user's time zone ("today 12.3 km"), for embedding in READMEs and blogs. Badges are only served for users who enabled public_badge in their tracking defaults.
+//	@Tags			Public
+//	@Produce		image/svg+xml
+//	@Param			username	path		string	true	"Username"
+//	@Param			show		query		string	false	"last_seen (default) or distance_today"
+//	@Param			label		query		string	false	"Text of the left part instead of the default"
+//	@Success		200			{string}	string					"SVG badge"
+//	@Success		304			{string}	string					"Badge not modified"
+//	@Failure		400			{object}	models.ErrorResponse	"Invalid show or label"
+//	@Failure		404			{object}	models.ErrorResponse	"User not found or badge not enabled"
+//	@Router			/users/{username}/badge.svg [get]
+func (h *PublicHandler) GetBadge(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	// Users without a badge are not told apart from unknown users
+	if !exists || !h.userService.GetTrackingDefaults(user).PublicBadge {
+		return apis.NewNotFoundError("Badge not found", nil)
+	}
+
+	label := c.QueryParam("label")
+	if len(label) > constants.BadgeMaxLabelLength {
+		return apis.NewBadRequestError(fmt.Sprintf("label must be at most %d characters", constants.BadgeMaxLabelLength), nil)
+	}
+
+	dao := requestDao(h.app, c)
+	var message, color string
+	switch show := c.QueryParam("show"); show {
+	case "", constants.BadgeShowLastSeen:
+		if label == "" {
+			label = "last seen"
+		}
+		message, color = "never", constants.BadgeColorInactive
+		latest, err := dao.FindRecordsByFilter(constants.CollectionLocations, "user = {:user}", "-timestamp", 1, 0,
+			dbx.Params{"user": user.Id})
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch locations", err)
+		}
+		if len(latest) > 0 {
+			seen := latest[0].GetDateTime("timestamp").Time()
+			message = utils.FormatTimeAgo(seen, time.Now())
+			switch elapsed := time.Since(seen); {
+			case elapsed < constants.BadgeActiveWindow:
+				color = constants.BadgeColorActive
+			case elapsed < 24*time.Hour:
+				color = constants.BadgeColorRecent
+			}
+		}
+	case constants.BadgeShowDistanceToday:
+		if label == "" {
+			label = "today"
+		}
+		zone, err := time.LoadLocation(h.userService.GetTrackingDefaults(user).Timezone)
+		if err != nil {
+			zone = time.UTC
+		}
+		now := time.Now().In(zone)
+		midnight, _ := types.ParseDateTime(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, zone))
+		records, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+			"user = {:user} && timestamp >= {:from}", "timestamp", 0, 0,
+			dbx.Params{"user": user.Id, "from": midnight})
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch locations", err)
+		}
+		stats := utils.ComputeTrackStats(locationsToTimedPoints(records), h.gapThresholds())
+		message, color = utils.FormatDistance(stats.Distance), constants.BadgeColorDistance
+	default:
+		return apis.NewBadRequestError(fmt.Sprintf("show must be %s or %s", constants.BadgeShowLastSeen, constants.BadgeShowDistanceToday), nil)
+	}
+
+	svg := utils.RenderBadge(label, message, color)
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(svg))
+
+	header := c.Response().Header()
+	header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(constants.BadgeCacheMaxAge.Seconds())))
+	header.Set("ETag", etag)
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, "image/svg+xml; charset=utf-8", svg)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/services"
+	"vibe-tracker/utils"
+)
+
+type RouteHandler struct {
+	app     *pocketbase.PocketBase
+	routing *services.RoutingService
+}
+
+func NewRouteHandler(app *pocketbase.PocketBase, routing *services.RoutingService) *RouteHandler {
+	return &RouteHandler{
+		app:     app,
+		routing: routing,
+	}
+}
+
+// PlanRoute plans a route between two positions
+//
+//	@Summary		Plan route
+//	@Description	Plans a route with the configured routing engine and returns it as a GeoJSON Feature with elevations, an elevation profile, ascent, descent and climbs. With format=gpx the route is returned as a GPX file that can be uploaded as the planned route of a session.
+//	@Tags			Routing
+//	@Produce		json,application/gpx+xml
+//	@Security		BearerAuth
+//	@Param			from	query		string	true	"Start position as lat,lon"
+//	@Param			to		query		string	true	"Destination as lat,lon"
+//	@Param			profile	query		string	false	"Routing profile: foot (default) or bike"
+//	@Param			format	query		string	false	"Response format: geojson (default) or gpx"
+//	@Success		200		{object}	models.SuccessResponse{data=models.RouteFeature}	"Route planned successfully"
+//	@Failure		400		{object}	models.ErrorResponse								"Invalid positions or profile"
+//	@Failure		401		{object}	models.ErrorResponse								"Authentication required"
+//	@Failure		404		{object}	models.ErrorResponse								"No route found"
+//	@Failure		502		{object}	models.ErrorResponse								"Routing engine unavailable"
+//	@Failure		503		{object}	models.ErrorResponse								"Route planning not configured"
+//	@Router			/route [get]
+func (h *RouteHandler) PlanRoute(c echo.Context) error {
+	if !h.routing.Enabled() {
+		return apis.NewApiError(http.StatusServiceUnavailable, "Route planning is not configured", nil)
+	}
+
+	params, ok := middleware.GetValidatedQuery(c).(*appmodels.RouteQueryParams)
+	if !ok {
+		return apis.NewBadRequestError("Invalid query parameters", nil)
+	}
+
+	fromLat, fromLon, err := utils.ParseLatLon(params.From)
+	if err != nil {
+		return apis.NewBadRequestError("Invalid from position: "+err.Error(), nil)
+	}
+	toLat, toLon, err := utils.ParseLatLon(params.To)
+	if err != nil {
+		return apis.NewBadRequestError("Invalid to position: "+err.Error(), nil)
+	}
+
+	profile := params.Profile
+	if profile == "" {
+		profile = constants.DefaultRouteProfile
+	}
+
+	route, err := h.routing.Plan(fromLat, fromLon, toLat, toLon, profile)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	if params.Format == constants.RouteFormatGPX {
+		gpx, err := utils.BuildRouteGPX(constants.RouteGPXName, *route)
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to build GPX", err)
+		}
+		c.Response().Header().Set("Content-Disposition", `attachment; filename="route.gpx"`)
+		return c.Blob(http.StatusOK, "application/gpx+xml", gpx)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, route, "Route planned successfully")
+}
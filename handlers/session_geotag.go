@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// GeotagPhoto positions a photo without GPS data from the session track
+//
+//	@Summary		Geotag photo
+//	@Description	Positions a photo without GPS data at its capture time (EXIF DateTimeOriginal, in the camera time zone) on the session track, interpolating between recorded points like the position-at endpoint. Returns a copy of the JPEG with the position embedded as GPS EXIF, keeping the other metadata, or with format=json only the position, which also works for PNG, WebP and HEIC photos. Nothing is stored.
+//	@Tags			Sessions
+//	@Accept			multipart/form-data
+//	@Produce		image/jpeg,json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Param			photo		formData	file	true	"Photo to geotag"
+//	@Param			format		formData	string	false	"jpeg (default) or json"
+//	@Param			max_offset	formData	int		false	"Seconds to the closest recorded point when the position cannot be interpolated (default: 300)"
+//	@Param			timezone	formData	string	false	"Time zone of the camera clock, an IANA name or UTC offset (default: UTC)"
+//	@Success		200			{object}	models.SuccessResponse{data=models.HistoricalPosition}	"Position of the photo (format=json)"
+//	@Failure		400			{object}	models.ErrorResponse	"Invalid photo, photo already geotagged or without capture time"
+//	@Failure		401			{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse	"Session not found or no position recorded near the capture time"
+//	@Router			/sessions/{username}/{name}/geotag [post]
+func (h *SessionHandler) GeotagPhoto(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+	if !canAccess(c, constants.PermSessionsRead, user.Id) {
+		return apis.NewForbiddenError("Cannot view another user's positions", nil)
+	}
+
+	dao := requestDao(h.app, c)
+	session, err := findSessionByNameAndUser(dao, c.PathParam("name"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	data := middleware.GetValidatedData(c).(*appmodels.GeotagPhotoRequest)
+	file, err := data.Photo.Open()
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to read photo file", err)
+	}
+	defer file.Close()
+
+	photo, err := io.ReadAll(file)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to read photo file", err)
+	}
+
+	embed := data.Format != "json"
+	if embed && http.DetectContentType(photo) != "image/jpeg" {
+		return apis.NewBadRequestError("Only JPEG photos can be geotagged, use format=json for the position of other photos", nil)
+	}
+
+	exifData, err := utils.ExtractEXIFData(bytes.NewReader(photo))
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to extract EXIF data", err)
+	}
+	if exifData.HasGPS {
+		return apis.NewBadRequestError("Photo is already geotagged", nil)
+	}
+	capturedAt := photoCaptureTime(exifData, data.Timezone)
+	if capturedAt == nil {
+		return apis.NewBadRequestError("Photo has no capture time in its EXIF data", nil)
+	}
+
+	maxOffset := constants.DefaultPositionMaxOffset
+	if data.MaxOffset != nil {
+		maxOffset = time.Duration(*data.MaxOffset) * time.Second
+	}
+
+	position, err := findPositionAt(dao, "session = {:session}", dbx.Params{"session": session.Id},
+		*capturedAt, h.gapThresholds, maxOffset)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch locations", err)
+	}
+	if position == nil {
+		return apis.NewNotFoundError("No position recorded near the capture time of the photo", nil)
+	}
+
+	if !embed {
+		return utils.SendSuccess(c, http.StatusOK, position, "")
+	}
+
+	geotagged, err := utils.EmbedGPSEXIF(photo, position.Latitude, position.Longitude, position.Altitude, *capturedAt)
+	if err != nil {
+		return apis.NewBadRequestError("Failed to geotag photo: "+err.Error(), nil)
+	}
+
+	c.Response().Header().Set("Content-Disposition",
+		mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(data.Photo.Filename)}))
+	return c.Blob(http.StatusOK, "image/jpeg", geotagged)
+}
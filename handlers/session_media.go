@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// UploadSessionCover sets the cover photo of a session
+//
+//	@Summary		Upload session cover
+//	@Description	Sets the cover photo shown for the session in session lists and previews, replacing the previous cover
+//	@Tags			Sessions
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Param			photo		formData	file	true	"Cover photo"
+//	@Success		200			{object}	models.SuccessResponse{data=models.SessionMedia}	"Cover uploaded successfully"
+//	@Failure		400			{object}	models.ErrorResponse	"Invalid file"
+//	@Failure		401			{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse	"Session not found"
+//	@Router			/sessions/{username}/{name}/cover [put]
+func (h *SessionHandler) UploadSessionCover(c echo.Context) error {
+	session, err := h.findOwnSession(c)
+	if err != nil {
+		return err
+	}
+
+	data := middleware.GetValidatedData(c).(*appmodels.UploadSessionCoverRequest)
+	dao := requestDao(h.app, c)
+
+	previous, err := findSessionCovers(dao, session.Id)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch session cover", err)
+	}
+
+	cover, err := h.saveSessionMedia(dao, session, data.Photo, constants.SessionMediaImage, "", true)
+	if err != nil {
+		return err
+	}
+
+	// Replaced only once the new cover is stored, a failed upload keeps the old one
+	for _, record := range previous {
+		if err := dao.DeleteRecord(record); err != nil {
+			utils.LogWarn().Err(err).Str("media_id", record.Id).Msg("Failed to delete replaced session cover")
+		}
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, recordToSessionMedia(h.app, cover), "Cover uploaded successfully")
+}
+
+// DeleteSessionCover removes the cover photo of a session
+//
+//	@Summary		Delete session cover
+//	@Description	Removes the cover photo of the session
+//	@Tags			Sessions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Success		200			{object}	models.SuccessResponse	"Cover deleted successfully"
+//	@Failure		401			{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse	"Session or cover not found"
+//	@Router			/sessions/{username}/{name}/cover [delete]
+func (h *SessionHandler) DeleteSessionCover(c echo.Context) error {
+	session, err := h.findOwnSession(c)
+	if err != nil {
+		return err
+	}
+
+	dao := requestDao(h.app, c)
+	covers, err := findSessionCovers(dao, session.Id)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch session cover", err)
+	}
+	if len(covers) == 0 {
+		return apis.NewNotFoundError("Session has no cover", nil)
+	}
+
+	for _, record := range covers {
+		if err := dao.DeleteRecord(record); err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to delete session cover", err)
+		}
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Cover deleted successfully")
+}
+
+// ListSessionMedia lists the photos and videos attached to a session
+//
+//	@Summary		List session media
+//	@Description	Returns the photos and videos attached to the session, oldest first, with signed download URLs. The cover photo is not included.
+//	@Tags			Sessions
+//	@Produce		json
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Success		200			{object}	models.SuccessResponse{data=[]models.SessionMedia}	"Media retrieved successfully"
+//	@Failure		403			{object}	models.ErrorResponse	"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse	"Session not found"
+//	@Router			/sessions/{username}/{name}/media [get]
+func (h *SessionHandler) ListSessionMedia(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	dao := requestDao(h.app, c)
+	session, err := findSessionByNameAndUser(dao, c.PathParam("name"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+	if !hasSessionAccess(c, session) {
+		return apis.NewForbiddenError("Access denied", nil)
+	}
+
+	media, err := listSessionMedia(h.app, dao, session.Id)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch session media", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, media, "")
+}
+
+// UploadSessionMedia attaches a photo or video to a session
+//
+//	@Summary		Upload session media
+//	@Description	Attaches a photo or short video to the session, independent of its waypoints
+//	@Tags			Sessions
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Param			file		formData	file	true	"Photo (JPEG, PNG, WebP, HEIC) or video (MP4, QuickTime)"
+//	@Param			caption		formData	string	false	"Caption"
+//	@Success		201			{object}	models.SuccessResponse{data=models.SessionMedia}	"Media uploaded successfully"
+//	@Failure		400			{object}	models.ErrorResponse	"Invalid file or too many media"
+//	@Failure		401			{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse	"Session not found"
+//	@Router			/sessions/{username}/{name}/media [post]
+func (h *SessionHandler) UploadSessionMedia(c echo.Context) error {
+	session, err := h.findOwnSession(c)
+	if err != nil {
+		return err
+	}
+
+	data := middleware.GetValidatedData(c).(*appmodels.UploadSessionMediaRequest)
+	dao := requestDao(h.app, c)
+
+	var count int
+	err = dao.DB().Select("count(*)").From(constants.CollectionSessionMedia).
+		Where(dbx.HashExp{"session": session.Id, "cover": false}).Row(&count)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to count session media", err)
+	}
+	if count >= constants.MaxSessionMedia {
+		return apis.NewBadRequestError(fmt.Sprintf("A session can have at most %d media files", constants.MaxSessionMedia), nil)
+	}
+
+	mediaType := constants.SessionMediaImage
+	if detected, _ := utils.SniffMultipartFile(data.File); !utils.IsValidImageFormat(detected) {
+		mediaType = constants.SessionMediaVideo
+	}
+
+	media, err := h.saveSessionMedia(dao, session, data.File, mediaType, data.Caption, false)
+	if err != nil {
+		return err
+	}
+
+	return utils.SendSuccess(c, http.StatusCreated, recordToSessionMedia(h.app, media), "Media uploaded successfully")
+}
+
+// DeleteSessionMedia removes a photo or video from a session
+//
+//	@Summary		Delete session media
+//	@Description	Removes a photo or video attached to the session
+//	@Tags			Sessions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Param			id			path		string	true	"Media ID"
+//	@Success		200			{object}	models.SuccessResponse	"Media deleted successfully"
+//	@Failure		401			{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse	"Session or media not found"
+//	@Router			/sessions/{username}/{name}/media/{id} [delete]
+func (h *SessionHandler) DeleteSessionMedia(c echo.Context) error {
+	session, err := h.findOwnSession(c)
+	if err != nil {
+		return err
+	}
+
+	dao := requestDao(h.app, c)
+	media, err := dao.FindRecordById(constants.CollectionSessionMedia, c.PathParam("id"))
+	if err != nil || media.GetString("session") != session.Id || media.GetBool("cover") {
+		return apis.NewNotFoundError("Media not found", err)
+	}
+
+	if err := dao.DeleteRecord(media); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to delete media", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Media deleted successfully")
+}
+
+// saveSessionMedia stores an uploaded file as media of a session
+func (h *SessionHandler) saveSessionMedia(dao *daos.Dao, session *models.Record, fileHeader *multipart.FileHeader, mediaType, caption string, cover bool) (*models.Record, error) {
+	collection, err := dao.FindCollectionByNameOrId(constants.CollectionSessionMedia)
+	if err != nil {
+		return nil, apis.NewApiError(http.StatusInternalServerError, "Session media collection not found", err)
+	}
+
+	file, err := filesystem.NewFileFromMultipart(fileHeader)
+	if err != nil {
+		return nil, apis.NewApiError(http.StatusInternalServerError, "Failed to read uploaded file", err)
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("session", session.Id)
+	record.Set("user", session.GetString("user"))
+	record.Set("type", mediaType)
+	record.Set("caption", caption)
+	record.Set("cover", cover)
+
+	// Only the file is taken from the upload, other form values never reach the record
+	form := forms.NewRecordUpsert(h.app, record)
+	form.SetDao(dao)
+	if err := form.AddFiles("file", file); err != nil {
+		return nil, apis.NewApiError(http.StatusInternalServerError, "Failed to save media", err)
+	}
+	if err := form.Submit(); err != nil {
+		return nil, apis.NewApiError(http.StatusInternalServerError, "Failed to save media", err)
+	}
+
+	return record, nil
+}
+
+// findSessionCovers returns the cover media of a session; there is at most one unless a
+// replacement failed halfway
+func findSessionCovers(dao *daos.Dao, sessionID string) ([]*models.Record, error) {
+	return dao.FindRecordsByFilter(constants.CollectionSessionMedia, "session = {:session} && cover = true",
+		"-created", 0, 0, dbx.Params{"session": sessionID})
+}
+
+// listSessionMedia returns the media of a session besides its cover, oldest first
+func listSessionMedia(app *pocketbase.PocketBase, dao *daos.Dao, sessionID string) ([]appmodels.SessionMedia, error) {
+	records, err := dao.FindRecordsByFilter(constants.CollectionSessionMedia, "session = {:session} && cover = false",
+		"created", 0, 0, dbx.Params{"session": sessionID})
+	if err != nil {
+		return nil, err
+	}
+
+	media := make([]appmodels.SessionMedia, len(records))
+	for i, record := range records {
+		media[i] = recordToSessionMedia(app, record)
+	}
+	return media, nil
+}
+
+// sessionCoverURLs returns signed cover URLs of sessions by session ID, for sessions with a cover
+func sessionCoverURLs(app *pocketbase.PocketBase, dao *daos.Dao, sessionIDs ...string) map[string]string {
+	urls := map[string]string{}
+	if len(sessionIDs) == 0 {
+		return urls
+	}
+
+	ids := make([]any, len(sessionIDs))
+	for i, id := range sessionIDs {
+		ids[i] = id
+	}
+	covers, err := dao.FindRecordsByExpr(constants.CollectionSessionMedia,
+		dbx.HashExp{"session": ids, "cover": true})
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Failed to fetch session covers")
+		return urls
+	}
+
+	for _, cover := range covers {
+		urls[cover.GetString("session")] = signedFileURL(app, cover, "file")
+	}
+	return urls
+}
+
+func recordToSessionMedia(app *pocketbase.PocketBase, record *models.Record) appmodels.SessionMedia {
+	return appmodels.SessionMedia{
+		ID:      record.Id,
+		Type:    record.GetString("type"),
+		Caption: record.GetString("caption"),
+		URL:     signedFileURL(app, record, "file"),
+		Created: record.GetDateTime("created").Time(),
+	}
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// WithGapThresholds sets the limits for splitting snapshot tracks into segments
+func (h *SessionHandler) WithGapThresholds(thresholds utils.GapThresholds) *SessionHandler {
+	h.gapThresholds = thresholds
+	return h
+}
+
+// CreateSessionSnapshot archives a session as a static bundle
+//
+//	@Summary		Create session snapshot
+//	@Description	Renders the session track as GeoJSON, its statistics, waypoints and photos into a static bundle in the file storage (local or S3) and returns the permanent URL of its manifest, snapshot.json. The manifest references the other files by name, relative to itself. The bundle is a copy: it stays available when points or media are later pruned or the session is deleted, and anyone with the URL can read it, even for private sessions.
+//	@Tags			Sessions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Success		201			{object}	models.SuccessResponse{data=models.SessionSnapshot}	"Snapshot created successfully"
+//	@Failure		401			{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse	"Session not found or session has no locations"
+//	@Router			/sessions/{username}/{name}/snapshot [post]
+func (h *SessionHandler) CreateSessionSnapshot(c echo.Context) error {
+	session, err := h.findOwnSession(c)
+	if err != nil {
+		return err
+	}
+	user, _ := GetRequestUser(c)
+	dao := requestDao(h.app, c)
+
+	records, err := dao.FindRecordsByFilter(
+		constants.CollectionLocations,
+		"session = {:session}",
+		"timestamp",
+		0,
+		0,
+		dbx.Params{"session": session.Id},
+	)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch session data", err)
+	}
+	if len(records) == 0 {
+		return apis.NewNotFoundError("No locations found for this session", nil)
+	}
+
+	title := session.GetString("title")
+	if title == "" {
+		title = session.GetString("name")
+	}
+
+	segments, _ := utils.SegmentTrack(locationsToTimedPoints(records), h.gapThresholds)
+	track, err := jsonFile(utils.BuildSessionGeoJSON(session.GetString("name"), title, locationsToExportPoints(records), segments), constants.SnapshotTrackName)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to build GeoJSON file", err)
+	}
+
+	manifest := appmodels.SnapshotManifest{
+		Version: constants.SnapshotFormatVersion,
+		Session: appmodels.SnapshotSession{
+			Username:        user.GetString("username"),
+			Name:            session.GetString("name"),
+			Title:           title,
+			Description:     session.GetString("description"),
+			DescriptionHTML: utils.RenderMarkdown(session.GetString("description")),
+			Activity:        session.GetString("activity"),
+		},
+		Stats:     trackStatsOf(session, records, h.gapThresholds),
+		Track:     track.Name,
+		Photos:    []appmodels.SnapshotPhoto{},
+		Waypoints: []appmodels.SnapshotWaypoint{},
+		Created:   time.Now().UTC(),
+	}
+	if endedAt := session.GetDateTime("ended_at"); !endedAt.IsZero() {
+		ended := endedAt.Time()
+		manifest.Session.EndedAt = &ended
+	}
+
+	files := []*filesystem.File{track}
+	if err := h.collectSnapshotPhotos(dao, session, &manifest, &files); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to copy session photos", err)
+	}
+
+	if len(files)+1 > constants.MaxSnapshotFiles {
+		return apis.NewBadRequestError("Session has too many photos for a snapshot", nil)
+	}
+
+	manifestFile, err := jsonFile(manifest, constants.SnapshotManifestName)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to build snapshot manifest", err)
+	}
+	files = append(files, manifestFile)
+
+	collection, err := dao.FindCollectionByNameOrId(constants.CollectionSessionSnapshots)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Session snapshots collection not found", err)
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("session", session.Id)
+	record.Set("user", user.Id)
+	record.Set("name", session.GetString("name"))
+	record.Set("manifest", manifestFile.Name)
+
+	form := forms.NewRecordUpsert(h.app, record)
+	form.SetDao(dao)
+	if err := form.AddFiles("files", files...); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to save snapshot", err)
+	}
+	if err := form.Submit(); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to save snapshot", err)
+	}
+
+	snapshot := appmodels.SessionSnapshot{
+		ID:      record.Id,
+		URL:     snapshotURL(h.app.Settings().Meta.AppUrl, record),
+		Files:   len(files),
+		Created: record.GetDateTime("created").Time(),
+	}
+	return utils.SendSuccess(c, http.StatusCreated, snapshot, "Snapshot created successfully")
+}
+
+// collectSnapshotPhotos copies the cover, the image media and the waypoint photos of a session
+// into a snapshot; videos are left out
+func (h *SessionHandler) collectSnapshotPhotos(dao *daos.Dao, session *models.Record, manifest *appmodels.SnapshotManifest, files *[]*filesystem.File) error {
+	fs, err := h.app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	copyFile := func(record *models.Record, field string) (string, error) {
+		name := record.GetString(field)
+		if name == "" {
+			return "", nil
+		}
+		reader, err := fs.GetFile(record.BaseFilesPath() + "/" + name)
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return "", err
+		}
+		file, err := filesystem.NewFileFromBytes(content, name)
+		if err != nil {
+			return "", err
+		}
+		*files = append(*files, file)
+		return file.Name, nil
+	}
+
+	media, err := dao.FindRecordsByFilter(constants.CollectionSessionMedia, "session = {:session} && type = {:type}",
+		"created", 0, 0, dbx.Params{"session": session.Id, "type": constants.SessionMediaImage})
+	if err != nil {
+		return err
+	}
+	for _, record := range media {
+		name, err := copyFile(record, "file")
+		if err != nil {
+			return err
+		}
+		if record.GetBool("cover") {
+			manifest.Cover = name
+			continue
+		}
+		manifest.Photos = append(manifest.Photos, appmodels.SnapshotPhoto{File: name, Caption: record.GetString("caption")})
+	}
+
+	waypoints, err := dao.FindRecordsByFilter(constants.CollectionWaypoints, "session_id = {:session}",
+		"order,created", 0, 0, dbx.Params{"session": session.Id})
+	if err != nil {
+		return err
+	}
+	for _, record := range waypoints {
+		photo, err := copyFile(record, "photo")
+		if err != nil {
+			return err
+		}
+		waypoint := appmodels.SnapshotWaypoint{
+			Name:            record.GetString("name"),
+			Type:            record.GetString("type"),
+			Description:     record.GetString("description"),
+			DescriptionHTML: utils.RenderMarkdown(record.GetString("description")),
+			Latitude:        record.GetFloat("latitude"),
+			Longitude:       record.GetFloat("longitude"),
+			Altitude:        utils.RecordAltitude(record),
+			Photo:           photo,
+		}
+		manifest.Waypoints = append(manifest.Waypoints, waypoint)
+	}
+	return nil
+}
+
+// jsonFile encodes a value as a JSON file of a snapshot bundle
+func jsonFile(value any, name string) (*filesystem.File, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return filesystem.NewFileFromBytes(content, name)
+}
+
+// snapshotURL returns the permanent URL of a snapshot's manifest. Snapshot files are not
+// protected, PocketBase serves them from the local or S3 storage without a token.
+func snapshotURL(appURL string, record *models.Record) string {
+	return strings.TrimRight(appURL, "/") + "/api/files/" + record.Collection().Name + "/" + record.Id + "/" + record.GetString("manifest")
+}
@@ -2,21 +2,28 @@ package handlers
 
 import (
 	"fmt"
+	"math"
+	"mime/multipart"
 	"net/http"
+	"sort"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 
 	"vibe-tracker/constants"
 	"vibe-tracker/middleware"
 	appmodels "vibe-tracker/models"
+	"vibe-tracker/plugins"
 	"vibe-tracker/services"
 	"vibe-tracker/utils"
 )
@@ -24,15 +31,48 @@ import (
 type SessionHandler struct {
 	app            *pocketbase.PocketBase
 	sessionService *services.SessionService
+	checkIns       *services.CheckInService
+	mapMatch       *services.MapMatchService
+	webhooks       *services.WebhookService
+	hooks          *plugins.Hooks
+	gapThresholds  utils.GapThresholds
+	simplification utils.SimplificationOptions
 }
 
-func NewSessionHandler(app *pocketbase.PocketBase, sessionService *services.SessionService) *SessionHandler {
+func NewSessionHandler(app *pocketbase.PocketBase, sessionService *services.SessionService, checkIns *services.CheckInService, mapMatch *services.MapMatchService) *SessionHandler {
 	return &SessionHandler{
 		app:            app,
 		sessionService: sessionService,
+		checkIns:       checkIns,
+		mapMatch:       mapMatch,
+		simplification: utils.SimplificationOptions{
+			Enabled:           constants.DefaultSimplifyTracks,
+			MinPoints:         constants.DefaultSimplifyMinPoints,
+			MaxPoints:         constants.DefaultSimplifyMaxPoints,
+			EpsilonMultiplier: constants.DefaultSimplifyEpsilonMultiplier,
+		},
 	}
 }
 
+// WithWebhooks sends the session.published webhook when a draft session is published
+func (h *SessionHandler) WithWebhooks(webhooks *services.WebhookService) *SessionHandler {
+	h.webhooks = webhooks
+	return h
+}
+
+// WithPluginHooks triggers the plugin OnSessionPublished hook when a draft session is published
+func (h *SessionHandler) WithPluginHooks(hooks *plugins.Hooks) *SessionHandler {
+	h.hooks = hooks
+	return h
+}
+
+// WithSimplification sets how planned tracks imported from GPX are simplified unless an upload
+// sets its own options
+func (h *SessionHandler) WithSimplification(options utils.SimplificationOptions) *SessionHandler {
+	h.simplification = options
+	return h
+}
+
 // ListSessions lists all sessions for a user
 //
 //	@Summary		List user sessions
@@ -66,10 +106,19 @@ func (h *SessionHandler) ListSessions(c echo.Context) error {
 		}
 	}
 
+	// Sessions hidden by moderation and drafts are only listed to users who may read them
+	filter := "user = {:user}"
+	countExp := dbx.HashExp{"user": user.Id}
+	if !canAccess(c, constants.PermSessionsRead, user.Id) {
+		filter += " && hidden = false && draft = false"
+		countExp["hidden"] = false
+		countExp["draft"] = false
+	}
+
 	// Get sessions with pagination
-	sessions, err := h.app.Dao().FindRecordsByFilter(
+	sessions, err := requestDao(h.app, c).FindRecordsByFilter(
 		"sessions",
-		"user = {:user}",
+		filter,
 		"-created", // Order by newest first
 		perPage,
 		(page-1)*perPage,
@@ -82,14 +131,22 @@ func (h *SessionHandler) ListSessions(c echo.Context) error {
 
 	// Count total sessions for pagination
 	var totalSessions int64
-	err = h.app.Dao().DB().Select("count(*)").From("sessions").Where(dbx.HashExp{"user": user.Id}).Row(&totalSessions)
+	err = requestDao(h.app, c).DB().Select("count(*)").From("sessions").Where(countExp).Row(&totalSessions)
 	if err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to count sessions", err)
 	}
 
-	// Check if authenticated user is the owner
-	authRecord, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
-	isOwner := authRecord != nil && authRecord.Id == user.Id
+	// Check if authenticated user may manage the sessions
+	canManage := canAccess(c, constants.PermSessionsWrite, user.Id)
+
+	// Covers are shown for the sessions the requester may view
+	var viewableIDs []string
+	for _, session := range sessions {
+		if hasSessionAccess(c, session) {
+			viewableIDs = append(viewableIDs, session.Id)
+		}
+	}
+	coverURLs := sessionCoverURLs(h.app, requestDao(h.app, c), viewableIDs...)
 
 	// Format response
 	sessionList := make([]map[string]any, len(sessions))
@@ -99,17 +156,28 @@ func (h *SessionHandler) ListSessions(c echo.Context) error {
 			"name":              session.GetString("name"),
 			"title":             session.GetString("title"),
 			"description":       session.GetString("description"),
+			"description_html":  utils.RenderMarkdown(session.GetString("description")),
 			"public":            session.GetBool("public"),
+			"activity":          session.GetString("activity"),
 			"created":           session.GetDateTime("created").Time().Format(time.RFC3339),
 			"updated":           session.GetDateTime("updated").Time().Format(time.RFC3339),
 			"gpx_track":         session.GetString("gpx_track"),
 			"track_name":        session.GetString("track_name"),
 			"track_description": session.GetString("track_description"),
+			"ended_at":          formatOptionalDate(session, "ended_at"),
+		}
+		if coverURL, ok := coverURLs[session.Id]; ok {
+			sessionData["cover_url"] = coverURL
 		}
 
-		// Include share_token only for owner
-		if isOwner {
+		// Include share_token only for users managing the session
+		if canManage {
 			sessionData["share_token"] = session.GetString("share_token")
+			sessionData["show_viewer_count"] = session.GetBool("show_viewer_count")
+			sessionData["hidden"] = session.GetBool("hidden")
+			sessionData["draft"] = session.GetBool("draft")
+			sessionData["downsampling"] = session.Get("downsampling")
+			sessionData["downsampled_points"] = session.GetInt("downsampled_points")
 		}
 
 		sessionList[i] = sessionData
@@ -142,36 +210,61 @@ func (h *SessionHandler) GetSession(c echo.Context) error {
 	username := c.PathParam("username")
 	sessionName := c.PathParam("name")
 
-	user, err := findUserByUsername(h.app.Dao(), username)
+	user, err := findUserByUsername(requestDao(h.app, c), username)
 	if err != nil {
 		return apis.NewNotFoundError("User not found", err)
 	}
 
-	session, err := findSessionByNameAndUser(h.app.Dao(), sessionName, user.Id)
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), sessionName, user.Id)
 	if err != nil {
 		return apis.NewNotFoundError("Session not found", err)
 	}
 
-	// Check if authenticated user is the owner
-	authRecord, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
-	isOwner := authRecord != nil && authRecord.Id == user.Id
+	if (session.GetBool("hidden") || session.GetBool("draft")) && !canAccess(c, constants.PermSessionsRead, user.Id) {
+		return apis.NewNotFoundError("Session not found", nil)
+	}
+
+	// Check if authenticated user may manage the sessions
+	canManage := canAccess(c, constants.PermSessionsWrite, user.Id)
 
 	sessionData := map[string]any{
 		"id":                session.Id,
 		"name":              session.GetString("name"),
 		"title":             session.GetString("title"),
 		"description":       session.GetString("description"),
+		"description_html":  utils.RenderMarkdown(session.GetString("description")),
 		"public":            session.GetBool("public"),
+		"activity":          session.GetString("activity"),
 		"created":           session.GetDateTime("created").Time().Format(time.RFC3339),
 		"updated":           session.GetDateTime("updated").Time().Format(time.RFC3339),
 		"gpx_track":         session.GetString("gpx_track"),
 		"track_name":        session.GetString("track_name"),
 		"track_description": session.GetString("track_description"),
+		"ended_at":          formatOptionalDate(session, "ended_at"),
+	}
+
+	// Download links only for viewers of the track, the metadata of private sessions is listed too
+	if hasSessionAccess(c, session) {
+		dao := requestDao(h.app, c)
+		sessionData["gpx_track_url"] = signedFileURL(h.app, session, "gpx_track")
+		if coverURL, ok := sessionCoverURLs(h.app, dao, session.Id)[session.Id]; ok {
+			sessionData["cover_url"] = coverURL
+		}
+		media, err := listSessionMedia(h.app, dao, session.Id)
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch session media", err)
+		}
+		sessionData["media"] = media
 	}
 
-	// Include share_token only for owner
-	if isOwner {
+	// Include share_token only for users managing the session
+	if canManage {
 		sessionData["share_token"] = session.GetString("share_token")
+		sessionData["show_viewer_count"] = session.GetBool("show_viewer_count")
+		sessionData["hidden"] = session.GetBool("hidden")
+		sessionData["draft"] = session.GetBool("draft")
+		sessionData["downsampling"] = session.Get("downsampling")
+		sessionData["downsampled_points"] = session.GetInt("downsampled_points")
 	}
 
 	return utils.SendSuccess(c, http.StatusOK, sessionData, "")
@@ -209,13 +302,13 @@ func (h *SessionHandler) CreateSession(c echo.Context) error {
 	}
 
 	// Check if session with this name already exists for the user
-	existingSession, _ := findSessionByNameAndUser(h.app.Dao(), data.Name, record.Id)
+	existingSession, _ := findSessionByNameAndUser(requestDao(h.app, c), data.Name, record.Id)
 	if existingSession != nil {
 		return apis.NewBadRequestError("Session with this name already exists", nil)
 	}
 
 	// Create new session
-	sessionsCollection, err := h.app.Dao().FindCollectionByNameOrId("sessions")
+	sessionsCollection, err := requestDao(h.app, c).FindCollectionByNameOrId("sessions")
 	if err != nil {
 		return apis.NewNotFoundError("sessions collection not found", err)
 	}
@@ -235,10 +328,12 @@ func (h *SessionHandler) CreateSession(c echo.Context) error {
 	session.Set("title", data.Title)
 	session.Set("description", data.Description)
 	session.Set("public", isPublic)
+	session.Set("draft", data.Draft)
+	session.Set("activity", data.Activity)
 	// Generate share token for private session sharing
 	session.Set("share_token", security.RandomString(32))
 
-	if err := h.app.Dao().SaveRecord(session); err != nil {
+	if err := requestDao(h.app, c).SaveRecord(session); err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to create session", err)
 	}
 
@@ -247,8 +342,11 @@ func (h *SessionHandler) CreateSession(c echo.Context) error {
 		"name":              session.GetString("name"),
 		"title":             session.GetString("title"),
 		"description":       session.GetString("description"),
+		"description_html":  utils.RenderMarkdown(session.GetString("description")),
 		"public":            session.GetBool("public"),
+		"activity":          session.GetString("activity"),
 		"share_token":       session.GetString("share_token"), // Always include for creator
+		"draft":             session.GetBool("draft"),
 		"created":           session.GetDateTime("created").Time().Format(time.RFC3339),
 		"updated":           session.GetDateTime("updated").Time().Format(time.RFC3339),
 		"gpx_track":         session.GetString("gpx_track"),
@@ -277,20 +375,17 @@ func (h *SessionHandler) CreateSession(c echo.Context) error {
 //	@Failure		404			{object}	models.ErrorResponse				"Session not found"
 //	@Router			/sessions/{username}/{name} [put]
 func (h *SessionHandler) UpdateSession(c echo.Context) error {
-	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
-	if record == nil {
-		return apis.NewUnauthorizedError("Authentication required", nil)
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
 	}
 
-	username := c.PathParam("username")
-	sessionName := c.PathParam("name")
-
-	// Verify user matches authenticated user
-	if record.Username() != username {
+	// Verify the authenticated user may modify this user's sessions
+	if !canAccess(c, constants.PermSessionsWrite, user.Id) {
 		return apis.NewForbiddenError("Cannot update another user's sessions", nil)
 	}
 
-	session, err := findSessionByNameAndUser(h.app.Dao(), sessionName, record.Id)
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
 	if err != nil {
 		return apis.NewNotFoundError("Session not found", err)
 	}
@@ -306,32 +401,199 @@ func (h *SessionHandler) UpdateSession(c echo.Context) error {
 	session.Set("title", data.Title)
 	session.Set("description", data.Description)
 	session.Set("public", data.Public)
+	if data.Activity != nil {
+		session.Set("activity", *data.Activity)
+	}
+	if data.ShowViewerCount != nil {
+		session.Set("show_viewer_count", *data.ShowViewerCount)
+	}
+	if data.ResetDownsampling {
+		session.Set("downsampling", nil)
+	} else if data.Downsampling != nil {
+		session.Set("downsampling", data.Downsampling)
+	}
 
-	if err := h.app.Dao().SaveRecord(session); err != nil {
+	if err := requestDao(h.app, c).SaveRecord(session); err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to update session", err)
 	}
 
 	sessionData := map[string]any{
-		"id":                session.Id,
-		"name":              session.GetString("name"),
-		"title":             session.GetString("title"),
-		"description":       session.GetString("description"),
-		"public":            session.GetBool("public"),
-		"share_token":       session.GetString("share_token"), // Always include for owner
-		"created":           session.GetDateTime("created").Time().Format(time.RFC3339),
-		"updated":           session.GetDateTime("updated").Time().Format(time.RFC3339),
-		"gpx_track":         session.GetString("gpx_track"),
-		"track_name":        session.GetString("track_name"),
-		"track_description": session.GetString("track_description"),
+		"id":                 session.Id,
+		"name":               session.GetString("name"),
+		"title":              session.GetString("title"),
+		"description":        session.GetString("description"),
+		"description_html":   utils.RenderMarkdown(session.GetString("description")),
+		"public":             session.GetBool("public"),
+		"activity":           session.GetString("activity"),
+		"share_token":        session.GetString("share_token"), // Always include for owner
+		"show_viewer_count":  session.GetBool("show_viewer_count"),
+		"draft":              session.GetBool("draft"),
+		"downsampling":       session.Get("downsampling"),
+		"downsampled_points": session.GetInt("downsampled_points"),
+		"created":            session.GetDateTime("created").Time().Format(time.RFC3339),
+		"updated":            session.GetDateTime("updated").Time().Format(time.RFC3339),
+		"gpx_track":          session.GetString("gpx_track"),
+		"track_name":         session.GetString("track_name"),
+		"track_description":  session.GetString("track_description"),
 	}
 
 	return utils.SendSuccess(c, http.StatusOK, sessionData, "Session updated successfully")
 }
 
+// sessionNameReferences are the collections that refer to sessions of a user by name: the sources
+// that track into a fixed session. Locations refer to their session by relation.
+var sessionNameReferences = []string{
+	constants.CollectionIngestSources,
+	constants.CollectionMapShareFeeds,
+	constants.CollectionHardwareTrackers,
+}
+
+// RenameSession changes the name of a session
+//
+//	@Summary		Rename session
+//	@Description	Changes the name of a session, e.g. to fix a typo, together with the references to it by name of the ingest sources, MapShare feeds and hardware trackers tracking into it. A title generated from the old name is regenerated. Links to the old name stop working; snapshots keep the name they were taken with.
+//	@Tags			Sessions
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string						true	"Username"
+//	@Param			name		path		string						true	"Session name"
+//	@Param			request		body		models.RenameSessionRequest	true	"New session name"
+//	@Success		200			{object}	models.SuccessResponse			"Session renamed successfully"
+//	@Failure		400			{object}	models.ErrorResponse				"Invalid name or a session with the name exists"
+//	@Failure		401			{object}	models.ErrorResponse				"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse				"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse				"Session not found"
+//	@Router			/sessions/{username}/{name}/rename [post]
+func (h *SessionHandler) RenameSession(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	// Verify the authenticated user may modify this user's sessions
+	if !canAccess(c, constants.PermSessionsWrite, user.Id) {
+		return apis.NewForbiddenError("Cannot rename another user's sessions", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	data, ok := middleware.GetValidatedData(c).(*appmodels.RenameSessionRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	oldName := session.GetString("name")
+	if data.Name == oldName {
+		return apis.NewBadRequestError("Session already has this name", nil)
+	}
+	if existing, _ := findSessionByNameAndUser(requestDao(h.app, c), data.Name, user.Id); existing != nil {
+		return apis.NewBadRequestError("Session with this name already exists", nil)
+	}
+
+	session.Set("name", data.Name)
+	if session.GetString("title") == GenerateSessionTitle(oldName) {
+		session.Set("title", GenerateSessionTitle(data.Name))
+	}
+
+	// Locations and sources find the session by name, they are renamed with it or not at all
+	err = requestDao(h.app, c).RunInTransaction(func(txDao *daos.Dao) error {
+		if err := txDao.SaveRecord(session); err != nil {
+			return err
+		}
+		for _, collection := range sessionNameReferences {
+			_, err := txDao.DB().Update(collection, dbx.Params{"session": data.Name},
+				dbx.HashExp{"user": user.Id, "session": oldName}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to rename session", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, map[string]any{
+		"id":            session.Id,
+		"name":          session.GetString("name"),
+		"title":         session.GetString("title"),
+		"previous_name": oldName,
+	}, "Session renamed successfully")
+}
+
+// PublishSession publishes a draft session
+//
+//	@Summary		Publish draft session
+//	@Description	Publishes a draft session, e.g. an imported one after reviewing its title, photos and track, so a public session appears in public feeds. Sends the session.published webhook.
+//	@Tags			Sessions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Success		200			{object}	models.SuccessResponse	"Session published successfully"
+//	@Failure		400			{object}	models.ErrorResponse		"Session is not a draft"
+//	@Failure		401			{object}	models.ErrorResponse		"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse		"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse		"Session not found"
+//	@Router			/sessions/{username}/{name}/publish [post]
+func (h *SessionHandler) PublishSession(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	// Verify the authenticated user may modify this user's sessions
+	if !canAccess(c, constants.PermSessionsWrite, user.Id) {
+		return apis.NewForbiddenError("Cannot publish another user's sessions", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+	if !session.GetBool("draft") {
+		return apis.NewBadRequestError("Session is not a draft", nil)
+	}
+
+	session.Set("draft", false)
+	if err := requestDao(h.app, c).SaveRecord(session); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to publish session", err)
+	}
+
+	if h.webhooks != nil {
+		h.webhooks.Send(constants.WebhookEventSessionPublished, appmodels.SessionPublishedEvent{
+			SessionID:   session.Id,
+			Name:        session.GetString("name"),
+			Title:       session.GetString("title"),
+			UserID:      user.Id,
+			Public:      session.GetBool("public"),
+			PublishedAt: session.GetDateTime("updated").Time(),
+		})
+	}
+	if h.hooks != nil {
+		h.hooks.SessionPublished(session)
+	}
+
+	sessionData := map[string]any{
+		"id":      session.Id,
+		"name":    session.GetString("name"),
+		"title":   session.GetString("title"),
+		"public":  session.GetBool("public"),
+		"draft":   false,
+		"updated": session.GetDateTime("updated").Time().Format(time.RFC3339),
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, sessionData, "Session published successfully")
+}
+
 // DeleteSession deletes an existing session
 //
 //	@Summary		Delete session
-//	@Description	Deletes an existing session for the authenticated user
+//	@Description	Deletes an existing session for the authenticated user together with its tracked locations
 //	@Tags			Sessions
 //	@Produce		json
 //	@Security		BearerAuth
@@ -343,25 +605,22 @@ func (h *SessionHandler) UpdateSession(c echo.Context) error {
 //	@Failure		404			{object}	models.ErrorResponse		"Session not found"
 //	@Router			/sessions/{username}/{name} [delete]
 func (h *SessionHandler) DeleteSession(c echo.Context) error {
-	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
-	if record == nil {
-		return apis.NewUnauthorizedError("Authentication required", nil)
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
 	}
 
-	username := c.PathParam("username")
-	sessionName := c.PathParam("name")
-
-	// Verify user matches authenticated user
-	if record.Username() != username {
+	// Verify the authenticated user may modify this user's sessions
+	if !canAccess(c, constants.PermSessionsWrite, user.Id) {
 		return apis.NewForbiddenError("Cannot delete another user's sessions", nil)
 	}
 
-	session, err := findSessionByNameAndUser(h.app.Dao(), sessionName, record.Id)
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
 	if err != nil {
 		return apis.NewNotFoundError("Session not found", err)
 	}
 
-	if err := h.app.Dao().DeleteRecord(session); err != nil {
+	if err := requestDao(h.app, c).DeleteRecord(session); err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to delete session", err)
 	}
 
@@ -371,93 +630,128 @@ func (h *SessionHandler) DeleteSession(c echo.Context) error {
 // UploadGPXTrack uploads and processes a GPX file for a session
 //
 //	@Summary		Upload GPX track
-//	@Description	Uploads a GPX file to a session and processes track points and waypoints
+//	@Description	Uploads a GPX file to a session and processes track points and waypoints. With as_recorded the timed track points are stored in one transaction as recorded locations of the session, like tracked points, instead of as its planned track; points without a time or at the time of a stored location of the session are skipped, and the file is not kept. An activity with timestamps that overlaps the recorded track of another session of the user is rejected with 409 listing the overlapping sessions, unless confirm_duplicate is set.
 //	@Tags			Sessions
 //	@Accept			multipart/form-data
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			username	path		string	true	"Username"
-//	@Param			name		path		string	true	"Session name"
-//	@Param			gpx_file	formData	file	true	"GPX file to upload"
-//	@Success		200			{object}	models.SuccessResponse	"GPX track uploaded successfully"
-//	@Failure		400			{object}	models.ErrorResponse		"Invalid request or file"
-//	@Failure		401			{object}	models.ErrorResponse		"Authentication required"
-//	@Failure		403			{object}	models.ErrorResponse		"Forbidden"
-//	@Failure		404			{object}	models.ErrorResponse		"Session not found"
+//	@Param			username			path		string	true	"Username"
+//	@Param			name				path		string	true	"Session name"
+//	@Param			gpx_file			formData	file	true	"GPX file to upload"
+//	@Param			draft				formData	bool	false	"Keep the session a draft until it is published"
+//	@Param			confirm_duplicate	formData	bool	false	"Import the activity although it overlaps other sessions"
+//	@Param			lenient				formData	bool	false	"Read files that are not quite valid XML or GPX, e.g. from older devices, and list the repairs in warnings"
+//	@Param			as_recorded			query		bool	false	"Store the points as recorded locations with their original times, heart rate and speed instead of as the planned track; also accepted as form field"
+//	@Param			simplify			formData	bool	false	"Simplify the planned track (default: instance setting)"
+//	@Param			max_points			formData	int		false	"Most points of the simplified plan, 0 for no limit (default: instance setting)"
+//	@Param			epsilon_multiplier	formData	number	false	"Scales the simplification tolerance (default: instance setting)"
+//	@Success		200					{object}	models.SuccessResponse				"GPX track uploaded successfully"
+//	@Failure		400					{object}	models.ErrorResponse				"Invalid request or file"
+//	@Failure		401					{object}	models.ErrorResponse				"Authentication required"
+//	@Failure		403					{object}	models.ErrorResponse				"Forbidden"
+//	@Failure		404					{object}	models.ErrorResponse				"Session not found"
+//	@Failure		409					{object}	models.DuplicateActivityResponse	"The activity overlaps other sessions"
 //	@Router			/sessions/{username}/{name}/gpx [post]
 func (h *SessionHandler) UploadGPXTrack(c echo.Context) error {
-	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
-	if record == nil {
-		return apis.NewUnauthorizedError("Authentication required", nil)
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
 	}
 
-	username := c.PathParam("username")
-	sessionName := c.PathParam("name")
-
-	// Verify user matches authenticated user
-	if record.Username() != username {
+	// Verify the authenticated user may modify this user's sessions
+	if !canAccess(c, constants.PermSessionsWrite, user.Id) {
 		return apis.NewForbiddenError("Cannot modify another user's sessions", nil)
 	}
 
 	// Find the session
-	session, err := findSessionByNameAndUser(h.app.Dao(), sessionName, record.Id)
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
 	if err != nil {
 		return apis.NewNotFoundError("Session not found", err)
 	}
 
 	// Get the uploaded file
-	file, fileHeader, err := c.Request().FormFile("gpx_file")
+	req := middleware.GetValidatedData(c).(*appmodels.UploadGPXTrackRequest)
+	fileHeader := req.GPXFile
+	file, err := fileHeader.Open()
 	if err != nil {
-		return apis.NewBadRequestError("No GPX file provided", err)
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to read GPX file", err)
 	}
 	defer file.Close()
 
 	// Validate file type
-	if !isValidGPXFile(fileHeader.Filename, fileHeader.Header.Get("Content-Type")) {
+	if !isValidGPXFile(fileHeader) {
 		return apis.NewBadRequestError("Invalid file type. Please upload a GPX file", nil)
 	}
 
 	// Parse the GPX file
-	gpxData, err := utils.ParseGPX(file)
+	gpxData, err := utils.GPXParseOptions{Lenient: req.Lenient}.Parse(file)
 	if err != nil {
-		return apis.NewBadRequestError(fmt.Sprintf("Failed to parse GPX file: %v", err), err)
+		message := fmt.Sprintf("Failed to parse GPX file: %v", err)
+		if !req.Lenient {
+			message += "; upload with lenient=true to read it anyway"
+		}
+		return apis.NewBadRequestError(message, err)
 	}
 
-	// Reset file reader for storage
-	file.Seek(0, 0)
-
-	// Store the original GPX file
-	fs, err := h.app.NewFilesystem()
-	if err != nil {
-		return apis.NewApiError(http.StatusInternalServerError, "Failed to initialize filesystem", err)
+	// A recorded activity covering the track of another session would be counted twice
+	if !req.ConfirmDuplicate {
+		duplicates, err := findDuplicateSessions(requestDao(h.app, c), session, gpxData.TrackPoints)
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to check for duplicate activities", err)
+		}
+		if len(duplicates) > 0 {
+			return c.JSON(http.StatusConflict, appmodels.DuplicateActivityResponse{
+				Code:       http.StatusConflict,
+				Message:    "The activity overlaps existing sessions, upload again with confirm_duplicate=true to import it anyway",
+				Duplicates: duplicates,
+			})
+		}
 	}
-	defer fs.Close()
 
-	// Generate unique file key
-	gpxFileName := session.Id + "_" + fileHeader.Filename
+	if req.AsRecorded || c.QueryParam("as_recorded") == "true" {
+		return h.uploadRecordedGPXTrack(c, session, req, gpxData)
+	}
 
-	err = fs.UploadMultipart(fileHeader, gpxFileName)
+	// Store the original GPX file in the session's file field, which replaces the previous upload
+	gpxFile, err := filesystem.NewFileFromMultipart(fileHeader)
 	if err != nil {
-		return apis.NewApiError(http.StatusInternalServerError, "Failed to upload GPX file", err)
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to read GPX file", err)
 	}
 
-	// Update session with GPX data
-	session.Set("gpx_track", gpxFileName)
+	// Update session with GPX data; an imported session can be kept a draft for review. Older
+	// uploads are stored outside of the file field and deleted here instead of by PocketBase.
+	legacyGPXKey := utils.LegacyGPXTrackKey(session.Id, session.GetString("gpx_track"))
+	if legacyGPXKey != "" {
+		session.Set("gpx_track", "")
+	}
 	session.Set("track_name", gpxData.TrackName)
 	session.Set("track_description", gpxData.TrackDescription)
+	session.Set("planned_tracks", plannedTracks(gpxData.Tracks))
+	if req.Draft {
+		session.Set("draft", true)
+	}
 
-	if err := h.app.Dao().SaveRecord(session); err != nil {
+	form := forms.NewRecordUpsert(h.app, session)
+	form.SetDao(requestDao(h.app, c))
+	if err := form.AddFiles("gpx_track", gpxFile); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to upload GPX file", err)
+	}
+	if err := form.Submit(); err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to update session", err)
 	}
 
+	if legacyGPXKey != "" {
+		h.deleteLegacyGPXFile(legacyGPXKey)
+	}
+
 	// Process track points
-	trackPointsCount, err := h.processGPXTrackPoints(session.Id, gpxData.TrackPoints)
+	trackPointsCount, simplifiedCount, err := h.processGPXTrackPoints(requestDao(h.app, c), session.Id, gpxData.TrackPoints, h.uploadSimplification(req))
 	if err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to process track points", err)
 	}
 
 	// Process waypoints
-	waypointsCount, err := h.processGPXWaypoints(session.Id, gpxData.Waypoints)
+	waypointsCount, err := h.processGPXWaypoints(requestDao(h.app, c), session.Id, session.GetString("user"), gpxData.Waypoints)
 	if err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to process waypoints", err)
 	}
@@ -467,21 +761,230 @@ func (h *SessionHandler) UploadGPXTrack(c echo.Context) error {
 		"track_name":        gpxData.TrackName,
 		"track_description": gpxData.TrackDescription,
 		"track_points":      trackPointsCount,
+		"simplified_points": simplifiedCount,
 		"waypoints":         waypointsCount,
+		"draft":             session.GetBool("draft"),
+		"warnings":          gpxData.Warnings,
 	}
 
 	return utils.SendSuccess(c, http.StatusOK, response, "GPX track uploaded successfully")
 }
 
+// uploadRecordedGPXTrack stores the timed points of a GPX file as the recorded locations of a
+// session, together with its waypoints in one transaction
+func (h *SessionHandler) uploadRecordedGPXTrack(c echo.Context, session *models.Record, req *appmodels.UploadGPXTrackRequest, gpxData *utils.ParsedGPXData) error {
+	untimed := 0
+	for _, point := range gpxData.TrackPoints {
+		if point.Time.IsZero() {
+			untimed++
+		}
+	}
+	if untimed == len(gpxData.TrackPoints) {
+		return apis.NewBadRequestError("The GPX file has no timed track points; upload it without as_recorded to import it as planned track", nil)
+	}
+	warnings := gpxData.Warnings
+	if untimed > 0 {
+		warnings = append(warnings, fmt.Sprintf("Skipped %d track points without a time", untimed))
+	}
+
+	var recordedCount, skippedCount, waypointsCount int
+	err := requestDao(h.app, c).RunInTransaction(func(txDao *daos.Dao) error {
+		var err error
+		recordedCount, skippedCount, err = h.processRecordedPoints(txDao, session, gpxData.TrackPoints)
+		if err != nil {
+			return err
+		}
+		waypointsCount, err = h.processGPXWaypoints(txDao, session.Id, session.GetString("user"), gpxData.Waypoints)
+		if err != nil {
+			return err
+		}
+
+		if req.Draft && !session.GetBool("draft") {
+			session.Set("draft", true)
+			if err := txDao.SaveRecord(session); err != nil {
+				return err
+			}
+		}
+		return recomputeSessionStats(txDao, session, h.gapThresholds)
+	})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to import recorded points", err)
+	}
+
+	response := map[string]interface{}{
+		"message":         "GPX track imported as recorded",
+		"recorded_points": recordedCount,
+		"skipped_points":  skippedCount, // At the time of a location the session already has
+		"waypoints":       waypointsCount,
+		"draft":           session.GetBool("draft"),
+		"warnings":        warnings,
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, response, "GPX track imported as recorded")
+}
+
+// ReprocessGPXTrack parses the stored GPX file of a session again
+//
+//	@Summary		Reprocess GPX track
+//	@Description	Parses the GPX file stored with a session again, e.g. after a parser improvement, and replaces its planned track points, simplified with the current settings, and the waypoints imported from GPX. Other waypoints are kept; the track and waypoints are replaced together or not at all.
+//	@Tags			Sessions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Param			lenient		query		bool	false	"Read files that are not quite valid XML or GPX and list the repairs in warnings"
+//	@Success		200			{object}	models.SuccessResponse	"GPX track reprocessed successfully"
+//	@Failure		400			{object}	models.ErrorResponse	"Stored GPX file cannot be parsed"
+//	@Failure		401			{object}	models.ErrorResponse	"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse	"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse	"Session or GPX file not found"
+//	@Router			/sessions/{username}/{name}/gpx/reprocess [post]
+func (h *SessionHandler) ReprocessGPXTrack(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	// Verify the authenticated user may modify this user's sessions
+	if !canAccess(c, constants.PermSessionsWrite, user.Id) {
+		return apis.NewForbiddenError("Cannot modify another user's sessions", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+	if session.GetString("gpx_track") == "" {
+		return apis.NewNotFoundError("Session has no GPX file", nil)
+	}
+
+	gpxData, err := h.parseStoredGPXTrack(session, utils.GPXParseOptions{Lenient: c.QueryParam("lenient") == "true"})
+	if err != nil {
+		return err
+	}
+
+	var trackPointsCount, simplifiedCount, waypointsCount int
+	err = requestDao(h.app, c).RunInTransaction(func(txDao *daos.Dao) error {
+		session.Set("track_name", gpxData.TrackName)
+		session.Set("track_description", gpxData.TrackDescription)
+		session.Set("planned_tracks", plannedTracks(gpxData.Tracks))
+		if err := txDao.SaveRecord(session); err != nil {
+			return err
+		}
+
+		imported, err := txDao.FindRecordsByFilter(constants.CollectionWaypoints,
+			"session_id = {:session} && source = 'gpx'", "", 0, 0, dbx.Params{"session": session.Id})
+		if err != nil {
+			return err
+		}
+		for _, waypoint := range imported {
+			if err := txDao.DeleteRecord(waypoint); err != nil {
+				return err
+			}
+		}
+
+		trackPointsCount, simplifiedCount, err = h.processGPXTrackPoints(txDao, session.Id, gpxData.TrackPoints, h.simplification)
+		if err != nil {
+			return err
+		}
+		waypointsCount, err = h.processGPXWaypoints(txDao, session.Id, session.GetString("user"), gpxData.Waypoints)
+		return err
+	})
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to reprocess GPX track", err)
+	}
+
+	response := map[string]interface{}{
+		"track_name":        gpxData.TrackName,
+		"track_description": gpxData.TrackDescription,
+		"track_points":      trackPointsCount,
+		"simplified_points": simplifiedCount,
+		"waypoints":         waypointsCount,
+		"warnings":          gpxData.Warnings,
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, response, "GPX track reprocessed successfully")
+}
+
+// parseStoredGPXTrack reads and parses the GPX file stored with a session
+func (h *SessionHandler) parseStoredGPXTrack(session *models.Record, options utils.GPXParseOptions) (*utils.ParsedGPXData, error) {
+	fs, err := h.app.NewFilesystem()
+	if err != nil {
+		return nil, apis.NewApiError(http.StatusInternalServerError, "Failed to initialize filesystem", err)
+	}
+	defer fs.Close()
+
+	file, err := fs.GetFile(signedFileKey(fs, session, "gpx_track"))
+	if err != nil {
+		return nil, apis.NewNotFoundError("GPX file not found", err)
+	}
+	defer file.Close()
+
+	gpxData, err := options.Parse(file)
+	if err != nil {
+		message := fmt.Sprintf("Failed to parse GPX file: %v", err)
+		if !options.Lenient {
+			message += "; reprocess with lenient=true to read it anyway"
+		}
+		return nil, apis.NewBadRequestError(message, err)
+	}
+	return gpxData, nil
+}
+
+// DownloadGPXTrack serves the GPX file uploaded to a session
+//
+//	@Summary		Download GPX track
+//	@Description	Downloads the GPX file uploaded to a session as it was uploaded, named after the session
+//	@Tags			Sessions
+//	@Produce		application/gpx+xml
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Success		200			{file}		file					"GPX file"
+//	@Failure		403			{object}	models.ErrorResponse	"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse	"Session or GPX file not found"
+//	@Router			/sessions/{username}/{name}/gpx [get]
+func (h *SessionHandler) DownloadGPXTrack(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	if !hasSessionAccess(c, session) {
+		return apis.NewForbiddenError("Access denied", nil)
+	}
+
+	if session.GetString("gpx_track") == "" {
+		return apis.NewNotFoundError("Session has no GPX file", nil)
+	}
+
+	fs, err := h.app.NewFilesystem()
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to initialize filesystem", err)
+	}
+	defer fs.Close()
+
+	// Access follows the session's visibility, which can change at any time
+	c.Response().Header().Set("Cache-Control", "private, no-cache")
+	if err := fs.Serve(c.Response(), c.Request(), signedFileKey(fs, session, "gpx_track"), session.GetString("name")+".gpx"); err != nil {
+		return apis.NewNotFoundError("GPX file not found", err)
+	}
+	return nil
+}
+
 // GetTrackData retrieves the planned track points for a session
 //
 //	@Summary		Get session track data
-//	@Description	Returns the planned track points from uploaded GPX for a session
+//	@Description	Returns the planned track points from uploaded GPX for a session with the categorized climbs along the route. Every point names its track and segment; tracks lists the tracks and routes of the file, in the order of the points, with the sequence range of each segment.
 //	@Tags			Sessions
 //	@Produce		json
 //	@Param			username	path		string	true	"Username"
 //	@Param			name		path		string	true	"Session name"
-//	@Param			simplified	query		bool	false	"Return simplified track (default: true)"
+//	@Param			simplified	query		bool	false	"Return the simplified plan, or the full-resolution one with false (default: true)"
 //	@Success		200			{object}	models.SuccessResponse	"Track data retrieved successfully"
 //	@Failure		404			{object}	models.ErrorResponse		"Session or track not found"
 //	@Router			/sessions/{username}/{name}/track [get]
@@ -490,26 +993,19 @@ func (h *SessionHandler) GetTrackData(c echo.Context) error {
 	sessionName := c.PathParam("name")
 
 	// Find user
-	user, err := findUserByUsername(h.app.Dao(), username)
+	user, err := findUserByUsername(requestDao(h.app, c), username)
 	if err != nil {
 		return apis.NewNotFoundError("User not found", err)
 	}
 
 	// Find session
-	session, err := findSessionByNameAndUser(h.app.Dao(), sessionName, user.Id)
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), sessionName, user.Id)
 	if err != nil {
 		return apis.NewNotFoundError("Session not found", err)
 	}
 
-	// Check access: allow if public, or if owner, or if valid share_token
-	authRecord, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
-	isOwner := authRecord != nil && authRecord.Id == user.Id
-	isPublic := session.GetBool("public")
-	shareToken := c.QueryParam("share_token")
-	storedShareToken := session.GetString("share_token")
-	hasValidShareToken := shareToken != "" && storedShareToken != "" && shareToken == storedShareToken
-
-	if !isPublic && !isOwner && !hasValidShareToken {
+	// Check access: allow if public, readable by the requester, or if valid share_token
+	if !hasSessionAccess(c, session) {
 		return apis.NewForbiddenError("Access denied", nil)
 	}
 
@@ -519,19 +1015,16 @@ func (h *SessionHandler) GetTrackData(c echo.Context) error {
 		simplified = false
 	}
 
-	// Get track points
-	var orderBy string
+	// Get track points; the full-resolution plan holds the simplified one
+	filter := "session_id = {:session_id}"
 	if simplified {
-		// For simplified tracks, we might want to add a simplified flag in the future
-		orderBy = "sequence ASC"
-	} else {
-		orderBy = "sequence ASC"
+		filter += " && simplified = true"
 	}
 
-	trackPoints, err := h.app.Dao().FindRecordsByFilter(
+	trackPoints, err := requestDao(h.app, c).FindRecordsByFilter(
 		"gpx_tracks",
-		"session_id = {:session_id}",
-		orderBy,
+		filter,
+		"sequence",
 		0, 0, // No limit
 		dbx.Params{"session_id": session.Id},
 	)
@@ -542,15 +1035,19 @@ func (h *SessionHandler) GetTrackData(c echo.Context) error {
 
 	// Format track points
 	points := make([]map[string]interface{}, len(trackPoints))
+	routePoints := make([]utils.TimedPoint, len(trackPoints))
 	for i, point := range trackPoints {
 		pointData := map[string]interface{}{
 			"latitude":  point.GetFloat("latitude"),
 			"longitude": point.GetFloat("longitude"),
 			"sequence":  point.GetInt("sequence"),
+			"track":     point.GetInt("track"),
+			"segment":   point.GetInt("segment"),
 		}
 
-		if altitude := point.GetFloat("altitude"); altitude != 0 {
-			pointData["altitude"] = altitude
+		routePoints[i] = utils.TimedPoint{Latitude: point.GetFloat("latitude"), Longitude: point.GetFloat("longitude"), Altitude: utils.RecordAltitude(point)}
+		if altitude := routePoints[i].Altitude; altitude != nil {
+			pointData["altitude"] = *altitude
 		}
 
 		points[i] = pointData
@@ -562,34 +1059,406 @@ func (h *SessionHandler) GetTrackData(c echo.Context) error {
 		"track_description": session.GetString("track_description"),
 		"track_points":      points,
 		"point_count":       len(points),
+		"tracks":            summarizePlannedTracks(session, trackPoints),
+		"climbs":            utils.DetectClimbs(routePoints),
 	}
 
 	return utils.SendSuccess(c, http.StatusOK, response, "Track data retrieved successfully")
 }
 
-// processGPXTrackPoints saves track points to the database with optional simplification
-func (h *SessionHandler) processGPXTrackPoints(sessionID string, points []utils.ParsedTrackPoint) (int, error) {
-	if len(points) == 0 {
-		return 0, nil
+// GetSessionProgress returns the waypoint progress of a session
+//
+//	@Summary		Get session progress
+//	@Description	Returns visited and remaining waypoint counts and the next expected waypoint of a session. Live sessions also get the sunrise and sunset at the latest position, the daylight remaining and how far the current pace gets before sunset.
+//	@Tags			Sessions
+//	@Produce		json
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Param			share_token	query		string	false	"Share token for private sessions"
+//	@Param			guest_token	query		string	false	"Guest viewer token"
+//	@Success		200			{object}	models.SuccessResponse{data=models.SessionProgressResponse}	"Session progress retrieved successfully"
+//	@Failure		403			{object}	models.ErrorResponse										"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse										"Session not found"
+//	@Router			/sessions/{username}/{name}/progress [get]
+func (h *SessionHandler) GetSessionProgress(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	// Check access: allow if public, or if owner, or if valid share_token
+	if !hasSessionAccess(c, session) {
+		return apis.NewForbiddenError("Access denied", nil)
+	}
+
+	// Waypoints hidden by moderation only count for viewers who can read them
+	filter := "session_id = {:session_id}"
+	if !canAccess(c, constants.PermWaypointsRead, session.GetString("user")) {
+		filter += " && hidden = false"
+	}
+	waypoints, err := requestDao(h.app, c).FindRecordsByFilter(
+		"waypoints",
+		filter,
+		constants.WaypointOrderSort,
+		0, 0,
+		dbx.Params{"session_id": session.Id},
+	)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch waypoints", err)
+	}
+
+	progress := appmodels.SessionProgressResponse{
+		SessionID:      session.Id,
+		TotalWaypoints: len(waypoints),
+	}
+
+	// Waypoints are already in order, so the first unvisited one is the next expected waypoint
+	for _, waypoint := range waypoints {
+		if !waypoint.GetDateTime("visited_at").IsZero() {
+			progress.VisitedWaypoints++
+			continue
+		}
+		if progress.NextWaypoint == nil {
+			progress.NextWaypoint = recordToWaypoint(h.app, waypoint)
+		}
 	}
+	progress.RemainingWaypoints = progress.TotalWaypoints - progress.VisitedWaypoints
+
+	if session.GetDateTime("ended_at").IsZero() {
+		recent, err := requestDao(h.app, c).FindRecordsByFilter(
+			constants.CollectionLocations,
+			"session = {:session}",
+			"-timestamp",
+			constants.ETARecentPoints, 0,
+			dbx.Params{"session": session.Id},
+		)
+		if err != nil {
+			return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch latest locations", err)
+		}
 
-	// Apply simplification for long tracks
-	simplifiedPoints := points
-	if len(points) > 100 {
-		epsilon := utils.CalculateSimplificationEpsilon(points)
-		if epsilon > 0 {
-			simplifiedPoints = utils.SimplifyTrack(points, epsilon)
+		// Oldest first, as the estimate expects
+		for i, j := 0, len(recent)-1; i < j; i, j = i+1, j-1 {
+			recent[i], recent[j] = recent[j], recent[i]
 		}
+		progress.Daylight = utils.EstimateDaylight(locationsToTimedPoints(recent), time.Now(), progress.NextWaypoint)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, progress, "Session progress retrieved successfully")
+}
+
+// GetSessionReplay returns keyframed positions for animating a session playback
+//
+//	@Summary		Get session replay
+//	@Description	Returns tracked positions resampled to one frame per playback second, where each second covers `speed` seconds of recorded time
+//	@Tags			Sessions
+//	@Produce		json
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Param			speed		query		int		false	"Time compression factor (default: 60, max: 86400)"
+//	@Param			share_token	query		string	false	"Share token for private sessions"
+//	@Param			guest_token	query		string	false	"Guest viewer token"
+//	@Success		200			{object}	models.SuccessResponse{data=models.SessionReplayResponse}	"Session replay retrieved successfully"
+//	@Failure		400			{object}	models.ErrorResponse										"Invalid speed"
+//	@Failure		403			{object}	models.ErrorResponse										"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse										"Session not found"
+//	@Router			/sessions/{username}/{name}/replay [get]
+func (h *SessionHandler) GetSessionReplay(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	speed := constants.DefaultReplaySpeed
+	if speedStr := c.QueryParam("speed"); speedStr != "" {
+		s, err := strconv.Atoi(speedStr)
+		if err != nil || s < 1 || s > constants.MaxReplaySpeed {
+			return apis.NewBadRequestError(fmt.Sprintf("speed must be an integer between 1 and %d", constants.MaxReplaySpeed), err)
+		}
+		speed = s
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	if !hasSessionAccess(c, session) {
+		return apis.NewForbiddenError("Access denied", nil)
+	}
+
+	records, err := requestDao(h.app, c).FindRecordsByFilter(
+		"locations",
+		"session = {:session}",
+		"timestamp",
+		0, 0,
+		dbx.Params{"session": session.Id},
+	)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch session data", err)
+	}
+
+	if len(records) == 0 {
+		return apis.NewNotFoundError("No locations found for this session", nil)
+	}
+
+	points := locationsToTimedPoints(records)
+
+	frames, err := utils.ResampleTrack(points, time.Duration(speed)*time.Second, constants.MaxReplayFrames)
+	if err != nil {
+		return apis.NewBadRequestError("Session is too long for this speed, use a higher speed", err)
+	}
+
+	replay := appmodels.SessionReplayResponse{
+		SessionID: session.Id,
+		Speed:     speed,
+		StartTime: points[0].Timestamp.Unix(),
+		EndTime:   points[len(points)-1].Timestamp.Unix(),
+		Frames:    frames,
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, replay, "")
+}
+
+// GetCheckIn returns the missed check-in alert state of a session
+//
+//	@Summary		Get session check-in
+//	@Description	Returns whether missed check-in alerts are armed for the session, the deadline of the next check-in and the current alert level
+//	@Tags			Sessions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Success		200			{object}	models.SuccessResponse{data=models.CheckInStatus}	"Check-in retrieved successfully"
+//	@Failure		401			{object}	models.ErrorResponse								"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse								"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse								"Session not found"
+//	@Router			/sessions/{username}/{name}/checkin [get]
+func (h *SessionHandler) GetCheckIn(c echo.Context) error {
+	session, err := h.findOwnSession(c)
+	if err != nil {
+		return err
+	}
+
+	status, err := h.checkIns.Status(session)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, status, "")
+}
+
+// ArmCheckIn arms missed check-in alerts for a session
+//
+//	@Summary		Arm session check-in
+//	@Description	Expects a location at least every interval_minutes. When none arrives in time the owner is emailed, after another interval the listed and the verified profile emergency contacts are alerted with the last known position and a map link.
+//	@Tags			Sessions
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string						true	"Username"
+//	@Param			name		path		string						true	"Session name"
+//	@Param			request		body		models.ArmCheckInRequest	true	"Check-in interval and emergency contacts"
+//	@Success		200			{object}	models.SuccessResponse{data=models.CheckInStatus}	"Check-in armed successfully"
+//	@Failure		400			{object}	models.ErrorResponse								"Invalid request or session ended"
+//	@Failure		401			{object}	models.ErrorResponse								"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse								"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse								"Session not found"
+//	@Router			/sessions/{username}/{name}/checkin [put]
+func (h *SessionHandler) ArmCheckIn(c echo.Context) error {
+	session, err := h.findOwnSession(c)
+	if err != nil {
+		return err
+	}
+
+	if !session.GetDateTime("ended_at").IsZero() {
+		return apis.NewBadRequestError("Cannot arm check-in for an ended session", nil)
+	}
+
+	// Get validated data from middleware
+	req, ok := middleware.GetValidatedData(c).(*appmodels.ArmCheckInRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	status, err := h.checkIns.Arm(session, *req)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, status, "Check-in armed successfully")
+}
+
+// DisarmCheckIn turns off missed check-in alerts for a session
+//
+//	@Summary		Disarm session check-in
+//	@Description	Turns off missed check-in alerts for the session
+//	@Tags			Sessions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Success		200			{object}	models.SuccessResponse	"Check-in disarmed successfully"
+//	@Failure		401			{object}	models.ErrorResponse		"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse		"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse		"Session not found"
+//	@Router			/sessions/{username}/{name}/checkin [delete]
+func (h *SessionHandler) DisarmCheckIn(c echo.Context) error {
+	session, err := h.findOwnSession(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.checkIns.Disarm(session); err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Check-in disarmed successfully")
+}
+
+// findOwnSession finds the session named in the path, which the authenticated user must be allowed to modify
+// MapMatchSession snaps a session track to roads
+//
+//	@Summary		Map match session track
+//	@Description	Snaps the session's locations to roads with the configured Valhalla or OSRM backend and stores the snapped positions next to the recorded ones, replacing an earlier match. Session data returns the snapped track with track=matched.
+//	@Tags			Sessions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Success		200			{object}	models.SuccessResponse{data=models.MapMatchResponse}	"Session track map matched successfully"
+//	@Failure		400			{object}	models.ErrorResponse									"Session has no locations"
+//	@Failure		401			{object}	models.ErrorResponse									"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse									"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse									"Session not found"
+//	@Failure		502			{object}	models.ErrorResponse									"Map matching backend unavailable"
+//	@Failure		503			{object}	models.ErrorResponse									"Map matching not configured"
+//	@Router			/sessions/{username}/{name}/map-match [post]
+func (h *SessionHandler) MapMatchSession(c echo.Context) error {
+	session, err := h.findOwnSession(c)
+	if err != nil {
+		return err
+	}
+
+	if !h.mapMatch.Enabled() {
+		return apis.NewApiError(http.StatusServiceUnavailable, "Map matching is not configured", nil)
+	}
+
+	result, err := h.mapMatch.MatchSession(session)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, result, "Session track map matched successfully")
+}
+
+func (h *SessionHandler) findOwnSession(c echo.Context) (*models.Record, error) {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return nil, apis.NewNotFoundError("User not found", nil)
+	}
+
+	if !canAccess(c, constants.PermSessionsWrite, user.Id) {
+		return nil, apis.NewForbiddenError("Cannot modify another user's sessions", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
+	if err != nil {
+		return nil, apis.NewNotFoundError("Session not found", err)
+	}
+	return session, nil
+}
+
+// findDuplicateSessions returns the other sessions of the session owner that recorded a similar
+// track during the time of an imported activity. Tracks without times are planned routes, they
+// never duplicate an activity.
+func findDuplicateSessions(dao *daos.Dao, session *models.Record, points []utils.ParsedTrackPoint) ([]appmodels.DuplicateSession, error) {
+	var imported []utils.TimedPoint
+	for _, point := range points {
+		if !point.Time.IsZero() {
+			imported = append(imported, utils.TimedPoint{Timestamp: point.Time, Latitude: point.Latitude, Longitude: point.Longitude})
+		}
+	}
+	if len(imported) < constants.DuplicateMinPoints {
+		return nil, nil
+	}
+	sort.SliceStable(imported, func(i, j int) bool { return imported[i].Timestamp.Before(imported[j].Timestamp) })
+
+	records, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+		"user = {:user} && session != '' && session != {:session} && timestamp >= {:from} && timestamp <= {:to}",
+		"timestamp", 0, 0, dbx.Params{
+			"user":    session.GetString("user"),
+			"session": session.Id,
+			"from":    imported[0].Timestamp.Add(-constants.DuplicateMaxTimeOffset).UTC().Format(types.DefaultDateLayout),
+			"to":      imported[len(imported)-1].Timestamp.Add(constants.DuplicateMaxTimeOffset).UTC().Format(types.DefaultDateLayout),
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	expandLocationSessions(dao, records...)
+
+	var names []string
+	recorded := map[string][]utils.TimedPoint{}
+	for _, record := range records {
+		name := utils.LocationSessionName(record)
+		if _, ok := recorded[name]; !ok {
+			names = append(names, name)
+		}
+		recorded[name] = append(recorded[name], utils.TimedPoint{
+			Timestamp: record.GetDateTime("timestamp").Time(),
+			Latitude:  record.GetFloat("latitude"),
+			Longitude: record.GetFloat("longitude"),
+		})
+	}
+
+	duplicates := []appmodels.DuplicateSession{}
+	for _, name := range names {
+		overlap := utils.CompareActivities(imported, recorded[name], constants.DuplicateMaxTimeOffset, constants.DuplicateMaxDistance)
+		if overlap == nil || overlap.Points < constants.DuplicateMinPoints || overlap.Similarity < constants.DuplicateMinSimilarity {
+			continue
+		}
+
+		duplicate := appmodels.DuplicateSession{
+			Name:       name,
+			Start:      overlap.Start,
+			End:        overlap.End,
+			Similarity: math.Round(overlap.Similarity*100) / 100,
+		}
+		if other, err := findSessionByNameAndUser(dao, name, session.GetString("user")); err == nil {
+			duplicate.ID = other.Id
+			duplicate.Title = other.GetString("title")
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+	return duplicates, nil
+}
+
+// processGPXTrackPoints saves the track points of a session's plan at full resolution, flagging
+// the points kept by the simplified plan. It returns the number of saved and simplified points.
+func (h *SessionHandler) processGPXTrackPoints(dao *daos.Dao, sessionID string, points []utils.ParsedTrackPoint, simplification utils.SimplificationOptions) (int, int, error) {
+	if len(points) == 0 {
+		return 0, 0, nil
+	}
+
+	simplifiedPoints := simplification.Simplify(points)
+	simplified := make(map[int]bool, len(simplifiedPoints))
+	for _, point := range simplifiedPoints {
+		simplified[point.Sequence] = true
 	}
 
 	// Get the gpx_tracks collection
-	collection, err := h.app.Dao().FindCollectionByNameOrId("gpx_tracks")
+	collection, err := dao.FindCollectionByNameOrId("gpx_tracks")
 	if err != nil {
-		return 0, fmt.Errorf("gpx_tracks collection not found: %v", err)
+		return 0, 0, fmt.Errorf("gpx_tracks collection not found: %v", err)
 	}
 
 	// Delete existing track points for this session
-	existingPoints, err := h.app.Dao().FindRecordsByFilter(
+	existingPoints, err := dao.FindRecordsByFilter(
 		"gpx_tracks",
 		"session_id = {:session_id}",
 		"",
@@ -598,47 +1467,187 @@ func (h *SessionHandler) processGPXTrackPoints(sessionID string, points []utils.
 	)
 	if err == nil {
 		for _, point := range existingPoints {
-			h.app.Dao().DeleteRecord(point)
+			dao.DeleteRecord(point)
 		}
 	}
 
 	// Create new track point records
-	for _, point := range simplifiedPoints {
+	for _, point := range points {
 		record := models.NewRecord(collection)
 		record.Set("session_id", sessionID)
 		record.Set("latitude", point.Latitude)
 		record.Set("longitude", point.Longitude)
 		record.Set("sequence", point.Sequence)
+		record.Set("track", point.Track)
+		record.Set("segment", point.Segment)
+		record.Set("simplified", simplified[point.Sequence])
+		utils.SetRecordAltitude(record, point.Altitude)
+
+		if err := dao.SaveRecord(record); err != nil {
+			return 0, 0, fmt.Errorf("failed to save track point: %v", err)
+		}
+	}
+
+	return len(points), len(simplifiedPoints), nil
+}
 
+// processRecordedPoints saves the timed track points of a GPX file as locations of a session,
+// with the time, heart rate, speed, accuracy and sensor readings they were recorded with. Points at the time of a
+// location the session already has are skipped, so uploading a file again stores no duplicates. It
+// returns the number of saved and skipped points.
+func (h *SessionHandler) processRecordedPoints(dao *daos.Dao, session *models.Record, points []utils.ParsedTrackPoint) (int, int, error) {
+	collection, err := dao.FindCollectionByNameOrId(constants.CollectionLocations)
+	if err != nil {
+		return 0, 0, fmt.Errorf("locations collection not found: %v", err)
+	}
+
+	var stored []struct {
+		Timestamp types.DateTime `db:"timestamp"`
+	}
+	err = dao.DB().Select("timestamp").From(constants.CollectionLocations).
+		Where(dbx.HashExp{"session": session.Id}).All(&stored)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read recorded points: %v", err)
+	}
+	recorded := make(map[int64]bool, len(stored))
+	for _, location := range stored {
+		recorded[location.Timestamp.Time().UnixMilli()] = true
+	}
+
+	// Imported points are history: saving them without the model hooks keeps them out of live
+	// broadcasts, arrival checks, Home Assistant and plugins
+	historyDao := daos.New(dao.DB())
+
+	saved, skipped := 0, 0
+	for _, point := range points {
+		if point.Time.IsZero() {
+			continue
+		}
+		if recorded[point.Time.UnixMilli()] {
+			skipped++
+			continue
+		}
+		recorded[point.Time.UnixMilli()] = true
+
+		timestamp, err := types.ParseDateTime(point.Time)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid time of track point %d: %v", point.Sequence, err)
+		}
+
+		record := models.NewRecord(collection)
+		record.Set("user", session.GetString("user"))
+		record.Set("timestamp", timestamp)
+		record.Set("latitude", utils.RoundTo(point.Latitude, constants.CoordinatePrecision))
+		record.Set("longitude", utils.RoundTo(point.Longitude, constants.CoordinatePrecision))
 		if point.Altitude != nil {
-			record.Set("altitude", *point.Altitude)
+			altitude := utils.RoundTo(*point.Altitude, constants.AltitudePrecision)
+			utils.SetRecordAltitude(record, &altitude)
+		} else {
+			utils.SetRecordAltitude(record, nil)
+		}
+		utils.SetRecordSpeed(record, point.Speed)
+		if point.HeartRate != nil {
+			record.Set("heart_rate", *point.HeartRate)
+		}
+		utils.SetRecordSensors(record, point.Cadence, point.Power, point.Temperature)
+		if point.Accuracy != nil {
+			record.Set("accuracy", *point.Accuracy)
+		}
+		setLocationSession(record, session)
+
+		if err := historyDao.SaveRecord(record); err != nil {
+			return 0, 0, fmt.Errorf("failed to save recorded point: %v", err)
+		}
+		saved++
+	}
+
+	return saved, skipped, nil
+}
+
+// plannedTracks returns the names and kinds of the tracks and routes of a GPX file, as stored in
+// the planned_tracks of its session
+func plannedTracks(tracks []utils.ParsedTrack) []appmodels.PlannedTrack {
+	planned := make([]appmodels.PlannedTrack, len(tracks))
+	for i, track := range tracks {
+		planned[i] = appmodels.PlannedTrack{Name: track.Name, Description: track.Description, Type: constants.PlannedTrackTypeTrack}
+		if track.Route {
+			planned[i].Type = constants.PlannedTrackTypeRoute
+		}
+	}
+	return planned
+}
+
+// summarizePlannedTracks returns the tracks and routes of a session's plan with the segments of
+// its points, which are ordered by sequence. Plans stored before tracks were told apart are a
+// single track named after the session's track.
+func summarizePlannedTracks(session *models.Record, points []*models.Record) []appmodels.PlannedTrackSummary {
+	var planned []appmodels.PlannedTrack
+	_ = session.UnmarshalJSONField("planned_tracks", &planned)
+
+	summaries := []appmodels.PlannedTrackSummary{}
+	for _, point := range points {
+		track, segment, sequence := point.GetInt("track"), point.GetInt("segment"), point.GetInt("sequence")
+
+		if len(summaries) == 0 || summaries[len(summaries)-1].Track != track {
+			summary := appmodels.PlannedTrackSummary{
+				PlannedTrack: appmodels.PlannedTrack{Name: session.GetString("track_name"), Type: constants.PlannedTrackTypeTrack},
+				Track:        track,
+				Segments:     []appmodels.PlannedTrackSegment{},
+			}
+			if track < len(planned) {
+				summary.PlannedTrack = planned[track]
+			}
+			summaries = append(summaries, summary)
 		}
 
-		if err := h.app.Dao().SaveRecord(record); err != nil {
-			return 0, fmt.Errorf("failed to save track point: %v", err)
+		summary := &summaries[len(summaries)-1]
+		if len(summary.Segments) == 0 || summary.Segments[len(summary.Segments)-1].Segment != segment {
+			summary.Segments = append(summary.Segments, appmodels.PlannedTrackSegment{Segment: segment, FirstSequence: sequence})
 		}
+		current := &summary.Segments[len(summary.Segments)-1]
+		current.LastSequence = sequence
+		current.PointCount++
 	}
 
-	return len(simplifiedPoints), nil
+	return summaries
+}
+
+// uploadSimplification returns the simplification options of a GPX upload: the handler's options
+// with the fields the upload sets
+func (h *SessionHandler) uploadSimplification(req *appmodels.UploadGPXTrackRequest) utils.SimplificationOptions {
+	options := h.simplification
+	if req.Simplify != nil {
+		options.Enabled = *req.Simplify
+	}
+	if req.MaxPoints != nil {
+		options.MaxPoints = *req.MaxPoints
+	}
+	if req.EpsilonMultiplier != nil {
+		options.EpsilonMultiplier = *req.EpsilonMultiplier
+	}
+	return options
 }
 
 // processGPXWaypoints saves waypoints from GPX to the database
-func (h *SessionHandler) processGPXWaypoints(sessionID string, waypoints []utils.ParsedWaypoint) (int, error) {
+func (h *SessionHandler) processGPXWaypoints(dao *daos.Dao, sessionID, userID string, waypoints []utils.ParsedWaypoint) (int, error) {
 	if len(waypoints) == 0 {
 		return 0, nil
 	}
 
 	// Get the waypoints collection
-	collection, err := h.app.Dao().FindCollectionByNameOrId("waypoints")
+	collection, err := dao.FindCollectionByNameOrId("waypoints")
 	if err != nil {
 		return 0, fmt.Errorf("waypoints collection not found: %v", err)
 	}
 
-	// Create waypoint records
+	// Create waypoint records, keeping the order in which they appear in the GPX file
+	order := nextWaypointOrder(dao, sessionID)
 	savedCount := 0
 	for _, wp := range waypoints {
 		record := models.NewRecord(collection)
+		record.Set("user", userID)
 		record.Set("session_id", sessionID)
+		record.Set("order", order)
 		record.Set("name", wp.Name)
 		record.Set("type", wp.Type)
 		record.Set("description", wp.Description)
@@ -646,41 +1655,36 @@ func (h *SessionHandler) processGPXWaypoints(sessionID string, waypoints []utils
 		record.Set("longitude", wp.Longitude)
 		record.Set("source", wp.Source)
 		record.Set("position_confidence", wp.PositionConfidence)
+		utils.SetRecordAltitude(record, wp.Altitude)
 
-		if wp.Altitude != nil {
-			record.Set("altitude", *wp.Altitude)
-		}
-
-		if err := h.app.Dao().SaveRecord(record); err != nil {
+		if err := dao.SaveRecord(record); err != nil {
 			// Log error but continue with other waypoints
 			continue
 		}
+		order++
 		savedCount++
 	}
 
 	return savedCount, nil
 }
 
-// isValidGPXFile checks if the uploaded file is a valid GPX file
-func isValidGPXFile(filename, contentType string) bool {
-	// Check file extension
-	if !strings.HasSuffix(strings.ToLower(filename), ".gpx") {
-		return false
-	}
-
-	// Check content type
-	validTypes := []string{
-		"application/gpx+xml",
-		"application/xml",
-		"text/xml",
-		"application/octet-stream", // Some browsers might send this
+// deleteLegacyGPXFile deletes a GPX upload stored at the root of the storage. Failures are logged
+// only, the file is reported by the orphan scan.
+func (h *SessionHandler) deleteLegacyGPXFile(key string) {
+	fs, err := h.app.NewFilesystem()
+	if err != nil {
+		utils.LogWarn().Err(err).Str("key", key).Msg("Failed to open file storage")
+		return
 	}
+	defer fs.Close()
 
-	for _, validType := range validTypes {
-		if contentType == validType {
-			return true
-		}
+	if err := fs.Delete(key); err != nil {
+		utils.LogWarn().Err(err).Str("key", key).Msg("Failed to delete replaced GPX file")
 	}
+}
 
-	return false
+// isValidGPXFile checks that the uploaded file is a GPX document by its content
+func isValidGPXFile(fileHeader *multipart.FileHeader) bool {
+	detected, err := utils.SniffMultipartFile(fileHeader)
+	return err == nil && detected == "application/gpx+xml"
 }
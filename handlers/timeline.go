@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/services"
+	"vibe-tracker/utils"
+)
+
+type TimelineHandler struct {
+	app           *pocketbase.PocketBase
+	timelines     *services.TimelineService
+	gapThresholds utils.GapThresholds
+}
+
+func NewTimelineHandler(app *pocketbase.PocketBase, timelines *services.TimelineService) *TimelineHandler {
+	return &TimelineHandler{
+		app:       app,
+		timelines: timelines,
+	}
+}
+
+// WithGapThresholds sets the limits beyond which positions are not interpolated between points
+func (h *TimelineHandler) WithGapThresholds(thresholds utils.GapThresholds) *TimelineHandler {
+	h.gapThresholds = thresholds
+	return h
+}
+
+// GetTimeline returns a user's daily timeline of stays and movements
+//
+//	@Summary		Get daily timeline
+//	@Description	Returns the stays and movements, with detected transport mode, of one day in the user's time zone. Timelines are computed from all of the user's locations by a background job; recent days may lag behind by up to an hour.
+//	@Tags			Timeline
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			date		query		string	false	"Day as YYYY-MM-DD (default: today in the user's time zone)"
+//	@Success		200			{object}	models.SuccessResponse{data=models.TimelineResponse}	"Timeline retrieved successfully"
+//	@Failure		400			{object}	models.ErrorResponse									"Invalid date"
+//	@Failure		401			{object}	models.ErrorResponse									"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse									"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse									"User not found"
+//	@Router			/users/{username}/timeline [get]
+func (h *TimelineHandler) GetTimeline(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	// Timelines reveal where a user lives and works, they are never public
+	if !canAccess(c, constants.PermSessionsRead, user.Id) {
+		return apis.NewForbiddenError("Cannot view another user's timeline", nil)
+	}
+
+	today := h.timelines.Today(user)
+	date := c.QueryParam("date")
+	if date == "" {
+		date = today
+	}
+	if _, err := time.Parse(constants.TimelineDateFormat, date); err != nil {
+		return apis.NewBadRequestError("date must be formatted as YYYY-MM-DD", err)
+	}
+	// Dates in the same format compare chronologically
+	if date > today {
+		return apis.NewBadRequestError("date must not be in the future", nil)
+	}
+
+	timeline, err := h.timelines.Get(user, date)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, timeline, "")
+}
+
+// GetPositionAt returns where a user was at a given time
+//
+//	@Summary		Get historical position
+//	@Description	Returns the user's position at an arbitrary time, e.g. to geotag photos. Between two recorded points of the same track segment the position is interpolated; across a gap in tracking or before the first and after the last point, the closest point is returned when it was recorded within max_offset seconds.
+//	@Tags			Timeline
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	path		string	true	"Username"
+//	@Param			timestamp	query		string	true	"RFC 3339 time or Unix timestamp"
+//	@Param			max_offset	query		int		false	"Seconds to the closest recorded point when the position cannot be interpolated (default: 300)"
+//	@Success		200			{object}	models.SuccessResponse{data=models.HistoricalPosition}	"Position retrieved successfully"
+//	@Failure		400			{object}	models.ErrorResponse									"Invalid timestamp"
+//	@Failure		401			{object}	models.ErrorResponse									"Authentication required"
+//	@Failure		403			{object}	models.ErrorResponse									"Forbidden"
+//	@Failure		404			{object}	models.ErrorResponse									"User not found or no position recorded near that time"
+//	@Router			/users/{username}/position-at [get]
+func (h *TimelineHandler) GetPositionAt(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	// Like timelines, positions outside of shared sessions are never public
+	if !canAccess(c, constants.PermSessionsRead, user.Id) {
+		return apis.NewForbiddenError("Cannot view another user's positions", nil)
+	}
+
+	params, ok := middleware.GetValidatedQuery(c).(*appmodels.PositionAtQueryParams)
+	if !ok {
+		return apis.NewBadRequestError("Invalid query parameters", nil)
+	}
+
+	at, err := utils.ParseTimeParam(params.Timestamp)
+	if err != nil {
+		return apis.NewBadRequestError("Invalid timestamp: "+err.Error(), nil)
+	}
+
+	maxOffset := constants.DefaultPositionMaxOffset
+	if params.MaxOffset != nil {
+		maxOffset = time.Duration(*params.MaxOffset) * time.Second
+	}
+
+	position, err := findPositionAt(requestDao(h.app, c), "user = {:user}", dbx.Params{"user": user.Id}, at, h.gapThresholds, maxOffset)
+	if err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch locations", err)
+	}
+	if position == nil {
+		return apis.NewNotFoundError("No position recorded near that time", nil)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, position, "")
+}
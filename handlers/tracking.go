@@ -1,16 +1,23 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tools/types"
 
+	"vibe-tracker/config"
 	"vibe-tracker/constants"
 	"vibe-tracker/middleware"
 	appmodels "vibe-tracker/models"
@@ -21,19 +28,30 @@ import (
 type TrackingHandler struct {
 	app             *pocketbase.PocketBase
 	locationService *services.LocationService
+	batteryAlerts   *services.BatteryAlertService
+	ingest          *services.IngestService
+	config          *config.TrackingConfig
 }
 
-func NewTrackingHandler(app *pocketbase.PocketBase, locationService *services.LocationService) *TrackingHandler {
+func NewTrackingHandler(app *pocketbase.PocketBase, locationService *services.LocationService, batteryAlerts *services.BatteryAlertService, trackingConfig *config.TrackingConfig) *TrackingHandler {
 	return &TrackingHandler{
 		app:             app,
 		locationService: locationService,
+		batteryAlerts:   batteryAlerts,
+		config:          trackingConfig,
 	}
 }
 
+// WithIngestService writes tracked points in batches instead of one transaction per point
+func (h *TrackingHandler) WithIngestService(ingest *services.IngestService) *TrackingHandler {
+	h.ingest = ingest
+	return h
+}
+
 // TrackLocationGET tracks location via GET request with query parameters
 //
 //	@Summary		Track location (GET)
-//	@Description	Tracks user location using GET request with query parameters
+//	@Description	Tracks user location using GET request with query parameters. A retried request with the client_id or Idempotency-Key of a point that was already tracked is answered with the stored point instead of storing it again.
 //	@Tags			Tracking
 //	@Produce		json
 //	@Security		BearerAuth
@@ -41,14 +59,28 @@ func NewTrackingHandler(app *pocketbase.PocketBase, locationService *services.Lo
 //	@Param			lat			query		float64	true	"Latitude (-90 to 90)"
 //	@Param			lon			query		float64	true	"Longitude (-180 to 180)"
 //	@Param			alt			query		float64	false	"Altitude in meters"
-//	@Param			speed		query		float64	false	"Speed in m/s"
-//	@Param			timestamp	query		int64	false	"Unix timestamp"
+//	@Param			speed		query		float64	false	"Speed, stored in m/s"
+//	@Param			speed_unit	query		string	false	"Unit of speed (m/s, km/h, mph, knots); defaults to the user's tracking setting"
+//	@Param			timestamp	query		int64	false	"Unix timestamp; rejected when too old or in the future"
+//	@Param			allow_historical	query	bool	false	"Accept timestamps older than the configured maximum age"
+//	@Param			accuracy	query		float64	false	"Horizontal accuracy in meters"
+//	@Param			device		query		string	false	"Reporting device identifier"
+//	@Param			battery		query		float64	false	"Tracker battery level in percent"
+//	@Param			vertical_accuracy	query	float64	false	"Vertical accuracy in meters"
+//	@Param			bearing		query		float64	false	"Direction of travel in degrees clockwise from true north (0-360)"
+//	@Param			heart_rate	query		float64	false	"Heart rate in beats per minute"
+//	@Param			cadence		query		float64	false	"Cadence in revolutions or steps per minute"
+//	@Param			power		query		float64	false	"Power output in watts"
+//	@Param			temperature	query		float64	false	"Ambient temperature in degrees Celsius"
 //	@Param			session		query		string	false	"Session name"
 //	@Param			status		query		string	false	"Status information"
 //	@Param			event		query		string	false	"Event information"
+//	@Param			client_id	query		string	false	"Unique ID of the point, e.g. a UUID, so retries are stored once"
+//	@Param			Idempotency-Key	header	string	false	"Client ID of the point when client_id is not set"
 //	@Success		200			{object}	models.SuccessResponse	"Location tracked successfully"
 //	@Failure		400			{object}	models.ErrorResponse		"Invalid request"
 //	@Failure		401			{object}	models.ErrorResponse		"Authentication required"
+//	@Failure		503			{object}	models.ErrorResponse		"Too many points are waiting to be saved, retry after Retry-After seconds"
 //	@Router			/track [get]
 func (h *TrackingHandler) TrackLocationGET(c echo.Context) error {
 	// Get authenticated user from middleware context
@@ -64,68 +96,42 @@ func (h *TrackingHandler) TrackLocationGET(c echo.Context) error {
 		return apis.NewBadRequestError("Invalid query parameters", nil)
 	}
 
-	collection, err := h.app.Dao().FindCollectionByNameOrId(constants.CollectionLocations)
-	if err != nil {
-		return apis.NewNotFoundError("locations collection not found", err)
+	if err := h.checkTimestamp(c, params.Timestamp); err != nil {
+		return err
 	}
 
-	record := models.NewRecord(collection)
-	record.Set("user", user.Id)
-	record.Set("timestamp", types.NowDateTime())
-	if params.Timestamp != 0 {
-		timeStamp, _ := types.ParseDateTime(time.Unix(params.Timestamp, 0))
-		record.Set("timestamp", timeStamp)
-	}
-	record.Set("latitude", params.Latitude)
-	record.Set("longitude", params.Longitude)
-	if params.Altitude != nil {
-		record.Set("altitude", *params.Altitude)
-	}
-	if params.Speed != nil {
-		record.Set("speed", *params.Speed)
-	}
-	if params.HeartRate != nil {
-		record.Set("heart_rate", *params.HeartRate)
-	}
-	if params.Status != "" {
-		record.Set("status", params.Status)
-	}
-	if params.Event != "" {
-		record.Set("event", params.Event)
-	}
-	// Handle session - create if doesn't exist
-	sessionName := params.Session
-	record.Set("session", sessionName) // Keep backward compatibility
-
-	if sessionName != "" {
-		session, err := findOrCreateSession(h.app.Dao(), sessionName, user)
-		if err != nil {
-			log.Printf("Warning: Failed to create/find session %s for user %s: %v", sessionName, user.Id, err)
-		} else if session != nil {
-			record.Set("session_id", session.Id)
-		}
+	clientID, err := pointClientID(c, params.ClientID)
+	if err != nil {
+		return err
 	}
+	params.ClientID = clientID
 
-	if err := h.app.Dao().SaveRecord(record); err != nil {
-		return apis.NewApiError(http.StatusInternalServerError, "Failed to save tracking data", err)
+	record, err := h.trackPoint(c.Request().Context(), user, params)
+	if err != nil {
+		return setRetryAfter(c, err)
 	}
 
-	return utils.SendSuccess(c, http.StatusOK, record, "Location tracked successfully")
+	return sendTrackedPoint(c, record)
 }
 
 // TrackLocationPOST tracks location via POST request with JSON body
 //
 //	@Summary		Track location (POST)
-//	@Description	Tracks user location using POST request with JSON payload
+//	@Description	Tracks user location using POST request with a GeoJSON Point Feature. Coordinates must be [longitude, latitude] or [longitude, latitude, altitude] with finite values in range; other geometry types are rejected with field-level errors. Cellular trackers may send the compact CBOR (application/cbor) or protobuf (application/x-protobuf) encoding of the point instead. Bodies may be sent with Content-Encoding: gzip; the inflated body is held to the request size limit. A retried request with the client_id property or Idempotency-Key of a point that was already tracked is answered with the stored point instead of storing it again.
 //	@Tags			Tracking
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Security		TokenAuth
 //	@Param			request	body		models.LocationRequest	true	"Location data"
+//	@Param			allow_historical	query	bool	false	"Accept timestamps older than the configured maximum age"
+//	@Param			Idempotency-Key	header	string	false	"Client ID of the point when the client_id property is not set"
+//	@Param			Content-Encoding	header	string	false	"gzip for a compressed body"
 //	@Success		200		{object}	models.SuccessResponse	"Location tracked successfully"
-//	@Failure		400		{object}	models.ErrorResponse		"Invalid request"
+//	@Failure		400		{object}	models.ErrorResponse		"Invalid request or GeoJSON validation failed"
 //	@Failure		401		{object}	models.ErrorResponse		"Authentication required"
+//	@Failure		415		{object}	models.ErrorResponse		"Unsupported Content-Encoding"
+//	@Failure		503		{object}	models.ErrorResponse		"Too many points are waiting to be saved, retry after Retry-After seconds"
 //	@Router			/track [post]
 func (h *TrackingHandler) TrackLocationPOST(c echo.Context) error {
 	// Get authenticated user from middleware context
@@ -141,52 +147,346 @@ func (h *TrackingHandler) TrackLocationPOST(c echo.Context) error {
 		return apis.NewBadRequestError("Invalid request data", nil)
 	}
 
-	collection, err := h.app.Dao().FindCollectionByNameOrId(constants.CollectionLocations)
+	if err := h.checkTimestamp(c, data.Properties.Timestamp); err != nil {
+		return err
+	}
+
+	params := locationRequestParams(data)
+	clientID, err := pointClientID(c, params.ClientID)
+	if err != nil {
+		return err
+	}
+	params.ClientID = clientID
+
+	record, err := h.trackPoint(c.Request().Context(), user, params)
 	if err != nil {
-		return apis.NewNotFoundError("locations collection not found", err)
+		return setRetryAfter(c, err)
+	}
+
+	return sendTrackedPoint(c, record)
+}
+
+// locationRequestParams converts a GeoJSON point to the parameters of a tracked point
+func locationRequestParams(data *appmodels.LocationRequest) *appmodels.TrackingQueryParams {
+	properties := data.Properties
+	params := &appmodels.TrackingQueryParams{
+		Latitude:         data.Geometry.Coordinates[1],
+		Longitude:        data.Geometry.Coordinates[0],
+		Timestamp:        properties.Timestamp,
+		Speed:            properties.Speed,
+		SpeedUnit:        properties.SpeedUnit,
+		Accuracy:         properties.Accuracy,
+		Device:           properties.Device,
+		Battery:          properties.Battery,
+		VerticalAccuracy: properties.VerticalAccuracy,
+		Bearing:          properties.Bearing,
+		Cadence:          properties.Cadence,
+		Power:            properties.Power,
+		Temperature:      properties.Temperature,
+		HeartRate:        properties.HeartRate,
+		Session:          properties.Session,
+		Status:           properties.Status,
+		Event:            properties.Event,
+		ClientID:         properties.ClientID,
+	}
+	if len(data.Geometry.Coordinates) > 2 {
+		params.Altitude = &data.Geometry.Coordinates[2]
+	}
+	return params
+}
+
+// trackPoint stores a validated point of user: it resolves the session, checks off waypoints and
+// alerts on low battery. It is shared by GET and POST /api/track and the gRPC ingestion service. A point
+// discarded by the downsampling policy is returned unsaved, without an id; for a point with the
+// client ID of an already tracked point, or repeating a stored point of its session, that point
+// is returned. Points implying an impossible speed are rejected.
+func (h *TrackingHandler) trackPoint(ctx context.Context, user *models.Record, params *appmodels.TrackingQueryParams) (*models.Record, error) {
+	dao := utils.ContextDao(h.app.Dao(), ctx)
+
+	if tracked := h.findTrackedPoint(dao, user.Id, params.ClientID); tracked != nil {
+		return tracked, nil
+	}
+
+	collection, err := dao.FindCollectionByNameOrId(constants.CollectionLocations)
+	if err != nil {
+		return nil, apis.NewNotFoundError("locations collection not found", err)
 	}
 
 	record := models.NewRecord(collection)
 	record.Set("user", user.Id)
-	if data.Properties.Timestamp == constants.DefaultTimestamp {
-		record.Set("timestamp", types.NowDateTime())
-	} else {
-		timeStamp, _ := types.ParseDateTime(time.Unix(data.Properties.Timestamp, 0))
+	if params.ClientID != "" {
+		record.Set("client_id", params.ClientID)
+	}
+	record.Set("timestamp", types.NowDateTime())
+	if params.Timestamp != 0 {
+		timeStamp, _ := types.ParseDateTime(time.Unix(params.Timestamp, 0))
 		record.Set("timestamp", timeStamp)
 	}
-	record.Set("longitude", data.Geometry.Coordinates[0])
-	record.Set("latitude", data.Geometry.Coordinates[1])
-	if len(data.Geometry.Coordinates) > 2 {
-		record.Set("altitude", data.Geometry.Coordinates[2])
+	h.locationService.SetPosition(record, params.Latitude, params.Longitude, params.Altitude)
+	if err := h.locationService.SetSpeed(record, user, params.Speed, params.SpeedUnit); err != nil {
+		return nil, apis.NewBadRequestError(err.Error(), nil)
+	}
+	if params.HeartRate != nil {
+		record.Set("heart_rate", *params.HeartRate)
+	}
+	utils.SetRecordSensors(record, params.Cadence, params.Power, params.Temperature)
+	if params.Accuracy != nil {
+		record.Set("accuracy", *params.Accuracy)
 	}
-	if data.Properties.Speed != nil {
-		record.Set("speed", *data.Properties.Speed)
+	if params.Device != "" {
+		record.Set("device", params.Device)
 	}
-	if data.Properties.HeartRate != nil {
-		record.Set("heart_rate", *data.Properties.HeartRate)
+	if params.Battery != nil {
+		record.Set("battery", *params.Battery)
 	}
-	if data.Properties.Status != "" {
-		record.Set("status", data.Properties.Status)
+	if params.VerticalAccuracy != nil {
+		record.Set("vertical_accuracy", *params.VerticalAccuracy)
 	}
-	if data.Properties.Event != "" {
-		record.Set("event", data.Properties.Event)
+	utils.SetRecordBearing(record, params.Bearing)
+	if params.Status != "" {
+		record.Set("status", params.Status)
+	}
+	if params.Event != "" {
+		record.Set("event", params.Event)
 	}
 	// Handle session - create if doesn't exist
-	sessionName := data.Properties.Session
-	record.Set("session", sessionName) // Keep backward compatibility
+	sessionName := params.Session
+	if sessionName == "" {
+		// Fall back to the user's automatic session setting
+		autoSession, err := h.locationService.WithContext(ctx).ResolveAutoSession(user, record.GetDateTime("timestamp").Time())
+		if err != nil {
+			log.Printf("Warning: Failed to resolve automatic session for user %s: %v", user.Id, err)
+		}
+		sessionName = autoSession
+	}
 
+	var session *models.Record
 	if sessionName != "" {
-		session, err := findOrCreateSession(h.app.Dao(), sessionName, user)
+		session, err = findOrCreateSession(dao, sessionName, user)
 		if err != nil {
 			log.Printf("Warning: Failed to create/find session %s for user %s: %v", sessionName, user.Id, err)
 		} else if session != nil {
-			record.Set("session_id", session.Id)
+			setLocationSession(record, session)
 		}
 	}
 
-	if err := h.app.Dao().SaveRecord(record); err != nil {
-		return apis.NewApiError(http.StatusInternalServerError, "Failed to save tracking data", err)
+	if duplicate := h.findDuplicatePoint(ctx, dao, record); duplicate != nil {
+		return duplicate, nil
 	}
+	if err := h.checkPlausibility(ctx, record); err != nil {
+		return nil, err
+	}
+	h.deriveMotion(ctx, record)
 
+	if !h.discardDownsampled(ctx, dao, user, session, record) {
+		stored, err := h.saveLocation(dao, record)
+		if err != nil {
+			return nil, saveError(err)
+		}
+		if stored != record {
+			return stored, nil
+		}
+		// Only a stored point reopens an ended session, repeated and rejected points keep its stats
+		if session != nil {
+			reopenSession(dao, session)
+		}
+	}
+
+	h.checkOffWaypoints(record)
+	h.batteryAlerts.CheckLocation(user, record, params.Battery)
+
+	return record, nil
+}
+
+// findDuplicatePoint returns the stored point of the session a tracked point repeats, which is
+// answered instead of storing the point again, or nil. Points are stored when the check fails.
+func (h *TrackingHandler) findDuplicatePoint(ctx context.Context, dao *daos.Dao, record *models.Record) *models.Record {
+	duplicate, err := h.locationService.WithContext(ctx).FindDuplicate(record)
+	if err != nil {
+		utils.LogWarn().Err(err).Str("user_id", record.GetString("user")).Msg("Failed to check for duplicate point")
+		return nil
+	}
+	if duplicate == nil {
+		return nil
+	}
+
+	utils.LogDebug().Str("user_id", record.GetString("user")).Str("location_id", duplicate.Id).Msg("Point repeats a stored point")
+	expandLocationSessions(dao, duplicate)
+	return duplicate
+}
+
+// checkPlausibility rejects a tracked point that implies an impossible speed from the previous
+// point of its session. Points are stored when the check fails.
+func (h *TrackingHandler) checkPlausibility(ctx context.Context, record *models.Record) error {
+	err := h.locationService.WithContext(ctx).CheckPlausibility(record)
+	var outlier *services.LocationError
+	if errors.As(err, &outlier) {
+		utils.LogDebug().Str("user_id", record.GetString("user")).Str("reason", outlier.Message).Msg("Rejected outlier point")
+		return apis.NewBadRequestError(outlier.Message, nil)
+	}
+	if err != nil {
+		utils.LogWarn().Err(err).Str("user_id", record.GetString("user")).Msg("Failed to check point plausibility")
+	}
+	return nil
+}
+
+// deriveMotion fills in the speed and bearing of a tracked point that reports none from the
+// previous point of its session. Points are stored as reported when that fails.
+func (h *TrackingHandler) deriveMotion(ctx context.Context, record *models.Record) {
+	if err := h.locationService.WithContext(ctx).DeriveMotion(record); err != nil {
+		utils.LogWarn().Err(err).Str("user_id", record.GetString("user")).Msg("Failed to derive point speed and bearing")
+	}
+}
+
+// discardDownsampled reports whether the downsampling policy discards a point instead of storing
+// it, and counts discarded points in the statistics of their session. Points are kept when the
+// policy cannot be checked.
+func (h *TrackingHandler) discardDownsampled(ctx context.Context, dao *daos.Dao, user, session, record *models.Record) bool {
+	discard, err := h.locationService.WithContext(ctx).ShouldDownsample(user, session, record)
+	if err != nil {
+		utils.LogWarn().Err(err).Str("user_id", user.Id).Msg("Failed to check downsampling policy")
+		return false
+	}
+	if !discard || session == nil {
+		return discard
+	}
+
+	// The counter is statistics, so it does not touch the session's updated time
+	_, err = dao.DB().Update(constants.CollectionSessions, dbx.Params{
+		"downsampled_points": dbx.NewExp("downsampled_points + 1"),
+	}, dbx.HashExp{"id": session.Id}).Execute()
+	if err != nil {
+		utils.LogWarn().Err(err).Str("session_id", session.Id).Msg("Failed to count downsampled point")
+	}
+	return true
+}
+
+// sendTrackedPoint responds with a tracked point, or reports that the downsampling policy
+// discarded it; discarded points are never saved and have no id
+func sendTrackedPoint(c echo.Context, record *models.Record) error {
+	if record.Id == "" {
+		return utils.SendSuccess(c, http.StatusOK, map[string]any{
+			"discarded": true,
+			"session":   utils.LocationSessionName(record),
+		}, "Location discarded by downsampling policy")
+	}
 	return utils.SendSuccess(c, http.StatusOK, record, "Location tracked successfully")
 }
+
+// saveLocation stores a tracked point, through the write batches when batching is enabled, and
+// returns the stored point. When a retry of the point was stored first, that point is returned.
+func (h *TrackingHandler) saveLocation(dao *daos.Dao, record *models.Record) (*models.Record, error) {
+	var err error
+	if h.ingest != nil {
+		err = h.ingest.Save(record)
+	} else {
+		err = dao.SaveRecord(record)
+	}
+	if err == nil {
+		return record, nil
+	}
+	if errors.Is(err, services.ErrIngestQueueFull) {
+		return nil, err
+	}
+
+	// The unique client ID index rejected the point
+	if tracked := h.findTrackedPoint(dao, record.GetString("user"), record.GetString("client_id")); tracked != nil {
+		return tracked, nil
+	}
+	return nil, err
+}
+
+// saveError reports a point that could not be saved. A full write queue is temporary, so its
+// senders are asked to retry instead of being told the point is broken.
+func saveError(err error) error {
+	if errors.Is(err, services.ErrIngestQueueFull) {
+		return apis.NewApiError(http.StatusServiceUnavailable, "Too many points are waiting to be saved, try again later", nil)
+	}
+	return apis.NewApiError(http.StatusInternalServerError, "Failed to save tracking data", err)
+}
+
+// setRetryAfter tells the sender of a point refused by the full write queue when to retry
+func setRetryAfter(c echo.Context, err error) error {
+	var apiErr *apis.ApiError
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusServiceUnavailable {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(constants.IngestRetryAfter/time.Second)))
+	}
+	return err
+}
+
+// findTrackedPoint returns the point a user already tracked with a client ID, or nil. Retried
+// uploads are answered with the stored point instead of storing it again.
+func (h *TrackingHandler) findTrackedPoint(dao *daos.Dao, userID, clientID string) *models.Record {
+	if clientID == "" {
+		return nil
+	}
+
+	// A retry may arrive while the first upload is still queued for the next write batch
+	if h.ingest != nil {
+		h.ingest.FlushUser(userID)
+	}
+
+	record, err := dao.FindFirstRecordByFilter(constants.CollectionLocations,
+		"user = {:user} && client_id = {:client_id}", dbx.Params{"user": userID, "client_id": clientID})
+	if err != nil {
+		return nil
+	}
+
+	utils.LogDebug().Str("user_id", userID).Str("client_id", clientID).Msg("Point was already tracked")
+	expandLocationSessions(dao, record)
+	return record
+}
+
+// pointClientID returns the client ID of the point of a /track request: its client_id, or else the
+// Idempotency-Key header
+func pointClientID(c echo.Context, clientID string) (string, error) {
+	if clientID != "" {
+		return clientID, nil
+	}
+
+	key := c.Request().Header.Get(constants.IdempotencyKeyHeader)
+	if len(key) > constants.MaxClientIDLength {
+		return "", apis.NewBadRequestError(fmt.Sprintf("%s must be at most %d characters", constants.IdempotencyKeyHeader, constants.MaxClientIDLength), nil)
+	}
+	return key, nil
+}
+
+// checkTimestamp rejects explicit point timestamps that are too old or too far in the future,
+// which usually come from devices with a broken clock
+func (h *TrackingHandler) checkTimestamp(c echo.Context, timestamp int64) error {
+	if timestamp == constants.DefaultTimestamp {
+		return nil // Points without a timestamp are recorded at the current time
+	}
+
+	allowHistorical := c.QueryParam(constants.AllowHistoricalParam) == "true"
+	if err := h.validateTimestamp(timestamp, allowHistorical); err != nil {
+		return apis.NewBadRequestError("Validation failed", err)
+	}
+
+	return nil
+}
+
+// validateTimestamp applies the configured age and skew limits to an explicit point timestamp
+func (h *TrackingHandler) validateTimestamp(timestamp int64, allowHistorical bool) error {
+	return utils.ValidateTimestamp(time.Unix(timestamp, 0), time.Now(),
+		h.config.MaxTimestampAge, h.config.MaxTimestampSkew, allowHistorical)
+}
+
+// checkOffWaypoints marks session waypoints near a newly tracked location as visited
+func (h *TrackingHandler) checkOffWaypoints(record *models.Record) {
+	sessionID := record.GetString("session")
+	if sessionID == "" {
+		return
+	}
+
+	marked, err := markVisitedWaypoints(h.app.Dao(), sessionID,
+		record.GetFloat("latitude"), record.GetFloat("longitude"),
+		record.GetDateTime("timestamp"), h.config.WaypointVisitRadius)
+	if err != nil {
+		log.Printf("Warning: Failed to check off waypoints for session %s: %v", sessionID, err)
+		return
+	}
+	if marked > 0 {
+		log.Printf("Marked %d waypoint(s) as visited for session %s", marked, sessionID)
+	}
+}
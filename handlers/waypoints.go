@@ -10,8 +10,10 @@ import (
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
 
 	"vibe-tracker/constants"
 	"vibe-tracker/middleware"
@@ -20,7 +22,8 @@ import (
 )
 
 type WaypointHandler struct {
-	app *pocketbase.PocketBase
+	app           *pocketbase.PocketBase
+	gapThresholds utils.GapThresholds
 }
 
 func NewWaypointHandler(app *pocketbase.PocketBase) *WaypointHandler {
@@ -29,6 +32,13 @@ func NewWaypointHandler(app *pocketbase.PocketBase) *WaypointHandler {
 	}
 }
 
+// WithGapThresholds sets the limits beyond which photo positions are not interpolated between
+// tracked points
+func (h *WaypointHandler) WithGapThresholds(thresholds utils.GapThresholds) *WaypointHandler {
+	h.gapThresholds = thresholds
+	return h
+}
+
 // ListWaypoints lists waypoints for a user or session
 //
 //	@Summary		List waypoints
@@ -37,6 +47,7 @@ func NewWaypointHandler(app *pocketbase.PocketBase) *WaypointHandler {
 //	@Produce		json
 //	@Param			username	path		string	true	"Username"
 //	@Param			session		query		string	false	"Session name filter"
+//	@Param			scope		query		string	false	"Limit to user-level (user) or session-linked (session) waypoints"
 //	@Param			type		query		string	false	"Waypoint type filter"
 //	@Param			page		query		int		false	"Page number (default: 1)"
 //	@Param			per_page	query		int		false	"Items per page (default: 20, max: 100)"
@@ -51,8 +62,7 @@ func (h *WaypointHandler) ListWaypoints(c echo.Context) error {
 	}
 
 	// Check if user has access to this data
-	authRecord, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
-	isOwner := authRecord != nil && authRecord.Id == user.Id
+	canReadAll := canAccess(c, constants.PermWaypointsRead, user.Id)
 
 	// Parse pagination parameters
 	page := constants.DefaultPage
@@ -71,30 +81,46 @@ func (h *WaypointHandler) ListWaypoints(c echo.Context) error {
 	// Build filter
 	filter := ""
 	params := dbx.Params{}
+	sort := "-created" // Order by newest first across sessions
 
 	// Session filter
 	if sessionName := c.QueryParam("session"); sessionName != "" {
 		// Find the session first
-		session, err := findSessionByNameAndUser(h.app.Dao(), sessionName, user.Id)
+		session, err := findSessionByNameAndUser(requestDao(h.app, c), sessionName, user.Id)
 		if err != nil {
 			return apis.NewNotFoundError("Session not found", err)
 		}
 
 		// Check if user has access to this session
-		if !session.GetBool("public") && !isOwner {
+		if !isPublicSession(session) && !canReadAll {
 			return apis.NewForbiddenError("Access denied", nil)
 		}
 
 		filter = "session_id = {:session_id}"
 		params["session_id"] = session.Id
+		if !canReadAll {
+			filter += " && hidden = false"
+		}
+		sort = constants.WaypointOrderSort
 	} else {
-		// List waypoints from all user's sessions
-		if isOwner {
-			filter = "session_id.user = {:user}"
+		// List all waypoints owned by the user; others only see those in public sessions
+		if canReadAll {
+			filter = "user = {:user}"
 		} else {
-			filter = "session_id.user = {:user} && session_id.public = true"
+			filter = "user = {:user} && session_id.public = true && session_id.hidden = false && session_id.draft = false && hidden = false"
 		}
 		params["user"] = user.Id
+
+		// Scope filter
+		switch c.QueryParam("scope") {
+		case "user":
+			filter += " && session_id = ''"
+		case "session":
+			filter += " && session_id != ''"
+		case "":
+		default:
+			return apis.NewBadRequestError("scope must be either 'user' or 'session'", nil)
+		}
 	}
 
 	// Type filter
@@ -108,10 +134,10 @@ func (h *WaypointHandler) ListWaypoints(c echo.Context) error {
 	}
 
 	// Get waypoints with pagination
-	waypoints, err := h.app.Dao().FindRecordsByFilter(
+	waypoints, err := requestDao(h.app, c).FindRecordsByFilter(
 		"waypoints",
 		filter,
-		"-created", // Order by newest first
+		sort,
 		perPage,
 		(page-1)*perPage,
 		params,
@@ -122,7 +148,7 @@ func (h *WaypointHandler) ListWaypoints(c echo.Context) error {
 	}
 
 	// Count total waypoints for pagination
-	totalWaypoints, err := h.app.Dao().FindRecordsByFilter("waypoints", filter, "", 0, 0, params)
+	totalWaypoints, err := requestDao(h.app, c).FindRecordsByFilter("waypoints", filter, "", 0, 0, params)
 	if err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to count waypoints", err)
 	}
@@ -162,16 +188,14 @@ func (h *WaypointHandler) ListWaypointsBySession(c echo.Context) error {
 	sessionID := c.PathParam("sessionId")
 
 	// Find the session to verify it exists and check access
-	session, err := h.app.Dao().FindRecordById("sessions", sessionID)
+	session, err := requestDao(h.app, c).FindRecordById("sessions", sessionID)
 	if err != nil {
 		return apis.NewNotFoundError("Session not found", err)
 	}
 
 	// Check if user has access to this session
-	authRecord, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
-	isOwner := authRecord != nil && authRecord.Id == session.GetString("user")
-
-	if !session.GetBool("public") && !isOwner {
+	canReadAll := canAccess(c, constants.PermWaypointsRead, session.GetString("user"))
+	if !isPublicSession(session) && !canReadAll {
 		return apis.NewForbiddenError("Access denied", nil)
 	}
 
@@ -192,6 +216,9 @@ func (h *WaypointHandler) ListWaypointsBySession(c echo.Context) error {
 	// Build filter
 	filter := "session_id = {:session_id}"
 	params := dbx.Params{"session_id": sessionID}
+	if !canReadAll {
+		filter += " && hidden = false"
+	}
 
 	// Type filter
 	if waypointType := c.QueryParam("type"); waypointType != "" {
@@ -200,10 +227,10 @@ func (h *WaypointHandler) ListWaypointsBySession(c echo.Context) error {
 	}
 
 	// Get waypoints with pagination
-	waypoints, err := h.app.Dao().FindRecordsByFilter(
+	waypoints, err := requestDao(h.app, c).FindRecordsByFilter(
 		"waypoints",
 		filter,
-		"-created", // Order by newest first
+		constants.WaypointOrderSort, // Order by explicit waypoint order
 		perPage,
 		(page-1)*perPage,
 		params,
@@ -216,37 +243,7 @@ func (h *WaypointHandler) ListWaypointsBySession(c echo.Context) error {
 	// Format response as GeoJSON FeatureCollection to match frontend expectations
 	features := make([]map[string]any, len(waypoints))
 	for i, waypoint := range waypoints {
-		features[i] = map[string]any{
-			"type": "Feature",
-			"id":   waypoint.Id,
-			"geometry": map[string]any{
-				"type": "Point",
-				"coordinates": []float64{
-					waypoint.GetFloat("longitude"),
-					waypoint.GetFloat("latitude"),
-				},
-			},
-			"properties": map[string]any{
-				"id":                  waypoint.Id,
-				"name":                waypoint.GetString("name"),
-				"type":                waypoint.GetString("type"),
-				"description":         waypoint.GetString("description"),
-				"session_id":          waypoint.GetString("session_id"),
-				"source":              waypoint.GetString("source"),
-				"position_confidence": waypoint.GetString("position_confidence"),
-				"created":             waypoint.GetDateTime("created").Time().Format(time.RFC3339),
-				"updated":             waypoint.GetDateTime("updated").Time().Format(time.RFC3339),
-			},
-		}
-
-		// Add optional fields
-		if altitude := waypoint.GetFloat("altitude"); altitude != 0 {
-			features[i]["properties"].(map[string]any)["altitude"] = altitude
-		}
-
-		if photo := waypoint.GetString("photo"); photo != "" {
-			features[i]["properties"].(map[string]any)["photo"] = photo
-		}
+		features[i] = waypointToFeature(h.app, waypoint)
 	}
 
 	// Return GeoJSON FeatureCollection format to match frontend expectations
@@ -255,6 +252,11 @@ func (h *WaypointHandler) ListWaypointsBySession(c echo.Context) error {
 		"features": features,
 	}
 
+	// Include the next expected waypoint derived from the waypoint order
+	if next, err := findNextWaypoint(requestDao(h.app, c), sessionID, canReadAll); err == nil {
+		response["next_waypoint_id"] = next.Id
+	}
+
 	return utils.SendSuccess(c, http.StatusOK, response, "")
 }
 
@@ -271,21 +273,13 @@ func (h *WaypointHandler) ListWaypointsBySession(c echo.Context) error {
 func (h *WaypointHandler) GetWaypoint(c echo.Context) error {
 	waypointID := c.PathParam("id")
 
-	waypoint, err := h.app.Dao().FindRecordById("waypoints", waypointID)
+	waypoint, err := requestDao(h.app, c).FindRecordById("waypoints", waypointID)
 	if err != nil {
 		return apis.NewNotFoundError("Waypoint not found", err)
 	}
 
 	// Check if user has access to this waypoint
-	session, err := h.app.Dao().FindRecordById("sessions", waypoint.GetString("session_id"))
-	if err != nil {
-		return apis.NewNotFoundError("Session not found", err)
-	}
-
-	authRecord, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
-	isOwner := authRecord != nil && authRecord.Id == session.GetString("user")
-
-	if !session.GetBool("public") && !isOwner {
+	if !h.canViewWaypoint(c, waypoint) {
 		return apis.NewForbiddenError("Access denied", nil)
 	}
 
@@ -296,7 +290,7 @@ func (h *WaypointHandler) GetWaypoint(c echo.Context) error {
 // CreateWaypoint creates a new waypoint
 //
 //	@Summary		Create waypoint
-//	@Description	Creates a new waypoint for a session
+//	@Description	Creates a new waypoint for a session, or a user-level waypoint when no session is given
 //	@Tags			Waypoints
 //	@Accept			json
 //	@Produce		json
@@ -320,23 +314,20 @@ func (h *WaypointHandler) CreateWaypoint(c echo.Context) error {
 		return apis.NewBadRequestError("Invalid request data", nil)
 	}
 
-	// Verify user owns the session
-	session, err := h.app.Dao().FindRecordById("sessions", data.SessionID)
-	if err != nil {
-		return apis.NewNotFoundError("Session not found", err)
-	}
-
-	if session.GetString("user") != record.Id {
-		return apis.NewForbiddenError("Cannot create waypoints for another user's session", nil)
+	// Waypoints linked to a session belong to the session owner, access is checked by the middleware
+	ownerID := record.Id
+	if session, ok := middleware.GetRequestSession(c); ok {
+		ownerID = session.GetString("user")
 	}
 
 	// Create waypoint
-	collection, err := h.app.Dao().FindCollectionByNameOrId("waypoints")
+	collection, err := requestDao(h.app, c).FindCollectionByNameOrId("waypoints")
 	if err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Waypoints collection not found", err)
 	}
 
 	waypoint := models.NewRecord(collection)
+	waypoint.Set("user", ownerID)
 	waypoint.Set("session_id", data.SessionID)
 	waypoint.Set("name", data.Name)
 	waypoint.Set("type", data.Type)
@@ -345,47 +336,21 @@ func (h *WaypointHandler) CreateWaypoint(c echo.Context) error {
 	waypoint.Set("longitude", data.Longitude)
 	waypoint.Set("source", data.Source)
 	waypoint.Set("position_confidence", data.PositionConfidence)
+	utils.SetRecordAltitude(waypoint, data.Altitude)
 
-	if data.Altitude != nil {
-		waypoint.Set("altitude", *data.Altitude)
+	// Append to the end of the session's waypoints unless an explicit order was requested
+	if data.Order != nil {
+		waypoint.Set("order", *data.Order)
+	} else if data.SessionID != "" {
+		waypoint.Set("order", nextWaypointOrder(requestDao(h.app, c), data.SessionID))
 	}
 
-	if err := h.app.Dao().SaveRecord(waypoint); err != nil {
+	if err := requestDao(h.app, c).SaveRecord(waypoint); err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to create waypoint", err)
 	}
 
 	// Format as GeoJSON Feature to match frontend expectations
-	waypointFeature := map[string]any{
-		"type": "Feature",
-		"id":   waypoint.Id,
-		"geometry": map[string]any{
-			"type": "Point",
-			"coordinates": []float64{
-				waypoint.GetFloat("longitude"),
-				waypoint.GetFloat("latitude"),
-			},
-		},
-		"properties": map[string]any{
-			"id":                  waypoint.Id,
-			"name":                waypoint.GetString("name"),
-			"type":                waypoint.GetString("type"),
-			"description":         waypoint.GetString("description"),
-			"session_id":          waypoint.GetString("session_id"),
-			"source":              waypoint.GetString("source"),
-			"position_confidence": waypoint.GetString("position_confidence"),
-			"created":             waypoint.GetDateTime("created").Time().Format(time.RFC3339),
-			"updated":             waypoint.GetDateTime("updated").Time().Format(time.RFC3339),
-		},
-	}
-
-	// Add optional fields
-	if altitude := waypoint.GetFloat("altitude"); altitude != 0 {
-		waypointFeature["properties"].(map[string]any)["altitude"] = altitude
-	}
-
-	if photo := waypoint.GetString("photo"); photo != "" {
-		waypointFeature["properties"].(map[string]any)["photo"] = photo
-	}
+	waypointFeature := waypointToFeature(h.app, waypoint)
 
 	return utils.SendSuccess(c, http.StatusCreated, waypointFeature, "Waypoint created successfully")
 }
@@ -412,22 +377,7 @@ func (h *WaypointHandler) UpdateWaypoint(c echo.Context) error {
 		return apis.NewUnauthorizedError("Authentication required", nil)
 	}
 
-	waypointID := c.PathParam("id")
-
-	waypoint, err := h.app.Dao().FindRecordById("waypoints", waypointID)
-	if err != nil {
-		return apis.NewNotFoundError("Waypoint not found", err)
-	}
-
-	// Verify user owns the session
-	session, err := h.app.Dao().FindRecordById("sessions", waypoint.GetString("session_id"))
-	if err != nil {
-		return apis.NewNotFoundError("Session not found", err)
-	}
-
-	if session.GetString("user") != record.Id {
-		return apis.NewForbiddenError("Cannot update another user's waypoints", nil)
-	}
+	waypoint, _ := middleware.GetRequestWaypoint(c)
 
 	// Get validated data from middleware
 	validatedData := middleware.GetValidatedData(c)
@@ -436,6 +386,24 @@ func (h *WaypointHandler) UpdateWaypoint(c echo.Context) error {
 		return apis.NewBadRequestError("Invalid request data", nil)
 	}
 
+	// Link to another session or unlink into a user-level waypoint
+	if data.SessionID != nil && *data.SessionID != waypoint.GetString("session_id") {
+		if *data.SessionID != "" {
+			session, err := requestDao(h.app, c).FindRecordById("sessions", *data.SessionID)
+			if err != nil {
+				return apis.NewNotFoundError("Session not found", err)
+			}
+
+			// Waypoints can only be linked to sessions of their own owner
+			if session.GetString("user") != waypoint.GetString("user") {
+				return apis.NewForbiddenError("Cannot link waypoints to another user's session", nil)
+			}
+
+			waypoint.Set("order", nextWaypointOrder(requestDao(h.app, c), session.Id))
+		}
+		waypoint.Set("session_id", *data.SessionID)
+	}
+
 	// Update waypoint fields
 	if data.Name != "" {
 		waypoint.Set("name", data.Name)
@@ -453,45 +421,18 @@ func (h *WaypointHandler) UpdateWaypoint(c echo.Context) error {
 		waypoint.Set("longitude", *data.Longitude)
 	}
 	if data.Altitude != nil {
-		waypoint.Set("altitude", *data.Altitude)
+		utils.SetRecordAltitude(waypoint, data.Altitude)
+	}
+	if data.Order != nil {
+		waypoint.Set("order", *data.Order)
 	}
 
-	if err := h.app.Dao().SaveRecord(waypoint); err != nil {
+	if err := requestDao(h.app, c).SaveRecord(waypoint); err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Failed to update waypoint", err)
 	}
 
 	// Format as GeoJSON Feature to match frontend expectations
-	waypointFeature := map[string]any{
-		"type": "Feature",
-		"id":   waypoint.Id,
-		"geometry": map[string]any{
-			"type": "Point",
-			"coordinates": []float64{
-				waypoint.GetFloat("longitude"),
-				waypoint.GetFloat("latitude"),
-			},
-		},
-		"properties": map[string]any{
-			"id":                  waypoint.Id,
-			"name":                waypoint.GetString("name"),
-			"type":                waypoint.GetString("type"),
-			"description":         waypoint.GetString("description"),
-			"session_id":          waypoint.GetString("session_id"),
-			"source":              waypoint.GetString("source"),
-			"position_confidence": waypoint.GetString("position_confidence"),
-			"created":             waypoint.GetDateTime("created").Time().Format(time.RFC3339),
-			"updated":             waypoint.GetDateTime("updated").Time().Format(time.RFC3339),
-		},
-	}
-
-	// Add optional fields
-	if altitude := waypoint.GetFloat("altitude"); altitude != 0 {
-		waypointFeature["properties"].(map[string]any)["altitude"] = altitude
-	}
-
-	if photo := waypoint.GetString("photo"); photo != "" {
-		waypointFeature["properties"].(map[string]any)["photo"] = photo
-	}
+	waypointFeature := waypointToFeature(h.app, waypoint)
 
 	return utils.SendSuccess(c, http.StatusOK, waypointFeature, "Waypoint updated successfully")
 }
@@ -515,28 +456,154 @@ func (h *WaypointHandler) DeleteWaypoint(c echo.Context) error {
 		return apis.NewUnauthorizedError("Authentication required", nil)
 	}
 
-	waypointID := c.PathParam("id")
+	waypoint, _ := middleware.GetRequestWaypoint(c)
+
+	if err := requestDao(h.app, c).DeleteRecord(waypoint); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to delete waypoint", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, nil, "Waypoint deleted successfully")
+}
 
-	waypoint, err := h.app.Dao().FindRecordById("waypoints", waypointID)
+// ReorderWaypoints sets the order of all waypoints in a session
+//
+//	@Summary		Reorder waypoints
+//	@Description	Reorders the waypoints of a session; the request must list every waypoint of the session exactly once
+//	@Tags			Waypoints
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		models.ReorderWaypointsRequest	true	"Session ID and ordered waypoint IDs"
+//	@Success		200		{object}	models.SuccessResponse			"Waypoints reordered successfully"
+//	@Failure		400		{object}	models.ErrorResponse			"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse			"Authentication required"
+//	@Failure		403		{object}	models.ErrorResponse			"Forbidden"
+//	@Failure		404		{object}	models.ErrorResponse			"Session not found"
+//	@Router			/waypoints/reorder [put]
+func (h *WaypointHandler) ReorderWaypoints(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	// Get validated data from middleware
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.ReorderWaypointsRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	session, _ := middleware.GetRequestSession(c)
+
+	waypoints, err := requestDao(h.app, c).FindRecordsByFilter(
+		"waypoints",
+		"session_id = {:session_id}",
+		constants.WaypointOrderSort,
+		0, 0,
+		dbx.Params{"session_id": session.Id},
+	)
 	if err != nil {
-		return apis.NewNotFoundError("Waypoint not found", err)
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to fetch waypoints", err)
 	}
 
-	// Verify user owns the session
-	session, err := h.app.Dao().FindRecordById("sessions", waypoint.GetString("session_id"))
+	// The new order must be a permutation of the session's waypoints
+	if len(data.WaypointIDs) != len(waypoints) {
+		return apis.NewBadRequestError("waypoint_ids must list every waypoint of the session exactly once", nil)
+	}
+
+	byID := make(map[string]*models.Record, len(waypoints))
+	for _, waypoint := range waypoints {
+		byID[waypoint.Id] = waypoint
+	}
+
+	ordered := make([]*models.Record, len(data.WaypointIDs))
+	for i, id := range data.WaypointIDs {
+		waypoint, exists := byID[id]
+		if !exists {
+			return apis.NewBadRequestError(fmt.Sprintf("Waypoint %s does not belong to the session or is listed twice", id), nil)
+		}
+		delete(byID, id)
+		ordered[i] = waypoint
+	}
+
+	err = requestDao(h.app, c).RunInTransaction(func(txDao *daos.Dao) error {
+		for i, waypoint := range ordered {
+			if waypoint.GetInt("order") == i {
+				continue
+			}
+			waypoint.Set("order", i)
+			if err := txDao.SaveRecord(waypoint); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return apis.NewNotFoundError("Session not found", err)
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to reorder waypoints", err)
 	}
 
-	if session.GetString("user") != record.Id {
-		return apis.NewForbiddenError("Cannot delete another user's waypoints", nil)
+	features := make([]map[string]any, len(ordered))
+	for i, waypoint := range ordered {
+		features[i] = waypointToFeature(h.app, waypoint)
 	}
 
-	if err := h.app.Dao().DeleteRecord(waypoint); err != nil {
-		return apis.NewApiError(http.StatusInternalServerError, "Failed to delete waypoint", err)
+	response := map[string]any{
+		"type":     "FeatureCollection",
+		"features": features,
 	}
 
-	return utils.SendSuccess(c, http.StatusOK, nil, "Waypoint deleted successfully")
+	if next, err := findNextWaypoint(requestDao(h.app, c), session.Id, true); err == nil {
+		response["next_waypoint_id"] = next.Id
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, response, "Waypoints reordered successfully")
+}
+
+// SetWaypointVisited checks off a waypoint or clears its visited state
+//
+//	@Summary		Set waypoint visited state
+//	@Description	Marks a waypoint as visited (optionally at a given Unix timestamp) or clears the visited state
+//	@Tags			Waypoints
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string							true	"Waypoint ID"
+//	@Param			request	body		models.SetWaypointVisitedRequest	true	"Visited state"
+//	@Success		200		{object}	models.SuccessResponse				"Waypoint visited state updated successfully"
+//	@Failure		400		{object}	models.ErrorResponse				"Invalid request"
+//	@Failure		401		{object}	models.ErrorResponse				"Authentication required"
+//	@Failure		403		{object}	models.ErrorResponse				"Forbidden"
+//	@Failure		404		{object}	models.ErrorResponse				"Waypoint not found"
+//	@Router			/waypoints/{id}/visited [put]
+func (h *WaypointHandler) SetWaypointVisited(c echo.Context) error {
+	record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	if record == nil {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	waypoint, _ := middleware.GetRequestWaypoint(c)
+
+	// Get validated data from middleware
+	validatedData := middleware.GetValidatedData(c)
+	data, ok := validatedData.(*appmodels.SetWaypointVisitedRequest)
+	if !ok {
+		return apis.NewBadRequestError("Invalid request data", nil)
+	}
+
+	if !data.Visited {
+		waypoint.Set("visited_at", "")
+	} else if data.VisitedAt != 0 {
+		visitedAt, _ := types.ParseDateTime(time.Unix(data.VisitedAt, 0))
+		waypoint.Set("visited_at", visitedAt)
+	} else {
+		waypoint.Set("visited_at", types.NowDateTime())
+	}
+
+	if err := requestDao(h.app, c).SaveRecord(waypoint); err != nil {
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to update waypoint", err)
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, waypointToFeature(h.app, waypoint), "Waypoint visited state updated successfully")
 }
 
 // UploadPhotoWaypoint uploads a photo and creates a waypoint with intelligent positioning
@@ -552,6 +619,7 @@ func (h *WaypointHandler) DeleteWaypoint(c echo.Context) error {
 //	@Param			type		formData	string	false	"Waypoint type (default: generic)"
 //	@Param			description	formData	string	false	"Waypoint description (optional)"
 //	@Param			photo		formData	file	true	"Photo file to upload"
+//	@Param			timezone	formData	string	false	"Time zone of the camera clock, an IANA name or UTC offset (default: UTC)"
 //	@Success		201			{object}	models.SuccessResponse	"Photo waypoint created successfully"
 //	@Failure		400			{object}	models.ErrorResponse	"Invalid request or file"
 //	@Failure		401			{object}	models.ErrorResponse	"Authentication required"
@@ -563,32 +631,22 @@ func (h *WaypointHandler) UploadPhotoWaypoint(c echo.Context) error {
 		return apis.NewUnauthorizedError("Authentication required", nil)
 	}
 
-	// Get form parameters
-	sessionID := c.FormValue("session_id")
-	if sessionID == "" {
-		return apis.NewBadRequestError("session_id is required", nil)
-	}
-
-	// Verify user owns the session
-	session, err := h.app.Dao().FindRecordById("sessions", sessionID)
-	if err != nil {
-		return apis.NewNotFoundError("Session not found", err)
-	}
-
-	if session.GetString("user") != record.Id {
-		return apis.NewForbiddenError("Cannot create waypoints for another user's session", nil)
-	}
+	data := middleware.GetValidatedData(c).(*appmodels.UploadPhotoWaypointRequest)
+	session, _ := middleware.GetRequestSession(c)
+	sessionID := session.Id
+	ownerID := session.GetString("user")
 
 	// Get the uploaded photo
-	file, fileHeader, err := c.Request().FormFile("photo")
+	fileHeader := data.Photo
+	file, err := fileHeader.Open()
 	if err != nil {
-		return apis.NewBadRequestError("No photo file provided", err)
+		return apis.NewApiError(http.StatusInternalServerError, "Failed to read photo file", err)
 	}
 	defer file.Close()
 
 	// Validate file type
-	if !utils.IsValidImageFormat(fileHeader.Filename, fileHeader.Header.Get("Content-Type")) {
-		return apis.NewBadRequestError("Invalid image format. Supported formats: JPEG, TIFF", nil)
+	if detected, err := utils.SniffMultipartFile(fileHeader); err != nil || !utils.IsValidImageFormat(detected) {
+		return apis.NewBadRequestError("Invalid image format. Supported formats: JPEG, PNG, WebP, HEIC", nil)
 	}
 
 	// Extract EXIF data
@@ -612,7 +670,7 @@ func (h *WaypointHandler) UploadPhotoWaypoint(c echo.Context) error {
 		positionConfidence = "gps"
 	} else {
 		// Use intelligent fallback positioning
-		lat, lon, alt, confidence, err := h.getFallbackPosition(sessionID, exifData.Timestamp, record.Id)
+		lat, lon, alt, confidence, err := h.getFallbackPosition(session, photoCaptureTime(exifData, data.Timezone), ownerID)
 		if err != nil {
 			return apis.NewApiError(http.StatusInternalServerError,
 				fmt.Sprintf("No GPS data in photo and fallback positioning failed: %v", err), err)
@@ -624,7 +682,7 @@ func (h *WaypointHandler) UploadPhotoWaypoint(c echo.Context) error {
 	}
 
 	// Generate waypoint name if not provided
-	name := c.FormValue("name")
+	name := data.Name
 	if name == "" {
 		if exifData.Timestamp != nil {
 			name = fmt.Sprintf("Photo %s", exifData.Timestamp.Format("15:04"))
@@ -634,15 +692,15 @@ func (h *WaypointHandler) UploadPhotoWaypoint(c echo.Context) error {
 	}
 
 	// Get waypoint type, default to generic
-	waypointType := c.FormValue("type")
+	waypointType := data.Type
 	if waypointType == "" {
 		waypointType = "generic"
 	}
 
-	description := c.FormValue("description")
+	description := data.Description
 
 	// Create waypoint using PocketBase form handling for proper file association
-	collection, err := h.app.Dao().FindCollectionByNameOrId("waypoints")
+	collection, err := requestDao(h.app, c).FindCollectionByNameOrId("waypoints")
 	if err != nil {
 		return apis.NewApiError(http.StatusInternalServerError, "Waypoints collection not found", err)
 	}
@@ -650,6 +708,7 @@ func (h *WaypointHandler) UploadPhotoWaypoint(c echo.Context) error {
 	waypoint := models.NewRecord(collection)
 
 	// Pre-set fields that are not in the form
+	waypoint.Set("user", ownerID)
 	waypoint.Set("session_id", sessionID)
 	waypoint.Set("name", name)
 	waypoint.Set("type", waypointType)
@@ -658,10 +717,8 @@ func (h *WaypointHandler) UploadPhotoWaypoint(c echo.Context) error {
 	waypoint.Set("longitude", *longitude)
 	waypoint.Set("source", "photo")
 	waypoint.Set("position_confidence", positionConfidence)
-
-	if altitude != nil {
-		waypoint.Set("altitude", *altitude)
-	}
+	waypoint.Set("order", nextWaypointOrder(requestDao(h.app, c), sessionID))
+	utils.SetRecordAltitude(waypoint, altitude)
 
 	// Use PocketBase forms to handle the file upload properly
 	form := forms.NewRecordUpsert(h.app, waypoint)
@@ -692,11 +749,33 @@ func (h *WaypointHandler) UploadPhotoWaypoint(c echo.Context) error {
 	return utils.SendSuccess(c, http.StatusCreated, response, "Photo waypoint created successfully")
 }
 
+// canViewWaypoint reports whether a waypoint is visible to the requester: users whose role lets them
+// read the owner's waypoints see all of them, everyone else only unhidden ones linked to a public session
+func (h *WaypointHandler) canViewWaypoint(c echo.Context, waypoint *models.Record) bool {
+	if canAccess(c, constants.PermWaypointsRead, waypoint.GetString("user")) {
+		return true
+	}
+
+	sessionID := waypoint.GetString("session_id")
+	if sessionID == "" || waypoint.GetBool("hidden") {
+		return false
+	}
+
+	session, err := requestDao(h.app, c).FindRecordById("sessions", sessionID)
+	if err != nil {
+		return false
+	}
+
+	return isPublicSession(session)
+}
+
 // getFallbackPosition implements the intelligent positioning fallback logic
-func (h *WaypointHandler) getFallbackPosition(sessionID string, photoTimestamp *time.Time, userID string) (*float64, *float64, *float64, string, error) {
+func (h *WaypointHandler) getFallbackPosition(session *models.Record, photoTimestamp *time.Time, userID string) (*float64, *float64, *float64, string, error) {
+	sessionID := session.Id
+
 	// Priority 1: Time-based proximity matching with tracked locations
 	if photoTimestamp != nil {
-		if lat, lon, alt, err := h.findTimeMatchedLocation(sessionID, *photoTimestamp); err == nil {
+		if lat, lon, alt, err := h.findTimeMatchedLocation(session, *photoTimestamp); err == nil {
 			return lat, lon, alt, "time_matched", nil
 		}
 	}
@@ -720,58 +799,21 @@ func (h *WaypointHandler) getFallbackPosition(sessionID string, photoTimestamp *
 	return nil, nil, nil, "manual", fmt.Errorf("no fallback position available, manual placement required")
 }
 
-// findTimeMatchedLocation finds the closest tracked location by timestamp
-func (h *WaypointHandler) findTimeMatchedLocation(sessionID string, photoTime time.Time) (*float64, *float64, *float64, error) {
-	// Look for locations within 30 minutes of the photo timestamp
+// findTimeMatchedLocation finds the tracked position of the session at the photo time,
+// interpolated between locations or the closest location within 30 minutes
+func (h *WaypointHandler) findTimeMatchedLocation(session *models.Record, photoTime time.Time) (*float64, *float64, *float64, error) {
 	timeWindow := 30 * time.Minute
-	startTime := photoTime.Add(-timeWindow)
-	endTime := photoTime.Add(timeWindow)
-
-	locations, err := h.app.Dao().FindRecordsByFilter(
-		"locations",
-		"session = {:session} && timestamp >= {:start_time} && timestamp <= {:end_time}",
-		"timestamp ASC",
-		10, 0, // Get up to 10 closest locations
-		dbx.Params{
-			"session":    sessionID,
-			"start_time": startTime.Unix(),
-			"end_time":   endTime.Unix(),
-		},
-	)
 
-	if err != nil || len(locations) == 0 {
-		return nil, nil, nil, fmt.Errorf("no locations found within time window")
-	}
-
-	// Find the location with the smallest time difference
-	var closestLocation *models.Record
-	var minTimeDiff time.Duration = timeWindow + 1 // Initialize with a value larger than window
-
-	for _, location := range locations {
-		locationTime := location.GetDateTime("timestamp").Time()
-		timeDiff := photoTime.Sub(locationTime)
-		if timeDiff < 0 {
-			timeDiff = -timeDiff
-		}
-
-		if timeDiff < minTimeDiff {
-			minTimeDiff = timeDiff
-			closestLocation = location
-		}
-	}
-
-	if closestLocation == nil {
-		return nil, nil, nil, fmt.Errorf("no suitable time-matched location found")
+	position, err := findPositionAt(h.app.Dao(), "session = {:session}", dbx.Params{"session": session.Id},
+		photoTime, h.gapThresholds, timeWindow)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-
-	lat := closestLocation.GetFloat("latitude")
-	lon := closestLocation.GetFloat("longitude")
-	var alt *float64
-	if altitude := closestLocation.GetFloat("altitude"); altitude != 0 {
-		alt = &altitude
+	if position == nil {
+		return nil, nil, nil, fmt.Errorf("no locations found within time window")
 	}
 
-	return &lat, &lon, alt, nil
+	return &position.Latitude, &position.Longitude, position.Altitude, nil
 }
 
 // findLastTrackedLocation finds the last tracked location for a session
@@ -791,12 +833,7 @@ func (h *WaypointHandler) findLastTrackedLocation(sessionID string) (*float64, *
 	location := locations[0]
 	lat := location.GetFloat("latitude")
 	lon := location.GetFloat("longitude")
-	var alt *float64
-	if altitude := location.GetFloat("altitude"); altitude != 0 {
-		alt = &altitude
-	}
-
-	return &lat, &lon, alt, nil
+	return &lat, &lon, utils.RecordAltitude(location), nil
 }
 
 // findLastGPXTrackPoint finds the last point in the GPX track for a session
@@ -816,12 +853,7 @@ func (h *WaypointHandler) findLastGPXTrackPoint(sessionID string) (*float64, *fl
 	point := trackPoints[0]
 	lat := point.GetFloat("latitude")
 	lon := point.GetFloat("longitude")
-	var alt *float64
-	if altitude := point.GetFloat("altitude"); altitude != 0 {
-		alt = &altitude
-	}
-
-	return &lat, &lon, alt, nil
+	return &lat, &lon, utils.RecordAltitude(point), nil
 }
 
 // findLastKnownUserLocation finds the most recent location from any of the user's sessions
@@ -842,12 +874,7 @@ func (h *WaypointHandler) findLastKnownUserLocation(userID string) (*float64, *f
 	location := locations[0]
 	lat := location.GetFloat("latitude")
 	lon := location.GetFloat("longitude")
-	var alt *float64
-	if altitude := location.GetFloat("altitude"); altitude != 0 {
-		alt = &altitude
-	}
-
-	return &lat, &lon, alt, nil
+	return &lat, &lon, utils.RecordAltitude(location), nil
 }
 
 // formatWaypointResponse formats a waypoint record for API response
@@ -857,22 +884,29 @@ func (h *WaypointHandler) formatWaypointResponse(waypoint *models.Record) map[st
 		"name":                waypoint.GetString("name"),
 		"type":                waypoint.GetString("type"),
 		"description":         waypoint.GetString("description"),
+		"description_html":    utils.RenderMarkdown(waypoint.GetString("description")),
 		"latitude":            waypoint.GetFloat("latitude"),
 		"longitude":           waypoint.GetFloat("longitude"),
 		"session_id":          waypoint.GetString("session_id"),
 		"source":              waypoint.GetString("source"),
 		"position_confidence": waypoint.GetString("position_confidence"),
+		"order":               waypoint.GetInt("order"),
 		"created":             waypoint.GetDateTime("created").Time().Format(time.RFC3339),
 		"updated":             waypoint.GetDateTime("updated").Time().Format(time.RFC3339),
 	}
 
 	// Add optional fields
-	if altitude := waypoint.GetFloat("altitude"); altitude != 0 {
-		data["altitude"] = altitude
+	if altitude := utils.RecordAltitude(waypoint); altitude != nil {
+		data["altitude"] = *altitude
 	}
 
 	if photo := waypoint.GetString("photo"); photo != "" {
 		data["photo"] = photo
+		data["photo_url"] = signedFileURL(h.app, waypoint, "photo")
+	}
+
+	if visitedAt := waypoint.GetDateTime("visited_at"); !visitedAt.IsZero() {
+		data["visited_at"] = visitedAt.Time().Format(time.RFC3339)
 	}
 
 	return data
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/middleware"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/services"
+	"vibe-tracker/utils"
+)
+
+type WeatherHandler struct {
+	app     *pocketbase.PocketBase
+	weather *services.WeatherService
+}
+
+func NewWeatherHandler(app *pocketbase.PocketBase, weather *services.WeatherService) *WeatherHandler {
+	return &WeatherHandler{
+		app:     app,
+		weather: weather,
+	}
+}
+
+// GetRouteWeather returns the weather forecast along a session's planned route
+//
+//	@Summary		Get weather along planned route
+//	@Description	Samples the planned route of the session about every 10 km and returns the forecasted weather at each point for the hour it is reached when starting at the given time and moving at the given speed. Points reached in the past or more than 16 days ahead have no forecast.
+//	@Tags			Sessions
+//	@Produce		json
+//	@Param			username	path		string	true	"Username"
+//	@Param			name		path		string	true	"Session name"
+//	@Param			start		query		string	true	"Expected start time, RFC 3339 or Unix timestamp"
+//	@Param			speed		query		number	false	"Expected average speed in km/h (default: 5)"
+//	@Param			share_token	query		string	false	"Share token for private sessions"
+//	@Param			guest_token	query		string	false	"Guest viewer token"
+//	@Success		200			{object}	models.SuccessResponse{data=models.RouteWeatherResponse}	"Route weather retrieved successfully"
+//	@Failure		400			{object}	models.ErrorResponse										"Invalid start time or speed"
+//	@Failure		403			{object}	models.ErrorResponse										"Access denied"
+//	@Failure		404			{object}	models.ErrorResponse										"Session or planned route not found"
+//	@Failure		502			{object}	models.ErrorResponse										"Forecast API unavailable"
+//	@Failure		503			{object}	models.ErrorResponse										"Weather forecasts not configured"
+//	@Router			/sessions/{username}/{name}/weather [get]
+func (h *WeatherHandler) GetRouteWeather(c echo.Context) error {
+	user, exists := GetRequestUser(c)
+	if !exists {
+		return apis.NewNotFoundError("User not found", nil)
+	}
+
+	session, err := findSessionByNameAndUser(requestDao(h.app, c), c.PathParam("name"), user.Id)
+	if err != nil {
+		return apis.NewNotFoundError("Session not found", err)
+	}
+
+	if !hasSessionAccess(c, session) {
+		return apis.NewForbiddenError("Access denied", nil)
+	}
+
+	if !h.weather.Enabled() {
+		return apis.NewApiError(http.StatusServiceUnavailable, "Weather forecasts are not configured", nil)
+	}
+
+	params, ok := middleware.GetValidatedQuery(c).(*appmodels.RouteWeatherQueryParams)
+	if !ok {
+		return apis.NewBadRequestError("Invalid query parameters", nil)
+	}
+
+	start, err := utils.ParseTimeParam(params.Start)
+	if err != nil {
+		return apis.NewBadRequestError("Invalid start: "+err.Error(), nil)
+	}
+
+	speed := constants.DefaultPlanningSpeed
+	if params.Speed != nil {
+		speed = *params.Speed
+	}
+
+	weather, err := h.weather.RouteWeather(session, start, speed)
+	if err != nil {
+		return err // Let middleware handle the structured error
+	}
+
+	return utils.SendSuccess(c, http.StatusOK, weather, "")
+}
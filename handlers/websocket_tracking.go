@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+	"golang.org/x/net/websocket"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// TrackWebSocket streams tracked points over a WebSocket
+//
+//	@Summary		Track locations (WebSocket)
+//	@Description	Upgrades to a WebSocket on which the client streams points instead of sending a request per point, e.g. when tracking every second. Every text message is one JSON point with the fields of the GET /api/track query parameters; it is validated and stored like a GET /api/track request and acknowledged in order with its index, id and session, or the reason it was rejected. Rejected points do not close the connection. The session and device query parameters apply to points that do not set them. Connections without messages for 5 minutes are closed.
+//	@Tags			Tracking
+//	@Security		BearerAuth
+//	@Security		TokenAuth
+//	@Param			session				query		string	false	"Session of points without one"
+//	@Param			device				query		string	false	"Device of points without one"
+//	@Param			allow_historical	query		bool	false	"Accept timestamps older than the configured maximum age"
+//	@Success		101					{array}		models.TrackingSocketAck	"Switched to the WebSocket protocol, one acknowledgement is sent per point"
+//	@Failure		400					{object}	models.ErrorResponse		"Not a WebSocket request"
+//	@Failure		401					{object}	models.ErrorResponse		"Authentication required"
+//	@Router			/track/ws [get]
+func (h *TrackingHandler) TrackWebSocket(c echo.Context) error {
+	user, exists := GetAuthUser(c)
+	if !exists {
+		return apis.NewUnauthorizedError("Authentication required", nil)
+	}
+
+	if !strings.EqualFold(c.Request().Header.Get(echo.HeaderUpgrade), "websocket") {
+		return apis.NewBadRequestError("WebSocket upgrade required", nil)
+	}
+
+	defaults := appmodels.TrackingQueryParams{
+		Token:   constants.TrackingSocketSource,
+		Session: c.QueryParam("session"),
+		Device:  c.QueryParam("device"),
+	}
+	allowHistorical := c.QueryParam(constants.AllowHistoricalParam) == "true"
+	ctx := c.Request().Context()
+
+	server := websocket.Server{
+		// Clients authenticate with a token or JWT rather than cookies, so any origin may connect
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			conn.MaxPayloadBytes = constants.TrackingSocketMaxMessageSize
+			h.receivePoints(ctx, conn, user, defaults, allowHistorical)
+		},
+	}
+	server.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// receivePoints tracks the points streamed over a connection until the client closes it, stays
+// idle or sends a message that is too large
+func (h *TrackingHandler) receivePoints(ctx context.Context, conn *websocket.Conn, user *models.Record, defaults appmodels.TrackingQueryParams, allowHistorical bool) {
+	defer conn.Close()
+
+	for index := uint32(0); ; index++ {
+		conn.SetReadDeadline(time.Now().Add(constants.TrackingSocketIdleTimeout))
+		var message []byte
+		if err := websocket.Message.Receive(conn, &message); err != nil {
+			return
+		}
+
+		ack := h.trackSocketPoint(ctx, user, defaults, allowHistorical, message)
+		ack.Index = index
+
+		conn.SetWriteDeadline(time.Now().Add(constants.TrackingSocketWriteTimeout))
+		if err := websocket.JSON.Send(conn, ack); err != nil {
+			return
+		}
+	}
+}
+
+// trackSocketPoint validates a streamed point with the GET /api/track rules and stores it
+func (h *TrackingHandler) trackSocketPoint(ctx context.Context, user *models.Record, defaults appmodels.TrackingQueryParams, allowHistorical bool, message []byte) appmodels.TrackingSocketAck {
+	params := defaults
+	if err := json.Unmarshal(message, &params); err != nil {
+		return appmodels.TrackingSocketAck{Error: "Invalid JSON point"}
+	}
+	if err := utils.ValidateStruct(&params); err != nil {
+		return appmodels.TrackingSocketAck{Error: err.Error()}
+	}
+	if params.Timestamp != constants.DefaultTimestamp {
+		if err := h.validateTimestamp(params.Timestamp, allowHistorical); err != nil {
+			return appmodels.TrackingSocketAck{Error: err.Error()}
+		}
+	}
+
+	record, err := h.trackPoint(ctx, user, &params)
+	if err != nil {
+		var apiErr *apis.ApiError
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusInternalServerError {
+			utils.LogError(err, "websocket ingestion").Str("user_id", user.Id).Msg("Failed to store streamed point")
+		}
+		return appmodels.TrackingSocketAck{Error: err.Error()}
+	}
+
+	return appmodels.TrackingSocketAck{
+		ID:        record.Id,
+		Session:   utils.LocationSessionName(record),
+		Discarded: record.Id == "", // See sendTrackedPoint
+	}
+}
@@ -22,18 +22,12 @@
 package main
 
 import (
-	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/pocketbase"
-	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/plugins/migratecmd"
 
 	"vibe-tracker/config"
-	"vibe-tracker/constants"
-	"vibe-tracker/container"
-	_ "vibe-tracker/docs/api"
-	_ "vibe-tracker/migrations"
-	"vibe-tracker/models"
 	"vibe-tracker/utils"
+	"vibe-tracker/vibetracker"
 )
 
 func main() {
@@ -42,175 +36,16 @@ func main() {
 	// Load configuration
 	cfg := config.NewAppConfig()
 
-	// Initialize structured logger
-	utils.InitLogger(cfg)
+	// Register services, background jobs and routes
+	vibetracker.New(cfg).Attach(app)
 
 	// Register migration command
 	migratecmd.MustRegister(app, app.RootCmd, migratecmd.Config{
 		Automigrate: cfg.Automigrate,
 	})
 
-	// Initialize dependency injection container
-	di := container.NewContainer(app, cfg)
-
-	app.OnBeforeServe().Add(func(e *core.ServeEvent) error {
-		// Apply global middleware
-		setupGlobalMiddleware(e.Router, di, cfg)
-
-		// Setup API routes
-		setupAPIRoutes(e.Router, di)
-
-		// Setup documentation routes
-		setupDocumentationRoutes(e.Router, di)
-
-		// Setup health check routes
-		setupHealthRoutes(e.Router, di, cfg)
-
-		// Setup static routes
-		setupStaticRoutes(e.Router)
-
-		return nil
-	})
-
 	if err := app.Start(); err != nil {
 		utils.LogError(err, "failed to start application").Msg("Application startup failed")
 		panic(err)
 	}
 }
-
-// setupGlobalMiddleware configures global middleware in the correct order
-func setupGlobalMiddleware(router *echo.Echo, di *container.Container, cfg *config.AppConfig) {
-	router.Use(di.ErrorHandler.RecoveryMiddleware())
-	router.Use(di.ErrorHandler.SecurityHeaders(cfg.Security.HSTSEnabled, cfg.Security.CSPEnabled))
-	router.Use(di.ErrorHandler.CORSMiddleware(cfg.Security.CORSAllowedOrigins, cfg.Security.CORSAllowAll))
-	router.Use(di.InjectMiddleware())
-
-	// 404 Protection middleware - should be early in the chain
-	if di.NotFoundProtection != nil {
-		router.Use(di.NotFoundProtection.Middleware())
-	}
-
-	if di.SecurityMiddleware != nil {
-		router.Use(di.SecurityMiddleware.RequestSizeLimit())
-		router.Use(di.SecurityMiddleware.RequestTimeout())
-		router.Use(di.SecurityMiddleware.UserAgentFilter())
-		router.Use(di.SecurityMiddleware.FileUploadSecurity())
-		if cfg.Security.EnableRequestLogs {
-			router.Use(di.SecurityMiddleware.RequestLogging())
-		}
-	}
-}
-
-// setupAPIRoutes configures all API endpoints
-func setupAPIRoutes(router *echo.Echo, di *container.Container) {
-	api := router.Group(constants.APIPrefix)
-
-	// Location endpoints
-	var publicMiddleware []echo.MiddlewareFunc
-	if di.RateLimitMiddleware != nil {
-		publicMiddleware = append(publicMiddleware, di.RateLimitMiddleware.PublicEndpoints())
-	}
-
-	api.GET(constants.EndpointLocation, di.PublicHandler.GetLocation, append(publicMiddleware, di.UserMiddleware.LoadUserFromPath())...)
-	api.GET(constants.EndpointPublicLocation, di.PublicHandler.GetPublicLocations, publicMiddleware...)
-	api.GET("/session/:username/:session", di.PublicHandler.GetSessionData, append(publicMiddleware, di.UserMiddleware.LoadUserFromPath())...)
-
-	// Session management endpoints
-	var sessionMiddleware []echo.MiddlewareFunc
-	if di.RateLimitMiddleware != nil {
-		sessionMiddleware = append(sessionMiddleware, di.RateLimitMiddleware.SessionEndpoints())
-	}
-
-	api.GET("/sessions/:username", di.SessionHandler.ListSessions, append(sessionMiddleware, di.UserMiddleware.LoadUserFromPath())...)
-	api.GET("/sessions/:username/:name", di.SessionHandler.GetSession, append(sessionMiddleware, di.UserMiddleware.LoadUserFromPath())...)
-	api.POST("/sessions", di.SessionHandler.CreateSession, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.ValidationMiddleware.ValidateJSON(&models.CreateSessionRequest{}))...)
-	api.PUT("/sessions/:username/:name", di.SessionHandler.UpdateSession, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserOwnership(), di.ValidationMiddleware.ValidateJSON(&models.UpdateSessionRequest{}))...)
-	api.DELETE("/sessions/:username/:name", di.SessionHandler.DeleteSession, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserOwnership())...)
-
-	// GPX track endpoints
-	api.POST("/sessions/:username/:name/gpx", di.SessionHandler.UploadGPXTrack, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserOwnership())...)
-	api.GET("/sessions/:username/:name/track", di.SessionHandler.GetTrackData, append(sessionMiddleware, di.UserMiddleware.LoadUserFromPath())...)
-
-	// Waypoint endpoints
-	var waypointMiddleware []echo.MiddlewareFunc
-	if di.RateLimitMiddleware != nil {
-		waypointMiddleware = append(waypointMiddleware, di.RateLimitMiddleware.SessionEndpoints()) // Use same rate limiting as sessions
-	}
-
-	api.GET("/waypoints/:username", di.WaypointHandler.ListWaypoints, append(waypointMiddleware, di.UserMiddleware.LoadUserFromPath())...)
-	api.GET("/waypoints/by-session/:sessionId", di.WaypointHandler.ListWaypointsBySession, waypointMiddleware...)
-	api.GET("/waypoints/detail/:id", di.WaypointHandler.GetWaypoint, waypointMiddleware...)
-	api.POST("/waypoints", di.WaypointHandler.CreateWaypoint, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.ValidationMiddleware.ValidateJSON(&models.CreateWaypointRequest{}))...)
-	api.PUT("/waypoints/:id", di.WaypointHandler.UpdateWaypoint, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.ValidationMiddleware.ValidateJSON(&models.UpdateWaypointRequest{}))...)
-	api.DELETE("/waypoints/:id", di.WaypointHandler.DeleteWaypoint, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth())...)
-	api.POST("/waypoints/photo", di.WaypointHandler.UploadPhotoWaypoint, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth())...)
-
-	// Authentication endpoints
-	var authMiddleware []echo.MiddlewareFunc
-	if di.RateLimitMiddleware != nil {
-		authMiddleware = append(authMiddleware, di.RateLimitMiddleware.AuthEndpoints())
-	}
-	if di.AuthSecurityMiddleware != nil {
-		authMiddleware = append(authMiddleware, di.AuthSecurityMiddleware.BruteForceProtection())
-	}
-
-	api.POST(constants.EndpointLogin, di.AuthHandler.Login, append(authMiddleware, di.ValidationMiddleware.ValidateJSON(&models.LoginRequest{}))...)
-	api.POST("/auth/refresh", di.AuthHandler.RefreshToken, authMiddleware...)
-	api.GET("/me", di.AuthHandler.GetMe, di.AuthMiddleware.RequireJWTAuth())
-	api.PUT("/profile", di.AuthHandler.UpdateProfile, di.AuthMiddleware.RequireJWTAuth(), di.ValidationMiddleware.ValidateJSON(&models.UpdateProfileRequest{}))
-	api.POST("/profile/avatar", di.AuthHandler.UploadAvatar, di.AuthMiddleware.RequireJWTAuth())
-	api.PUT("/profile/regenerate-token", di.AuthHandler.RegenerateToken, di.AuthMiddleware.RequireJWTAuth())
-
-	// Tracking endpoints
-	var trackingMiddleware []echo.MiddlewareFunc
-	if di.RateLimitMiddleware != nil {
-		trackingMiddleware = append(trackingMiddleware, di.RateLimitMiddleware.TrackingEndpoints())
-	}
-
-	api.GET(constants.EndpointTrack, di.TrackingHandler.TrackLocationGET, append(trackingMiddleware, di.AuthMiddleware.RequireFlexibleAuth(), di.ValidationMiddleware.ValidateQueryParams(&models.TrackingQueryParams{}))...)
-	api.POST(constants.EndpointTrack, di.TrackingHandler.TrackLocationPOST, append(trackingMiddleware, di.AuthMiddleware.RequireFlexibleAuth(), di.ValidationMiddleware.ValidateJSON(&models.LocationRequest{}))...)
-}
-
-// setupDocumentationRoutes configures API documentation endpoints
-func setupDocumentationRoutes(router *echo.Echo, di *container.Container) {
-	var docsMiddleware []echo.MiddlewareFunc
-	if di.RateLimitMiddleware != nil {
-		docsMiddleware = append(docsMiddleware, di.RateLimitMiddleware.DocsEndpoints())
-	}
-
-	router.GET("/swagger/json", di.DocsHandler.ServeSwaggerJSON, docsMiddleware...)
-	router.GET("/swagger", di.DocsHandler.ServeSwaggerUI, docsMiddleware...)
-}
-
-// setupHealthRoutes configures health check endpoints
-func setupHealthRoutes(router *echo.Echo, di *container.Container, cfg *config.AppConfig) {
-	if cfg.Health.Enabled {
-		router.GET(constants.HealthLivenessEndpoint, di.HealthHandler.GetLiveness)
-		router.GET(constants.HealthReadinessEndpoint, di.HealthHandler.GetReadiness)
-		if cfg.Health.DetailedEnabled {
-			router.GET(constants.HealthEndpoint, di.HealthHandler.GetDetailedHealth)
-		}
-	}
-}
-
-// setupStaticRoutes configures frontend static file serving
-func setupStaticRoutes(router *echo.Echo) {
-	router.GET("/u/:username", func(c echo.Context) error {
-		return c.File("dist/index.html")
-	})
-
-	router.GET("/u/:username/s/:session", func(c echo.Context) error {
-		return c.File("dist/index.html")
-	})
-
-	// SPA fallback routes - serve index.html for frontend routes
-	router.GET("/profile", func(c echo.Context) error {
-		return c.File("dist/index.html")
-	})
-
-	router.GET("/profile/sessions", func(c echo.Context) error {
-		return c.File("dist/index.html")
-	})
-
-	router.Static("/", "dist")
-}
@@ -3,25 +3,50 @@ package middleware
 import (
 	"errors"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/utils"
 )
 
 const (
 	UserContextKey = "auth_user"
+
+	// Set when the request was authenticated with the tracking token sent as its device ID
+	deviceIDAuthContextKey = "device_id_auth"
 )
 
 // AuthMiddleware provides authentication middleware functions
 type AuthMiddleware struct {
-	app *pocketbase.PocketBase
+	app       *pocketbase.PocketBase
+	blacklist *TokenBlacklist
+	readOnly  bool
+
+	// Last-seen updates of device sessions are throttled to one write per interval
+	mu      sync.Mutex
+	touched map[string]time.Time
 }
 
-func NewAuthMiddleware(app *pocketbase.PocketBase) *AuthMiddleware {
-	return &AuthMiddleware{app: app}
+func NewAuthMiddleware(app *pocketbase.PocketBase, blacklist *TokenBlacklist) *AuthMiddleware {
+	return &AuthMiddleware{
+		app:       app,
+		blacklist: blacklist,
+		touched:   make(map[string]time.Time),
+	}
+}
+
+// WithReadOnly stops recording the last activity of device sessions, see READ_ONLY
+func (m *AuthMiddleware) WithReadOnly() *AuthMiddleware {
+	m.readOnly = true
+	return m
 }
 
 // RequireJWTAuth middleware that requires valid JWT authentication
@@ -48,6 +73,52 @@ func (m *AuthMiddleware) RequireJWTAuth() echo.MiddlewareFunc {
 	}
 }
 
+// RejectRevokedTokens middleware that drops the auth record PocketBase loaded from a token of a
+// revoked device session, or of none, so PocketBase's own APIs and collection rules treat the
+// request as unauthenticated too
+func (m *AuthMiddleware) RejectRevokedTokens() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if record, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record); record != nil {
+				token := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+				if _, err := m.checkDeviceSession(token); err != nil {
+					c.Set(apis.ContextAuthRecordKey, nil)
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireRecentAuth middleware that blocks sensitive actions from a device session flagged as a
+// suspicious login until the user re-enters their password, for the given window after the login.
+// It must run after RequireJWTAuth; a zero window disables the check.
+func (m *AuthMiddleware) RequireRecentAuth(window time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if window <= 0 {
+				return next(c)
+			}
+
+			sessionID := utils.TokenSessionID(strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer "))
+			if sessionID == "" {
+				return next(c)
+			}
+
+			loginAfter, _ := types.ParseDateTime(time.Now().Add(-window))
+			_, err := m.app.Dao().FindFirstRecordByFilter(constants.CollectionAuthSessions,
+				"jti = {:jti} && suspicious_reasons != '' && reauthenticated_at = '' && created > {:login_after}",
+				dbx.Params{"jti": sessionID, "login_after": loginAfter})
+			if err == nil {
+				return apis.NewForbiddenError("Re-authentication required after a suspicious login",
+					map[string]bool{"reauth_required": true})
+			}
+
+			return next(c)
+		}
+	}
+}
+
 // RequireCustomTokenAuth middleware that requires valid custom token authentication
 func (m *AuthMiddleware) RequireCustomTokenAuth() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -109,6 +180,10 @@ func (m *AuthMiddleware) RequireFlexibleAuth() echo.MiddlewareFunc {
 				customToken := c.QueryParam("token")
 				if customToken == "" && !strings.HasPrefix(authHeader, "Bearer ") {
 					customToken = authHeader
+					// Apps that only support HTTP basic authentication, like OwnTracks, send the token as the password
+					if _, password, ok := c.Request().BasicAuth(); ok {
+						customToken = password
+					}
 				}
 
 				if customToken != "" {
@@ -126,6 +201,39 @@ func (m *AuthMiddleware) RequireFlexibleAuth() echo.MiddlewareFunc {
 	}
 }
 
+// RequireDeviceIDAuth middleware for trackers that can only be configured with a server URL and a
+// device ID, like osmAnd/Traccar clients: the tracking token is entered as the device ID. A JWT or
+// the token query parameter are accepted as by RequireFlexibleAuth, the device ID is then only an
+// identifier.
+func (m *AuthMiddleware) RequireDeviceIDAuth() echo.MiddlewareFunc {
+	flexibleAuth := m.RequireFlexibleAuth()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		authenticated := flexibleAuth(next)
+		return func(c echo.Context) error {
+			deviceID := c.QueryParam(constants.OsmAndDeviceIDParam)
+			if deviceID == "" || c.QueryParam("token") != "" || c.Request().Header.Get("Authorization") != "" {
+				return authenticated(c)
+			}
+
+			record, err := m.findUserByToken(deviceID)
+			if err != nil {
+				return apis.NewUnauthorizedError("Valid authentication required", err)
+			}
+
+			c.Set(UserContextKey, record)
+			c.Set(deviceIDAuthContextKey, true)
+			return next(c)
+		}
+	}
+}
+
+// AuthenticatedByDeviceID reports whether the device ID of the request is the tracking token, which
+// must not be stored as the device
+func AuthenticatedByDeviceID(c echo.Context) bool {
+	authenticated, _ := c.Get(deviceIDAuthContextKey).(bool)
+	return authenticated
+}
+
 // OptionalAuth middleware that optionally extracts user if authenticated
 func (m *AuthMiddleware) OptionalAuth() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -160,6 +268,16 @@ func (m *AuthMiddleware) OptionalAuth() echo.MiddlewareFunc {
 	}
 }
 
+// AuthenticateToken resolves the user of an authorization value received outside of an HTTP
+// request, e.g. gRPC metadata. Like RequireFlexibleAuth it accepts "Bearer <jwt>" or the user's
+// tracking token.
+func (m *AuthMiddleware) AuthenticateToken(authorization string) (*models.Record, error) {
+	if token, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+		return m.getAuthRecordFromToken(token)
+	}
+	return m.findUserByToken(authorization)
+}
+
 // Helper function to get authenticated user from context
 func GetAuthUser(c echo.Context) (*models.Record, bool) {
 	user, exists := c.Get(UserContextKey).(*models.Record)
@@ -174,9 +292,60 @@ func (m *AuthMiddleware) getAuthRecordFromToken(token string) (*models.Record, e
 		return nil, err
 	}
 
+	sessionID, err := m.checkDeviceSession(token)
+	if err != nil {
+		return nil, err
+	}
+	m.touchDeviceSession(sessionID)
+
 	return record, nil
 }
 
+// checkDeviceSession returns the device session of a verified token, or an error when the session
+// was revoked. Tokens without a device session are rejected: they were issued before device
+// sessions existed and could not be revoked.
+func (m *AuthMiddleware) checkDeviceSession(token string) (string, error) {
+	sessionID := utils.TokenSessionID(token)
+	if sessionID == "" {
+		return "", errors.New("token is not bound to a device session")
+	}
+	if m.blacklist != nil && m.blacklist.IsBlacklisted(sessionID) {
+		return "", errors.New("token has been revoked")
+	}
+	return sessionID, nil
+}
+
+// touchDeviceSession records the last activity of a device session
+func (m *AuthMiddleware) touchDeviceSession(sessionID string) {
+	if m.readOnly {
+		return
+	}
+	now := time.Now()
+
+	m.mu.Lock()
+	if last, ok := m.touched[sessionID]; ok && now.Sub(last) < constants.AuthSessionTouchInterval {
+		m.mu.Unlock()
+		return
+	}
+	// Forget sessions that have been idle long enough to be written again anyway
+	for id, last := range m.touched {
+		if now.Sub(last) >= constants.AuthSessionTouchInterval {
+			delete(m.touched, id)
+		}
+	}
+	m.touched[sessionID] = now
+	m.mu.Unlock()
+
+	lastSeenAt, _ := types.ParseDateTime(now)
+	_, err := m.app.Dao().DB().NewQuery("UPDATE " + constants.CollectionAuthSessions +
+		" SET last_seen_at = {:last_seen_at} WHERE jti = {:jti}").
+		Bind(dbx.Params{"last_seen_at": lastSeenAt.String(), "jti": sessionID}).
+		Execute()
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Failed to update device session activity")
+	}
+}
+
 func (m *AuthMiddleware) findUserByToken(token string) (*models.Record, error) {
 	if token == "" {
 		return nil, errors.New("token is missing")
@@ -8,9 +8,19 @@ import (
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/pocketbase/apis"
 
+	"vibe-tracker/constants"
 	"vibe-tracker/utils"
 )
 
+// captchaProtectedRoutes are PocketBase API routes that always require a CAPTCHA when one is configured
+var captchaProtectedRoutes = map[string]bool{
+	http.MethodPost + " " + constants.APIPrefix + "/collections/users/records":                true, // Registration
+	http.MethodPost + " " + constants.APIPrefix + "/collections/users/request-password-reset": true,
+}
+
+// pocketBaseLoginRoute is PocketBase's password login, protected like the app's own login
+const pocketBaseLoginRoute = http.MethodPost + " " + constants.APIPrefix + "/collections/users/auth-with-password"
+
 // LoginAttempt tracks failed login attempts
 type LoginAttempt struct {
 	Count       int
@@ -27,9 +37,14 @@ type AuthSecurityMiddleware struct {
 	cleanupTicker     *time.Ticker
 	done              chan bool
 	enableLogging     bool
+
+	// Optional CAPTCHA, required for logins once a client reached captchaAfterFailures failed attempts
+	captcha              *CaptchaVerifier
+	captchaAfterFailures int
 }
 
-// NewAuthSecurityMiddleware creates a new authentication security middleware
+// NewAuthSecurityMiddleware creates a new authentication security middleware. A maxFailedAttempts
+// of 0 disables lockouts while failed attempts are still counted for the CAPTCHA requirement.
 func NewAuthSecurityMiddleware(maxFailedAttempts int, lockoutDuration time.Duration, enableLogging bool) *AuthSecurityMiddleware {
 	m := &AuthSecurityMiddleware{
 		failedAttempts:    make(map[string]*LoginAttempt),
@@ -46,6 +61,14 @@ func NewAuthSecurityMiddleware(maxFailedAttempts int, lockoutDuration time.Durat
 	return m
 }
 
+// WithCaptcha enables CAPTCHA verification on registration and password reset, and on logins from
+// clients with at least afterFailures failed attempts
+func (m *AuthSecurityMiddleware) WithCaptcha(captcha *CaptchaVerifier, afterFailures int) *AuthSecurityMiddleware {
+	m.captcha = captcha
+	m.captchaAfterFailures = afterFailures
+	return m
+}
+
 // cleanup removes old failed attempt records
 func (m *AuthSecurityMiddleware) cleanup() {
 	for {
@@ -91,6 +114,13 @@ func (m *AuthSecurityMiddleware) BruteForceProtection() echo.MiddlewareFunc {
 				})
 			}
 
+			// Clients with repeated failures have to solve a CAPTCHA before credentials are checked
+			if m.captcha != nil && m.getAttemptCount(clientIP) >= m.captchaAfterFailures {
+				if err := m.verifyCaptcha(c); err != nil {
+					return err
+				}
+			}
+
 			// Execute the handler
 			err := next(c)
 
@@ -118,6 +148,48 @@ func (m *AuthSecurityMiddleware) BruteForceProtection() echo.MiddlewareFunc {
 	}
 }
 
+// CaptchaProtection middleware that requires a CAPTCHA on registration and password reset requests.
+// PocketBase's password login gets the lockout and CAPTCHA requirement of BruteForceProtection.
+func (m *AuthSecurityMiddleware) CaptchaProtection() echo.MiddlewareFunc {
+	bruteForceProtection := m.BruteForceProtection()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		protectedLogin := bruteForceProtection(next)
+		return func(c echo.Context) error {
+			route := c.Request().Method + " " + c.Request().URL.Path
+			if route == pocketBaseLoginRoute {
+				return protectedLogin(c)
+			}
+			if m.captcha == nil || !captchaProtectedRoutes[route] {
+				return next(c)
+			}
+
+			if err := m.verifyCaptcha(c); err != nil {
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// verifyCaptcha checks the CAPTCHA token sent with the request
+func (m *AuthSecurityMiddleware) verifyCaptcha(c echo.Context) error {
+	err := m.captcha.Verify(c.Request().Header.Get(constants.CaptchaTokenHeader), c.RealIP())
+	if err == nil {
+		return nil
+	}
+
+	if m.enableLogging {
+		utils.LogSuspiciousRequest(m.getClientIP(c), c.Request().Header.Get("User-Agent"), c.Request().URL.Path, "captcha_failed")
+	}
+
+	// Forbidden rather than unauthorized, so a missing CAPTCHA does not count as a failed login
+	return apis.NewForbiddenError("CAPTCHA verification required", map[string]any{
+		"captcha_required": true,
+		"provider":         m.captcha.Provider(),
+	})
+}
+
 // getClientIP extracts the client IP address
 func (m *AuthSecurityMiddleware) getClientIP(c echo.Context) string {
 	// Try X-Forwarded-For first (for reverse proxies)
@@ -136,6 +208,10 @@ func (m *AuthSecurityMiddleware) getClientIP(c echo.Context) string {
 
 // isLockedOut checks if a client is currently locked out
 func (m *AuthSecurityMiddleware) isLockedOut(clientID string) bool {
+	if m.maxFailedAttempts <= 0 {
+		return false
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -170,7 +246,7 @@ func (m *AuthSecurityMiddleware) recordFailedAttempt(clientID string) {
 	attempt.LastAttempt = now
 
 	// If max attempts reached, set lockout period
-	if attempt.Count >= m.maxFailedAttempts {
+	if m.maxFailedAttempts > 0 && attempt.Count >= m.maxFailedAttempts {
 		attempt.LockedUntil = now.Add(m.lockoutDuration)
 
 		if m.enableLogging {
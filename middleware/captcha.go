@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/utils"
+)
+
+// CaptchaConfig holds the CAPTCHA verification settings
+type CaptchaConfig struct {
+	Provider      string // hcaptcha, turnstile or pow
+	SiteKey       string // Public site key of hCaptcha or Turnstile
+	Secret        string // Verification secret; generated per process for pow when empty
+	PoWDifficulty int    // Leading zero bits required by proof-of-work solutions
+}
+
+// CaptchaVerifier checks CAPTCHA responses with hCaptcha or Cloudflare Turnstile, or solutions to
+// the proof-of-work challenges it issues
+type CaptchaVerifier struct {
+	config CaptchaConfig
+	client *http.Client
+
+	// Solved proof-of-work challenges are remembered until they expire, so each is used once
+	mu     sync.Mutex
+	solved map[string]time.Time
+}
+
+// NewCaptchaVerifier creates a new CAPTCHA verifier
+func NewCaptchaVerifier(config CaptchaConfig) *CaptchaVerifier {
+	if config.Provider == constants.CaptchaProviderPoW && config.Secret == "" {
+		config.Secret = security.RandomString(32)
+	}
+	if config.PoWDifficulty <= 0 {
+		config.PoWDifficulty = constants.DefaultPoWDifficulty
+	}
+
+	return &CaptchaVerifier{
+		config: config,
+		client: &http.Client{Timeout: constants.CaptchaVerifyTimeout},
+		solved: make(map[string]time.Time),
+	}
+}
+
+// Provider returns the configured CAPTCHA provider
+func (v *CaptchaVerifier) Provider() string {
+	return v.config.Provider
+}
+
+// SiteKey returns the public site key clients render the CAPTCHA widget with
+func (v *CaptchaVerifier) SiteKey() string {
+	return v.config.SiteKey
+}
+
+// PoWDifficulty returns the leading zero bits required by proof-of-work solutions
+func (v *CaptchaVerifier) PoWDifficulty() int {
+	return v.config.PoWDifficulty
+}
+
+// NewPoWChallenge issues a proof-of-work challenge and returns it with its expiry
+func (v *CaptchaVerifier) NewPoWChallenge() (string, time.Time) {
+	now := time.Now()
+	return utils.NewPoWChallenge(v.config.Secret, now, constants.PoWChallengeTTL), now.Add(constants.PoWChallengeTTL)
+}
+
+// Verify checks a CAPTCHA response or proof-of-work solution submitted by a client
+func (v *CaptchaVerifier) Verify(token, remoteIP string) error {
+	if token == "" {
+		return errors.New("captcha token missing")
+	}
+
+	switch v.config.Provider {
+	case constants.CaptchaProviderPoW:
+		return v.verifyPoW(token)
+	case constants.CaptchaProviderHCaptcha:
+		return v.verifySiteVerify(constants.HCaptchaVerifyURL, token, remoteIP)
+	case constants.CaptchaProviderTurnstile:
+		return v.verifySiteVerify(constants.TurnstileVerifyURL, token, remoteIP)
+	default:
+		return fmt.Errorf("unknown captcha provider %q", v.config.Provider)
+	}
+}
+
+func (v *CaptchaVerifier) verifyPoW(token string) error {
+	now := time.Now()
+	challenge, err := utils.VerifyPoWSolution(v.config.Secret, token, v.config.PoWDifficulty, now)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for solved, expiresAt := range v.solved {
+		if now.After(expiresAt) {
+			delete(v.solved, solved)
+		}
+	}
+	if _, used := v.solved[challenge]; used {
+		return errors.New("proof-of-work challenge already used")
+	}
+	v.solved[challenge] = now.Add(constants.PoWChallengeTTL)
+
+	return nil
+}
+
+// verifySiteVerify checks a response token with the siteverify API shared by hCaptcha and Turnstile
+func (v *CaptchaVerifier) verifySiteVerify(verifyURL, token, remoteIP string) error {
+	form := url.Values{
+		"secret":   {v.config.Secret},
+		"response": {token},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	resp, err := v.client.PostForm(verifyURL, form)
+	if err != nil {
+		return fmt.Errorf("captcha verification failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("invalid captcha verification response: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("captcha rejected: %v", result.ErrorCodes)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/utils"
+)
+
+// ErrorReporter forwards incidents to Sentry or a Sentry compatible service such as GlitchTip
+type ErrorReporter struct {
+	hub *sentry.Hub
+}
+
+// NewErrorReporter initializes the Sentry SDK for a DSN. Besides request failures reported by
+// the reporter, background jobs then report their failures through utils.ReportJobError.
+func NewErrorReporter(dsn, environment string) (*ErrorReporter, error) {
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:         dsn,
+		Environment: environment,
+		Release:     "vibe-tracker@" + constants.AppVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ErrorReporter{hub: sentry.CurrentHub()}, nil
+}
+
+// Middleware reports errors returned by handlers that end in a 5xx response. Client errors are
+// expected and only logged.
+func (r *ErrorReporter) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			if err != nil && errorStatus(err) >= http.StatusInternalServerError {
+				r.report(c, err, func(scope *sentry.Scope) {
+					scope.SetLevel(sentry.LevelError)
+				})
+			}
+			return err
+		}
+	}
+}
+
+// ReportPanic reports a panic recovered while serving a request. The incident ID returned to the
+// client is attached as a tag, so support requests can be matched to the report.
+func (r *ErrorReporter) ReportPanic(c echo.Context, incidentID string, err error, stack []byte) {
+	r.report(c, err, func(scope *sentry.Scope) {
+		scope.SetLevel(sentry.LevelFatal)
+		scope.SetTag("incident_id", incidentID)
+		// Panics of handlers running behind the timeout middleware are re-raised on another
+		// goroutine, the captured trace is the one of the original goroutine
+		scope.SetContext("panic", sentry.Context{"stack": string(stack)})
+	})
+}
+
+// report captures a request failure tagged with the route, request ID and authenticated user
+func (r *ErrorReporter) report(c echo.Context, err error, configure func(scope *sentry.Scope)) {
+	// Requests are served concurrently, each report gets its own scope stack
+	hub := r.hub.Clone()
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetRequest(c.Request())
+		scope.SetTag("route", c.Path())
+		scope.SetTag("request_id", GetRequestID(c))
+		if user := requestAuthRecord(c); user != nil {
+			scope.SetUser(sentry.User{ID: user.Id})
+			scope.SetTag("user_id", user.Id)
+		}
+		configure(scope)
+		hub.CaptureException(err)
+	})
+}
+
+// requestAuthRecord returns the user authenticated by our JWT middleware or by PocketBase
+func requestAuthRecord(c echo.Context) *models.Record {
+	if user, ok := GetAuthUser(c); ok {
+		return user
+	}
+	user, _ := c.Get(apis.ContextAuthRecordKey).(*models.Record)
+	return user
+}
+
+// errorStatus returns the HTTP status a handler error is answered with
+func errorStatus(err error) int {
+	var apiErr *apis.ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// Stop delivers queued reports before the app exits
+func (r *ErrorReporter) Stop(e *core.TerminateEvent) error {
+	if !r.hub.Flush(constants.SentryFlushTimeout) {
+		utils.LogWarn().Msg("Timed out delivering queued error reports")
+	}
+	return nil
+}
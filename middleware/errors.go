@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"runtime/debug"
 	"strings"
 
 	"github.com/labstack/echo/v5"
@@ -11,39 +12,81 @@ import (
 	"vibe-tracker/utils"
 )
 
+// RequestIDContextKey is the context key of the ID assigned to each request
+const RequestIDContextKey = "request_id"
+
 // ErrorHandler provides error handling middleware and utilities
-type ErrorHandler struct{}
+type ErrorHandler struct {
+	reporter *ErrorReporter // nil when error reporting is disabled
+}
 
 func NewErrorHandler() *ErrorHandler {
 	return &ErrorHandler{}
 }
 
-// RecoveryMiddleware recovers from panics and returns proper error responses
+// WithErrorReporter enables reporting recovered panics, a nil reporter leaves reporting disabled
+func (h *ErrorHandler) WithErrorReporter(reporter *ErrorReporter) *ErrorHandler {
+	h.reporter = reporter
+	return h
+}
+
+// recoveredPanic carries a panic recovered on another goroutine to RecoveryMiddleware, together
+// with the stack of the goroutine that panicked
+type recoveredPanic struct {
+	value any
+	stack []byte
+}
+
+// RecoveryMiddleware recovers from panics, logs them with their stack trace under a new incident
+// ID and returns a 500 response carrying that ID, so users can refer to the incident
 func (h *ErrorHandler) RecoveryMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			defer func() {
-				if r := recover(); r != nil {
-					var err error
-					switch x := r.(type) {
-					case string:
-						err = fmt.Errorf("panic: %s", x)
-					case error:
-						err = fmt.Errorf("panic: %w", x)
-					default:
-						err = fmt.Errorf("panic: %v", x)
-					}
+				r := recover()
+				if r == nil {
+					return
+				}
+				// Aborting a response on purpose is not an incident
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
 
-					utils.LogError(err, "panic recovered").
-						Str("method", c.Request().Method).
-						Str("path", c.Request().URL.Path).
-						Msg("Request panic recovered")
+				stack := debug.Stack()
+				if recovered, ok := r.(*recoveredPanic); ok {
+					r, stack = recovered.value, recovered.stack
+				}
 
-					if !c.Response().Committed {
-						c.JSON(http.StatusInternalServerError, map[string]string{
-							"error": "Internal server error",
-						})
-					}
+				var err error
+				switch x := r.(type) {
+				case string:
+					err = fmt.Errorf("panic: %s", x)
+				case error:
+					err = fmt.Errorf("panic: %w", x)
+				default:
+					err = fmt.Errorf("panic: %v", x)
+				}
+
+				incidentID := utils.NewIncidentID()
+				utils.LogError(err, "panic recovered").
+					Str("incident_id", incidentID).
+					Str("request_id", GetRequestID(c)).
+					Str("method", c.Request().Method).
+					Str("path", c.Request().URL.Path).
+					Str("stack", string(stack)).
+					Msg("Request panic recovered")
+
+				if h.reporter != nil {
+					h.reporter.ReportPanic(c, incidentID, err, stack)
+				}
+
+				if !c.Response().Committed {
+					c.Response().Header().Set(constants.IncidentIDHeader, incidentID)
+					c.JSON(http.StatusInternalServerError, ErrorResponse{
+						Code:       http.StatusInternalServerError,
+						Message:    "Internal server error",
+						IncidentID: incidentID,
+					})
 				}
 			}()
 			return next(c)
@@ -51,6 +94,43 @@ func (h *ErrorHandler) RecoveryMiddleware() echo.MiddlewareFunc {
 	}
 }
 
+// RequestID assigns every request an ID, taken from the X-Request-ID header when the caller sent a
+// usable one, and returns it in the response so logs and error reports can be correlated
+func (h *ErrorHandler) RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Request().Header.Get(constants.RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = utils.NewRequestID()
+			}
+
+			c.Set(RequestIDContextKey, requestID)
+			c.Response().Header().Set(constants.RequestIDHeader, requestID)
+
+			return next(c)
+		}
+	}
+}
+
+// validRequestID accepts short IDs of letters, digits and the separators commonly used by proxies
+func validRequestID(id string) bool {
+	if id == "" || len(id) > constants.RequestIDMaxLength {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// GetRequestID returns the ID assigned to the request by the RequestID middleware
+func GetRequestID(c echo.Context) string {
+	requestID, _ := c.Get(RequestIDContextKey).(string)
+	return requestID
+}
+
 // LoggingMiddleware logs requests and responses
 func (h *ErrorHandler) LoggingMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -120,7 +200,8 @@ func (h *ErrorHandler) CORSMiddleware(allowedOrigins []string, allowAll bool) ec
 			}
 
 			c.Response().Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Response().Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+			c.Response().Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-Guest-Token, X-Captcha-Token")
+			c.Response().Header().Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After, X-Request-ID, X-Incident-ID")
 			c.Response().Header().Set("Access-Control-Allow-Credentials", "true")
 			c.Response().Header().Set("Access-Control-Max-Age", "86400") // 24 hours
 
@@ -206,9 +287,10 @@ func (h *ErrorHandler) buildCSP(c echo.Context) string {
 
 // ErrorResponse standardizes error responses
 type ErrorResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+	Details    string `json:"details,omitempty"`
+	IncidentID string `json:"incident_id,omitempty"` // Set for unexpected failures that were logged
 }
 
 // StandardizeErrors converts various error types to consistent API responses
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/utils"
+)
+
+const GuestContextKey = "guest_viewer"
+
+// GuestMiddleware resolves anonymous guest viewer tokens minted from share links
+type GuestMiddleware struct {
+	app *pocketbase.PocketBase
+}
+
+func NewGuestMiddleware(app *pocketbase.PocketBase) *GuestMiddleware {
+	return &GuestMiddleware{app: app}
+}
+
+// LoadGuestToken middleware that verifies an optional guest token from the X-Guest-Token header
+// or guest_token query parameter and stores the viewer claims in the context. Requests without
+// a token pass through unchanged; invalid or expired tokens are rejected so clients can mint a new one.
+func (m *GuestMiddleware) LoadGuestToken() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := c.Request().Header.Get(constants.GuestTokenHeader)
+			if token == "" {
+				token = c.QueryParam(constants.GuestTokenQueryParam)
+			}
+			if token == "" {
+				return next(c)
+			}
+
+			claims, err := utils.ParseGuestToken(GuestTokenSecret(m.app), token, time.Now())
+			if err != nil {
+				return apis.NewUnauthorizedError("Invalid or expired guest token", err)
+			}
+
+			c.Set(GuestContextKey, claims)
+
+			utils.LogInfo().
+				Str("viewer_id", claims.ViewerID).
+				Str("session_id", claims.SessionID).
+				Str("method", c.Request().Method).
+				Str("path", c.Request().URL.Path).
+				Msg("Guest viewer access")
+
+			return next(c)
+		}
+	}
+}
+
+// GuestTokenSecret returns the key used to sign guest tokens
+func GuestTokenSecret(app *pocketbase.PocketBase) string {
+	return app.Settings().RecordAuthToken.Secret
+}
+
+// Helper function to get the guest viewer claims loaded by LoadGuestToken
+func GetGuestViewer(c echo.Context) (*utils.GuestClaims, bool) {
+	claims, exists := c.Get(GuestContextKey).(*utils.GuestClaims)
+	return claims, exists
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// LatencyBudgetConfig holds the p95 latency budgets of routes
+type LatencyBudgetConfig struct {
+	DefaultBudget time.Duration            // Budget of routes without their own (0 = unmonitored)
+	RouteBudgets  map[string]time.Duration // Budgets keyed by utils.RouteKey
+	AlertAfter    time.Duration            // How long a route must stay over budget before an alert
+}
+
+// LatencyMonitor records the p95 latency of every route per minute and raises an alert when a
+// route stays over its budget, which usually means the database needs maintenance
+type LatencyMonitor struct {
+	config  LatencyBudgetConfig
+	onAlert func(event appmodels.LatencyBudgetEvent)
+
+	mu     sync.Mutex
+	routes map[string]*routeLatency
+}
+
+// routeLatency holds the samples of the current window of a route and how long it is over budget
+type routeLatency struct {
+	windowStart time.Time
+	samples     []time.Duration
+	seen        int
+
+	overSince time.Time // Zero while the route is within its budget
+	alerted   bool
+}
+
+// NewLatencyMonitor creates a new latency monitor
+func NewLatencyMonitor(config LatencyBudgetConfig) *LatencyMonitor {
+	return &LatencyMonitor{
+		config: config,
+		routes: make(map[string]*routeLatency),
+	}
+}
+
+// WithAlertHandler sets a function notified when a route exceeds its budget, e.g. to send a webhook
+func (m *LatencyMonitor) WithAlertHandler(onAlert func(event appmodels.LatencyBudgetEvent)) *LatencyMonitor {
+	m.onAlert = onAlert
+	return m
+}
+
+// Middleware measures the handling time of requests to registered routes
+func (m *LatencyMonitor) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			// Event streams and upgraded connections stay open by design
+			path := c.Path()
+			if path == "" || c.Request().Header.Get("Upgrade") != "" ||
+				strings.HasPrefix(c.Response().Header().Get(echo.HeaderContentType), "text/event-stream") {
+				return err
+			}
+
+			m.Record(utils.RouteKey(c.Request().Method, path), time.Since(start), time.Now())
+			return err
+		}
+	}
+}
+
+// Record adds the duration of a request to a route. Once the window of the route is over its p95
+// is compared to the budget.
+func (m *LatencyMonitor) Record(route string, duration time.Duration, now time.Time) {
+	budget, ok := m.config.RouteBudgets[route]
+	if !ok {
+		budget = m.config.DefaultBudget
+	}
+	if budget <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.routes[route]
+	if !ok {
+		state = &routeLatency{windowStart: now}
+		m.routes[route] = state
+	}
+
+	if now.Sub(state.windowStart) >= constants.LatencyWindow {
+		m.closeWindow(route, state, budget, now)
+	}
+
+	// Busy routes keep a uniform sample of their requests
+	state.seen++
+	if len(state.samples) < constants.LatencySampleLimit {
+		state.samples = append(state.samples, duration)
+	} else if i := rand.Intn(state.seen); i < constants.LatencySampleLimit {
+		state.samples[i] = duration
+	}
+}
+
+// closeWindow checks the p95 of the finished window and starts a new one. Callers hold the lock.
+func (m *LatencyMonitor) closeWindow(route string, state *routeLatency, budget time.Duration, now time.Time) {
+	p95 := utils.Percentile(state.samples, constants.LatencyPercentile)
+
+	// A route without requests for a whole window has no latency to judge, the streak starts over
+	if p95 <= budget || now.Sub(state.windowStart) >= 2*constants.LatencyWindow {
+		if state.alerted {
+			utils.LogInfo().Str("route", route).Dur("p95", p95).Dur("budget", budget).Msg("Route is back within its latency budget")
+		}
+		state.overSince = time.Time{}
+		state.alerted = false
+	}
+	if p95 > budget {
+		if state.overSince.IsZero() {
+			state.overSince = state.windowStart
+		}
+
+		if !state.alerted && now.Sub(state.overSince) >= m.config.AlertAfter {
+			state.alerted = true
+			m.alert(appmodels.LatencyBudgetEvent{
+				Route:        route,
+				P95Millis:    p95.Milliseconds(),
+				BudgetMillis: budget.Milliseconds(),
+				OverSince:    state.overSince.UTC(),
+			})
+		}
+	}
+
+	state.windowStart = now
+	state.samples = state.samples[:0]
+	state.seen = 0
+}
+
+func (m *LatencyMonitor) alert(event appmodels.LatencyBudgetEvent) {
+	utils.LogWarn().
+		Str("route", event.Route).
+		Int64("p95_ms", event.P95Millis).
+		Int64("budget_ms", event.BudgetMillis).
+		Time("over_since", event.OverSince).
+		Msg("Route exceeds its latency budget, the database may need maintenance")
+
+	if m.onAlert != nil {
+		// Notifications may block, requests should not wait for them
+		go m.onAlert(event)
+	}
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/utils"
+)
+
+const (
+	OrganizationContextKey = "organization"
+	OrgRoleContextKey      = "organization_role"
+	OrgAPIKeyContextKey    = "organization_api_key"
+)
+
+// orgRoleRanks orders organization roles so that higher roles include lower ones
+var orgRoleRanks = map[string]int{
+	constants.OrgRoleMember: 1,
+	constants.OrgRoleAdmin:  2,
+	constants.OrgRoleOwner:  3,
+}
+
+// OrgMiddleware provides organization lookup and role checks
+type OrgMiddleware struct {
+	app      *pocketbase.PocketBase
+	readOnly bool
+}
+
+func NewOrgMiddleware(app *pocketbase.PocketBase) *OrgMiddleware {
+	return &OrgMiddleware{app: app}
+}
+
+// WithReadOnly stops recording when API keys were last used, see READ_ONLY
+func (m *OrgMiddleware) WithReadOnly() *OrgMiddleware {
+	m.readOnly = true
+	return m
+}
+
+// RequireOrgRole middleware that loads the organization from the :org path parameter (slug)
+// and ensures the caller holds at least the given role, either as an authenticated member
+// or through an organization API key. Authentication middleware must run before it.
+func (m *OrgMiddleware) RequireOrgRole(minRole string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			slug := c.PathParam("org")
+			if slug == "" {
+				return apis.NewBadRequestError("Organization parameter is required", nil)
+			}
+
+			organization, err := m.app.Dao().FindFirstRecordByFilter(constants.CollectionOrganizations,
+				"slug = {:slug}", dbx.Params{"slug": slug})
+			if err != nil {
+				return apis.NewNotFoundError("Organization not found", err)
+			}
+
+			role := ""
+			if authUser, exists := GetAuthUser(c); exists {
+				membership, err := m.app.Dao().FindFirstRecordByFilter(constants.CollectionOrganizationMembers,
+					"organization = {:organization} && user = {:user}",
+					dbx.Params{"organization": organization.Id, "user": authUser.Id})
+				if err == nil {
+					role = membership.GetString("role")
+				}
+			} else if key := c.Request().Header.Get(constants.OrgAPIKeyHeader); key != "" {
+				apiKey, err := m.findAPIKey(organization.Id, key)
+				if err != nil {
+					return apis.NewUnauthorizedError("Invalid organization API key", err)
+				}
+				role = apiKey.GetString("role")
+				c.Set(OrgAPIKeyContextKey, apiKey)
+			} else {
+				return apis.NewUnauthorizedError("Authentication required", nil)
+			}
+
+			if !HasOrgRole(role, minRole) {
+				return apis.NewForbiddenError("Insufficient organization role", nil)
+			}
+
+			c.Set(OrganizationContextKey, organization)
+			c.Set(OrgRoleContextKey, role)
+			return next(c)
+		}
+	}
+}
+
+// HasOrgRole reports whether role grants at least the permissions of minRole
+func HasOrgRole(role, minRole string) bool {
+	rank, ok := orgRoleRanks[role]
+	return ok && rank >= orgRoleRanks[minRole]
+}
+
+// Helper function to get the organization loaded by RequireOrgRole
+func GetOrganization(c echo.Context) (*models.Record, bool) {
+	organization, exists := c.Get(OrganizationContextKey).(*models.Record)
+	return organization, exists
+}
+
+// Helper function to get the caller's role in the loaded organization
+func GetOrgRole(c echo.Context) string {
+	role, _ := c.Get(OrgRoleContextKey).(string)
+	return role
+}
+
+// Private helper method
+func (m *OrgMiddleware) findAPIKey(organizationID, key string) (*models.Record, error) {
+	apiKey, err := m.app.Dao().FindFirstRecordByFilter(constants.CollectionOrganizationAPIKeys,
+		"organization = {:organization} && key_hash = {:hash}",
+		dbx.Params{"organization": organizationID, "hash": utils.HashToken(key)})
+	if err != nil {
+		return nil, err
+	}
+
+	// Usage tracking is best effort and must not block the request
+	if m.readOnly {
+		return apiKey, nil
+	}
+	apiKey.Set("last_used_at", types.NowDateTime())
+	if err := m.app.Dao().SaveRecord(apiKey); err != nil {
+		utils.LogWarn().Err(err).Str("api_key_id", apiKey.Id).Msg("Failed to update API key usage")
+	}
+
+	return apiKey, nil
+}
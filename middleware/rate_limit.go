@@ -2,7 +2,11 @@ package middleware
 
 import (
 	"fmt"
+	"math"
 	"net"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,6 +14,7 @@ import (
 	"github.com/pocketbase/pocketbase/apis"
 	"golang.org/x/time/rate"
 
+	"vibe-tracker/constants"
 	"vibe-tracker/utils"
 )
 
@@ -22,6 +27,7 @@ const (
 	SessionEndpoints
 	PublicEndpoints
 	DocsEndpoints
+	GuestEndpoints
 )
 
 // RateLimitConfig defines rate limit configuration for different endpoint types
@@ -48,16 +54,18 @@ type RateLimitMiddleware struct {
 	sessionLimiter *RateLimiter
 	publicLimiter  *RateLimiter
 	docsLimiter    *RateLimiter
+	guestLimiter   *RateLimiter
 }
 
 // NewRateLimitMiddleware creates a new rate limiting middleware
 func NewRateLimitMiddleware() *RateLimitMiddleware {
 	configs := map[RateLimitType]RateLimitConfig{
-		AuthEndpoints:     {RequestsPerMinute: 5, BurstSize: 2},    // Strict for auth
-		TrackingEndpoints: {RequestsPerMinute: 60, BurstSize: 10},  // High for tracking
-		SessionEndpoints:  {RequestsPerMinute: 30, BurstSize: 5},   // Moderate for sessions
-		PublicEndpoints:   {RequestsPerMinute: 100, BurstSize: 20}, // Generous for public
-		DocsEndpoints:     {RequestsPerMinute: 10, BurstSize: 3},   // Low for docs
+		AuthEndpoints:     {RequestsPerMinute: constants.AuthRateLimit, BurstSize: constants.AuthBurstSize},
+		TrackingEndpoints: {RequestsPerMinute: constants.TrackingRateLimit, BurstSize: constants.TrackingBurstSize},
+		SessionEndpoints:  {RequestsPerMinute: constants.SessionRateLimit, BurstSize: constants.SessionBurstSize},
+		PublicEndpoints:   {RequestsPerMinute: constants.PublicRateLimit, BurstSize: constants.PublicBurstSize},
+		DocsEndpoints:     {RequestsPerMinute: constants.DocsRateLimit, BurstSize: constants.DocsBurstSize},
+		GuestEndpoints:    {RequestsPerMinute: constants.GuestRateLimit, BurstSize: constants.GuestBurstSize},
 	}
 
 	return &RateLimitMiddleware{
@@ -66,6 +74,7 @@ func NewRateLimitMiddleware() *RateLimitMiddleware {
 		sessionLimiter: newRateLimiter(configs[SessionEndpoints]),
 		publicLimiter:  newRateLimiter(configs[PublicEndpoints]),
 		docsLimiter:    newRateLimiter(configs[DocsEndpoints]),
+		guestLimiter:   newRateLimiter(configs[GuestEndpoints]),
 	}
 }
 
@@ -137,10 +146,62 @@ func (rl *RateLimiter) getLimiter(clientID string) *rate.Limiter {
 	return limiter
 }
 
-// allow checks if a request should be allowed
-func (rl *RateLimiter) allow(clientID string) bool {
+// rateLimitStatus describes a client's quota after a rate limit decision
+type rateLimitStatus struct {
+	Allowed    bool
+	Limit      int           // Bucket capacity (burst size)
+	Remaining  int           // Requests that may be sent right away
+	Reset      time.Duration // Until the bucket is full again
+	RetryAfter time.Duration // Until the next request is allowed, when denied
+}
+
+// take consumes one request from the client's bucket and reports the resulting quota
+func (rl *RateLimiter) take(clientID string) rateLimitStatus {
 	limiter := rl.getLimiter(clientID)
-	return limiter.Allow()
+	now := time.Now()
+
+	allowed := limiter.AllowN(now, 1)
+	tokens := limiter.TokensAt(now)
+	perSecond := float64(limiter.Limit())
+
+	status := rateLimitStatus{
+		Allowed:   allowed,
+		Limit:     rl.config.BurstSize,
+		Remaining: max(0, int(tokens)),
+		Reset:     time.Duration((float64(rl.config.BurstSize) - tokens) / perSecond * float64(time.Second)),
+	}
+	if !allowed {
+		status.RetryAfter = time.Duration((1 - tokens) / perSecond * float64(time.Second))
+	}
+
+	return status
+}
+
+// check applies the limiter to the request, setting the standard RateLimit-* headers on every
+// response and Retry-After on rejected ones, so clients can back off without guessing
+func (m *RateLimitMiddleware) check(c echo.Context, limiter *RateLimiter, clientID string, limitType string) error {
+	status := limiter.take(clientID)
+
+	header := c.Response().Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(status.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(status.Reset)))
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=60;burst=%d", limiter.config.RequestsPerMinute, limiter.config.BurstSize))
+
+	if status.Allowed {
+		return nil
+	}
+
+	header.Set("Retry-After", strconv.Itoa(ceilSeconds(status.RetryAfter)))
+	return m.rateLimitError(c, limiter, status, limitType)
+}
+
+// ceilSeconds rounds a duration up to whole seconds as used by the rate limit headers
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
 }
 
 // getClientID extracts client identifier from request
@@ -157,6 +218,28 @@ func (m *RateLimitMiddleware) getClientID(c echo.Context, useUserID bool) string
 	return fmt.Sprintf("ip:%s", ip)
 }
 
+// limiterFor moves guest viewers to their own limiter keyed by IP and viewer on the routes of the
+// session their token was minted for, so share link traffic neither consumes nor is blocked by the
+// limits of the endpoint group. Minting guest tokens and other routes keep the group's limits.
+func (m *RateLimitMiddleware) limiterFor(c echo.Context, limiter *RateLimiter, clientID string) (*RateLimiter, string) {
+	if guest, exists := GetGuestViewer(c); exists && isGuestSessionRoute(c, guest) {
+		return m.guestLimiter, fmt.Sprintf("guest:%s:%s", m.getClientIP(c), guest.ViewerID)
+	}
+	return limiter, clientID
+}
+
+// isGuestSessionRoute reports whether the request reads the session of a guest token
+func isGuestSessionRoute(c echo.Context, guest *utils.GuestClaims) bool {
+	if strings.HasSuffix(c.Path(), "/guest-token") {
+		return false
+	}
+	name := c.PathParam("session")
+	if name == "" {
+		name = c.PathParam("name")
+	}
+	return guest.Username != "" && c.PathParam("username") == guest.Username && name == guest.SessionName
+}
+
 // getClientIP extracts client IP from request
 func (m *RateLimitMiddleware) getClientIP(c echo.Context) string {
 	// Check X-Forwarded-For header (for reverse proxies)
@@ -183,15 +266,16 @@ func (m *RateLimitMiddleware) getClientIP(c echo.Context) string {
 }
 
 // rateLimitError creates a standardized rate limit error response
-func (m *RateLimitMiddleware) rateLimitError(c echo.Context, clientID string, limitType string) error {
+func (m *RateLimitMiddleware) rateLimitError(c echo.Context, limiter *RateLimiter, status rateLimitStatus, limitType string) error {
 	ip := m.getClientIP(c)
 
 	// Log the rate limit violation
-	utils.LogRateLimitViolation(ip, c.Request().URL.Path, 0)
+	utils.LogRateLimitViolation(ip, c.Request().URL.Path, limiter.config.RequestsPerMinute)
 
-	return apis.NewBadRequestError("Rate limit exceeded. Please try again later.", map[string]any{
+	return apis.NewApiError(http.StatusTooManyRequests, "Rate limit exceeded. Please try again later.", map[string]any{
 		"error_type":  "rate_limit_exceeded",
-		"retry_after": "60s",
+		"limit_type":  limitType,
+		"retry_after": fmt.Sprintf("%ds", ceilSeconds(status.RetryAfter)),
 	})
 }
 
@@ -201,8 +285,8 @@ func (m *RateLimitMiddleware) AuthEndpoints() echo.MiddlewareFunc {
 		return func(c echo.Context) error {
 			clientID := m.getClientID(c, false) // Use IP for auth endpoints
 
-			if !m.authLimiter.allow(clientID) {
-				return m.rateLimitError(c, clientID, "auth")
+			if err := m.check(c, m.authLimiter, clientID, "auth"); err != nil {
+				return err
 			}
 
 			return next(c)
@@ -216,8 +300,8 @@ func (m *RateLimitMiddleware) TrackingEndpoints() echo.MiddlewareFunc {
 		return func(c echo.Context) error {
 			clientID := m.getClientID(c, true) // Use user ID for tracking endpoints
 
-			if !m.trackLimiter.allow(clientID) {
-				return m.rateLimitError(c, clientID, "tracking")
+			if err := m.check(c, m.trackLimiter, clientID, "tracking"); err != nil {
+				return err
 			}
 
 			return next(c)
@@ -231,8 +315,10 @@ func (m *RateLimitMiddleware) SessionEndpoints() echo.MiddlewareFunc {
 		return func(c echo.Context) error {
 			clientID := m.getClientID(c, true) // Use user ID for session endpoints
 
-			if !m.sessionLimiter.allow(clientID) {
-				return m.rateLimitError(c, clientID, "session")
+			limiter, clientID := m.limiterFor(c, m.sessionLimiter, clientID)
+
+			if err := m.check(c, limiter, clientID, "session"); err != nil {
+				return err
 			}
 
 			return next(c)
@@ -246,8 +332,10 @@ func (m *RateLimitMiddleware) PublicEndpoints() echo.MiddlewareFunc {
 		return func(c echo.Context) error {
 			clientID := m.getClientID(c, false) // Use IP for public endpoints
 
-			if !m.publicLimiter.allow(clientID) {
-				return m.rateLimitError(c, clientID, "public")
+			limiter, clientID := m.limiterFor(c, m.publicLimiter, clientID)
+
+			if err := m.check(c, limiter, clientID, "public"); err != nil {
+				return err
 			}
 
 			return next(c)
@@ -261,8 +349,8 @@ func (m *RateLimitMiddleware) DocsEndpoints() echo.MiddlewareFunc {
 		return func(c echo.Context) error {
 			clientID := m.getClientID(c, false) // Use IP for docs endpoints
 
-			if !m.docsLimiter.allow(clientID) {
-				return m.rateLimitError(c, clientID, "docs")
+			if err := m.check(c, m.docsLimiter, clientID, "docs"); err != nil {
+				return err
 			}
 
 			return next(c)
@@ -277,4 +365,5 @@ func (m *RateLimitMiddleware) Cleanup() {
 	m.sessionLimiter.Stop()
 	m.publicLimiter.Stop()
 	m.docsLimiter.Stop()
+	m.guestLimiter.Stop()
 }
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/utils"
+)
+
+// GetUserRole returns the access control role of a user record
+func GetUserRole(user *models.Record) string {
+	if user == nil {
+		return ""
+	}
+	return utils.NormalizeRole(user.GetString("role"))
+}
+
+// CanAccess reports whether a user may use a permission on a resource owned by ownerID
+func CanAccess(user *models.Record, permission, ownerID string) bool {
+	if user == nil {
+		return false
+	}
+	return utils.CanAccess(GetUserRole(user), permission, user.Id, ownerID)
+}
+
+// RequirePermission middleware that ensures the authenticated user's role grants the permission.
+// Ownership of the target resource is checked separately by CanAccess.
+func (m *AuthMiddleware) RequirePermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authUser, exists := GetAuthUser(c)
+			if !exists {
+				return apis.NewUnauthorizedError("Authentication required", nil)
+			}
+
+			if !utils.HasPermission(GetUserRole(authUser), permission) {
+				return apis.NewForbiddenError("Your role does not allow this action", nil)
+			}
+
+			return next(c)
+		}
+	}
+}
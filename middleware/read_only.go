@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+
+	"vibe-tracker/constants"
+)
+
+// mutatingGETRoutes are GET routes that write, for trackers that can only send GET requests and
+// the WebSocket upgrade of streamed tracking
+var mutatingGETRoutes = map[string]bool{
+	constants.APIPrefix + constants.EndpointTrack:          true,
+	constants.APIPrefix + constants.EndpointOsmAnd:         true,
+	constants.APIPrefix + constants.EndpointTrackWebSocket: true,
+}
+
+// readOnlyPOSTRoutes are POST routes that only read, e.g. GraphQL queries sent as JSON body or
+// photos to geotag
+var readOnlyPOSTRoutes = map[string]bool{
+	constants.APIPrefix + constants.EndpointGraphQL:       true,
+	constants.APIPrefix + constants.EndpointSessionGeotag: true,
+}
+
+// ReadOnlyMiddleware rejects every request that could write to the database, so a stale database
+// is not written to during deploys and restores
+type ReadOnlyMiddleware struct {
+	message string
+}
+
+func NewReadOnlyMiddleware(message string) *ReadOnlyMiddleware {
+	return &ReadOnlyMiddleware{message: message}
+}
+
+// Middleware answers mutating requests with 503 Service Unavailable and the maintenance message
+func (m *ReadOnlyMiddleware) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if isMutatingRequest(c) {
+				return apis.NewApiError(http.StatusServiceUnavailable, m.message, nil)
+			}
+			return next(c)
+		}
+	}
+}
+
+func isMutatingRequest(c echo.Context) bool {
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead:
+		return mutatingGETRoutes[c.Path()]
+	case http.MethodPost:
+		return !readOnlyPOSTRoutes[c.Path()]
+	case http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
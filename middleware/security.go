@@ -1,9 +1,16 @@
 package middleware
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"runtime/debug"
 	"strings"
 	"time"
 
@@ -19,6 +26,7 @@ type SecurityMiddleware struct {
 	maxRequestSize int64
 	requestTimeout time.Duration
 	enableLogging  bool
+	virusScanner   *VirusScanner // nil when uploads are not scanned
 }
 
 // NewSecurityMiddleware creates a new security middleware instance
@@ -30,6 +38,12 @@ func NewSecurityMiddleware(maxSize int64, timeout time.Duration, enableLogging b
 	}
 }
 
+// WithVirusScanner enables virus scanning of uploaded files
+func (m *SecurityMiddleware) WithVirusScanner(scanner *VirusScanner) *SecurityMiddleware {
+	m.virusScanner = scanner
+	return m
+}
+
 // RequestSizeLimit limits the size of incoming requests
 func (m *SecurityMiddleware) RequestSizeLimit() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -58,29 +72,93 @@ func (m *SecurityMiddleware) RequestSizeLimit() echo.MiddlewareFunc {
 	}
 }
 
+// DecompressRequest inflates gzip request bodies (Content-Encoding: gzip) before they are
+// validated. The inflated body is held to the request size limit, so a small compressed body
+// cannot expand past it; other content encodings are rejected.
+func (m *SecurityMiddleware) DecompressRequest() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			encoding := strings.ToLower(strings.TrimSpace(req.Header.Get("Content-Encoding")))
+			switch encoding {
+			case "", "identity":
+				return next(c)
+			case "gzip", "x-gzip":
+			default:
+				return apis.NewApiError(http.StatusUnsupportedMediaType, "Unsupported Content-Encoding", map[string]any{
+					"supported": []string{"gzip"},
+				})
+			}
+
+			reader, err := gzip.NewReader(req.Body)
+			if err != nil {
+				return apis.NewBadRequestError("Invalid gzip request body", err)
+			}
+			defer reader.Close()
+
+			// One byte past the limit tells an oversized body from one that fits exactly
+			body, err := io.ReadAll(io.LimitReader(reader, m.maxRequestSize+1))
+			if err != nil {
+				return apis.NewBadRequestError("Invalid gzip request body", err)
+			}
+			if int64(len(body)) > m.maxRequestSize {
+				if m.enableLogging {
+					utils.LogSecurityViolation(c.RealIP(), "request_size_exceeded", "decompressed gzip body")
+				}
+
+				return apis.NewBadRequestError("Request size exceeds limit", map[string]any{
+					"max_size_mb": m.maxRequestSize / (1024 * 1024),
+				})
+			}
+
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			req.ContentLength = int64(len(body))
+			req.Header.Del("Content-Encoding")
+			req.Header.Del("Content-Length")
+
+			return next(c)
+		}
+	}
+}
+
 // RequestTimeout adds timeout protection to requests
 func (m *SecurityMiddleware) RequestTimeout() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			// Long-lived streams end when the client disconnects, not after a fixed timeout
+			if isStreamingRequest(c.Request()) {
+				return next(c)
+			}
+
 			// Create context with timeout
 			ctx, cancel := context.WithTimeout(c.Request().Context(), m.requestTimeout)
 			defer cancel()
 
-			// Set the new context
+			// Set the new context, queries run with it abort once the timeout has passed
 			c.SetRequest(c.Request().WithContext(ctx))
 
-			// Channel to receive the result
+			// Channels to receive the result or a panic of the handler
 			done := make(chan error, 1)
+			panics := make(chan *recoveredPanic, 1)
 
-			// Execute the handler in a goroutine
+			// Execute the handler in a goroutine. A panic there cannot be recovered by
+			// RecoveryMiddleware and would take down the process, so it is handed over.
 			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						panics <- &recoveredPanic{value: r, stack: debug.Stack()}
+					}
+				}()
 				done <- next(c)
 			}()
 
-			// Wait for completion or timeout
+			// Wait for completion, a panic or timeout
 			select {
 			case err := <-done:
 				return err
+			case recovered := <-panics:
+				panic(recovered)
 			case <-ctx.Done():
 				if m.enableLogging {
 					utils.LogError(nil, "request timeout exceeded").
@@ -259,6 +337,28 @@ func (m *SecurityMiddleware) FileUploadSecurity() echo.MiddlewareFunc {
 								"max_size_mb": constants.MaxFileUploadSize / (1024 * 1024),
 							})
 						}
+
+						// Check the content, the file name and Content-Type header are up to the client
+						detected, err := utils.SniffMultipartFile(file)
+						if err != nil || !utils.ContentMatchesExtension(file.Filename, detected) {
+							if m.enableLogging {
+								utils.LogError(err, "file content does not match its type").
+									Str("filename", file.Filename).
+									Str("detected_type", detected).
+									Str("declared_type", file.Header.Get("Content-Type")).
+									Str("field_name", fieldName).
+									Str("client_ip", c.RealIP()).
+									Msg("File upload blocked: content mismatch")
+							}
+
+							return apis.NewBadRequestError("File content does not match its type", map[string]any{
+								"filename": file.Filename,
+							})
+						}
+
+						if err := m.scanFile(c, fieldName, file); err != nil {
+							return err
+						}
 					}
 				}
 			}
@@ -268,6 +368,45 @@ func (m *SecurityMiddleware) FileUploadSecurity() echo.MiddlewareFunc {
 	}
 }
 
+// scanFile checks an uploaded file for malware. Infected files are rejected and logged with their
+// SHA-256 digest so they can be looked up later; files that could not be scanned are rejected
+// unless the scanner fails open.
+func (m *SecurityMiddleware) scanFile(c echo.Context, fieldName string, file *multipart.FileHeader) error {
+	if m.virusScanner == nil {
+		return nil
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return apis.NewBadRequestError("Invalid file upload", nil)
+	}
+	defer f.Close()
+
+	digest := sha256.New()
+	signature, err := m.virusScanner.Scan(io.TeeReader(f, digest))
+	if err != nil {
+		utils.LogError(err, "virus scan failed").
+			Str("filename", file.Filename).
+			Str("field_name", fieldName).
+			Bool("fail_open", m.virusScanner.FailOpen()).
+			Msg("Uploaded file could not be scanned")
+
+		if m.virusScanner.FailOpen() {
+			return nil
+		}
+		return apis.NewApiError(http.StatusServiceUnavailable, "File scanning is unavailable, try again later", nil)
+	}
+
+	if signature != "" {
+		utils.LogQuarantinedUpload(c.RealIP(), file.Filename, hex.EncodeToString(digest.Sum(nil)), signature, file.Size)
+		return apis.NewBadRequestError("File rejected by virus scan", map[string]any{
+			"filename": file.Filename,
+		})
+	}
+
+	return nil
+}
+
 // RequestLogging logs security-relevant request information
 func (m *SecurityMiddleware) RequestLogging() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -310,7 +449,7 @@ func (m *SecurityMiddleware) RequestLogging() echo.MiddlewareFunc {
 			}
 
 			// Log entry
-			logger := utils.GetLogger().With().
+			logger := utils.HTTPLogger().With().
 				Str("method", c.Request().Method).
 				Str("path", path).
 				Str("client_ip", c.RealIP()).
@@ -336,3 +475,9 @@ func (m *SecurityMiddleware) RequestLogging() echo.MiddlewareFunc {
 		}
 	}
 }
+
+// isStreamingRequest reports whether the request opens a long-lived event stream or WebSocket
+func isStreamingRequest(r *http.Request) bool {
+	return strings.Contains(r.Header.Get(echo.HeaderAccept), "text/event-stream") ||
+		strings.EqualFold(r.Header.Get(echo.HeaderUpgrade), "websocket")
+}
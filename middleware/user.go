@@ -6,21 +6,38 @@ import (
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/constants"
 )
 
 const (
-	RequestUserContextKey = "request_user"
+	RequestUserContextKey     = "request_user"
+	RequestWaypointContextKey = "request_waypoint"
+	RequestSessionContextKey  = "request_session"
 )
 
+// SessionReference is implemented by validated request data that refers to a session by ID
+type SessionReference interface {
+	ReferencedSessionID() string
+}
+
 // UserMiddleware provides user lookup middleware functions
 type UserMiddleware struct {
-	app *pocketbase.PocketBase
+	app          *pocketbase.PocketBase
+	flushPending func(userID string)
 }
 
 func NewUserMiddleware(app *pocketbase.PocketBase) *UserMiddleware {
 	return &UserMiddleware{app: app}
 }
 
+// WithPendingWritesFlusher sets a function that writes the batched points of a user before their
+// data is read, so trackers always read their own writes
+func (m *UserMiddleware) WithPendingWritesFlusher(flush func(userID string)) *UserMiddleware {
+	m.flushPending = flush
+	return m
+}
+
 // LoadUserFromPath middleware that loads user from :username path parameter
 func (m *UserMiddleware) LoadUserFromPath() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -35,6 +52,10 @@ func (m *UserMiddleware) LoadUserFromPath() echo.MiddlewareFunc {
 				return apis.NewNotFoundError("User not found", err)
 			}
 
+			if m.flushPending != nil {
+				m.flushPending(user.Id)
+			}
+
 			c.Set(RequestUserContextKey, user)
 			return next(c)
 		}
@@ -69,6 +90,91 @@ func (m *UserMiddleware) RequireUserOwnership() echo.MiddlewareFunc {
 	}
 }
 
+// RequireUserPermission middleware that loads the user from :username path parameter and ensures
+// the authenticated user may use the permission on that user's resources
+func (m *UserMiddleware) RequireUserPermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authUser, exists := GetAuthUser(c)
+			if !exists {
+				return apis.NewUnauthorizedError("Authentication required", nil)
+			}
+
+			user, err := m.findUserByUsername(c.PathParam("username"))
+			if err != nil {
+				return apis.NewNotFoundError("User not found", err)
+			}
+
+			if !CanAccess(authUser, permission, user.Id) {
+				return apis.NewForbiddenError("Cannot access another user's resources", nil)
+			}
+
+			c.Set(RequestUserContextKey, user)
+			return next(c)
+		}
+	}
+}
+
+// RequireWaypointOwnership middleware that loads the waypoint from :id path parameter and ensures
+// the authenticated user may change the waypoints of its owner
+func (m *UserMiddleware) RequireWaypointOwnership() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authUser, exists := GetAuthUser(c)
+			if !exists {
+				return apis.NewUnauthorizedError("Authentication required", nil)
+			}
+
+			waypoint, err := m.app.Dao().FindRecordById("waypoints", c.PathParam("id"))
+			if err != nil {
+				return apis.NewNotFoundError("Waypoint not found", err)
+			}
+
+			if !CanAccess(authUser, constants.PermWaypointsWrite, waypoint.GetString("user")) {
+				return apis.NewForbiddenError("Cannot change another user's waypoints", nil)
+			}
+
+			c.Set(RequestWaypointContextKey, waypoint)
+			return next(c)
+		}
+	}
+}
+
+// RequireSessionOwnershipByID middleware that loads a session by ID and ensures the authenticated
+// user may use the permission on it. The ID is taken from :sessionId path parameter, or from the
+// validated request data when it is a SessionReference, so it must run after the validation
+// middleware. Requests that do not refer to a session pass without a session in the context.
+func (m *UserMiddleware) RequireSessionOwnershipByID(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authUser, exists := GetAuthUser(c)
+			if !exists {
+				return apis.NewUnauthorizedError("Authentication required", nil)
+			}
+
+			sessionID := c.PathParam("sessionId")
+			if reference, ok := GetValidatedData(c).(SessionReference); ok && sessionID == "" {
+				sessionID = reference.ReferencedSessionID()
+			}
+			if sessionID == "" {
+				return next(c)
+			}
+
+			session, err := m.app.Dao().FindRecordById("sessions", sessionID)
+			if err != nil {
+				return apis.NewNotFoundError("Session not found", err)
+			}
+
+			if !CanAccess(authUser, permission, session.GetString("user")) {
+				return apis.NewForbiddenError("Cannot access another user's session", nil)
+			}
+
+			c.Set(RequestSessionContextKey, session)
+			return next(c)
+		}
+	}
+}
+
 // LoadUserFromPathOptional middleware that optionally loads user from :username path parameter
 func (m *UserMiddleware) LoadUserFromPathOptional() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -91,6 +197,18 @@ func GetRequestUser(c echo.Context) (*models.Record, bool) {
 	return user, exists
 }
 
+// GetRequestWaypoint returns the waypoint loaded by RequireWaypointOwnership
+func GetRequestWaypoint(c echo.Context) (*models.Record, bool) {
+	waypoint, exists := c.Get(RequestWaypointContextKey).(*models.Record)
+	return waypoint, exists
+}
+
+// GetRequestSession returns the session loaded by RequireSessionOwnershipByID
+func GetRequestSession(c echo.Context) (*models.Record, bool) {
+	session, exists := c.Get(RequestSessionContextKey).(*models.Record)
+	return session, exists
+}
+
 // Private helper method
 func (m *UserMiddleware) findUserByUsername(username string) (*models.Record, error) {
 	if username == "" {
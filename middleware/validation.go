@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"reflect"
 	"strconv"
 	"strings"
@@ -11,6 +12,8 @@ import (
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/pocketbase/apis"
 
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
 	"vibe-tracker/utils"
 )
 
@@ -23,6 +26,55 @@ func NewValidationMiddleware() *ValidationMiddleware {
 
 // ValidateJSON middleware that validates and binds JSON request bodies
 func (v *ValidationMiddleware) ValidateJSON(target interface{}) echo.MiddlewareFunc {
+	return v.validateJSONBody(target, nil)
+}
+
+// ValidateGeoJSON middleware that strictly validates a GeoJSON Point Feature before binding it like
+// ValidateJSON, rejecting other geometry types, wrong coordinate counts and out of range or
+// non-finite coordinates with field-level errors
+func (v *ValidationMiddleware) ValidateGeoJSON(target interface{}) echo.MiddlewareFunc {
+	return v.validateJSONBody(target, utils.ValidateGeoJSONPointFeature)
+}
+
+// ValidateLocation validates a tracked point like ValidateGeoJSON, and also accepts the compact CBOR
+// and protobuf encodings of cellular trackers (see utils.DecodeCompactLocation). Either way the
+// point is stored as *models.LocationRequest.
+func (v *ValidationMiddleware) ValidateLocation() echo.MiddlewareFunc {
+	validateGeoJSON := v.ValidateGeoJSON(&appmodels.LocationRequest{})
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		geoJSONHandler := validateGeoJSON(next)
+
+		return func(c echo.Context) error {
+			contentType := c.Request().Header.Get("Content-Type")
+			if !utils.IsCompactLocationType(contentType) {
+				return geoJSONHandler(c)
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return apis.NewBadRequestError("Failed to read request body", err)
+			}
+			if len(body) == 0 {
+				return apis.NewBadRequestError("Request body is empty", nil)
+			}
+
+			location, err := utils.DecodeCompactLocation(contentType, body)
+			if err != nil {
+				return apis.NewBadRequestError("Validation failed", err)
+			}
+			if err := utils.ValidateStruct(location); err != nil {
+				return apis.NewBadRequestError("Validation failed", err)
+			}
+
+			c.Set("validated_data", location)
+			return next(c)
+		}
+	}
+}
+
+// validateJSONBody binds and validates a JSON body, running the optional raw body check first
+func (v *ValidationMiddleware) validateJSONBody(target interface{}, checkBody func([]byte) error) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			if c.Request().Method != "POST" && c.Request().Method != "PUT" && c.Request().Method != "PATCH" {
@@ -47,6 +99,12 @@ func (v *ValidationMiddleware) ValidateJSON(target interface{}) echo.MiddlewareF
 				return apis.NewBadRequestError("Request body is empty", nil)
 			}
 
+			if checkBody != nil {
+				if err := checkBody(body); err != nil {
+					return apis.NewBadRequestError("Validation failed", err)
+				}
+			}
+
 			if err := json.Unmarshal(body, targetValue); err != nil {
 				return apis.NewBadRequestError("Invalid JSON format", err)
 			}
@@ -63,6 +121,94 @@ func (v *ValidationMiddleware) ValidateJSON(target interface{}) echo.MiddlewareF
 	}
 }
 
+// ValidateMultipart middleware that validates and binds multipart form uploads. Text fields are
+// bound by their `form` tag and validated like JSON bodies, *multipart.FileHeader fields are
+// checked against their `file` tag (see utils.FileRule) with the file type sniffed from the
+// content. Invalid `file` tags panic when the route is registered.
+func (v *ValidationMiddleware) ValidateMultipart(target interface{}) echo.MiddlewareFunc {
+	targetType := reflect.TypeOf(target).Elem()
+	rules := map[string]utils.FileRule{}
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		if field.Type != fileHeaderType {
+			continue
+		}
+		rule, err := utils.ParseFileRule(field.Tag.Get("file"))
+		if err != nil {
+			panic(fmt.Sprintf("%s.%s: %v", targetType.Name(), field.Name, err))
+		}
+		rules[field.Name] = rule
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			contentType := c.Request().Header.Get("Content-Type")
+			if !strings.Contains(contentType, "multipart/form-data") {
+				return apis.NewBadRequestError("Content-Type must be multipart/form-data", nil)
+			}
+
+			// Already parsed by FileUploadSecurity when it is enabled
+			if err := c.Request().ParseMultipartForm(constants.MaxFileUploadSize); err != nil {
+				return apis.NewBadRequestError("Invalid multipart form", err)
+			}
+
+			targetValue := reflect.New(targetType)
+			if err := v.bindMultipart(c.Request().MultipartForm, targetValue.Elem(), rules); err != nil {
+				return apis.NewBadRequestError("Validation failed", err)
+			}
+
+			if err := utils.ValidateStruct(targetValue.Interface()); err != nil {
+				return apis.NewBadRequestError("Validation failed", err)
+			}
+
+			c.Set("validated_data", targetValue.Interface())
+			return next(c)
+		}
+	}
+}
+
+var fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+
+// bindMultipart binds the fields of a multipart form, collecting the errors of all file fields
+func (v *ValidationMiddleware) bindMultipart(form *multipart.Form, target reflect.Value, rules map[string]utils.FileRule) error {
+	var errs utils.ValidationErrors
+	targetType := target.Type()
+
+	for i := 0; i < target.NumField(); i++ {
+		fieldType := targetType.Field(i)
+		name, _, _ := strings.Cut(fieldType.Tag.Get("form"), ",")
+		if name == "" || !target.Field(i).CanSet() {
+			continue
+		}
+
+		if rule, isFile := rules[fieldType.Name]; isFile {
+			files := form.File[name]
+			if err := rule.Check(name, files); err != nil {
+				errs = append(errs, err.(utils.ValidationErrors)...)
+				continue
+			}
+			if len(files) > 0 {
+				target.Field(i).Set(reflect.ValueOf(files[0]))
+			}
+			continue
+		}
+
+		values := form.Value[name]
+		if len(values) == 0 || values[0] == "" {
+			continue
+		}
+		if err := v.setFieldValue(target.Field(i), values[0]); err != nil {
+			errs = append(errs, utils.ValidationError{Field: name, Tag: "type", Value: values[0],
+				Message: fmt.Sprintf("%s has an invalid value", name)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
 // ValidateRequired middleware that checks for required path parameters
 func (v *ValidationMiddleware) ValidateRequired(params ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
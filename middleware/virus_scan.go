@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"time"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/utils"
+)
+
+// VirusScanner scans uploaded files with a ClamAV daemon
+type VirusScanner struct {
+	network  string
+	address  string
+	failOpen bool
+}
+
+// NewVirusScanner creates a virus scanner for a clamd socket address (see utils.ParseClamdAddress).
+// With failOpen, uploads are accepted when clamd cannot be reached, otherwise they are rejected.
+func NewVirusScanner(address string, failOpen bool) (*VirusScanner, error) {
+	network, addr, err := utils.ParseClamdAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VirusScanner{
+		network:  network,
+		address:  addr,
+		failOpen: failOpen,
+	}, nil
+}
+
+// FailOpen reports whether uploads are accepted when a file could not be scanned
+func (s *VirusScanner) FailOpen() bool {
+	return s.failOpen
+}
+
+// Scan streams content to clamd and returns the name of the detected signature, or "" when the
+// content is clean
+func (s *VirusScanner) Scan(content io.Reader) (string, error) {
+	conn, err := net.DialTimeout(s.network, s.address, constants.ClamAVDialTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(constants.ClamAVScanTimeout)); err != nil {
+		return "", err
+	}
+
+	if err := utils.WriteClamdStream(conn, content, constants.ClamAVChunkSize); err != nil {
+		return "", err
+	}
+
+	response, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return utils.ParseClamdResponse(response)
+}
@@ -0,0 +1,103 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding order field to waypoints collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("waypoints")
+		if err != nil {
+			return fmt.Errorf("waypoints collection not found: %v", err)
+		}
+
+		// Check if field already exists to avoid duplicates
+		if collection.Schema.GetFieldByName("order") != nil {
+			log.Println("order field already exists in waypoints collection, skipping...")
+			return nil
+		}
+
+		collection.Schema.AddField(&schema.SchemaField{
+			Name:     "order",
+			Type:     schema.FieldTypeNumber,
+			Required: false,
+			Options: &schema.NumberOptions{
+				Min:       types.Pointer(0.0),
+				NoDecimal: true,
+			},
+		})
+
+		collection.Indexes = append(collection.Indexes,
+			"CREATE INDEX idx_waypoints_session_order ON waypoints (session_id, `order`)")
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save waypoints collection with order field: %v", err)
+		}
+
+		// Backfill existing waypoints so that the initial order matches creation time
+		log.Println("Backfilling waypoint order from creation time...")
+		waypoints, err := dao.FindRecordsByFilter("waypoints", "id != ''", "session_id,created", 0, 0)
+		if err != nil {
+			return fmt.Errorf("failed to load waypoints for order backfill: %v", err)
+		}
+
+		currentSession := ""
+		position := 0
+		for _, waypoint := range waypoints {
+			if waypoint.GetString("session_id") != currentSession {
+				currentSession = waypoint.GetString("session_id")
+				position = 0
+			}
+			waypoint.Set("order", position)
+			if err := dao.SaveRecord(waypoint); err != nil {
+				return fmt.Errorf("failed to backfill order for waypoint %s: %v", waypoint.Id, err)
+			}
+			position++
+		}
+
+		log.Printf("Successfully added order field to waypoints collection (%d waypoints backfilled)!", len(waypoints))
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the order field and its index from waypoints collection
+		dao := daos.New(db)
+
+		log.Println("Removing order field from waypoints collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("waypoints")
+		if err != nil {
+			log.Printf("Waypoints collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if field := collection.Schema.GetFieldByName("order"); field != nil {
+			collection.Schema.RemoveField(field.Id)
+		}
+
+		indexes := types.JsonArray[string]{}
+		for _, index := range collection.Indexes {
+			if index != "CREATE INDEX idx_waypoints_session_order ON waypoints (session_id, `order`)" {
+				indexes = append(indexes, index)
+			}
+		}
+		collection.Indexes = indexes
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove order field from waypoints collection: %v", err)
+		}
+
+		log.Println("Successfully removed order field from waypoints collection!")
+		return nil
+	})
+}
@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding visited_at field to waypoints collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("waypoints")
+		if err != nil {
+			return fmt.Errorf("waypoints collection not found: %v", err)
+		}
+
+		// Check if field already exists to avoid duplicates
+		if collection.Schema.GetFieldByName("visited_at") != nil {
+			log.Println("visited_at field already exists in waypoints collection, skipping...")
+			return nil
+		}
+
+		// Empty visited_at means the waypoint has not been reached yet
+		collection.Schema.AddField(&schema.SchemaField{
+			Name:     "visited_at",
+			Type:     schema.FieldTypeDate,
+			Required: false,
+		})
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save waypoints collection with visited_at field: %v", err)
+		}
+
+		log.Println("Successfully added visited_at field to waypoints collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the visited_at field from waypoints collection
+		dao := daos.New(db)
+
+		log.Println("Removing visited_at field from waypoints collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("waypoints")
+		if err != nil {
+			log.Printf("Waypoints collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if field := collection.Schema.GetFieldByName("visited_at"); field != nil {
+			collection.Schema.RemoveField(field.Id)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove visited_at field from waypoints collection: %v", err)
+		}
+
+		log.Println("Successfully removed visited_at field from waypoints collection!")
+		return nil
+	})
+}
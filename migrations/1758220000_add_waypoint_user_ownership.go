@@ -0,0 +1,149 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// waypointCreateRule lets users create waypoints of their own, in their own sessions or in none
+const waypointCreateRule = "user = @request.auth.id && (session_id = '' || session_id.user = @request.auth.id)"
+
+// waypointUpdateRule additionally keeps owners from giving their waypoints to other users or moving
+// them into sessions of other users
+const waypointUpdateRule = waypointCreateRule +
+	" && (@request.data.user:isset = false || @request.data.user = @request.auth.id)" +
+	" && (@request.data.session_id:isset = false || @request.data.session_id = '' || @request.data.session_id.user = @request.auth.id)"
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding user ownership to waypoints collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("waypoints")
+		if err != nil {
+			return fmt.Errorf("waypoints collection not found: %v", err)
+		}
+
+		// Check if field already exists to avoid duplicates
+		if collection.Schema.GetFieldByName("user") != nil {
+			log.Println("user field already exists in waypoints collection, skipping...")
+			return nil
+		}
+
+		usersCollection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		collection.Schema.AddField(&schema.SchemaField{
+			Name:     "user",
+			Type:     schema.FieldTypeRelation,
+			Required: false,
+			Options: &schema.RelationOptions{
+				CollectionId:  usersCollection.Id,
+				CascadeDelete: true,
+				MaxSelect:     types.Pointer(1),
+			},
+		})
+
+		// Waypoints no longer need a session; user-level waypoints leave it empty
+		if field := collection.Schema.GetFieldByName("session_id"); field != nil {
+			field.Required = false
+			if options, ok := field.Options.(*schema.RelationOptions); ok {
+				options.MinSelect = nil
+			}
+		}
+
+		// Ownership is now determined by the user field instead of the session
+		collection.ListRule = types.Pointer("user = @request.auth.id")
+		collection.ViewRule = types.Pointer("user = @request.auth.id || session_id.public = true")
+		collection.CreateRule = types.Pointer(waypointCreateRule)
+		collection.UpdateRule = types.Pointer(waypointUpdateRule)
+		collection.DeleteRule = types.Pointer("user = @request.auth.id")
+
+		collection.Indexes = append(collection.Indexes,
+			"CREATE INDEX idx_waypoints_user ON waypoints (user)")
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save waypoints collection with user field: %v", err)
+		}
+
+		// Backfill the owner of existing waypoints from their session
+		log.Println("Backfilling waypoint owners from sessions...")
+		waypoints, err := dao.FindRecordsByFilter("waypoints", "user = ''", "", 0, 0)
+		if err != nil {
+			return fmt.Errorf("failed to load waypoints for user backfill: %v", err)
+		}
+
+		for _, waypoint := range waypoints {
+			session, err := dao.FindRecordById("sessions", waypoint.GetString("session_id"))
+			if err != nil {
+				log.Printf("Session for waypoint %s not found, skipping backfill", waypoint.Id)
+				continue
+			}
+			waypoint.Set("user", session.GetString("user"))
+			if err := dao.SaveRecord(waypoint); err != nil {
+				return fmt.Errorf("failed to backfill user for waypoint %s: %v", waypoint.Id, err)
+			}
+		}
+
+		log.Printf("Successfully added user ownership to waypoints collection (%d waypoints backfilled)!", len(waypoints))
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the user field and restore session-based ownership
+		dao := daos.New(db)
+
+		log.Println("Removing user ownership from waypoints collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("waypoints")
+		if err != nil {
+			log.Printf("Waypoints collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		// User-level waypoints cannot exist without the user field
+		if _, err := db.NewQuery("DELETE FROM waypoints WHERE session_id = ''").Execute(); err != nil {
+			return fmt.Errorf("failed to delete user-level waypoints: %v", err)
+		}
+
+		if field := collection.Schema.GetFieldByName("user"); field != nil {
+			collection.Schema.RemoveField(field.Id)
+		}
+
+		if field := collection.Schema.GetFieldByName("session_id"); field != nil {
+			field.Required = true
+			if options, ok := field.Options.(*schema.RelationOptions); ok {
+				options.MinSelect = types.Pointer(1)
+			}
+		}
+
+		collection.ListRule = types.Pointer("session_id.user = @request.auth.id")
+		collection.ViewRule = types.Pointer("session_id.user = @request.auth.id || session_id.public = true")
+		collection.CreateRule = types.Pointer("session_id.user = @request.auth.id")
+		collection.UpdateRule = types.Pointer("session_id.user = @request.auth.id")
+		collection.DeleteRule = types.Pointer("session_id.user = @request.auth.id")
+
+		indexes := types.JsonArray[string]{}
+		for _, index := range collection.Indexes {
+			if index != "CREATE INDEX idx_waypoints_user ON waypoints (user)" {
+				indexes = append(indexes, index)
+			}
+		}
+		collection.Indexes = indexes
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove user field from waypoints collection: %v", err)
+		}
+
+		log.Println("Successfully removed user ownership from waypoints collection!")
+		return nil
+	})
+}
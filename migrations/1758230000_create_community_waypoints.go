@@ -0,0 +1,249 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		// Create community_waypoints collection for published waypoints
+		if err := createCommunityWaypointsCollection(dao); err != nil {
+			return fmt.Errorf("failed to create community_waypoints collection: %v", err)
+		}
+
+		// Create community_waypoint_flags collection for moderation reports
+		if err := createCommunityWaypointFlagsCollection(dao); err != nil {
+			return fmt.Errorf("failed to create community_waypoint_flags collection: %v", err)
+		}
+
+		return nil
+	}, func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		// Flags reference community waypoints, so remove them first
+		for _, name := range []string{"community_waypoint_flags", "community_waypoints"} {
+			if collection, err := dao.FindCollectionByNameOrId(name); err == nil {
+				if err := dao.DeleteCollection(collection); err != nil {
+					return fmt.Errorf("failed to delete %s collection: %v", name, err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func createCommunityWaypointsCollection(dao *daos.Dao) error {
+	// Check if collection already exists
+	if _, err := dao.FindCollectionByNameOrId("community_waypoints"); err == nil {
+		return nil
+	}
+
+	usersCollection, err := dao.FindCollectionByNameOrId("users")
+	if err != nil {
+		return fmt.Errorf("users collection not found: %v", err)
+	}
+
+	waypointsCollection, err := dao.FindCollectionByNameOrId("waypoints")
+	if err != nil {
+		return fmt.Errorf("waypoints collection not found: %v", err)
+	}
+
+	// Only visible entries are readable; writes go through the API
+	collection := &models.Collection{
+		Name:       "community_waypoints",
+		Type:       models.CollectionTypeBase,
+		ListRule:   types.Pointer("status = 'visible'"),
+		ViewRule:   types.Pointer("status = 'visible' || user = @request.auth.id"),
+		CreateRule: nil,
+		UpdateRule: nil,
+		DeleteRule: nil,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name:     "waypoint",
+				Type:     schema.FieldTypeRelation,
+				Required: true,
+				Options: &schema.RelationOptions{
+					CollectionId:  waypointsCollection.Id,
+					CascadeDelete: true,
+					MinSelect:     types.Pointer(1),
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "user",
+				Type:     schema.FieldTypeRelation,
+				Required: true,
+				Options: &schema.RelationOptions{
+					CollectionId:  usersCollection.Id,
+					CascadeDelete: true,
+					MinSelect:     types.Pointer(1),
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "attribution",
+				Type:     schema.FieldTypeText,
+				Required: true,
+				Options: &schema.TextOptions{
+					Max: types.Pointer(100),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "name",
+				Type:     schema.FieldTypeText,
+				Required: true,
+				Options: &schema.TextOptions{
+					Min: types.Pointer(1),
+					Max: types.Pointer(200),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "type",
+				Type:     schema.FieldTypeSelect,
+				Required: true,
+				Options: &schema.SelectOptions{
+					MaxSelect: 1,
+					Values:    []string{"generic", "food", "water", "shelter", "transition", "viewpoint", "camping", "parking", "danger", "medical", "fuel"},
+				},
+			},
+			&schema.SchemaField{
+				Name:     "description",
+				Type:     schema.FieldTypeEditor,
+				Required: false,
+				Options:  &schema.EditorOptions{},
+			},
+			&schema.SchemaField{
+				Name:     "latitude",
+				Type:     schema.FieldTypeNumber,
+				Required: true,
+				Options: &schema.NumberOptions{
+					Min: types.Pointer(-90.0),
+					Max: types.Pointer(90.0),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "longitude",
+				Type:     schema.FieldTypeNumber,
+				Required: true,
+				Options: &schema.NumberOptions{
+					Min: types.Pointer(-180.0),
+					Max: types.Pointer(180.0),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "altitude",
+				Type:     schema.FieldTypeNumber,
+				Required: false,
+				Options:  &schema.NumberOptions{},
+			},
+			&schema.SchemaField{
+				Name:     "status",
+				Type:     schema.FieldTypeSelect,
+				Required: true,
+				Options: &schema.SelectOptions{
+					MaxSelect: 1,
+					Values:    []string{"visible", "hidden"},
+				},
+			},
+			&schema.SchemaField{
+				Name:     "flag_count",
+				Type:     schema.FieldTypeNumber,
+				Required: false,
+				Options: &schema.NumberOptions{
+					Min:       types.Pointer(0.0),
+					NoDecimal: true,
+				},
+			},
+		),
+	}
+
+	// Create indexes for performance
+	collection.Indexes = types.JsonArray[string]{
+		"CREATE UNIQUE INDEX idx_community_waypoints_waypoint ON community_waypoints (waypoint)",
+		"CREATE INDEX idx_community_waypoints_user ON community_waypoints (user)",
+		"CREATE INDEX idx_community_waypoints_location ON community_waypoints (status, latitude, longitude)",
+	}
+
+	return dao.SaveCollection(collection)
+}
+
+func createCommunityWaypointFlagsCollection(dao *daos.Dao) error {
+	// Check if collection already exists
+	if _, err := dao.FindCollectionByNameOrId("community_waypoint_flags"); err == nil {
+		return nil
+	}
+
+	usersCollection, err := dao.FindCollectionByNameOrId("users")
+	if err != nil {
+		return fmt.Errorf("users collection not found: %v", err)
+	}
+
+	communityCollection, err := dao.FindCollectionByNameOrId("community_waypoints")
+	if err != nil {
+		return fmt.Errorf("community_waypoints collection not found: %v", err)
+	}
+
+	// Flags are only accessible to admins
+	collection := &models.Collection{
+		Name: "community_waypoint_flags",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name:     "community_waypoint",
+				Type:     schema.FieldTypeRelation,
+				Required: true,
+				Options: &schema.RelationOptions{
+					CollectionId:  communityCollection.Id,
+					CascadeDelete: true,
+					MinSelect:     types.Pointer(1),
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "user",
+				Type:     schema.FieldTypeRelation,
+				Required: true,
+				Options: &schema.RelationOptions{
+					CollectionId:  usersCollection.Id,
+					CascadeDelete: true,
+					MinSelect:     types.Pointer(1),
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "reason",
+				Type:     schema.FieldTypeSelect,
+				Required: true,
+				Options: &schema.SelectOptions{
+					MaxSelect: 1,
+					Values:    []string{"incorrect", "unavailable", "duplicate", "inappropriate", "other"},
+				},
+			},
+			&schema.SchemaField{
+				Name:     "comment",
+				Type:     schema.FieldTypeText,
+				Required: false,
+				Options: &schema.TextOptions{
+					Max: types.Pointer(500),
+				},
+			},
+		),
+	}
+
+	// One flag per user and community waypoint
+	collection.Indexes = types.JsonArray[string]{
+		"CREATE UNIQUE INDEX idx_community_flags_user ON community_waypoint_flags (community_waypoint, user)",
+	}
+
+	return dao.SaveCollection(collection)
+}
@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding show_viewer_count field to sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			return fmt.Errorf("sessions collection not found: %v", err)
+		}
+
+		// Check if field already exists to avoid duplicates
+		if collection.Schema.GetFieldByName("show_viewer_count") != nil {
+			log.Println("show_viewer_count field already exists in sessions collection, skipping...")
+			return nil
+		}
+
+		// Viewer counts are only shown to viewers when the owner opts in (defaults to false for privacy)
+		collection.Schema.AddField(&schema.SchemaField{
+			Name:     "show_viewer_count",
+			Type:     schema.FieldTypeBool,
+			Required: false,
+			Options:  &schema.BoolOptions{},
+		})
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save sessions collection with show_viewer_count field: %v", err)
+		}
+
+		log.Println("Successfully added show_viewer_count field to sessions collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the show_viewer_count field from sessions collection
+		dao := daos.New(db)
+
+		log.Println("Removing show_viewer_count field from sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			log.Printf("Sessions collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if field := collection.Schema.GetFieldByName("show_viewer_count"); field != nil {
+			collection.Schema.RemoveField(field.Id)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove show_viewer_count field from sessions collection: %v", err)
+		}
+
+		log.Println("Successfully removed show_viewer_count field from sessions collection!")
+		return nil
+	})
+}
@@ -0,0 +1,331 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		// Create organizations collection for clubs and race organizers
+		if err := createOrganizationsCollection(dao); err != nil {
+			return fmt.Errorf("failed to create organizations collection: %v", err)
+		}
+
+		// Create organization_members collection holding member roles
+		if err := createOrganizationMembersCollection(dao); err != nil {
+			return fmt.Errorf("failed to create organization_members collection: %v", err)
+		}
+
+		// Create organization_api_keys collection for org-scoped integrations
+		if err := createOrganizationAPIKeysCollection(dao); err != nil {
+			return fmt.Errorf("failed to create organization_api_keys collection: %v", err)
+		}
+
+		// Link sessions to the organization running them
+		if err := addSessionOrganizationField(dao); err != nil {
+			return fmt.Errorf("failed to add organization field to sessions: %v", err)
+		}
+
+		return nil
+	}, func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Removing organization field from sessions collection...")
+		if sessions, err := dao.FindCollectionByNameOrId("sessions"); err == nil {
+			if field := sessions.Schema.GetFieldByName("organization"); field != nil {
+				sessions.Schema.RemoveField(field.Id)
+			}
+
+			indexes := types.JsonArray[string]{}
+			for _, index := range sessions.Indexes {
+				if index != "CREATE INDEX idx_sessions_organization ON sessions (organization)" {
+					indexes = append(indexes, index)
+				}
+			}
+			sessions.Indexes = indexes
+
+			if err := dao.SaveCollection(sessions); err != nil {
+				return fmt.Errorf("failed to remove organization field from sessions: %v", err)
+			}
+		}
+
+		// Members and keys reference organizations, so remove them first
+		for _, name := range []string{"organization_api_keys", "organization_members", "organizations"} {
+			if collection, err := dao.FindCollectionByNameOrId(name); err == nil {
+				if err := dao.DeleteCollection(collection); err != nil {
+					return fmt.Errorf("failed to delete %s collection: %v", name, err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func createOrganizationsCollection(dao *daos.Dao) error {
+	// Check if collection already exists
+	if _, err := dao.FindCollectionByNameOrId("organizations"); err == nil {
+		return nil
+	}
+
+	usersCollection, err := dao.FindCollectionByNameOrId("users")
+	if err != nil {
+		return fmt.Errorf("users collection not found: %v", err)
+	}
+
+	// Membership checks live in the API, so the collection is admin-only
+	collection := &models.Collection{
+		Name: "organizations",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name:     "name",
+				Type:     schema.FieldTypeText,
+				Required: true,
+				Options: &schema.TextOptions{
+					Min: types.Pointer(1),
+					Max: types.Pointer(200),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "slug",
+				Type:     schema.FieldTypeText,
+				Required: true,
+				Options: &schema.TextOptions{
+					Min:     types.Pointer(1),
+					Max:     types.Pointer(100),
+					Pattern: "^[a-zA-Z0-9_-]+$",
+				},
+			},
+			&schema.SchemaField{
+				Name:     "description",
+				Type:     schema.FieldTypeText,
+				Required: false,
+				Options: &schema.TextOptions{
+					Max: types.Pointer(1000),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "created_by",
+				Type:     schema.FieldTypeRelation,
+				Required: false,
+				Options: &schema.RelationOptions{
+					CollectionId:  usersCollection.Id,
+					CascadeDelete: false,
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+		),
+	}
+
+	collection.Indexes = types.JsonArray[string]{
+		"CREATE UNIQUE INDEX idx_organizations_slug ON organizations (slug)",
+	}
+
+	return dao.SaveCollection(collection)
+}
+
+func createOrganizationMembersCollection(dao *daos.Dao) error {
+	// Check if collection already exists
+	if _, err := dao.FindCollectionByNameOrId("organization_members"); err == nil {
+		return nil
+	}
+
+	usersCollection, err := dao.FindCollectionByNameOrId("users")
+	if err != nil {
+		return fmt.Errorf("users collection not found: %v", err)
+	}
+
+	organizationsCollection, err := dao.FindCollectionByNameOrId("organizations")
+	if err != nil {
+		return fmt.Errorf("organizations collection not found: %v", err)
+	}
+
+	// Users may see their own memberships; changes go through the API
+	collection := &models.Collection{
+		Name:     "organization_members",
+		Type:     models.CollectionTypeBase,
+		ListRule: types.Pointer("user = @request.auth.id"),
+		ViewRule: types.Pointer("user = @request.auth.id"),
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name:     "organization",
+				Type:     schema.FieldTypeRelation,
+				Required: true,
+				Options: &schema.RelationOptions{
+					CollectionId:  organizationsCollection.Id,
+					CascadeDelete: true,
+					MinSelect:     types.Pointer(1),
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "user",
+				Type:     schema.FieldTypeRelation,
+				Required: true,
+				Options: &schema.RelationOptions{
+					CollectionId:  usersCollection.Id,
+					CascadeDelete: true,
+					MinSelect:     types.Pointer(1),
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "role",
+				Type:     schema.FieldTypeSelect,
+				Required: true,
+				Options: &schema.SelectOptions{
+					MaxSelect: 1,
+					Values:    []string{"owner", "admin", "member"},
+				},
+			},
+		),
+	}
+
+	// One membership per user and organization
+	collection.Indexes = types.JsonArray[string]{
+		"CREATE UNIQUE INDEX idx_organization_members_user ON organization_members (organization, user)",
+		"CREATE INDEX idx_organization_members_member ON organization_members (user)",
+	}
+
+	return dao.SaveCollection(collection)
+}
+
+func createOrganizationAPIKeysCollection(dao *daos.Dao) error {
+	// Check if collection already exists
+	if _, err := dao.FindCollectionByNameOrId("organization_api_keys"); err == nil {
+		return nil
+	}
+
+	usersCollection, err := dao.FindCollectionByNameOrId("users")
+	if err != nil {
+		return fmt.Errorf("users collection not found: %v", err)
+	}
+
+	organizationsCollection, err := dao.FindCollectionByNameOrId("organizations")
+	if err != nil {
+		return fmt.Errorf("organizations collection not found: %v", err)
+	}
+
+	// Only key hashes are stored; the collection is admin-only
+	collection := &models.Collection{
+		Name: "organization_api_keys",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name:     "organization",
+				Type:     schema.FieldTypeRelation,
+				Required: true,
+				Options: &schema.RelationOptions{
+					CollectionId:  organizationsCollection.Id,
+					CascadeDelete: true,
+					MinSelect:     types.Pointer(1),
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "name",
+				Type:     schema.FieldTypeText,
+				Required: true,
+				Options: &schema.TextOptions{
+					Min: types.Pointer(1),
+					Max: types.Pointer(100),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "key_hash",
+				Type:     schema.FieldTypeText,
+				Required: true,
+				Options: &schema.TextOptions{
+					Min: types.Pointer(64),
+					Max: types.Pointer(64),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "key_prefix",
+				Type:     schema.FieldTypeText,
+				Required: true,
+				Options: &schema.TextOptions{
+					Max: types.Pointer(20),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "role",
+				Type:     schema.FieldTypeSelect,
+				Required: true,
+				Options: &schema.SelectOptions{
+					MaxSelect: 1,
+					Values:    []string{"admin", "member"},
+				},
+			},
+			&schema.SchemaField{
+				Name:     "created_by",
+				Type:     schema.FieldTypeRelation,
+				Required: false,
+				Options: &schema.RelationOptions{
+					CollectionId:  usersCollection.Id,
+					CascadeDelete: false,
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "last_used_at",
+				Type:     schema.FieldTypeDate,
+				Required: false,
+				Options:  &schema.DateOptions{},
+			},
+		),
+	}
+
+	collection.Indexes = types.JsonArray[string]{
+		"CREATE UNIQUE INDEX idx_organization_api_keys_hash ON organization_api_keys (key_hash)",
+		"CREATE INDEX idx_organization_api_keys_organization ON organization_api_keys (organization)",
+	}
+
+	return dao.SaveCollection(collection)
+}
+
+func addSessionOrganizationField(dao *daos.Dao) error {
+	sessions, err := dao.FindCollectionByNameOrId("sessions")
+	if err != nil {
+		return fmt.Errorf("sessions collection not found: %v", err)
+	}
+
+	// Check if field already exists to avoid duplicates
+	if sessions.Schema.GetFieldByName("organization") != nil {
+		log.Println("organization field already exists in sessions collection, skipping...")
+		return nil
+	}
+
+	organizationsCollection, err := dao.FindCollectionByNameOrId("organizations")
+	if err != nil {
+		return fmt.Errorf("organizations collection not found: %v", err)
+	}
+
+	// Deleting an organization keeps its sessions with their tracking users
+	sessions.Schema.AddField(&schema.SchemaField{
+		Name:     "organization",
+		Type:     schema.FieldTypeRelation,
+		Required: false,
+		Options: &schema.RelationOptions{
+			CollectionId:  organizationsCollection.Id,
+			CascadeDelete: false,
+			MaxSelect:     types.Pointer(1),
+		},
+	})
+
+	sessions.Indexes = append(sessions.Indexes,
+		"CREATE INDEX idx_sessions_organization ON sessions (organization)")
+
+	return dao.SaveCollection(sessions)
+}
@@ -0,0 +1,114 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// userRoleGuard keeps the role field out of reach of the collection API, so users cannot grant themselves roles
+const userRoleGuard = "@request.data.role:isset = false"
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding role field to users collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		// Check if field already exists to avoid duplicates
+		if collection.Schema.GetFieldByName("role") != nil {
+			log.Println("role field already exists in users collection, skipping...")
+			return nil
+		}
+
+		collection.Schema.AddField(&schema.SchemaField{
+			Name:     "role",
+			Type:     schema.FieldTypeSelect,
+			Required: false,
+			Options: &schema.SelectOptions{
+				MaxSelect: 1,
+				Values:    []string{"admin", "moderator", "user", "readonly"},
+			},
+		})
+
+		collection.CreateRule = guardUserRoleRule(collection.CreateRule)
+		collection.UpdateRule = guardUserRoleRule(collection.UpdateRule)
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save users collection with role field: %v", err)
+		}
+
+		// Existing users keep their current capabilities
+		if _, err := db.NewQuery("UPDATE users SET role = 'user' WHERE role = '' OR role IS NULL").Execute(); err != nil {
+			return fmt.Errorf("failed to backfill user roles: %v", err)
+		}
+
+		log.Println("Successfully added role field to users collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the role field and its rule guards from users collection
+		dao := daos.New(db)
+
+		log.Println("Removing role field from users collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			log.Printf("Users collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if field := collection.Schema.GetFieldByName("role"); field != nil {
+			collection.Schema.RemoveField(field.Id)
+		}
+
+		collection.CreateRule = unguardUserRoleRule(collection.CreateRule)
+		collection.UpdateRule = unguardUserRoleRule(collection.UpdateRule)
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove role field from users collection: %v", err)
+		}
+
+		log.Println("Successfully removed role field from users collection!")
+		return nil
+	})
+}
+
+// guardUserRoleRule extends an API rule so that requests setting the role field are rejected;
+// admin-only (nil) rules are left as they are
+func guardUserRoleRule(rule *string) *string {
+	if rule == nil {
+		return nil
+	}
+	if *rule == "" {
+		return types.Pointer(userRoleGuard)
+	}
+	return types.Pointer("(" + *rule + ") && " + userRoleGuard)
+}
+
+// unguardUserRoleRule reverts guardUserRoleRule
+func unguardUserRoleRule(rule *string) *string {
+	if rule == nil {
+		return nil
+	}
+	suffix := ") && " + userRoleGuard
+	switch {
+	case *rule == userRoleGuard:
+		return types.Pointer("")
+	case strings.HasPrefix(*rule, "(") && strings.HasSuffix(*rule, suffix):
+		return types.Pointer(strings.TrimSuffix(strings.TrimPrefix(*rule, "("), suffix))
+	default:
+		return rule
+	}
+}
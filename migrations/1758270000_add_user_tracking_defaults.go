@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding tracking_defaults field to users collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		// Check if field already exists to avoid duplicates
+		if collection.Schema.GetFieldByName("tracking_defaults") != nil {
+			log.Println("tracking_defaults field already exists in users collection, skipping...")
+			return nil
+		}
+
+		// JSON settings object; missing keys fall back to application defaults
+		collection.Schema.AddField(&schema.SchemaField{
+			Name:     "tracking_defaults",
+			Type:     schema.FieldTypeJson,
+			Required: false,
+			Options: &schema.JsonOptions{
+				MaxSize: 10000,
+			},
+		})
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save users collection with tracking_defaults field: %v", err)
+		}
+
+		log.Println("Successfully added tracking_defaults field to users collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the tracking_defaults field from users collection
+		dao := daos.New(db)
+
+		log.Println("Removing tracking_defaults field from users collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			log.Printf("Users collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if field := collection.Schema.GetFieldByName("tracking_defaults"); field != nil {
+			collection.Schema.RemoveField(field.Id)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove tracking_defaults field from users collection: %v", err)
+		}
+
+		log.Println("Successfully removed tracking_defaults field from users collection!")
+		return nil
+	})
+}
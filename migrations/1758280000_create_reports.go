@@ -0,0 +1,228 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// hiddenFieldGuard keeps the moderation flag out of reach of the collection API, so owners cannot unhide their content
+const hiddenFieldGuard = "@request.data.hidden:isset = false"
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		// Create reports collection holding the moderation review queue
+		if err := createReportsCollection(dao); err != nil {
+			return fmt.Errorf("failed to create reports collection: %v", err)
+		}
+
+		// Hidden sessions are removed from public feeds until reviewed
+		if err := addHiddenField(dao, "sessions",
+			"user = @request.auth.id || (public = true && hidden = false)",
+			"user = @request.auth.id && "+hiddenFieldGuard,
+			"user = @request.auth.id && "+hiddenFieldGuard); err != nil {
+			return fmt.Errorf("failed to add hidden field to sessions: %v", err)
+		}
+
+		// Hidden waypoints are removed from public session views until reviewed
+		if err := addHiddenField(dao, "waypoints",
+			"user = @request.auth.id || (session_id.public = true && session_id.hidden = false && hidden = false)",
+			waypointCreateRule+" && "+hiddenFieldGuard,
+			waypointUpdateRule+" && "+hiddenFieldGuard); err != nil {
+			return fmt.Errorf("failed to add hidden field to waypoints: %v", err)
+		}
+
+		return nil
+	}, func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Removing hidden fields from sessions and waypoints collections...")
+		if err := removeHiddenField(dao, "sessions",
+			"user = @request.auth.id || public = true",
+			"user = @request.auth.id",
+			"user = @request.auth.id"); err != nil {
+			return err
+		}
+		if err := removeHiddenField(dao, "waypoints",
+			"user = @request.auth.id || session_id.public = true",
+			waypointCreateRule,
+			waypointUpdateRule); err != nil {
+			return err
+		}
+
+		if collection, err := dao.FindCollectionByNameOrId("reports"); err == nil {
+			if err := dao.DeleteCollection(collection); err != nil {
+				return fmt.Errorf("failed to delete reports collection: %v", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func createReportsCollection(dao *daos.Dao) error {
+	// Check if collection already exists
+	if _, err := dao.FindCollectionByNameOrId("reports"); err == nil {
+		return nil
+	}
+
+	usersCollection, err := dao.FindCollectionByNameOrId("users")
+	if err != nil {
+		return fmt.Errorf("users collection not found: %v", err)
+	}
+
+	// Reports are reviewed through the API, so the collection is admin-only. The target is
+	// stored as type and ID because reports may point at records of several collections.
+	collection := &models.Collection{
+		Name: "reports",
+		Type: models.CollectionTypeBase,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name:     "target_type",
+				Type:     schema.FieldTypeSelect,
+				Required: true,
+				Options: &schema.SelectOptions{
+					MaxSelect: 1,
+					Values:    []string{"session", "waypoint", "community_waypoint"},
+				},
+			},
+			&schema.SchemaField{
+				Name:     "target_id",
+				Type:     schema.FieldTypeText,
+				Required: true,
+				Options: &schema.TextOptions{
+					Min: types.Pointer(1),
+					Max: types.Pointer(15),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "target_owner",
+				Type:     schema.FieldTypeRelation,
+				Required: false,
+				Options: &schema.RelationOptions{
+					CollectionId:  usersCollection.Id,
+					CascadeDelete: true,
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "reporter",
+				Type:     schema.FieldTypeRelation,
+				Required: true,
+				Options: &schema.RelationOptions{
+					CollectionId:  usersCollection.Id,
+					CascadeDelete: true,
+					MinSelect:     types.Pointer(1),
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "reason",
+				Type:     schema.FieldTypeSelect,
+				Required: true,
+				Options: &schema.SelectOptions{
+					MaxSelect: 1,
+					Values:    []string{"spam", "inappropriate", "harassment", "privacy", "other"},
+				},
+			},
+			&schema.SchemaField{
+				Name:     "comment",
+				Type:     schema.FieldTypeText,
+				Required: false,
+				Options: &schema.TextOptions{
+					Max: types.Pointer(500),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "status",
+				Type:     schema.FieldTypeSelect,
+				Required: true,
+				Options: &schema.SelectOptions{
+					MaxSelect: 1,
+					Values:    []string{"pending", "dismissed", "actioned"},
+				},
+			},
+			&schema.SchemaField{
+				Name:     "reviewed_by",
+				Type:     schema.FieldTypeRelation,
+				Required: false,
+				Options: &schema.RelationOptions{
+					CollectionId:  usersCollection.Id,
+					CascadeDelete: false,
+					MaxSelect:     types.Pointer(1),
+				},
+			},
+			&schema.SchemaField{
+				Name:     "reviewed_at",
+				Type:     schema.FieldTypeDate,
+				Required: false,
+				Options:  &schema.DateOptions{},
+			},
+		),
+	}
+
+	// One report per user and target
+	collection.Indexes = types.JsonArray[string]{
+		"CREATE UNIQUE INDEX idx_reports_reporter ON reports (target_type, target_id, reporter)",
+		"CREATE INDEX idx_reports_status ON reports (status, created)",
+	}
+
+	return dao.SaveCollection(collection)
+}
+
+func addHiddenField(dao *daos.Dao, collectionName, viewRule, createRule, updateRule string) error {
+	log.Printf("Adding hidden field to %s collection...", collectionName)
+
+	collection, err := dao.FindCollectionByNameOrId(collectionName)
+	if err != nil {
+		return fmt.Errorf("%s collection not found: %v", collectionName, err)
+	}
+
+	// Check if field already exists to avoid duplicates
+	if collection.Schema.GetFieldByName("hidden") != nil {
+		log.Printf("hidden field already exists in %s collection, skipping...", collectionName)
+		return nil
+	}
+
+	collection.Schema.AddField(&schema.SchemaField{
+		Name:     "hidden",
+		Type:     schema.FieldTypeBool,
+		Required: false,
+	})
+
+	collection.ViewRule = types.Pointer(viewRule)
+	collection.CreateRule = types.Pointer(createRule)
+	collection.UpdateRule = types.Pointer(updateRule)
+
+	return dao.SaveCollection(collection)
+}
+
+func removeHiddenField(dao *daos.Dao, collectionName, viewRule, createRule, updateRule string) error {
+	collection, err := dao.FindCollectionByNameOrId(collectionName)
+	if err != nil {
+		log.Printf("%s collection not found during rollback: %v", collectionName, err)
+		return nil // Don't fail rollback if collection doesn't exist
+	}
+
+	if field := collection.Schema.GetFieldByName("hidden"); field != nil {
+		collection.Schema.RemoveField(field.Id)
+	}
+
+	collection.ViewRule = types.Pointer(viewRule)
+	collection.CreateRule = types.Pointer(createRule)
+	collection.UpdateRule = types.Pointer(updateRule)
+
+	if err := dao.SaveCollection(collection); err != nil {
+		return fmt.Errorf("failed to remove hidden field from %s collection: %v", collectionName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Creating auth_sessions collection...")
+
+		// Check if collection already exists
+		if _, err := dao.FindCollectionByNameOrId("auth_sessions"); err == nil {
+			log.Println("auth_sessions collection already exists, skipping...")
+			return nil
+		}
+
+		usersCollection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		// Device sessions are managed through the API, so the collection is admin-only
+		collection := &models.Collection{
+			Name: "auth_sessions",
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{
+					Name:     "user",
+					Type:     schema.FieldTypeRelation,
+					Required: true,
+					Options: &schema.RelationOptions{
+						CollectionId:  usersCollection.Id,
+						CascadeDelete: true,
+						MinSelect:     types.Pointer(1),
+						MaxSelect:     types.Pointer(1),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "jti",
+					Type:     schema.FieldTypeText,
+					Required: true,
+					Options: &schema.TextOptions{
+						Min: types.Pointer(1),
+						Max: types.Pointer(64),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "user_agent",
+					Type:     schema.FieldTypeText,
+					Required: false,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(500),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "ip",
+					Type:     schema.FieldTypeText,
+					Required: false,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(45), // Longest textual IPv6 address
+					},
+				},
+				&schema.SchemaField{
+					Name:     "last_seen_at",
+					Type:     schema.FieldTypeDate,
+					Required: false,
+					Options:  &schema.DateOptions{},
+				},
+				&schema.SchemaField{
+					Name:     "expires_at",
+					Type:     schema.FieldTypeDate,
+					Required: true,
+					Options:  &schema.DateOptions{},
+				},
+				&schema.SchemaField{
+					Name:     "revoked_at",
+					Type:     schema.FieldTypeDate,
+					Required: false,
+					Options:  &schema.DateOptions{},
+				},
+			),
+		}
+
+		collection.Indexes = types.JsonArray[string]{
+			"CREATE UNIQUE INDEX idx_auth_sessions_jti ON auth_sessions (jti)",
+			"CREATE INDEX idx_auth_sessions_user ON auth_sessions (user, created)",
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to create auth_sessions collection: %v", err)
+		}
+
+		log.Println("Successfully created auth_sessions collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the auth_sessions collection
+		dao := daos.New(db)
+
+		log.Println("Removing auth_sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("auth_sessions")
+		if err != nil {
+			log.Printf("auth_sessions collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if err := dao.DeleteCollection(collection); err != nil {
+			return fmt.Errorf("failed to delete auth_sessions collection: %v", err)
+		}
+
+		log.Println("Successfully removed auth_sessions collection!")
+		return nil
+	})
+}
@@ -0,0 +1,103 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// authSessionAnomalyFields returns the fields added to auth_sessions for login anomaly detection
+func authSessionAnomalyFields() []*schema.SchemaField {
+	return []*schema.SchemaField{
+		{
+			Name:     "country",
+			Type:     schema.FieldTypeText,
+			Required: false,
+			Options: &schema.TextOptions{
+				Max: types.Pointer(2), // ISO 3166-1 alpha-2 code
+			},
+		},
+		{
+			Name:     "asn",
+			Type:     schema.FieldTypeNumber,
+			Required: false,
+			Options: &schema.NumberOptions{
+				Min:       types.Pointer(0.0),
+				NoDecimal: true,
+			},
+		},
+		{
+			Name:     "suspicious_reasons",
+			Type:     schema.FieldTypeText,
+			Required: false,
+			Options: &schema.TextOptions{
+				Max: types.Pointer(100), // Comma separated anomaly reasons
+			},
+		},
+		{
+			Name:     "reauthenticated_at",
+			Type:     schema.FieldTypeDate,
+			Required: false,
+			Options:  &schema.DateOptions{},
+		},
+	}
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding anomaly detection fields to auth_sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("auth_sessions")
+		if err != nil {
+			return fmt.Errorf("auth_sessions collection not found: %v", err)
+		}
+
+		for _, field := range authSessionAnomalyFields() {
+			// Check if field already exists to avoid duplicates
+			if collection.Schema.GetFieldByName(field.Name) != nil {
+				log.Printf("%s field already exists in auth_sessions collection, skipping...", field.Name)
+				continue
+			}
+			collection.Schema.AddField(field)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save auth_sessions collection with anomaly detection fields: %v", err)
+		}
+
+		log.Println("Successfully added anomaly detection fields to auth_sessions collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the anomaly detection fields from auth_sessions collection
+		dao := daos.New(db)
+
+		log.Println("Removing anomaly detection fields from auth_sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("auth_sessions")
+		if err != nil {
+			log.Printf("auth_sessions collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		for _, field := range authSessionAnomalyFields() {
+			if existing := collection.Schema.GetFieldByName(field.Name); existing != nil {
+				collection.Schema.RemoveField(existing.Id)
+			}
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove anomaly detection fields from auth_sessions collection: %v", err)
+		}
+
+		log.Println("Successfully removed anomaly detection fields from auth_sessions collection!")
+		return nil
+	})
+}
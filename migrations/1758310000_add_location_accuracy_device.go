@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// locationFixFields returns the fields added to locations for choosing between fixes of several devices
+func locationFixFields() []*schema.SchemaField {
+	return []*schema.SchemaField{
+		{
+			Name:     "accuracy",
+			Type:     schema.FieldTypeNumber,
+			Required: false,
+			Options: &schema.NumberOptions{
+				Min: types.Pointer(0.0), // Horizontal accuracy radius in meters
+			},
+		},
+		{
+			Name:     "device",
+			Type:     schema.FieldTypeText,
+			Required: false,
+			Options: &schema.TextOptions{
+				Max: types.Pointer(100), // Client-chosen device identifier
+			},
+		},
+	}
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding accuracy and device fields to locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			return fmt.Errorf("locations collection not found: %v", err)
+		}
+
+		for _, field := range locationFixFields() {
+			// Check if field already exists to avoid duplicates
+			if collection.Schema.GetFieldByName(field.Name) != nil {
+				log.Printf("%s field already exists in locations collection, skipping...", field.Name)
+				continue
+			}
+			collection.Schema.AddField(field)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save locations collection with accuracy and device fields: %v", err)
+		}
+
+		log.Println("Successfully added accuracy and device fields to locations collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the accuracy and device fields from locations collection
+		dao := daos.New(db)
+
+		log.Println("Removing accuracy and device fields from locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			log.Printf("locations collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		for _, field := range locationFixFields() {
+			if existing := collection.Schema.GetFieldByName(field.Name); existing != nil {
+				collection.Schema.RemoveField(existing.Id)
+			}
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove accuracy and device fields from locations collection: %v", err)
+		}
+
+		log.Println("Successfully removed accuracy and device fields from locations collection!")
+		return nil
+	})
+}
@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// sessionEndFields returns the fields added to sessions for ending them after inactivity
+func sessionEndFields() []*schema.SchemaField {
+	return []*schema.SchemaField{
+		{
+			Name:     "ended_at",
+			Type:     schema.FieldTypeDate,
+			Required: false,
+			Options:  &schema.DateOptions{},
+		},
+		{
+			Name:     "stats",
+			Type:     schema.FieldTypeJson,
+			Required: false,
+			Options: &schema.JsonOptions{
+				MaxSize: 100000, // Track statistics computed when the session ended
+			},
+		},
+	}
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding session end fields to sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			return fmt.Errorf("sessions collection not found: %v", err)
+		}
+
+		for _, field := range sessionEndFields() {
+			// Check if field already exists to avoid duplicates
+			if collection.Schema.GetFieldByName(field.Name) != nil {
+				log.Printf("%s field already exists in sessions collection, skipping...", field.Name)
+				continue
+			}
+			collection.Schema.AddField(field)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save sessions collection with session end fields: %v", err)
+		}
+
+		log.Println("Successfully added session end fields to sessions collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the session end fields from sessions collection
+		dao := daos.New(db)
+
+		log.Println("Removing session end fields from sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			log.Printf("sessions collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		for _, field := range sessionEndFields() {
+			if existing := collection.Schema.GetFieldByName(field.Name); existing != nil {
+				collection.Schema.RemoveField(existing.Id)
+			}
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove session end fields from sessions collection: %v", err)
+		}
+
+		log.Println("Successfully removed session end fields from sessions collection!")
+		return nil
+	})
+}
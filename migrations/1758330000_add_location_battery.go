@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding battery field to locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			return fmt.Errorf("locations collection not found: %v", err)
+		}
+
+		// Check if field already exists to avoid duplicates
+		if collection.Schema.GetFieldByName("battery") != nil {
+			log.Println("battery field already exists in locations collection, skipping...")
+			return nil
+		}
+
+		// Battery level of the tracker in percent
+		collection.Schema.AddField(&schema.SchemaField{
+			Name:     "battery",
+			Type:     schema.FieldTypeNumber,
+			Required: false,
+			Options: &schema.NumberOptions{
+				Min: types.Pointer(0.0),
+				Max: types.Pointer(100.0),
+			},
+		})
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save locations collection with battery field: %v", err)
+		}
+
+		log.Println("Successfully added battery field to locations collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the battery field from locations collection
+		dao := daos.New(db)
+
+		log.Println("Removing battery field from locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			log.Printf("locations collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if field := collection.Schema.GetFieldByName("battery"); field != nil {
+			collection.Schema.RemoveField(field.Id)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove battery field from locations collection: %v", err)
+		}
+
+		log.Println("Successfully removed battery field from locations collection!")
+		return nil
+	})
+}
@@ -0,0 +1,104 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// sessionCheckInFields returns the fields added to sessions for missed check-in alerts
+func sessionCheckInFields() []*schema.SchemaField {
+	return []*schema.SchemaField{
+		{
+			Name:     "checkin_interval",
+			Type:     schema.FieldTypeNumber,
+			Required: false,
+			Options: &schema.NumberOptions{
+				Min:       types.Pointer(0.0), // Minutes; 0 means disarmed
+				NoDecimal: true,
+			},
+		},
+		{
+			Name:     "checkin_armed_at",
+			Type:     schema.FieldTypeDate,
+			Required: false,
+			Options:  &schema.DateOptions{},
+		},
+		{
+			Name:     "checkin_contacts",
+			Type:     schema.FieldTypeJson,
+			Required: false,
+			Options: &schema.JsonOptions{
+				MaxSize: 2000, // Email addresses alerted after the owner
+			},
+		},
+		{
+			Name:     "checkin_alert_level",
+			Type:     schema.FieldTypeNumber,
+			Required: false,
+			Options: &schema.NumberOptions{
+				Min:       types.Pointer(0.0),
+				NoDecimal: true,
+			},
+		},
+	}
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding check-in fields to sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			return fmt.Errorf("sessions collection not found: %v", err)
+		}
+
+		for _, field := range sessionCheckInFields() {
+			// Check if field already exists to avoid duplicates
+			if collection.Schema.GetFieldByName(field.Name) != nil {
+				log.Printf("%s field already exists in sessions collection, skipping...", field.Name)
+				continue
+			}
+			collection.Schema.AddField(field)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save sessions collection with check-in fields: %v", err)
+		}
+
+		log.Println("Successfully added check-in fields to sessions collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the check-in fields from sessions collection
+		dao := daos.New(db)
+
+		log.Println("Removing check-in fields from sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			log.Printf("sessions collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		for _, field := range sessionCheckInFields() {
+			if existing := collection.Schema.GetFieldByName(field.Name); existing != nil {
+				collection.Schema.RemoveField(existing.Id)
+			}
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove check-in fields from sessions collection: %v", err)
+		}
+
+		log.Println("Successfully removed check-in fields from sessions collection!")
+		return nil
+	})
+}
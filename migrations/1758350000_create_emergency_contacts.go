@@ -0,0 +1,134 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Creating emergency_contacts collection...")
+
+		// Check if collection already exists
+		if _, err := dao.FindCollectionByNameOrId("emergency_contacts"); err == nil {
+			log.Println("emergency_contacts collection already exists, skipping...")
+			return nil
+		}
+
+		usersCollection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		// Contacts and their verification tokens are managed through the API, so the collection is admin-only
+		collection := &models.Collection{
+			Name: "emergency_contacts",
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{
+					Name:     "user",
+					Type:     schema.FieldTypeRelation,
+					Required: true,
+					Options: &schema.RelationOptions{
+						CollectionId:  usersCollection.Id,
+						CascadeDelete: true,
+						MinSelect:     types.Pointer(1),
+						MaxSelect:     types.Pointer(1),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "name",
+					Type:     schema.FieldTypeText,
+					Required: true,
+					Options: &schema.TextOptions{
+						Min: types.Pointer(1),
+						Max: types.Pointer(100),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "email",
+					Type:     schema.FieldTypeEmail,
+					Required: true,
+					Options:  &schema.EmailOptions{},
+				},
+				&schema.SchemaField{
+					Name:     "phone",
+					Type:     schema.FieldTypeText,
+					Required: false,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(16), // E.164 number with leading plus
+					},
+				},
+				&schema.SchemaField{
+					Name:     "channel",
+					Type:     schema.FieldTypeSelect,
+					Required: true,
+					Options: &schema.SelectOptions{
+						MaxSelect: 1,
+						Values:    []string{"email", "sms"},
+					},
+				},
+				&schema.SchemaField{
+					Name:     "verified_at",
+					Type:     schema.FieldTypeDate,
+					Required: false,
+					Options:  &schema.DateOptions{},
+				},
+				&schema.SchemaField{
+					Name:     "verification_token",
+					Type:     schema.FieldTypeText,
+					Required: false,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(64),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "verification_sent_at",
+					Type:     schema.FieldTypeDate,
+					Required: false,
+					Options:  &schema.DateOptions{},
+				},
+			),
+		}
+
+		collection.Indexes = types.JsonArray[string]{
+			"CREATE INDEX idx_emergency_contacts_user ON emergency_contacts (user)",
+			"CREATE INDEX idx_emergency_contacts_token ON emergency_contacts (verification_token)",
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to create emergency_contacts collection: %v", err)
+		}
+
+		log.Println("Successfully created emergency_contacts collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the emergency_contacts collection
+		dao := daos.New(db)
+
+		log.Println("Removing emergency_contacts collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("emergency_contacts")
+		if err != nil {
+			log.Printf("emergency_contacts collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if err := dao.DeleteCollection(collection); err != nil {
+			return fmt.Errorf("failed to delete emergency_contacts collection: %v", err)
+		}
+
+		log.Println("Successfully removed emergency_contacts collection!")
+		return nil
+	})
+}
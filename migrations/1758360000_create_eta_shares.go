@@ -0,0 +1,138 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Creating eta_shares collection...")
+
+		// Check if collection already exists
+		if _, err := dao.FindCollectionByNameOrId("eta_shares"); err == nil {
+			log.Println("eta_shares collection already exists, skipping...")
+			return nil
+		}
+
+		usersCollection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		// Shares are read through their token by the API only, so the collection is admin-only
+		collection := &models.Collection{
+			Name: "eta_shares",
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{
+					Name:     "user",
+					Type:     schema.FieldTypeRelation,
+					Required: true,
+					Options: &schema.RelationOptions{
+						CollectionId:  usersCollection.Id,
+						CascadeDelete: true,
+						MinSelect:     types.Pointer(1),
+						MaxSelect:     types.Pointer(1),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "token",
+					Type:     schema.FieldTypeText,
+					Required: true,
+					Options: &schema.TextOptions{
+						Min: types.Pointer(1),
+						Max: types.Pointer(64),
+					},
+				},
+				// Not required, a required number field would reject the equator and the prime meridian
+				&schema.SchemaField{
+					Name:     "latitude",
+					Type:     schema.FieldTypeNumber,
+					Required: false,
+					Options: &schema.NumberOptions{
+						Min: types.Pointer(-90.0),
+						Max: types.Pointer(90.0),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "longitude",
+					Type:     schema.FieldTypeNumber,
+					Required: false,
+					Options: &schema.NumberOptions{
+						Min: types.Pointer(-180.0),
+						Max: types.Pointer(180.0),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "name",
+					Type:     schema.FieldTypeText,
+					Required: false,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(200),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "arrival_radius",
+					Type:     schema.FieldTypeNumber,
+					Required: true,
+					Options: &schema.NumberOptions{
+						Min: types.Pointer(1.0),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "expires_at",
+					Type:     schema.FieldTypeDate,
+					Required: true,
+					Options:  &schema.DateOptions{},
+				},
+				&schema.SchemaField{
+					Name:     "arrived_at",
+					Type:     schema.FieldTypeDate,
+					Required: false,
+					Options:  &schema.DateOptions{},
+				},
+			),
+		}
+
+		collection.Indexes = types.JsonArray[string]{
+			"CREATE UNIQUE INDEX idx_eta_shares_token ON eta_shares (token)",
+			"CREATE INDEX idx_eta_shares_user ON eta_shares (user, expires_at)",
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to create eta_shares collection: %v", err)
+		}
+
+		log.Println("Successfully created eta_shares collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the eta_shares collection
+		dao := daos.New(db)
+
+		log.Println("Removing eta_shares collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("eta_shares")
+		if err != nil {
+			log.Printf("eta_shares collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if err := dao.DeleteCollection(collection); err != nil {
+			return fmt.Errorf("failed to delete eta_shares collection: %v", err)
+		}
+
+		log.Println("Successfully removed eta_shares collection!")
+		return nil
+	})
+}
@@ -0,0 +1,112 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Creating timelines collection...")
+
+		// Check if collection already exists
+		if _, err := dao.FindCollectionByNameOrId("timelines"); err == nil {
+			log.Println("timelines collection already exists, skipping...")
+			return nil
+		}
+
+		usersCollection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		// Timelines are computed by a background job and read through the API, so the collection is admin-only
+		collection := &models.Collection{
+			Name: "timelines",
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{
+					Name:     "user",
+					Type:     schema.FieldTypeRelation,
+					Required: true,
+					Options: &schema.RelationOptions{
+						CollectionId:  usersCollection.Id,
+						CascadeDelete: true,
+						MinSelect:     types.Pointer(1),
+						MaxSelect:     types.Pointer(1),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "date",
+					Type:     schema.FieldTypeText,
+					Required: true,
+					Options: &schema.TextOptions{
+						Min: types.Pointer(10),
+						Max: types.Pointer(10), // YYYY-MM-DD in the user's time zone
+					},
+				},
+				&schema.SchemaField{
+					Name:     "timezone",
+					Type:     schema.FieldTypeText,
+					Required: true,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(64),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "entries",
+					Type:     schema.FieldTypeJson,
+					Required: false,
+					Options: &schema.JsonOptions{
+						MaxSize: 2000000,
+					},
+				},
+				&schema.SchemaField{
+					Name:     "computed_at",
+					Type:     schema.FieldTypeDate,
+					Required: true,
+					Options:  &schema.DateOptions{},
+				},
+			),
+		}
+
+		collection.Indexes = types.JsonArray[string]{
+			"CREATE UNIQUE INDEX idx_timelines_user_date ON timelines (user, date)",
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to create timelines collection: %v", err)
+		}
+
+		log.Println("Successfully created timelines collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the timelines collection
+		dao := daos.New(db)
+
+		log.Println("Removing timelines collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("timelines")
+		if err != nil {
+			log.Printf("timelines collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if err := dao.DeleteCollection(collection); err != nil {
+			return fmt.Errorf("failed to delete timelines collection: %v", err)
+		}
+
+		log.Println("Successfully removed timelines collection!")
+		return nil
+	})
+}
@@ -0,0 +1,121 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const locationGeocodedIndex = "CREATE INDEX idx_locations_geocoded ON locations (geocoded, created)"
+
+// locationCountryFields returns the fields added to locations for country statistics
+func locationCountryFields() []*schema.SchemaField {
+	return []*schema.SchemaField{
+		{
+			Name:     "country",
+			Type:     schema.FieldTypeText,
+			Required: false,
+			Options: &schema.TextOptions{
+				Max: types.Pointer(2), // ISO 3166-1 alpha-2 code
+			},
+		},
+		{
+			Name:     "country_name",
+			Type:     schema.FieldTypeText,
+			Required: false,
+			Options: &schema.TextOptions{
+				Max: types.Pointer(100),
+			},
+		},
+		{
+			Name:     "region",
+			Type:     schema.FieldTypeText,
+			Required: false,
+			Options: &schema.TextOptions{
+				Max: types.Pointer(100), // State, province or similar first-level subdivision
+			},
+		},
+		{
+			// Set once the location was looked up, also when it lies outside any country
+			Name:     "geocoded",
+			Type:     schema.FieldTypeBool,
+			Required: false,
+			Options:  &schema.BoolOptions{},
+		},
+	}
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding country fields to locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			return fmt.Errorf("locations collection not found: %v", err)
+		}
+
+		for _, field := range locationCountryFields() {
+			// Check if field already exists to avoid duplicates
+			if collection.Schema.GetFieldByName(field.Name) != nil {
+				log.Printf("%s field already exists in locations collection, skipping...", field.Name)
+				continue
+			}
+			collection.Schema.AddField(field)
+		}
+
+		hasIndex := false
+		for _, index := range collection.Indexes {
+			hasIndex = hasIndex || index == locationGeocodedIndex
+		}
+		if !hasIndex {
+			collection.Indexes = append(collection.Indexes, locationGeocodedIndex)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save locations collection with country fields: %v", err)
+		}
+
+		log.Println("Successfully added country fields to locations collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the country fields from locations collection
+		dao := daos.New(db)
+
+		log.Println("Removing country fields from locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			log.Printf("locations collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		indexes := types.JsonArray[string]{}
+		for _, index := range collection.Indexes {
+			if index != locationGeocodedIndex {
+				indexes = append(indexes, index)
+			}
+		}
+		collection.Indexes = indexes
+
+		for _, field := range locationCountryFields() {
+			if existing := collection.Schema.GetFieldByName(field.Name); existing != nil {
+				collection.Schema.RemoveField(existing.Id)
+			}
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove country fields from locations collection: %v", err)
+		}
+
+		log.Println("Successfully removed country fields from locations collection!")
+		return nil
+	})
+}
@@ -0,0 +1,86 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// sessionSurfaceFields returns the fields added to sessions for the surface and road type of tracks
+func sessionSurfaceFields() []*schema.SchemaField {
+	return []*schema.SchemaField{
+		{
+			Name:     "surface",
+			Type:     schema.FieldTypeJson,
+			Required: false,
+			Options: &schema.JsonOptions{
+				MaxSize: 5000000, // Matched segments with their geometry and the surface summary
+			},
+		},
+		{
+			// Set once the track was matched, also when it could not be matched to any way
+			Name:     "surface_matched_at",
+			Type:     schema.FieldTypeDate,
+			Required: false,
+			Options:  &schema.DateOptions{},
+		},
+	}
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding surface fields to sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			return fmt.Errorf("sessions collection not found: %v", err)
+		}
+
+		for _, field := range sessionSurfaceFields() {
+			// Check if field already exists to avoid duplicates
+			if collection.Schema.GetFieldByName(field.Name) != nil {
+				log.Printf("%s field already exists in sessions collection, skipping...", field.Name)
+				continue
+			}
+			collection.Schema.AddField(field)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save sessions collection with surface fields: %v", err)
+		}
+
+		log.Println("Successfully added surface fields to sessions collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the surface fields from sessions collection
+		dao := daos.New(db)
+
+		log.Println("Removing surface fields from sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			log.Printf("sessions collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		for _, field := range sessionSurfaceFields() {
+			if existing := collection.Schema.GetFieldByName(field.Name); existing != nil {
+				collection.Schema.RemoveField(existing.Id)
+			}
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove surface fields from sessions collection: %v", err)
+		}
+
+		log.Println("Successfully removed surface fields from sessions collection!")
+		return nil
+	})
+}
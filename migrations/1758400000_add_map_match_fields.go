@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// mapMatchFields returns the fields added per collection for road-snapped session tracks
+func mapMatchFields() map[string][]*schema.SchemaField {
+	return map[string][]*schema.SchemaField{
+		"locations": {
+			{
+				// Position snapped to a way; zero when the location was not matched
+				Name:     "matched_latitude",
+				Type:     schema.FieldTypeNumber,
+				Required: false,
+				Options:  &schema.NumberOptions{},
+			},
+			{
+				Name:     "matched_longitude",
+				Type:     schema.FieldTypeNumber,
+				Required: false,
+				Options:  &schema.NumberOptions{},
+			},
+		},
+		"sessions": {
+			{
+				Name:     "map_matched_at",
+				Type:     schema.FieldTypeDate,
+				Required: false,
+				Options:  &schema.DateOptions{},
+			},
+		},
+	}
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		for name, fields := range mapMatchFields() {
+			log.Printf("Adding map match fields to %s collection...", name)
+
+			collection, err := dao.FindCollectionByNameOrId(name)
+			if err != nil {
+				return fmt.Errorf("%s collection not found: %v", name, err)
+			}
+
+			for _, field := range fields {
+				// Check if field already exists to avoid duplicates
+				if collection.Schema.GetFieldByName(field.Name) != nil {
+					log.Printf("%s field already exists in %s collection, skipping...", field.Name, name)
+					continue
+				}
+				collection.Schema.AddField(field)
+			}
+
+			if err := dao.SaveCollection(collection); err != nil {
+				return fmt.Errorf("failed to save %s collection with map match fields: %v", name, err)
+			}
+		}
+
+		log.Println("Successfully added map match fields!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the map match fields
+		dao := daos.New(db)
+
+		log.Println("Removing map match fields...")
+
+		for name, fields := range mapMatchFields() {
+			collection, err := dao.FindCollectionByNameOrId(name)
+			if err != nil {
+				log.Printf("%s collection not found during rollback: %v", name, err)
+				continue // Don't fail rollback if collection doesn't exist
+			}
+
+			for _, field := range fields {
+				if existing := collection.Schema.GetFieldByName(field.Name); existing != nil {
+					collection.Schema.RemoveField(existing.Id)
+				}
+			}
+
+			if err := dao.SaveCollection(collection); err != nil {
+				return fmt.Errorf("failed to remove map match fields from %s collection: %v", name, err)
+			}
+		}
+
+		log.Println("Successfully removed map match fields!")
+		return nil
+	})
+}
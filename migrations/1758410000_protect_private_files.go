@@ -0,0 +1,75 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// privateFileFields lists the file fields per collection that are only served with signed URLs
+func privateFileFields() map[string]string {
+	return map[string]string{
+		"waypoints": "photo",
+		"sessions":  "gpx_track",
+	}
+}
+
+// setFileFieldsProtected marks the private file fields protected, so PocketBase's files API no
+// longer serves them to anyone who knows the file name
+func setFileFieldsProtected(db dbx.Builder, protected bool) error {
+	dao := daos.New(db)
+
+	for name, fieldName := range privateFileFields() {
+		collection, err := dao.FindCollectionByNameOrId(name)
+		if err != nil {
+			log.Printf("%s collection not found, skipping: %v", name, err)
+			continue
+		}
+
+		field := collection.Schema.GetFieldByName(fieldName)
+		if field == nil {
+			log.Printf("%s field not found in %s collection, skipping...", fieldName, name)
+			continue
+		}
+
+		options, ok := field.Options.(*schema.FileOptions)
+		if !ok {
+			return fmt.Errorf("%s field of %s collection is not a file field", fieldName, name)
+		}
+		options.Protected = protected
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save %s collection: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		log.Println("Protecting waypoint photos and session GPX files...")
+
+		if err := setFileFieldsProtected(db, true); err != nil {
+			return err
+		}
+
+		log.Println("Successfully protected private file fields!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Serve the files by name again
+		log.Println("Removing protection from waypoint photos and session GPX files...")
+
+		if err := setFileFieldsProtected(db, false); err != nil {
+			return err
+		}
+
+		log.Println("Successfully removed file field protection!")
+		return nil
+	})
+}
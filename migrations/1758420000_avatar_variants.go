@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// updateAvatarField replaces the accepted MIME types and thumb sizes of the users' avatar field
+func updateAvatarField(db dbx.Builder, mimeTypes []string, thumbs []string) error {
+	dao := daos.New(db)
+
+	collection, err := dao.FindCollectionByNameOrId("users")
+	if err != nil {
+		log.Printf("users collection not found, skipping: %v", err)
+		return nil
+	}
+
+	field := collection.Schema.GetFieldByName("avatar")
+	if field == nil {
+		log.Println("avatar field not found in users collection, skipping...")
+		return nil
+	}
+
+	options, ok := field.Options.(*schema.FileOptions)
+	if !ok {
+		return fmt.Errorf("avatar field of users collection is not a file field")
+	}
+	options.MimeTypes = mimeTypes
+	options.Thumbs = thumbs
+
+	return dao.SaveCollection(collection)
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		log.Println("Restricting avatars to processed PNG images with resized variants...")
+
+		// Avatars are re-encoded as PNG on upload, keep in sync with constants.AvatarThumb*
+		if err := updateAvatarField(db, []string{"image/png"}, []string{"64x64", "128x128"}); err != nil {
+			return err
+		}
+
+		log.Println("Successfully updated avatar field!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Accept the original image types again and drop the thumb sizes
+		log.Println("Restoring original avatar field options...")
+
+		mimeTypes := []string{"image/jpeg", "image/png", "image/svg+xml", "image/gif", "image/webp"}
+		if err := updateAvatarField(db, mimeTypes, nil); err != nil {
+			return err
+		}
+
+		log.Println("Successfully restored avatar field options!")
+		return nil
+	})
+}
@@ -0,0 +1,121 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Creating hardware_trackers collection...")
+
+		// Check if collection already exists
+		if _, err := dao.FindCollectionByNameOrId("hardware_trackers"); err == nil {
+			log.Println("hardware_trackers collection already exists, skipping...")
+			return nil
+		}
+
+		usersCollection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		// Users register their own devices through the records API
+		collection := &models.Collection{
+			Name:       "hardware_trackers",
+			Type:       models.CollectionTypeBase,
+			ListRule:   types.Pointer("user = @request.auth.id"),
+			ViewRule:   types.Pointer("user = @request.auth.id"),
+			CreateRule: types.Pointer("user = @request.auth.id"),
+			UpdateRule: types.Pointer("user = @request.auth.id && (@request.data.user:isset = false || @request.data.user = @request.auth.id)"),
+			DeleteRule: types.Pointer("user = @request.auth.id"),
+			Schema: schema.NewSchema(
+				&schema.SchemaField{
+					Name:     "user",
+					Type:     schema.FieldTypeRelation,
+					Required: true,
+					Options: &schema.RelationOptions{
+						CollectionId:  usersCollection.Id,
+						CascadeDelete: true,
+						MinSelect:     types.Pointer(1),
+						MaxSelect:     types.Pointer(1),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "imei",
+					Type:     schema.FieldTypeText,
+					Required: true,
+					Options: &schema.TextOptions{
+						Min:     types.Pointer(15),
+						Max:     types.Pointer(15),
+						Pattern: `^[0-9]{15}$`,
+					},
+				},
+				// Stored as the device of the tracked points
+				&schema.SchemaField{
+					Name:     "name",
+					Type:     schema.FieldTypeText,
+					Required: false,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(100),
+					},
+				},
+				// Session the points are tracked in, the user's automatic session when empty
+				&schema.SchemaField{
+					Name:     "session",
+					Type:     schema.FieldTypeText,
+					Required: false,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(100),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "last_seen_at",
+					Type:     schema.FieldTypeDate,
+					Required: false,
+					Options:  &schema.DateOptions{},
+				},
+			),
+		}
+
+		collection.Indexes = types.JsonArray[string]{
+			"CREATE UNIQUE INDEX idx_hardware_trackers_imei ON hardware_trackers (imei)",
+			"CREATE INDEX idx_hardware_trackers_user ON hardware_trackers (user)",
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to create hardware_trackers collection: %v", err)
+		}
+
+		log.Println("Successfully created hardware_trackers collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the hardware_trackers collection
+		dao := daos.New(db)
+
+		log.Println("Removing hardware_trackers collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("hardware_trackers")
+		if err != nil {
+			log.Printf("hardware_trackers collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if err := dao.DeleteCollection(collection); err != nil {
+			return fmt.Errorf("failed to delete hardware_trackers collection: %v", err)
+		}
+
+		log.Println("Successfully removed hardware_trackers collection!")
+		return nil
+	})
+}
@@ -0,0 +1,121 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Creating mapshare_feeds collection...")
+
+		// Check if collection already exists
+		if _, err := dao.FindCollectionByNameOrId("mapshare_feeds"); err == nil {
+			log.Println("mapshare_feeds collection already exists, skipping...")
+			return nil
+		}
+
+		usersCollection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		// Users configure their own feeds through the records API
+		collection := &models.Collection{
+			Name:       "mapshare_feeds",
+			Type:       models.CollectionTypeBase,
+			ListRule:   types.Pointer("user = @request.auth.id"),
+			ViewRule:   types.Pointer("user = @request.auth.id"),
+			CreateRule: types.Pointer("user = @request.auth.id"),
+			UpdateRule: types.Pointer("user = @request.auth.id && (@request.data.user:isset = false || @request.data.user = @request.auth.id)"),
+			DeleteRule: types.Pointer("user = @request.auth.id"),
+			Schema: schema.NewSchema(
+				&schema.SchemaField{
+					Name:     "user",
+					Type:     schema.FieldTypeRelation,
+					Required: true,
+					Options: &schema.RelationOptions{
+						CollectionId:  usersCollection.Id,
+						CascadeDelete: true,
+						MinSelect:     types.Pointer(1),
+						MaxSelect:     types.Pointer(1),
+					},
+				},
+				// MapShare page or feed URL, e.g. https://share.garmin.com/NAME
+				&schema.SchemaField{
+					Name:     "url",
+					Type:     schema.FieldTypeUrl,
+					Required: true,
+					Options: &schema.UrlOptions{
+						OnlyDomains: []string{"share.garmin.com"},
+					},
+				},
+				// MapShare password, only needed when the map is password protected
+				&schema.SchemaField{
+					Name:     "password",
+					Type:     schema.FieldTypeText,
+					Required: false,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(100),
+					},
+				},
+				// Session the positions are imported into; when empty, the session the user tracked
+				// into around the time of a position, or the automatic session
+				&schema.SchemaField{
+					Name:     "session",
+					Type:     schema.FieldTypeText,
+					Required: false,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(100),
+					},
+				},
+				// Time of the last imported position, later polls only import newer ones
+				&schema.SchemaField{
+					Name:     "last_point_at",
+					Type:     schema.FieldTypeDate,
+					Required: false,
+					Options:  &schema.DateOptions{},
+				},
+			),
+		}
+
+		collection.Indexes = types.JsonArray[string]{
+			"CREATE INDEX idx_mapshare_feeds_user ON mapshare_feeds (user)",
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to create mapshare_feeds collection: %v", err)
+		}
+
+		log.Println("Successfully created mapshare_feeds collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the mapshare_feeds collection
+		dao := daos.New(db)
+
+		log.Println("Removing mapshare_feeds collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("mapshare_feeds")
+		if err != nil {
+			log.Printf("mapshare_feeds collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if err := dao.DeleteCollection(collection); err != nil {
+			return fmt.Errorf("failed to delete mapshare_feeds collection: %v", err)
+		}
+
+		log.Println("Successfully removed mapshare_feeds collection!")
+		return nil
+	})
+}
@@ -0,0 +1,122 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Creating ingest_sources collection...")
+
+		// Check if collection already exists
+		if _, err := dao.FindCollectionByNameOrId("ingest_sources"); err == nil {
+			log.Println("ingest_sources collection already exists, skipping...")
+			return nil
+		}
+
+		usersCollection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		// Sources and their secrets are managed through the API, so the collection is admin-only
+		collection := &models.Collection{
+			Name: "ingest_sources",
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{
+					Name:     "user",
+					Type:     schema.FieldTypeRelation,
+					Required: true,
+					Options: &schema.RelationOptions{
+						CollectionId:  usersCollection.Id,
+						CascadeDelete: true,
+						MinSelect:     types.Pointer(1),
+						MaxSelect:     types.Pointer(1),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "name",
+					Type:     schema.FieldTypeText,
+					Required: true,
+					Options: &schema.TextOptions{
+						Min: types.Pointer(1),
+						Max: types.Pointer(100),
+					},
+				},
+				// Session points are tracked in unless the mapping sets one, the automatic session when empty
+				&schema.SchemaField{
+					Name:     "session",
+					Type:     schema.FieldTypeText,
+					Required: false,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(100),
+					},
+				},
+				// Point field name to JSONPath-like path or constant, see utils.MapWebhookLocation
+				&schema.SchemaField{
+					Name:     "mapping",
+					Type:     schema.FieldTypeJson,
+					Required: true,
+					Options: &schema.JsonOptions{
+						MaxSize: 10000,
+					},
+				},
+				&schema.SchemaField{
+					Name:     "secret",
+					Type:     schema.FieldTypeText,
+					Required: true,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(64),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "last_received_at",
+					Type:     schema.FieldTypeDate,
+					Required: false,
+					Options:  &schema.DateOptions{},
+				},
+			),
+		}
+
+		collection.Indexes = types.JsonArray[string]{
+			"CREATE INDEX idx_ingest_sources_user ON ingest_sources (user)",
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to create ingest_sources collection: %v", err)
+		}
+
+		log.Println("Successfully created ingest_sources collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the ingest_sources collection
+		dao := daos.New(db)
+
+		log.Println("Removing ingest_sources collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("ingest_sources")
+		if err != nil {
+			log.Printf("ingest_sources collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if err := dao.DeleteCollection(collection); err != nil {
+			return fmt.Errorf("failed to delete ingest_sources collection: %v", err)
+		}
+
+		log.Println("Successfully removed ingest_sources collection!")
+		return nil
+	})
+}
@@ -0,0 +1,132 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Creating session_media collection...")
+
+		// Check if collection already exists
+		if _, err := dao.FindCollectionByNameOrId("session_media"); err == nil {
+			log.Println("session_media collection already exists, skipping...")
+			return nil
+		}
+
+		sessionsCollection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			return fmt.Errorf("sessions collection not found: %v", err)
+		}
+		usersCollection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		// Media are managed through the API and served with signed URLs to viewers of the session,
+		// so the collection is admin-only and its files are protected
+		collection := &models.Collection{
+			Name: "session_media",
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				&schema.SchemaField{
+					Name:     "session",
+					Type:     schema.FieldTypeRelation,
+					Required: true,
+					Options: &schema.RelationOptions{
+						CollectionId:  sessionsCollection.Id,
+						CascadeDelete: true,
+						MinSelect:     types.Pointer(1),
+						MaxSelect:     types.Pointer(1),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "user",
+					Type:     schema.FieldTypeRelation,
+					Required: true,
+					Options: &schema.RelationOptions{
+						CollectionId:  usersCollection.Id,
+						CascadeDelete: true,
+						MinSelect:     types.Pointer(1),
+						MaxSelect:     types.Pointer(1),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "file",
+					Type:     schema.FieldTypeFile,
+					Required: true,
+					Options: &schema.FileOptions{
+						MaxSelect: 1,
+						MaxSize:   10485760, // 10MB limit
+						MimeTypes: []string{"image/jpeg", "image/png", "image/webp", "image/heic", "image/heif", "video/mp4", "video/quicktime"},
+						Protected: true,
+					},
+				},
+				&schema.SchemaField{
+					Name:     "type",
+					Type:     schema.FieldTypeSelect,
+					Required: true,
+					Options: &schema.SelectOptions{
+						MaxSelect: 1,
+						Values:    []string{"image", "video"},
+					},
+				},
+				&schema.SchemaField{
+					Name:     "caption",
+					Type:     schema.FieldTypeText,
+					Required: false,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(500),
+					},
+				},
+				// The session's cover photo, at most one per session; it is not listed with the media
+				&schema.SchemaField{
+					Name:     "cover",
+					Type:     schema.FieldTypeBool,
+					Required: false,
+					Options:  &schema.BoolOptions{},
+				},
+			),
+		}
+
+		collection.Indexes = types.JsonArray[string]{
+			"CREATE INDEX idx_session_media_session ON session_media (session)",
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to create session_media collection: %v", err)
+		}
+
+		log.Println("Successfully created session_media collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the session_media collection
+		dao := daos.New(db)
+
+		log.Println("Removing session_media collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("session_media")
+		if err != nil {
+			log.Printf("session_media collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if err := dao.DeleteCollection(collection); err != nil {
+			return fmt.Errorf("failed to delete session_media collection: %v", err)
+		}
+
+		log.Println("Successfully removed session_media collection!")
+		return nil
+	})
+}
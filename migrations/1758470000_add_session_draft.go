@@ -0,0 +1,105 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// draftViewRules are the view rules of the collections readable through public sessions,
+// before and after drafts are excluded
+var draftViewRules = map[string][2]string{
+	"sessions": {
+		"user = @request.auth.id || (public = true && hidden = false)",
+		"user = @request.auth.id || (public = true && hidden = false && draft = false)",
+	},
+	"waypoints": {
+		"user = @request.auth.id || (session_id.public = true && session_id.hidden = false && hidden = false)",
+		"user = @request.auth.id || (session_id.public = true && session_id.hidden = false && session_id.draft = false && hidden = false)",
+	},
+	"gpx_tracks": {
+		"session_id.user = @request.auth.id || session_id.public = true",
+		"session_id.user = @request.auth.id || (session_id.public = true && session_id.draft = false)",
+	},
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding draft field to sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			return fmt.Errorf("sessions collection not found: %v", err)
+		}
+
+		// Draft sessions, e.g. imported ones under review, stay out of public feeds until published
+		if collection.Schema.GetFieldByName("draft") == nil {
+			collection.Schema.AddField(&schema.SchemaField{
+				Name:     "draft",
+				Type:     schema.FieldTypeBool,
+				Required: false,
+			})
+			if err := dao.SaveCollection(collection); err != nil {
+				return fmt.Errorf("failed to save sessions collection with draft field: %v", err)
+			}
+		} else {
+			log.Println("draft field already exists in sessions collection, skipping...")
+		}
+
+		for name, rules := range draftViewRules {
+			if err := setViewRule(dao, name, rules[1]); err != nil {
+				return err
+			}
+		}
+
+		log.Println("Successfully added draft field!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Restore the view rules and remove the draft field
+		dao := daos.New(db)
+
+		log.Println("Removing draft field from sessions collection...")
+
+		for name, rules := range draftViewRules {
+			if err := setViewRule(dao, name, rules[0]); err != nil {
+				log.Printf("%v", err)
+			}
+		}
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			log.Printf("sessions collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+		if field := collection.Schema.GetFieldByName("draft"); field != nil {
+			collection.Schema.RemoveField(field.Id)
+		}
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove draft field from sessions collection: %v", err)
+		}
+
+		log.Println("Successfully removed draft field!")
+		return nil
+	})
+}
+
+func setViewRule(dao *daos.Dao, collectionName, rule string) error {
+	collection, err := dao.FindCollectionByNameOrId(collectionName)
+	if err != nil {
+		return fmt.Errorf("%s collection not found: %v", collectionName, err)
+	}
+
+	collection.ViewRule = types.Pointer(rule)
+	if err := dao.SaveCollection(collection); err != nil {
+		return fmt.Errorf("failed to update %s view rule: %v", collectionName, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// sessionDownsamplingFields returns the fields added to sessions for storage downsampling
+func sessionDownsamplingFields() []*schema.SchemaField {
+	return []*schema.SchemaField{
+		{
+			Name:     "downsampling",
+			Type:     schema.FieldTypeJson,
+			Required: false,
+			Options: &schema.JsonOptions{
+				MaxSize: 1000, // Session policy, empty when the user's tracking defaults apply
+			},
+		},
+		{
+			Name:     "downsampled_points",
+			Type:     schema.FieldTypeNumber,
+			Required: false,
+			Options: &schema.NumberOptions{
+				Min:       types.Pointer(0.0), // Points discarded at ingestion
+				NoDecimal: true,
+			},
+		},
+	}
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding downsampling fields to sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			return fmt.Errorf("sessions collection not found: %v", err)
+		}
+
+		for _, field := range sessionDownsamplingFields() {
+			// Check if field already exists to avoid duplicates
+			if collection.Schema.GetFieldByName(field.Name) != nil {
+				log.Printf("%s field already exists in sessions collection, skipping...", field.Name)
+				continue
+			}
+			collection.Schema.AddField(field)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save sessions collection with downsampling fields: %v", err)
+		}
+
+		log.Println("Successfully added downsampling fields to sessions collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the downsampling fields from sessions collection
+		dao := daos.New(db)
+
+		log.Println("Removing downsampling fields from sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			log.Printf("sessions collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		for _, field := range sessionDownsamplingFields() {
+			if existing := collection.Schema.GetFieldByName(field.Name); existing != nil {
+				collection.Schema.RemoveField(existing.Id)
+			}
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove downsampling fields from sessions collection: %v", err)
+		}
+
+		log.Println("Successfully removed downsampling fields from sessions collection!")
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding activity field to sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			return fmt.Errorf("sessions collection not found: %v", err)
+		}
+
+		// Activity type of the session, e.g. running, for filtering the public feed
+		if collection.Schema.GetFieldByName("activity") == nil {
+			collection.Schema.AddField(&schema.SchemaField{
+				Name:     "activity",
+				Type:     schema.FieldTypeText,
+				Required: false,
+				Options: &schema.TextOptions{
+					Max: types.Pointer(20),
+				},
+			})
+			if err := dao.SaveCollection(collection); err != nil {
+				return fmt.Errorf("failed to save sessions collection with activity field: %v", err)
+			}
+		} else {
+			log.Println("activity field already exists in sessions collection, skipping...")
+		}
+
+		log.Println("Successfully added activity field!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the activity field
+		dao := daos.New(db)
+
+		log.Println("Removing activity field from sessions collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			log.Printf("sessions collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+		if field := collection.Schema.GetFieldByName("activity"); field != nil {
+			collection.Schema.RemoveField(field.Id)
+		}
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove activity field from sessions collection: %v", err)
+		}
+
+		log.Println("Successfully removed activity field!")
+		return nil
+	})
+}
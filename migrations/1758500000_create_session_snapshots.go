@@ -0,0 +1,122 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Creating session_snapshots collection...")
+
+		// Check if collection already exists
+		if _, err := dao.FindCollectionByNameOrId("session_snapshots"); err == nil {
+			log.Println("session_snapshots collection already exists, skipping...")
+			return nil
+		}
+
+		sessionsCollection, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			return fmt.Errorf("sessions collection not found: %v", err)
+		}
+		usersCollection, err := dao.FindCollectionByNameOrId("users")
+		if err != nil {
+			return fmt.Errorf("users collection not found: %v", err)
+		}
+
+		// Snapshots are created through the API and their files are public, so that the permanent
+		// URL keeps working without signing; the collection itself is admin-only
+		collection := &models.Collection{
+			Name: "session_snapshots",
+			Type: models.CollectionTypeBase,
+			Schema: schema.NewSchema(
+				// Cleared rather than cascaded when the session is deleted, the archive outlives it
+				&schema.SchemaField{
+					Name:     "session",
+					Type:     schema.FieldTypeRelation,
+					Required: false,
+					Options: &schema.RelationOptions{
+						CollectionId:  sessionsCollection.Id,
+						CascadeDelete: false,
+						MaxSelect:     types.Pointer(1),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "user",
+					Type:     schema.FieldTypeRelation,
+					Required: true,
+					Options: &schema.RelationOptions{
+						CollectionId:  usersCollection.Id,
+						CascadeDelete: true,
+						MinSelect:     types.Pointer(1),
+						MaxSelect:     types.Pointer(1),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "name",
+					Type:     schema.FieldTypeText,
+					Required: true,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(200),
+					},
+				},
+				&schema.SchemaField{
+					Name:     "manifest",
+					Type:     schema.FieldTypeText,
+					Required: true,
+					Options: &schema.TextOptions{
+						Max: types.Pointer(200), // Stored name of the manifest among the files
+					},
+				},
+				&schema.SchemaField{
+					Name:     "files",
+					Type:     schema.FieldTypeFile,
+					Required: true,
+					Options: &schema.FileOptions{
+						MaxSelect: 100,
+						MaxSize:   52428800, // 50MB limit
+					},
+				},
+			),
+		}
+
+		collection.Indexes = types.JsonArray[string]{
+			"CREATE INDEX idx_session_snapshots_session ON session_snapshots (session)",
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to create session_snapshots collection: %v", err)
+		}
+
+		log.Println("Successfully created session_snapshots collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the session_snapshots collection
+		dao := daos.New(db)
+
+		log.Println("Removing session_snapshots collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("session_snapshots")
+		if err != nil {
+			log.Printf("session_snapshots collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if err := dao.DeleteCollection(collection); err != nil {
+			return fmt.Errorf("failed to delete session_snapshots collection: %v", err)
+		}
+
+		log.Println("Successfully removed session_snapshots collection!")
+		return nil
+	})
+}
@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// altitudeCollections store an optional altitude next to their coordinates
+var altitudeCollections = []string{"locations", "waypoints", "gpx_tracks", "community_waypoints"}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding has_altitude fields...")
+
+		for _, name := range altitudeCollections {
+			collection, err := dao.FindCollectionByNameOrId(name)
+			if err != nil {
+				return fmt.Errorf("%s collection not found: %v", name, err)
+			}
+
+			// Number fields store a missing altitude as 0, the flag keeps altitudes at sea level
+			if collection.Schema.GetFieldByName("has_altitude") != nil {
+				log.Printf("has_altitude field already exists in %s collection, skipping...", name)
+				continue
+			}
+			collection.Schema.AddField(&schema.SchemaField{
+				Name:     "has_altitude",
+				Type:     schema.FieldTypeBool,
+				Required: false,
+			})
+			if err := dao.SaveCollection(collection); err != nil {
+				return fmt.Errorf("failed to save %s collection with has_altitude field: %v", name, err)
+			}
+
+			// Existing records cannot tell sea level from missing, zero stays missing as before
+			if _, err := db.NewQuery(fmt.Sprintf("UPDATE %s SET has_altitude = TRUE WHERE altitude != 0", name)).Execute(); err != nil {
+				return fmt.Errorf("failed to backfill has_altitude of %s: %v", name, err)
+			}
+		}
+
+		log.Println("Successfully added has_altitude fields!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the has_altitude fields
+		dao := daos.New(db)
+
+		log.Println("Removing has_altitude fields...")
+
+		for _, name := range altitudeCollections {
+			collection, err := dao.FindCollectionByNameOrId(name)
+			if err != nil {
+				log.Printf("%s collection not found during rollback: %v", name, err)
+				continue // Don't fail rollback if collection doesn't exist
+			}
+			if field := collection.Schema.GetFieldByName("has_altitude"); field != nil {
+				collection.Schema.RemoveField(field.Id)
+			}
+			if err := dao.SaveCollection(collection); err != nil {
+				return fmt.Errorf("failed to remove has_altitude field from %s collection: %v", name, err)
+			}
+		}
+
+		log.Println("Successfully removed has_altitude fields!")
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const locationTimestampIndex = "CREATE INDEX idx_locations_user_timestamp ON locations (user, timestamp)"
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Backfilling location timestamps...")
+
+		// Locations are ordered by timestamp, points stored without one were recorded when created
+		result, err := db.NewQuery("UPDATE locations SET timestamp = created WHERE timestamp = '' OR timestamp IS NULL").Execute()
+		if err != nil {
+			return fmt.Errorf("failed to backfill location timestamps: %v", err)
+		}
+		if backfilled, err := result.RowsAffected(); err == nil && backfilled > 0 {
+			log.Printf("Backfilled the timestamp of %d locations", backfilled)
+		}
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			return fmt.Errorf("locations collection not found: %v", err)
+		}
+
+		for _, index := range collection.Indexes {
+			if index == locationTimestampIndex {
+				log.Println("Location timestamp index already exists, skipping...")
+				return nil
+			}
+		}
+		collection.Indexes = append(collection.Indexes, locationTimestampIndex)
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save locations collection with timestamp index: %v", err)
+		}
+
+		log.Println("Successfully added location timestamp index!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the index, backfilled timestamps are kept
+		dao := daos.New(db)
+
+		log.Println("Removing location timestamp index...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			log.Printf("locations collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		indexes := types.JsonArray[string]{}
+		for _, index := range collection.Indexes {
+			if index != locationTimestampIndex {
+				indexes = append(indexes, index)
+			}
+		}
+		collection.Indexes = indexes
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove timestamp index from locations collection: %v", err)
+		}
+
+		log.Println("Successfully removed location timestamp index!")
+		return nil
+	})
+}
@@ -0,0 +1,175 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const locationSessionIndex = "CREATE INDEX idx_locations_session_timestamp ON locations (session, timestamp)"
+
+// Locations stored their session twice: a name in the session text field, which some code paths
+// filled with the session ID instead, and a session_id relation. The relation becomes the only
+// session field, named session, and deleting a session deletes its locations.
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Converting the session of locations to a relation...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			return fmt.Errorf("locations collection not found: %v", err)
+		}
+
+		sessionField := collection.Schema.GetFieldByName("session")
+		if sessionField != nil && sessionField.Type == schema.FieldTypeRelation {
+			log.Println("Location session is already a relation, skipping...")
+			return nil
+		}
+		relationField := collection.Schema.GetFieldByName("session_id")
+		if relationField == nil {
+			return fmt.Errorf("locations collection has no session_id field")
+		}
+
+		if sessionField != nil {
+			if err := createMissingLocationSessions(dao); err != nil {
+				return err
+			}
+
+			// The text field holds a session name, or the session ID when set by the location service
+			result, err := db.NewQuery(`UPDATE locations SET session_id = COALESCE((
+				SELECT sessions.id FROM sessions
+				WHERE sessions.user = locations.user AND (sessions.name = locations.session OR sessions.id = locations.session)
+				LIMIT 1), '')
+				WHERE (session_id = '' OR session_id IS NULL) AND session != ''`).Execute()
+			if err != nil {
+				return fmt.Errorf("failed to link locations to their sessions: %v", err)
+			}
+			if linked, err := result.RowsAffected(); err == nil && linked > 0 {
+				log.Printf("Linked %d locations to their sessions", linked)
+			}
+
+			collection.Schema.RemoveField(sessionField.Id)
+			if err := dao.SaveCollection(collection); err != nil {
+				return fmt.Errorf("failed to remove session name field from locations: %v", err)
+			}
+		}
+
+		// Renaming keeps the field ID, so the column is renamed with its values
+		relationField.Name = "session"
+		if options, ok := relationField.Options.(*schema.RelationOptions); ok {
+			options.CascadeDelete = true
+		}
+		collection.Indexes = append(collection.Indexes, locationSessionIndex)
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save locations collection with session relation: %v", err)
+		}
+
+		log.Println("Successfully converted the session of locations to a relation!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Restore the session_id relation next to a session name field
+		dao := daos.New(db)
+
+		log.Println("Restoring location session names...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			log.Printf("locations collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		relationField := collection.Schema.GetFieldByName("session")
+		if relationField == nil || relationField.Type != schema.FieldTypeRelation {
+			log.Println("Location session is not a relation, skipping...")
+			return nil
+		}
+
+		relationField.Name = "session_id"
+		if options, ok := relationField.Options.(*schema.RelationOptions); ok {
+			options.CascadeDelete = false
+		}
+		indexes := types.JsonArray[string]{}
+		for _, index := range collection.Indexes {
+			if index != locationSessionIndex {
+				indexes = append(indexes, index)
+			}
+		}
+		collection.Indexes = indexes
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to restore session_id field of locations: %v", err)
+		}
+
+		collection.Schema.AddField(&schema.SchemaField{
+			Name:     "session",
+			Type:     schema.FieldTypeText,
+			Required: false,
+		})
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to add session name field to locations: %v", err)
+		}
+
+		_, err = db.NewQuery(`UPDATE locations SET session = COALESCE((
+			SELECT sessions.name FROM sessions WHERE sessions.id = locations.session_id), '')`).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to restore location session names: %v", err)
+		}
+
+		log.Println("Successfully restored location session names!")
+		return nil
+	})
+}
+
+// createMissingLocationSessions creates the sessions of locations that only know their session
+// by a name without a session record, so that no location loses its session
+func createMissingLocationSessions(dao *daos.Dao) error {
+	type sessionData struct {
+		User    string `db:"user"`
+		Session string `db:"session"`
+	}
+
+	var missing []sessionData
+	err := dao.DB().NewQuery(`SELECT DISTINCT user, session FROM locations
+		WHERE session != '' AND (session_id = '' OR session_id IS NULL) AND NOT EXISTS (
+			SELECT 1 FROM sessions
+			WHERE sessions.user = locations.user AND (sessions.name = locations.session OR sessions.id = locations.session))`).
+		All(&missing)
+	if err != nil {
+		return fmt.Errorf("failed to query locations without a session record: %v", err)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	log.Printf("Creating %d sessions of existing locations", len(missing))
+
+	sessionsCollection, err := dao.FindCollectionByNameOrId("sessions")
+	if err != nil {
+		return fmt.Errorf("sessions collection not found: %v", err)
+	}
+
+	for _, sessionData := range missing {
+		sessionRecord := models.NewRecord(sessionsCollection)
+		sessionRecord.Set("name", sessionData.Session)
+		sessionRecord.Set("user", sessionData.User)
+		sessionRecord.Set("public", false)
+		sessionRecord.Set("title", generateSessionTitle(sessionData.Session))
+
+		if err := dao.SaveRecord(sessionRecord); err != nil {
+			log.Printf("Warning: Failed to create session record for user %s, session %s: %v",
+				sessionData.User, sessionData.Session, err)
+		}
+	}
+
+	return nil
+}
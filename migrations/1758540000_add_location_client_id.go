@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Retried uploads carry the client ID of the point, which is stored only once per user
+const locationClientIDIndex = "CREATE UNIQUE INDEX idx_locations_user_client_id ON locations (user, client_id) WHERE client_id != ''"
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding client_id field to locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			return fmt.Errorf("locations collection not found: %v", err)
+		}
+
+		if collection.Schema.GetFieldByName("client_id") != nil {
+			log.Println("client_id field already exists in locations collection, skipping...")
+			return nil
+		}
+
+		collection.Schema.AddField(&schema.SchemaField{
+			Name:     "client_id",
+			Type:     schema.FieldTypeText,
+			Required: false,
+			Options:  &schema.TextOptions{Max: types.Pointer(100)},
+		})
+		collection.Indexes = append(collection.Indexes, locationClientIDIndex)
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save locations collection with client_id field: %v", err)
+		}
+
+		log.Println("Successfully added client_id field to locations collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the client_id field and its index
+		dao := daos.New(db)
+
+		log.Println("Removing client_id field from locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			log.Printf("locations collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		indexes := types.JsonArray[string]{}
+		for _, index := range collection.Indexes {
+			if index != locationClientIDIndex {
+				indexes = append(indexes, index)
+			}
+		}
+		collection.Indexes = indexes
+		if field := collection.Schema.GetFieldByName("client_id"); field != nil {
+			collection.Schema.RemoveField(field.Id)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove client_id field from locations collection: %v", err)
+		}
+
+		log.Println("Successfully removed client_id field from locations collection!")
+		return nil
+	})
+}
@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// locationMeasureFields are the fix measures reported by tracking apps next to the horizontal
+// accuracy and battery level that locations already store
+var locationMeasureFields = []*schema.SchemaField{
+	{
+		Name:    "vertical_accuracy", // Meters
+		Type:    schema.FieldTypeNumber,
+		Options: &schema.NumberOptions{Min: types.Pointer(0.0)},
+	},
+	{
+		Name:    "bearing", // Degrees clockwise from true north
+		Type:    schema.FieldTypeNumber,
+		Options: &schema.NumberOptions{Min: types.Pointer(0.0), Max: types.Pointer(360.0)},
+	},
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding vertical_accuracy and bearing fields to locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			return fmt.Errorf("locations collection not found: %v", err)
+		}
+
+		for _, field := range locationMeasureFields {
+			if collection.Schema.GetFieldByName(field.Name) != nil {
+				log.Printf("%s field already exists in locations collection, skipping...", field.Name)
+				continue
+			}
+			collection.Schema.AddField(&schema.SchemaField{
+				Name:    field.Name,
+				Type:    field.Type,
+				Options: field.Options,
+			})
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save locations collection with vertical_accuracy and bearing fields: %v", err)
+		}
+
+		log.Println("Successfully added vertical_accuracy and bearing fields to locations collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the vertical_accuracy and bearing fields
+		dao := daos.New(db)
+
+		log.Println("Removing vertical_accuracy and bearing fields from locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			log.Printf("locations collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		for _, field := range locationMeasureFields {
+			if existing := collection.Schema.GetFieldByName(field.Name); existing != nil {
+				collection.Schema.RemoveField(existing.Id)
+			}
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove vertical_accuracy and bearing fields from locations collection: %v", err)
+		}
+
+		log.Println("Successfully removed vertical_accuracy and bearing fields from locations collection!")
+		return nil
+	})
+}
@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// The simplified plan of a session is read by session and flag, in track order
+const gpxTracksSimplifiedIndex = "CREATE INDEX idx_gpx_tracks_simplified ON gpx_tracks (session_id, simplified, sequence)"
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding simplified field to gpx_tracks collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("gpx_tracks")
+		if err != nil {
+			return fmt.Errorf("gpx_tracks collection not found: %v", err)
+		}
+
+		if collection.Schema.GetFieldByName("simplified") != nil {
+			log.Println("simplified field already exists in gpx_tracks collection, skipping...")
+			return nil
+		}
+
+		collection.Schema.AddField(&schema.SchemaField{
+			Name:     "simplified",
+			Type:     schema.FieldTypeBool,
+			Required: false,
+		})
+		collection.Indexes = append(collection.Indexes, gpxTracksSimplifiedIndex)
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save gpx_tracks collection with simplified field: %v", err)
+		}
+
+		// Only the simplified plan was stored so far
+		if _, err := db.NewQuery("UPDATE gpx_tracks SET simplified = TRUE").Execute(); err != nil {
+			return fmt.Errorf("failed to mark stored track points simplified: %v", err)
+		}
+
+		log.Println("Successfully added simplified field to gpx_tracks collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Keep the simplified plans only and remove the simplified field and its index
+		dao := daos.New(db)
+
+		log.Println("Removing simplified field from gpx_tracks collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("gpx_tracks")
+		if err != nil {
+			log.Printf("gpx_tracks collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		if collection.Schema.GetFieldByName("simplified") != nil {
+			if _, err := db.NewQuery("DELETE FROM gpx_tracks WHERE simplified = FALSE").Execute(); err != nil {
+				return fmt.Errorf("failed to delete full-resolution track points: %v", err)
+			}
+		}
+
+		indexes := types.JsonArray[string]{}
+		for _, index := range collection.Indexes {
+			if index != gpxTracksSimplifiedIndex {
+				indexes = append(indexes, index)
+			}
+		}
+		collection.Indexes = indexes
+		if field := collection.Schema.GetFieldByName("simplified"); field != nil {
+			collection.Schema.RemoveField(field.Id)
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove simplified field from gpx_tracks collection: %v", err)
+		}
+
+		log.Println("Successfully removed simplified field from gpx_tracks collection!")
+		return nil
+	})
+}
@@ -0,0 +1,120 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// gpxTrackPointStructureFields place a planned track point in the tracks, routes and segments of
+// its GPX file. Points stored before they were told apart are all in the first segment of the
+// first track.
+var gpxTrackPointStructureFields = []*schema.SchemaField{
+	{
+		Name:    "track", // Index in the planned_tracks of the session
+		Type:    schema.FieldTypeNumber,
+		Options: &schema.NumberOptions{Min: types.Pointer(0.0), NoDecimal: true},
+	},
+	{
+		Name:    "segment", // Index of the segment within its track
+		Type:    schema.FieldTypeNumber,
+		Options: &schema.NumberOptions{Min: types.Pointer(0.0), NoDecimal: true},
+	},
+}
+
+// plannedTracksField lists the names of the tracks and routes of a session's GPX file
+var plannedTracksField = &schema.SchemaField{
+	Name: "planned_tracks",
+	Type: schema.FieldTypeJson,
+	Options: &schema.JsonOptions{
+		MaxSize: 200000, // Name, description and kind of every track and route
+	},
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding track structure fields to gpx_tracks and sessions collections...")
+
+		gpxTracks, err := dao.FindCollectionByNameOrId("gpx_tracks")
+		if err != nil {
+			return fmt.Errorf("gpx_tracks collection not found: %v", err)
+		}
+
+		for _, field := range gpxTrackPointStructureFields {
+			if gpxTracks.Schema.GetFieldByName(field.Name) != nil {
+				log.Printf("%s field already exists in gpx_tracks collection, skipping...", field.Name)
+				continue
+			}
+			gpxTracks.Schema.AddField(&schema.SchemaField{
+				Name:    field.Name,
+				Type:    field.Type,
+				Options: field.Options,
+			})
+		}
+
+		if err := dao.SaveCollection(gpxTracks); err != nil {
+			return fmt.Errorf("failed to save gpx_tracks collection with track structure fields: %v", err)
+		}
+
+		sessions, err := dao.FindCollectionByNameOrId("sessions")
+		if err != nil {
+			return fmt.Errorf("sessions collection not found: %v", err)
+		}
+
+		if sessions.Schema.GetFieldByName(plannedTracksField.Name) != nil {
+			log.Println("planned_tracks field already exists in sessions collection, skipping...")
+		} else {
+			sessions.Schema.AddField(&schema.SchemaField{
+				Name:    plannedTracksField.Name,
+				Type:    plannedTracksField.Type,
+				Options: plannedTracksField.Options,
+			})
+			if err := dao.SaveCollection(sessions); err != nil {
+				return fmt.Errorf("failed to save sessions collection with planned_tracks field: %v", err)
+			}
+		}
+
+		log.Println("Successfully added track structure fields to gpx_tracks and sessions collections!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the track structure fields
+		dao := daos.New(db)
+
+		log.Println("Removing track structure fields from gpx_tracks and sessions collections...")
+
+		if gpxTracks, err := dao.FindCollectionByNameOrId("gpx_tracks"); err == nil {
+			for _, field := range gpxTrackPointStructureFields {
+				if existing := gpxTracks.Schema.GetFieldByName(field.Name); existing != nil {
+					gpxTracks.Schema.RemoveField(existing.Id)
+				}
+			}
+			if err := dao.SaveCollection(gpxTracks); err != nil {
+				return fmt.Errorf("failed to remove track structure fields from gpx_tracks collection: %v", err)
+			}
+		} else {
+			log.Printf("gpx_tracks collection not found during rollback: %v", err)
+		}
+
+		if sessions, err := dao.FindCollectionByNameOrId("sessions"); err == nil {
+			if existing := sessions.Schema.GetFieldByName(plannedTracksField.Name); existing != nil {
+				sessions.Schema.RemoveField(existing.Id)
+				if err := dao.SaveCollection(sessions); err != nil {
+					return fmt.Errorf("failed to remove planned_tracks field from sessions collection: %v", err)
+				}
+			}
+		} else {
+			log.Printf("sessions collection not found during rollback: %v", err)
+		}
+
+		log.Println("Successfully removed track structure fields from gpx_tracks and sessions collections!")
+		return nil
+	})
+}
@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// GPX files that are not quite valid XML, e.g. cut off or UTF-16 encoded, are detected as plain
+// text; lenient uploads store them as well
+const plainTextMimeType = "text/plain"
+
+// updateGPXTrackMimeTypes adds or removes plain text from the accepted MIME types of the sessions'
+// gpx_track field
+func updateGPXTrackMimeTypes(db dbx.Builder, accept bool) error {
+	dao := daos.New(db)
+
+	collection, err := dao.FindCollectionByNameOrId("sessions")
+	if err != nil {
+		log.Printf("sessions collection not found, skipping: %v", err)
+		return nil
+	}
+
+	field := collection.Schema.GetFieldByName("gpx_track")
+	if field == nil {
+		log.Println("gpx_track field not found in sessions collection, skipping...")
+		return nil
+	}
+
+	options, ok := field.Options.(*schema.FileOptions)
+	if !ok {
+		return fmt.Errorf("gpx_track field of sessions collection is not a file field")
+	}
+	options.MimeTypes = slices.DeleteFunc(options.MimeTypes, func(mimeType string) bool {
+		return mimeType == plainTextMimeType
+	})
+	if accept {
+		options.MimeTypes = append(options.MimeTypes, plainTextMimeType)
+	}
+
+	return dao.SaveCollection(collection)
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		log.Println("Accepting plain text GPX files...")
+
+		if err := updateGPXTrackMimeTypes(db, true); err != nil {
+			return err
+		}
+
+		log.Println("Successfully updated gpx_track field!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Accept XML files only again
+		log.Println("Restoring gpx_track field MIME types...")
+
+		if err := updateGPXTrackMimeTypes(db, false); err != nil {
+			return err
+		}
+
+		log.Println("Successfully restored gpx_track field MIME types!")
+		return nil
+	})
+}
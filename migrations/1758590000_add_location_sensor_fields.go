@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// locationSensorFields are the readings of cycling computers and sports watches next to the heart
+// rate and speed that locations already store
+var locationSensorFields = []*schema.SchemaField{
+	{
+		Name:    "cadence", // Revolutions or steps per minute
+		Type:    schema.FieldTypeNumber,
+		Options: &schema.NumberOptions{Min: types.Pointer(0.0)},
+	},
+	{
+		Name:    "power", // Watts
+		Type:    schema.FieldTypeNumber,
+		Options: &schema.NumberOptions{Min: types.Pointer(0.0)},
+	},
+	{
+		Name:    "temperature", // Ambient, degrees Celsius
+		Type:    schema.FieldTypeNumber,
+		Options: &schema.NumberOptions{},
+	},
+	{
+		// Number fields store a missing temperature as 0, the flag keeps temperatures of 0 °C
+		Name:    "has_temperature",
+		Type:    schema.FieldTypeBool,
+		Options: &schema.BoolOptions{},
+	},
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding cadence, power and temperature fields to locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			return fmt.Errorf("locations collection not found: %v", err)
+		}
+
+		for _, field := range locationSensorFields {
+			if collection.Schema.GetFieldByName(field.Name) != nil {
+				log.Printf("%s field already exists in locations collection, skipping...", field.Name)
+				continue
+			}
+			collection.Schema.AddField(&schema.SchemaField{
+				Name:    field.Name,
+				Type:    field.Type,
+				Options: field.Options,
+			})
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save locations collection with sensor fields: %v", err)
+		}
+
+		log.Println("Successfully added cadence, power and temperature fields to locations collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the sensor fields
+		dao := daos.New(db)
+
+		log.Println("Removing cadence, power and temperature fields from locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			log.Printf("locations collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		for _, field := range locationSensorFields {
+			if existing := collection.Schema.GetFieldByName(field.Name); existing != nil {
+				collection.Schema.RemoveField(existing.Id)
+			}
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove sensor fields from locations collection: %v", err)
+		}
+
+		log.Println("Successfully removed cadence, power and temperature fields from locations collection!")
+		return nil
+	})
+}
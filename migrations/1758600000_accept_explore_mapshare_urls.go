@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// setMapShareURLDomains sets the domains accepted by the url field of the mapshare_feeds collection
+func setMapShareURLDomains(db dbx.Builder, domains []string) error {
+	dao := daos.New(db)
+
+	collection, err := dao.FindCollectionByNameOrId("mapshare_feeds")
+	if err != nil {
+		log.Printf("mapshare_feeds collection not found, skipping: %v", err)
+		return nil
+	}
+
+	field := collection.Schema.GetFieldByName("url")
+	if field == nil {
+		log.Println("url field not found in mapshare_feeds collection, skipping...")
+		return nil
+	}
+
+	options, ok := field.Options.(*schema.UrlOptions)
+	if !ok {
+		return fmt.Errorf("url field of mapshare_feeds collection is not a URL field")
+	}
+	options.OnlyDomains = domains
+
+	return dao.SaveCollection(collection)
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		log.Println("Accepting MapShare URLs of the Garmin Explore hosts...")
+
+		// Older MapShare links use the Explore hosts, feeds are only fetched from these
+		if err := setMapShareURLDomains(db, []string{"share.garmin.com", "explore.garmin.com", "eur.explore.garmin.com"}); err != nil {
+			return err
+		}
+
+		log.Println("Successfully updated mapshare_feeds url field!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Accept share.garmin.com only again
+		log.Println("Restoring mapshare_feeds url field domains...")
+
+		if err := setMapShareURLDomains(db, []string{"share.garmin.com"}); err != nil {
+			return err
+		}
+
+		log.Println("Successfully restored mapshare_feeds url field domains!")
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// locationMotionFlags tell a reported standstill or heading due north from a missing speed or
+// bearing; number fields store both as 0
+var locationMotionFlags = []string{"has_speed", "has_bearing"}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Adding has_speed and has_bearing fields to locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			return fmt.Errorf("locations collection not found: %v", err)
+		}
+
+		for _, name := range locationMotionFlags {
+			if collection.Schema.GetFieldByName(name) != nil {
+				log.Printf("%s field already exists in locations collection, skipping...", name)
+				continue
+			}
+			collection.Schema.AddField(&schema.SchemaField{
+				Name:    name,
+				Type:    schema.FieldTypeBool,
+				Options: &schema.BoolOptions{},
+			})
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to save locations collection with motion flags: %v", err)
+		}
+
+		// Until now a speed or bearing of 0 meant none was reported
+		if _, err := db.NewQuery("UPDATE locations SET has_speed = (speed > 0), has_bearing = (bearing > 0)").Execute(); err != nil {
+			return fmt.Errorf("failed to set motion flags of existing locations: %v", err)
+		}
+
+		log.Println("Successfully added has_speed and has_bearing fields to locations collection!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the motion flags
+		dao := daos.New(db)
+
+		log.Println("Removing has_speed and has_bearing fields from locations collection...")
+
+		collection, err := dao.FindCollectionByNameOrId("locations")
+		if err != nil {
+			log.Printf("locations collection not found during rollback: %v", err)
+			return nil // Don't fail rollback if collection doesn't exist
+		}
+
+		for _, name := range locationMotionFlags {
+			if existing := collection.Schema.GetFieldByName(name); existing != nil {
+				collection.Schema.RemoveField(existing.Id)
+			}
+		}
+
+		if err := dao.SaveCollection(collection); err != nil {
+			return fmt.Errorf("failed to remove motion flags from locations collection: %v", err)
+		}
+
+		log.Println("Successfully removed has_speed and has_bearing fields from locations collection!")
+		return nil
+	})
+}
@@ -0,0 +1,100 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// readonlyRoleGuard keeps users with the readonly role from writing through the collection API,
+// which does not go through the permission checks of the custom routes
+const readonlyRoleGuard = `@request.auth.role != "readonly"`
+
+// readonlyRoleGuardSuffix is the guard as appended to a non-empty rule
+const readonlyRoleGuardSuffix = ") && " + readonlyRoleGuard
+
+// readonlyGuardedCollections are the collections whose write rules the guard is added to
+var readonlyGuardedCollections = []string{"sessions", "waypoints"}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+
+		log.Println("Restricting session and waypoint writes of readonly users...")
+
+		for _, name := range readonlyGuardedCollections {
+			collection, err := dao.FindCollectionByNameOrId(name)
+			if err != nil {
+				return fmt.Errorf("%s collection not found: %v", name, err)
+			}
+
+			collection.CreateRule = guardReadonlyRule(collection.CreateRule)
+			collection.UpdateRule = guardReadonlyRule(collection.UpdateRule)
+			collection.DeleteRule = guardReadonlyRule(collection.DeleteRule)
+
+			if err := dao.SaveCollection(collection); err != nil {
+				return fmt.Errorf("failed to save %s collection with readonly guard: %v", name, err)
+			}
+		}
+
+		log.Println("Successfully restricted session and waypoint writes of readonly users!")
+		return nil
+
+	}, func(db dbx.Builder) error {
+		// Rollback: Remove the readonly guard from the write rules
+		dao := daos.New(db)
+
+		log.Println("Removing readonly guard from session and waypoint rules...")
+
+		for _, name := range readonlyGuardedCollections {
+			collection, err := dao.FindCollectionByNameOrId(name)
+			if err != nil {
+				log.Printf("%s collection not found during rollback: %v", name, err)
+				continue // Don't fail rollback if collection doesn't exist
+			}
+
+			collection.CreateRule = unguardReadonlyRule(collection.CreateRule)
+			collection.UpdateRule = unguardReadonlyRule(collection.UpdateRule)
+			collection.DeleteRule = unguardReadonlyRule(collection.DeleteRule)
+
+			if err := dao.SaveCollection(collection); err != nil {
+				return fmt.Errorf("failed to remove readonly guard from %s collection: %v", name, err)
+			}
+		}
+
+		log.Println("Successfully removed readonly guard from session and waypoint rules!")
+		return nil
+	})
+}
+
+// guardReadonlyRule extends an API rule so that requests of readonly users are rejected; admin-only
+// (nil) rules and rules that are already guarded are left as they are
+func guardReadonlyRule(rule *string) *string {
+	if rule == nil || *rule == readonlyRoleGuard || strings.HasSuffix(*rule, readonlyRoleGuardSuffix) {
+		return rule
+	}
+	if *rule == "" {
+		return types.Pointer(readonlyRoleGuard)
+	}
+	return types.Pointer("(" + *rule + readonlyRoleGuardSuffix)
+}
+
+// unguardReadonlyRule reverts guardReadonlyRule
+func unguardReadonlyRule(rule *string) *string {
+	if rule == nil {
+		return nil
+	}
+	switch {
+	case *rule == readonlyRoleGuard:
+		return types.Pointer("")
+	case strings.HasPrefix(*rule, "(") && strings.HasSuffix(*rule, readonlyRoleGuardSuffix):
+		return types.Pointer(strings.TrimSuffix(strings.TrimPrefix(*rule, "("), readonlyRoleGuardSuffix))
+	default:
+		return rule
+	}
+}
@@ -1,11 +1,22 @@
 package models
 
+import (
+	"mime/multipart"
+	"time"
+)
+
 // LoginRequest represents the request body for login
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6,max=128"`
 }
 
+// UploadAvatarRequest represents the multipart form of an avatar upload. SVG is not accepted, avatars
+// are decoded and re-encoded as PNG.
+type UploadAvatarRequest struct {
+	Avatar *multipart.FileHeader `form:"avatar" file:"required,max_size=5MB,types=image/jpeg image/png image/gif image/webp"`
+}
+
 // UpdateProfileRequest represents the request body for updating user profile
 type UpdateProfileRequest struct {
 	Username             string `json:"username,omitempty" validate:"omitempty,username,min=3,max=50"`
@@ -15,6 +26,62 @@ type UpdateProfileRequest struct {
 	DefaultSessionPublic *bool  `json:"default_session_public,omitempty"`
 }
 
+// NotificationPreferences selects which tracking notifications a user receives
+type NotificationPreferences struct {
+	Email           bool `json:"email"`
+	Push            bool `json:"push"`
+	WaypointVisited bool `json:"waypoint_visited"`
+	LowBattery      bool `json:"low_battery"`
+}
+
+// TrackingDefaults represents a user's default tracking settings
+type TrackingDefaults struct {
+	DefaultSessionPublic bool                    `json:"default_session_public"`
+	AutoSessionGap       int                     `json:"auto_session_gap"` // Minutes without points after which a new automatic session starts; 0 disables automatic sessions
+	PointInterval        int                     `json:"point_interval"`   // Suggested seconds between points for tracking clients
+	SpeedUnit            string                  `json:"speed_unit"`       // Unit tracking clients report speeds in unless a point names its own
+	LocationPolicy       string                  `json:"location_policy"`  // How the current position is chosen among recent fixes: newest, most_accurate or per_device
+	AccuracyWindow       int                     `json:"accuracy_window"`  // Seconds before the newest fix that accuracy-based policies consider
+	Timezone             string                  `json:"timezone"`         // IANA time zone the daily timeline is split into days in
+	PrivacyZonesEnabled  bool                    `json:"privacy_zones_enabled"`
+	PublicBadge          bool                    `json:"public_badge"` // Serves the status badge at /api/users/{username}/badge.svg
+	Notifications        NotificationPreferences `json:"notifications"`
+	Downsampling         DownsamplingPolicy      `json:"downsampling"` // Applies to sessions without their own policy
+}
+
+// DownsamplingPolicy limits how densely tracked points are stored: a point is discarded at
+// ingestion when it follows the last stored point of its session too soon or too close. Zero
+// disables a limit.
+type DownsamplingPolicy struct {
+	MinInterval int     `json:"min_interval" validate:"min=0,max=3600"` // Seconds
+	MinDistance float64 `json:"min_distance" validate:"min=0,max=1000"` // Meters
+}
+
+// Enabled reports whether the policy discards any points
+func (p DownsamplingPolicy) Enabled() bool {
+	return p.MinInterval > 0 || p.MinDistance > 0
+}
+
+// UpdateTrackingDefaultsRequest represents the request body for updating tracking defaults; omitted fields are left unchanged
+type UpdateTrackingDefaultsRequest struct {
+	DefaultSessionPublic *bool                    `json:"default_session_public,omitempty"`
+	AutoSessionGap       *int                     `json:"auto_session_gap,omitempty" validate:"omitempty,min=0,max=10080"`
+	PointInterval        *int                     `json:"point_interval,omitempty" validate:"omitempty,min=1,max=3600"`
+	SpeedUnit            *string                  `json:"speed_unit,omitempty" validate:"omitempty,oneof=m/s km/h mph knots"`
+	LocationPolicy       *string                  `json:"location_policy,omitempty" validate:"omitempty,oneof=newest most_accurate per_device"`
+	AccuracyWindow       *int                     `json:"accuracy_window,omitempty" validate:"omitempty,min=1,max=3600"`
+	Timezone             *string                  `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	PrivacyZonesEnabled  *bool                    `json:"privacy_zones_enabled,omitempty"`
+	PublicBadge          *bool                    `json:"public_badge,omitempty"`
+	Notifications        *NotificationPreferences `json:"notifications,omitempty"`
+	Downsampling         *DownsamplingPolicy      `json:"downsampling,omitempty"`
+}
+
+// UpdateUserRoleRequest represents the request body for changing a user's access control role
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin moderator user readonly"`
+}
+
 // LoginResponse represents the response for successful login
 type LoginResponse struct {
 	Token string `json:"token"`
@@ -28,6 +95,7 @@ type User struct {
 	Email                string `json:"email"`
 	Avatar               string `json:"avatar,omitempty"`
 	DefaultSessionPublic bool   `json:"default_session_public"`
+	Role                 string `json:"role"`
 	Created              string `json:"created,omitempty"`
 	Updated              string `json:"updated,omitempty"`
 }
@@ -41,3 +109,40 @@ type TokenResponse struct {
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" validate:"required"`
 }
+
+// ReauthRequest represents a password confirmation for sensitive actions
+type ReauthRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// CaptchaChallenge tells clients which CAPTCHA to solve before registration, password reset or
+// repeated login attempts; the solution is sent in the X-Captcha-Token header
+type CaptchaChallenge struct {
+	Provider   string     `json:"provider"` // hcaptcha, turnstile or pow; empty when no CAPTCHA is configured
+	SiteKey    string     `json:"site_key,omitempty"`
+	Challenge  string     `json:"challenge,omitempty"`  // Proof-of-work challenge, solved as "<challenge>:<nonce>"
+	Difficulty int        `json:"difficulty,omitempty"` // Leading zero bits of SHA-256("<challenge>:<nonce>")
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// ClientInfo describes the client a token is issued to
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// DeviceSession represents a login of a user on one device; each issued JWT belongs to one device session
+type DeviceSession struct {
+	ID         string     `json:"id"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	Country    string     `json:"country,omitempty"`    // ISO country code from geo-IP lookup
+	ASN        int        `json:"asn,omitempty"`        // Autonomous system number from geo-IP lookup
+	Suspicious []string   `json:"suspicious,omitempty"` // Reasons the login was flagged, e.g. new_country
+	Current    bool       `json:"current"`              // Session of the requesting token
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Created    time.Time  `json:"created"`
+}
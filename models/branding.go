@@ -0,0 +1,42 @@
+package models
+
+// BrandingResponse describes an instance, so the frontend and third-party clients can configure
+// themselves for it
+type BrandingResponse struct {
+	Name     string           `json:"name"`
+	LogoURL  string           `json:"logo_url"`
+	Map      MapDefaults      `json:"map"`
+	Features InstanceFeatures `json:"features"`
+}
+
+// MapDefaults is the initial map view and tile layer of an instance
+type MapDefaults struct {
+	Center [2]float64   `json:"center"` // [latitude, longitude]
+	Zoom   int          `json:"zoom"`
+	Tiles  TileProvider `json:"tiles"`
+}
+
+// TileProvider is a raster tile layer as a Leaflet URL template
+type TileProvider struct {
+	URL         string `json:"url"`
+	Attribution string `json:"attribution"` // HTML
+	MaxZoom     int    `json:"max_zoom"`
+}
+
+// InstanceFeatures lists which optional features are available on an instance
+type InstanceFeatures struct {
+	ReadOnly         bool `json:"read_only"` // Writes are rejected during maintenance
+	GraphQL          bool `json:"graphql"`
+	RoutePlanning    bool `json:"route_planning"`
+	Elevation        bool `json:"elevation"`
+	Weather          bool `json:"weather"`
+	MapMatching      bool `json:"map_matching"`
+	CountryStats     bool `json:"country_stats"`
+	WaypointCheckOff bool `json:"waypoint_check_off"`
+	LowBatteryAlerts bool `json:"low_battery_alerts"`
+	GRPCIngest       bool `json:"grpc_ingest"`
+	HardwareTrackers bool `json:"hardware_trackers"`
+	APRS             bool `json:"aprs"`
+	MapShare         bool `json:"mapshare"`
+	HomeAssistant    bool `json:"home_assistant"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // ErrorResponse represents a standardized error response
 type ErrorResponse struct {
 	Code    int    `json:"code"`
@@ -27,3 +29,10 @@ type PaginatedResponse struct {
 	Data       interface{}    `json:"data"`
 	Pagination PaginationMeta `json:"pagination"`
 }
+
+// WebhookEvent is the JSON body of outgoing webhook requests
+type WebhookEvent struct {
+	Event     string    `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// PublishCommunityWaypointRequest represents the request body for publishing a waypoint to the community layer
+type PublishCommunityWaypointRequest struct {
+	WaypointID string `json:"waypoint_id" validate:"required"`
+}
+
+// FlagCommunityWaypointRequest represents the request body for flagging a community waypoint for moderation
+type FlagCommunityWaypointRequest struct {
+	Reason  string `json:"reason" validate:"required,oneof=incorrect unavailable duplicate inappropriate other"`
+	Comment string `json:"comment,omitempty" validate:"omitempty,max=500"`
+}
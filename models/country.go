@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// CountryVisit is one stay in a country, from the first to the last point before leaving it
+type CountryVisit struct {
+	EntryAt time.Time `json:"entry_at"`
+	ExitAt  time.Time `json:"exit_at"`
+}
+
+// RegionStats describes a visited region of a country, such as a state or province
+type RegionStats struct {
+	Name       string    `json:"name"`
+	FirstVisit time.Time `json:"first_visit"`
+	LastVisit  time.Time `json:"last_visit"`
+}
+
+// CountryStats describes a country visited according to the location history
+type CountryStats struct {
+	CountryCode string         `json:"country_code"` // ISO 3166-1 alpha-2
+	Country     string         `json:"country"`
+	FirstVisit  time.Time      `json:"first_visit"`
+	LastVisit   time.Time      `json:"last_visit"`
+	Days        int            `json:"days"` // Distinct UTC days with points in the country
+	Visits      []CountryVisit `json:"visits"`
+	Regions     []RegionStats  `json:"regions"`
+}
+
+// CountriesResponse represents the countries and regions a user visited
+type CountriesResponse struct {
+	CountryCount     int            `json:"country_count"`
+	RegionCount      int            `json:"region_count"`
+	PendingLocations int            `json:"pending_locations"` // Locations not geocoded yet, the statistics do not include them
+	Countries        []CountryStats `json:"countries"`
+}
+
+// CountryFeatureProperties represents the properties of a visited country in the scratch map layer
+type CountryFeatureProperties struct {
+	CountryCode string    `json:"country_code"`
+	Country     string    `json:"country"`
+	FirstVisit  time.Time `json:"first_visit"`
+	LastVisit   time.Time `json:"last_visit"`
+	Days        int       `json:"days"`
+	VisitCount  int       `json:"visit_count"`
+}
+
+// CountryFeature is a visited country as a GeoJSON feature. The point is the center of the user's
+// locations in the country and the bbox their extent; clients shade the country boundary matching
+// country_code.
+type CountryFeature struct {
+	Type       string                   `json:"type"`
+	BBox       []float64                `json:"bbox"`
+	Geometry   Geometry                 `json:"geometry"`
+	Properties CountryFeatureProperties `json:"properties"`
+}
+
+// CountryFeatureCollection represents the scratch map layer of visited countries
+type CountryFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []CountryFeature `json:"features"`
+}
@@ -0,0 +1,67 @@
+package models
+
+// ClientConfigDocument is served at /.well-known/vibe-tracker.json so clients can set up from the
+// server URL alone
+type ClientConfigDocument struct {
+	ConfigVersion int                `json:"config_version"`
+	Name          string             `json:"name"`
+	Version       string             `json:"version"`
+	APIBase       string             `json:"api_base"`
+	Branding      string             `json:"branding"`     // URL of the branding and feature document
+	Capabilities  string             `json:"capabilities"` // URL of the version, modules and limits
+	ReadOnly      bool               `json:"read_only"`
+	Protocols     []TrackingProtocol `json:"protocols"`
+	Auth          []AuthMethod       `json:"auth"`
+}
+
+// TrackingProtocol describes a way of sending tracked points to the server
+type TrackingProtocol struct {
+	ID           string   `json:"id"`
+	Method       string   `json:"method,omitempty"`
+	URL          string   `json:"url,omitempty"`
+	ContentTypes []string `json:"content_types,omitempty"`
+	Address      string   `json:"address,omitempty"` // host:port of non-HTTP protocols
+	Service      string   `json:"service,omitempty"`
+	TLS          bool     `json:"tls,omitempty"`
+	Auth         []string `json:"auth"` // IDs of the accepted authentication methods
+}
+
+// AuthMethod describes how clients authenticate
+type AuthMethod struct {
+	ID         string `json:"id"`
+	LoginURL   string `json:"login_url,omitempty"`
+	RefreshURL string `json:"refresh_url,omitempty"`
+	QueryParam string `json:"query_param,omitempty"`
+	Header     string `json:"header,omitempty"`
+}
+
+// CapabilitiesResponse lists the server version, enabled modules and limits, so clients such as
+// uploaders can adapt instead of assuming them
+type CapabilitiesResponse struct {
+	Version       string           `json:"version"`
+	APIVersion    int              `json:"api_version"`
+	SchemaVersion string           `json:"schema_version"` // Newest database migration
+	Modules       InstanceFeatures `json:"modules"`
+	Limits        CapabilityLimits `json:"limits"`
+}
+
+// CapabilityLimits are the request limits enforced by the server
+type CapabilityLimits struct {
+	MaxRequestSize  int64                  `json:"max_request_size"`  // Bytes
+	MaxPageSize     int                    `json:"max_page_size"`     // Largest per_page of paginated lists
+	MaxTimestampAge int64                  `json:"max_timestamp_age"` // Seconds; older points need allow_historical
+	Uploads         map[string]UploadLimit `json:"uploads"`           // Keyed by upload form
+	RateLimits      map[string]RateLimit   `json:"rate_limits"`       // Keyed by endpoint group, null when rate limiting is disabled
+}
+
+// UploadLimit is the accepted size and content types of an uploaded file
+type UploadLimit struct {
+	MaxSize int64    `json:"max_size"` // Bytes
+	Types   []string `json:"types"`
+}
+
+// RateLimit is the token bucket applied per client to an endpoint group
+type RateLimit struct {
+	RequestsPerMinute int `json:"requests_per_minute"`
+	Burst             int `json:"burst"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// EmergencyContactRequest represents the request body for adding or updating an emergency contact
+type EmergencyContactRequest struct {
+	Name    string `json:"name" validate:"required,min=1,max=100"`
+	Email   string `json:"email" validate:"required,email"`
+	Phone   string `json:"phone,omitempty" validate:"required_if=Channel sms,omitempty,e164"`
+	Channel string `json:"channel" validate:"required,oneof=email sms"`
+}
+
+// EmergencyContact represents a person alerted when one of the user's sessions needs help. Contacts
+// are only alerted once they confirmed their email address.
+type EmergencyContact struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email"`
+	Phone      string     `json:"phone,omitempty"`
+	Channel    string     `json:"channel"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	Created    time.Time  `json:"created"`
+}
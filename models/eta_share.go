@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// CreateETAShareRequest represents the request body for sharing the live position and ETA to a destination
+type CreateETAShareRequest struct {
+	Latitude        float64 `json:"latitude" validate:"latitude"`
+	Longitude       float64 `json:"longitude" validate:"longitude"`
+	Name            string  `json:"name,omitempty" validate:"omitempty,max=200"`                   // Destination name shown to viewers
+	DurationMinutes int     `json:"duration_minutes" validate:"required,min=5,max=1440"`           // The share expires after this time at the latest
+	ArrivalRadius   float64 `json:"arrival_radius,omitempty" validate:"omitempty,min=10,max=5000"` // Meters, default 100
+}
+
+// ETADestination is the place an ETA share estimates the arrival at
+type ETADestination struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+}
+
+// ETAShare represents a temporary link sharing the owner's live position and ETA to a destination
+type ETAShare struct {
+	ID            string         `json:"id"`
+	Token         string         `json:"token"`
+	URL           string         `json:"url"`
+	Destination   ETADestination `json:"destination"`
+	ArrivalRadius float64        `json:"arrival_radius"`
+	Status        string         `json:"status"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+	ArrivedAt     *time.Time     `json:"arrived_at,omitempty"`
+	Created       time.Time      `json:"created"`
+}
+
+// ETAPosition is the latest position exposed by an active ETA share
+type ETAPosition struct {
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ETAShareView is what viewers of an ETA share link see. Position and estimate are only included
+// while the share is active.
+type ETAShareView struct {
+	Username    string         `json:"username"`
+	Destination ETADestination `json:"destination"`
+	Status      string         `json:"status"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+	ArrivedAt   *time.Time     `json:"arrived_at,omitempty"`
+	Position    *ETAPosition   `json:"position,omitempty"`
+	Distance    *float64       `json:"distance,omitempty"`    // Meters in a straight line
+	Speed       *float64       `json:"speed,omitempty"`       // Meters per second over the recent movement
+	ETASeconds  *int           `json:"eta_seconds,omitempty"` // Travel time from the latest position
+	ETA         *time.Time     `json:"eta,omitempty"`
+}
@@ -0,0 +1,50 @@
+package models
+
+// ExportQueryParams represents the query parameters of a session export
+type ExportQueryParams struct {
+	Format string `query:"format,omitempty" validate:"omitempty,oneof=gpx geojson"`
+}
+
+// TrackMeasures holds the recorded measures of a track segment as arrays aligned with its
+// coordinates, null where a point did not record the measure. Measures no point recorded
+// are left out.
+type TrackMeasures struct {
+	Time        []string   `json:"time"` // RFC 3339
+	Speed       []*float64 `json:"speed,omitempty"`
+	HeartRate   []*float64 `json:"heart_rate,omitempty"`
+	Cadence     []*float64 `json:"cadence,omitempty"`
+	Power       []*float64 `json:"power,omitempty"`       // Watts
+	Temperature []*float64 `json:"temperature,omitempty"` // °C
+	Accuracy    []*float64 `json:"accuracy,omitempty"`    // Meters
+}
+
+// TrackExportGeometry is the geometry of an exported track segment, a LineString or a Point
+// for segments of a single location
+type TrackExportGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"` // [lon, lat, altitude] positions, altitude only when known
+}
+
+// TrackExportProperties describes an exported track segment
+type TrackExportProperties struct {
+	Session   string `json:"session"`
+	Title     string `json:"title"`
+	Segment   int    `json:"segment"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// TrackExportFeature is a segment of an exported session track. The measures are a GeoJSON
+// foreign member, so they survive tools that rewrite feature properties.
+type TrackExportFeature struct {
+	Type       string                `json:"type"` // Feature
+	Geometry   TrackExportGeometry   `json:"geometry"`
+	Properties TrackExportProperties `json:"properties"`
+	Measures   TrackMeasures         `json:"measures"`
+}
+
+// TrackExportCollection is an exported session track, one feature per segment
+type TrackExportCollection struct {
+	Type     string               `json:"type"` // FeatureCollection
+	Features []TrackExportFeature `json:"features"`
+}
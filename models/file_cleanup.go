@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// OrphanedFile is a stored file that no record refers to anymore
+type OrphanedFile struct {
+	Key      string    `json:"key"`  // Storage key, relative to the storage root or S3 bucket
+	Size     int64     `json:"size"` // Bytes
+	Modified time.Time `json:"modified"`
+	Reason   string    `json:"reason"` // collection_deleted, record_deleted or unreferenced
+}
+
+// OrphanedFilesReport is the result of a scan of the file storage for orphaned files
+type OrphanedFilesReport struct {
+	ScannedAt    time.Time      `json:"scanned_at"`
+	ScannedFiles int            `json:"scanned_files"`
+	Orphans      []OrphanedFile `json:"orphans"`
+	OrphanedSize int64          `json:"orphaned_size"` // Bytes
+	Deleted      int            `json:"deleted"`       // Orphans deleted by the scan
+}
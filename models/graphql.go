@@ -0,0 +1,16 @@
+package models
+
+// GraphQLRequest represents a GraphQL query sent as JSON
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+}
+
+// GraphQLResponse represents the result of a GraphQL query
+type GraphQLResponse struct {
+	Data   map[string]any `json:"data,omitempty"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors,omitempty"`
+}
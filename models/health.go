@@ -49,22 +49,58 @@ type ResourceHealth struct {
 	DiskSpace       string `json:"disk_space,omitempty"`
 }
 
+// MigrationHealth represents the state of database migrations and data backfills
+type MigrationHealth struct {
+	Status    HealthStatus `json:"status"`
+	Version   string       `json:"version"` // Latest applied migration
+	Applied   int          `json:"applied"`
+	Pending   int          `json:"pending"`
+	Backfills []string     `json:"backfills,omitempty"` // Data backfills still running
+	Error     string       `json:"error,omitempty"`
+}
+
+// IngestQueueStats represents the state of the write batching queue; the counters are since the start
+type IngestQueueStats struct {
+	Mode             string `json:"mode"`
+	Workers          int    `json:"workers"`
+	Capacity         int    `json:"capacity"` // Points queued at most
+	Queued           int    `json:"queued"`   // Points accepted but not written yet
+	Accepted         int64  `json:"accepted"`
+	Rejected         int64  `json:"rejected"` // Points refused because the queue was full
+	Written          int64  `json:"written"`
+	Failed           int64  `json:"failed"`
+	Batches          int64  `json:"batches"`
+	LastBatchSize    int    `json:"last_batch_size"`
+	LastBatchLatency string `json:"last_batch_latency"` // From queuing to commit of its oldest point
+}
+
 // DetailedHealthResponse represents the comprehensive health check response
 type DetailedHealthResponse struct {
-	Status    HealthStatus                `json:"status"`
-	Version   string                      `json:"version"`
-	Uptime    string                      `json:"uptime"`
-	Timestamp time.Time                   `json:"timestamp"`
-	Checks    map[string]*ComponentHealth `json:"checks"`
-	Services  *ServiceHealth              `json:"services,omitempty"`
-	Resources *ResourceHealth             `json:"resources,omitempty"`
+	Status     HealthStatus                `json:"status"`
+	Version    string                      `json:"version"`
+	Uptime     string                      `json:"uptime"`
+	Timestamp  time.Time                   `json:"timestamp"`
+	Checks     map[string]*ComponentHealth `json:"checks"`
+	Services   *ServiceHealth              `json:"services,omitempty"`
+	Resources  *ResourceHealth             `json:"resources,omitempty"`
+	Migrations *MigrationHealth            `json:"migrations,omitempty"`
+	Ingest     *IngestQueueStats           `json:"ingest,omitempty"` // Only when write batching is on
 }
 
 // SystemHealth aggregates all health information
 type SystemHealth struct {
-	Overall   HealthStatus
-	Database  *ComponentHealth
-	Services  *ServiceHealth
-	Resources *ResourceHealth
-	StartTime time.Time
+	Overall    HealthStatus
+	Database   *ComponentHealth
+	Services   *ServiceHealth
+	Resources  *ResourceHealth
+	Migrations *MigrationHealth
+	StartTime  time.Time
+}
+
+// LatencyBudgetEvent is the webhook payload sent when a route stays over its latency budget
+type LatencyBudgetEvent struct {
+	Route        string    `json:"route"` // Method and registered path, e.g. "GET /api/session/:username/:session"
+	P95Millis    int64     `json:"p95_ms"`
+	BudgetMillis int64     `json:"budget_ms"`
+	OverSince    time.Time `json:"over_since"`
 }
@@ -0,0 +1,20 @@
+package models
+
+// HomeAssistantLocation is the data of a Home Assistant mobile_app "update_location" webhook
+type HomeAssistantLocation struct {
+	GPS              []float64 `json:"gps" validate:"required,len=2,dive,finite"` // [latitude, longitude]
+	GPSAccuracy      *float64  `json:"gps_accuracy,omitempty" validate:"omitempty,finite,gte=0"`
+	Battery          *float64  `json:"battery,omitempty" validate:"omitempty,finite,gte=0,lte=100"`
+	Speed            *float64  `json:"speed,omitempty" validate:"omitempty,finite"` // m/s, negative when unknown
+	Altitude         *float64  `json:"altitude,omitempty" validate:"omitempty,finite"`
+	Course           *float64  `json:"course,omitempty" validate:"omitempty,finite"`
+	VerticalAccuracy *float64  `json:"vertical_accuracy,omitempty" validate:"omitempty,finite"`
+	LocationName     string    `json:"location_name,omitempty" validate:"omitempty,max=100"` // Zone the device is in, tracked as the point status
+}
+
+// HomeAssistantWebhookRequest is a location update in the format of Home Assistant's mobile_app
+// device_tracker webhooks, so automations and companion apps can report to the tracker unchanged
+type HomeAssistantWebhookRequest struct {
+	Type string                `json:"type,omitempty" validate:"omitempty,eq=update_location"`
+	Data HomeAssistantLocation `json:"data" validate:"required"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// IngestSourceRequest represents the request body for adding or updating a webhook ingest source
+type IngestSourceRequest struct {
+	Name    string            `json:"name" validate:"required,min=1,max=100"`
+	Session string            `json:"session,omitempty" validate:"omitempty,session_name,max=100"` // Default session of the points
+	Mapping map[string]string `json:"mapping" validate:"required"`                                 // Point field to JSONPath-like path or constant
+}
+
+// IngestSource represents a third-party service posting locations to its webhook URL. The body
+// of each webhook is turned into a point through the field mapping.
+type IngestSource struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Session        string            `json:"session,omitempty"`
+	Mapping        map[string]string `json:"mapping"`
+	WebhookURL     string            `json:"webhook_url"`
+	Secret         string            `json:"secret"` // Sent in the X-Webhook-Secret header or the secret query parameter
+	LastReceivedAt *time.Time        `json:"last_received_at,omitempty"`
+	Created        time.Time         `json:"created"`
+}
@@ -8,19 +8,33 @@ type Coordinates []float64
 // Geometry represents GeoJSON geometry
 type Geometry struct {
 	Type        string      `json:"type" validate:"required,oneof=Point"`
-	Coordinates Coordinates `json:"coordinates" validate:"required,min=2,max=3"`
+	Coordinates Coordinates `json:"coordinates" validate:"required,min=2,max=3,dive,finite"`
 }
 
 // LocationProperties represents properties of a location point
 type LocationProperties struct {
 	Timestamp int64    `json:"timestamp" validate:"required,gte=0"`
-	Speed     *float64 `json:"speed,omitempty" validate:"omitempty,gte=0"`
-	HeartRate *float64 `json:"heart_rate,omitempty" validate:"omitempty,gte=0,lte=300"`
+	Speed     *float64 `json:"speed,omitempty" validate:"omitempty,finite,gte=0"`
+	SpeedUnit string   `json:"speed_unit,omitempty" validate:"omitempty,oneof=m/s km/h mph knots"` // Overrides the user's default speed unit
+	Accuracy  *float64 `json:"accuracy,omitempty" validate:"omitempty,finite,gte=0"`               // Horizontal accuracy radius in meters
+	Device    string   `json:"device,omitempty" validate:"omitempty,max=100"`                      // Identifies the reporting device when a user tracks with several
+	Battery   *float64 `json:"battery,omitempty" validate:"omitempty,finite,gte=0,lte=100"`        // Tracker battery level in percent
+
+	VerticalAccuracy *float64 `json:"vertical_accuracy,omitempty" validate:"omitempty,finite,gte=0"` // Altitude accuracy in meters
+	Bearing          *float64 `json:"bearing,omitempty" validate:"omitempty,finite,gte=0,lte=360"`   // Direction of travel in degrees clockwise from true north
+
+	// Readings of cycling computers and sports watches
+	Cadence     *float64 `json:"cadence,omitempty" validate:"omitempty,finite,gte=0,lte=300"`        // Revolutions or steps per minute
+	Power       *float64 `json:"power,omitempty" validate:"omitempty,finite,gte=0,lte=5000"`         // Watts
+	Temperature *float64 `json:"temperature,omitempty" validate:"omitempty,finite,gte=-100,lte=100"` // Ambient, degrees Celsius
+
+	HeartRate *float64 `json:"heart_rate,omitempty" validate:"omitempty,finite,gte=0,lte=300"`
 	Session   string   `json:"session,omitempty" validate:"omitempty,session_name,max=100"`
 	Username  string   `json:"username,omitempty"`
 	Title     string   `json:"session_title,omitempty"`
 	Status    string   `json:"status,omitempty" validate:"omitempty,max=100"`
 	Event     string   `json:"event,omitempty" validate:"omitempty,max=100"`
+	ClientID  string   `json:"client_id,omitempty" validate:"omitempty,max=100"` // Unique per point of the user; a point with a known ID is not stored again
 }
 
 // LocationRequest represents a GeoJSON feature for tracking location
@@ -43,29 +57,108 @@ type LocationsResponse struct {
 	Features []LocationResponse `json:"features"`
 }
 
-// TrackingQueryParams represents query parameters for GET tracking requests
+// TrackingQueryParams represents query parameters for GET tracking requests. The JSON points
+// streamed over the tracking WebSocket have the same fields; their token is the connection's.
 type TrackingQueryParams struct {
-	Token     string   `query:"token" validate:"required,min=1"`
-	Latitude  float64  `query:"latitude" validate:"required,latitude"`
-	Longitude float64  `query:"longitude" validate:"required,longitude"`
-	Timestamp int64    `query:"timestamp,omitempty" validate:"omitempty,gte=0"`
-	Altitude  *float64 `query:"altitude,omitempty" validate:"omitempty,gte=0"`
-	Speed     *float64 `query:"speed,omitempty" validate:"omitempty,gte=0"`
-	HeartRate *float64 `query:"heart_rate,omitempty" validate:"omitempty,gte=0,lte=300"`
+	Token     string   `query:"token" json:"-" validate:"required,min=1"`
+	Latitude  float64  `query:"latitude" json:"latitude,omitempty" validate:"required,latitude"`
+	Longitude float64  `query:"longitude" json:"longitude,omitempty" validate:"required,longitude"`
+	Timestamp int64    `query:"timestamp,omitempty" json:"timestamp,omitempty" validate:"omitempty,gte=0"`
+	Altitude  *float64 `query:"altitude,omitempty" json:"altitude,omitempty" validate:"omitempty,finite"`
+	Speed     *float64 `query:"speed,omitempty" json:"speed,omitempty" validate:"omitempty,finite,gte=0"`
+	SpeedUnit string   `query:"speed_unit,omitempty" json:"speed_unit,omitempty" validate:"omitempty,oneof=m/s km/h mph knots"`
+	Accuracy  *float64 `query:"accuracy,omitempty" json:"accuracy,omitempty" validate:"omitempty,finite,gte=0"`
+	Device    string   `query:"device,omitempty" json:"device,omitempty" validate:"omitempty,max=100"`
+	Battery   *float64 `query:"battery,omitempty" json:"battery,omitempty" validate:"omitempty,finite,gte=0,lte=100"`
+
+	VerticalAccuracy *float64 `query:"vertical_accuracy,omitempty" json:"vertical_accuracy,omitempty" validate:"omitempty,finite,gte=0"`
+	Bearing          *float64 `query:"bearing,omitempty" json:"bearing,omitempty" validate:"omitempty,finite,gte=0,lte=360"`
+
+	// See LocationProperties.Cadence, Power and Temperature
+	Cadence     *float64 `query:"cadence,omitempty" json:"cadence,omitempty" validate:"omitempty,finite,gte=0,lte=300"`
+	Power       *float64 `query:"power,omitempty" json:"power,omitempty" validate:"omitempty,finite,gte=0,lte=5000"`
+	Temperature *float64 `query:"temperature,omitempty" json:"temperature,omitempty" validate:"omitempty,finite,gte=-100,lte=100"`
+
+	HeartRate *float64 `query:"heart_rate,omitempty" json:"heart_rate,omitempty" validate:"omitempty,finite,gte=0,lte=300"`
+	Session   string   `query:"session,omitempty" json:"session,omitempty" validate:"omitempty,session_name,max=100"`
+	Status    string   `query:"status,omitempty" json:"status,omitempty" validate:"omitempty,max=100"`
+	Event     string   `query:"event,omitempty" json:"event,omitempty" validate:"omitempty,max=100"`
+	ClientID  string   `query:"client_id,omitempty" json:"client_id,omitempty" validate:"omitempty,max=100"` // See LocationProperties.ClientID
+}
+
+// TrackingSocketAck acknowledges a point streamed over the tracking WebSocket, in the order the
+// points were received. Rejected points report an error and do not close the connection.
+type TrackingSocketAck struct {
+	Index     uint32 `json:"index"` // Position of the point in the stream, starting at 0
+	ID        string `json:"id,omitempty"`
+	Session   string `json:"session,omitempty"`
+	Discarded bool   `json:"discarded,omitempty"` // Dropped by the downsampling policy
+	Error     string `json:"error,omitempty"`
+}
+
+// OsmAndQueryParams represents a position sent with the osmAnd/Traccar HTTP protocol; parameters
+// of the protocol that are not tracked, like hdop or charge, are ignored
+type OsmAndQueryParams struct {
+	ID        string   `query:"id,omitempty" validate:"omitempty,max=100"`
+	Latitude  *float64 `query:"lat,omitempty" validate:"omitempty,finite"`
+	Longitude *float64 `query:"lon,omitempty" validate:"omitempty,finite"`
+	Location  string   `query:"location,omitempty" validate:"omitempty,max=100"` // "lat,lon", instead of lat and lon
+	Timestamp string   `query:"timestamp,omitempty" validate:"omitempty,max=50"` // Unix seconds or milliseconds, or a date
+	Altitude  *float64 `query:"altitude,omitempty" validate:"omitempty,finite"`
+	Speed     *float64 `query:"speed,omitempty" validate:"omitempty,finite,gte=0"`
+	SpeedUnit string   `query:"speed_unit,omitempty" validate:"omitempty,oneof=m/s km/h mph knots"` // Knots when not set
+	Accuracy  *float64 `query:"accuracy,omitempty" validate:"omitempty,finite,gte=0"`
+	Bearing   *float64 `query:"bearing,omitempty" validate:"omitempty,finite"`
+	Battery   *float64 `query:"batt,omitempty" validate:"omitempty,finite"`
+	Alarm     string   `query:"alarm,omitempty" validate:"omitempty,max=100"`
 	Session   string   `query:"session,omitempty" validate:"omitempty,session_name,max=100"`
-	Status    string   `query:"status,omitempty" validate:"omitempty,max=100"`
-	Event     string   `query:"event,omitempty" validate:"omitempty,max=100"`
+}
+
+// PublicLocationsQueryParams represents the filters and sort order of the public locations feed
+type PublicLocationsQueryParams struct {
+	Activity     string `query:"activity,omitempty" validate:"omitempty,max=200"`     // Comma-separated session activities
+	ActiveWithin string `query:"active_within,omitempty" validate:"omitempty,max=20"` // Duration since the latest location, e.g. 15m
+	BBox         string `query:"bbox,omitempty" validate:"omitempty,max=100"`         // minLon,minLat,maxLon,maxLat
+	Sort         string `query:"sort,omitempty" validate:"omitempty,oneof=recent distance username"`
+}
+
+// PositionAtQueryParams represents the query of a historical position lookup
+type PositionAtQueryParams struct {
+	Timestamp string `query:"timestamp" validate:"required,max=50"`                      // RFC 3339 time or Unix timestamp
+	MaxOffset *int   `query:"max_offset,omitempty" validate:"omitempty,min=0,max=86400"` // Seconds to the closest point when not interpolated
+}
+
+// HistoricalPosition is where a user was at a given time
+type HistoricalPosition struct {
+	Timestamp int64    `json:"timestamp"` // Requested time
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Altitude  *float64 `json:"altitude,omitempty"`
+	Session   string   `json:"session,omitempty"`
+	Method    string   `json:"method"` // exact, interpolated or nearest
+	Offset    int64    `json:"offset"` // Seconds between the requested time and the closest recorded point
 }
 
 // Location represents a stored location record
 type Location struct {
-	ID        string    `json:"id"`
-	User      string    `json:"user"`
-	Latitude  float64   `json:"latitude"`
-	Longitude float64   `json:"longitude"`
-	Altitude  float64   `json:"altitude,omitempty"`
-	Speed     float64   `json:"speed,omitempty"`
-	HeartRate float64   `json:"heart_rate,omitempty"`
+	ID        string   `json:"id"`
+	User      string   `json:"user"`
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Altitude  *float64 `json:"altitude,omitempty"`
+	Speed     float64  `json:"speed,omitempty"`
+	HeartRate float64  `json:"heart_rate,omitempty"`
+	Accuracy  float64  `json:"accuracy,omitempty"`
+	Device    string   `json:"device,omitempty"`
+	Battery   float64  `json:"battery,omitempty"`
+
+	VerticalAccuracy float64 `json:"vertical_accuracy,omitempty"`
+	Bearing          float64 `json:"bearing,omitempty"`
+
+	Cadence     float64  `json:"cadence,omitempty"`
+	Power       float64  `json:"power,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+
 	Session   string    `json:"session,omitempty"`
 	Status    string    `json:"status,omitempty"`
 	Event     string    `json:"event,omitempty"`
@@ -79,3 +172,78 @@ type SessionDataResponse struct {
 	Type     string             `json:"type"`
 	Features []LocationResponse `json:"features"`
 }
+
+// ColoredSegment is a track segment between two consecutive locations with its metric value
+type ColoredSegment struct {
+	Coordinates [][]float64 `json:"coordinates"` // [[lon, lat], [lon, lat]]
+	Value       float64     `json:"value"`
+	Normalized  float64     `json:"normalized"` // Value scaled to 0..1 between Min and Max
+}
+
+// TrackColoringResponse contains per-segment metric values for coloring a session track
+type TrackColoringResponse struct {
+	Metric    string           `json:"metric"`
+	Unit      string           `json:"unit"`
+	Min       float64          `json:"min"`
+	Max       float64          `json:"max"`
+	ColorRamp []string         `json:"color_ramp"`
+	Segments  []ColoredSegment `json:"segments"`
+}
+
+// TrackGap is a break between two consecutive locations that were too far apart in time or
+// distance to be connected, e.g. a tunnel, a flight or a tracker outage
+type TrackGap struct {
+	StartTime int64     `json:"start_time"` // Unix timestamp of the last location before the gap
+	EndTime   int64     `json:"end_time"`   // Unix timestamp of the first location after the gap
+	Duration  int64     `json:"duration"`   // Seconds
+	Distance  float64   `json:"distance"`   // Straight-line meters across the gap
+	From      []float64 `json:"from"`       // [lon, lat]
+	To        []float64 `json:"to"`         // [lon, lat]
+}
+
+// SessionStatsResponse summarizes a session track; distance and moving time exclude gaps
+type SessionStatsResponse struct {
+	PointCount      int        `json:"point_count"`
+	SegmentCount    int        `json:"segment_count"`
+	StartTime       int64      `json:"start_time,omitempty"`
+	EndTime         int64      `json:"end_time,omitempty"`
+	Duration        int64      `json:"duration"`         // Seconds from the first to the last location
+	TrackedDuration int64      `json:"tracked_duration"` // Seconds spent within segments
+	Distance        float64    `json:"distance"`         // Meters within segments
+	GapDuration     int64      `json:"gap_duration"`     // Seconds spent in gaps
+	Gaps            []TrackGap `json:"gaps"`
+	Climbs          []Climb    `json:"climbs"`
+
+	// Surface statistics, once the ended session was matched to OSM ways
+	Surface *SurfaceSummary `json:"surface,omitempty"`
+
+	// Sunrise and sunset at the start of the track
+	Daylight *SunTimes `json:"daylight,omitempty"`
+}
+
+// SunTimes are the sunrise and sunset of a day at a place
+type SunTimes struct {
+	Date       string     `json:"date"`       // Local solar date, YYYY-MM-DD
+	Sunrise    *time.Time `json:"sunrise"`    // Null during polar day and night
+	Sunset     *time.Time `json:"sunset"`     // Null during polar day and night
+	DayLength  int64      `json:"day_length"` // Seconds between sunrise and sunset
+	PolarDay   bool       `json:"polar_day,omitempty"`
+	PolarNight bool       `json:"polar_night,omitempty"`
+}
+
+// Climb is a categorized ascent along a track, distances are measured from the start of the track
+type Climb struct {
+	StartIndex      int       `json:"start_index"` // Index of the first point of the climb
+	EndIndex        int       `json:"end_index"`   // Index of the top of the climb
+	StartDistance   float64   `json:"start_distance"`
+	EndDistance     float64   `json:"end_distance"`
+	Length          float64   `json:"length"`           // Meters
+	StartAltitude   float64   `json:"start_altitude"`   // Meters
+	EndAltitude     float64   `json:"end_altitude"`     // Meters
+	ElevationGain   float64   `json:"elevation_gain"`   // Meters from the start to the top
+	AverageGradient float64   `json:"average_gradient"` // Percent
+	MaxGradient     float64   `json:"max_gradient"`     // Percent, steepest stretch of at least 100 m
+	Category        string    `json:"category"`         // 4, 3, 2, 1 or HC
+	From            []float64 `json:"from"`             // [lon, lat]
+	To              []float64 `json:"to"`               // [lon, lat]
+}
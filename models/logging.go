@@ -0,0 +1,14 @@
+package models
+
+// UpdateLogLevelRequest represents the request body for changing a log level at runtime; without
+// a module the default level is changed, "reset" removes the override of a module
+type UpdateLogLevelRequest struct {
+	Module string `json:"module,omitempty" validate:"omitempty,max=50"`
+	Level  string `json:"level" validate:"required,oneof=trace debug info warn error disabled reset"`
+}
+
+// LogLevels represents the default log level and the level overrides of modules
+type LogLevels struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// MapMatchResponse summarizes the road-snapped track produced for a session
+type MapMatchResponse struct {
+	SessionID    string    `json:"session_id"`
+	Provider     string    `json:"provider"`      // valhalla or osrm
+	PointCount   int       `json:"point_count"`   // Locations of the session
+	MatchedCount int       `json:"matched_count"` // Locations snapped to a way
+	MatchedAt    time.Time `json:"matched_at"`
+}
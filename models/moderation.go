@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// CreateReportRequest represents the request body for reporting public content
+type CreateReportRequest struct {
+	TargetType string `json:"target_type" validate:"required,oneof=session waypoint community_waypoint"`
+	TargetID   string `json:"target_id" validate:"required,max=15"`
+	Reason     string `json:"reason" validate:"required,oneof=spam inappropriate harassment privacy other"`
+	Comment    string `json:"comment,omitempty" validate:"omitempty,max=500"`
+}
+
+// ReviewReportRequest represents the request body for resolving a report; the decision
+// applies to every pending report of the same content
+type ReviewReportRequest struct {
+	Action string `json:"action" validate:"required,oneof=dismiss hide"`
+}
+
+// Report represents an abuse report on public content
+type Report struct {
+	ID          string     `json:"id"`
+	TargetType  string     `json:"target_type"`
+	TargetID    string     `json:"target_id"`
+	TargetOwner string     `json:"target_owner,omitempty"`
+	Reporter    string     `json:"reporter"`
+	Reason      string     `json:"reason"`
+	Comment     string     `json:"comment,omitempty"`
+	Status      string     `json:"status"`
+	ReviewedBy  string     `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	Created     time.Time  `json:"created"`
+}
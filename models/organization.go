@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// CreateOrganizationRequest represents the request body for creating an organization
+type CreateOrganizationRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=200"`
+	Slug        string `json:"slug" validate:"required,slug,min=1,max=100"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=1000"`
+}
+
+// AddOrganizationMemberRequest represents the request body for adding a member to an organization
+type AddOrganizationMemberRequest struct {
+	Username string `json:"username" validate:"required"`
+	Role     string `json:"role" validate:"required,oneof=owner admin member"`
+}
+
+// UpdateOrganizationMemberRequest represents the request body for changing a member's role
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner admin member"`
+}
+
+// CreateOrganizationAPIKeyRequest represents the request body for creating an organization API key
+type CreateOrganizationAPIKeyRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+	Role string `json:"role" validate:"required,oneof=admin member"`
+}
+
+// CreateOrganizationSessionRequest represents the request body for creating a session run by an organization
+type CreateOrganizationSessionRequest struct {
+	Username    string `json:"username" validate:"required"` // Member whose tracker records the session
+	Name        string `json:"name" validate:"required,session_name,min=1,max=100"`
+	Title       string `json:"title,omitempty" validate:"omitempty,max=200"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Public      bool   `json:"public"`
+}
+
+// Organization represents an organization such as a club or race organizer
+type Organization struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description,omitempty"`
+	Role        string    `json:"role,omitempty"` // Role of the requesting member
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+// OrganizationMember represents a user's membership in an organization
+type OrganizationMember struct {
+	ID       string    `json:"id"`
+	UserID   string    `json:"user"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	Created  time.Time `json:"created"`
+}
+
+// OrganizationAPIKey represents an organization API key; the plain key is only returned on creation
+type OrganizationAPIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Role       string     `json:"role"`
+	KeyPrefix  string     `json:"key_prefix"`
+	Key        string     `json:"key,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	Created    time.Time  `json:"created"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// OwnTracksMessage is a message posted by the OwnTracks apps in HTTP mode. Only "location"
+// messages carry a position; the apps also post transitions, waypoints and status messages.
+type OwnTracksMessage struct {
+	Type      string   `json:"_type" validate:"required,max=50"`
+	Timestamp int64    `json:"tst,omitempty" validate:"omitempty,gte=0"` // Unix seconds of the fix
+	Latitude  float64  `json:"lat,omitempty" validate:"omitempty,latitude"`
+	Longitude float64  `json:"lon,omitempty" validate:"omitempty,longitude"`
+	Altitude  *float64 `json:"alt,omitempty" validate:"omitempty,finite"`
+	Accuracy  *float64 `json:"acc,omitempty" validate:"omitempty,finite,gte=0"`
+	VAccuracy *float64 `json:"vac,omitempty" validate:"omitempty,finite"` // Vertical accuracy in meters
+	Course    *float64 `json:"cog,omitempty" validate:"omitempty,finite"` // Course over ground in degrees
+	Battery   *float64 `json:"batt,omitempty" validate:"omitempty,finite,gte=0,lte=100"`
+	Velocity  *float64 `json:"vel,omitempty" validate:"omitempty,finite,gte=0"` // km/h
+	TrackerID string   `json:"tid,omitempty" validate:"omitempty,max=100"`
+	Topic     string   `json:"topic,omitempty" validate:"omitempty,max=200"` // owntracks/<user>/<device>
+	InRegions []string `json:"inregions,omitempty" validate:"omitempty,dive,max=100"`
+}
@@ -0,0 +1,33 @@
+package models
+
+// RouteQueryParams represents the query parameters of a route planning request
+type RouteQueryParams struct {
+	From    string `query:"from" validate:"required,max=50"` // "lat,lon"
+	To      string `query:"to" validate:"required,max=50"`   // "lat,lon"
+	Profile string `query:"profile,omitempty" validate:"omitempty,oneof=foot bike"`
+	Format  string `query:"format,omitempty" validate:"omitempty,oneof=geojson gpx"`
+}
+
+// RouteGeometry is the GeoJSON LineString of a planned route
+type RouteGeometry struct {
+	Type        string      `json:"type"`        // LineString
+	Coordinates [][]float64 `json:"coordinates"` // [[lon, lat, elevation], ...], elevation only when known
+}
+
+// RouteProperties describes a planned route
+type RouteProperties struct {
+	Profile          string       `json:"profile"`
+	Distance         float64      `json:"distance"`          // Meters
+	Duration         float64      `json:"duration"`          // Seconds estimated by the routing engine
+	Ascent           float64      `json:"ascent"`            // Meters
+	Descent          float64      `json:"descent"`           // Meters
+	ElevationProfile [][2]float64 `json:"elevation_profile"` // [[distance from start, elevation], ...] in meters
+	Climbs           []Climb      `json:"climbs"`
+}
+
+// RouteFeature is a planned route as a GeoJSON Feature
+type RouteFeature struct {
+	Type       string          `json:"type"` // Feature
+	Geometry   RouteGeometry   `json:"geometry"`
+	Properties RouteProperties `json:"properties"`
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"mime/multipart"
+	"time"
+)
 
 // CreateSessionRequest represents the request body for creating a session
 type CreateSessionRequest struct {
@@ -8,6 +11,8 @@ type CreateSessionRequest struct {
 	Title       string `json:"title,omitempty" validate:"omitempty,max=200"`
 	Description string `json:"description,omitempty" validate:"omitempty,max=1000"`
 	Public      *bool  `json:"public,omitempty"` // Optional - uses user's default if not specified
+	Draft       bool   `json:"draft,omitempty"`  // Optional - keeps the session out of public feeds until published
+	Activity    string `json:"activity,omitempty" validate:"omitempty,activity"`
 }
 
 // UpdateSessionRequest represents the request body for updating a session
@@ -15,6 +20,19 @@ type UpdateSessionRequest struct {
 	Title       string `json:"title,omitempty" validate:"omitempty,max=200"`
 	Description string `json:"description,omitempty" validate:"omitempty,max=1000"`
 	Public      bool   `json:"public"`
+	// Optional - an empty string clears the activity
+	Activity *string `json:"activity,omitempty" validate:"omitempty,activity"`
+	// Optional - shows the live viewer count to viewers of the session stream
+	ShowViewerCount *bool `json:"show_viewer_count,omitempty"`
+	// Optional - overrides the downsampling policy of the user's tracking defaults
+	Downsampling *DownsamplingPolicy `json:"downsampling,omitempty"`
+	// Optional - removes the session's own policy so the tracking defaults apply again
+	ResetDownsampling bool `json:"reset_downsampling,omitempty"`
+}
+
+// RenameSessionRequest represents the request body for renaming a session
+type RenameSessionRequest struct {
+	Name string `json:"name" validate:"required,session_name,min=1,max=100"`
 }
 
 // Session represents a session in the system
@@ -23,16 +41,29 @@ type Session struct {
 	Name             string    `json:"name"`
 	Title            string    `json:"title"`
 	Description      string    `json:"description"`
+	DescriptionHTML  string    `json:"description_html"` // Description rendered from markdown, safe to embed
 	Public           bool      `json:"public"`
 	ShareToken       string    `json:"share_token,omitempty"` // Only included for owner
 	User             string    `json:"user,omitempty"`
+	Organization     string    `json:"organization,omitempty"`
 	GpxTrack         string    `json:"gpx_track,omitempty"`
 	TrackName        string    `json:"track_name,omitempty"`
 	TrackDescription string    `json:"track_description,omitempty"`
+	ShowViewerCount  bool      `json:"show_viewer_count"`
+	Draft            bool      `json:"draft"`
+	Activity         string    `json:"activity,omitempty"`
 	Created          time.Time `json:"created"`
 	Updated          time.Time `json:"updated"`
 }
 
+// GuestTokenResponse represents a guest viewer token minted from a share link
+type GuestTokenResponse struct {
+	GuestToken string    `json:"guest_token"`
+	ViewerID   string    `json:"viewer_id"`
+	SessionID  string    `json:"session_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
 // SessionsListResponse represents the paginated response for listing sessions
 type SessionsListResponse struct {
 	Sessions   []Session `json:"sessions"`
@@ -59,21 +90,125 @@ type GpxTrackPoint struct {
 	Updated   time.Time `json:"updated"`
 }
 
-// Waypoint represents a waypoint associated with a session
+// Waypoint represents a waypoint owned by a user and optionally linked to a session
 type Waypoint struct {
-	ID                 string    `json:"id"`
-	Name               string    `json:"name"`
-	Type               string    `json:"type"`
-	Description        string    `json:"description,omitempty"`
-	Latitude           float64   `json:"latitude"`
-	Longitude          float64   `json:"longitude"`
-	Altitude           *float64  `json:"altitude,omitempty"`
-	Photo              string    `json:"photo,omitempty"`
-	SessionID          string    `json:"session_id"`
-	Source             string    `json:"source"`
-	PositionConfidence string    `json:"position_confidence"`
-	Created            time.Time `json:"created"`
-	Updated            time.Time `json:"updated"`
+	ID                 string     `json:"id"`
+	Name               string     `json:"name"`
+	Type               string     `json:"type"`
+	Description        string     `json:"description,omitempty"`
+	DescriptionHTML    string     `json:"description_html,omitempty"` // Description rendered from markdown, safe to embed
+	Latitude           float64    `json:"latitude"`
+	Longitude          float64    `json:"longitude"`
+	Altitude           *float64   `json:"altitude,omitempty"`
+	Photo              string     `json:"photo,omitempty"`
+	PhotoURL           string     `json:"photo_url,omitempty"` // Signed download URL, expires after a few minutes
+	SessionID          string     `json:"session_id,omitempty"`
+	UserID             string     `json:"user"`
+	Source             string     `json:"source"`
+	PositionConfidence string     `json:"position_confidence"`
+	Order              int        `json:"order"`
+	VisitedAt          *time.Time `json:"visited_at,omitempty"`
+	Created            time.Time  `json:"created"`
+	Updated            time.Time  `json:"updated"`
+}
+
+// UploadGPXTrackRequest represents the multipart form of a planned route upload
+type UploadGPXTrackRequest struct {
+	GPXFile *multipart.FileHeader `form:"gpx_file" file:"required,max_size=5MB,types=application/gpx+xml"`
+	Draft   bool                  `form:"draft"` // Keeps the session a draft for review until it is published
+	// Imports the activity although it overlaps other sessions
+	ConfirmDuplicate bool `form:"confirm_duplicate"`
+	// Reads files that are not quite valid XML or GPX, reporting the repairs as warnings
+	Lenient bool `form:"lenient"`
+	// Stores the timed points as recorded locations of the session instead of as its planned track
+	AsRecorded bool `form:"as_recorded"`
+
+	// Simplification of the planned track, the instance settings apply to omitted fields
+	Simplify          *bool    `form:"simplify"`
+	MaxPoints         *int     `form:"max_points" validate:"omitempty,min=0,max=100000"` // 0 = no limit
+	EpsilonMultiplier *float64 `form:"epsilon_multiplier" validate:"omitempty,gt=0,lte=100"`
+}
+
+// PlannedTrack is a track or route of the GPX file of a session, as stored in its planned_tracks
+type PlannedTrack struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"` // track or route
+}
+
+// PlannedTrackSummary is a track or route of a session's plan with the segments of its points
+type PlannedTrackSummary struct {
+	PlannedTrack
+	Track    int                   `json:"track"` // Index of the track, the track of its points
+	Segments []PlannedTrackSegment `json:"segments"`
+}
+
+// PlannedTrackSegment is the range of the points of a segment of a planned track
+type PlannedTrackSegment struct {
+	Segment       int `json:"segment"`
+	FirstSequence int `json:"first_sequence"`
+	LastSequence  int `json:"last_sequence"`
+	PointCount    int `json:"point_count"`
+}
+
+// DuplicateSession is another session whose recorded track overlaps an imported activity
+type DuplicateSession struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Title      string    `json:"title"`
+	Start      time.Time `json:"start"` // Shared time window
+	End        time.Time `json:"end"`
+	Similarity float64   `json:"similarity"` // Share of the compared imported points close to the session's track, 0-1
+}
+
+// DuplicateActivityResponse is the conflict returned for an import overlapping other sessions
+type DuplicateActivityResponse struct {
+	Code       int                `json:"code"`
+	Message    string             `json:"message"`
+	Duplicates []DuplicateSession `json:"duplicates"`
+}
+
+// UploadSessionCoverRequest represents the multipart form of a session cover photo upload
+type UploadSessionCoverRequest struct {
+	Photo *multipart.FileHeader `form:"photo" file:"required,max_size=10MB,types=image/jpeg image/png image/webp image/heic image/heif"`
+}
+
+// UploadSessionMediaRequest represents the multipart form of a session media upload
+type UploadSessionMediaRequest struct {
+	File    *multipart.FileHeader `form:"file" file:"required,max_size=10MB,types=image/jpeg image/png image/webp image/heic image/heif video/mp4 video/quicktime"`
+	Caption string                `form:"caption" validate:"omitempty,max=500"`
+}
+
+// SessionMedia represents a photo or video attached to a session
+type SessionMedia struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"type"` // image or video
+	Caption string    `json:"caption,omitempty"`
+	URL     string    `json:"url"` // Signed download URL
+	Created time.Time `json:"created"`
+}
+
+// UploadPhotoWaypointRequest represents the multipart form of a photo waypoint upload
+type UploadPhotoWaypointRequest struct {
+	SessionID   string                `form:"session_id" validate:"required,max=50"`
+	Name        string                `form:"name" validate:"omitempty,max=200"` // Generated from the photo time when empty
+	Type        string                `form:"type" validate:"omitempty,oneof=generic food water shelter transition viewpoint camping parking danger medical fuel"`
+	Description string                `form:"description" validate:"omitempty,max=1000"`
+	Photo       *multipart.FileHeader `form:"photo" file:"required,max_size=10MB,types=image/jpeg image/png image/webp image/heic image/heif"`
+	Timezone    string                `form:"timezone" validate:"omitempty,max=64,camera_timezone"` // Camera clock time zone, UTC when empty
+}
+
+// ReferencedSessionID returns the session the photo waypoint is added to
+func (r *UploadPhotoWaypointRequest) ReferencedSessionID() string {
+	return r.SessionID
+}
+
+// GeotagPhotoRequest represents the multipart form of a photo to position from a session track
+type GeotagPhotoRequest struct {
+	Photo     *multipart.FileHeader `form:"photo" file:"required,max_size=10MB,types=image/jpeg image/png image/webp image/heic image/heif"`
+	Format    string                `form:"format" validate:"omitempty,oneof=jpeg json"`          // jpeg (default) returns the geotagged photo, json only its position
+	MaxOffset *int                  `form:"max_offset" validate:"omitempty,min=0,max=86400"`      // Seconds to the closest point when not interpolated
+	Timezone  string                `form:"timezone" validate:"omitempty,max=64,camera_timezone"` // Camera clock time zone, UTC when empty
 }
 
 // CreateWaypointRequest represents the request body for creating a waypoint
@@ -84,9 +219,15 @@ type CreateWaypointRequest struct {
 	Latitude           float64  `json:"latitude" validate:"required,min=-90,max=90"`
 	Longitude          float64  `json:"longitude" validate:"required,min=-180,max=180"`
 	Altitude           *float64 `json:"altitude,omitempty"`
-	SessionID          string   `json:"session_id" validate:"required"`
+	SessionID          string   `json:"session_id,omitempty"` // Optional - empty creates a user-level waypoint
 	Source             string   `json:"source" validate:"required,oneof=gpx manual photo"`
 	PositionConfidence string   `json:"position_confidence" validate:"required,oneof=gps time_matched tracked gpx_track last_known manual"`
+	Order              *int     `json:"order,omitempty" validate:"omitempty,min=0"`
+}
+
+// ReferencedSessionID returns the session the waypoint is created in, empty for user-level waypoints
+func (r *CreateWaypointRequest) ReferencedSessionID() string {
+	return r.SessionID
 }
 
 // UpdateWaypointRequest represents the request body for updating a waypoint
@@ -97,6 +238,49 @@ type UpdateWaypointRequest struct {
 	Latitude    *float64 `json:"latitude,omitempty" validate:"omitempty,min=-90,max=90"`
 	Longitude   *float64 `json:"longitude,omitempty" validate:"omitempty,min=-180,max=180"`
 	Altitude    *float64 `json:"altitude,omitempty"`
+	Order       *int     `json:"order,omitempty" validate:"omitempty,min=0"`
+	SessionID   *string  `json:"session_id,omitempty"` // Empty string unlinks the waypoint from its session
+}
+
+// ReorderWaypointsRequest represents the request body for reordering the waypoints of a session.
+// WaypointIDs lists every waypoint of the session in the desired order.
+type ReorderWaypointsRequest struct {
+	SessionID   string   `json:"session_id" validate:"required"`
+	WaypointIDs []string `json:"waypoint_ids" validate:"required,min=1,dive,required"`
+}
+
+// ReferencedSessionID returns the session whose waypoints are reordered
+func (r *ReorderWaypointsRequest) ReferencedSessionID() string {
+	return r.SessionID
+}
+
+// SetWaypointVisitedRequest represents the request body for checking off a waypoint.
+// VisitedAt is a Unix timestamp and defaults to the current time when omitted.
+type SetWaypointVisitedRequest struct {
+	Visited   bool  `json:"visited"`
+	VisitedAt int64 `json:"visited_at,omitempty" validate:"omitempty,min=0"`
+}
+
+// SessionProgressResponse represents the waypoint progress of a session
+type SessionProgressResponse struct {
+	SessionID          string    `json:"session_id"`
+	TotalWaypoints     int       `json:"total_waypoints"`
+	VisitedWaypoints   int       `json:"visited_waypoints"`
+	RemainingWaypoints int       `json:"remaining_waypoints"`
+	NextWaypoint       *Waypoint `json:"next_waypoint,omitempty"`
+
+	// Daylight at the latest position of a live session
+	Daylight *DaylightProgress `json:"daylight,omitempty"`
+}
+
+// DaylightProgress tells how much daylight is left at the latest position of a session and how
+// far the current pace gets before sunset
+type DaylightProgress struct {
+	SunTimes
+	Remaining                int64    `json:"remaining"`                             // Seconds until sunset, 0 after it
+	Pace                     *float64 `json:"pace,omitempty"`                        // Meters per second over the recent movement
+	DistanceBeforeSunset     *float64 `json:"distance_before_sunset,omitempty"`      // Meters at the current pace
+	NextWaypointBeforeSunset *bool    `json:"next_waypoint_before_sunset,omitempty"` // Whether the next waypoint is reached in daylight at the current pace
 }
 
 // WaypointsListResponse represents the paginated response for listing waypoints
@@ -119,3 +303,86 @@ type GpxTrackResponse struct {
 	SessionID   string          `json:"session_id"`
 	TrackName   string          `json:"track_name,omitempty"`
 }
+
+// ReplayFrame is a single interpolated position of a session replay
+type ReplayFrame struct {
+	Frame     int      `json:"frame"`     // Playback second, starting at 0
+	Timestamp int64    `json:"timestamp"` // Unix timestamp of the recorded time this frame represents
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Altitude  *float64 `json:"altitude,omitempty"`
+}
+
+// SessionReplayResponse contains keyframed positions for animating a session playback
+type SessionReplayResponse struct {
+	SessionID string        `json:"session_id"`
+	Speed     int           `json:"speed"` // Recorded seconds per playback second
+	StartTime int64         `json:"start_time"`
+	EndTime   int64         `json:"end_time"`
+	Frames    []ReplayFrame `json:"frames"`
+}
+
+// SessionEndedEvent is the webhook payload sent when a session is ended after inactivity
+type SessionEndedEvent struct {
+	SessionID string                `json:"session_id"`
+	Name      string                `json:"name"`
+	Title     string                `json:"title"`
+	UserID    string                `json:"user_id"`
+	EndedAt   time.Time             `json:"ended_at"` // Time of the last location
+	Stats     *SessionStatsResponse `json:"stats"`
+}
+
+// SessionPublishedEvent is the webhook payload sent when a draft session is published
+type SessionPublishedEvent struct {
+	SessionID   string    `json:"session_id"`
+	Name        string    `json:"name"`
+	Title       string    `json:"title"`
+	UserID      string    `json:"user_id"`
+	Public      bool      `json:"public"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// LowBatteryEvent is the webhook payload sent when a live tracker's battery drops below the threshold
+type LowBatteryEvent struct {
+	SessionID string    `json:"session_id"`
+	Session   string    `json:"session"`
+	UserID    string    `json:"user_id"`
+	Device    string    `json:"device,omitempty"`
+	Battery   float64   `json:"battery"`   // Percent
+	Threshold float64   `json:"threshold"` // Percent
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ArmCheckInRequest represents the request body for arming missed check-in alerts on a session
+type ArmCheckInRequest struct {
+	IntervalMinutes int      `json:"interval_minutes" validate:"required,min=5,max=1440"`
+	Contacts        []string `json:"contacts,omitempty" validate:"omitempty,max=5,dive,email"` // Alerted along with the verified emergency contacts when the owner does not react
+}
+
+// CheckInStatus describes the missed check-in alerting state of a session
+type CheckInStatus struct {
+	Armed           bool       `json:"armed"`
+	IntervalMinutes int        `json:"interval_minutes,omitempty"`
+	ArmedAt         *time.Time `json:"armed_at,omitempty"`
+	LastPointAt     *time.Time `json:"last_point_at,omitempty"`
+	Deadline        *time.Time `json:"deadline,omitempty"` // A point must arrive before this time
+	AlertLevel      int        `json:"alert_level"`        // 0 none, 1 owner alerted, 2 contacts alerted
+	Contacts        []string   `json:"contacts,omitempty"`
+}
+
+// CheckInMissedEvent is the webhook payload sent when an armed session misses its check-in
+type CheckInMissedEvent struct {
+	SessionID   string    `json:"session_id"`
+	Session     string    `json:"session"`
+	UserID      string    `json:"user_id"`
+	AlertLevel  int       `json:"alert_level"`
+	Deadline    time.Time `json:"deadline"`
+	LastPointAt time.Time `json:"last_point_at,omitempty"`
+	Latitude    float64   `json:"latitude,omitempty"`
+	Longitude   float64   `json:"longitude,omitempty"`
+	MapURL      string    `json:"map_url,omitempty"`
+
+	SMSRecipients []string `json:"sms_recipients,omitempty"` // Phone numbers of emergency contacts to alert by SMS
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// SessionSnapshot is a static bundle of a session served from a permanent URL
+type SessionSnapshot struct {
+	ID      string    `json:"id"`
+	URL     string    `json:"url"`   // Public URL of the manifest, the other files are next to it
+	Files   int       `json:"files"` // Manifest, track and photos
+	Created time.Time `json:"created"`
+}
+
+// SnapshotManifest describes a session snapshot. Files are referenced by their names, relative to
+// the manifest, so the bundle can also be copied to any static host.
+type SnapshotManifest struct {
+	Version   int                   `json:"version"`
+	Session   SnapshotSession       `json:"session"`
+	Stats     *SessionStatsResponse `json:"stats"`
+	Track     string                `json:"track"` // GeoJSON FeatureCollection, one feature per segment
+	Cover     string                `json:"cover,omitempty"`
+	Photos    []SnapshotPhoto       `json:"photos"`
+	Waypoints []SnapshotWaypoint    `json:"waypoints"`
+	Created   time.Time             `json:"created"`
+}
+
+// SnapshotSession is the session metadata at the time of the snapshot
+type SnapshotSession struct {
+	Username        string     `json:"username"`
+	Name            string     `json:"name"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"` // Markdown
+	DescriptionHTML string     `json:"description_html"`
+	Activity        string     `json:"activity,omitempty"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+}
+
+// SnapshotPhoto is a photo of the session media
+type SnapshotPhoto struct {
+	File    string `json:"file"`
+	Caption string `json:"caption,omitempty"`
+}
+
+// SnapshotWaypoint is a waypoint of the session with its photo
+type SnapshotWaypoint struct {
+	Name            string   `json:"name"`
+	Type            string   `json:"type"`
+	Description     string   `json:"description,omitempty"`
+	DescriptionHTML string   `json:"description_html,omitempty"`
+	Latitude        float64  `json:"latitude"`
+	Longitude       float64  `json:"longitude"`
+	Altitude        *float64 `json:"altitude,omitempty"`
+	Photo           string   `json:"photo,omitempty"`
+}
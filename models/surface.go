@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// SurfaceSegment is a stretch of a matched track on ways with the same surface and road type
+type SurfaceSegment struct {
+	Surface     string      `json:"surface"`      // Valhalla surface, e.g. paved_smooth, gravel or path
+	SurfaceType string      `json:"surface_type"` // paved, unpaved or unknown
+	Highway     string      `json:"highway"`      // Road class, e.g. residential, or way use such as track or footway
+	Distance    float64     `json:"distance"`     // Meters
+	Coordinates [][]float64 `json:"coordinates"`  // [[lon, lat], ...] snapped to the ways
+}
+
+// SurfaceSummary sums up the matched distance of a track per surface type, surface and road type
+type SurfaceSummary struct {
+	MatchedDistance float64            `json:"matched_distance"` // Meters
+	PavedDistance   float64            `json:"paved_distance"`
+	UnpavedDistance float64            `json:"unpaved_distance"`
+	UnknownDistance float64            `json:"unknown_distance"`
+	PavedPercent    float64            `json:"paved_percent"`
+	UnpavedPercent  float64            `json:"unpaved_percent"`
+	Surfaces        map[string]float64 `json:"surfaces"` // Meters per surface
+	Highways        map[string]float64 `json:"highways"` // Meters per road type
+}
+
+// SessionSurface is the surface and road type enrichment of a session track
+type SessionSurface struct {
+	MatchedAt time.Time        `json:"matched_at"`
+	Summary   SurfaceSummary   `json:"summary"`
+	Segments  []SurfaceSegment `json:"segments"`
+}
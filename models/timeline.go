@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// TimelineEntry is a stay at one place or a movement between stays in a daily timeline
+type TimelineEntry struct {
+	Type       string    `json:"type"` // stay or movement
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	Duration   int64     `json:"duration"` // Seconds
+	PointCount int       `json:"point_count"`
+
+	// Stays
+	Latitude  *float64 `json:"latitude,omitempty"` // Center of the stay's points
+	Longitude *float64 `json:"longitude,omitempty"`
+
+	// Movements
+	Distance     float64 `json:"distance,omitempty"`      // Meters
+	AverageSpeed float64 `json:"average_speed,omitempty"` // Meters per second
+	MaxSpeed     float64 `json:"max_speed,omitempty"`     // Meters per second between consecutive points
+	Mode         string  `json:"mode,omitempty"`          // Detected transport mode: walking, cycling, driving or flying
+}
+
+// TimelineResponse represents a user's timeline of one day in the user's time zone
+type TimelineResponse struct {
+	Date       string          `json:"date"`
+	Timezone   string          `json:"timezone"`
+	ComputedAt time.Time       `json:"computed_at"`
+	Entries    []TimelineEntry `json:"entries"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// RouteWeatherQueryParams represents the query parameters of a weather along route request
+type RouteWeatherQueryParams struct {
+	Start string   `query:"start" validate:"required,max=50"`                         // RFC 3339 time or Unix timestamp
+	Speed *float64 `query:"speed,omitempty" validate:"omitempty,finite,gt=0,lte=200"` // Kilometers per hour
+}
+
+// WeatherForecast is the forecasted weather of one hour at a place
+type WeatherForecast struct {
+	Time                     time.Time `json:"time"`                                // Start of the forecast hour
+	Temperature              *float64  `json:"temperature,omitempty"`               // Degrees Celsius
+	PrecipitationProbability *float64  `json:"precipitation_probability,omitempty"` // Percent
+	Precipitation            *float64  `json:"precipitation,omitempty"`             // Millimeters
+	WindSpeed                *float64  `json:"wind_speed,omitempty"`                // Meters per second
+	WindDirection            *float64  `json:"wind_direction,omitempty"`            // Degrees
+	WeatherCode              *int      `json:"weather_code,omitempty"`              // WMO weather interpretation code
+}
+
+// RouteWeatherSample is a point along a planned route with the weather expected on arrival
+type RouteWeatherSample struct {
+	Distance  float64          `json:"distance"` // Meters from the start of the route
+	Latitude  float64          `json:"latitude"`
+	Longitude float64          `json:"longitude"`
+	ArrivalAt time.Time        `json:"arrival_at"`
+	Forecast  *WeatherForecast `json:"forecast"` // Null beyond the forecast horizon or in the past
+}
+
+// RouteWeatherResponse represents the weather along a session's planned route
+type RouteWeatherResponse struct {
+	SessionID string               `json:"session_id"`
+	StartAt   time.Time            `json:"start_at"`
+	Speed     float64              `json:"speed"` // Kilometers per hour
+	Samples   []RouteWeatherSample `json:"samples"`
+}
@@ -0,0 +1,146 @@
+// Package plugins lets forks add custom behavior, e.g. pushing tracked locations to a company
+// system, without patching handlers. A plugin registers itself from an init function in a file
+// of this package or of a package imported by main:
+//
+//	func init() {
+//		plugins.Register(&companyPlugin{})
+//	}
+//
+// and subscribes to the hooks it needs when the app starts.
+package plugins
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/hook"
+
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// Plugin is an extension registered with Register
+type Plugin interface {
+	// Name identifies the plugin in logs, it must be unique
+	Name() string
+
+	// Setup subscribes the plugin to the hooks; an error stops the app from starting
+	Setup(app *pocketbase.PocketBase, hooks *Hooks) error
+}
+
+// LocationEvent is passed to OnLocationIngested handlers
+type LocationEvent struct {
+	Location *models.Record
+}
+
+// SessionClosedEvent is passed to OnSessionClosed handlers
+type SessionClosedEvent struct {
+	Session *models.Record
+	Stats   *appmodels.SessionStatsResponse
+}
+
+// SessionPublishedEvent is passed to OnSessionPublished handlers
+type SessionPublishedEvent struct {
+	Session *models.Record
+}
+
+// WaypointEvent is passed to OnWaypointCreated handlers
+type WaypointEvent struct {
+	Waypoint *models.Record
+}
+
+// Hooks are the extension points plugins subscribe to. Handlers run synchronously after the
+// change is committed, in the order they were added; slow work such as calls to other systems
+// belongs in a goroutine. A handler error is logged and stops the remaining handlers of the event.
+type Hooks struct {
+	OnLocationIngested *hook.Hook[*LocationEvent]         // A location was stored; GPX files uploaded as recorded history do not trigger it
+	OnSessionClosed    *hook.Hook[*SessionClosedEvent]    // A session was ended after its tracker stopped reporting
+	OnSessionPublished *hook.Hook[*SessionPublishedEvent] // A draft session was published
+	OnWaypointCreated  *hook.Hook[*WaypointEvent]         // A waypoint was stored
+}
+
+// NewHooks creates the hooks without any handler
+func NewHooks() *Hooks {
+	return &Hooks{
+		OnLocationIngested: &hook.Hook[*LocationEvent]{},
+		OnSessionClosed:    &hook.Hook[*SessionClosedEvent]{},
+		OnSessionPublished: &hook.Hook[*SessionPublishedEvent]{},
+		OnWaypointCreated:  &hook.Hook[*WaypointEvent]{},
+	}
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []Plugin
+)
+
+// Register adds a plugin to be set up when the app starts. It panics when a plugin with the same
+// name is already registered, as that is a programming error.
+func Register(plugin Plugin) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, registered := range registry {
+		if registered.Name() == plugin.Name() {
+			panic(fmt.Sprintf("plugin %q registered twice", plugin.Name()))
+		}
+	}
+	registry = append(registry, plugin)
+}
+
+// Registered returns the registered plugins in registration order
+func Registered() []Plugin {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	return append([]Plugin(nil), registry...)
+}
+
+// Setup sets up every registered plugin with the hooks
+func Setup(app *pocketbase.PocketBase, hooks *Hooks) error {
+	for _, plugin := range Registered() {
+		if err := plugin.Setup(app, hooks); err != nil {
+			return fmt.Errorf("plugin %s: %w", plugin.Name(), err)
+		}
+		utils.LogInfo().Str("plugin", plugin.Name()).Msg("Plugin set up")
+	}
+	return nil
+}
+
+// trigger runs the handlers of an event and logs their error
+func trigger[T any](h *hook.Hook[T], event string, data T) {
+	if err := h.Trigger(data); err != nil {
+		utils.LogError(err, "plugin hook").Str("event", event).Msg("Plugin hook failed")
+	}
+}
+
+// LocationCreated triggers OnLocationIngested, registered as a PocketBase after create hook of
+// the locations collection
+func (h *Hooks) LocationCreated(e *core.ModelEvent) error {
+	if record, ok := e.Model.(*models.Record); ok {
+		trigger(h.OnLocationIngested, "location_ingested", &LocationEvent{Location: record})
+	}
+	return nil
+}
+
+// WaypointCreated triggers OnWaypointCreated, registered as a PocketBase after create hook of the
+// waypoints collection
+func (h *Hooks) WaypointCreated(e *core.ModelEvent) error {
+	if record, ok := e.Model.(*models.Record); ok {
+		trigger(h.OnWaypointCreated, "waypoint_created", &WaypointEvent{Waypoint: record})
+	}
+	return nil
+}
+
+// SessionClosed triggers OnSessionClosed
+func (h *Hooks) SessionClosed(session *models.Record, stats *appmodels.SessionStatsResponse) {
+	trigger(h.OnSessionClosed, "session_closed", &SessionClosedEvent{Session: session, Stats: stats})
+}
+
+// SessionPublished triggers OnSessionPublished
+func (h *Hooks) SessionPublished(session *models.Record) {
+	trigger(h.OnSessionPublished, "session_published", &SessionPublishedEvent{Session: session})
+}
@@ -0,0 +1,91 @@
+package plugins
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPlugin struct {
+	name  string
+	err   error
+	setup func(hooks *Hooks)
+}
+
+func (p *testPlugin) Name() string { return p.name }
+
+func (p *testPlugin) Setup(app *pocketbase.PocketBase, hooks *Hooks) error {
+	if p.setup != nil {
+		p.setup(hooks)
+	}
+	return p.err
+}
+
+// withRegistry runs a test with an empty plugin registry
+func withRegistry(t *testing.T) {
+	registryMu.Lock()
+	saved := registry
+	registry = nil
+	registryMu.Unlock()
+
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = saved
+		registryMu.Unlock()
+	})
+}
+
+func TestRegister(t *testing.T) {
+	withRegistry(t)
+
+	Register(&testPlugin{name: "first"})
+	Register(&testPlugin{name: "second"})
+	assert.Len(t, Registered(), 2)
+	assert.Equal(t, "first", Registered()[0].Name())
+
+	assert.Panics(t, func() { Register(&testPlugin{name: "first"}) })
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("plugins receive events", func(t *testing.T) {
+		withRegistry(t)
+
+		var ingested []string
+		Register(&testPlugin{name: "recorder", setup: func(hooks *Hooks) {
+			hooks.OnLocationIngested.Add(func(e *LocationEvent) error {
+				ingested = append(ingested, e.Location.Id)
+				return nil
+			})
+		}})
+
+		hooks := NewHooks()
+		assert.NoError(t, Setup(nil, hooks))
+
+		location := models.NewRecord(&models.Collection{})
+		location.Id = "loc1"
+		assert.NoError(t, hooks.LocationCreated(&core.ModelEvent{BaseModelEvent: core.BaseModelEvent{Model: location}}))
+		assert.Equal(t, []string{"loc1"}, ingested)
+	})
+
+	t.Run("setup errors name the plugin", func(t *testing.T) {
+		withRegistry(t)
+
+		Register(&testPlugin{name: "broken", err: errors.New("missing API key")})
+		err := Setup(nil, NewHooks())
+		assert.ErrorContains(t, err, "plugin broken: missing API key")
+	})
+
+	t.Run("handler errors do not fail the change", func(t *testing.T) {
+		hooks := NewHooks()
+		hooks.OnWaypointCreated.Add(func(e *WaypointEvent) error {
+			return errors.New("company system unavailable")
+		})
+
+		waypoint := models.NewRecord(&models.Collection{})
+		assert.NoError(t, hooks.WaypointCreated(&core.ModelEvent{BaseModelEvent: core.BaseModelEvent{Model: waypoint}}))
+	})
+}
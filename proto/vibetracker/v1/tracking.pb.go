@@ -0,0 +1,623 @@
+// gRPC ingestion API for embedded trackers on constrained links. Points are validated with the
+// same rules as GET /api/track and stored through the same code path.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.0
+// 	protoc        (unknown)
+// source: vibetracker/v1/tracking.proto
+
+package trackingv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// LocationPoint is a single tracked position, the fields match the GET /api/track parameters
+type LocationPoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Latitude  float64 `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64 `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	// Unix timestamp in seconds, the time of arrival when unset
+	Timestamp int64    `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Altitude  *float64 `protobuf:"fixed64,4,opt,name=altitude,proto3,oneof" json:"altitude,omitempty"`
+	Speed     *float64 `protobuf:"fixed64,5,opt,name=speed,proto3,oneof" json:"speed,omitempty"`
+	// Unit of speed: m/s, km/h, mph or knots; the user's tracking setting when unset
+	SpeedUnit string `protobuf:"bytes,6,opt,name=speed_unit,json=speedUnit,proto3" json:"speed_unit,omitempty"`
+	// Horizontal accuracy in meters
+	Accuracy *float64 `protobuf:"fixed64,7,opt,name=accuracy,proto3,oneof" json:"accuracy,omitempty"`
+	Device   string   `protobuf:"bytes,8,opt,name=device,proto3" json:"device,omitempty"`
+	// Battery level in percent
+	Battery   *float64 `protobuf:"fixed64,9,opt,name=battery,proto3,oneof" json:"battery,omitempty"`
+	HeartRate *float64 `protobuf:"fixed64,10,opt,name=heart_rate,json=heartRate,proto3,oneof" json:"heart_rate,omitempty"`
+	// Session name, the user's automatic session when unset
+	Session string `protobuf:"bytes,11,opt,name=session,proto3" json:"session,omitempty"`
+	Status  string `protobuf:"bytes,12,opt,name=status,proto3" json:"status,omitempty"`
+	Event   string `protobuf:"bytes,13,opt,name=event,proto3" json:"event,omitempty"`
+	// Accept timestamps older than the configured maximum age, for uploads of old tracks
+	AllowHistorical bool `protobuf:"varint,14,opt,name=allow_historical,json=allowHistorical,proto3" json:"allow_historical,omitempty"`
+	// Altitude accuracy in meters
+	VerticalAccuracy *float64 `protobuf:"fixed64,15,opt,name=vertical_accuracy,json=verticalAccuracy,proto3,oneof" json:"vertical_accuracy,omitempty"`
+	// Direction of travel in degrees clockwise from true north
+	Bearing *float64 `protobuf:"fixed64,16,opt,name=bearing,proto3,oneof" json:"bearing,omitempty"`
+	// Unique per point of the user; a point with a known ID is not stored again
+	ClientId string `protobuf:"bytes,17,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	// Pedaling or running cadence in revolutions or steps per minute
+	Cadence *float64 `protobuf:"fixed64,18,opt,name=cadence,proto3,oneof" json:"cadence,omitempty"`
+	// Power output in watts
+	Power *float64 `protobuf:"fixed64,19,opt,name=power,proto3,oneof" json:"power,omitempty"`
+	// Ambient temperature in degrees Celsius
+	Temperature *float64 `protobuf:"fixed64,20,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"`
+}
+
+func (x *LocationPoint) Reset() {
+	*x = LocationPoint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vibetracker_v1_tracking_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocationPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocationPoint) ProtoMessage() {}
+
+func (x *LocationPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_vibetracker_v1_tracking_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocationPoint.ProtoReflect.Descriptor instead.
+func (*LocationPoint) Descriptor() ([]byte, []int) {
+	return file_vibetracker_v1_tracking_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LocationPoint) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetAltitude() float64 {
+	if x != nil && x.Altitude != nil {
+		return *x.Altitude
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetSpeed() float64 {
+	if x != nil && x.Speed != nil {
+		return *x.Speed
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetSpeedUnit() string {
+	if x != nil {
+		return x.SpeedUnit
+	}
+	return ""
+}
+
+func (x *LocationPoint) GetAccuracy() float64 {
+	if x != nil && x.Accuracy != nil {
+		return *x.Accuracy
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *LocationPoint) GetBattery() float64 {
+	if x != nil && x.Battery != nil {
+		return *x.Battery
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetHeartRate() float64 {
+	if x != nil && x.HeartRate != nil {
+		return *x.HeartRate
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+func (x *LocationPoint) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *LocationPoint) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *LocationPoint) GetAllowHistorical() bool {
+	if x != nil {
+		return x.AllowHistorical
+	}
+	return false
+}
+
+func (x *LocationPoint) GetVerticalAccuracy() float64 {
+	if x != nil && x.VerticalAccuracy != nil {
+		return *x.VerticalAccuracy
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetBearing() float64 {
+	if x != nil && x.Bearing != nil {
+		return *x.Bearing
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *LocationPoint) GetCadence() float64 {
+	if x != nil && x.Cadence != nil {
+		return *x.Cadence
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetPower() float64 {
+	if x != nil && x.Power != nil {
+		return *x.Power
+	}
+	return 0
+}
+
+func (x *LocationPoint) GetTemperature() float64 {
+	if x != nil && x.Temperature != nil {
+		return *x.Temperature
+	}
+	return 0
+}
+
+// Session is a tracking session points were stored in
+type Session struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id    string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Title string `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vibetracker_v1_tracking_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_vibetracker_v1_tracking_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_vibetracker_v1_tracking_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Session) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Session) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Session) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+// PointError explains why a point of the stream was rejected
+type PointError struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Zero based position of the point in the stream
+	Index   uint32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *PointError) Reset() {
+	*x = PointError{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vibetracker_v1_tracking_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PointError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PointError) ProtoMessage() {}
+
+func (x *PointError) ProtoReflect() protoreflect.Message {
+	mi := &file_vibetracker_v1_tracking_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PointError.ProtoReflect.Descriptor instead.
+func (*PointError) Descriptor() ([]byte, []int) {
+	return file_vibetracker_v1_tracking_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PointError) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *PointError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// TrackSummary is returned once the client closes the stream
+type TrackSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Accepted uint32        `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Rejected uint32        `protobuf:"varint,2,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	Errors   []*PointError `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	// Sessions the accepted points were stored in
+	Sessions []*Session `protobuf:"bytes,4,rep,name=sessions,proto3" json:"sessions,omitempty"`
+}
+
+func (x *TrackSummary) Reset() {
+	*x = TrackSummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vibetracker_v1_tracking_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrackSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackSummary) ProtoMessage() {}
+
+func (x *TrackSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_vibetracker_v1_tracking_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackSummary.ProtoReflect.Descriptor instead.
+func (*TrackSummary) Descriptor() ([]byte, []int) {
+	return file_vibetracker_v1_tracking_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TrackSummary) GetAccepted() uint32 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+func (x *TrackSummary) GetRejected() uint32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *TrackSummary) GetErrors() []*PointError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *TrackSummary) GetSessions() []*Session {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+var File_vibetracker_v1_tracking_proto protoreflect.FileDescriptor
+
+var file_vibetracker_v1_tracking_proto_rawDesc = []byte{
+	0x0a, 0x1d, 0x76, 0x69, 0x62, 0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f, 0x76, 0x31,
+	0x2f, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x0e, 0x76, 0x69, 0x62, 0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x22,
+	0x87, 0x06, 0x0a, 0x0d, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x69, 0x6e,
+	0x74, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x1c, 0x0a,
+	0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1f, 0x0a, 0x08, 0x61, 0x6c, 0x74,
+	0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x08, 0x61,
+	0x6c, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x73, 0x70,
+	0x65, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x05, 0x73, 0x70, 0x65,
+	0x65, 0x64, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x75,
+	0x6e, 0x69, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x70, 0x65, 0x65, 0x64,
+	0x55, 0x6e, 0x69, 0x74, 0x12, 0x1f, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x48, 0x02, 0x52, 0x08, 0x61, 0x63, 0x63, 0x75, 0x72, 0x61,
+	0x63, 0x79, 0x88, 0x01, 0x01, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x1d, 0x0a,
+	0x07, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x48, 0x03,
+	0x52, 0x07, 0x62, 0x61, 0x74, 0x74, 0x65, 0x72, 0x79, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a, 0x0a,
+	0x68, 0x65, 0x61, 0x72, 0x74, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x04, 0x52, 0x09, 0x68, 0x65, 0x61, 0x72, 0x74, 0x52, 0x61, 0x74, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x5f, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x63, 0x61, 0x6c, 0x18, 0x0e, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0f, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69,
+	0x63, 0x61, 0x6c, 0x12, 0x30, 0x0a, 0x11, 0x76, 0x65, 0x72, 0x74, 0x69, 0x63, 0x61, 0x6c, 0x5f,
+	0x61, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x01, 0x48, 0x05,
+	0x52, 0x10, 0x76, 0x65, 0x72, 0x74, 0x69, 0x63, 0x61, 0x6c, 0x41, 0x63, 0x63, 0x75, 0x72, 0x61,
+	0x63, 0x79, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x62, 0x65, 0x61, 0x72, 0x69, 0x6e, 0x67,
+	0x18, 0x10, 0x20, 0x01, 0x28, 0x01, 0x48, 0x06, 0x52, 0x07, 0x62, 0x65, 0x61, 0x72, 0x69, 0x6e,
+	0x67, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69,
+	0x64, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49,
+	0x64, 0x12, 0x1d, 0x0a, 0x07, 0x63, 0x61, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x12, 0x20, 0x01,
+	0x28, 0x01, 0x48, 0x07, 0x52, 0x07, 0x63, 0x61, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x19, 0x0a, 0x05, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x18, 0x13, 0x20, 0x01, 0x28, 0x01, 0x48,
+	0x08, 0x52, 0x05, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x0b, 0x74,
+	0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x09, 0x52, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x88,
+	0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x61, 0x6c, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x42,
+	0x08, 0x0a, 0x06, 0x5f, 0x73, 0x70, 0x65, 0x65, 0x64, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x61, 0x63,
+	0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x62, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x79, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x68, 0x65, 0x61, 0x72, 0x74, 0x5f, 0x72, 0x61, 0x74,
+	0x65, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x76, 0x65, 0x72, 0x74, 0x69, 0x63, 0x61, 0x6c, 0x5f, 0x61,
+	0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x62, 0x65, 0x61, 0x72,
+	0x69, 0x6e, 0x67, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x63, 0x61, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x42,
+	0x08, 0x0a, 0x06, 0x5f, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x74, 0x65,
+	0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x43, 0x0a, 0x07, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x22, 0x3c,
+	0x0a, 0x0a, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xaf, 0x01, 0x0a,
+	0x0c, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1a, 0x0a,
+	0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x08, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6a,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x72, 0x65, 0x6a,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x32, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x76, 0x69, 0x62, 0x65, 0x74, 0x72, 0x61, 0x63,
+	0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x12, 0x33, 0x0a, 0x08, 0x73, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x76, 0x69,
+	0x62, 0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x32, 0x59,
+	0x0a, 0x0f, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x12, 0x46, 0x0a, 0x05, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x12, 0x1d, 0x2e, 0x76, 0x69, 0x62,
+	0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x1a, 0x1c, 0x2e, 0x76, 0x69, 0x62, 0x65,
+	0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x6b,
+	0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x28, 0x01, 0x42, 0x2e, 0x5a, 0x2c, 0x76, 0x69, 0x62,
+	0x65, 0x2d, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
+	0x76, 0x69, 0x62, 0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x3b, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_vibetracker_v1_tracking_proto_rawDescOnce sync.Once
+	file_vibetracker_v1_tracking_proto_rawDescData = file_vibetracker_v1_tracking_proto_rawDesc
+)
+
+func file_vibetracker_v1_tracking_proto_rawDescGZIP() []byte {
+	file_vibetracker_v1_tracking_proto_rawDescOnce.Do(func() {
+		file_vibetracker_v1_tracking_proto_rawDescData = protoimpl.X.CompressGZIP(file_vibetracker_v1_tracking_proto_rawDescData)
+	})
+	return file_vibetracker_v1_tracking_proto_rawDescData
+}
+
+var file_vibetracker_v1_tracking_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_vibetracker_v1_tracking_proto_goTypes = []interface{}{
+	(*LocationPoint)(nil), // 0: vibetracker.v1.LocationPoint
+	(*Session)(nil),       // 1: vibetracker.v1.Session
+	(*PointError)(nil),    // 2: vibetracker.v1.PointError
+	(*TrackSummary)(nil),  // 3: vibetracker.v1.TrackSummary
+}
+var file_vibetracker_v1_tracking_proto_depIdxs = []int32{
+	2, // 0: vibetracker.v1.TrackSummary.errors:type_name -> vibetracker.v1.PointError
+	1, // 1: vibetracker.v1.TrackSummary.sessions:type_name -> vibetracker.v1.Session
+	0, // 2: vibetracker.v1.TrackingService.Track:input_type -> vibetracker.v1.LocationPoint
+	3, // 3: vibetracker.v1.TrackingService.Track:output_type -> vibetracker.v1.TrackSummary
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_vibetracker_v1_tracking_proto_init() }
+func file_vibetracker_v1_tracking_proto_init() {
+	if File_vibetracker_v1_tracking_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_vibetracker_v1_tracking_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocationPoint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vibetracker_v1_tracking_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Session); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vibetracker_v1_tracking_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PointError); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vibetracker_v1_tracking_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrackSummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_vibetracker_v1_tracking_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_vibetracker_v1_tracking_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_vibetracker_v1_tracking_proto_goTypes,
+		DependencyIndexes: file_vibetracker_v1_tracking_proto_depIdxs,
+		MessageInfos:      file_vibetracker_v1_tracking_proto_msgTypes,
+	}.Build()
+	File_vibetracker_v1_tracking_proto = out.File
+	file_vibetracker_v1_tracking_proto_rawDesc = nil
+	file_vibetracker_v1_tracking_proto_goTypes = nil
+	file_vibetracker_v1_tracking_proto_depIdxs = nil
+}
@@ -0,0 +1,152 @@
+// gRPC ingestion API for embedded trackers on constrained links. Points are validated with the
+// same rules as GET /api/track and stored through the same code path.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: vibetracker/v1/tracking.proto
+
+package trackingv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	TrackingService_Track_FullMethodName = "/vibetracker.v1.TrackingService/Track"
+)
+
+// TrackingServiceClient is the client API for TrackingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TrackingServiceClient interface {
+	// Track stores a stream of points of the authenticated user. Authenticate with the
+	// "authorization" metadata, either "Bearer <jwt>" or the user's tracking token. Invalid points
+	// are skipped and reported in the summary, the rest of the stream is still stored.
+	Track(ctx context.Context, opts ...grpc.CallOption) (TrackingService_TrackClient, error)
+}
+
+type trackingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTrackingServiceClient(cc grpc.ClientConnInterface) TrackingServiceClient {
+	return &trackingServiceClient{cc}
+}
+
+func (c *trackingServiceClient) Track(ctx context.Context, opts ...grpc.CallOption) (TrackingService_TrackClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TrackingService_ServiceDesc.Streams[0], TrackingService_Track_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &trackingServiceTrackClient{stream}
+	return x, nil
+}
+
+type TrackingService_TrackClient interface {
+	Send(*LocationPoint) error
+	CloseAndRecv() (*TrackSummary, error)
+	grpc.ClientStream
+}
+
+type trackingServiceTrackClient struct {
+	grpc.ClientStream
+}
+
+func (x *trackingServiceTrackClient) Send(m *LocationPoint) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *trackingServiceTrackClient) CloseAndRecv() (*TrackSummary, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(TrackSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TrackingServiceServer is the server API for TrackingService service.
+// All implementations must embed UnimplementedTrackingServiceServer
+// for forward compatibility
+type TrackingServiceServer interface {
+	// Track stores a stream of points of the authenticated user. Authenticate with the
+	// "authorization" metadata, either "Bearer <jwt>" or the user's tracking token. Invalid points
+	// are skipped and reported in the summary, the rest of the stream is still stored.
+	Track(TrackingService_TrackServer) error
+	mustEmbedUnimplementedTrackingServiceServer()
+}
+
+// UnimplementedTrackingServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedTrackingServiceServer struct {
+}
+
+func (UnimplementedTrackingServiceServer) Track(TrackingService_TrackServer) error {
+	return status.Errorf(codes.Unimplemented, "method Track not implemented")
+}
+func (UnimplementedTrackingServiceServer) mustEmbedUnimplementedTrackingServiceServer() {}
+
+// UnsafeTrackingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TrackingServiceServer will
+// result in compilation errors.
+type UnsafeTrackingServiceServer interface {
+	mustEmbedUnimplementedTrackingServiceServer()
+}
+
+func RegisterTrackingServiceServer(s grpc.ServiceRegistrar, srv TrackingServiceServer) {
+	s.RegisterService(&TrackingService_ServiceDesc, srv)
+}
+
+func _TrackingService_Track_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TrackingServiceServer).Track(&trackingServiceTrackServer{stream})
+}
+
+type TrackingService_TrackServer interface {
+	SendAndClose(*TrackSummary) error
+	Recv() (*LocationPoint, error)
+	grpc.ServerStream
+}
+
+type trackingServiceTrackServer struct {
+	grpc.ServerStream
+}
+
+func (x *trackingServiceTrackServer) SendAndClose(m *TrackSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *trackingServiceTrackServer) Recv() (*LocationPoint, error) {
+	m := new(LocationPoint)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TrackingService_ServiceDesc is the grpc.ServiceDesc for TrackingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TrackingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "vibetracker.v1.TrackingService",
+	HandlerType: (*TrackingServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Track",
+			Handler:       _TrackingService_Track_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "vibetracker/v1/tracking.proto",
+}
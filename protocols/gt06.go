@@ -0,0 +1,187 @@
+package protocols
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"vibe-tracker/constants"
+)
+
+// GT06 message types
+const (
+	gt06Login     = 0x01
+	gt06GPS       = 0x12
+	gt06Heartbeat = 0x13
+	gt06Alarm     = 0x16
+	gt06GPS2      = 0x22 // GPS report of newer firmware, same position block as 0x12
+)
+
+// gt06 is the binary protocol of Concox GT06 and the many trackers copying it. Frames start with
+// 0x7878 (one byte length) or 0x7979 (two byte length) and end with 0x0D0A; the device logs in
+// with its IMEI and then reports positions without it.
+type gt06 struct{}
+
+func init() {
+	register(gt06{})
+}
+
+func (gt06) Name() string {
+	return constants.TrackerProtocolGT06
+}
+
+func (gt06) Datagrams() bool {
+	return false
+}
+
+func (gt06) Split(data []byte, atEOF bool) (int, []byte, error) {
+	for start := 0; start+1 < len(data); start++ {
+		var length, header int
+		switch {
+		case data[start] == 0x78 && data[start+1] == 0x78:
+			if start+2 >= len(data) {
+				return start, nil, nil
+			}
+			length, header = int(data[start+2]), 3
+		case data[start] == 0x79 && data[start+1] == 0x79:
+			if start+3 >= len(data) {
+				return start, nil, nil
+			}
+			length, header = int(binary.BigEndian.Uint16(data[start+2:])), 4
+		default:
+			continue
+		}
+
+		end := start + header + length + 2
+		if end > len(data) {
+			if atEOF {
+				continue // The stream ended, the marker was part of garbage or a truncated frame
+			}
+			return start, nil, nil
+		}
+		if data[end-2] != 0x0D || data[end-1] != 0x0A {
+			continue // Not a frame after all, look for the next start marker
+		}
+		return end, data[start:end], nil
+	}
+
+	if atEOF {
+		return len(data), nil, nil
+	}
+	// Keep a possible first byte of a start marker
+	if len(data) > 1 {
+		return len(data) - 1, nil, nil
+	}
+	return 0, nil, nil
+}
+
+func (gt06) Decode(frame []byte) (*Message, error) {
+	if len(frame) < 10 {
+		return nil, errors.New("gt06: frame too short")
+	}
+	header := 3
+	if frame[0] == 0x79 {
+		header = 4
+	}
+	// Length, type, content and serial number are covered by the checksum
+	if len(frame) < header+7 {
+		return nil, errors.New("gt06: frame too short")
+	}
+	checked := frame[2 : len(frame)-4]
+	if crcITU(checked) != binary.BigEndian.Uint16(frame[len(frame)-4:]) {
+		return nil, errors.New("gt06: checksum mismatch")
+	}
+
+	messageType := frame[header]
+	content := frame[header+1 : len(frame)-6]
+	serial := frame[len(frame)-6 : len(frame)-4]
+
+	message := &Message{}
+	switch messageType {
+	case gt06Login:
+		if len(content) < 8 {
+			return nil, errors.New("gt06: login frame too short")
+		}
+		// BCD encoded terminal ID, the IMEI with a leading zero
+		imei := strings.TrimLeft(hex.EncodeToString(content[:8]), "0")
+		if len(imei) != constants.IMEILength {
+			return nil, fmt.Errorf("gt06: invalid IMEI %q", imei)
+		}
+		message.IMEI = imei
+		message.Reply = gt06Reply(messageType, serial)
+	case gt06GPS, gt06GPS2:
+		position, err := decodeGT06Position(content)
+		if err != nil {
+			return nil, err
+		}
+		message.Position = position
+	case gt06Alarm:
+		position, err := decodeGT06Position(content)
+		if err != nil {
+			return nil, err
+		}
+		position.Event = constants.TrackerEventAlarm
+		message.Position = position
+		message.Reply = gt06Reply(messageType, serial)
+	case gt06Heartbeat:
+		message.Reply = gt06Reply(messageType, serial)
+	}
+	return message, nil
+}
+
+// decodeGT06Position decodes the GPS block that starts the content of position reports
+func decodeGT06Position(content []byte) (*Position, error) {
+	if len(content) < 18 {
+		return nil, errors.New("gt06: position frame too short")
+	}
+
+	timestamp := time.Date(2000+int(content[0]), time.Month(content[1]), int(content[2]),
+		int(content[3]), int(content[4]), int(content[5]), 0, time.UTC)
+
+	// Coordinates are in 1/30000 minutes, the hemispheres are flags of the course field
+	latitude := float64(binary.BigEndian.Uint32(content[7:11])) / 1800000
+	longitude := float64(binary.BigEndian.Uint32(content[11:15])) / 1800000
+	flags := binary.BigEndian.Uint16(content[16:18])
+	if flags&0x0400 == 0 {
+		latitude = -latitude
+	}
+	if flags&0x0800 != 0 {
+		longitude = -longitude
+	}
+
+	return &Position{
+		Time:      timestamp,
+		Valid:     flags&0x1000 != 0,
+		Latitude:  latitude,
+		Longitude: longitude,
+		Speed:     float64(content[15]),
+		SpeedUnit: constants.SpeedUnitKilometersPerHr,
+		Course:    float64(flags & 0x03FF),
+	}, nil
+}
+
+// gt06Reply acknowledges a frame by echoing its type and serial number
+func gt06Reply(messageType byte, serial []byte) []byte {
+	reply := []byte{0x78, 0x78, 0x05, messageType, serial[0], serial[1]}
+	reply = binary.BigEndian.AppendUint16(reply, crcITU(reply[2:]))
+	return append(reply, 0x0D, 0x0A)
+}
+
+// crcITU is the CRC-16/X-25 checksum of GT06 frames
+func crcITU(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0x8408
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return ^crc
+}
@@ -0,0 +1,115 @@
+package protocols
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"vibe-tracker/constants"
+)
+
+// h02 is the text protocol of Sinotrack and other H02 trackers, sent over TCP or UDP:
+//
+//	*HQ,865205030330012,V1,145452,A,2240.55181,N,11358.32389,E,0.00,0,100815,FFFFFBFF#
+//
+// Every frame carries the IMEI. Only V1 position reports are decoded, heartbeats and cell tower
+// reports are accepted without a position. Devices do not expect replies.
+type h02 struct{}
+
+func init() {
+	register(h02{})
+}
+
+func (h02) Name() string {
+	return constants.TrackerProtocolH02
+}
+
+func (h02) Datagrams() bool {
+	return true
+}
+
+func (h02) Split(data []byte, atEOF bool) (int, []byte, error) {
+	start := bytes.IndexByte(data, '*')
+	if start < 0 {
+		return len(data), nil, nil // No frame started, drop the garbage
+	}
+
+	end := bytes.IndexByte(data[start:], '#')
+	if end < 0 {
+		if atEOF {
+			return len(data), nil, nil
+		}
+		return start, nil, nil
+	}
+	end += start + 1
+
+	return end, data[start:end], nil
+}
+
+func (h02) Decode(frame []byte) (*Message, error) {
+	text := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(string(frame)), "*"), "#")
+	fields := strings.Split(text, ",")
+	if len(fields) < 3 {
+		return nil, errors.New("h02: frame too short")
+	}
+
+	imei := fields[1]
+	if len(imei) != constants.IMEILength {
+		return nil, fmt.Errorf("h02: invalid IMEI %q", imei)
+	}
+
+	message := &Message{IMEI: imei}
+	if fields[2] != "V1" {
+		return message, nil
+	}
+	if len(fields) < 12 {
+		return nil, errors.New("h02: position report too short")
+	}
+
+	timestamp, err := time.Parse("020106150405", fields[11]+fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("h02: invalid time: %w", err)
+	}
+	latitude, err := parseNMEACoordinate(fields[5], fields[6], "N", "S")
+	if err != nil {
+		return nil, fmt.Errorf("h02: invalid latitude: %w", err)
+	}
+	longitude, err := parseNMEACoordinate(fields[7], fields[8], "E", "W")
+	if err != nil {
+		return nil, fmt.Errorf("h02: invalid longitude: %w", err)
+	}
+	speed, _ := strconv.ParseFloat(fields[9], 64)
+	course, _ := strconv.ParseFloat(fields[10], 64)
+
+	message.Position = &Position{
+		Time:      timestamp,
+		Valid:     fields[4] == "A",
+		Latitude:  latitude,
+		Longitude: longitude,
+		Speed:     speed,
+		SpeedUnit: constants.SpeedUnitKnots,
+		Course:    course,
+	}
+	return message, nil
+}
+
+// parseNMEACoordinate converts a (d)ddmm.mmmm value and its hemisphere to decimal degrees
+func parseNMEACoordinate(value, hemisphere, positive, negative string) (float64, error) {
+	raw, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	degrees := float64(int(raw/100)) + (raw-float64(int(raw/100))*100)/60
+	switch hemisphere {
+	case positive:
+		return degrees, nil
+	case negative:
+		return -degrees, nil
+	default:
+		return 0, fmt.Errorf("unknown hemisphere %q", hemisphere)
+	}
+}
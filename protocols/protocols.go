@@ -0,0 +1,76 @@
+// Package protocols decodes the socket protocols of cheap dedicated GPS trackers, so they can
+// report to the tracker directly instead of through a Traccar instance. Each protocol cuts frames
+// from the connection stream and decodes them into positions, the listener in handlers maps the
+// IMEI of the device to a user.
+package protocols
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Protocol is a tracker protocol spoken over TCP (and UDP where the devices support it)
+type Protocol interface {
+	// Name identifies the protocol in the configuration and in logs
+	Name() string
+
+	// Split cuts the next frame from a TCP stream, see bufio.SplitFunc. Garbage before a frame is
+	// skipped, so one corrupt frame does not end the connection.
+	Split(data []byte, atEOF bool) (advance int, token []byte, err error)
+
+	// Decode parses a single frame
+	Decode(frame []byte) (*Message, error)
+
+	// Datagrams reports whether devices also send frames as UDP datagrams
+	Datagrams() bool
+}
+
+// Message is a decoded frame
+type Message struct {
+	// IMEI of the device, set by login frames and by protocols that send it with every frame
+	IMEI string
+
+	// Position reported by the frame, nil for frames without one, e.g. heartbeats
+	Position *Position
+
+	// Reply the device expects, nil when the frame is not acknowledged
+	Reply []byte
+}
+
+// Position is a fix reported by a device
+type Position struct {
+	Time      time.Time
+	Valid     bool // The device had a GPS fix; invalid positions repeat the last known fix
+	Latitude  float64
+	Longitude float64
+	Speed     float64 // In SpeedUnit
+	SpeedUnit string
+	Course    float64
+	Event     string // Set for alarm reports
+}
+
+var registry = map[string]Protocol{}
+
+func register(protocol Protocol) {
+	registry[protocol.Name()] = protocol
+}
+
+// ByName returns the protocol with the given name
+func ByName(name string) (Protocol, error) {
+	protocol, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown tracker protocol %q, supported: %v", name, Names())
+	}
+	return protocol, nil
+}
+
+// Names returns the names of the supported protocols
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,159 @@
+package protocols
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	assert.NoError(t, err)
+	return data
+}
+
+func TestByName(t *testing.T) {
+	protocol, err := ByName("gt06")
+	assert.NoError(t, err)
+	assert.Equal(t, "gt06", protocol.Name())
+
+	_, err = ByName("tk103")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"gt06", "h02"}, Names())
+}
+
+func TestGT06Login(t *testing.T) {
+	frame := mustHex(t, "7878 0D 01 0123456789012345 0001 8CDD 0D0A")
+
+	message, err := gt06{}.Decode(frame)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "123456789012345", message.IMEI)
+		assert.Nil(t, message.Position)
+		assert.Equal(t, mustHex(t, "7878 05 01 0001 D9DC 0D0A"), message.Reply)
+	}
+}
+
+func TestGT06Position(t *testing.T) {
+	frame := mustHex(t, "7878 1F 12 0B081D112E10 CF 027AC7EB 0C465849 00 148F 01CC00287D001FB8 0003 8081 0D0A")
+
+	message, err := gt06{}.Decode(frame)
+	if assert.NoError(t, err) && assert.NotNil(t, message.Position) {
+		position := message.Position
+		assert.Equal(t, time.Date(2011, 8, 29, 17, 46, 16, 0, time.UTC), position.Time)
+		assert.True(t, position.Valid)
+		assert.InDelta(t, 23.1116683, position.Latitude, 1e-6)
+		assert.InDelta(t, 114.4092850, position.Longitude, 1e-6)
+		assert.Equal(t, 0.0, position.Speed)
+		assert.Equal(t, "km/h", position.SpeedUnit)
+		assert.Equal(t, 143.0, position.Course)
+		assert.Empty(t, message.IMEI)
+		assert.Nil(t, message.Reply)
+	}
+}
+
+func TestGT06Hemispheres(t *testing.T) {
+	// Same fix with the north flag cleared and the west flag set
+	frame := mustHex(t, "7878 1F 12 0B081D112E10 CF 027AC7EB 0C465849 00 188F 01CC00287D001FB8 0003 0000 0D0A")
+	frame = withGT06Checksum(frame)
+
+	message, err := gt06{}.Decode(frame)
+	if assert.NoError(t, err) {
+		assert.InDelta(t, -23.1116683, message.Position.Latitude, 1e-6)
+		assert.InDelta(t, -114.4092850, message.Position.Longitude, 1e-6)
+	}
+}
+
+func TestGT06Invalid(t *testing.T) {
+	frame := mustHex(t, "7878 0D 01 0123456789012345 0001 8CDE 0D0A")
+	_, err := gt06{}.Decode(frame)
+	assert.Error(t, err, "checksum mismatch")
+
+	_, err = gt06{}.Decode([]byte{0x78, 0x78})
+	assert.Error(t, err)
+}
+
+func TestGT06Split(t *testing.T) {
+	login := mustHex(t, "7878 0D 01 0123456789012345 0001 8CDD 0D0A")
+	heartbeat := withGT06Checksum(mustHex(t, "7878 0A 13 4B 04 03 0001 0002 0000 0D0A"))
+
+	stream := append([]byte{0x00, 0x42, 0x78}, login...)
+	stream = append(stream, heartbeat...)
+
+	scanner := bufio.NewScanner(bytes.NewReader(stream))
+	scanner.Split(gt06{}.Split)
+
+	var frames [][]byte
+	for scanner.Scan() {
+		frames = append(frames, append([]byte(nil), scanner.Bytes()...))
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, [][]byte{login, heartbeat}, frames)
+
+	message, err := gt06{}.Decode(heartbeat)
+	if assert.NoError(t, err) {
+		assert.Nil(t, message.Position)
+		assert.NotNil(t, message.Reply)
+	}
+}
+
+func TestH02Position(t *testing.T) {
+	frame := []byte("*HQ,865205030330012,V1,145452,A,2240.55181,N,11358.32389,W,1.50,90,100815,FFFFFBFF#")
+
+	message, err := h02{}.Decode(frame)
+	if assert.NoError(t, err) && assert.NotNil(t, message.Position) {
+		position := message.Position
+		assert.Equal(t, "865205030330012", message.IMEI)
+		assert.Equal(t, time.Date(2015, 8, 10, 14, 54, 52, 0, time.UTC), position.Time)
+		assert.True(t, position.Valid)
+		assert.InDelta(t, 22.6758635, position.Latitude, 1e-6)
+		assert.InDelta(t, -113.9720648, position.Longitude, 1e-6)
+		assert.Equal(t, 1.5, position.Speed)
+		assert.Equal(t, "knots", position.SpeedUnit)
+		assert.Equal(t, 90.0, position.Course)
+		assert.Nil(t, message.Reply)
+	}
+}
+
+func TestH02Other(t *testing.T) {
+	message, err := h02{}.Decode([]byte("*HQ,865205030330012,XT,1,100815#"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "865205030330012", message.IMEI)
+		assert.Nil(t, message.Position)
+	}
+
+	_, err = h02{}.Decode([]byte("*HQ,1234,V1,145452,A,2240.55181,N,11358.32389,E,0.00,0,100815,FFFFFBFF#"))
+	assert.Error(t, err, "invalid IMEI")
+
+	_, err = h02{}.Decode([]byte("*HQ,865205030330012,V1,145452,A,2240.55181,X,11358.32389,E,0.00,0,100815,FFFFFBFF#"))
+	assert.Error(t, err, "invalid hemisphere")
+}
+
+func TestH02Split(t *testing.T) {
+	stream := "noise*HQ,865205030330012,XT,1#\r\n*HQ,865205030330012,V1,145452,V,2240.55181,N,11358.32389,E,0.00,0,100815,FFFFFBFF#*HQ,8652"
+
+	scanner := bufio.NewScanner(strings.NewReader(stream))
+	scanner.Split(h02{}.Split)
+
+	var frames []string
+	for scanner.Scan() {
+		frames = append(frames, scanner.Text())
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, []string{
+		"*HQ,865205030330012,XT,1#",
+		"*HQ,865205030330012,V1,145452,V,2240.55181,N,11358.32389,E,0.00,0,100815,FFFFFBFF#",
+	}, frames)
+}
+
+// withGT06Checksum replaces the checksum of a short GT06 frame
+func withGT06Checksum(frame []byte) []byte {
+	crc := crcITU(frame[2 : len(frame)-4])
+	frame[len(frame)-4] = byte(crc >> 8)
+	frame[len(frame)-3] = byte(crc)
+	return frame
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/pocketbase/pocketbase/models"
@@ -13,6 +14,7 @@ type UserRepository interface {
 	FindByID(userID string) (*models.Record, error)
 	FindByToken(token string) (*models.Record, error)
 	Save(user *models.Record) error
+	WithContext(ctx context.Context) UserRepository
 }
 
 // SessionRepository defines the interface for session database operations
@@ -26,6 +28,7 @@ type SessionRepository interface {
 	FindByID(sessionID string) (*models.Record, error)
 	GetCollection() (*models.Collection, error)
 	CreateNewRecord() (*models.Record, error)
+	WithContext(ctx context.Context) SessionRepository
 }
 
 // LocationRepository defines the interface for location database operations
@@ -37,6 +40,7 @@ type LocationRepository interface {
 	FindAllLocations(userID, sessionFilter string, fromTime, toTime *time.Time, sort string, limit, offset int) ([]*models.Record, error)
 	GetCollection() (*models.Collection, error)
 	CreateNewRecord() (*models.Record, error)
+	WithContext(ctx context.Context) LocationRepository
 }
 
 // SessionServiceInterface defines the interface for session service operations
@@ -1,19 +1,23 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 
 	"vibe-tracker/constants"
+	"vibe-tracker/utils"
 )
 
 // locationRepository implements LocationRepository interface
 type locationRepository struct {
 	app *pocketbase.PocketBase
+	ctx context.Context // Request context queries run with, nil when unbound
 }
 
 // NewLocationRepository creates a new location repository instance
@@ -21,9 +25,19 @@ func NewLocationRepository(app *pocketbase.PocketBase) LocationRepository {
 	return &locationRepository{app: app}
 }
 
+// WithContext returns a copy of the repository whose queries are aborted when ctx is cancelled
+func (r *locationRepository) WithContext(ctx context.Context) LocationRepository {
+	return &locationRepository{app: r.app, ctx: ctx}
+}
+
+// dao returns the app's Dao bound to the repository's context
+func (r *locationRepository) dao() *daos.Dao {
+	return utils.ContextDao(r.app.Dao(), r.ctx)
+}
+
 // Create creates a new location record
 func (r *locationRepository) Create(location *models.Record) error {
-	return r.app.Dao().SaveRecord(location)
+	return r.dao().SaveRecord(location)
 }
 
 // FindByUser finds locations for a user with optional filters
@@ -49,7 +63,7 @@ func (r *locationRepository) FindByUser(userID string, filters map[string]interf
 		}
 	}
 
-	return r.app.Dao().FindRecordsByFilter(
+	return r.dao().FindRecordsByFilter(
 		constants.CollectionLocations,
 		filter,
 		sort,
@@ -61,7 +75,7 @@ func (r *locationRepository) FindByUser(userID string, filters map[string]interf
 
 // FindByUserWithSession finds locations for a user within a specific session
 func (r *locationRepository) FindByUserWithSession(userID, sessionID string, sort string, limit, offset int) ([]*models.Record, error) {
-	return r.app.Dao().FindRecordsByFilter(
+	return r.dao().FindRecordsByFilter(
 		constants.CollectionLocations,
 		"user = {:user} && session = {:session}",
 		sort,
@@ -73,7 +87,7 @@ func (r *locationRepository) FindByUserWithSession(userID, sessionID string, sor
 
 // GetCollection gets the locations collection
 func (r *locationRepository) GetCollection() (*models.Collection, error) {
-	return r.app.Dao().FindCollectionByNameOrId(constants.CollectionLocations)
+	return r.dao().FindCollectionByNameOrId(constants.CollectionLocations)
 }
 
 // CreateNewRecord creates a new record for the locations collection
@@ -87,7 +101,7 @@ func (r *locationRepository) CreateNewRecord() (*models.Record, error) {
 
 // FindPublicLocations finds locations with public sessions
 func (r *locationRepository) FindPublicLocations(limit, offset int) ([]*models.Record, error) {
-	return r.app.Dao().FindRecordsByFilter(
+	return r.dao().FindRecordsByFilter(
 		constants.CollectionLocations,
 		"user.username != '' AND session != ''",
 		"-timestamp",
@@ -134,7 +148,7 @@ func (r *locationRepository) FindAllLocations(userID, sessionFilter string, from
 		filter = "id != ''"
 	}
 
-	return r.app.Dao().FindRecordsByFilter(
+	return r.dao().FindRecordsByFilter(
 		constants.CollectionLocations,
 		filter,
 		sort,
@@ -1,16 +1,20 @@
 package repositories
 
 import (
+	"context"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 
 	"vibe-tracker/constants"
+	"vibe-tracker/utils"
 )
 
 // sessionRepository implements SessionRepository interface
 type sessionRepository struct {
 	app *pocketbase.PocketBase
+	ctx context.Context // Request context queries run with, nil when unbound
 }
 
 // NewSessionRepository creates a new session repository instance
@@ -18,9 +22,19 @@ func NewSessionRepository(app *pocketbase.PocketBase) SessionRepository {
 	return &sessionRepository{app: app}
 }
 
+// WithContext returns a copy of the repository whose queries are aborted when ctx is cancelled
+func (r *sessionRepository) WithContext(ctx context.Context) SessionRepository {
+	return &sessionRepository{app: r.app, ctx: ctx}
+}
+
+// dao returns the app's Dao bound to the repository's context
+func (r *sessionRepository) dao() *daos.Dao {
+	return utils.ContextDao(r.app.Dao(), r.ctx)
+}
+
 // FindByUser finds sessions for a user with pagination and sorting
 func (r *sessionRepository) FindByUser(userID string, sort string, limit, offset int) ([]*models.Record, error) {
-	return r.app.Dao().FindRecordsByFilter(
+	return r.dao().FindRecordsByFilter(
 		constants.CollectionSessions,
 		"user = {:user}",
 		sort,
@@ -32,7 +46,7 @@ func (r *sessionRepository) FindByUser(userID string, sort string, limit, offset
 
 // CountByUser counts total sessions for a user
 func (r *sessionRepository) CountByUser(userID string) (int, error) {
-	records, err := r.app.Dao().FindRecordsByFilter(
+	records, err := r.dao().FindRecordsByFilter(
 		constants.CollectionSessions,
 		"user = {:user}",
 		"",
@@ -48,33 +62,33 @@ func (r *sessionRepository) CountByUser(userID string) (int, error) {
 
 // Create creates a new session record
 func (r *sessionRepository) Create(session *models.Record) error {
-	return r.app.Dao().SaveRecord(session)
+	return r.dao().SaveRecord(session)
 }
 
 // Update updates an existing session record
 func (r *sessionRepository) Update(session *models.Record) error {
-	return r.app.Dao().SaveRecord(session)
+	return r.dao().SaveRecord(session)
 }
 
 // Delete deletes a session record
 func (r *sessionRepository) Delete(session *models.Record) error {
-	return r.app.Dao().DeleteRecord(session)
+	return r.dao().DeleteRecord(session)
 }
 
 // FindByNameAndUser finds a session by name and user
 func (r *sessionRepository) FindByNameAndUser(name, userID string) (*models.Record, error) {
-	return r.app.Dao().FindFirstRecordByFilter(constants.CollectionSessions, "name = {:name} && user = {:user}",
+	return r.dao().FindFirstRecordByFilter(constants.CollectionSessions, "name = {:name} && user = {:user}",
 		dbx.Params{"name": name, "user": userID})
 }
 
 // FindByID finds a session by ID
 func (r *sessionRepository) FindByID(sessionID string) (*models.Record, error) {
-	return r.app.Dao().FindRecordById(constants.CollectionSessions, sessionID)
+	return r.dao().FindRecordById(constants.CollectionSessions, sessionID)
 }
 
 // GetCollection gets the sessions collection
 func (r *sessionRepository) GetCollection() (*models.Collection, error) {
-	return r.app.Dao().FindCollectionByNameOrId(constants.CollectionSessions)
+	return r.dao().FindCollectionByNameOrId(constants.CollectionSessions)
 }
 
 // CreateNewRecord creates a new record for the sessions collection
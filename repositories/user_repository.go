@@ -1,16 +1,20 @@
 package repositories
 
 import (
+	"context"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 
 	"vibe-tracker/constants"
+	"vibe-tracker/utils"
 )
 
 // userRepository implements UserRepository interface
 type userRepository struct {
 	app *pocketbase.PocketBase
+	ctx context.Context // Request context queries run with, nil when unbound
 }
 
 // NewUserRepository creates a new user repository instance
@@ -18,29 +22,39 @@ func NewUserRepository(app *pocketbase.PocketBase) UserRepository {
 	return &userRepository{app: app}
 }
 
+// WithContext returns a copy of the repository whose queries are aborted when ctx is cancelled
+func (r *userRepository) WithContext(ctx context.Context) UserRepository {
+	return &userRepository{app: r.app, ctx: ctx}
+}
+
+// dao returns the app's Dao bound to the repository's context
+func (r *userRepository) dao() *daos.Dao {
+	return utils.ContextDao(r.app.Dao(), r.ctx)
+}
+
 // FindByUsername finds a user by username
 func (r *userRepository) FindByUsername(username string) (*models.Record, error) {
-	return r.app.Dao().FindFirstRecordByFilter(constants.CollectionUsers, "username = {:username}",
+	return r.dao().FindFirstRecordByFilter(constants.CollectionUsers, "username = {:username}",
 		dbx.Params{"username": username})
 }
 
 // FindByEmail finds a user by email
 func (r *userRepository) FindByEmail(email string) (*models.Record, error) {
-	return r.app.Dao().FindAuthRecordByEmail(constants.CollectionUsers, email)
+	return r.dao().FindAuthRecordByEmail(constants.CollectionUsers, email)
 }
 
 // FindByID finds a user by ID
 func (r *userRepository) FindByID(userID string) (*models.Record, error) {
-	return r.app.Dao().FindRecordById(constants.CollectionUsers, userID)
+	return r.dao().FindRecordById(constants.CollectionUsers, userID)
 }
 
 // FindByToken finds a user by their custom token
 func (r *userRepository) FindByToken(token string) (*models.Record, error) {
-	return r.app.Dao().FindFirstRecordByFilter(constants.CollectionUsers, "token = {:token}",
+	return r.dao().FindFirstRecordByFilter(constants.CollectionUsers, "token = {:token}",
 		dbx.Params{"token": token})
 }
 
 // Save saves a user record
 func (r *userRepository) Save(user *models.Record) error {
-	return r.app.Dao().SaveRecord(user)
+	return r.dao().SaveRecord(user)
 }
@@ -0,0 +1,30 @@
+#!/bin/bash
+
+# Generate the Go code of the gRPC ingestion service from proto/vibetracker/v1/tracking.proto
+# Requires protoc; the Go plugins are installed when missing
+
+set -e
+
+echo "🔧 Generating gRPC code..."
+
+if ! command -v protoc &> /dev/null; then
+    echo "❌ protoc is not installed, see https://grpc.io/docs/protoc-installation/"
+    exit 1
+fi
+
+if ! command -v protoc-gen-go &> /dev/null; then
+    echo "❌ protoc-gen-go is not installed. Installing..."
+    go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.34.0
+fi
+
+if ! command -v protoc-gen-go-grpc &> /dev/null; then
+    echo "❌ protoc-gen-go-grpc is not installed. Installing..."
+    go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.3.0
+fi
+
+protoc --proto_path=proto \
+    --go_out=proto --go_opt=paths=source_relative \
+    --go-grpc_out=proto --go-grpc_opt=paths=source_relative \
+    vibetracker/v1/tracking.proto
+
+echo "✅ gRPC code generated in proto/vibetracker/v1"
@@ -1,11 +1,19 @@
 package services
 
 import (
+	"context"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tokens"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 
+	"vibe-tracker/constants"
 	appmodels "vibe-tracker/models"
 	"vibe-tracker/repositories"
 	"vibe-tracker/utils"
@@ -15,6 +23,7 @@ import (
 type AuthService struct {
 	app      *pocketbase.PocketBase
 	userRepo repositories.UserRepository
+	ctx      context.Context // Request context queries run with, nil when unbound
 }
 
 // NewAuthService creates a new AuthService instance
@@ -25,8 +34,18 @@ func NewAuthService(app *pocketbase.PocketBase, userRepo repositories.UserReposi
 	}
 }
 
-// Login authenticates a user and returns a token and user information
-func (s *AuthService) Login(req appmodels.LoginRequest) (*appmodels.LoginResponse, error) {
+// WithContext returns a copy of the service whose queries are aborted when ctx is cancelled
+func (s *AuthService) WithContext(ctx context.Context) *AuthService {
+	return &AuthService{app: s.app, userRepo: s.userRepo.WithContext(ctx), ctx: ctx}
+}
+
+// dao returns the app's Dao bound to the service's context
+func (s *AuthService) dao() *daos.Dao {
+	return utils.ContextDao(s.app.Dao(), s.ctx)
+}
+
+// Login authenticates a user and returns a token for a new device session and user information
+func (s *AuthService) Login(req appmodels.LoginRequest, client appmodels.ClientInfo) (*appmodels.LoginResponse, error) {
 	// Find user by email
 	record, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
@@ -39,7 +58,7 @@ func (s *AuthService) Login(req appmodels.LoginRequest) (*appmodels.LoginRespons
 	}
 
 	// Generate auth token
-	token, err := tokens.NewRecordAuthToken(s.app, record)
+	token, err := s.IssueToken(record, client, "")
 	if err != nil {
 		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to generate auth token")
 	}
@@ -55,6 +74,133 @@ func (s *AuthService) Login(req appmodels.LoginRequest) (*appmodels.LoginRespons
 	return response, nil
 }
 
+// IssueToken generates an auth token bound to a device session. An empty sessionID starts a new
+// device session; otherwise the existing session is extended, as when a token is refreshed.
+func (s *AuthService) IssueToken(record *models.Record, client appmodels.ClientInfo, sessionID string) (string, error) {
+	dao := s.dao()
+	duration := s.app.Settings().RecordAuthToken.Duration
+
+	var session *models.Record
+	if sessionID != "" {
+		existing, err := dao.FindFirstRecordByFilter(constants.CollectionAuthSessions,
+			"jti = {:jti} && user = {:user} && revoked_at = ''", dbx.Params{"jti": sessionID, "user": record.Id})
+		if err != nil {
+			return "", err
+		}
+		session = existing
+	} else {
+		collection, err := dao.FindCollectionByNameOrId(constants.CollectionAuthSessions)
+		if err != nil {
+			return "", err
+		}
+		session = models.NewRecord(collection)
+		session.Set("user", record.Id)
+		session.Set("jti", security.RandomString(32))
+	}
+
+	now := time.Now()
+	expiresAt, _ := types.ParseDateTime(now.Add(time.Duration(duration) * time.Second))
+	lastSeenAt, _ := types.ParseDateTime(now)
+	session.Set("user_agent", client.UserAgent)
+	session.Set("ip", client.IP)
+	session.Set("last_seen_at", lastSeenAt)
+	session.Set("expires_at", expiresAt)
+	if err := dao.SaveRecord(session); err != nil {
+		return "", err
+	}
+
+	// Same claims as tokens.NewRecordAuthToken plus the device session ID, so PocketBase keeps verifying the token
+	return security.NewJWT(
+		jwt.MapClaims{
+			"id":           record.Id,
+			"type":         tokens.TypeAuthRecord,
+			"collectionId": record.Collection().Id,
+			"jti":          session.GetString("jti"),
+		},
+		record.TokenKey()+s.app.Settings().RecordAuthToken.Secret,
+		duration,
+	)
+}
+
+// ListDeviceSessions returns the user's device sessions, newest first. Without history only
+// sessions that are neither revoked nor expired are returned.
+func (s *AuthService) ListDeviceSessions(userID string, includeHistory bool) ([]*models.Record, error) {
+	filter := "user = {:user}"
+	params := dbx.Params{"user": userID}
+	if !includeHistory {
+		filter += " && revoked_at = '' && expires_at > {:now}"
+		params["now"] = types.NowDateTime()
+	}
+
+	return s.dao().FindRecordsByFilter(constants.CollectionAuthSessions, filter, "-last_seen_at",
+		constants.AuthSessionHistoryLimit, 0, params)
+}
+
+// RevokeDeviceSessions revokes the user's active device sessions with the given record IDs, or all
+// of them except keepSessionID when ids is empty, and returns the revoked sessions
+func (s *AuthService) RevokeDeviceSessions(userID string, ids []string, keepSessionID string) ([]*models.Record, error) {
+	active, err := s.ListDeviceSessions(userID, false)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch device sessions")
+	}
+
+	selected := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	revoked := make([]*models.Record, 0, len(active))
+	now := types.NowDateTime()
+	for _, session := range active {
+		if len(ids) > 0 && !selected[session.Id] {
+			continue
+		}
+		if len(ids) == 0 && session.GetString("jti") == keepSessionID {
+			continue
+		}
+
+		session.Set("revoked_at", now)
+		if err := s.dao().SaveRecord(session); err != nil {
+			return revoked, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to revoke device session")
+		}
+		revoked = append(revoked, session)
+	}
+
+	return revoked, nil
+}
+
+// FindRevokedDeviceSessions returns revoked device sessions whose tokens have not expired yet
+func (s *AuthService) FindRevokedDeviceSessions() ([]*models.Record, error) {
+	return s.dao().FindRecordsByFilter(constants.CollectionAuthSessions,
+		"revoked_at != '' && expires_at > {:now}", "", 0, 0, dbx.Params{"now": types.NowDateTime()})
+}
+
+// Reauthenticate confirms the user's password for the device session, lifting the re-authentication
+// requirement for sensitive actions after a suspicious login
+func (s *AuthService) Reauthenticate(record *models.Record, sessionID, password string) error {
+	if !record.ValidatePassword(password) {
+		return utils.NewAuthenticationError("Invalid credentials", nil)
+	}
+
+	// Tokens issued before device sessions existed are never flagged, so there is nothing to confirm
+	if sessionID == "" {
+		return nil
+	}
+
+	session, err := s.dao().FindFirstRecordByFilter(constants.CollectionAuthSessions,
+		"jti = {:jti} && user = {:user}", dbx.Params{"jti": sessionID, "user": record.Id})
+	if err != nil {
+		return utils.LogAndWrapError(err, utils.ErrorTypeNotFound, "Device session not found", record.Id)
+	}
+
+	session.Set("reauthenticated_at", types.NowDateTime())
+	if err := s.dao().SaveRecord(session); err != nil {
+		return utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to save re-authentication", record.Id)
+	}
+
+	return nil
+}
+
 // UpdateProfile updates user profile information
 func (s *AuthService) UpdateProfile(record *models.Record, req appmodels.UpdateProfileRequest) error {
 	// Update username if provided
@@ -132,6 +278,7 @@ func (s *AuthService) recordToUser(record *models.Record) appmodels.User {
 		Email:                record.Email(),
 		Avatar:               record.GetString("avatar"),
 		DefaultSessionPublic: record.GetBool("default_session_public"),
+		Role:                 utils.NormalizeRole(record.GetString("role")),
 		Created:              record.Created.String(),
 		Updated:              record.Updated.String(),
 	}
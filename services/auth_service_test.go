@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"vibe-tracker/constants"
 	appmodels "vibe-tracker/models"
 	"vibe-tracker/services/mocks"
 )
@@ -56,7 +57,7 @@ func TestAuthService_Login(t *testing.T) {
 		service := NewAuthService(mockApp, mockUserRepo)
 
 		// Execute
-		result, err := service.Login(req)
+		result, err := service.Login(req, appmodels.ClientInfo{})
 
 		// Assert
 		assert.Error(t, err)
@@ -374,5 +375,8 @@ func TestAuthService_recordToUser(t *testing.T) {
 		// Note: Username/Email testing requires complex PocketBase record setup
 		// Avatar field should work since it's accessed via Set/Get
 		assert.Equal(t, "avatar.png", result.Avatar)
+
+		// Users without a stored role are regular users
+		assert.Equal(t, constants.RoleUser, result.Role)
 	})
 }
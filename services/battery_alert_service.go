@@ -0,0 +1,111 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// BatteryAlertService notifies session owners when a live tracker's battery runs low, so support
+// crews can react before the tracker dies
+type BatteryAlertService struct {
+	app      *pocketbase.PocketBase
+	config   *config.TrackingConfig
+	webhooks *WebhookService
+}
+
+// NewBatteryAlertService creates a new BatteryAlertService instance
+func NewBatteryAlertService(app *pocketbase.PocketBase, trackingConfig *config.TrackingConfig, webhooks *WebhookService) *BatteryAlertService {
+	return &BatteryAlertService{
+		app:      app,
+		config:   trackingConfig,
+		webhooks: webhooks,
+	}
+}
+
+// CheckLocation alerts the owner when a saved location of an active session reports a battery
+// level that dropped below the threshold. Alerts go to the channels enabled in the owner's
+// notification preferences; failures are logged only.
+func (s *BatteryAlertService) CheckLocation(user *models.Record, location *models.Record, battery *float64) {
+	if battery == nil || s.config.LowBatteryThreshold <= 0 {
+		return
+	}
+
+	sessionID := location.GetString("session")
+	if sessionID == "" || *battery >= s.config.LowBatteryThreshold {
+		return
+	}
+
+	preferences := trackingDefaultsFromRecord(user).Notifications
+	if !preferences.LowBattery {
+		return
+	}
+
+	dao := s.app.Dao()
+	session, err := dao.FindRecordById(constants.CollectionSessions, sessionID)
+	if err != nil || session.GetString("ended_at") != "" {
+		return
+	}
+
+	var previous *float64
+	earlier, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+		"session = {:session} && id != {:id} && battery > 0 && timestamp <= {:timestamp}", "-timestamp", 1, 0,
+		dbx.Params{"session": sessionID, "id": location.Id, "timestamp": location.GetDateTime("timestamp")})
+	if err != nil {
+		utils.LogWarn().Err(err).Str("session_id", sessionID).Msg("Failed to find previous battery level")
+		return
+	}
+	if len(earlier) > 0 {
+		level := earlier[0].GetFloat("battery")
+		previous = &level
+	}
+
+	if !utils.IsLowBatteryTransition(previous, *battery, s.config.LowBatteryThreshold) {
+		return
+	}
+
+	event := appmodels.LowBatteryEvent{
+		SessionID: sessionID,
+		Session:   session.GetString("name"),
+		UserID:    user.Id,
+		Device:    location.GetString("device"),
+		Battery:   *battery,
+		Threshold: s.config.LowBatteryThreshold,
+		Latitude:  location.GetFloat("latitude"),
+		Longitude: location.GetFloat("longitude"),
+		Timestamp: location.GetDateTime("timestamp").Time(),
+	}
+
+	utils.LogInfo().Str("user_id", user.Id).Str("session_id", sessionID).Float64("battery", *battery).
+		Msg("Tracker battery low")
+
+	s.webhooks.Send(constants.WebhookEventLowBattery, event)
+	if preferences.Email {
+		go s.notify(user, session, event)
+	}
+}
+
+// notify emails the owner about a tracker running low on battery
+func (s *BatteryAlertService) notify(user *models.Record, session *models.Record, event appmodels.LowBatteryEvent) {
+	title := session.GetString("title")
+	if title == "" {
+		title = event.Session
+	}
+
+	text := fmt.Sprintf("Hello %s,\n\nthe tracker of your session %q reported a battery level of %.0f%% "+
+		"at %s, last seen at %.5f, %.5f.\n\nCharge or replace it soon to keep the session tracked.\n",
+		user.Username(), title, event.Battery, event.Timestamp.UTC().Format("2006-01-02 15:04 MST"),
+		event.Latitude, event.Longitude)
+
+	subject := fmt.Sprintf("Low tracker battery: %s", title)
+	if err := sendUserMail(s.app, user, subject, text); err != nil {
+		utils.LogError(err, "low battery notification").Str("user_id", user.Id).Msg("Failed to send notification email")
+	}
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// CheckInService implements a dead man's switch for sessions: once armed with a check-in
+// interval, a session that receives no point within the interval alerts its owner, and after
+// another interval the session's and the owner's verified emergency contacts, with the last known position.
+type CheckInService struct {
+	app      *pocketbase.PocketBase
+	webhooks *WebhookService
+	contacts *EmergencyContactService
+	stop     chan struct{}
+}
+
+// NewCheckInService creates a new CheckInService instance
+func NewCheckInService(app *pocketbase.PocketBase, webhooks *WebhookService, contacts *EmergencyContactService) *CheckInService {
+	return &CheckInService{
+		app:      app,
+		webhooks: webhooks,
+		contacts: contacts,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start runs the periodic check-in monitoring while the server is running
+func (s *CheckInService) Start(e *core.ServeEvent) error {
+	go func() {
+		ticker := time.NewTicker(constants.CheckInCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.CheckSessions(time.Now()); err != nil {
+					utils.LogError(err, "check-in monitoring").Msg("Failed to check armed sessions")
+					utils.ReportJobError(err, "check-in monitoring")
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop ends the periodic check-in monitoring
+func (s *CheckInService) Stop(e *core.TerminateEvent) error {
+	close(s.stop)
+	return nil
+}
+
+// Arm enables missed check-in alerts on a session, starting the first interval now
+func (s *CheckInService) Arm(session *models.Record, req appmodels.ArmCheckInRequest) (*appmodels.CheckInStatus, error) {
+	contacts := req.Contacts
+	if contacts == nil {
+		contacts = []string{}
+	}
+
+	session.Set("checkin_interval", req.IntervalMinutes)
+	session.Set("checkin_armed_at", types.NowDateTime())
+	session.Set("checkin_contacts", contacts)
+	session.Set("checkin_alert_level", constants.CheckInAlertNone)
+	if err := s.app.Dao().SaveRecord(session); err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to arm check-in")
+	}
+
+	return s.Status(session)
+}
+
+// Disarm turns off missed check-in alerts on a session
+func (s *CheckInService) Disarm(session *models.Record) error {
+	session.Set("checkin_interval", 0)
+	session.Set("checkin_alert_level", constants.CheckInAlertNone)
+	if err := s.app.Dao().SaveRecord(session); err != nil {
+		return utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to disarm check-in")
+	}
+	return nil
+}
+
+// Status returns the check-in state of a session
+func (s *CheckInService) Status(session *models.Record) (*appmodels.CheckInStatus, error) {
+	status := &appmodels.CheckInStatus{
+		IntervalMinutes: session.GetInt("checkin_interval"),
+		AlertLevel:      session.GetInt("checkin_alert_level"),
+		Contacts:        checkInContacts(session),
+	}
+	status.Armed = status.IntervalMinutes > 0
+	if !status.Armed {
+		return status, nil
+	}
+
+	armedAt := session.GetDateTime("checkin_armed_at").Time()
+	status.ArmedAt = &armedAt
+
+	last, err := s.lastPoint(session)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to find latest session location")
+	}
+	var lastPointAt time.Time
+	if last != nil {
+		lastPointAt = last.GetDateTime("timestamp").Time()
+		status.LastPointAt = &lastPointAt
+	}
+
+	deadline := utils.CheckInDeadline(armedAt, lastPointAt, checkInInterval(session))
+	status.Deadline = &deadline
+
+	return status, nil
+}
+
+// CheckSessions escalates alerts of armed sessions that missed their check-in and resets the
+// alert level of sessions that received a point again
+func (s *CheckInService) CheckSessions(now time.Time) error {
+	sessions, err := s.app.Dao().FindRecordsByFilter(constants.CollectionSessions,
+		"checkin_interval > 0 && ended_at = ''", "updated", 0, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := s.checkSession(session, now); err != nil {
+			utils.LogWarn().Err(err).Str("session_id", session.Id).Msg("Failed to check session check-in")
+		}
+	}
+	return nil
+}
+
+func (s *CheckInService) checkSession(session *models.Record, now time.Time) error {
+	last, err := s.lastPoint(session)
+	if err != nil {
+		return err
+	}
+
+	var lastPointAt time.Time
+	if last != nil {
+		lastPointAt = last.GetDateTime("timestamp").Time()
+	}
+
+	interval := checkInInterval(session)
+	deadline := utils.CheckInDeadline(session.GetDateTime("checkin_armed_at").Time(), lastPointAt, interval)
+	level := utils.CheckInAlertLevel(deadline, now, interval)
+	current := session.GetInt("checkin_alert_level")
+	if level == current {
+		return nil
+	}
+
+	session.Set("checkin_alert_level", level)
+	if err := s.app.Dao().SaveRecord(session); err != nil {
+		return err
+	}
+
+	// A lower level means a point arrived, the session checked in again
+	if level < current {
+		utils.LogInfo().Str("session_id", session.Id).Msg("Session checked in again after missed check-in")
+		return nil
+	}
+
+	user, err := s.app.Dao().FindRecordById(constants.CollectionUsers, session.GetString("user"))
+	if err != nil {
+		return err
+	}
+
+	event := appmodels.CheckInMissedEvent{
+		SessionID:   session.Id,
+		Session:     session.GetString("name"),
+		UserID:      user.Id,
+		AlertLevel:  level,
+		Deadline:    deadline,
+		LastPointAt: lastPointAt,
+	}
+	if last != nil {
+		event.Latitude = last.GetFloat("latitude")
+		event.Longitude = last.GetFloat("longitude")
+		event.MapURL = utils.MapLink(event.Latitude, event.Longitude)
+	}
+
+	utils.LogWarn().Str("session_id", session.Id).Str("user_id", user.Id).Int("alert_level", level).
+		Msg("Session missed its check-in")
+
+	// Contacts notified by SMS are passed on to the SMS gateway receiving the webhook
+	var contacts []appmodels.EmergencyContact
+	if level >= constants.CheckInAlertContacts {
+		if contacts, err = s.contacts.Active(user.Id); err != nil {
+			utils.LogWarn().Err(err).Str("user_id", user.Id).Msg("Failed to find emergency contacts")
+		}
+		for _, contact := range contacts {
+			if contact.Channel == constants.ContactChannelSMS {
+				event.SMSRecipients = append(event.SMSRecipients, contact.Phone)
+			}
+		}
+	}
+
+	s.webhooks.Send(constants.WebhookEventCheckInMissed, event)
+	go s.notify(user, session, event, current, contacts)
+
+	return nil
+}
+
+// notify emails everyone due at the reached alert level that was not alerted before: the owner
+// first, then the emergency contacts. Levels can be skipped when monitoring was not running.
+func (s *CheckInService) notify(user *models.Record, session *models.Record, event appmodels.CheckInMissedEvent, previousLevel int,
+	contacts []appmodels.EmergencyContact) {
+	title := session.GetString("title")
+	if title == "" {
+		title = event.Session
+	}
+
+	where := "No position was recorded since the check-in was armed."
+	if event.MapURL != "" {
+		where = fmt.Sprintf("The last known position is %.5f, %.5f, recorded at %s: %s",
+			event.Latitude, event.Longitude, event.LastPointAt.UTC().Format("2006-01-02 15:04 MST"), event.MapURL)
+	}
+	deadline := event.Deadline.UTC().Format("2006-01-02 15:04 MST")
+
+	if previousLevel < constants.CheckInAlertOwner {
+		text := fmt.Sprintf("Hello %s,\n\nyour session %q expected a check-in by %s, but no location arrived.\n\n%s\n\n"+
+			"Send a location or disarm the check-in, otherwise your emergency contacts will be alerted.\n",
+			user.Username(), title, deadline, where)
+		if err := sendUserMail(s.app, user, fmt.Sprintf("Missed check-in: %s", title), text); err != nil {
+			utils.LogError(err, "missed check-in notification").Str("user_id", user.Id).Msg("Failed to send notification email")
+		}
+	}
+
+	if event.AlertLevel < constants.CheckInAlertContacts {
+		return
+	}
+
+	text := fmt.Sprintf("Hello,\n\n%s listed you as an emergency contact for their tracked session %q. "+
+		"It expected a check-in by %s and has not received a location since.\n\n%s\n\n"+
+		"Please try to reach %s.\n", user.Username(), title, deadline, where, user.Username())
+	subject := fmt.Sprintf("Missed check-in by %s", user.Username())
+	for _, to := range checkInRecipients(session, contacts) {
+		if err := sendMail(s.app, to, subject, text); err != nil {
+			utils.LogError(err, "missed check-in notification").Str("session_id", session.Id).Msg("Failed to alert emergency contact")
+		}
+	}
+}
+
+// lastPoint returns the latest location of a session, or nil when it has none
+func (s *CheckInService) lastPoint(session *models.Record) (*models.Record, error) {
+	locations, err := s.app.Dao().FindRecordsByFilter(constants.CollectionLocations,
+		"session = {:session}", "-timestamp", 1, 0, dbx.Params{"session": session.Id})
+	if err != nil || len(locations) == 0 {
+		return nil, err
+	}
+	return locations[0], nil
+}
+
+func checkInInterval(session *models.Record) time.Duration {
+	return time.Duration(session.GetInt("checkin_interval")) * time.Minute
+}
+
+// checkInRecipients returns the addresses of the session's contacts and the owner's verified
+// emergency contacts notified by email, each address once
+func checkInRecipients(session *models.Record, contacts []appmodels.EmergencyContact) []mail.Address {
+	var recipients []mail.Address
+	seen := map[string]bool{}
+	add := func(address mail.Address) {
+		if key := strings.ToLower(address.Address); !seen[key] {
+			seen[key] = true
+			recipients = append(recipients, address)
+		}
+	}
+
+	for _, contact := range contacts {
+		if contact.Channel == constants.ContactChannelEmail {
+			add(mail.Address{Name: contact.Name, Address: contact.Email})
+		}
+	}
+	for _, email := range checkInContacts(session) {
+		add(mail.Address{Address: email})
+	}
+	return recipients
+}
+
+// checkInContacts reads the emergency contact addresses stored on a session
+func checkInContacts(session *models.Record) []string {
+	contacts := []string{}
+	if raw := session.GetString("checkin_contacts"); raw != "" && raw != "null" {
+		if err := json.Unmarshal([]byte(raw), &contacts); err != nil {
+			utils.LogWarn().Err(err).Str("session_id", session.Id).Msg("Ignoring invalid check-in contacts")
+		}
+	}
+	return contacts
+}
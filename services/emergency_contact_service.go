@@ -0,0 +1,237 @@
+package services
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// EmergencyContactService manages the emergency contacts of users. A contact confirms its email
+// address through a link sent when it is added, only confirmed contacts are alerted.
+type EmergencyContactService struct {
+	app *pocketbase.PocketBase
+}
+
+// NewEmergencyContactService creates a new EmergencyContactService instance
+func NewEmergencyContactService(app *pocketbase.PocketBase) *EmergencyContactService {
+	return &EmergencyContactService{
+		app: app,
+	}
+}
+
+// List returns all emergency contacts of a user
+func (s *EmergencyContactService) List(userID string) ([]appmodels.EmergencyContact, error) {
+	records, err := s.findByUser(userID)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch emergency contacts", userID)
+	}
+
+	contacts := make([]appmodels.EmergencyContact, len(records))
+	for i, record := range records {
+		contacts[i] = recordToEmergencyContact(record)
+	}
+	return contacts, nil
+}
+
+// Active returns the confirmed emergency contacts of a user, the ones alerts are sent to
+func (s *EmergencyContactService) Active(userID string) ([]appmodels.EmergencyContact, error) {
+	records, err := s.app.Dao().FindRecordsByFilter(constants.CollectionEmergencyContacts,
+		"user = {:user} && verified_at != ''", "created", 0, 0, dbx.Params{"user": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	contacts := make([]appmodels.EmergencyContact, len(records))
+	for i, record := range records {
+		contacts[i] = recordToEmergencyContact(record)
+	}
+	return contacts, nil
+}
+
+// Create adds an emergency contact and sends it a verification email
+func (s *EmergencyContactService) Create(user *models.Record, req appmodels.EmergencyContactRequest) (*appmodels.EmergencyContact, error) {
+	existing, err := s.findByUser(user.Id)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch emergency contacts", user.Id)
+	}
+	if len(existing) >= constants.MaxEmergencyContacts {
+		return nil, utils.NewValidationError(fmt.Sprintf("At most %d emergency contacts are allowed", constants.MaxEmergencyContacts))
+	}
+	for _, contact := range existing {
+		if strings.EqualFold(contact.GetString("email"), req.Email) {
+			return nil, utils.NewConflictError("Emergency contact already exists", req.Email)
+		}
+	}
+
+	collection, err := s.app.Dao().FindCollectionByNameOrId(constants.CollectionEmergencyContacts)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Emergency contacts collection not found")
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("user", user.Id)
+	setEmergencyContactFields(record, req)
+
+	return s.saveAndVerify(user, record)
+}
+
+// Update changes an emergency contact of a user. Changing the email address requires a new verification.
+func (s *EmergencyContactService) Update(user *models.Record, id string, req appmodels.EmergencyContactRequest) (*appmodels.EmergencyContact, error) {
+	record, err := s.find(user.Id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	emailChanged := !strings.EqualFold(record.GetString("email"), req.Email)
+	setEmergencyContactFields(record, req)
+	if !emailChanged {
+		if err := s.app.Dao().SaveRecord(record); err != nil {
+			return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to update emergency contact", user.Id)
+		}
+		contact := recordToEmergencyContact(record)
+		return &contact, nil
+	}
+
+	record.Set("verified_at", nil)
+	return s.saveAndVerify(user, record)
+}
+
+// Delete removes an emergency contact of a user
+func (s *EmergencyContactService) Delete(userID, id string) error {
+	record, err := s.find(userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.app.Dao().DeleteRecord(record); err != nil {
+		return utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to delete emergency contact", userID)
+	}
+	return nil
+}
+
+// ResendVerification sends a new verification email to an unconfirmed emergency contact
+func (s *EmergencyContactService) ResendVerification(user *models.Record, id string) error {
+	record, err := s.find(user.Id, id)
+	if err != nil {
+		return err
+	}
+
+	if !record.GetDateTime("verified_at").IsZero() {
+		return utils.NewConflictError("Emergency contact is already verified", id)
+	}
+	if sentAt := record.GetDateTime("verification_sent_at").Time(); time.Since(sentAt) < constants.ContactVerificationResendInterval {
+		return utils.NewRateLimitError("A verification email was sent recently, try again later")
+	}
+
+	_, err = s.saveAndVerify(user, record)
+	return err
+}
+
+// Verify confirms the emergency contact a verification token was sent to
+func (s *EmergencyContactService) Verify(token string) (*appmodels.EmergencyContact, error) {
+	if len(token) != constants.ContactVerificationTokenLength {
+		return nil, utils.NewNotFoundError("Verification link", "")
+	}
+
+	record, err := s.app.Dao().FindFirstRecordByFilter(constants.CollectionEmergencyContacts,
+		"verification_token = {:token}", dbx.Params{"token": token})
+	if err != nil {
+		return nil, utils.NewNotFoundError("Verification link", "")
+	}
+
+	if time.Since(record.GetDateTime("verification_sent_at").Time()) > constants.ContactVerificationTTL {
+		return nil, utils.NewValidationError("Verification link expired", "ask for a new verification email")
+	}
+
+	record.Set("verified_at", types.NowDateTime())
+	record.Set("verification_token", "")
+	if err := s.app.Dao().SaveRecord(record); err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to verify emergency contact")
+	}
+
+	utils.LogInfo().Str("contact_id", record.Id).Str("user_id", record.GetString("user")).Msg("Emergency contact verified")
+
+	contact := recordToEmergencyContact(record)
+	return &contact, nil
+}
+
+// saveAndVerify issues a new verification token, saves the contact and emails it the verification link
+func (s *EmergencyContactService) saveAndVerify(user *models.Record, record *models.Record) (*appmodels.EmergencyContact, error) {
+	token := security.RandomString(constants.ContactVerificationTokenLength)
+	record.Set("verification_token", token)
+	record.Set("verification_sent_at", types.NowDateTime())
+	if err := s.app.Dao().SaveRecord(record); err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to save emergency contact", user.Id)
+	}
+
+	contact := recordToEmergencyContact(record)
+
+	// Sending mail may take a while, the response should not wait for it
+	go s.sendVerification(user, contact, token)
+
+	return &contact, nil
+}
+
+func (s *EmergencyContactService) sendVerification(user *models.Record, contact appmodels.EmergencyContact, token string) {
+	meta := s.app.Settings().Meta
+	link := strings.TrimRight(meta.AppUrl, "/") + "/api/emergency-contacts/verify/" + token
+
+	text := fmt.Sprintf("Hello %s,\n\n%s added you as an emergency contact on %s. You would be alerted with their "+
+		"last known position if one of their tracked sessions stops checking in.\n\nTo agree, confirm your "+
+		"email address within %d days: %s\n\nIf you do not know %s, ignore this email and you will not be contacted.\n",
+		contact.Name, user.Username(), meta.AppName, int(constants.ContactVerificationTTL.Hours()/24), link, user.Username())
+
+	subject := fmt.Sprintf("%s added you as an emergency contact", user.Username())
+	if err := sendMail(s.app, mail.Address{Name: contact.Name, Address: contact.Email}, subject, text); err != nil {
+		utils.LogError(err, "emergency contact verification").Str("contact_id", contact.ID).Msg("Failed to send verification email")
+	}
+}
+
+func (s *EmergencyContactService) findByUser(userID string) ([]*models.Record, error) {
+	return s.app.Dao().FindRecordsByFilter(constants.CollectionEmergencyContacts,
+		"user = {:user}", "created", 0, 0, dbx.Params{"user": userID})
+}
+
+// find returns an emergency contact owned by the user
+func (s *EmergencyContactService) find(userID, id string) (*models.Record, error) {
+	record, err := s.app.Dao().FindRecordById(constants.CollectionEmergencyContacts, id)
+	if err != nil || record.GetString("user") != userID {
+		return nil, utils.NewNotFoundError("Emergency contact", id)
+	}
+	return record, nil
+}
+
+func setEmergencyContactFields(record *models.Record, req appmodels.EmergencyContactRequest) {
+	record.Set("name", req.Name)
+	record.Set("email", req.Email)
+	record.Set("phone", req.Phone)
+	record.Set("channel", req.Channel)
+}
+
+func recordToEmergencyContact(record *models.Record) appmodels.EmergencyContact {
+	contact := appmodels.EmergencyContact{
+		ID:      record.Id,
+		Name:    record.GetString("name"),
+		Email:   record.GetString("email"),
+		Phone:   record.GetString("phone"),
+		Channel: record.GetString("channel"),
+		Created: record.GetDateTime("created").Time(),
+	}
+	if verifiedAt := record.GetDateTime("verified_at"); !verifiedAt.IsZero() {
+		t := verifiedAt.Time()
+		contact.Verified = true
+		contact.VerifiedAt = &t
+	}
+	return contact
+}
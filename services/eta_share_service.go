@@ -0,0 +1,230 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// ETAShareService manages temporary links that share a user's live position and estimated
+// arrival at a destination. A share ends when the user arrives or when it expires.
+type ETAShareService struct {
+	app *pocketbase.PocketBase
+}
+
+// NewETAShareService creates a new ETAShareService instance
+func NewETAShareService(app *pocketbase.PocketBase) *ETAShareService {
+	return &ETAShareService{
+		app: app,
+	}
+}
+
+// List returns the ETA shares of a user, newest first
+func (s *ETAShareService) List(userID string) ([]appmodels.ETAShare, error) {
+	records, err := s.app.Dao().FindRecordsByFilter(constants.CollectionETAShares,
+		"user = {:user}", "-created", 0, 0, dbx.Params{"user": userID})
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch ETA shares", userID)
+	}
+
+	shares := make([]appmodels.ETAShare, len(records))
+	for i, record := range records {
+		shares[i] = s.recordToETAShare(record, time.Now())
+	}
+	return shares, nil
+}
+
+// Create starts sharing the user's live position and ETA to a destination
+func (s *ETAShareService) Create(user *models.Record, req appmodels.CreateETAShareRequest) (*appmodels.ETAShare, error) {
+	now := time.Now()
+	active, err := s.findActive(user.Id, now)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch ETA shares", user.Id)
+	}
+	if len(active) >= constants.MaxActiveETAShares {
+		return nil, utils.NewValidationError(fmt.Sprintf("At most %d ETA shares can be active at a time", constants.MaxActiveETAShares))
+	}
+
+	collection, err := s.app.Dao().FindCollectionByNameOrId(constants.CollectionETAShares)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "ETA shares collection not found")
+	}
+
+	radius := req.ArrivalRadius
+	if radius == 0 {
+		radius = constants.DefaultArrivalRadius
+	}
+	expiresAt, _ := types.ParseDateTime(now.Add(time.Duration(req.DurationMinutes) * time.Minute))
+
+	record := models.NewRecord(collection)
+	record.Set("user", user.Id)
+	record.Set("token", security.RandomString(constants.ETAShareTokenLength))
+	record.Set("latitude", req.Latitude)
+	record.Set("longitude", req.Longitude)
+	record.Set("name", req.Name)
+	record.Set("arrival_radius", radius)
+	record.Set("expires_at", expiresAt)
+	if err := s.app.Dao().SaveRecord(record); err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to create ETA share", user.Id)
+	}
+
+	share := s.recordToETAShare(record, now)
+	return &share, nil
+}
+
+// Delete ends an ETA share of a user before it expires
+func (s *ETAShareService) Delete(userID, id string) error {
+	record, err := s.app.Dao().FindRecordById(constants.CollectionETAShares, id)
+	if err != nil || record.GetString("user") != userID {
+		return utils.NewNotFoundError("ETA share", id)
+	}
+
+	if err := s.app.Dao().DeleteRecord(record); err != nil {
+		return utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to delete ETA share", userID)
+	}
+	return nil
+}
+
+// View returns what viewers of an ETA share link see: the latest position and the estimated
+// arrival while the share is active, only its final state afterwards
+func (s *ETAShareService) View(token string) (*appmodels.ETAShareView, error) {
+	if len(token) != constants.ETAShareTokenLength {
+		return nil, utils.NewNotFoundError("ETA share", "")
+	}
+
+	dao := s.app.Dao()
+	record, err := dao.FindFirstRecordByFilter(constants.CollectionETAShares, "token = {:token}", dbx.Params{"token": token})
+	if err != nil {
+		return nil, utils.NewNotFoundError("ETA share", "")
+	}
+
+	user, err := dao.FindRecordById(constants.CollectionUsers, record.GetString("user"))
+	if err != nil {
+		return nil, utils.NewNotFoundError("ETA share", "")
+	}
+
+	share := s.recordToETAShare(record, time.Now())
+	view := &appmodels.ETAShareView{
+		Username:    user.Username(),
+		Destination: share.Destination,
+		Status:      share.Status,
+		ExpiresAt:   share.ExpiresAt,
+		ArrivedAt:   share.ArrivedAt,
+	}
+	if share.Status != constants.ETAShareStatusActive {
+		return view, nil
+	}
+
+	locations, err := dao.FindRecordsByFilter(constants.CollectionLocations, "user = {:user}", "-timestamp",
+		constants.ETARecentPoints, 0, dbx.Params{"user": user.Id})
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch latest locations", user.Id)
+	}
+	if len(locations) == 0 {
+		return view, nil
+	}
+
+	// Oldest first, as the estimate expects
+	points := make([]utils.TimedPoint, len(locations))
+	for i, location := range locations {
+		points[len(locations)-1-i] = utils.TimedPoint{
+			Timestamp: location.GetDateTime("timestamp").Time(),
+			Latitude:  location.GetFloat("latitude"),
+			Longitude: location.GetFloat("longitude"),
+		}
+	}
+
+	latest := points[len(points)-1]
+	estimate := utils.EstimateArrival(points, share.Destination.Latitude, share.Destination.Longitude)
+	view.Position = &appmodels.ETAPosition{Latitude: latest.Latitude, Longitude: latest.Longitude, Timestamp: latest.Timestamp}
+	view.Distance = &estimate.Distance
+	if estimate.ArrivalAt != nil {
+		seconds := int(estimate.Remaining.Seconds())
+		view.Speed = &estimate.Speed
+		view.ETASeconds = &seconds
+		view.ETA = estimate.ArrivalAt
+	}
+
+	return view, nil
+}
+
+// CheckArrival ends the active ETA shares of a location's user whose destination the location
+// reached. Locations recorded before a share was created, e.g. flushed late by an offline device,
+// are not arrivals. It runs after a location is stored and never fails the insert.
+func (s *ETAShareService) CheckArrival(e *core.ModelEvent) error {
+	location, ok := e.Model.(*models.Record)
+	if !ok {
+		return nil
+	}
+
+	shares, err := s.findActive(location.GetString("user"), time.Now())
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Failed to find active ETA shares")
+		return nil
+	}
+
+	timestamp := location.GetDateTime("timestamp").Time()
+	for _, share := range shares {
+		if timestamp.Before(share.Created.Time()) {
+			continue
+		}
+		distance := utils.HaversineDistance(location.GetFloat("latitude"), location.GetFloat("longitude"),
+			share.GetFloat("latitude"), share.GetFloat("longitude"))
+		if distance > share.GetFloat("arrival_radius") {
+			continue
+		}
+
+		share.Set("arrived_at", location.GetDateTime("timestamp"))
+		if err := s.app.Dao().SaveRecord(share); err != nil {
+			utils.LogWarn().Err(err).Str("share_id", share.Id).Msg("Failed to end ETA share on arrival")
+			continue
+		}
+		utils.LogInfo().Str("share_id", share.Id).Str("user_id", share.GetString("user")).Msg("ETA share ended on arrival")
+	}
+
+	return nil
+}
+
+func (s *ETAShareService) findActive(userID string, now time.Time) ([]*models.Record, error) {
+	nowDate, _ := types.ParseDateTime(now)
+	return s.app.Dao().FindRecordsByFilter(constants.CollectionETAShares,
+		"user = {:user} && arrived_at = '' && expires_at > {:now}", "", 0, 0,
+		dbx.Params{"user": userID, "now": nowDate})
+}
+
+func (s *ETAShareService) recordToETAShare(record *models.Record, now time.Time) appmodels.ETAShare {
+	share := appmodels.ETAShare{
+		ID:    record.Id,
+		Token: record.GetString("token"),
+		URL:   strings.TrimRight(s.app.Settings().Meta.AppUrl, "/") + "/api/eta/" + record.GetString("token"),
+		Destination: appmodels.ETADestination{
+			Latitude:  record.GetFloat("latitude"),
+			Longitude: record.GetFloat("longitude"),
+			Name:      record.GetString("name"),
+		},
+		ArrivalRadius: record.GetFloat("arrival_radius"),
+		Status:        constants.ETAShareStatusActive,
+		ExpiresAt:     record.GetDateTime("expires_at").Time(),
+		Created:       record.GetDateTime("created").Time(),
+	}
+
+	if arrivedAt := record.GetDateTime("arrived_at"); !arrivedAt.IsZero() {
+		t := arrivedAt.Time()
+		share.ArrivedAt = &t
+		share.Status = constants.ETAShareStatusArrived
+	} else if !now.Before(share.ExpiresAt) {
+		share.Status = constants.ETAShareStatusExpired
+	}
+	return share
+}
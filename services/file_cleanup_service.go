@@ -0,0 +1,223 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// FileCleanupService removes stored files together with the records they belong to. PocketBase
+// deletes the files of a record's file fields, e.g. waypoint photos, session covers and GPX uploads,
+// with the record; GPX uploads made before they were stored in the gpx_track field are kept at the
+// root of the storage and are deleted by this service.
+// A daily scan reports files left behind anyway, e.g. by a failed S3 request, and deletes them
+// when configured to.
+type FileCleanupService struct {
+	app           *pocketbase.PocketBase
+	deleteOrphans bool
+	stop          chan struct{}
+}
+
+// NewFileCleanupService creates a new FileCleanupService instance; orphaned files found by the
+// daily scan are only reported unless deleteOrphans is set
+func NewFileCleanupService(app *pocketbase.PocketBase, deleteOrphans bool) *FileCleanupService {
+	return &FileCleanupService{
+		app:           app,
+		deleteOrphans: deleteOrphans,
+		stop:          make(chan struct{}),
+	}
+}
+
+// SessionDeleted deletes the GPX file of a deleted session that was uploaded to the root of the
+// storage. Failures are logged only, the file is reported by the next orphan scan.
+func (s *FileCleanupService) SessionDeleted(e *core.ModelEvent) error {
+	session, ok := e.Model.(*models.Record)
+	if !ok {
+		return nil
+	}
+	s.DeleteFile(utils.LegacyGPXTrackKey(session.Id, session.GetString("gpx_track")))
+	return nil
+}
+
+// DeleteFile deletes a stored file by its key, if it exists. Failures are logged only.
+func (s *FileCleanupService) DeleteFile(key string) {
+	if key == "" {
+		return
+	}
+
+	fs, err := s.app.NewFilesystem()
+	if err != nil {
+		utils.LogError(err, "file cleanup").Str("key", key).Msg("Failed to open file storage")
+		return
+	}
+	defer fs.Close()
+
+	if exists, err := fs.Exists(key); err != nil || !exists {
+		return
+	}
+	if err := fs.Delete(key); err != nil {
+		utils.LogError(err, "file cleanup").Str("key", key).Msg("Failed to delete stored file")
+	}
+}
+
+// Start runs the daily orphan scan while the server is running
+func (s *FileCleanupService) Start(e *core.ServeEvent) error {
+	go func() {
+		ticker := time.NewTicker(constants.OrphanScanInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				report, err := s.ScanOrphanedFiles(time.Now(), s.deleteOrphans)
+				if err != nil {
+					utils.LogError(err, "orphan scan").Msg("Failed to scan the file storage")
+					utils.ReportJobError(err, "orphan scan")
+					continue
+				}
+				if len(report.Orphans) > 0 {
+					utils.LogWarn().
+						Int("orphans", len(report.Orphans)).
+						Int64("size", report.OrphanedSize).
+						Int("deleted", report.Deleted).
+						Msg("Found orphaned files in the file storage")
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop ends the daily orphan scan
+func (s *FileCleanupService) Stop(e *core.TerminateEvent) error {
+	close(s.stop)
+	return nil
+}
+
+// ScanOrphanedFiles lists the stored files that belong to a deleted collection or record, or are
+// no longer listed by their record, and deletes them when remove is set. Files younger than
+// constants.OrphanFileMinAge are skipped, their record may not be saved yet.
+func (s *FileCleanupService) ScanOrphanedFiles(now time.Time, remove bool) (*appmodels.OrphanedFilesReport, error) {
+	dao := s.app.Dao()
+
+	var collections []*models.Collection
+	if err := dao.CollectionQuery().All(&collections); err != nil {
+		return nil, err
+	}
+	collectionsByID := make(map[string]*models.Collection, len(collections))
+	for _, collection := range collections {
+		collectionsByID[collection.Id] = collection
+	}
+
+	// Older GPX uploads are stored at the root of the storage under the key kept in gpx_track
+	var gpxKeys []string
+	err := dao.DB().Select("gpx_track").From(constants.CollectionSessions).
+		Where(dbx.NewExp("gpx_track != ''")).Column(&gpxKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	fs, err := s.app.NewFilesystem()
+	if err != nil {
+		return nil, err
+	}
+	defer fs.Close()
+
+	files, err := fs.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &appmodels.OrphanedFilesReport{ScannedAt: now, Orphans: []appmodels.OrphanedFile{}}
+	records := map[string]*models.Record{} // Looked up records by collection and record ID, nil when deleted
+	cutoff := now.Add(-constants.OrphanFileMinAge)
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+		report.ScannedFiles++
+		if file.ModTime.After(cutoff) {
+			continue
+		}
+
+		reason := ""
+		if stored, ok := utils.ParseStorageKey(file.Key); !ok {
+			if !slices.Contains(gpxKeys, file.Key) {
+				reason = constants.OrphanReasonUnreferenced
+			}
+		} else if collection := collectionsByID[stored.CollectionID]; collection == nil {
+			reason = constants.OrphanReasonCollectionDeleted
+		} else {
+			recordKey := stored.CollectionID + "/" + stored.RecordID
+			record, seen := records[recordKey]
+			if !seen {
+				record, err = findStoredFileRecord(dao, collection, stored.RecordID)
+				if err != nil {
+					return nil, err
+				}
+				records[recordKey] = record
+			}
+
+			if record == nil {
+				reason = constants.OrphanReasonRecordDeleted
+			} else if !recordListsFile(record, stored.Name) {
+				reason = constants.OrphanReasonUnreferenced
+			}
+		}
+		if reason == "" {
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, appmodels.OrphanedFile{
+			Key:      file.Key,
+			Size:     file.Size,
+			Modified: file.ModTime,
+			Reason:   reason,
+		})
+		report.OrphanedSize += file.Size
+
+		if remove {
+			if err := fs.Delete(file.Key); err != nil {
+				utils.LogWarn().Err(err).Str("key", file.Key).Msg("Failed to delete orphaned file")
+				continue
+			}
+			report.Deleted++
+		}
+	}
+
+	return report, nil
+}
+
+// findStoredFileRecord returns the record stored files belong to, or nil when it was deleted
+func findStoredFileRecord(dao *daos.Dao, collection *models.Collection, recordID string) (*models.Record, error) {
+	record, err := dao.FindRecordById(collection.Id, recordID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return record, err
+}
+
+// recordListsFile reports whether a file field of a record holds the file name
+func recordListsFile(record *models.Record, name string) bool {
+	for _, field := range record.Collection().Schema.Fields() {
+		if field.Type == schema.FieldTypeFile && slices.Contains(record.GetStringSlice(field.Name), name) {
+			return true
+		}
+	}
+	return false
+}
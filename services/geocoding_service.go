@@ -0,0 +1,245 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// GeocodingService assigns stored locations their country and region through an external reverse
+// geocoding service and computes the countries users visited from them. A background job works
+// through locations not yet geocoded, looking up each grid cell once.
+type GeocodingService struct {
+	app       *pocketbase.PocketBase
+	lookupURL string
+	client    *http.Client
+	stop      chan struct{}
+
+	mu    sync.Mutex
+	cache map[string]utils.Place
+}
+
+// NewGeocodingService creates a new GeocodingService instance. The lookup URL contains {lat} and
+// {lon} placeholders; an empty URL disables geocoding.
+func NewGeocodingService(app *pocketbase.PocketBase, lookupURL string) *GeocodingService {
+	return &GeocodingService{
+		app:       app,
+		lookupURL: lookupURL,
+		client:    &http.Client{Timeout: constants.GeocodeTimeout},
+		stop:      make(chan struct{}),
+		cache:     make(map[string]utils.Place),
+	}
+}
+
+// Enabled reports whether a reverse geocoding service is configured
+func (s *GeocodingService) Enabled() bool {
+	return s.lookupURL != ""
+}
+
+// Start runs the periodic geocoding of new locations while the server is running
+func (s *GeocodingService) Start(e *core.ServeEvent) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(constants.GeocodeJobInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.GeocodePending(); err != nil {
+					utils.LogError(err, "reverse geocoding").Msg("Failed to geocode locations")
+					utils.ReportJobError(err, "reverse geocoding")
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop ends the periodic geocoding
+func (s *GeocodingService) Stop(e *core.TerminateEvent) error {
+	close(s.stop)
+	return nil
+}
+
+// GeocodePending geocodes a batch of locations that were not looked up yet and returns how many
+// were geocoded. A failing lookup ends the batch, the remaining locations are retried next run.
+func (s *GeocodingService) GeocodePending() (int, error) {
+	dao := s.app.Dao()
+	records, err := dao.FindRecordsByFilter(constants.CollectionLocations, "geocoded = false", "created",
+		constants.GeocodeBatchSize, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, record := range records {
+		place, err := s.lookup(record.GetFloat("latitude"), record.GetFloat("longitude"))
+		if err != nil {
+			return i, err
+		}
+
+		// Written directly, geocoding is no change of the location and must not touch "updated"
+		_, err = dao.DB().Update(constants.CollectionLocations, dbx.Params{
+			"country":      place.CountryCode,
+			"country_name": place.Country,
+			"region":       place.Region,
+			"geocoded":     true,
+		}, dbx.HashExp{"id": record.Id}).Execute()
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(records), nil
+}
+
+// Countries returns the countries and regions a user visited according to the geocoded locations
+func (s *GeocodingService) Countries(userID string) (*appmodels.CountriesResponse, error) {
+	points, err := s.placedPoints(userID)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch visited countries", userID)
+	}
+
+	var pending int
+	err = s.app.Dao().DB().Select("count(*)").From(constants.CollectionLocations).
+		Where(dbx.HashExp{"user": userID, "geocoded": false}).Row(&pending)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to count pending locations", userID)
+	}
+
+	countries := utils.SummarizeCountries(points)
+	response := &appmodels.CountriesResponse{
+		CountryCount:     len(countries),
+		PendingLocations: pending,
+		Countries:        countries,
+	}
+	for _, country := range countries {
+		response.RegionCount += len(country.Regions)
+	}
+	return response, nil
+}
+
+// CountryLayer returns the countries a user visited as a GeoJSON layer for a scratch map
+func (s *GeocodingService) CountryLayer(userID string) (*appmodels.CountryFeatureCollection, error) {
+	points, err := s.placedPoints(userID)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch visited countries", userID)
+	}
+
+	return &appmodels.CountryFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: utils.CountryFeatures(points, utils.SummarizeCountries(points)),
+	}, nil
+}
+
+// placedPoints reads the geocoded locations of a user inside a country, ordered by timestamp
+func (s *GeocodingService) placedPoints(userID string) ([]utils.PlacedPoint, error) {
+	var rows []struct {
+		Timestamp   string  `db:"timestamp"`
+		Latitude    float64 `db:"latitude"`
+		Longitude   float64 `db:"longitude"`
+		Country     string  `db:"country"`
+		CountryName string  `db:"country_name"`
+		Region      string  `db:"region"`
+	}
+	err := s.app.Dao().DB().
+		Select("timestamp", "latitude", "longitude", "country", "country_name", "region").
+		From(constants.CollectionLocations).
+		Where(dbx.And(dbx.HashExp{"user": userID}, dbx.NewExp("country != ''"))).
+		OrderBy("timestamp").
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]utils.PlacedPoint, len(rows))
+	for i, row := range rows {
+		timestamp, _ := types.ParseDateTime(row.Timestamp)
+		points[i] = utils.PlacedPoint{
+			Timestamp: timestamp.Time(),
+			Latitude:  row.Latitude,
+			Longitude: row.Longitude,
+			Place:     utils.Place{CountryCode: row.Country, Country: row.CountryName, Region: row.Region},
+		}
+	}
+	return points, nil
+}
+
+// lookup returns the place of a position's grid cell, querying the geocoding service once per cell
+func (s *GeocodingService) lookup(latitude, longitude float64) (utils.Place, error) {
+	cell := utils.GeocodeCell(latitude, longitude)
+
+	s.mu.Lock()
+	place, ok := s.cache[cell]
+	s.mu.Unlock()
+	if ok {
+		return place, nil
+	}
+
+	// Only the background job looks up, so pausing here keeps to the service's rate limit
+	time.Sleep(constants.GeocodeRequestDelay)
+
+	place, err := s.fetch(utils.GeocodeCellCenter(latitude, longitude))
+	if err != nil {
+		return utils.Place{}, err
+	}
+
+	s.mu.Lock()
+	if len(s.cache) >= constants.GeocodeCacheSize {
+		s.cache = make(map[string]utils.Place)
+	}
+	s.cache[cell] = place
+	s.mu.Unlock()
+
+	return place, nil
+}
+
+func (s *GeocodingService) fetch(latitude, longitude float64) (utils.Place, error) {
+	lookupURL := strings.NewReplacer(
+		"{lat}", strconv.FormatFloat(latitude, 'f', 5, 64),
+		"{lon}", strconv.FormatFloat(longitude, 'f', 5, 64),
+	).Replace(s.lookupURL)
+
+	req, err := http.NewRequest(http.MethodGet, lookupURL, nil)
+	if err != nil {
+		return utils.Place{}, err
+	}
+	// Nominatim's usage policy requires an identifying user agent
+	req.Header.Set("User-Agent", s.app.Settings().Meta.AppName+" (vibe-tracker)")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return utils.Place{}, fmt.Errorf("reverse geocoding failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return utils.Place{}, fmt.Errorf("reverse geocoding returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return utils.Place{}, fmt.Errorf("failed to read reverse geocoding response: %w", err)
+	}
+
+	return utils.ParseReverseGeocodeResponse(body)
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/utils"
+)
+
+// GeoIPService resolves client addresses to a country and autonomous system through an external
+// HTTP lookup service, caching results per address
+type GeoIPService struct {
+	lookupURL string
+	client    *http.Client
+
+	mu    sync.Mutex
+	cache map[string]geoIPCacheEntry
+}
+
+type geoIPCacheEntry struct {
+	location  utils.NetworkLocation
+	expiresAt time.Time
+}
+
+// NewGeoIPService creates a new GeoIPService instance. The lookup URL contains an {ip} placeholder,
+// e.g. https://ipinfo.io/{ip}/json; an empty URL disables lookups.
+func NewGeoIPService(lookupURL string) *GeoIPService {
+	return &GeoIPService{
+		lookupURL: lookupURL,
+		client:    &http.Client{Timeout: constants.GeoIPLookupTimeout},
+		cache:     make(map[string]geoIPCacheEntry),
+	}
+}
+
+// Enabled reports whether a lookup service is configured
+func (s *GeoIPService) Enabled() bool {
+	return s.lookupURL != ""
+}
+
+// Lookup returns the network location of an address. Private and unparsable addresses resolve to
+// an empty location without querying the lookup service.
+func (s *GeoIPService) Lookup(ip string) (utils.NetworkLocation, error) {
+	if !s.Enabled() || !utils.IsPublicIP(ip) {
+		return utils.NetworkLocation{}, nil
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	if entry, ok := s.cache[ip]; ok && now.Before(entry.expiresAt) {
+		s.mu.Unlock()
+		return entry.location, nil
+	}
+	s.mu.Unlock()
+
+	location, err := s.fetch(ip)
+	if err != nil {
+		return utils.NetworkLocation{}, err
+	}
+
+	s.mu.Lock()
+	if len(s.cache) >= constants.GeoIPCacheSize {
+		for address, entry := range s.cache {
+			if now.After(entry.expiresAt) {
+				delete(s.cache, address)
+			}
+		}
+	}
+	s.cache[ip] = geoIPCacheEntry{location: location, expiresAt: now.Add(constants.GeoIPCacheTTL)}
+	s.mu.Unlock()
+
+	return location, nil
+}
+
+func (s *GeoIPService) fetch(ip string) (utils.NetworkLocation, error) {
+	lookupURL := strings.ReplaceAll(s.lookupURL, "{ip}", url.PathEscape(ip))
+
+	resp, err := s.client.Get(lookupURL)
+	if err != nil {
+		return utils.NetworkLocation{}, fmt.Errorf("geo-IP lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return utils.NetworkLocation{}, fmt.Errorf("geo-IP lookup returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return utils.NetworkLocation{}, fmt.Errorf("failed to read geo-IP response: %w", err)
+	}
+
+	return utils.ParseGeoIPResponse(body)
+}
@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/pocketbase/pocketbase"
+	pbmigrations "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/migrate"
 
 	"vibe-tracker/constants"
 	"vibe-tracker/models"
 	"vibe-tracker/repositories"
+	"vibe-tracker/utils"
 )
 
 // HealthService provides health check functionality
@@ -30,6 +34,12 @@ type HealthService struct {
 	lastHealthCheck time.Time
 	cacheTTL        time.Duration
 	dbTimeout       time.Duration
+
+	// Running data backfills by name; the app is not ready while any runs
+	backfills   map[string]time.Time
+	backfillMux sync.Mutex
+
+	ingest *IngestService // nil when write batching is off
 }
 
 // NewHealthService creates a new health service
@@ -57,6 +67,27 @@ func NewHealthService(
 		startTime:       time.Now(),
 		cacheTTL:        cacheTTL,
 		dbTimeout:       dbTimeout,
+		backfills:       make(map[string]time.Time),
+	}
+}
+
+// WithIngestService reports the write batching queue in the detailed health response
+func (s *HealthService) WithIngestService(ingest *IngestService) *HealthService {
+	s.ingest = ingest
+	return s
+}
+
+// BeginBackfill marks a long running data backfill as started, readiness fails until the returned
+// function is called
+func (s *HealthService) BeginBackfill(name string) func() {
+	s.backfillMux.Lock()
+	s.backfills[name] = time.Now()
+	s.backfillMux.Unlock()
+
+	return func() {
+		s.backfillMux.Lock()
+		delete(s.backfills, name)
+		s.backfillMux.Unlock()
 	}
 }
 
@@ -84,6 +115,9 @@ func (s *HealthService) GetReadiness() *models.ReadinessResponse {
 		checks["services"] = models.HealthStatusUnhealthy
 	}
 
+	// Instances are not ready to serve until the schema is current and backfills are done
+	checks["migrations"] = s.checkMigrations().Status
+
 	// Check configuration
 	checks["configuration"] = models.HealthStatusHealthy // Always healthy if we got this far
 
@@ -143,6 +177,9 @@ func (s *HealthService) performFullHealthCheck() *models.SystemHealth {
 	// Resources health check
 	health.Resources = s.checkResourcesHealth()
 
+	// Migrations health check
+	health.Migrations = s.checkMigrations()
+
 	// Determine overall health
 	health.Overall = models.HealthStatusHealthy
 
@@ -152,6 +189,10 @@ func (s *HealthService) performFullHealthCheck() *models.SystemHealth {
 		health.Overall = models.HealthStatusWarning
 	}
 
+	if health.Migrations.Status == models.HealthStatusUnhealthy {
+		health.Overall = models.HealthStatusUnhealthy
+	}
+
 	// Check for warning conditions in resources
 	if health.Resources != nil {
 		goroutines := runtime.NumGoroutine()
@@ -212,6 +253,46 @@ func (s *HealthService) checkDatabaseHealth() *models.ComponentHealth {
 	return componentHealth
 }
 
+// checkMigrations compares the applied migrations with the registered ones and lists running
+// backfills
+func (s *HealthService) checkMigrations() *models.MigrationHealth {
+	migrationHealth := &models.MigrationHealth{Status: models.HealthStatusHealthy}
+
+	s.backfillMux.Lock()
+	for name := range s.backfills {
+		migrationHealth.Backfills = append(migrationHealth.Backfills, name)
+	}
+	s.backfillMux.Unlock()
+	sort.Strings(migrationHealth.Backfills)
+
+	var applied []string
+	err := s.app.Dao().DB().Select("file").
+		From(migrate.DefaultMigrationsTable).
+		OrderBy("applied ASC", "file ASC").
+		Column(&applied)
+	if err != nil {
+		migrationHealth.Status = models.HealthStatusUnhealthy
+		migrationHealth.Error = err.Error()
+		return migrationHealth
+	}
+
+	registered := make([]string, 0, len(pbmigrations.AppMigrations.Items()))
+	for _, migration := range pbmigrations.AppMigrations.Items() {
+		registered = append(registered, migration.File)
+	}
+
+	migrationHealth.Applied = len(applied)
+	migrationHealth.Pending = len(utils.PendingMigrations(registered, applied))
+	if len(applied) > 0 {
+		migrationHealth.Version = applied[len(applied)-1]
+	}
+
+	if migrationHealth.Pending > 0 || len(migrationHealth.Backfills) > 0 {
+		migrationHealth.Status = models.HealthStatusUnhealthy
+	}
+	return migrationHealth
+}
+
 // checkServicesHealth validates that all services are available
 func (s *HealthService) checkServicesHealth() *models.ServiceHealth {
 	return &models.ServiceHealth{
@@ -261,14 +342,22 @@ func (s *HealthService) buildDetailedResponse(health *models.SystemHealth) *mode
 	checks := make(map[string]*models.ComponentHealth)
 	checks["database"] = health.Database
 
+	// The queue changes by the millisecond, so it is never cached
+	var ingest *models.IngestQueueStats
+	if s.ingest != nil {
+		ingest = s.ingest.Stats()
+	}
+
 	return &models.DetailedHealthResponse{
-		Status:    health.Overall,
-		Version:   constants.AppVersion,
-		Uptime:    s.getUptime(),
-		Timestamp: time.Now(),
-		Checks:    checks,
-		Services:  health.Services,
-		Resources: health.Resources,
+		Status:     health.Overall,
+		Version:    constants.AppVersion,
+		Uptime:     s.getUptime(),
+		Timestamp:  time.Now(),
+		Checks:     checks,
+		Services:   health.Services,
+		Resources:  health.Resources,
+		Migrations: health.Migrations,
+		Ingest:     ingest,
 	}
 }
 
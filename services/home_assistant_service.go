@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	"vibe-tracker/utils"
+)
+
+// HomeAssistantService publishes the latest locations of the configured users to an MQTT broker
+// as Home Assistant device trackers, announced through MQTT discovery, for presence automations.
+// The discovery configs and latest locations are retained and republished after every reconnect;
+// a will message marks the trackers unavailable while the server is away.
+type HomeAssistantService struct {
+	app    *pocketbase.PocketBase
+	broker *utils.MQTTBroker
+	config config.HomeAssistantConfig
+	users  map[string]bool // Published usernames
+	queue  chan *models.Record
+
+	announced map[string]bool // Users whose tracker was announced on the current connection
+
+	mu     sync.Mutex
+	conn   net.Conn
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewHomeAssistantService creates a publisher of the configured users' locations to the broker
+func NewHomeAssistantService(app *pocketbase.PocketBase, broker *utils.MQTTBroker, cfg config.HomeAssistantConfig) *HomeAssistantService {
+	service := &HomeAssistantService{
+		app:    app,
+		broker: broker,
+		config: cfg,
+		users:  map[string]bool{},
+		queue:  make(chan *models.Record, constants.HAMQTTQueueSize),
+	}
+	for _, username := range cfg.Users {
+		service.users[username] = true
+	}
+	return service
+}
+
+// Start connects to the broker in the background
+func (s *HomeAssistantService) Start(e *core.ServeEvent) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go s.run(ctx)
+
+	utils.LogInfo().Str("broker", s.broker.Address).Int("users", len(s.users)).Msg("Home Assistant publisher started")
+	return nil
+}
+
+// Stop disconnects from the broker and waits for the publisher to finish
+func (s *HomeAssistantService) Stop(e *core.TerminateEvent) error {
+	if s.cancel == nil {
+		return nil
+	}
+
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// LocationCreated queues a stored location for publishing. Locations of users that are not
+// published are skipped later, when the user is looked up.
+func (s *HomeAssistantService) LocationCreated(e *core.ModelEvent) error {
+	record, ok := e.Model.(*models.Record)
+	if !ok {
+		return nil
+	}
+
+	select {
+	case s.queue <- record:
+	default:
+		utils.LogDebug().Str("location_id", record.Id).Msg("Home Assistant queue full, location not published")
+	}
+	return nil
+}
+
+func (s *HomeAssistantService) run(ctx context.Context) {
+	defer close(s.done)
+
+	for {
+		err := s.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		utils.LogWarn().Err(err).Str("broker", s.broker.Address).Msg("MQTT connection lost, reconnecting")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(constants.HAMQTTReconnectDelay):
+		}
+	}
+}
+
+// session connects, announces the trackers and publishes queued locations until the connection
+// fails or the service is stopped
+func (s *HomeAssistantService) session(ctx context.Context) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+
+	statusTopic := s.config.TopicPrefix + "/status"
+	if err := s.write(utils.MQTTConnectPacket(utils.MQTTConnectOptions{
+		ClientID:    s.config.TopicPrefix,
+		Username:    s.broker.Username,
+		Password:    s.broker.Password,
+		KeepAlive:   constants.HAMQTTKeepAlive,
+		WillTopic:   statusTopic,
+		WillPayload: []byte("offline"),
+		WillRetain:  true,
+	})); err != nil {
+		return err
+	}
+	conn.SetReadDeadline(time.Now().Add(constants.HAMQTTKeepAlive))
+	packetType, body, err := utils.ReadMQTTPacket(conn)
+	if err != nil {
+		return err
+	}
+	if packetType != utils.MQTTConnAck {
+		return fmt.Errorf("mqtt: expected CONNACK, got packet type %#x", packetType)
+	}
+	if err := utils.CheckMQTTConnAck(body); err != nil {
+		return err
+	}
+
+	// The broker only sends ping responses, reading them detects lost connections
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			conn.SetReadDeadline(time.Now().Add(constants.HAMQTTKeepAlive * 3 / 2))
+			if _, _, err := utils.ReadMQTTPacket(conn); err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	if err := s.write(utils.MQTTPublishPacket(statusTopic, []byte("online"), true)); err != nil {
+		return err
+	}
+	s.announced = map[string]bool{}
+	for username := range s.users {
+		if err := s.announce(username); err != nil {
+			return err
+		}
+	}
+
+	ping := time.NewTicker(constants.HAMQTTKeepAlive / 2)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.write(utils.MQTTPublishPacket(statusTopic, []byte("offline"), true))
+			s.write(utils.MQTTDisconnectPacket)
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case <-ping.C:
+			if err := s.write(utils.MQTTPingPacket); err != nil {
+				return err
+			}
+		case record := <-s.queue:
+			if err := s.publishLocation(record); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *HomeAssistantService) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: constants.HAMQTTKeepAlive}
+	if s.broker.TLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer}
+		return tlsDialer.DialContext(ctx, "tcp", s.broker.Address)
+	}
+	return dialer.DialContext(ctx, "tcp", s.broker.Address)
+}
+
+func (s *HomeAssistantService) write(packet []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return errors.New("mqtt: not connected")
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(constants.HAMQTTKeepAlive))
+	_, err := s.conn.Write(packet)
+	return err
+}
+
+// announce publishes the discovery config of a user's tracker and the user's latest location
+func (s *HomeAssistantService) announce(username string) error {
+	dao := s.app.Dao()
+	user, err := dao.FindFirstRecordByFilter(constants.CollectionUsers, "username = {:username}", dbx.Params{"username": username})
+	if err != nil {
+		utils.LogWarn().Str("username", username).Msg("Home Assistant user not found, not published")
+		return nil
+	}
+	s.announced[username] = true
+
+	discovery, _ := json.Marshal(map[string]any{
+		"name":                  nil, // The entity is named after its device
+		"unique_id":             s.config.TopicPrefix + "_" + user.Id,
+		"object_id":             s.config.TopicPrefix + "_" + username,
+		"source_type":           "gps",
+		"json_attributes_topic": s.attributesTopic(username),
+		"availability_topic":    s.config.TopicPrefix + "/status",
+		"device": map[string]any{
+			"identifiers":  []string{s.config.TopicPrefix + "_" + user.Id},
+			"name":         username,
+			"manufacturer": "Vibe Tracker",
+			"sw_version":   constants.AppVersion,
+		},
+	})
+	topic := fmt.Sprintf("%s/device_tracker/%s_%s/config", s.config.DiscoveryPrefix, s.config.TopicPrefix, username)
+	if err := s.write(utils.MQTTPublishPacket(topic, discovery, true)); err != nil {
+		return err
+	}
+
+	latest, err := dao.FindRecordsByFilter(constants.CollectionLocations, "user = {:user}", "-timestamp", 1, 0,
+		dbx.Params{"user": user.Id})
+	if err != nil || len(latest) == 0 {
+		return nil
+	}
+	return s.write(utils.MQTTPublishPacket(s.attributesTopic(username), homeAssistantAttributes(latest[0]), true))
+}
+
+// publishLocation publishes a new location of a published user. Users created after connecting
+// are announced with their first location.
+func (s *HomeAssistantService) publishLocation(record *models.Record) error {
+	user, err := s.app.Dao().FindRecordById(constants.CollectionUsers, record.GetString("user"))
+	if err != nil || !s.users[user.Username()] {
+		return nil
+	}
+	if !s.announced[user.Username()] {
+		return s.announce(user.Username())
+	}
+	return s.write(utils.MQTTPublishPacket(s.attributesTopic(user.Username()), homeAssistantAttributes(record), true))
+}
+
+func (s *HomeAssistantService) attributesTopic(username string) string {
+	return s.config.TopicPrefix + "/" + username + "/attributes"
+}
+
+// homeAssistantAttributes returns the JSON attributes of a location; Home Assistant places the
+// tracker at latitude and longitude within gps_accuracy
+func homeAssistantAttributes(record *models.Record) []byte {
+	attributes := map[string]any{
+		"latitude":  record.GetFloat("latitude"),
+		"longitude": record.GetFloat("longitude"),
+		"timestamp": record.GetDateTime("timestamp").Time().Format(time.RFC3339),
+	}
+	for field, attribute := range map[string]string{
+		"accuracy": "gps_accuracy",
+		"speed":    "speed",
+		"battery":  "battery_level",
+	} {
+		if value := record.GetFloat(field); value > 0 {
+			attributes[attribute] = value
+		}
+	}
+	// Altitudes at or below sea level are valid
+	if altitude := utils.RecordAltitude(record); altitude != nil {
+		attributes["altitude"] = *altitude
+	}
+	if session := utils.LocationSessionName(record); session != "" {
+		attributes["session"] = session
+	}
+	for _, field := range []string{"device", "status"} {
+		if value := record.GetString(field); value != "" {
+			attributes[field] = value
+		}
+	}
+
+	payload, _ := json.Marshal(attributes)
+	return payload
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// ErrIngestQueueFull is returned by Save when the queue holds as many points as it may; the sender
+// should retry later
+var ErrIngestQueueFull = errors.New("ingest queue is full")
+
+// IngestService writes tracked points in batches, one transaction per batch instead of one per
+// point. Points are queued and a pool of workers writes the batches, so a burst of points does not
+// hold up the senders. In sync mode senders wait until their batch is committed; in async mode they
+// are answered once the point is queued, and queued points are lost if the process crashes. The
+// queue is bounded: when it is full, points are refused instead of queued. Reading the data of a
+// user first writes the queued points of that user, so senders always read their own writes.
+type IngestService struct {
+	app       *pocketbase.PocketBase
+	mode      string
+	interval  time.Duration
+	batchSize int
+	queueSize int
+	workers   int
+
+	mu           sync.Mutex
+	written      *sync.Cond         // Broadcast whenever a batch is written
+	pending      []*pendingLocation // Points not handed to a worker yet
+	queued       int                // Points accepted but not written yet
+	pendingUsers map[string]int     // Queued points per user
+	stopped      bool
+
+	// Batches waiting for a worker; with at least one point per batch it never holds more batches
+	// than the queue holds points, so handing over a batch never blocks
+	batches chan []*pendingLocation
+	wg      sync.WaitGroup
+	stop    chan struct{}
+
+	accepted      atomic.Int64
+	rejected      atomic.Int64
+	stored        atomic.Int64
+	failed        atomic.Int64
+	batchCount    atomic.Int64
+	lastBatchSize atomic.Int64
+	lastLatency   atomic.Int64 // Nanoseconds from queuing to commit of the oldest point of the last batch
+}
+
+// pendingLocation is a queued point; done receives the result of the write in sync mode
+type pendingLocation struct {
+	record   *models.Record
+	done     chan error
+	queuedAt time.Time
+}
+
+// NewIngestService creates a new IngestService instance
+func NewIngestService(app *pocketbase.PocketBase, trackingConfig *config.TrackingConfig) *IngestService {
+	batchSize := trackingConfig.IngestBatchSize
+	if batchSize <= 0 {
+		batchSize = constants.DefaultIngestBatchSize
+	}
+	interval := trackingConfig.IngestBatchInterval
+	if interval <= 0 {
+		interval = constants.DefaultIngestBatchInterval
+	}
+	queueSize := trackingConfig.IngestQueueSize
+	if queueSize <= 0 {
+		queueSize = constants.DefaultIngestQueueSize
+	}
+	workers := trackingConfig.IngestWorkers
+	if workers <= 0 {
+		workers = constants.DefaultIngestWorkers
+	}
+
+	s := &IngestService{
+		app:          app,
+		mode:         trackingConfig.IngestMode,
+		interval:     interval,
+		batchSize:    batchSize,
+		queueSize:    queueSize,
+		workers:      workers,
+		pendingUsers: make(map[string]int),
+		batches:      make(chan []*pendingLocation, queueSize),
+		stop:         make(chan struct{}),
+	}
+	s.written = sync.NewCond(&s.mu)
+	return s
+}
+
+// Start starts the workers and hands the queued points to them every batch interval while the
+// server is running
+func (s *IngestService) Start(e *core.ServeEvent) error {
+	for range s.workers {
+		s.wg.Add(1)
+		go s.work()
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.mu.Lock()
+				s.dispatch()
+				s.mu.Unlock()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop writes the points still queued and stops the workers. Points saved afterwards are written
+// right away.
+func (s *IngestService) Stop(e *core.TerminateEvent) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.dispatch()
+	s.stopped = true
+	close(s.batches)
+	s.mu.Unlock()
+	close(s.stop)
+
+	// Helps the workers drain the queue, and drains it alone when they were never started
+	for batch := range s.batches {
+		s.writeBatch(batch)
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// Save queues a new location record, or returns ErrIngestQueueFull when the queue is full. In sync
+// mode it returns once the batch holding the record is committed; in async mode the record gets its
+// ID right away and Save returns immediately.
+func (s *IngestService) Save(record *models.Record) error {
+	write := &pendingLocation{record: record, queuedAt: time.Now()}
+	if s.mode == constants.IngestModeAsync {
+		if record.Id == "" {
+			record.RefreshId()
+		}
+	} else {
+		write.done = make(chan error, 1)
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return s.app.Dao().SaveRecord(record)
+	}
+	if s.queued >= s.queueSize {
+		s.mu.Unlock()
+		s.rejected.Add(1)
+		return ErrIngestQueueFull
+	}
+	s.pending = append(s.pending, write)
+	s.queued++
+	s.pendingUsers[record.GetString("user")]++
+	if len(s.pending) >= s.batchSize {
+		s.dispatch()
+	}
+	s.mu.Unlock()
+	s.accepted.Add(1)
+
+	if write.done == nil {
+		return nil
+	}
+	return <-write.done
+}
+
+// FlushUser writes the queued points of a user before their data is read
+func (s *IngestService) FlushUser(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pendingUsers[userID] == 0 {
+		return
+	}
+	s.dispatch()
+	for s.pendingUsers[userID] > 0 {
+		s.written.Wait()
+	}
+}
+
+// Stats returns the state of the queue and the counters since the start
+func (s *IngestService) Stats() *appmodels.IngestQueueStats {
+	s.mu.Lock()
+	queued := s.queued
+	s.mu.Unlock()
+
+	return &appmodels.IngestQueueStats{
+		Mode:             s.mode,
+		Workers:          s.workers,
+		Capacity:         s.queueSize,
+		Queued:           queued,
+		Accepted:         s.accepted.Load(),
+		Rejected:         s.rejected.Load(),
+		Written:          s.stored.Load(),
+		Failed:           s.failed.Load(),
+		Batches:          s.batchCount.Load(),
+		LastBatchSize:    int(s.lastBatchSize.Load()),
+		LastBatchLatency: time.Duration(s.lastLatency.Load()).String(),
+	}
+}
+
+// dispatch hands the points not handed over yet to the workers as one batch; s.mu must be held
+func (s *IngestService) dispatch() {
+	if len(s.pending) == 0 || s.stopped {
+		return
+	}
+	s.batches <- s.pending
+	s.pending = nil
+}
+
+// work writes batches until the queue is closed
+func (s *IngestService) work() {
+	defer s.wg.Done()
+
+	for batch := range s.batches {
+		s.writeBatch(batch)
+	}
+}
+
+// writeBatch writes a batch, removes its points from the queue and reports the results
+func (s *IngestService) writeBatch(batch []*pendingLocation) {
+	errs := s.write(batch)
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	s.stored.Add(int64(len(batch) - failed))
+	s.failed.Add(int64(failed))
+	s.batchCount.Add(1)
+	s.lastBatchSize.Store(int64(len(batch)))
+	s.lastLatency.Store(int64(time.Since(batch[0].queuedAt)))
+
+	s.mu.Lock()
+	s.queued -= len(batch)
+	for _, write := range batch {
+		user := write.record.GetString("user")
+		if s.pendingUsers[user]--; s.pendingUsers[user] <= 0 {
+			delete(s.pendingUsers, user)
+		}
+	}
+	s.written.Broadcast()
+	s.mu.Unlock()
+
+	for i, write := range batch {
+		if write.done != nil {
+			write.done <- errs[i]
+		} else if errs[i] != nil {
+			utils.LogError(errs[i], "location batch write").
+				Str("user_id", write.record.GetString("user")).
+				Msg("Failed to save queued location")
+		}
+	}
+}
+
+// write saves a batch in a single transaction. When the transaction fails the points are saved one
+// by one, so a single invalid point does not fail the rest of its batch.
+func (s *IngestService) write(batch []*pendingLocation) []error {
+	errs := make([]error, len(batch))
+
+	err := s.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		for _, write := range batch {
+			if err := txDao.SaveRecord(write.record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		return errs
+	}
+
+	utils.LogWarn().Err(err).Int("batch_size", len(batch)).Msg("Location batch failed, saving points individually")
+	for i, write := range batch {
+		// Records saved before the rollback are already marked as stored
+		write.record.MarkAsNew()
+		errs[i] = s.app.Dao().SaveRecord(write.record)
+	}
+	return errs
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+)
+
+func TestIngestService_Backpressure(t *testing.T) {
+	// Without Start no worker writes, so the queued points stay queued
+	service := NewIngestService(nil, &config.TrackingConfig{
+		IngestMode:      constants.IngestModeAsync,
+		IngestBatchSize: 10,
+		IngestQueueSize: 2,
+	})
+
+	for range 2 {
+		record := createMockRecord()
+		record.Set("user", "user123")
+		assert.NoError(t, service.Save(record))
+		assert.NotEmpty(t, record.Id, "Queued points get their ID right away")
+	}
+
+	refused := createMockRecord()
+	refused.Set("user", "user456")
+	assert.ErrorIs(t, service.Save(refused), ErrIngestQueueFull)
+
+	// Nothing of the refused point's user is queued, so reading their data does not wait
+	service.FlushUser("user456")
+
+	stats := service.Stats()
+	assert.Equal(t, constants.IngestModeAsync, stats.Mode)
+	assert.Equal(t, constants.DefaultIngestWorkers, stats.Workers)
+	assert.Equal(t, 2, stats.Capacity)
+	assert.Equal(t, 2, stats.Queued)
+	assert.Equal(t, int64(2), stats.Accepted)
+	assert.Equal(t, int64(1), stats.Rejected)
+	assert.Zero(t, stats.Written)
+	assert.Zero(t, stats.Batches)
+}
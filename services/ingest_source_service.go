@@ -0,0 +1,188 @@
+package services
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// IngestSourceService manages the webhook ingest sources of users. Each source has its own
+// webhook URL and secret, and a field mapping turning the JSON bodies the third-party service
+// posts into tracked points.
+type IngestSourceService struct {
+	app      *pocketbase.PocketBase
+	readOnly bool
+}
+
+// NewIngestSourceService creates a new IngestSourceService instance
+func NewIngestSourceService(app *pocketbase.PocketBase) *IngestSourceService {
+	return &IngestSourceService{
+		app: app,
+	}
+}
+
+// List returns all ingest sources of a user
+func (s *IngestSourceService) List(userID string) ([]appmodels.IngestSource, error) {
+	records, err := s.findByUser(userID)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch ingest sources", userID)
+	}
+
+	sources := make([]appmodels.IngestSource, len(records))
+	for i, record := range records {
+		sources[i] = s.recordToIngestSource(record)
+	}
+	return sources, nil
+}
+
+// Create adds an ingest source with a new secret
+func (s *IngestSourceService) Create(userID string, req appmodels.IngestSourceRequest) (*appmodels.IngestSource, error) {
+	if err := utils.ValidateWebhookMapping(req.Mapping); err != nil {
+		return nil, utils.NewValidationError("Invalid field mapping", err.Error())
+	}
+
+	existing, err := s.findByUser(userID)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch ingest sources", userID)
+	}
+	if len(existing) >= constants.MaxIngestSources {
+		return nil, utils.NewValidationError(fmt.Sprintf("At most %d ingest sources are allowed", constants.MaxIngestSources))
+	}
+
+	collection, err := s.app.Dao().FindCollectionByNameOrId(constants.CollectionIngestSources)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Ingest sources collection not found")
+	}
+
+	record := models.NewRecord(collection)
+	record.Set("user", userID)
+	record.Set("secret", security.RandomString(constants.IngestSourceSecretLength))
+	setIngestSourceFields(record, req)
+	if err := s.app.Dao().SaveRecord(record); err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to save ingest source", userID)
+	}
+
+	source := s.recordToIngestSource(record)
+	return &source, nil
+}
+
+// Update changes the name, session and mapping of an ingest source; its URL and secret are kept
+func (s *IngestSourceService) Update(userID, id string, req appmodels.IngestSourceRequest) (*appmodels.IngestSource, error) {
+	if err := utils.ValidateWebhookMapping(req.Mapping); err != nil {
+		return nil, utils.NewValidationError("Invalid field mapping", err.Error())
+	}
+
+	record, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	setIngestSourceFields(record, req)
+	if err := s.app.Dao().SaveRecord(record); err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to update ingest source", userID)
+	}
+
+	source := s.recordToIngestSource(record)
+	return &source, nil
+}
+
+// Delete removes an ingest source of a user; its webhook URL stops working
+func (s *IngestSourceService) Delete(userID, id string) error {
+	record, err := s.find(userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.app.Dao().DeleteRecord(record); err != nil {
+		return utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to delete ingest source", userID)
+	}
+	return nil
+}
+
+// WithReadOnly stops recording when sources last delivered a point, see READ_ONLY
+func (s *IngestSourceService) WithReadOnly() *IngestSourceService {
+	s.readOnly = true
+	return s
+}
+
+// Authenticate returns the ingest source a webhook was posted to when the secret matches. Unknown
+// sources and wrong secrets are not told apart.
+func (s *IngestSourceService) Authenticate(id, secret string) (*models.Record, error) {
+	record, err := s.app.Dao().FindRecordById(constants.CollectionIngestSources, id)
+	if err != nil || secret == "" || subtle.ConstantTimeCompare([]byte(record.GetString("secret")), []byte(secret)) != 1 {
+		return nil, utils.NewAuthenticationError("Invalid ingest source or secret", nil)
+	}
+	return record, nil
+}
+
+// Mapping returns the field mapping of an ingest source record
+func (s *IngestSourceService) Mapping(record *models.Record) map[string]string {
+	mapping := map[string]string{}
+	if err := record.UnmarshalJSONField("mapping", &mapping); err != nil {
+		utils.LogWarn().Err(err).Str("source_id", record.Id).Msg("Invalid ingest source mapping")
+	}
+	return mapping
+}
+
+// Touch records when a source last delivered a point
+func (s *IngestSourceService) Touch(record *models.Record) {
+	if s.readOnly || time.Since(record.GetDateTime("last_received_at").Time()) < constants.TrackerSeenInterval {
+		return
+	}
+
+	// Written directly, receiving a point is no change of the source and must not touch "updated"
+	_, err := s.app.Dao().DB().Update(constants.CollectionIngestSources, dbx.Params{
+		"last_received_at": types.NowDateTime().String(),
+	}, dbx.HashExp{"id": record.Id}).Execute()
+	if err != nil {
+		utils.LogWarn().Err(err).Str("source_id", record.Id).Msg("Failed to update ingest source activity")
+	}
+}
+
+func (s *IngestSourceService) findByUser(userID string) ([]*models.Record, error) {
+	return s.app.Dao().FindRecordsByFilter(constants.CollectionIngestSources,
+		"user = {:user}", "created", 0, 0, dbx.Params{"user": userID})
+}
+
+// find returns an ingest source owned by the user
+func (s *IngestSourceService) find(userID, id string) (*models.Record, error) {
+	record, err := s.app.Dao().FindRecordById(constants.CollectionIngestSources, id)
+	if err != nil || record.GetString("user") != userID {
+		return nil, utils.NewNotFoundError("Ingest source", id)
+	}
+	return record, nil
+}
+
+func setIngestSourceFields(record *models.Record, req appmodels.IngestSourceRequest) {
+	record.Set("name", req.Name)
+	record.Set("session", req.Session)
+	record.Set("mapping", req.Mapping)
+}
+
+func (s *IngestSourceService) recordToIngestSource(record *models.Record) appmodels.IngestSource {
+	source := appmodels.IngestSource{
+		ID:         record.Id,
+		Name:       record.GetString("name"),
+		Session:    record.GetString("session"),
+		Mapping:    s.Mapping(record),
+		WebhookURL: strings.TrimRight(s.app.Settings().Meta.AppUrl, "/") + "/api/ingest/webhook/" + record.Id,
+		Secret:     record.GetString("secret"),
+		Created:    record.GetDateTime("created").Time(),
+	}
+	if lastReceivedAt := record.GetDateTime("last_received_at"); !lastReceivedAt.IsZero() {
+		t := lastReceivedAt.Time()
+		source.LastReceivedAt = &t
+	}
+	return source
+}
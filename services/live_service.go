@@ -0,0 +1,131 @@
+package services
+
+import (
+	"sync"
+)
+
+// Live event types sent to session stream subscribers
+const (
+	LiveEventLocation = "location"
+	LiveEventViewers  = "viewers"
+)
+
+// liveSubscriberBuffer is the number of events buffered per subscriber before events are dropped
+const liveSubscriberBuffer = 16
+
+// LiveEvent is a message delivered to live session stream subscribers
+type LiveEvent struct {
+	Type string
+	Data any
+}
+
+// LiveSubscription is a single stream connection to a session
+type LiveSubscription struct {
+	Events   chan LiveEvent
+	isViewer bool
+	viewerID string
+}
+
+// LiveService fans out live session events and keeps track of concurrent viewers
+type LiveService struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*LiveSubscription]struct{}
+}
+
+// NewLiveService creates a new live service
+func NewLiveService() *LiveService {
+	return &LiveService{
+		subscribers: make(map[string]map[*LiveSubscription]struct{}),
+	}
+}
+
+// Subscribe registers a stream connection for a session. Viewers are counted towards the
+// viewer count, while the owner's own connections are not.
+func (s *LiveService) Subscribe(sessionID string, isViewer bool) *LiveSubscription {
+	return s.subscribe(sessionID, isViewer, "")
+}
+
+// SubscribeGuest registers a stream connection of an identified guest viewer. Multiple
+// connections of the same guest (e.g. several open tabs) count as a single viewer.
+func (s *LiveService) SubscribeGuest(sessionID, viewerID string) *LiveSubscription {
+	return s.subscribe(sessionID, true, viewerID)
+}
+
+func (s *LiveService) subscribe(sessionID string, isViewer bool, viewerID string) *LiveSubscription {
+	sub := &LiveSubscription{
+		Events:   make(chan LiveEvent, liveSubscriberBuffer),
+		isViewer: isViewer,
+		viewerID: viewerID,
+	}
+
+	s.mu.Lock()
+	if s.subscribers[sessionID] == nil {
+		s.subscribers[sessionID] = make(map[*LiveSubscription]struct{})
+	}
+	s.subscribers[sessionID][sub] = struct{}{}
+	s.mu.Unlock()
+
+	s.publishViewerCount(sessionID)
+	return sub
+}
+
+// Unsubscribe removes a stream connection and notifies the remaining subscribers
+func (s *LiveService) Unsubscribe(sessionID string, sub *LiveSubscription) {
+	s.mu.Lock()
+	if subs, ok := s.subscribers[sessionID]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(s.subscribers, sessionID)
+		}
+	}
+	s.mu.Unlock()
+
+	s.publishViewerCount(sessionID)
+}
+
+// ViewerCount returns the number of concurrent viewers of a session
+func (s *LiveService) ViewerCount(sessionID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	guests := make(map[string]struct{})
+	for sub := range s.subscribers[sessionID] {
+		switch {
+		case !sub.isViewer:
+		case sub.viewerID == "":
+			count++
+		default:
+			guests[sub.viewerID] = struct{}{}
+		}
+	}
+	return count + len(guests)
+}
+
+// HasSubscribers reports whether anyone is streaming the session
+func (s *LiveService) HasSubscribers(sessionID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.subscribers[sessionID]) > 0
+}
+
+// Publish sends an event to all subscribers of a session. Slow subscribers miss events
+// instead of blocking the publisher.
+func (s *LiveService) Publish(sessionID string, event LiveEvent) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for sub := range s.subscribers[sessionID] {
+		select {
+		case sub.Events <- event:
+		default:
+		}
+	}
+}
+
+func (s *LiveService) publishViewerCount(sessionID string) {
+	s.Publish(sessionID, LiveEvent{
+		Type: LiveEventViewers,
+		Data: map[string]int{"count": s.ViewerCount(sessionID)},
+	})
+}
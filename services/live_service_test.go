@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveService_ViewerCount(t *testing.T) {
+	service := NewLiveService()
+
+	owner := service.Subscribe("session1", false)
+	assert.Equal(t, 0, service.ViewerCount("session1"))
+	assert.True(t, service.HasSubscribers("session1"))
+
+	viewer1 := service.Subscribe("session1", true)
+	viewer2 := service.Subscribe("session1", true)
+	service.Subscribe("session2", true)
+	assert.Equal(t, 2, service.ViewerCount("session1"))
+	assert.Equal(t, 1, service.ViewerCount("session2"))
+
+	service.Unsubscribe("session1", viewer1)
+	assert.Equal(t, 1, service.ViewerCount("session1"))
+
+	service.Unsubscribe("session1", viewer2)
+	service.Unsubscribe("session1", owner)
+	assert.Equal(t, 0, service.ViewerCount("session1"))
+	assert.False(t, service.HasSubscribers("session1"))
+}
+
+func TestLiveService_GuestViewerCount(t *testing.T) {
+	service := NewLiveService()
+
+	// Several connections of the same guest count once
+	tab1 := service.SubscribeGuest("session1", "guest1")
+	tab2 := service.SubscribeGuest("session1", "guest1")
+	service.SubscribeGuest("session1", "guest2")
+	service.Subscribe("session1", true)
+	assert.Equal(t, 3, service.ViewerCount("session1"))
+
+	service.Unsubscribe("session1", tab1)
+	assert.Equal(t, 3, service.ViewerCount("session1"))
+
+	service.Unsubscribe("session1", tab2)
+	assert.Equal(t, 2, service.ViewerCount("session1"))
+}
+
+func TestLiveService_Publish(t *testing.T) {
+	service := NewLiveService()
+	sub := service.Subscribe("session1", false)
+
+	// Subscribing announces the current viewer count
+	event := <-sub.Events
+	assert.Equal(t, LiveEventViewers, event.Type)
+	assert.Equal(t, map[string]int{"count": 0}, event.Data)
+
+	viewer := service.Subscribe("session1", true)
+	event = <-sub.Events
+	assert.Equal(t, map[string]int{"count": 1}, event.Data)
+
+	service.Publish("session1", LiveEvent{Type: LiveEventLocation, Data: "point"})
+	event = <-sub.Events
+	assert.Equal(t, LiveEventLocation, event.Type)
+	assert.Equal(t, "point", event.Data)
+
+	// Events for other sessions are not delivered
+	service.Publish("session2", LiveEvent{Type: LiveEventLocation, Data: "other"})
+	assert.Len(t, sub.Events, 0)
+
+	// A full buffer drops events instead of blocking
+	for i := 0; i < liveSubscriberBuffer*2; i++ {
+		service.Publish("session1", LiveEvent{Type: LiveEventLocation, Data: i})
+	}
+	assert.Len(t, viewer.Events, liveSubscriberBuffer)
+}
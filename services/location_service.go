@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -10,6 +13,7 @@ import (
 	"vibe-tracker/constants"
 	appmodels "vibe-tracker/models"
 	"vibe-tracker/repositories"
+	"vibe-tracker/utils"
 )
 
 // LocationService handles location tracking and GeoJSON business logic
@@ -18,6 +22,10 @@ type LocationService struct {
 	userRepo       repositories.UserRepository
 	sessionRepo    repositories.SessionRepository
 	sessionService repositories.SessionServiceInterface
+	duplicates     *utils.DuplicateThresholds // Nil stores repeated points
+	outliers       *utils.OutlierThresholds   // Nil stores points at any speed
+	motion         *utils.GapThresholds       // Nil stores points without speed or bearing as they are
+	flushPending   func(userID string)        // Nil when points are written right away
 }
 
 // NewLocationService creates a new LocationService instance
@@ -30,6 +38,50 @@ func NewLocationService(locationRepo repositories.LocationRepository, userRepo r
 	}
 }
 
+// WithContext returns a copy of the service whose queries are aborted when ctx is cancelled
+func (s *LocationService) WithContext(ctx context.Context) *LocationService {
+	bound := &LocationService{
+		locationRepo:   s.locationRepo.WithContext(ctx),
+		userRepo:       s.userRepo.WithContext(ctx),
+		sessionRepo:    s.sessionRepo.WithContext(ctx),
+		sessionService: s.sessionService,
+		duplicates:     s.duplicates,
+		outliers:       s.outliers,
+		motion:         s.motion,
+		flushPending:   s.flushPending,
+	}
+	if sessionService, ok := s.sessionService.(*SessionService); ok {
+		bound.sessionService = sessionService.WithContext(ctx)
+	}
+	return bound
+}
+
+// WithDeduplication makes FindDuplicate report tracked points that repeat a stored point
+func (s *LocationService) WithDeduplication(thresholds utils.DuplicateThresholds) *LocationService {
+	s.duplicates = &thresholds
+	return s
+}
+
+// WithOutlierFilter makes CheckPlausibility reject tracked points that imply impossible speeds
+func (s *LocationService) WithOutlierFilter(thresholds utils.OutlierThresholds) *LocationService {
+	s.outliers = &thresholds
+	return s
+}
+
+// WithDerivedMotion makes DeriveMotion fill in the speed and bearing of tracked points that
+// report none, from the previous point of their session unless the gap to it is too large
+func (s *LocationService) WithDerivedMotion(gaps utils.GapThresholds) *LocationService {
+	s.motion = &gaps
+	return s
+}
+
+// WithPendingWritesFlusher sets a function that writes the batched points of a user before the
+// stored points are compared with a tracked point, so it is not compared with stale ones
+func (s *LocationService) WithPendingWritesFlusher(flush func(userID string)) *LocationService {
+	s.flushPending = flush
+	return s
+}
+
 // TrackLocationFromGeoJSON processes a GeoJSON location request
 func (s *LocationService) TrackLocationFromGeoJSON(req appmodels.LocationRequest, user *models.Record) error {
 	record, err := s.locationRepo.CreateNewRecord()
@@ -47,21 +99,20 @@ func (s *LocationService) TrackLocationFromGeoJSON(req appmodels.LocationRequest
 	}
 
 	// Set coordinates
-	record.Set("longitude", req.Geometry.Coordinates[0])
-	record.Set("latitude", req.Geometry.Coordinates[1])
-
-	// Set altitude if provided
+	var altitude *float64
 	if len(req.Geometry.Coordinates) > 2 {
-		record.Set("altitude", req.Geometry.Coordinates[2])
+		altitude = &req.Geometry.Coordinates[2]
 	}
+	s.SetPosition(record, req.Geometry.Coordinates[1], req.Geometry.Coordinates[0], altitude)
 
 	// Set optional properties
-	if req.Properties.Speed != nil && *req.Properties.Speed > 0 {
-		record.Set("speed", *req.Properties.Speed)
+	if err := s.SetSpeed(record, user, req.Properties.Speed, req.Properties.SpeedUnit); err != nil {
+		return err
 	}
 	if req.Properties.HeartRate != nil && *req.Properties.HeartRate > 0 {
 		record.Set("heart_rate", *req.Properties.HeartRate)
 	}
+	utils.SetRecordSensors(record, req.Properties.Cadence, req.Properties.Power, req.Properties.Temperature)
 
 	// Handle session
 	if req.Properties.Session != "" {
@@ -93,19 +144,16 @@ func (s *LocationService) TrackLocationFromParams(params appmodels.TrackingQuery
 	}
 	record.Set("user", user.Id)
 	record.Set("timestamp", types.NowDateTime())
-	record.Set("longitude", params.Longitude)
-	record.Set("latitude", params.Latitude)
+	s.SetPosition(record, params.Latitude, params.Longitude, params.Altitude)
 
 	// Set optional parameters
-	if params.Altitude != nil {
-		record.Set("altitude", *params.Altitude)
-	}
-	if params.Speed != nil && *params.Speed > 0 {
-		record.Set("speed", *params.Speed)
+	if err := s.SetSpeed(record, user, params.Speed, params.SpeedUnit); err != nil {
+		return err
 	}
 	if params.HeartRate != nil && *params.HeartRate > 0 {
 		record.Set("heart_rate", *params.HeartRate)
 	}
+	utils.SetRecordSensors(record, params.Cadence, params.Power, params.Temperature)
 
 	// Handle session
 	if params.Session != "" {
@@ -129,6 +177,234 @@ func (s *LocationService) TrackLocationFromParams(params appmodels.TrackingQuery
 	return s.locationRepo.Create(record)
 }
 
+// SetPosition stores coordinates on a location record rounded to about 1 cm; clients often send
+// far more digits than their accuracy justifies
+func (s *LocationService) SetPosition(record *models.Record, latitude, longitude float64, altitude *float64) {
+	record.Set("latitude", utils.RoundTo(latitude, constants.CoordinatePrecision))
+	record.Set("longitude", utils.RoundTo(longitude, constants.CoordinatePrecision))
+	if altitude != nil {
+		rounded := utils.RoundTo(*altitude, constants.AltitudePrecision)
+		altitude = &rounded
+	}
+	utils.SetRecordAltitude(record, altitude)
+}
+
+// SetSpeed stores a reported speed on a location record in m/s. The unit named by the point
+// itself wins, so a device can report in its own unit; otherwise the user's default applies.
+func (s *LocationService) SetSpeed(record *models.Record, user *models.Record, speed *float64, unit string) error {
+	if speed == nil {
+		return nil
+	}
+
+	if unit == "" {
+		unit = trackingDefaultsFromRecord(user).SpeedUnit
+	}
+	metersPerSecond, err := utils.SpeedToMetersPerSecond(*speed, unit)
+	if err != nil {
+		return &LocationError{Message: err.Error()}
+	}
+
+	utils.SetRecordSpeed(record, &metersPerSecond)
+	return nil
+}
+
+// ResolveAutoSession returns the session name for a point tracked without one, based on the
+// user's auto_session_gap setting: points continue the session of the previous point unless
+// the gap was exceeded, which starts a new automatic session. An empty name means no session.
+func (s *LocationService) ResolveAutoSession(user *models.Record, timestamp time.Time) (string, error) {
+	defaults := trackingDefaultsFromRecord(user)
+	if defaults.AutoSessionGap <= 0 {
+		return "", nil
+	}
+
+	s.readOwnWrites(user.Id)
+	locations, err := s.locationRepo.FindByUser(user.Id, nil, "-timestamp", 1, 0)
+	if err != nil {
+		return "", err
+	}
+
+	if len(locations) > 0 {
+		previous := locations[0]
+		gap := time.Duration(defaults.AutoSessionGap) * time.Minute
+		if sessionID := previous.GetString("session"); sessionID != "" &&
+			timestamp.Sub(previous.GetDateTime("timestamp").Time()) <= gap {
+			session, err := s.sessionRepo.FindByID(sessionID)
+			if err != nil {
+				return "", err
+			}
+			return session.GetString("name"), nil
+		}
+	}
+
+	return timestamp.UTC().Format(constants.AutoSessionNameFormat), nil
+}
+
+// DownsamplingPolicy returns the policy applied to tracked points of a session: the session's
+// own policy if it has one, else the one of the user's tracking defaults. Points without a
+// session use the tracking defaults.
+func (s *LocationService) DownsamplingPolicy(user, session *models.Record) appmodels.DownsamplingPolicy {
+	if session != nil {
+		if raw := session.GetString("downsampling"); raw != "" && raw != "null" {
+			var policy appmodels.DownsamplingPolicy
+			err := json.Unmarshal([]byte(raw), &policy)
+			if err == nil {
+				return policy
+			}
+			utils.LogWarn().Err(err).Str("session_id", session.Id).Msg("Ignoring invalid session downsampling policy")
+		}
+	}
+	return trackingDefaultsFromRecord(user).Downsampling
+}
+
+// ShouldDownsample reports whether a tracked point is discarded by the downsampling policy because
+// it follows the last stored point of its session too soon or too close. Points reporting a status
+// or an event are always stored.
+func (s *LocationService) ShouldDownsample(user, session, record *models.Record) (bool, error) {
+	policy := s.DownsamplingPolicy(user, session)
+	if !policy.Enabled() || record.GetString("status") != "" || record.GetString("event") != "" {
+		return false, nil
+	}
+
+	s.readOwnWrites(user.Id)
+	locations, err := s.locationRepo.FindByUser(user.Id, map[string]interface{}{"session": record.GetString("session")}, "-timestamp", 1, 0)
+	if err != nil || len(locations) == 0 {
+		return false, err
+	}
+
+	last := utils.TimedPoint{
+		Timestamp: locations[0].GetDateTime("timestamp").Time(),
+		Latitude:  locations[0].GetFloat("latitude"),
+		Longitude: locations[0].GetFloat("longitude"),
+	}
+	point := utils.TimedPoint{
+		Timestamp: record.GetDateTime("timestamp").Time(),
+		Latitude:  record.GetFloat("latitude"),
+		Longitude: record.GetFloat("longitude"),
+	}
+	return utils.ShouldDownsample(last, point, time.Duration(policy.MinInterval)*time.Second, policy.MinDistance), nil
+}
+
+// FindDuplicate returns the stored point of the same session that a tracked point repeats, e.g.
+// sent again by a flaky client or uploaded twice from a device buffer, or nil when deduplication
+// is disabled. A point with a status or an event only repeats a point reporting the same.
+func (s *LocationService) FindDuplicate(record *models.Record) (*models.Record, error) {
+	if s.duplicates == nil {
+		return nil, nil
+	}
+
+	s.readOwnWrites(record.GetString("user"))
+	timestamp := record.GetDateTime("timestamp").Time()
+	candidates, err := s.locationRepo.FindByUser(record.GetString("user"), map[string]interface{}{
+		"session": record.GetString("session"),
+		"from":    timestamp.Add(-s.duplicates.MaxInterval).UTC().Format(types.DefaultDateLayout),
+		"to":      timestamp.Add(s.duplicates.MaxInterval).UTC().Format(types.DefaultDateLayout),
+	}, "-timestamp", constants.DuplicateCandidates, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	point := utils.TimedPoint{Timestamp: timestamp, Latitude: record.GetFloat("latitude"), Longitude: record.GetFloat("longitude")}
+	for _, candidate := range candidates {
+		if candidate.GetString("status") != record.GetString("status") || candidate.GetString("event") != record.GetString("event") {
+			continue
+		}
+		stored := utils.TimedPoint{
+			Timestamp: candidate.GetDateTime("timestamp").Time(),
+			Latitude:  candidate.GetFloat("latitude"),
+			Longitude: candidate.GetFloat("longitude"),
+		}
+		if s.duplicates.IsDuplicate(stored, point) {
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
+
+// CheckPlausibility returns a LocationError for a tracked point that the previous stored point of
+// its session could not reach without exceeding the maximum speed, e.g. a GPS glitch that would
+// show as a spike in the track and add its detour to the distance. Points with a status or an
+// event are always stored, as are all points when the outlier filter is disabled.
+func (s *LocationService) CheckPlausibility(record *models.Record) error {
+	if s.outliers == nil || record.GetString("status") != "" || record.GetString("event") != "" {
+		return nil
+	}
+
+	stored, err := s.findPreviousPoint(record)
+	if err != nil || stored == nil {
+		return err
+	}
+
+	previous, point := timedPoint(stored), timedPoint(record)
+	previousAccuracy, accuracy := stored.GetFloat("accuracy"), record.GetFloat("accuracy")
+	if !s.outliers.IsOutlier(previous, point, previousAccuracy, accuracy) {
+		return nil
+	}
+
+	return &LocationError{Message: fmt.Sprintf(
+		"Location implies a speed of %.0f km/h from the previous point of its session, above the limit of %.0f km/h",
+		s.outliers.ImpliedSpeed(previous, point, previousAccuracy, accuracy)*3.6, s.outliers.MaxSpeed*3.6,
+	)}
+}
+
+// DeriveMotion sets the speed and bearing of a tracked point that reports neither, or only one of
+// them, to those of the travel from the previous stored point of its session, so charts and
+// popups show them for every point. Reported values, a standstill or a heading due north
+// included, are kept. Nothing is set across gaps or when derived motion is disabled.
+func (s *LocationService) DeriveMotion(record *models.Record) error {
+	if s.motion == nil || (utils.RecordSpeed(record) != nil && utils.RecordBearing(record) != nil) {
+		return nil
+	}
+
+	stored, err := s.findPreviousPoint(record)
+	if err != nil || stored == nil {
+		return err
+	}
+
+	speed, bearing, ok := s.motion.ImpliedMotion(timedPoint(stored), timedPoint(record))
+	if !ok {
+		return nil
+	}
+	if utils.RecordSpeed(record) == nil {
+		speed = utils.RoundTo(speed, 2)
+		utils.SetRecordSpeed(record, &speed)
+	}
+	if utils.RecordBearing(record) == nil && bearing != nil {
+		rounded := utils.RoundTo(*bearing, 1)
+		utils.SetRecordBearing(record, &rounded)
+	}
+	return nil
+}
+
+// findPreviousPoint returns the latest stored point of a tracked point's session that is not
+// newer than it, or nil for the first point
+func (s *LocationService) findPreviousPoint(record *models.Record) (*models.Record, error) {
+	s.readOwnWrites(record.GetString("user"))
+	locations, err := s.locationRepo.FindByUser(record.GetString("user"), map[string]interface{}{
+		"session": record.GetString("session"),
+		"to":      record.GetDateTime("timestamp").Time().UTC().Format(types.DefaultDateLayout),
+	}, "-timestamp", 1, 0)
+	if err != nil || len(locations) == 0 {
+		return nil, err
+	}
+	return locations[0], nil
+}
+
+// readOwnWrites writes the batched points of a user before their stored points are read
+func (s *LocationService) readOwnWrites(userID string) {
+	if s.flushPending != nil {
+		s.flushPending(userID)
+	}
+}
+
+// timedPoint returns the time and position of a location record
+func timedPoint(record *models.Record) utils.TimedPoint {
+	return utils.TimedPoint{
+		Timestamp: record.GetDateTime("timestamp").Time(),
+		Latitude:  record.GetFloat("latitude"),
+		Longitude: record.GetFloat("longitude"),
+	}
+}
+
 // GetLatestLocationByUser returns the latest location for a user as GeoJSON
 func (s *LocationService) GetLatestLocationByUser(username string) (*appmodels.LocationResponse, error) {
 	// Find user by username
@@ -138,15 +414,44 @@ func (s *LocationService) GetLatestLocationByUser(username string) (*appmodels.L
 	}
 
 	// Get latest location
-	locations, err := s.locationRepo.FindByUser(user.Id, nil, "-timestamp", 1, 0)
+	locations, err := s.locationRepo.FindByUser(user.Id, nil, "-timestamp", s.PositionCandidateLimit(user), 0)
 	if err != nil || len(locations) == 0 {
 		return nil, err
 	}
-	location := locations[0]
+	location := s.SelectCurrentPosition(user, locations)
 
 	return s.recordToGeoJSON(location, user)
 }
 
+// PositionCandidateLimit returns how many recent locations SelectCurrentPosition needs for the user
+func (s *LocationService) PositionCandidateLimit(user *models.Record) int {
+	if trackingDefaultsFromRecord(user).LocationPolicy == constants.LocationPolicyNewest {
+		return 1
+	}
+	return constants.LatestPositionCandidates
+}
+
+// SelectCurrentPosition picks the record representing the user's current position from recent
+// locations (newest first) using the location policy of the user's tracking defaults
+func (s *LocationService) SelectCurrentPosition(user *models.Record, locations []*models.Record) *models.Record {
+	if len(locations) == 0 {
+		return nil
+	}
+
+	defaults := trackingDefaultsFromRecord(user)
+	fixes := make([]utils.PositionFix, len(locations))
+	for i, location := range locations {
+		fixes[i] = utils.PositionFix{
+			Timestamp: location.GetDateTime("timestamp").Time(),
+			Accuracy:  location.GetFloat("accuracy"),
+			Device:    location.GetString("device"),
+		}
+	}
+
+	window := time.Duration(defaults.AccuracyWindow) * time.Second
+	return locations[utils.SelectPosition(fixes, defaults.LocationPolicy, window)]
+}
+
 // GetPublicLocations returns all public latest locations as GeoJSON FeatureCollection
 func (s *LocationService) GetPublicLocations() (*appmodels.LocationsResponse, error) {
 	// Get users with public sessions that have recent locations
@@ -166,7 +471,7 @@ func (s *LocationService) GetPublicLocations() (*appmodels.LocationsResponse, er
 		if sessionID != "" {
 			// Check if session is public
 			session, err := s.sessionRepo.FindByID(sessionID)
-			if err != nil || !session.GetBool("public") {
+			if err != nil || !session.GetBool("public") || session.GetBool("hidden") || session.GetBool("draft") {
 				continue
 			}
 		}
@@ -237,26 +542,35 @@ func (s *LocationService) GetSessionData(username, sessionName string) (*appmode
 // Helper methods
 
 func (s *LocationService) recordToGeoJSON(record *models.Record, user *models.Record) (*appmodels.LocationResponse, error) {
-	coordinates := []float64{
-		record.GetFloat("longitude"),
-		record.GetFloat("latitude"),
-	}
-
-	if altitude := record.GetFloat("altitude"); altitude != 0 {
-		coordinates = append(coordinates, altitude)
-	}
+	coordinates := utils.RecordCoordinates(record)
 
 	properties := appmodels.LocationProperties{
 		Timestamp: record.GetDateTime("timestamp").Time().Unix(),
 		Username:  user.GetString("username"),
 	}
 
-	if speed := record.GetFloat("speed"); speed > 0 {
-		properties.Speed = &speed
-	}
+	properties.Speed = utils.RecordSpeed(record)
 	if heartRate := record.GetFloat("heart_rate"); heartRate > 0 {
 		properties.HeartRate = &heartRate
 	}
+	if cadence := record.GetFloat("cadence"); cadence > 0 {
+		properties.Cadence = &cadence
+	}
+	if power := record.GetFloat("power"); power > 0 {
+		properties.Power = &power
+	}
+	properties.Temperature = utils.RecordTemperature(record)
+	if accuracy := record.GetFloat("accuracy"); accuracy > 0 {
+		properties.Accuracy = &accuracy
+	}
+	properties.Device = record.GetString("device")
+	if battery := record.GetFloat("battery"); battery > 0 {
+		properties.Battery = &battery
+	}
+	if verticalAccuracy := record.GetFloat("vertical_accuracy"); verticalAccuracy > 0 {
+		properties.VerticalAccuracy = &verticalAccuracy
+	}
+	properties.Bearing = utils.RecordBearing(record)
 
 	// Get session info if available
 	if sessionID := record.GetString("session"); sessionID != "" {
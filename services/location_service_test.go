@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ import (
 	appmodels "vibe-tracker/models"
 	"vibe-tracker/repositories"
 	"vibe-tracker/services/mocks"
+	"vibe-tracker/utils"
 )
 
 // Test helper to create a mock SessionService that implements the interface
@@ -261,6 +263,527 @@ func TestLocationService_TrackLocationFromGeoJSON(t *testing.T) {
 	})
 }
 
+func TestLocationService_Normalization(t *testing.T) {
+	service := NewLocationService(&mocks.MockLocationRepository{}, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{})
+
+	t.Run("Coordinates are rounded to centimeters", func(t *testing.T) {
+		record := createMockRecord()
+		altitude := 123.456789
+		service.SetPosition(record, 47.497912345678, 19.040234567891, &altitude)
+
+		assert.Equal(t, 47.4979123, record.GetFloat("latitude"))
+		assert.Equal(t, 19.0402346, record.GetFloat("longitude"))
+		assert.Equal(t, 123.46, record.GetFloat("altitude"))
+	})
+
+	t.Run("Sea level altitude is kept", func(t *testing.T) {
+		record := createMockRecord()
+		altitude := 0.0
+		service.SetPosition(record, 52.37, 4.89, &altitude)
+
+		assert.Equal(t, &altitude, utils.RecordAltitude(record))
+		assert.Equal(t, []float64{4.89, 52.37, 0}, utils.RecordCoordinates(record))
+	})
+
+	t.Run("Missing altitude is not zero", func(t *testing.T) {
+		record := createMockRecord()
+		service.SetPosition(record, 52.37, 4.89, nil)
+
+		assert.Nil(t, utils.RecordAltitude(record))
+		assert.Equal(t, []float64{4.89, 52.37}, utils.RecordCoordinates(record))
+	})
+
+	t.Run("Speed uses the user's default unit", func(t *testing.T) {
+		record := createMockRecord()
+		mockUser := createMockRecord()
+		mockUser.Set("tracking_defaults", `{"speed_unit":"km/h"}`)
+		speed := 36.0
+
+		assert.NoError(t, service.SetSpeed(record, mockUser, &speed, ""))
+		assert.InDelta(t, 10.0, record.GetFloat("speed"), 0.0001)
+	})
+
+	t.Run("Point unit overrides the user's default", func(t *testing.T) {
+		record := createMockRecord()
+		mockUser := createMockRecord()
+		mockUser.Set("tracking_defaults", `{"speed_unit":"km/h"}`)
+		speed := 10.0
+
+		assert.NoError(t, service.SetSpeed(record, mockUser, &speed, constants.SpeedUnitKnots))
+		assert.InDelta(t, 5.1444, record.GetFloat("speed"), 0.0001)
+	})
+
+	t.Run("Speed defaults to m/s", func(t *testing.T) {
+		record := createMockRecord()
+		speed := 5.5
+
+		assert.NoError(t, service.SetSpeed(record, createMockRecord(), &speed, ""))
+		assert.Equal(t, 5.5, record.GetFloat("speed"))
+	})
+
+	t.Run("Unknown unit", func(t *testing.T) {
+		speed := 5.5
+		assert.Error(t, service.SetSpeed(createMockRecord(), createMockRecord(), &speed, "furlongs"))
+	})
+}
+
+func TestLocationService_ResolveAutoSession(t *testing.T) {
+	now := time.Date(2025, 9, 20, 8, 30, 0, 0, time.UTC)
+
+	newService := func(locationRepo *mocks.MockLocationRepository) *LocationService {
+		return NewLocationService(locationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{})
+	}
+
+	previousLocation := func(sessionID string, at time.Time) *models.Record {
+		record := createMockRecord()
+		timestamp, _ := types.ParseDateTime(at)
+		record.Set("timestamp", timestamp)
+		record.Set("session", sessionID)
+		return record
+	}
+
+	t.Run("Automatic sessions disabled", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockUser := createMockRecord()
+		mockUser.Id = "user123"
+
+		sessionName, err := newService(mockLocationRepo).ResolveAutoSession(mockUser, now)
+
+		assert.NoError(t, err)
+		assert.Empty(t, sessionName)
+		mockLocationRepo.AssertNotCalled(t, "FindByUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Continues previous session within the gap", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockUser := createMockRecord()
+		mockUser.Id = "user123"
+		mockUser.Set("tracking_defaults", `{"auto_session_gap":30}`)
+
+		mockSessionRepo := &mocks.MockSessionRepository{}
+		mockSession := createMockRecord()
+		mockSession.Id = "session123"
+		mockSession.Set("name", "morning-run")
+
+		mockLocationRepo.On("FindByUser", "user123", mock.Anything, "-timestamp", 1, 0).
+			Return([]*models.Record{previousLocation("session123", now.Add(-10*time.Minute))}, nil)
+		mockSessionRepo.On("FindByID", "session123").Return(mockSession, nil)
+
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, mockSessionRepo, &testSessionService{})
+		sessionName, err := service.ResolveAutoSession(mockUser, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "morning-run", sessionName)
+		mockLocationRepo.AssertExpectations(t)
+		mockSessionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Starts a new session after the gap", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockUser := createMockRecord()
+		mockUser.Id = "user123"
+		mockUser.Set("tracking_defaults", `{"auto_session_gap":30}`)
+
+		mockLocationRepo.On("FindByUser", "user123", mock.Anything, "-timestamp", 1, 0).
+			Return([]*models.Record{previousLocation("session123", now.Add(-2*time.Hour))}, nil)
+
+		sessionName, err := newService(mockLocationRepo).ResolveAutoSession(mockUser, now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "auto-20250920-0830", sessionName)
+		mockLocationRepo.AssertExpectations(t)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockUser := createMockRecord()
+		mockUser.Id = "user123"
+		mockUser.Set("tracking_defaults", `{"auto_session_gap":30}`)
+
+		mockLocationRepo.On("FindByUser", "user123", mock.Anything, "-timestamp", 1, 0).
+			Return([]*models.Record{}, errors.New("database error"))
+
+		sessionName, err := newService(mockLocationRepo).ResolveAutoSession(mockUser, now)
+
+		assert.Error(t, err)
+		assert.Empty(t, sessionName)
+	})
+}
+
+func TestLocationService_ShouldDownsample(t *testing.T) {
+	now := time.Date(2025, 9, 20, 8, 30, 0, 0, time.UTC)
+
+	newService := func(locationRepo *mocks.MockLocationRepository) *LocationService {
+		return NewLocationService(locationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{})
+	}
+
+	location := func(at time.Time, latitude float64) *models.Record {
+		record := createMockRecord()
+		timestamp, _ := types.ParseDateTime(at)
+		record.Set("timestamp", timestamp)
+		record.Set("latitude", latitude)
+		record.Set("longitude", 19.0)
+		record.Set("session", "commute")
+		return record
+	}
+
+	t.Run("No policy", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockUser := createMockRecord()
+		mockUser.Id = "user123"
+
+		discard, err := newService(mockLocationRepo).ShouldDownsample(mockUser, nil, location(now, 47.0))
+
+		assert.NoError(t, err)
+		assert.False(t, discard)
+		mockLocationRepo.AssertNotCalled(t, "FindByUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Tracking defaults discard a point too soon", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockUser := createMockRecord()
+		mockUser.Id = "user123"
+		mockUser.Set("tracking_defaults", `{"downsampling":{"min_interval":5}}`)
+
+		mockLocationRepo.On("FindByUser", "user123", map[string]interface{}{"session": "commute"}, "-timestamp", 1, 0).
+			Return([]*models.Record{location(now.Add(-2*time.Second), 47.0)}, nil)
+
+		discard, err := newService(mockLocationRepo).ShouldDownsample(mockUser, nil, location(now, 47.01))
+
+		assert.NoError(t, err)
+		assert.True(t, discard)
+		mockLocationRepo.AssertExpectations(t)
+	})
+
+	t.Run("Session policy overrides tracking defaults", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockUser := createMockRecord()
+		mockUser.Id = "user123"
+		mockUser.Set("tracking_defaults", `{"downsampling":{"min_interval":5}}`)
+		session := createMockRecord()
+		session.Set("downsampling", `{"min_interval":0,"min_distance":0}`)
+
+		discard, err := newService(mockLocationRepo).ShouldDownsample(mockUser, session, location(now, 47.0))
+
+		assert.NoError(t, err)
+		assert.False(t, discard)
+	})
+
+	t.Run("Events are always stored", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockUser := createMockRecord()
+		mockUser.Id = "user123"
+		session := createMockRecord()
+		session.Set("downsampling", `{"min_distance":10}`)
+		record := location(now, 47.0)
+		record.Set("event", "sos")
+
+		discard, err := newService(mockLocationRepo).ShouldDownsample(mockUser, session, record)
+
+		assert.NoError(t, err)
+		assert.False(t, discard)
+	})
+
+	t.Run("First point of a session", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockUser := createMockRecord()
+		mockUser.Id = "user123"
+		mockUser.Set("tracking_defaults", `{"downsampling":{"min_distance":10}}`)
+
+		mockLocationRepo.On("FindByUser", "user123", mock.Anything, "-timestamp", 1, 0).Return([]*models.Record{}, nil)
+
+		discard, err := newService(mockLocationRepo).ShouldDownsample(mockUser, nil, location(now, 47.0))
+
+		assert.NoError(t, err)
+		assert.False(t, discard)
+	})
+}
+
+func TestLocationService_FindDuplicate(t *testing.T) {
+	now := time.Date(2025, 9, 20, 8, 30, 0, 0, time.UTC)
+
+	location := func(at time.Time, latitude float64) *models.Record {
+		record := createMockRecord()
+		timestamp, _ := types.ParseDateTime(at)
+		record.Set("user", "user123")
+		record.Set("timestamp", timestamp)
+		record.Set("latitude", latitude)
+		record.Set("longitude", 19.0)
+		record.Set("session", "commute")
+		return record
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{})
+
+		duplicate, err := service.FindDuplicate(location(now, 47.0))
+
+		assert.NoError(t, err)
+		assert.Nil(t, duplicate)
+		mockLocationRepo.AssertNotCalled(t, "FindByUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Identical point", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		stored := location(now, 47.0)
+		mockLocationRepo.On("FindByUser", "user123", map[string]interface{}{
+			"session": "commute",
+			"from":    "2025-09-20 08:30:00.000Z",
+			"to":      "2025-09-20 08:30:00.000Z",
+		}, "-timestamp", constants.DuplicateCandidates, 0).Return([]*models.Record{stored}, nil)
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithDeduplication(utils.DuplicateThresholds{})
+
+		duplicate, err := service.FindDuplicate(location(now, 47.0))
+
+		assert.NoError(t, err)
+		assert.Same(t, stored, duplicate)
+		mockLocationRepo.AssertExpectations(t)
+	})
+
+	t.Run("Queued points are written first", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		var flushed []string
+		mockLocationRepo.On("FindByUser", "user123", mock.Anything, "-timestamp", constants.DuplicateCandidates, 0).
+			Run(func(mock.Arguments) { assert.Equal(t, []string{"user123"}, flushed, "Flushed before reading") }).
+			Return([]*models.Record{}, nil)
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithDeduplication(utils.DuplicateThresholds{}).
+			WithPendingWritesFlusher(func(userID string) { flushed = append(flushed, userID) })
+
+		duplicate, err := service.WithContext(context.Background()).FindDuplicate(location(now, 47.0))
+
+		assert.NoError(t, err)
+		assert.Nil(t, duplicate)
+		mockLocationRepo.AssertExpectations(t)
+	})
+
+	t.Run("Within the limits", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		stored := location(now.Add(-2*time.Second), 47.0)
+		mockLocationRepo.On("FindByUser", "user123", mock.Anything, "-timestamp", constants.DuplicateCandidates, 0).
+			Return([]*models.Record{location(now.Add(-time.Second), 47.01), stored}, nil)
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithDeduplication(utils.DuplicateThresholds{MaxInterval: 5 * time.Second, MaxDistance: 20})
+
+		duplicate, err := service.FindDuplicate(location(now, 47.0001))
+
+		assert.NoError(t, err)
+		assert.Same(t, stored, duplicate)
+	})
+
+	t.Run("Events repeat the same event only", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockLocationRepo.On("FindByUser", "user123", mock.Anything, "-timestamp", constants.DuplicateCandidates, 0).
+			Return([]*models.Record{location(now, 47.0)}, nil)
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithDeduplication(utils.DuplicateThresholds{})
+		record := location(now, 47.0)
+		record.Set("event", "sos")
+
+		duplicate, err := service.FindDuplicate(record)
+
+		assert.NoError(t, err)
+		assert.Nil(t, duplicate)
+	})
+}
+
+func TestLocationService_CheckPlausibility(t *testing.T) {
+	now := time.Date(2025, 9, 20, 8, 30, 0, 0, time.UTC)
+
+	location := func(at time.Time, latitude float64) *models.Record {
+		record := createMockRecord()
+		timestamp, _ := types.ParseDateTime(at)
+		record.Set("user", "user123")
+		record.Set("timestamp", timestamp)
+		record.Set("latitude", latitude)
+		record.Set("longitude", 19.0)
+		record.Set("session", "commute")
+		return record
+	}
+	filter := utils.OutlierThresholds{MaxSpeed: 300 / 3.6}
+
+	t.Run("Disabled", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{})
+
+		err := service.CheckPlausibility(location(now, 48.0))
+
+		assert.NoError(t, err)
+		mockLocationRepo.AssertNotCalled(t, "FindByUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Plausible point", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockLocationRepo.On("FindByUser", "user123", map[string]interface{}{
+			"session": "commute",
+			"to":      "2025-09-20 08:30:00.000Z",
+		}, "-timestamp", 1, 0).Return([]*models.Record{location(now.Add(-time.Minute), 47.0)}, nil)
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithOutlierFilter(filter)
+
+		err := service.CheckPlausibility(location(now, 47.01))
+
+		assert.NoError(t, err)
+		mockLocationRepo.AssertExpectations(t)
+	})
+
+	t.Run("Teleport", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockLocationRepo.On("FindByUser", "user123", mock.Anything, "-timestamp", 1, 0).
+			Return([]*models.Record{location(now.Add(-time.Minute), 47.0)}, nil)
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithOutlierFilter(filter)
+
+		err := service.CheckPlausibility(location(now, 48.0))
+
+		var locationErr *LocationError
+		assert.ErrorAs(t, err, &locationErr)
+		assert.Contains(t, err.Error(), "above the limit of 300 km/h")
+	})
+
+	t.Run("First point of a session", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockLocationRepo.On("FindByUser", "user123", mock.Anything, "-timestamp", 1, 0).Return([]*models.Record{}, nil)
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithOutlierFilter(filter)
+
+		assert.NoError(t, service.CheckPlausibility(location(now, 48.0)))
+	})
+
+	t.Run("Events are always stored", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithOutlierFilter(filter)
+		record := location(now, 48.0)
+		record.Set("event", "sos")
+
+		assert.NoError(t, service.CheckPlausibility(record))
+		mockLocationRepo.AssertNotCalled(t, "FindByUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestLocationService_DeriveMotion(t *testing.T) {
+	now := time.Date(2025, 9, 20, 8, 30, 0, 0, time.UTC)
+
+	location := func(at time.Time, latitude float64) *models.Record {
+		record := createMockRecord()
+		timestamp, _ := types.ParseDateTime(at)
+		record.Set("user", "user123")
+		record.Set("timestamp", timestamp)
+		record.Set("latitude", latitude)
+		record.Set("longitude", 19.0)
+		record.Set("session", "commute")
+		return record
+	}
+	gaps := utils.GapThresholds{MaxInterval: 10 * time.Minute}
+
+	t.Run("Disabled", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{})
+		record := location(now, 47.01)
+
+		assert.NoError(t, service.DeriveMotion(record))
+		assert.Zero(t, record.GetFloat("speed"))
+		mockLocationRepo.AssertNotCalled(t, "FindByUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("From the previous point", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockLocationRepo.On("FindByUser", "user123", map[string]interface{}{
+			"session": "commute",
+			"to":      "2025-09-20 08:30:00.000Z",
+		}, "-timestamp", 1, 0).Return([]*models.Record{location(now.Add(-time.Minute), 47.0)}, nil)
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithDerivedMotion(gaps)
+		record := location(now, 47.01)
+
+		assert.NoError(t, service.DeriveMotion(record))
+		assert.InDelta(t, 18.53, record.GetFloat("speed"), 0.01)
+		if bearing := utils.RecordBearing(record); assert.NotNil(t, bearing, "Due north is a bearing") {
+			assert.Zero(t, *bearing)
+		}
+		mockLocationRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reported speed is kept", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockLocationRepo.On("FindByUser", "user123", mock.Anything, "-timestamp", 1, 0).
+			Return([]*models.Record{location(now.Add(-time.Minute), 47.0)}, nil)
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithDerivedMotion(gaps)
+		record := location(now, 47.01)
+		reported := 20.0
+		utils.SetRecordSpeed(record, &reported)
+
+		assert.NoError(t, service.DeriveMotion(record))
+		assert.Equal(t, 20.0, record.GetFloat("speed"))
+		assert.NotNil(t, utils.RecordBearing(record))
+	})
+
+	t.Run("Reported standstill and heading north are kept", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithDerivedMotion(gaps)
+		record := location(now, 47.01)
+		standstill, north := 0.0, 0.0
+		utils.SetRecordSpeed(record, &standstill)
+		utils.SetRecordBearing(record, &north)
+
+		assert.NoError(t, service.DeriveMotion(record))
+		assert.Zero(t, record.GetFloat("speed"))
+		assert.Zero(t, record.GetFloat("bearing"))
+		mockLocationRepo.AssertNotCalled(t, "FindByUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Nothing across a gap", func(t *testing.T) {
+		mockLocationRepo := &mocks.MockLocationRepository{}
+		mockLocationRepo.On("FindByUser", "user123", mock.Anything, "-timestamp", 1, 0).
+			Return([]*models.Record{location(now.Add(-time.Hour), 47.0)}, nil)
+		service := NewLocationService(mockLocationRepo, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{}).
+			WithDerivedMotion(gaps)
+		record := location(now, 47.01)
+
+		assert.NoError(t, service.DeriveMotion(record))
+		assert.Nil(t, utils.RecordSpeed(record))
+		assert.Nil(t, utils.RecordBearing(record))
+	})
+}
+
+func TestLocationService_SelectCurrentPosition(t *testing.T) {
+	service := NewLocationService(&mocks.MockLocationRepository{}, &mocks.MockUserRepository{}, &mocks.MockSessionRepository{}, &testSessionService{})
+	now := time.Now()
+
+	location := func(age time.Duration, accuracy float64) *models.Record {
+		record := createMockRecord()
+		timestamp, _ := types.ParseDateTime(now.Add(-age))
+		record.Set("timestamp", timestamp)
+		record.Set("accuracy", accuracy)
+		return record
+	}
+	locations := []*models.Record{location(0, 50), location(30*time.Second, 5), location(10*time.Minute, 1)}
+
+	t.Run("Newest by default", func(t *testing.T) {
+		mockUser := createMockRecord()
+
+		assert.Equal(t, 1, service.PositionCandidateLimit(mockUser))
+		assert.Same(t, locations[0], service.SelectCurrentPosition(mockUser, locations))
+	})
+
+	t.Run("Most accurate within the user's window", func(t *testing.T) {
+		mockUser := createMockRecord()
+		mockUser.Set("tracking_defaults", `{"location_policy":"most_accurate","accuracy_window":60}`)
+
+		assert.Equal(t, constants.LatestPositionCandidates, service.PositionCandidateLimit(mockUser))
+		assert.Same(t, locations[1], service.SelectCurrentPosition(mockUser, locations))
+	})
+
+	t.Run("No locations", func(t *testing.T) {
+		assert.Nil(t, service.SelectCurrentPosition(createMockRecord(), nil))
+	})
+}
+
 func TestLocationService_GetLatestLocationByUser(t *testing.T) {
 	t.Run("Successful retrieval", func(t *testing.T) {
 		// Setup mocks
@@ -282,8 +805,15 @@ func TestLocationService_GetLatestLocationByUser(t *testing.T) {
 		mockLocation.Set("longitude", -122.4194)
 		mockLocation.Set("latitude", 37.7749)
 		mockLocation.Set("altitude", 10.0)
+		mockLocation.Set("has_altitude", true)
 		mockLocation.Set("speed", 5.5)
+		mockLocation.Set("has_speed", true)
+		mockLocation.Set("bearing", 0.0) // Due north
+		mockLocation.Set("has_bearing", true)
 		mockLocation.Set("heart_rate", 140.0)
+		mockLocation.Set("power", 230.0)
+		mockLocation.Set("temperature", 0.0)
+		mockLocation.Set("has_temperature", true)
 		mockLocation.Set("timestamp", parsedTime)
 		mockLocation.Set("user", "user123")
 		mockLocation.Set("session", "")
@@ -314,6 +844,12 @@ func TestLocationService_GetLatestLocationByUser(t *testing.T) {
 		assert.Equal(t, 5.5, *result.Properties.Speed)
 		assert.NotNil(t, result.Properties.HeartRate)
 		assert.Equal(t, 140.0, *result.Properties.HeartRate)
+		assert.Nil(t, result.Properties.Cadence)
+		assert.Equal(t, 230.0, *result.Properties.Power)
+		assert.Equal(t, 0.0, *result.Properties.Temperature)
+		if assert.NotNil(t, result.Properties.Bearing) {
+			assert.Zero(t, *result.Properties.Bearing)
+		}
 
 		mockUserRepo.AssertExpectations(t)
 		mockLocationRepo.AssertExpectations(t)
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/utils"
+)
+
+// LoginAnomalyService flags logins from countries and networks that do not appear in the user's
+// login history and notifies the user about them by email
+type LoginAnomalyService struct {
+	app   *pocketbase.PocketBase
+	geoIP *GeoIPService
+}
+
+// NewLoginAnomalyService creates a new LoginAnomalyService instance
+func NewLoginAnomalyService(app *pocketbase.PocketBase, geoIP *GeoIPService) *LoginAnomalyService {
+	return &LoginAnomalyService{
+		app:   app,
+		geoIP: geoIP,
+	}
+}
+
+// CheckLogin classifies the device session created by a login and records its location. Suspicious
+// logins are logged as security events and reported to the user. Failures are logged only, a
+// login is never rejected because the check could not run.
+func (s *LoginAnomalyService) CheckLogin(sessionID string) {
+	if sessionID == "" || !s.geoIP.Enabled() {
+		return
+	}
+
+	dao := s.app.Dao()
+	session, err := dao.FindFirstRecordByFilter(constants.CollectionAuthSessions, "jti = {:jti}", dbx.Params{"jti": sessionID})
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Failed to find device session for login anomaly check")
+		return
+	}
+
+	current, err := s.geoIP.Lookup(session.GetString("ip"))
+	if err != nil {
+		utils.LogWarn().Err(err).Str("client_ip", session.GetString("ip")).Msg("Geo-IP lookup failed")
+		return
+	}
+	if current == (utils.NetworkLocation{}) {
+		return
+	}
+
+	previous, err := dao.FindRecordsByFilter(constants.CollectionAuthSessions, "user = {:user} && id != {:id}", "-created",
+		constants.AuthSessionHistoryLimit, 0, dbx.Params{"user": session.GetString("user"), "id": session.Id})
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Failed to fetch login history for anomaly check")
+		return
+	}
+
+	history := make([]utils.NetworkLocation, len(previous))
+	for i, record := range previous {
+		history[i] = utils.NetworkLocation{Country: record.GetString("country"), ASN: record.GetInt("asn")}
+	}
+	reasons := utils.DetectLoginAnomalies(history, current)
+
+	session.Set("country", current.Country)
+	session.Set("asn", current.ASN)
+	session.Set("suspicious_reasons", strings.Join(reasons, ","))
+	if err := dao.SaveRecord(session); err != nil {
+		utils.LogWarn().Err(err).Msg("Failed to record login location")
+		return
+	}
+
+	if len(reasons) == 0 {
+		return
+	}
+
+	utils.LogSuspiciousLogin(session.GetString("ip"), session.GetString("user"), current.Country, current.ASN, reasons)
+
+	user, err := dao.FindRecordById(constants.CollectionUsers, session.GetString("user"))
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Failed to find user for suspicious login notification")
+		return
+	}
+	// Sending mail may take a while, the login response should not wait for it
+	go s.notify(user, session, current)
+}
+
+// notify emails the user about a suspicious login
+func (s *LoginAnomalyService) notify(user *models.Record, session *models.Record, location utils.NetworkLocation) {
+	appName := s.app.Settings().Meta.AppName
+
+	where := location.Country
+	if where == "" {
+		where = "an unknown location"
+	}
+	details := fmt.Sprintf("%s from %s (IP address %s, network AS%d)",
+		utils.DescribeUserAgent(session.GetString("user_agent")), where, session.GetString("ip"), location.ASN)
+	text := fmt.Sprintf("Hello %s,\n\nyour %s account was just signed in to on %s, which we have not seen "+
+		"for your account before.\n\nIf this was you, no action is needed. Otherwise change your password and "+
+		"sign out the unknown device from your profile.\n", user.Username(), appName, details)
+
+	subject := fmt.Sprintf("New sign-in to your %s account", appName)
+	if err := sendUserMail(s.app, user, subject, text); err != nil {
+		utils.LogError(err, "suspicious login notification").Str("user_id", user.Id).Msg("Failed to send notification email")
+	}
+}
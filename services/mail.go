@@ -0,0 +1,39 @@
+package services
+
+import (
+	"html"
+	"net/mail"
+	"strings"
+
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/mailer"
+)
+
+// sendUserMail emails a plain text message, with paragraphs separated by blank lines, to a user
+// from the configured sender. Nothing is sent without a sender address or a user email.
+func sendUserMail(app *pocketbase.PocketBase, user *models.Record, subject, text string) error {
+	if user.Email() == "" {
+		return nil
+	}
+	return sendMail(app, mail.Address{Address: user.Email()}, subject, text)
+}
+
+// sendMail emails a plain text message to any address from the configured sender. Nothing is sent
+// without a sender address.
+func sendMail(app *pocketbase.PocketBase, to mail.Address, subject, text string) error {
+	meta := app.Settings().Meta
+	if meta.SenderAddress == "" {
+		return nil
+	}
+
+	message := &mailer.Message{
+		From:    mail.Address{Name: meta.SenderName, Address: meta.SenderAddress},
+		To:      []mail.Address{to},
+		Subject: subject,
+		Text:    text,
+		HTML:    "<p>" + strings.ReplaceAll(html.EscapeString(text), "\n\n", "</p><p>") + "</p>",
+	}
+
+	return app.NewMailClient().Send(message)
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// MapMatchService snaps session tracks to roads with a Valhalla or OSRM backend. The snapped
+// position of each location is stored next to the recorded one, so noisy urban tracks can be
+// shown on the roads they followed without losing the raw data.
+type MapMatchService struct {
+	app    *pocketbase.PocketBase
+	config *config.TrackingConfig
+	client *http.Client
+}
+
+// NewMapMatchService creates a new MapMatchService instance
+func NewMapMatchService(app *pocketbase.PocketBase, trackingConfig *config.TrackingConfig) *MapMatchService {
+	return &MapMatchService{
+		app:    app,
+		config: trackingConfig,
+		client: &http.Client{Timeout: constants.MapMatchTimeout},
+	}
+}
+
+// Enabled reports whether a map matching backend is configured
+func (s *MapMatchService) Enabled() bool {
+	return s.config.MapMatchURL != ""
+}
+
+// MatchSession snaps the locations of a session to roads and stores the snapped positions,
+// replacing those of an earlier match. Locations the backend cannot snap keep no snapped position.
+func (s *MapMatchService) MatchSession(session *models.Record) (*appmodels.MapMatchResponse, error) {
+	userID := session.GetString("user")
+
+	dao := s.app.Dao()
+	locations, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+		"session = {:session}", "timestamp", 0, 0, dbx.Params{"session": session.Id})
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch session locations", userID)
+	}
+	if len(locations) == 0 {
+		return nil, utils.NewValidationError("Session has no locations to match")
+	}
+
+	points := make([]utils.TimedPoint, len(locations))
+	for i, location := range locations {
+		points[i] = utils.TimedPoint{
+			Timestamp: location.GetDateTime("timestamp").Time(),
+			Latitude:  location.GetFloat("latitude"),
+			Longitude: location.GetFloat("longitude"),
+		}
+	}
+	segments, _ := utils.SegmentTrack(points, utils.GapThresholds{
+		MaxInterval: s.config.GapMaxInterval,
+		MaxDistance: s.config.GapMaxDistance,
+	})
+
+	chunkSize := constants.ValhallaMatchChunkSize
+	if s.config.MapMatchProvider == constants.MapMatchProviderOSRM {
+		chunkSize = constants.OSRMMatchChunkSize
+	}
+
+	positions := make([]utils.MatchedPosition, len(points))
+	for _, chunk := range utils.MatchChunks(segments, chunkSize) {
+		matched, err := s.matchChunk(points[chunk[0]:chunk[1]])
+		if err != nil {
+			return nil, utils.NewExternalError("map matching", err)
+		}
+		for i, position := range matched {
+			// The point shared by two chunks keeps whichever match succeeded
+			if position.Matched || !positions[chunk[0]+i].Matched {
+				positions[chunk[0]+i] = position
+			}
+		}
+	}
+
+	response := &appmodels.MapMatchResponse{
+		SessionID:  session.Id,
+		Provider:   s.config.MapMatchProvider,
+		PointCount: len(locations),
+	}
+	matchedAt := types.NowDateTime()
+
+	err = dao.RunInTransaction(func(txDao *daos.Dao) error {
+		for i, location := range locations {
+			position := positions[i]
+			if position.Matched {
+				response.MatchedCount++
+			} else {
+				position = utils.MatchedPosition{}
+			}
+
+			// Written directly, snapping is no change of the location and must not touch "updated"
+			_, err := txDao.DB().Update(constants.CollectionLocations, dbx.Params{
+				"matched_latitude":  position.Latitude,
+				"matched_longitude": position.Longitude,
+			}, dbx.HashExp{"id": location.Id}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := txDao.DB().Update(constants.CollectionSessions, dbx.Params{"map_matched_at": matchedAt.String()},
+			dbx.HashExp{"id": session.Id}).Execute()
+		return err
+	})
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to store map matched track", userID)
+	}
+
+	response.MatchedAt = matchedAt.Time()
+	return response, nil
+}
+
+// matchChunk snaps a chunk of points with the configured backend
+func (s *MapMatchService) matchChunk(points []utils.TimedPoint) ([]utils.MatchedPosition, error) {
+	if len(points) < 2 {
+		return make([]utils.MatchedPosition, len(points)), nil
+	}
+
+	if s.config.MapMatchProvider == constants.MapMatchProviderOSRM {
+		return s.matchOSRM(points)
+	}
+	return s.matchValhalla(points)
+}
+
+// matchValhalla snaps points with Valhalla's trace_attributes API
+func (s *MapMatchService) matchValhalla(points []utils.TimedPoint) ([]utils.MatchedPosition, error) {
+	shape := make([]map[string]float64, len(points))
+	for i, point := range points {
+		shape[i] = map[string]float64{"lat": point.Latitude, "lon": point.Longitude}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"shape":       shape,
+		"costing":     s.config.MapMatchCosting,
+		"shape_match": "map_snap",
+		"filters": map[string]interface{}{
+			"attributes": []string{"matched.point", "matched.type"},
+			"action":     "include",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Post(s.config.MapMatchURL+"/trace_attributes", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Valhalla answers 400 when no path matches the points
+	if resp.StatusCode == http.StatusBadRequest {
+		return make([]utils.MatchedPosition, len(points)), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("valhalla returned status %d", resp.StatusCode)
+	}
+
+	result, err := io.ReadAll(io.LimitReader(resp.Body, 16*1024*1024))
+	if err != nil {
+		return nil, err
+	}
+	return utils.ParseValhallaMatchedPoints(result, len(points))
+}
+
+// matchOSRM snaps points with OSRM's match service
+func (s *MapMatchService) matchOSRM(points []utils.TimedPoint) ([]utils.MatchedPosition, error) {
+	coordinates := make([]string, len(points))
+	timestamps := make([]string, len(points))
+	for i, point := range points {
+		coordinates[i] = strconv.FormatFloat(point.Longitude, 'f', 6, 64) + "," + strconv.FormatFloat(point.Latitude, 'f', 6, 64)
+		timestamps[i] = strconv.FormatInt(point.Timestamp.Unix(), 10)
+	}
+
+	matchURL := fmt.Sprintf("%s/match/v1/%s/%s?overview=false&timestamps=%s", s.config.MapMatchURL,
+		url.PathEscape(s.config.MapMatchCosting), strings.Join(coordinates, ";"), strings.Join(timestamps, ";"))
+
+	resp, err := s.client.Get(matchURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// OSRM reports failed matches such as NoMatch with status 400 and a code in the body
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return nil, fmt.Errorf("osrm returned status %d", resp.StatusCode)
+	}
+
+	result, err := io.ReadAll(io.LimitReader(resp.Body, 16*1024*1024))
+	if err != nil {
+		return nil, err
+	}
+	return utils.ParseOSRMTracepoints(result, len(points))
+}
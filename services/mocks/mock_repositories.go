@@ -1,10 +1,13 @@
 package mocks
 
 import (
+	"context"
 	"time"
 
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/stretchr/testify/mock"
+
+	"vibe-tracker/repositories"
 )
 
 // MockLocationRepository is a mock implementation of LocationRepository
@@ -12,6 +15,11 @@ type MockLocationRepository struct {
 	mock.Mock
 }
 
+// WithContext returns the mock itself, expectations do not depend on the context
+func (m *MockLocationRepository) WithContext(ctx context.Context) repositories.LocationRepository {
+	return m
+}
+
 func (m *MockLocationRepository) Create(location *models.Record) error {
 	args := m.Called(location)
 	return args.Error(0)
@@ -52,6 +60,11 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
+// WithContext returns the mock itself, expectations do not depend on the context
+func (m *MockUserRepository) WithContext(ctx context.Context) repositories.UserRepository {
+	return m
+}
+
 func (m *MockUserRepository) FindByUsername(username string) (*models.Record, error) {
 	args := m.Called(username)
 	return args.Get(0).(*models.Record), args.Error(1)
@@ -82,6 +95,11 @@ type MockSessionRepository struct {
 	mock.Mock
 }
 
+// WithContext returns the mock itself, expectations do not depend on the context
+func (m *MockSessionRepository) WithContext(ctx context.Context) repositories.SessionRepository {
+	return m
+}
+
 func (m *MockSessionRepository) FindByUser(userID string, sort string, limit, offset int) ([]*models.Record, error) {
 	args := m.Called(userID, sort, limit, offset)
 	return args.Get(0).([]*models.Record), args.Error(1)
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// routeCosting maps route planning profiles to Valhalla costing models and OSRM profiles
+var routeCosting = map[string]map[string]string{
+	constants.RoutingProviderValhalla: {
+		constants.RouteProfileFoot: "pedestrian",
+		constants.RouteProfileBike: "bicycle",
+	},
+	constants.RoutingProviderOSRM: {
+		constants.RouteProfileFoot: "foot",
+		constants.RouteProfileBike: "bike",
+	},
+}
+
+// RoutingService plans routes with a Valhalla or OSRM routing engine and adds elevations from a
+// Valhalla height service, so planned routes come with an elevation profile and climbs
+type RoutingService struct {
+	config *config.TrackingConfig
+	client *http.Client
+}
+
+// NewRoutingService creates a new RoutingService instance
+func NewRoutingService(trackingConfig *config.TrackingConfig) *RoutingService {
+	return &RoutingService{
+		config: trackingConfig,
+		client: &http.Client{Timeout: constants.RoutingTimeout},
+	}
+}
+
+// Enabled reports whether a routing engine is configured
+func (s *RoutingService) Enabled() bool {
+	return s.config.RoutingURL != ""
+}
+
+// Plan returns the route between two positions for a profile. Elevations are left out when no
+// height service is configured or it fails, the route itself is still useful without them.
+func (s *RoutingService) Plan(fromLat, fromLon, toLat, toLon float64, profile string) (*appmodels.RouteFeature, error) {
+	costing, ok := routeCosting[s.config.RoutingProvider][profile]
+	if !ok {
+		return nil, utils.NewValidationError("Unsupported route profile", profile)
+	}
+
+	var body []byte
+	var status int
+	var err error
+	if s.config.RoutingProvider == constants.RoutingProviderOSRM {
+		routeURL := fmt.Sprintf("%s/route/v1/%s/%f,%f;%f,%f?overview=full&geometries=geojson",
+			s.config.RoutingURL, url.PathEscape(costing), fromLon, fromLat, toLon, toLat)
+		body, status, err = s.do(http.MethodGet, routeURL, nil)
+	} else {
+		body, status, err = s.do(http.MethodPost, s.config.RoutingURL+"/route", map[string]interface{}{
+			"locations":       []map[string]float64{{"lat": fromLat, "lon": fromLon}, {"lat": toLat, "lon": toLon}},
+			"costing":         costing,
+			"directions_type": "none",
+		})
+	}
+	if err != nil {
+		return nil, utils.NewExternalError("routing", err)
+	}
+
+	// Both engines answer 400 when the positions cannot be connected, e.g. across the sea
+	if status == http.StatusBadRequest {
+		return nil, utils.NewNotFoundError("Route", "")
+	}
+	if status != http.StatusOK {
+		return nil, utils.NewExternalError("routing", fmt.Errorf("routing engine returned status %d", status))
+	}
+
+	var coordinates [][]float64
+	var distance, duration float64
+	if s.config.RoutingProvider == constants.RoutingProviderOSRM {
+		coordinates, distance, duration, err = utils.ParseOSRMRoute(body)
+	} else {
+		coordinates, distance, duration, err = utils.ParseValhallaRoute(body)
+	}
+	if err != nil {
+		return nil, utils.NewExternalError("routing", err)
+	}
+
+	heights, err := s.heights(coordinates)
+	if err != nil {
+		utils.LogWarn().Err(err).Msg("Failed to fetch route elevations")
+	}
+
+	route := utils.BuildRouteFeature(profile, coordinates, heights, distance, duration)
+	return &route, nil
+}
+
+// heights looks up the elevation of each position with Valhalla's height service
+func (s *RoutingService) heights(coordinates [][]float64) ([]*float64, error) {
+	if s.config.ElevationURL == "" || len(coordinates) == 0 {
+		return nil, nil
+	}
+
+	shape := make([]map[string]float64, len(coordinates))
+	for i, coordinate := range coordinates {
+		shape[i] = map[string]float64{"lat": coordinate[1], "lon": coordinate[0]}
+	}
+
+	body, status, err := s.do(http.MethodPost, s.config.ElevationURL+"/height", map[string]interface{}{
+		"shape": shape,
+		"range": false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("height service returned status %d", status)
+	}
+
+	return utils.ParseValhallaHeights(body, len(coordinates))
+}
+
+// do sends a request with an optional JSON body and returns the response body and status
+func (s *RoutingService) do(method, requestURL string, payload interface{}) ([]byte, int, error) {
+	var reader io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, 0, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, requestURL, reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16*1024*1024))
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/plugins"
+	"vibe-tracker/utils"
+)
+
+// SessionCloserService ends sessions that stopped receiving locations, so forgotten trackers do
+// not leave them open indefinitely. Ended sessions get their statistics computed and a
+// session.ended webhook is sent.
+type SessionCloserService struct {
+	app      *pocketbase.PocketBase
+	config   *config.TrackingConfig
+	webhooks *WebhookService
+	hooks    *plugins.Hooks
+	stop     chan struct{}
+}
+
+// NewSessionCloserService creates a new SessionCloserService instance
+func NewSessionCloserService(app *pocketbase.PocketBase, trackingConfig *config.TrackingConfig, webhooks *WebhookService) *SessionCloserService {
+	return &SessionCloserService{
+		app:      app,
+		config:   trackingConfig,
+		webhooks: webhooks,
+		stop:     make(chan struct{}),
+	}
+}
+
+// WithPluginHooks triggers the plugin OnSessionClosed hook for every ended session
+func (s *SessionCloserService) WithPluginHooks(hooks *plugins.Hooks) *SessionCloserService {
+	s.hooks = hooks
+	return s
+}
+
+// Start runs the periodic inactivity check while the server is running
+func (s *SessionCloserService) Start(e *core.ServeEvent) error {
+	if s.config.SessionInactivityTimeout <= 0 {
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(constants.SessionCloseCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.CloseInactiveSessions(time.Now()); err != nil {
+					utils.LogError(err, "session auto-close").Msg("Failed to end inactive sessions")
+					utils.ReportJobError(err, "session auto-close")
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop ends the periodic inactivity check
+func (s *SessionCloserService) Stop(e *core.TerminateEvent) error {
+	close(s.stop)
+	return nil
+}
+
+// CloseInactiveSessions ends open sessions whose latest location is older than the inactivity
+// timeout and returns how many were ended. Sessions without any location, e.g. planned routes,
+// are left open.
+func (s *SessionCloserService) CloseInactiveSessions(now time.Time) (int, error) {
+	dao := s.app.Dao()
+	sessions, err := dao.FindRecordsByFilter(constants.CollectionSessions, "ended_at = ''", "updated", 0, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := now.Add(-s.config.SessionInactivityTimeout)
+	closed := 0
+	for _, session := range sessions {
+		latest, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+			"session = {:session}", "-timestamp", 1, 0, dbx.Params{"session": session.Id})
+		if err != nil {
+			utils.LogWarn().Err(err).Str("session_id", session.Id).Msg("Failed to find latest session location")
+			continue
+		}
+		if len(latest) == 0 || latest[0].GetDateTime("timestamp").Time().After(cutoff) {
+			continue
+		}
+
+		if err := s.endSession(session, latest[0].GetDateTime("timestamp")); err != nil {
+			utils.LogWarn().Err(err).Str("session_id", session.Id).Msg("Failed to end inactive session")
+			continue
+		}
+		closed++
+	}
+
+	if closed > 0 {
+		utils.LogInfo().Int("sessions", closed).Msg("Ended inactive sessions")
+	}
+	return closed, nil
+}
+
+// endSession marks a session as ended at its last location, stores its statistics and notifies
+// webhook endpoints and plugins
+func (s *SessionCloserService) endSession(session *models.Record, endedAt types.DateTime) error {
+	dao := s.app.Dao()
+	locations, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+		"session = {:session}", "timestamp", 0, 0, dbx.Params{"session": session.Id})
+	if err != nil {
+		return err
+	}
+
+	points := make([]utils.TimedPoint, len(locations))
+	for i, location := range locations {
+		points[i] = utils.TimedPoint{
+			Timestamp: location.GetDateTime("timestamp").Time(),
+			Latitude:  location.GetFloat("latitude"),
+			Longitude: location.GetFloat("longitude"),
+			Altitude:  utils.RecordAltitude(location),
+		}
+	}
+	stats := utils.ComputeTrackStats(points, utils.GapThresholds{
+		MaxInterval: s.config.GapMaxInterval,
+		MaxDistance: s.config.GapMaxDistance,
+	})
+
+	session.Set("ended_at", endedAt)
+	session.Set("stats", stats)
+	if err := dao.SaveRecord(session); err != nil {
+		return err
+	}
+
+	s.webhooks.Send(constants.WebhookEventSessionEnded, appmodels.SessionEndedEvent{
+		SessionID: session.Id,
+		Name:      session.GetString("name"),
+		Title:     session.GetString("title"),
+		UserID:    session.GetString("user"),
+		EndedAt:   endedAt.Time(),
+		Stats:     stats,
+	})
+	if s.hooks != nil {
+		s.hooks.SessionClosed(session, stats)
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+
 	"github.com/pocketbase/pocketbase/models"
 
 	"vibe-tracker/constants"
@@ -19,6 +21,11 @@ func NewSessionService(repo repositories.SessionRepository) *SessionService {
 	return &SessionService{repo: repo}
 }
 
+// WithContext returns a copy of the service whose queries are aborted when ctx is cancelled
+func (s *SessionService) WithContext(ctx context.Context) *SessionService {
+	return &SessionService{repo: s.repo.WithContext(ctx)}
+}
+
 // ListSessions returns paginated sessions for a user
 func (s *SessionService) ListSessions(userID string, page, perPage int) (*appmodels.SessionsListResponse, error) {
 	if page < constants.DefaultPage {
@@ -93,6 +100,8 @@ func (s *SessionService) CreateSession(req appmodels.CreateSessionRequest, userI
 	session.Set("title", s.generateTitle(req))
 	session.Set("description", req.Description)
 	session.Set("public", req.Public)
+	session.Set("draft", req.Draft)
+	session.Set("activity", req.Activity)
 
 	if err := s.repo.Create(session); err != nil {
 		return nil, err
@@ -115,6 +124,17 @@ func (s *SessionService) UpdateSession(sessionName, userID string, req appmodels
 	}
 	session.Set("description", req.Description)
 	session.Set("public", req.Public)
+	if req.Activity != nil {
+		session.Set("activity", *req.Activity)
+	}
+	if req.ShowViewerCount != nil {
+		session.Set("show_viewer_count", *req.ShowViewerCount)
+	}
+	if req.ResetDownsampling {
+		session.Set("downsampling", nil)
+	} else if req.Downsampling != nil {
+		session.Set("downsampling", req.Downsampling)
+	}
 
 	if err := s.repo.Update(session); err != nil {
 		return nil, err
@@ -182,14 +202,19 @@ func (s *SessionService) generateTitle(req appmodels.CreateSessionRequest) strin
 
 func (s *SessionService) recordToSession(record *models.Record) appmodels.Session {
 	return appmodels.Session{
-		ID:          record.Id,
-		Name:        record.GetString("name"),
-		Title:       record.GetString("title"),
-		Description: record.GetString("description"),
-		Public:      record.GetBool("public"),
-		User:        record.GetString("user"),
-		Created:     record.Created.Time(),
-		Updated:     record.Updated.Time(),
+		ID:              record.Id,
+		Name:            record.GetString("name"),
+		Title:           record.GetString("title"),
+		Description:     record.GetString("description"),
+		DescriptionHTML: utils.RenderMarkdown(record.GetString("description")),
+		Public:          record.GetBool("public"),
+		User:            record.GetString("user"),
+		Organization:    record.GetString("organization"),
+		ShowViewerCount: record.GetBool("show_viewer_count"),
+		Draft:           record.GetBool("draft"),
+		Activity:        record.GetString("activity"),
+		Created:         record.Created.Time(),
+		Updated:         record.Updated.Time(),
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/stretchr/testify/assert"
 
 	"vibe-tracker/constants"
@@ -260,11 +261,11 @@ func TestSessionService_CreateSession(t *testing.T) {
 			Name:        "morning-run",
 			Title:       "Morning Run",
 			Description: "My daily morning run",
-			Public:      true,
+			Public:      types.Pointer(true),
 		}
 
 		newRecord := createMockSessionRecord()
-		createdRecord := createTestSessionRecord("session1", req.Name, req.Title, userID, req.Public)
+		createdRecord := createTestSessionRecord("session1", req.Name, req.Title, userID, *req.Public)
 		createdRecord.Set("description", req.Description)
 
 		// Setup expectations
@@ -284,7 +285,7 @@ func TestSessionService_CreateSession(t *testing.T) {
 		assert.Equal(t, req.Name, result.Name)
 		assert.Equal(t, req.Title, result.Title)
 		assert.Equal(t, req.Description, result.Description)
-		assert.Equal(t, req.Public, result.Public)
+		assert.Equal(t, *req.Public, result.Public)
 
 		mockRepo.AssertExpectations(t)
 	})
@@ -296,7 +297,7 @@ func TestSessionService_CreateSession(t *testing.T) {
 		userID := "user123"
 		req := appmodels.CreateSessionRequest{
 			Name:   "morning-run",
-			Public: false,
+			Public: types.Pointer(false),
 		}
 
 		newRecord := createMockSessionRecord()
@@ -329,7 +330,7 @@ func TestSessionService_CreateSession(t *testing.T) {
 		userID := "user123"
 		req := appmodels.CreateSessionRequest{
 			Name:   "existing-session",
-			Public: true,
+			Public: types.Pointer(true),
 		}
 
 		existingSession := createTestSessionRecord("session1", req.Name, "Existing Session", userID, true)
@@ -358,7 +359,7 @@ func TestSessionService_CreateSession(t *testing.T) {
 		userID := "user123"
 		req := appmodels.CreateSessionRequest{
 			Name:   "new-session",
-			Public: true,
+			Public: types.Pointer(true),
 		}
 
 		expectedError := errors.New("database error")
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// surfaceAttributes are the trace_attributes fields needed for surface segments
+var surfaceAttributes = []string{
+	"edge.length", "edge.surface", "edge.road_class", "edge.use",
+	"edge.begin_shape_index", "edge.end_shape_index", "shape",
+}
+
+// SurfaceService matches the tracks of ended sessions to OSM ways with a Valhalla instance and
+// stores the surface and road type of each stretch, so session statistics can show how much of a
+// track was paved. A background job works through ended sessions not matched yet.
+type SurfaceService struct {
+	app    *pocketbase.PocketBase
+	config *config.TrackingConfig
+	client *http.Client
+	stop   chan struct{}
+}
+
+// NewSurfaceService creates a new SurfaceService instance
+func NewSurfaceService(app *pocketbase.PocketBase, trackingConfig *config.TrackingConfig) *SurfaceService {
+	return &SurfaceService{
+		app:    app,
+		config: trackingConfig,
+		client: &http.Client{Timeout: constants.MapMatchTimeout},
+		stop:   make(chan struct{}),
+	}
+}
+
+// Enabled reports whether a Valhalla map matching service is configured, OSRM does not report
+// way surfaces
+func (s *SurfaceService) Enabled() bool {
+	return s.config.MapMatchURL != "" && s.config.MapMatchProvider == constants.MapMatchProviderValhalla
+}
+
+// Start runs the periodic surface matching of ended sessions while the server is running
+func (s *SurfaceService) Start(e *core.ServeEvent) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(constants.SurfaceJobInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.MatchPending(); err != nil {
+					utils.LogError(err, "surface matching").Msg("Failed to match session surfaces")
+					utils.ReportJobError(err, "surface matching")
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop ends the periodic surface matching
+func (s *SurfaceService) Stop(e *core.TerminateEvent) error {
+	close(s.stop)
+	return nil
+}
+
+// MatchPending matches a batch of ended sessions without surface data and returns how many were
+// matched. A failing request ends the batch, the remaining sessions are retried next run.
+func (s *SurfaceService) MatchPending() (int, error) {
+	dao := s.app.Dao()
+	sessions, err := dao.FindRecordsByFilter(constants.CollectionSessions,
+		"ended_at != '' && surface_matched_at = ''", "ended_at", constants.SurfaceBatchSize, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, session := range sessions {
+		surface, err := s.match(session)
+		if err != nil {
+			return i, err
+		}
+
+		encoded, err := json.Marshal(surface)
+		if err != nil {
+			return i, err
+		}
+
+		matchedAt, err := types.ParseDateTime(surface.MatchedAt)
+		if err != nil {
+			return i, err
+		}
+
+		// Written directly, the enrichment is no change of the session and must not touch "updated"
+		_, err = dao.DB().Update(constants.CollectionSessions, dbx.Params{
+			"surface":            string(encoded),
+			"surface_matched_at": matchedAt.String(),
+		}, dbx.HashExp{"id": session.Id}).Execute()
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(sessions), nil
+}
+
+// match matches the track of a session segment by segment, so gaps are not matched as travel
+func (s *SurfaceService) match(session *models.Record) (*appmodels.SessionSurface, error) {
+	locations, err := s.app.Dao().FindRecordsByFilter(constants.CollectionLocations,
+		"session = {:session}", "timestamp", 0, 0, dbx.Params{"session": session.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]utils.TimedPoint, len(locations))
+	for i, location := range locations {
+		points[i] = utils.TimedPoint{
+			Timestamp: location.GetDateTime("timestamp").Time(),
+			Latitude:  location.GetFloat("latitude"),
+			Longitude: location.GetFloat("longitude"),
+		}
+	}
+	segmentIndexes, _ := utils.SegmentTrack(points, utils.GapThresholds{
+		MaxInterval: s.config.GapMaxInterval,
+		MaxDistance: s.config.GapMaxDistance,
+	})
+
+	segments := []appmodels.SurfaceSegment{}
+	for _, chunk := range utils.MatchChunks(segmentIndexes, constants.ValhallaMatchChunkSize) {
+		matched, err := s.traceAttributes(points[chunk[0]:chunk[1]])
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, matched...)
+	}
+
+	return &appmodels.SessionSurface{
+		MatchedAt: time.Now(),
+		Summary:   utils.SummarizeSurface(segments),
+		Segments:  segments,
+	}, nil
+}
+
+// traceAttributes matches a chunk of points with Valhalla's trace_attributes API. Chunks Valhalla
+// cannot match, e.g. outside its map data, are skipped.
+func (s *SurfaceService) traceAttributes(points []utils.TimedPoint) ([]appmodels.SurfaceSegment, error) {
+	if len(points) < 2 {
+		return nil, nil
+	}
+
+	shape := make([]map[string]float64, len(points))
+	for i, point := range points {
+		shape[i] = map[string]float64{"lat": point.Latitude, "lon": point.Longitude}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"shape":       shape,
+		"costing":     s.config.MapMatchCosting,
+		"shape_match": "map_snap",
+		"filters": map[string]interface{}{
+			"attributes": surfaceAttributes,
+			"action":     "include",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Post(s.config.MapMatchURL+"/trace_attributes", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("map matching failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Valhalla answers 400 when no path matches the points
+	if resp.StatusCode == http.StatusBadRequest {
+		utils.LogDebug().Int("points", len(points)).Msg("Map matching found no path for track chunk")
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("map matching returned status %d", resp.StatusCode)
+	}
+
+	result, err := io.ReadAll(io.LimitReader(resp.Body, 16*1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read map matching response: %w", err)
+	}
+
+	return utils.ParseTraceAttributes(result)
+}
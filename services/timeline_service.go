@@ -0,0 +1,209 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// TimelineService computes daily timelines of stays and movements from users' raw locations. A
+// background job recomputes the days that received new locations, days nobody computed yet are
+// computed when first requested.
+type TimelineService struct {
+	app  *pocketbase.PocketBase
+	stop chan struct{}
+}
+
+// NewTimelineService creates a new TimelineService instance
+func NewTimelineService(app *pocketbase.PocketBase) *TimelineService {
+	return &TimelineService{
+		app:  app,
+		stop: make(chan struct{}),
+	}
+}
+
+// Start runs the periodic timeline computation while the server is running
+func (s *TimelineService) Start(e *core.ServeEvent) error {
+	go func() {
+		ticker := time.NewTicker(constants.TimelineJobInterval)
+		defer ticker.Stop()
+
+		// Locations stored while the server was down are picked up by the first run
+		since := time.Now().Add(-24 * time.Hour)
+		for {
+			select {
+			case now := <-ticker.C:
+				if err := s.ComputeRecent(since); err != nil {
+					utils.LogError(err, "timeline computation").Msg("Failed to compute timelines")
+					utils.ReportJobError(err, "timeline computation")
+					continue
+				}
+				since = now
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop ends the periodic timeline computation
+func (s *TimelineService) Stop(e *core.TerminateEvent) error {
+	close(s.stop)
+	return nil
+}
+
+// ComputeRecent recomputes the timelines of all days that received locations since the given time
+func (s *TimelineService) ComputeRecent(since time.Time) error {
+	sinceDate, _ := types.ParseDateTime(since)
+
+	var rows []struct {
+		User string `db:"user"`
+		Day  string `db:"day"`
+	}
+	err := s.app.Dao().DB().
+		NewQuery("SELECT DISTINCT user, substr(timestamp, 1, 10) AS day FROM locations WHERE created > {:since}").
+		Bind(dbx.Params{"since": sinceDate.String()}).
+		All(&rows)
+	if err != nil {
+		return err
+	}
+
+	users := map[string]*models.Record{}
+	computed := map[string]bool{}
+	for _, row := range rows {
+		user, ok := users[row.User]
+		if !ok {
+			if user, err = s.app.Dao().FindRecordById(constants.CollectionUsers, row.User); err != nil {
+				utils.LogWarn().Err(err).Str("user_id", row.User).Msg("Failed to find user for timeline")
+				continue
+			}
+			users[row.User] = user
+		}
+
+		// Stored timestamps are UTC, a UTC day overlaps one or two days in the user's time zone
+		dayStart, err := time.Parse(constants.TimelineDateFormat, row.Day)
+		if err != nil {
+			continue
+		}
+		location := s.userLocation(user)
+		for _, t := range []time.Time{dayStart, dayStart.Add(24*time.Hour - time.Nanosecond)} {
+			date := t.In(location).Format(constants.TimelineDateFormat)
+			if computed[user.Id+date] {
+				continue
+			}
+			computed[user.Id+date] = true
+
+			if _, err := s.Compute(user, date); err != nil {
+				utils.LogWarn().Err(err).Str("user_id", user.Id).Str("date", date).Msg("Failed to compute timeline")
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get returns the timeline of a day in the user's time zone, computing it if it was not yet
+func (s *TimelineService) Get(user *models.Record, date string) (*appmodels.TimelineResponse, error) {
+	timezone := s.userLocation(user).String()
+
+	record, err := s.app.Dao().FindFirstRecordByFilter(constants.CollectionTimelines,
+		"user = {:user} && date = {:date}", dbx.Params{"user": user.Id, "date": date})
+	if err == nil && record.GetString("timezone") == timezone {
+		timeline := &appmodels.TimelineResponse{
+			Date:       date,
+			Timezone:   timezone,
+			ComputedAt: record.GetDateTime("computed_at").Time(),
+			Entries:    []appmodels.TimelineEntry{},
+		}
+		if err := json.Unmarshal([]byte(record.GetString("entries")), &timeline.Entries); err == nil {
+			return timeline, nil
+		}
+	}
+
+	timeline, err := s.Compute(user, date)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to compute timeline", user.Id)
+	}
+	return timeline, nil
+}
+
+// Compute builds the timeline of a day in the user's time zone from the raw locations and stores it
+func (s *TimelineService) Compute(user *models.Record, date string) (*appmodels.TimelineResponse, error) {
+	location := s.userLocation(user)
+	dayStart, err := time.ParseInLocation(constants.TimelineDateFormat, date, location)
+	if err != nil {
+		return nil, err
+	}
+	from, _ := types.ParseDateTime(dayStart)
+	to, _ := types.ParseDateTime(dayStart.AddDate(0, 0, 1))
+
+	dao := s.app.Dao()
+	records, err := dao.FindRecordsByFilter(constants.CollectionLocations,
+		"user = {:user} && timestamp >= {:from} && timestamp < {:to}", "timestamp", 0, 0,
+		dbx.Params{"user": user.Id, "from": from, "to": to})
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]utils.TimedPoint, len(records))
+	for i, record := range records {
+		points[i] = utils.TimedPoint{
+			Timestamp: record.GetDateTime("timestamp").Time(),
+			Latitude:  record.GetFloat("latitude"),
+			Longitude: record.GetFloat("longitude"),
+		}
+	}
+
+	timeline := &appmodels.TimelineResponse{
+		Date:       date,
+		Timezone:   location.String(),
+		ComputedAt: time.Now().UTC(),
+		Entries:    utils.BuildTimeline(points),
+	}
+
+	record, err := dao.FindFirstRecordByFilter(constants.CollectionTimelines,
+		"user = {:user} && date = {:date}", dbx.Params{"user": user.Id, "date": date})
+	if err != nil {
+		collection, err := dao.FindCollectionByNameOrId(constants.CollectionTimelines)
+		if err != nil {
+			return nil, err
+		}
+		record = models.NewRecord(collection)
+		record.Set("user", user.Id)
+		record.Set("date", date)
+	}
+	record.Set("timezone", timeline.Timezone)
+	record.Set("entries", timeline.Entries)
+	record.Set("computed_at", types.NowDateTime())
+	if err := dao.SaveRecord(record); err != nil {
+		return nil, err
+	}
+
+	return timeline, nil
+}
+
+// Today returns the current date in the user's time zone
+func (s *TimelineService) Today(user *models.Record) string {
+	return time.Now().In(s.userLocation(user)).Format(constants.TimelineDateFormat)
+}
+
+// userLocation returns the time zone the user's timeline is split into days in
+func (s *TimelineService) userLocation(user *models.Record) *time.Location {
+	location, err := time.LoadLocation(trackingDefaultsFromRecord(user).Timezone)
+	if err != nil {
+		utils.LogWarn().Err(err).Str("user_id", user.Id).Msg("Ignoring invalid time zone")
+		return time.UTC
+	}
+	return location
+}
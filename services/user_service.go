@@ -1,10 +1,15 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+
 	"github.com/pocketbase/pocketbase/models"
 
+	"vibe-tracker/constants"
 	appmodels "vibe-tracker/models"
 	"vibe-tracker/repositories"
+	"vibe-tracker/utils"
 )
 
 // UserService handles user-related business logic
@@ -17,6 +22,11 @@ func NewUserService(repo repositories.UserRepository) *UserService {
 	return &UserService{repo: repo}
 }
 
+// WithContext returns a copy of the service whose queries are aborted when ctx is cancelled
+func (s *UserService) WithContext(ctx context.Context) *UserService {
+	return &UserService{repo: s.repo.WithContext(ctx)}
+}
+
 // GetUserByUsername finds a user by username
 func (s *UserService) GetUserByUsername(username string) (*models.Record, error) {
 	return s.repo.FindByUsername(username)
@@ -64,6 +74,7 @@ func (s *UserService) ConvertToUserModel(record *models.Record) appmodels.User {
 		Username: record.Username(),
 		Email:    record.Email(),
 		Avatar:   record.GetString("avatar"),
+		Role:     utils.NormalizeRole(record.GetString("role")),
 		Created:  record.Created.String(),
 		Updated:  record.Updated.String(),
 	}
@@ -78,6 +89,86 @@ func (s *UserService) ValidateUserExists(username string) (*models.Record, error
 	return user, nil
 }
 
+// GetTrackingDefaults returns the user's tracking defaults, filling in application defaults for unset values
+func (s *UserService) GetTrackingDefaults(user *models.Record) appmodels.TrackingDefaults {
+	return trackingDefaultsFromRecord(user)
+}
+
+// UpdateTrackingDefaults applies the provided fields to the user's tracking defaults and saves them
+func (s *UserService) UpdateTrackingDefaults(user *models.Record, req appmodels.UpdateTrackingDefaultsRequest) (*appmodels.TrackingDefaults, error) {
+	defaults := trackingDefaultsFromRecord(user)
+
+	if req.DefaultSessionPublic != nil {
+		defaults.DefaultSessionPublic = *req.DefaultSessionPublic
+	}
+	if req.AutoSessionGap != nil {
+		defaults.AutoSessionGap = *req.AutoSessionGap
+	}
+	if req.PointInterval != nil {
+		defaults.PointInterval = *req.PointInterval
+	}
+	if req.SpeedUnit != nil {
+		defaults.SpeedUnit = *req.SpeedUnit
+	}
+	if req.LocationPolicy != nil {
+		defaults.LocationPolicy = *req.LocationPolicy
+	}
+	if req.AccuracyWindow != nil {
+		defaults.AccuracyWindow = *req.AccuracyWindow
+	}
+	if req.Timezone != nil {
+		defaults.Timezone = *req.Timezone
+	}
+	if req.PrivacyZonesEnabled != nil {
+		defaults.PrivacyZonesEnabled = *req.PrivacyZonesEnabled
+	}
+	if req.PublicBadge != nil {
+		defaults.PublicBadge = *req.PublicBadge
+	}
+	if req.Notifications != nil {
+		defaults.Notifications = *req.Notifications
+	}
+	if req.Downsampling != nil {
+		defaults.Downsampling = *req.Downsampling
+	}
+
+	encoded, err := json.Marshal(defaults)
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to encode tracking defaults")
+	}
+
+	// Session privacy keeps living in its own field, which session creation already reads
+	user.Set("default_session_public", defaults.DefaultSessionPublic)
+	user.Set("tracking_defaults", string(encoded))
+
+	if err := s.repo.Save(user); err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to save tracking defaults")
+	}
+
+	return &defaults, nil
+}
+
+// trackingDefaultsFromRecord reads the tracking_defaults JSON field of a user record
+func trackingDefaultsFromRecord(user *models.Record) appmodels.TrackingDefaults {
+	defaults := appmodels.TrackingDefaults{
+		AutoSessionGap: constants.DefaultAutoSessionGap,
+		PointInterval:  constants.DefaultPointInterval,
+		SpeedUnit:      constants.DefaultSpeedUnit,
+		LocationPolicy: constants.DefaultLocationPolicy,
+		AccuracyWindow: constants.DefaultAccuracyWindow,
+		Timezone:       constants.DefaultTimezone,
+	}
+
+	if raw := user.GetString("tracking_defaults"); raw != "" && raw != "null" {
+		if err := json.Unmarshal([]byte(raw), &defaults); err != nil {
+			utils.LogWarn().Err(err).Str("user_id", user.Id).Msg("Ignoring invalid tracking defaults")
+		}
+	}
+	defaults.DefaultSessionPublic = user.GetBool("default_session_public")
+
+	return defaults
+}
+
 // UserError represents a user-related error
 type UserError struct {
 	Message string
@@ -7,6 +7,7 @@ import (
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/stretchr/testify/assert"
 
+	"vibe-tracker/constants"
 	appmodels "vibe-tracker/models"
 	"vibe-tracker/services/mocks"
 )
@@ -264,6 +265,80 @@ func TestUserService_UpdateAvatar(t *testing.T) {
 	})
 }
 
+func TestUserService_GetTrackingDefaults(t *testing.T) {
+	t.Run("Application defaults for unset settings", func(t *testing.T) {
+		testUser := createTestUserRecord("user123", "testuser", "test@example.com")
+		service := NewUserService(&mocks.MockUserRepository{})
+
+		defaults := service.GetTrackingDefaults(testUser)
+
+		assert.False(t, defaults.DefaultSessionPublic)
+		assert.Equal(t, constants.DefaultAutoSessionGap, defaults.AutoSessionGap)
+		assert.Equal(t, constants.DefaultPointInterval, defaults.PointInterval)
+		assert.False(t, defaults.PrivacyZonesEnabled)
+	})
+
+	t.Run("Stored settings override defaults", func(t *testing.T) {
+		testUser := createTestUserRecord("user123", "testuser", "test@example.com")
+		testUser.Set("default_session_public", true)
+		testUser.Set("tracking_defaults", `{"auto_session_gap":30,"notifications":{"push":true}}`)
+		service := NewUserService(&mocks.MockUserRepository{})
+
+		defaults := service.GetTrackingDefaults(testUser)
+
+		assert.True(t, defaults.DefaultSessionPublic)
+		assert.Equal(t, 30, defaults.AutoSessionGap)
+		assert.Equal(t, constants.DefaultPointInterval, defaults.PointInterval)
+		assert.True(t, defaults.Notifications.Push)
+		assert.False(t, defaults.Notifications.Email)
+	})
+}
+
+func TestUserService_UpdateTrackingDefaults(t *testing.T) {
+	t.Run("Partial update keeps other settings", func(t *testing.T) {
+		mockRepo := &mocks.MockUserRepository{}
+		testUser := createTestUserRecord("user123", "testuser", "test@example.com")
+		testUser.Set("tracking_defaults", `{"auto_session_gap":30,"point_interval":10}`)
+
+		mockRepo.On("Save", testUser).Return(nil)
+
+		service := NewUserService(mockRepo)
+
+		gap := 60
+		public := true
+		defaults, err := service.UpdateTrackingDefaults(testUser, appmodels.UpdateTrackingDefaultsRequest{
+			AutoSessionGap:       &gap,
+			DefaultSessionPublic: &public,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 60, defaults.AutoSessionGap)
+		assert.Equal(t, 10, defaults.PointInterval)
+		assert.True(t, testUser.GetBool("default_session_public"))
+
+		// The stored JSON round-trips to the returned settings
+		assert.Equal(t, *defaults, service.GetTrackingDefaults(testUser))
+
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Save error", func(t *testing.T) {
+		mockRepo := &mocks.MockUserRepository{}
+		testUser := createTestUserRecord("user123", "testuser", "test@example.com")
+
+		mockRepo.On("Save", testUser).Return(errors.New("database error"))
+
+		service := NewUserService(mockRepo)
+
+		defaults, err := service.UpdateTrackingDefaults(testUser, appmodels.UpdateTrackingDefaultsRequest{})
+
+		assert.Error(t, err)
+		assert.Nil(t, defaults)
+
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestUserService_ConvertToUserModel(t *testing.T) {
 	t.Run("Basic conversion structure", func(t *testing.T) {
 		// Setup mocks
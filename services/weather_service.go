@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/models"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// WeatherService forecasts the weather along the planned route of a session at the times it
+// would be reached, using an Open-Meteo compatible forecast API
+type WeatherService struct {
+	app         *pocketbase.PocketBase
+	forecastURL string
+	client      *http.Client
+}
+
+// NewWeatherService creates a new WeatherService instance; an empty forecast URL disables it
+func NewWeatherService(app *pocketbase.PocketBase, forecastURL string) *WeatherService {
+	return &WeatherService{
+		app:         app,
+		forecastURL: forecastURL,
+		client:      &http.Client{Timeout: constants.WeatherTimeout},
+	}
+}
+
+// Enabled reports whether a forecast API is configured
+func (s *WeatherService) Enabled() bool {
+	return s.forecastURL != ""
+}
+
+// RouteWeather samples the planned route of a session and forecasts the weather at each sample
+// for the time it is reached when starting at start and moving at speed km/h. Samples reached in
+// the past or beyond the forecast horizon get no forecast.
+func (s *WeatherService) RouteWeather(session *models.Record, start time.Time, speed float64) (*appmodels.RouteWeatherResponse, error) {
+	records, err := s.app.Dao().FindRecordsByFilter("gpx_tracks", "session_id = {:session_id} && simplified = true", "sequence", 0, 0,
+		dbx.Params{"session_id": session.Id})
+	if err != nil {
+		return nil, utils.LogAndWrapError(err, utils.ErrorTypeInternal, "Failed to fetch planned route", session.GetString("user"))
+	}
+	if len(records) == 0 {
+		return nil, utils.NewNotFoundError("Planned route", session.GetString("name"))
+	}
+
+	points := make([]utils.TimedPoint, len(records))
+	for i, record := range records {
+		points[i] = utils.TimedPoint{Latitude: record.GetFloat("latitude"), Longitude: record.GetFloat("longitude")}
+	}
+
+	samples := utils.SampleRoute(points, constants.WeatherSampleSpacing, constants.WeatherMaxSamples)
+	now := time.Now()
+	var forecastable []int
+	for i := range samples {
+		samples[i].ArrivalAt = start.Add(time.Duration(samples[i].Distance / (speed * 1000) * float64(time.Hour))).UTC()
+		if samples[i].ArrivalAt.After(now.Add(-time.Hour)) && samples[i].ArrivalAt.Before(now.Add(constants.WeatherForecastHorizon)) {
+			forecastable = append(forecastable, i)
+		}
+	}
+
+	if len(forecastable) > 0 {
+		forecasts, err := s.fetch(samples, forecastable)
+		if err != nil {
+			return nil, utils.NewExternalError("weather", err)
+		}
+		for j, i := range forecastable {
+			samples[i].Forecast = forecasts[j].At(samples[i].ArrivalAt)
+		}
+	}
+
+	return &appmodels.RouteWeatherResponse{
+		SessionID: session.Id,
+		StartAt:   start.UTC(),
+		Speed:     speed,
+		Samples:   samples,
+	}, nil
+}
+
+// fetch requests the hourly forecasts of the selected samples in one request
+func (s *WeatherService) fetch(samples []appmodels.RouteWeatherSample, selected []int) ([]utils.HourlyForecast, error) {
+	latitudes := make([]string, len(selected))
+	longitudes := make([]string, len(selected))
+	for j, i := range selected {
+		latitudes[j] = strconv.FormatFloat(samples[i].Latitude, 'f', 4, 64)
+		longitudes[j] = strconv.FormatFloat(samples[i].Longitude, 'f', 4, 64)
+	}
+
+	query := url.Values{
+		"latitude":        {strings.Join(latitudes, ",")},
+		"longitude":       {strings.Join(longitudes, ",")},
+		"hourly":          {constants.WeatherHourlyVariables},
+		"timeformat":      {"unixtime"},
+		"timezone":        {"GMT"},
+		"wind_speed_unit": {"ms"},
+		"start_date":      {samples[selected[0]].ArrivalAt.Format("2006-01-02")},
+		"end_date":        {samples[selected[len(selected)-1]].ArrivalAt.Format("2006-01-02")},
+	}
+
+	separator := "?"
+	if strings.Contains(s.forecastURL, "?") {
+		separator = "&"
+	}
+	resp, err := s.client.Get(s.forecastURL + separator + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("forecast API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 8*1024*1024))
+	if err != nil {
+		return nil, err
+	}
+
+	forecasts, err := utils.ParseOpenMeteoForecasts(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(forecasts) != len(selected) {
+		return nil, fmt.Errorf("forecast API returned %d places, expected %d", len(forecasts), len(selected))
+	}
+	return forecasts, nil
+}
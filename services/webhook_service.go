@@ -0,0 +1,86 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	"vibe-tracker/utils"
+)
+
+// WebhookService delivers tracking events as JSON POST requests to the configured endpoints
+type WebhookService struct {
+	urls   []string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookService creates a new WebhookService instance. Bodies are signed when a secret is set.
+func NewWebhookService(urls []string, secret string) *WebhookService {
+	endpoints := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if url = strings.TrimSpace(url); url != "" {
+			endpoints = append(endpoints, url)
+		}
+	}
+
+	return &WebhookService{
+		urls:   endpoints,
+		secret: secret,
+		client: &http.Client{Timeout: constants.WebhookTimeout},
+	}
+}
+
+// Enabled reports whether any webhook endpoint is configured
+func (s *WebhookService) Enabled() bool {
+	return len(s.urls) > 0
+}
+
+// Send delivers an event to every endpoint in the background. Delivery failures are logged only.
+func (s *WebhookService) Send(event string, data any) {
+	if !s.Enabled() {
+		return
+	}
+
+	body, err := json.Marshal(appmodels.WebhookEvent{Event: event, Timestamp: time.Now().UTC(), Data: data})
+	if err != nil {
+		utils.LogError(err, "webhook encoding").Str("event", event).Msg("Failed to encode webhook event")
+		return
+	}
+
+	for _, url := range s.urls {
+		go func(url string) {
+			if err := s.deliver(url, event, body); err != nil {
+				utils.LogWarn().Err(err).Str("event", event).Str("url", url).Msg("Webhook delivery failed")
+			}
+		}(url)
+	}
+}
+
+func (s *WebhookService) deliver(url, event string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(constants.WebhookEventHeader, event)
+	if s.secret != "" {
+		req.Header.Set(constants.WebhookSignatureHeader, utils.SignWebhookPayload(s.secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
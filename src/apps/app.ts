@@ -505,6 +505,7 @@ function fetchData(isInitialLoad: boolean = false, useDelta: boolean = false): v
                 ? data.features[0].properties?.session_title
                 : undefined),
             description: data.session?.description,
+            description_html: data.session?.description_html,
             public: data.session?.public ?? true,
             created: data.session?.created,
             updated: data.session?.updated,
@@ -26,7 +26,7 @@ export function createAvatarMarker(
     // Use avatar image
     avatarContent = `
       <img 
-        src="/api/files/users/${userId}/${avatar}" 
+        src="/api/files/users/${userId}/${avatar}?thumb=64x64" 
         alt="${username}" 
         style="
           width: ${avatarSize}px; 
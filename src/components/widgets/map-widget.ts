@@ -6,10 +6,14 @@ import type {
   GeoJSONFeature,
   LocationProperties,
   GpxTrackPointsResponse,
+  GpxTrackPointFeature,
   WaypointsResponse,
   WaypointFeature,
   WaypointType,
   PositionConfidence,
+  BrandingResponse,
+  SuccessResponse,
+  Coordinates,
 } from '@/types';
 import { createMarker } from '@/components/ui';
 import styles from '@/styles/components/widgets/map-widget.css?inline';
@@ -29,6 +33,7 @@ export default class MapWidget extends HTMLElement implements MapWidgetElement {
   // New layer groups for GPX tracks and waypoints
   private gpxTrackLayerGroup: L.LayerGroup | null = null;
   private waypointsLayerGroup: L.LayerGroup | null = null;
+  private tileLayer: L.TileLayer | null = null;
   private currentFeatureCollection: LocationsResponse | null = null;
   // Waypoint selection properties
   private waypointSelectionMode: boolean = false;
@@ -74,7 +79,7 @@ export default class MapWidget extends HTMLElement implements MapWidgetElement {
       this.map.getPane('gpxPane')!.style.zIndex = '400'; // Lower z-index for GPX tracks
       this.map.getPane('overlayPane')!.style.zIndex = '600'; // Higher z-index for main tracks
 
-      L.tileLayer('https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png', {
+      this.tileLayer = L.tileLayer('https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png', {
         attribution:
           '&copy; <a href="https://www.openstreetmap.org/copyright">OpenStreetMap</a> contributors',
       }).addTo(this.map);
@@ -92,6 +97,7 @@ export default class MapWidget extends HTMLElement implements MapWidgetElement {
       this.map.on('zoomend', () => this.updateUrlHash());
 
       this.setViewFromUrlHash();
+      this.applyBranding();
     } catch (error) {
       console.error('Failed to initialize map widget:', error);
       this.shadowRoot!.getElementById('map')!.innerHTML =
@@ -99,6 +105,36 @@ export default class MapWidget extends HTMLElement implements MapWidgetElement {
     }
   }
 
+  /**
+   * Switch to the instance's tile layer and, unless a view is already set, its default map view
+   */
+  async applyBranding(): Promise<void> {
+    try {
+      const response = await fetch('/api/config/branding');
+      if (!response.ok || !this.map) {
+        return;
+      }
+      const result: SuccessResponse<BrandingResponse> = await response.json();
+      const mapDefaults = result.data?.map;
+      if (!mapDefaults) {
+        return;
+      }
+
+      this.tileLayer?.remove();
+      this.tileLayer = L.tileLayer(mapDefaults.tiles.url, {
+        attribution: mapDefaults.tiles.attribution,
+        maxZoom: mapDefaults.tiles.max_zoom,
+      }).addTo(this.map);
+
+      // Leaflet marks the map loaded once a view is set, e.g. by data loaded in the meantime
+      if (!(this.map as any)._loaded) {
+        this.map.setView(mapDefaults.center, mapDefaults.zoom);
+      }
+    } catch (error) {
+      console.warn('Failed to load instance branding, keeping default map settings:', error);
+    }
+  }
+
   setViewFromUrlHash(): boolean {
     const hash = window.location.hash;
     if (hash.startsWith('#map=')) {
@@ -212,11 +248,25 @@ export default class MapWidget extends HTMLElement implements MapWidgetElement {
       (a, b) => a.properties.sequence - b.properties.sequence
     );
 
+    // One line per track segment, so separate tracks and routes are not joined
+    const trackCoordinates: [number, number][][] = [];
+    let previous: GpxTrackPointFeature | undefined;
+    for (const point of sortedPoints) {
+      if (
+        !previous ||
+        (previous.properties.track ?? 0) !== (point.properties.track ?? 0) ||
+        (previous.properties.segment ?? 0) !== (point.properties.segment ?? 0)
+      ) {
+        trackCoordinates.push([]);
+      }
+      trackCoordinates[trackCoordinates.length - 1].push([
+        point.geometry.coordinates[1], // latitude
+        point.geometry.coordinates[0], // longitude
+      ]);
+      previous = point;
+    }
+
     // Create the planned track polyline (dashed blue)
-    const trackCoordinates: [number, number][] = sortedPoints.map(point => [
-      point.geometry.coordinates[1], // latitude
-      point.geometry.coordinates[0], // longitude
-    ]);
 
     const plannedTrack = L.polyline(trackCoordinates, {
       color: 'hsl(320, 100%, 60%)', // Bright pink color for planned track
@@ -361,22 +411,23 @@ export default class MapWidget extends HTMLElement implements MapWidgetElement {
    * Creates popup content for waypoints
    */
   createWaypointPopupContent(waypoint: WaypointFeature): string {
-    const { name, type, description, altitude, source, position_confidence, photo, id } =
+    const { name, type, description_html, altitude, source, position_confidence, photo_url } =
       waypoint.properties;
     const coords = waypoint.geometry.coordinates;
 
-    const altitudeText = altitude ? `<b>Altitude:</b> ${altitude} m<br>` : '';
-    const descriptionText = description ? `<b>Description:</b> ${description}<br>` : '';
+    const altitudeText = altitude != null ? `<b>Altitude:</b> ${altitude} m<br>` : '';
+    // Only the sanitized rendering is inserted, the raw description may contain markup
+    const descriptionText = description_html
+      ? `<b>Description:</b> <div class="waypoint-description">${description_html}</div>`
+      : '';
 
     // Add photo display if available
     let photoHtml = '';
-    if (photo) {
-      const photoUrl = `/api/files/waypoints/${id}/${photo}`;
-      const thumbnailUrl = `${photoUrl}?thumb=150x150`;
+    if (photo_url) {
       photoHtml = `
         <div class="waypoint-photo">
-          <a href="${photoUrl}" target="_blank" rel="noopener noreferrer">
-            <img src="${thumbnailUrl}" alt="${name}" />
+          <a href="${photo_url}" target="_blank" rel="noopener noreferrer">
+            <img src="${photo_url}" alt="${name}" />
           </a>
         </div>
       `;
@@ -470,11 +521,7 @@ export default class MapWidget extends HTMLElement implements MapWidgetElement {
         const [longitude, latitude, _altitude] = point.geometry.coordinates;
 
         const marker = createMarker([latitude, longitude], point.properties);
-        const coords: [number, number, number] =
-          point.geometry.coordinates.length === 2
-            ? ([...point.geometry.coordinates, 0] as [number, number, number])
-            : (point.geometry.coordinates as [number, number, number]);
-        const popupContent = this.createPopupContent(point.properties, coords);
+        const popupContent = this.createPopupContent(point.properties, point.geometry.coordinates);
         marker.bindPopup(popupContent);
         this.dataLayerGroup!.addLayer(marker);
       });
@@ -586,11 +633,10 @@ export default class MapWidget extends HTMLElement implements MapWidgetElement {
       const latestPoint = points[points.length - 1];
       const [longitude, latitude, _altitude] = latestPoint.geometry.coordinates;
       const marker = createMarker([latitude, longitude], latestPoint.properties);
-      const coords: [number, number, number] =
-        latestPoint.geometry.coordinates.length === 2
-          ? ([...latestPoint.geometry.coordinates, 0] as [number, number, number])
-          : (latestPoint.geometry.coordinates as [number, number, number]);
-      const popupContent = this.createPopupContent(latestPoint.properties, coords);
+      const popupContent = this.createPopupContent(
+        latestPoint.properties,
+        latestPoint.geometry.coordinates
+      );
       marker.bindPopup(popupContent);
       this.dataLayerGroup!.addLayer(marker);
     }
@@ -620,11 +666,7 @@ export default class MapWidget extends HTMLElement implements MapWidgetElement {
     if (!this.setViewFromUrlHash()) {
       this.map!.setView([latitude, longitude], 15);
     }
-    const coords: [number, number, number] =
-      data.geometry.coordinates.length === 2
-        ? ([...data.geometry.coordinates, 0] as [number, number, number])
-        : (data.geometry.coordinates as [number, number, number]);
-    const popupContent = this.createPopupContent(data.properties, coords);
+    const popupContent = this.createPopupContent(data.properties, data.geometry.coordinates);
     const marker = createMarker([latitude, longitude], data.properties)
       .bindPopup(popupContent)
       .openPopup();
@@ -767,13 +809,22 @@ export default class MapWidget extends HTMLElement implements MapWidgetElement {
     return statusConfig[status || ''] || {}; // Default/active - no special styling
   }
 
-  createPopupContent(
-    properties: LocationProperties,
-    coordinates: [number, number, number]
-  ): string {
-    const { speed, heart_rate, timestamp, session, session_title, username, status, event } =
-      properties;
+  createPopupContent(properties: LocationProperties, coordinates: Coordinates): string {
+    const {
+      speed,
+      heart_rate,
+      cadence,
+      power,
+      temperature,
+      timestamp,
+      session,
+      session_title,
+      username,
+      status,
+      event,
+    } = properties;
     const altitude = coordinates[2];
+    const altitudeLine = altitude !== undefined ? `<b>Altitude:</b> ${altitude} m<br>` : '';
 
     const sessionDisplay =
       session_title && session_title !== session
@@ -796,12 +847,19 @@ export default class MapWidget extends HTMLElement implements MapWidgetElement {
       ? `<b>Event:</b> ${event.charAt(0).toUpperCase() + event.slice(1)}<br>`
       : '';
 
+    // Sensor readings of cycling computers and sports watches, only when recorded
+    const sensorLines = [
+      cadence !== undefined ? `<br><b>Cadence:</b> ${cadence} rpm` : '',
+      power !== undefined ? `<br><b>Power:</b> ${power} W` : '',
+      temperature !== undefined ? `<br><b>Temperature:</b> ${temperature} °C` : '',
+    ].join('');
+
     return `
       ${userLine}<b>Time:</b> ${new Date(timestamp * 1000).toLocaleString()}<br>
       <b>Session:</b> ${sessionLink}<br>
-      ${statusLine}${eventLine}<b>Altitude:</b> ${altitude} m<br>
+      ${statusLine}${eventLine}${altitudeLine}
       <b>Speed:</b> ${speed} km/h<br>
-      <b>Heart Rate:</b> ${heart_rate} bpm
+      <b>Heart Rate:</b> ${heart_rate} bpm${sensorLines}
     `;
   }
 
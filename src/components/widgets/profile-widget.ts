@@ -89,7 +89,7 @@ export default class ProfileWidget extends HTMLElement implements ProfileWidgetE
             <div class="avatar-controls">
               <div class="form-group">
                 <label for="avatar-file">Upload new avatar</label>
-                <input type="file" id="avatar-file" accept="image/*">
+                <input type="file" id="avatar-file" accept="image/jpeg,image/png,image/gif,image/webp">
               </div>
               <button id="upload-avatar" type="button">Upload Avatar</button>
             </div>
@@ -282,7 +282,7 @@ export default class ProfileWidget extends HTMLElement implements ProfileWidgetE
 
     if (this.user!.avatar) {
       const img = document.createElement('img');
-      img.src = `/api/files/users/${this.user!.id}/${this.user!.avatar}`;
+      img.src = `/api/files/users/${this.user!.id}/${this.user!.avatar}?thumb=128x128`;
       img.alt = 'User avatar';
       img.onerror = () => {
         this.avatarPlaceholder.textContent = this.user!.username
@@ -418,7 +418,15 @@ export default class SessionManagementWidget
 
     try {
       if (this.editingSession) {
-        await this.updateSession(name, { title, description, public: isPublic });
+        let updatedTitle = title;
+        if (name !== this.editingSession.name) {
+          await this.renameSession(this.editingSession.name, name);
+          // Keep a title generated from the name in sync with it
+          if (title === this.generateTitle(this.editingSession.name)) {
+            updatedTitle = this.generateTitle(name);
+          }
+        }
+        await this.updateSession(name, { title: updatedTitle, description, public: isPublic });
       } else {
         await this.createSession({ name, title, description, public: isPublic });
       }
@@ -478,6 +486,26 @@ export default class SessionManagementWidget
     return result.data || result;
   }
 
+  async renameSession(sessionName: string, newName: string): Promise<any> {
+    const response = await fetch(`/api/sessions/${this.user!.username}/${sessionName}/rename`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        Authorization: `Bearer ${localStorage.getItem('auth_token')}`,
+      },
+      body: JSON.stringify({ name: newName }),
+    });
+
+    if (!response.ok) {
+      const error = await response.text();
+      throw new Error(error || 'Failed to rename session');
+    }
+
+    const result = await response.json();
+    // Handle standardized response format
+    return result.data || result;
+  }
+
   async deleteSession(sessionName: string): Promise<void> {
     if (
       !confirm(
@@ -521,7 +549,6 @@ export default class SessionManagementWidget
 
     // Populate form with session data
     this.sessionNameInput.value = session.name;
-    this.sessionNameInput.disabled = true; // Don't allow changing the name
     this.sessionTitleInput.value = session.title || '';
     this.sessionDescriptionInput.value = session.description || '';
     this.sessionPublicInput.checked = session.public || false;
@@ -541,7 +568,6 @@ export default class SessionManagementWidget
     this.cancelBtn.classList.add('hidden');
     this.cancelBtn.classList.remove('show-inline-block');
 
-    this.sessionNameInput.disabled = false;
     this.sessionForm.reset();
     this.formMessage.textContent = '';
     this.formMessage.className = '';
@@ -25,6 +25,7 @@ interface SessionData {
   sessionId: string;
   title?: string;
   description?: string;
+  description_html?: string;
   public?: boolean;
   created?: string;
   updated?: string;
@@ -707,7 +708,7 @@ export default class SessionMapPanelWidget
     const {
       sessionName,
       title,
-      description,
+      description_html,
       public: isPublic,
       track_name,
     } = this.currentSessionData;
@@ -736,11 +737,11 @@ export default class SessionMapPanelWidget
             : ''
         }
         ${
-          description
+          description_html
             ? `
           <div class="info-row">
             <span class="label">Description:</span>
-            <span class="value">${description}</span>
+            <div class="value description">${description_html}</div>
           </div>
         `
             : ''
@@ -967,7 +968,7 @@ export default class SessionMapPanelWidget
         existingImg.remove();
       }
 
-      const avatarUrl = `/api/files/users/${this.currentUser.id}/${this.currentUser.avatar}`;
+      const avatarUrl = `/api/files/users/${this.currentUser.id}/${this.currentUser.avatar}?thumb=64x64`;
       const img = document.createElement('img');
       img.className = 'avatar-image';
       img.src = avatarUrl;
@@ -1390,7 +1391,9 @@ export default class SessionMapPanelWidget
         'Time',
         new Date(timestamp * 1000).toLocaleString()
       );
-      this.showLocationProperty(locationContent, 'Altitude', `${altitude.toFixed(2)} m`);
+      if (altitude !== undefined) {
+        this.showLocationProperty(locationContent, 'Altitude', `${altitude.toFixed(2)} m`);
+      }
       this.showLocationProperty(locationContent, 'Speed', `${speed.toFixed(2)} km/h`);
       this.showLocationProperty(locationContent, 'Heart Rate', `${heart_rate} bpm`);
     } else {
@@ -571,7 +571,7 @@ export default class WaypointManagerWidget
    * Displays waypoint photo in the edit form
    */
   private displayWaypointPhoto(waypoint: WaypointFeature): void {
-    const photo = waypoint.properties.photo;
+    const photoUrl = waypoint.properties.photo_url;
     const form = this.shadowRoot!.querySelector('#waypoint-form') as HTMLFormElement;
 
     // Remove existing photo display if any
@@ -581,10 +581,7 @@ export default class WaypointManagerWidget
     }
 
     // Add photo display if waypoint has a photo
-    if (photo) {
-      const photoUrl = `/api/files/waypoints/${waypoint.properties.id}/${photo}`;
-      const thumbnailUrl = `${photoUrl}?thumb=200x200`;
-
+    if (photoUrl) {
       const photoDisplay = document.createElement('div');
       photoDisplay.className = 'waypoint-photo-display';
       photoDisplay.innerHTML = `
@@ -592,7 +589,7 @@ export default class WaypointManagerWidget
           <label>Photo</label>
           <div class="photo-preview">
             <a href="${photoUrl}" target="_blank" rel="noopener noreferrer">
-              <img src="${thumbnailUrl}" alt="${waypoint.properties.name}" />
+              <img src="${photoUrl}" alt="${waypoint.properties.name}" />
             </a>
           </div>
         </div>
@@ -39,3 +39,40 @@ export const HTTP_STATUS = {
 } as const;
 
 export type HttpStatusCode = (typeof HTTP_STATUS)[keyof typeof HTTP_STATUS];
+
+// Instance branding from GET /api/config/branding
+export interface TileProvider {
+  url: string;
+  attribution: string;
+  max_zoom: number;
+}
+
+export interface MapDefaults {
+  center: [number, number]; // [latitude, longitude]
+  zoom: number;
+  tiles: TileProvider;
+}
+
+export interface InstanceFeatures {
+  read_only: boolean;
+  graphql: boolean;
+  route_planning: boolean;
+  elevation: boolean;
+  weather: boolean;
+  map_matching: boolean;
+  country_stats: boolean;
+  waypoint_check_off: boolean;
+  low_battery_alerts: boolean;
+  grpc_ingest: boolean;
+  hardware_trackers: boolean;
+  aprs: boolean;
+  mapshare: boolean;
+  home_assistant: boolean;
+}
+
+export interface BrandingResponse {
+  name: string;
+  logo_url: string;
+  map: MapDefaults;
+  features: InstanceFeatures;
+}
@@ -16,9 +16,13 @@ export interface LocationProperties {
   timestamp: number;
   speed?: number | null;
   heart_rate?: number | null;
+  cadence?: number; // rpm or steps per minute, left out when not recorded
+  power?: number; // W
+  temperature?: number; // Ambient, °C
   session?: string;
   username?: string;
   session_title?: string;
+  activity?: string; // Session activity, e.g. running
   user_id?: string;
   avatar?: string;
   status?: string;
@@ -111,7 +115,9 @@ export interface Session {
   name: string;
   title: string;
   description: string;
+  description_html?: string; // Description rendered from markdown, safe to insert as HTML
   public: boolean;
+  activity?: string;
   share_token?: string; // Only included for session owner
   user?: string;
   created: string;
@@ -146,6 +152,8 @@ export interface GpxTrackPointFeature {
 
 export interface GpxTrackPointProperties {
   sequence: number;
+  track?: number; // Index of the track or route of the GPX file
+  segment?: number; // Index of the segment within its track
   altitude?: number;
 }
 
@@ -155,10 +163,12 @@ export interface Waypoint {
   name: string;
   type: WaypointType;
   description?: string;
+  description_html?: string; // Description rendered from markdown, safe to insert as HTML
   latitude: number;
   longitude: number;
   altitude?: number;
   photo?: string;
+  photo_url?: string; // Signed download URL, expires after a few minutes
   session_id: string;
   source: WaypointSource;
   position_confidence: PositionConfidence;
@@ -205,8 +215,10 @@ export interface WaypointProperties {
   name: string;
   type: WaypointType;
   description?: string;
+  description_html?: string; // Description rendered from markdown, safe to insert as HTML
   altitude?: number;
   photo?: string;
+  photo_url?: string; // Signed download URL, expires after a few minutes
   source: WaypointSource;
   position_confidence: PositionConfidence;
 }
@@ -1,12 +1,17 @@
 package tests
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
 	"github.com/stretchr/testify/assert"
 
 	"vibe-tracker/config"
@@ -91,6 +96,99 @@ func TestRateLimitingConfiguration(t *testing.T) {
 	})
 }
 
+// TestRateLimitHeaders tests that rate limited responses tell clients when to retry
+func TestRateLimitHeaders(t *testing.T) {
+	rl := middleware.NewRateLimitMiddleware()
+	defer rl.Cleanup()
+
+	e := echo.New()
+	handler := rl.AuthEndpoints()(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	request := func() (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/api/login", nil)
+		req.RemoteAddr = "192.0.2.10:12345"
+		rec := httptest.NewRecorder()
+		return rec, handler(e.NewContext(req, rec))
+	}
+
+	// The auth limiter allows a burst of two requests
+	rec, err := request()
+	assert.NoError(t, err)
+	assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "12", rec.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "5;w=60;burst=2", rec.Header().Get("RateLimit-Policy"))
+	assert.Empty(t, rec.Header().Get("Retry-After"))
+
+	rec, err = request()
+	assert.NoError(t, err)
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+
+	rec, err = request()
+	if assert.Error(t, err) {
+		apiErr, ok := err.(*apis.ApiError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusTooManyRequests, apiErr.Code)
+	}
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "12", rec.Header().Get("Retry-After"))
+}
+
+// TestDecompressRequest tests that gzip bodies are inflated within the request size limit
+func TestDecompressRequest(t *testing.T) {
+	sm := middleware.NewSecurityMiddleware(64, time.Second, false)
+
+	e := echo.New()
+	var received string
+	handler := sm.DecompressRequest()(func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		received = string(body)
+		assert.Empty(t, c.Request().Header.Get("Content-Encoding"))
+		return err
+	})
+
+	gzipped := func(body string) *bytes.Buffer {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		_, _ = writer.Write([]byte(body))
+		_ = writer.Close()
+		return &buf
+	}
+	request := func(encoding string, body io.Reader) error {
+		req := httptest.NewRequest(http.MethodPost, "/api/track", body)
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		return handler(e.NewContext(req, httptest.NewRecorder()))
+	}
+	apiErrorCode := func(err error) int {
+		apiErr, ok := err.(*apis.ApiError)
+		if !assert.True(t, ok) {
+			return 0
+		}
+		return apiErr.Code
+	}
+
+	point := `{"type":"Feature"}`
+	assert.NoError(t, request("gzip", gzipped(point)))
+	assert.Equal(t, point, received)
+
+	// Uncompressed bodies are passed on as they are
+	assert.NoError(t, request("", strings.NewReader(point)))
+	assert.Equal(t, point, received)
+
+	// The limit applies to the inflated body, whatever its compressed size
+	exact := strings.Repeat("a", 64)
+	assert.NoError(t, request("gzip", gzipped(exact)))
+	assert.Equal(t, exact, received)
+	assert.Equal(t, http.StatusBadRequest, apiErrorCode(request("gzip", gzipped(exact+"a"))))
+
+	assert.Equal(t, http.StatusBadRequest, apiErrorCode(request("gzip", strings.NewReader(point))))
+	assert.Equal(t, http.StatusUnsupportedMediaType, apiErrorCode(request("br", strings.NewReader(point))))
+}
+
 // TestSecurityConfiguration tests security configuration
 func TestSecurityConfiguration(t *testing.T) {
 	t.Run("Security middleware configuration", func(t *testing.T) {
@@ -0,0 +1,108 @@
+// PocketBase v0.22 cannot decode its collection schemas with the JSON v2 experiment
+//go:build !goexperiment.jsonv2
+
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/models"
+	pbtests "github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "vibe-tracker/migrations"
+)
+
+// TestWaypointCollectionRules checks that the collection API keeps users to their own waypoints
+// and sessions
+func TestWaypointCollectionRules(t *testing.T) {
+	// An empty data directory gets the schema of the migrations only
+	app, err := pbtests.NewTestApp(t.TempDir())
+	require.NoError(t, err)
+	defer app.Cleanup()
+
+	dao := app.Dao()
+	newRecord := func(collectionName string, data map[string]any) *models.Record {
+		collection, err := dao.FindCollectionByNameOrId(collectionName)
+		require.NoError(t, err)
+		record := models.NewRecord(collection)
+		record.Load(data)
+		if collection.IsAuth() {
+			require.NoError(t, record.SetPassword("pass12345678"))
+		}
+		require.NoError(t, dao.SaveRecord(record))
+		return record
+	}
+
+	alice := newRecord("users", map[string]any{"username": "alice", "email": "alice@example.com"})
+	bob := newRecord("users", map[string]any{"username": "bob", "email": "bob@example.com"})
+	aliceSession := newRecord("sessions", map[string]any{"name": "alice-trip", "user": alice.Id})
+	bobSession := newRecord("sessions", map[string]any{"name": "bob-trip", "user": bob.Id})
+	waypoint := newRecord("waypoints", map[string]any{
+		"name": "Summit", "type": "generic", "latitude": 47.5, "longitude": 19.0,
+		"source": "manual", "position_confidence": "manual", "user": alice.Id, "session_id": aliceSession.Id,
+	})
+
+	token, err := tokens.NewRecordAuthToken(app, alice)
+	require.NoError(t, err)
+
+	e, err := apis.InitApi(app)
+	require.NoError(t, err)
+	request := func(method, url, body string) int {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	const waypoints = "/api/collections/waypoints/records"
+	point := `"name": "Hut", "type": "generic", "latitude": 47.6, "longitude": 19.1, "source": "manual", "position_confidence": "manual"`
+
+	t.Run("Create in own session", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(http.MethodPost, waypoints,
+			`{`+point+`, "user": "`+alice.Id+`", "session_id": "`+aliceSession.Id+`"}`))
+	})
+
+	t.Run("Create without session", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(http.MethodPost, waypoints,
+			`{`+point+`, "user": "`+alice.Id+`"}`))
+	})
+
+	t.Run("Create in another user's session", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, waypoints,
+			`{`+point+`, "user": "`+alice.Id+`", "session_id": "`+bobSession.Id+`"}`))
+	})
+
+	t.Run("Create for another user", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, waypoints,
+			`{`+point+`, "user": "`+bob.Id+`", "session_id": "`+bobSession.Id+`"}`))
+	})
+
+	t.Run("Rename own waypoint", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(http.MethodPatch, waypoints+"/"+waypoint.Id,
+			`{"name": "Peak"}`))
+	})
+
+	t.Run("Move into another user's session", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, request(http.MethodPatch, waypoints+"/"+waypoint.Id,
+			`{"session_id": "`+bobSession.Id+`"}`))
+	})
+
+	t.Run("Give to another user", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, request(http.MethodPatch, waypoints+"/"+waypoint.Id,
+			`{"user": "`+bob.Id+`"}`))
+	})
+
+	t.Run("Unlink from session", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(http.MethodPatch, waypoints+"/"+waypoint.Id,
+			`{"session_id": ""}`))
+	})
+}
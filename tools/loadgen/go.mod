@@ -0,0 +1,3 @@
+module github.com/dyuri/vibe-tracker/tools/loadgen
+
+go 1.23
@@ -0,0 +1,386 @@
+// Command loadgen simulates trackers posting points and viewers following public sessions
+// against a running Vibe Tracker instance, and reports the latency percentiles per request type.
+//
+// Rate limiting should be disabled on the target (ENABLE_RATE_LIMITING=false), otherwise most
+// requests of a larger run are answered with 429.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type config struct {
+	apiRoot  string
+	tokens   []string
+	duration time.Duration
+
+	trackers      int
+	rate          float64
+	sessionPrefix string
+	lat, lon      float64
+
+	viewers      int
+	viewerMode   string
+	pollInterval time.Duration
+	viewUser     string
+	viewSession  string
+}
+
+func main() {
+	cfg := config{}
+	tokens := flag.String("tokens", "", "Comma-separated tracking tokens, trackers are spread over them")
+	flag.StringVar(&cfg.apiRoot, "api-root", "http://localhost:8090", "API root URL")
+	flag.DurationVar(&cfg.duration, "duration", time.Minute, "Duration of the run")
+	flag.IntVar(&cfg.trackers, "trackers", 10, "Number of concurrent trackers")
+	flag.Float64Var(&cfg.rate, "rate", 1, "Points per second posted by each tracker")
+	flag.StringVar(&cfg.sessionPrefix, "session-prefix", "loadgen", "Trackers post to sessions named <prefix>-<n>")
+	flag.Float64Var(&cfg.lat, "lat", 47.4979, "Latitude trackers start around")
+	flag.Float64Var(&cfg.lon, "lon", 19.0402, "Longitude trackers start around")
+	flag.IntVar(&cfg.viewers, "viewers", 0, "Number of concurrent viewers")
+	flag.StringVar(&cfg.viewerMode, "viewer-mode", "poll", "How viewers follow a session: poll or stream")
+	flag.DurationVar(&cfg.pollInterval, "poll-interval", 5*time.Second, "Time between two polls of a viewer")
+	flag.StringVar(&cfg.viewUser, "view-user", "", "Owner of the session viewers follow; without it viewers poll the public locations")
+	flag.StringVar(&cfg.viewSession, "view-session", "", "Public session viewers follow")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: loadgen [flags]")
+		fmt.Fprintln(flag.CommandLine.Output(), "Simulates trackers and viewers against a Vibe Tracker instance and reports latency percentiles.")
+		fmt.Fprintln(flag.CommandLine.Output(), "\nFlags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	for _, token := range strings.Split(*tokens, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			cfg.tokens = append(cfg.tokens, token)
+		}
+	}
+	cfg.apiRoot = strings.TrimSuffix(cfg.apiRoot, "/")
+
+	if cfg.trackers > 0 && len(cfg.tokens) == 0 {
+		log.Fatal("Trackers need at least one token")
+	}
+	if cfg.trackers > 0 && cfg.rate <= 0 {
+		log.Fatal("Rate must be positive")
+	}
+	if cfg.viewerMode != "poll" && cfg.viewerMode != "stream" {
+		log.Fatal("Viewer mode must be poll or stream")
+	}
+	if cfg.viewers > 0 && cfg.viewerMode == "stream" && (cfg.viewUser == "" || cfg.viewSession == "") {
+		log.Fatal("Streaming viewers need -view-user and -view-session")
+	}
+	if cfg.viewers > 0 && (cfg.viewUser == "") != (cfg.viewSession == "") {
+		log.Fatal("-view-user and -view-session must be given together")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, cfg.duration)
+	defer cancel()
+
+	run(ctx, cfg)
+}
+
+func run(ctx context.Context, cfg config) {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			MaxIdleConns:        cfg.trackers + cfg.viewers,
+			MaxIdleConnsPerHost: cfg.trackers + cfg.viewers,
+		},
+	}
+	// Streams stay open for the whole run
+	streamClient := &http.Client{Transport: client.Transport}
+
+	track := newStats("track")
+	poll := newStats("poll")
+	stream := newStats("stream")
+	var events eventCounter
+
+	log.Printf("Starting %d trackers at %.2f points/s and %d %s viewers for %s",
+		cfg.trackers, cfg.rate, cfg.viewers, cfg.viewerMode, cfg.duration)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.trackers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			runTracker(ctx, client, cfg, i, track)
+		}(i)
+	}
+	for i := 0; i < cfg.viewers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if cfg.viewerMode == "stream" {
+				runStreamViewer(ctx, streamClient, cfg, stream, &events)
+			} else {
+				runPollViewer(ctx, client, cfg, poll)
+			}
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	fmt.Printf("\nRan for %s\n\n", elapsed.Round(time.Millisecond))
+	fmt.Printf("%-8s %9s %7s %9s %9s %9s %9s %9s %9s\n", "type", "requests", "errors", "req/s", "p50", "p90", "p95", "p99", "max")
+	for _, s := range []*stats{track, poll, stream} {
+		s.print(elapsed)
+	}
+	if cfg.viewerMode == "stream" && cfg.viewers > 0 {
+		fmt.Printf("\nStream events received: %d\n", events.load())
+	}
+	for _, s := range []*stats{track, poll, stream} {
+		s.printStatuses()
+	}
+}
+
+// runTracker posts a point of a random walk every 1/rate seconds
+func runTracker(ctx context.Context, client *http.Client, cfg config, n int, s *stats) {
+	token := cfg.tokens[n%len(cfg.tokens)]
+	session := fmt.Sprintf("%s-%d", cfg.sessionPrefix, n)
+	interval := time.Duration(float64(time.Second) / cfg.rate)
+
+	// Spread trackers over the interval and the area, so they neither post nor move in lockstep
+	lat := cfg.lat + (rand.Float64()-0.5)*0.05
+	lon := cfg.lon + (rand.Float64()-0.5)*0.05
+	heading := rand.Float64() * 2 * math.Pi
+	if !sleep(ctx, time.Duration(rand.Int63n(int64(interval)+1))) {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Walk at about 5 m/s, turning a little between points
+		heading += (rand.Float64() - 0.5) * 0.5
+		meters := 5 * interval.Seconds()
+		lat += meters * math.Cos(heading) / 111320
+		lon += meters * math.Sin(heading) / (111320 * math.Cos(lat*math.Pi/180))
+
+		body, _ := json.Marshal(map[string]any{
+			"type": "Feature",
+			"geometry": map[string]any{
+				"type":        "Point",
+				"coordinates": []float64{lon, lat, 100 + rand.Float64()*10},
+			},
+			"properties": map[string]any{
+				"timestamp": time.Now().Unix(),
+				"speed":     5,
+				"accuracy":  5 + rand.Float64()*10,
+				"session":   session,
+			},
+		})
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.apiRoot+"/api/track", bytes.NewReader(body))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", token)
+			s.do(ctx, client, req)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runPollViewer fetches a public session, or the public locations, every poll interval
+func runPollViewer(ctx context.Context, client *http.Client, cfg config, s *stats) {
+	target := cfg.apiRoot + "/api/public-locations"
+	if cfg.viewUser != "" {
+		target = fmt.Sprintf("%s/api/session/%s/%s", cfg.apiRoot, url.PathEscape(cfg.viewUser), url.PathEscape(cfg.viewSession))
+	}
+
+	if !sleep(ctx, time.Duration(rand.Int63n(int64(cfg.pollInterval)+1))) {
+		return
+	}
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err == nil {
+			s.do(ctx, client, req)
+		}
+
+		if !sleep(ctx, cfg.pollInterval) {
+			return
+		}
+	}
+}
+
+// runStreamViewer follows the live stream of a session and reconnects when it ends. The latency
+// of a stream is the time until the response headers arrive.
+func runStreamViewer(ctx context.Context, client *http.Client, cfg config, s *stats, events *eventCounter) {
+	target := fmt.Sprintf("%s/api/session/%s/%s/stream", cfg.apiRoot, url.PathEscape(cfg.viewUser), url.PathEscape(cfg.viewSession))
+
+	for ctx.Err() == nil {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return
+		}
+		req.Header.Set("Accept", "text/event-stream")
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.record(time.Since(start), 0)
+				sleep(ctx, time.Second)
+			}
+			continue
+		}
+		s.record(time.Since(start), resp.StatusCode)
+
+		if resp.StatusCode == http.StatusOK {
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				if strings.HasPrefix(scanner.Text(), "data:") {
+					events.add()
+				}
+			}
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			sleep(ctx, time.Second)
+		}
+	}
+}
+
+// sleep waits for d and reports false when the run ended meanwhile
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// stats collects the latencies and status codes of one request type
+type stats struct {
+	name string
+
+	mu        sync.Mutex
+	durations []time.Duration
+	statuses  map[int]int // 0 counts transport errors
+	errors    int
+}
+
+func newStats(name string) *stats {
+	return &stats{name: name, statuses: make(map[int]int)}
+}
+
+// do sends a request and records its latency. Requests cut off by the end of the run are not
+// counted.
+func (s *stats) do(ctx context.Context, client *http.Client, req *http.Request) {
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.record(time.Since(start), 0)
+		}
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	s.record(time.Since(start), resp.StatusCode)
+}
+
+func (s *stats) record(d time.Duration, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.durations = append(s.durations, d)
+	s.statuses[status]++
+	if status == 0 || status >= 400 {
+		s.errors++
+	}
+}
+
+func (s *stats) print(elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.durations) == 0 {
+		return
+	}
+
+	sort.Slice(s.durations, func(i, j int) bool { return s.durations[i] < s.durations[j] })
+	fmt.Printf("%-8s %9d %7d %9.1f %9s %9s %9s %9s %9s\n", s.name, len(s.durations), s.errors,
+		float64(len(s.durations))/elapsed.Seconds(),
+		s.percentile(0.5), s.percentile(0.9), s.percentile(0.95), s.percentile(0.99),
+		s.durations[len(s.durations)-1].Round(time.Microsecond))
+}
+
+// percentile returns the p-th percentile of the sorted durations by the nearest-rank method
+func (s *stats) percentile(p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(s.durations)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return s.durations[rank].Round(time.Microsecond)
+}
+
+func (s *stats) printStatuses() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.errors == 0 {
+		return
+	}
+
+	codes := make([]int, 0, len(s.statuses))
+	for code := range s.statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	parts := make([]string, 0, len(codes))
+	for _, code := range codes {
+		label := fmt.Sprint(code)
+		if code == 0 {
+			label = "transport error"
+		}
+		parts = append(parts, fmt.Sprintf("%s: %d", label, s.statuses[code]))
+	}
+	fmt.Printf("%s responses: %s\n", s.name, strings.Join(parts, ", "))
+}
+
+// eventCounter counts events received by streaming viewers
+type eventCounter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (c *eventCounter) add() {
+	c.mu.Lock()
+	c.count++
+	c.mu.Unlock()
+}
+
+func (c *eventCounter) load() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
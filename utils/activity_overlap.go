@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"sort"
+	"time"
+)
+
+// ActivityOverlap describes how an imported activity overlaps a recorded track
+type ActivityOverlap struct {
+	Start      time.Time // Shared time window
+	End        time.Time
+	Points     int     // Imported points in the window with a recorded position near in time
+	Similarity float64 // Share of those points close to the recorded position, 0-1
+}
+
+// CompareActivities compares an imported track with a recorded one, both ordered by timestamp.
+// Each imported point in the shared time window is compared with the recorded position nearest
+// in time, if one is within maxOffset, and matches when it is at most maxDistance meters away;
+// points in recording gaps are not compared. It returns nil when the tracks share no time.
+func CompareActivities(imported, recorded []TimedPoint, maxOffset time.Duration, maxDistance float64) *ActivityOverlap {
+	if len(imported) == 0 || len(recorded) == 0 {
+		return nil
+	}
+
+	start := imported[0].Timestamp
+	if recorded[0].Timestamp.After(start) {
+		start = recorded[0].Timestamp
+	}
+	end := imported[len(imported)-1].Timestamp
+	if recorded[len(recorded)-1].Timestamp.Before(end) {
+		end = recorded[len(recorded)-1].Timestamp
+	}
+	if end.Before(start) {
+		return nil
+	}
+
+	overlap := &ActivityOverlap{Start: start, End: end}
+	matched := 0
+	for _, point := range imported {
+		if point.Timestamp.Before(start) || point.Timestamp.After(end) {
+			continue
+		}
+
+		nearest := nearestInTime(recorded, point.Timestamp)
+		offset := nearest.Timestamp.Sub(point.Timestamp)
+		if offset < -maxOffset || offset > maxOffset {
+			continue
+		}
+
+		overlap.Points++
+		if HaversineDistance(point.Latitude, point.Longitude, nearest.Latitude, nearest.Longitude) <= maxDistance {
+			matched++
+		}
+	}
+
+	if overlap.Points > 0 {
+		overlap.Similarity = float64(matched) / float64(overlap.Points)
+	}
+	return overlap
+}
+
+// nearestInTime returns the point of a non-empty track, ordered by timestamp, closest to t
+func nearestInTime(track []TimedPoint, t time.Time) TimedPoint {
+	i := sort.Search(len(track), func(i int) bool { return !track[i].Timestamp.Before(t) })
+	if i == len(track) {
+		return track[i-1]
+	}
+	if i > 0 && t.Sub(track[i-1].Timestamp) < track[i].Timestamp.Sub(t) {
+		return track[i-1]
+	}
+	return track[i]
+}
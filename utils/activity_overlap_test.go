@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lineTrack returns points every interval from start of a walk north at about 1.1 m/s, which
+// started at lat 47.0 at 08:00
+func lineTrack(start time.Time, count int, interval time.Duration, lon float64) []TimedPoint {
+	walkStart := time.Date(2025, 9, 10, 8, 0, 0, 0, time.UTC)
+	points := make([]TimedPoint, count)
+	for i := range points {
+		timestamp := start.Add(time.Duration(i) * interval)
+		points[i] = TimedPoint{
+			Timestamp: timestamp,
+			Latitude:  47.0 + timestamp.Sub(walkStart).Seconds()*0.00001,
+			Longitude: lon,
+		}
+	}
+	return points
+}
+
+func TestCompareActivities(t *testing.T) {
+	start := time.Date(2025, 9, 10, 8, 0, 0, 0, time.UTC)
+
+	t.Run("same activity", func(t *testing.T) {
+		imported := lineTrack(start, 60, 10*time.Second, 19.0)
+		recorded := lineTrack(start.Add(5*time.Second), 20, 30*time.Second, 19.0)
+
+		overlap := CompareActivities(imported, recorded, 2*time.Minute, 150)
+		if assert.NotNil(t, overlap) {
+			assert.Equal(t, recorded[0].Timestamp, overlap.Start)
+			assert.Equal(t, recorded[19].Timestamp, overlap.End)
+			assert.Greater(t, overlap.Points, 50)
+			assert.Equal(t, 1.0, overlap.Similarity)
+		}
+	})
+
+	t.Run("different place at the same time", func(t *testing.T) {
+		imported := lineTrack(start, 60, 10*time.Second, 19.0)
+		recorded := lineTrack(start, 60, 10*time.Second, 19.1) // About 7.6 km east
+
+		overlap := CompareActivities(imported, recorded, 2*time.Minute, 150)
+		if assert.NotNil(t, overlap) {
+			assert.Equal(t, 60, overlap.Points)
+			assert.Equal(t, 0.0, overlap.Similarity)
+		}
+	})
+
+	t.Run("no shared time", func(t *testing.T) {
+		imported := lineTrack(start, 10, time.Minute, 19.0)
+		recorded := lineTrack(start.Add(time.Hour), 10, time.Minute, 19.0)
+
+		assert.Nil(t, CompareActivities(imported, recorded, 2*time.Minute, 150))
+		assert.Nil(t, CompareActivities(imported, nil, 2*time.Minute, 150))
+	})
+
+	t.Run("recording gaps are not compared", func(t *testing.T) {
+		imported := lineTrack(start, 60, 10*time.Second, 19.0)
+		recorded := []TimedPoint{imported[0], imported[59]}
+
+		overlap := CompareActivities(imported, recorded, 30*time.Second, 150)
+		if assert.NotNil(t, overlap) {
+			assert.Equal(t, 8, overlap.Points) // Within 30 s of either end
+			assert.Equal(t, 1.0, overlap.Similarity)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RecordAltitude returns the altitude of a location, waypoint or track point record, nil when none
+// was recorded. Number fields cannot be null, so has_altitude tells an altitude of 0 at sea level
+// from a missing one.
+func RecordAltitude(record *models.Record) *float64 {
+	if !record.GetBool("has_altitude") {
+		return nil
+	}
+	altitude := record.GetFloat("altitude")
+	return &altitude
+}
+
+// SetRecordAltitude stores the altitude of a location, waypoint or track point record, clearing it
+// when nil
+func SetRecordAltitude(record *models.Record, altitude *float64) {
+	if altitude == nil {
+		record.Set("altitude", 0)
+		record.Set("has_altitude", false)
+		return
+	}
+	record.Set("altitude", *altitude)
+	record.Set("has_altitude", true)
+}
+
+// RecordCoordinates returns the GeoJSON position of a location or waypoint record: longitude,
+// latitude and the altitude when one was recorded
+func RecordCoordinates(record *models.Record) []float64 {
+	coordinates := []float64{record.GetFloat("longitude"), record.GetFloat("latitude")}
+	if altitude := RecordAltitude(record); altitude != nil {
+		coordinates = append(coordinates, *altitude)
+	}
+	return coordinates
+}
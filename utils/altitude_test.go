@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAltitude(t *testing.T) {
+	newRecord := func() *models.Record {
+		record := models.NewRecord(&models.Collection{})
+		record.Set("latitude", 47.5)
+		record.Set("longitude", 19.04)
+		return record
+	}
+
+	t.Run("Sea level", func(t *testing.T) {
+		record := newRecord()
+		seaLevel := 0.0
+		SetRecordAltitude(record, &seaLevel)
+
+		altitude := RecordAltitude(record)
+		if assert.NotNil(t, altitude) {
+			assert.Equal(t, 0.0, *altitude)
+		}
+		assert.Equal(t, []float64{19.04, 47.5, 0}, RecordCoordinates(record))
+	})
+
+	t.Run("Below sea level", func(t *testing.T) {
+		record := newRecord()
+		deadSea := -430.5
+		SetRecordAltitude(record, &deadSea)
+
+		assert.Equal(t, []float64{19.04, 47.5, -430.5}, RecordCoordinates(record))
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		record := newRecord()
+		assert.Nil(t, RecordAltitude(record))
+		assert.Equal(t, []float64{19.04, 47.5}, RecordCoordinates(record))
+	})
+
+	t.Run("Cleared", func(t *testing.T) {
+		record := newRecord()
+		altitude := 120.0
+		SetRecordAltitude(record, &altitude)
+		SetRecordAltitude(record, nil)
+
+		assert.Nil(t, RecordAltitude(record))
+		assert.Equal(t, 0.0, record.GetFloat("altitude"))
+	})
+}
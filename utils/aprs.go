@@ -0,0 +1,305 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrAPRSNoPosition is returned for APRS packets that do not report a position, e.g. messages,
+// status reports and server comments
+var ErrAPRSNoPosition = errors.New("aprs: packet has no position")
+
+// APRSPosition is a position beacon received from APRS-IS
+type APRSPosition struct {
+	Source    string // Callsign with SSID of the sending station
+	Latitude  float64
+	Longitude float64
+	Altitude  *float64  // Meters
+	Speed     *float64  // Knots
+	Course    *float64  // Degrees
+	Time      time.Time // Zero when the beacon carries no UTC timestamp
+}
+
+// APRSFollow maps a followed callsign to the user and session its beacons are tracked for
+type APRSFollow struct {
+	Callsign string
+	Username string
+	Session  string // Empty for the user's automatic session
+}
+
+// ParseAPRSFollows parses followed callsigns given as "CALLSIGN=username/session", the session
+// being optional. Callsigns are compared case-insensitively including the SSID.
+func ParseAPRSFollows(entries []string) ([]APRSFollow, error) {
+	var follows []APRSFollow
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		callsign, target, ok := strings.Cut(entry, "=")
+		username, session, _ := strings.Cut(target, "/")
+		if !ok || callsign == "" || username == "" {
+			return nil, fmt.Errorf("invalid APRS follow %q, expected CALLSIGN=username/session", entry)
+		}
+		follows = append(follows, APRSFollow{
+			Callsign: strings.ToUpper(strings.TrimSpace(callsign)),
+			Username: strings.TrimSpace(username),
+			Session:  strings.TrimSpace(session),
+		})
+	}
+	return follows, nil
+}
+
+// ParseAPRSPacket decodes the position of an APRS-IS packet in TNC2 format, e.g.
+// "N0CALL-9>APRS,TCPIP*,qAC,T2TEST:!4903.50N/07201.75W-". Uncompressed, compressed and Mic-E
+// positions are supported. now resolves the day of DHM timestamps.
+func ParseAPRSPacket(line string, now time.Time) (*APRSPosition, error) {
+	if strings.HasPrefix(line, "#") {
+		return nil, ErrAPRSNoPosition // Server comment or keep-alive
+	}
+
+	header, info, ok := strings.Cut(line, ":")
+	source, path, ok2 := strings.Cut(header, ">")
+	if !ok || !ok2 || source == "" || info == "" {
+		return nil, fmt.Errorf("aprs: malformed packet %q", line)
+	}
+	destination, _, _ := strings.Cut(path, ",")
+
+	var position *APRSPosition
+	var err error
+	switch info[0] {
+	case '!', '=':
+		position, err = parseAPRSPosition(info[1:])
+	case '/', '@':
+		if len(info) < 8 {
+			return nil, errors.New("aprs: timestamped position too short")
+		}
+		position, err = parseAPRSPosition(info[8:])
+		if err == nil {
+			position.Time = parseAPRSTimestamp(info[1:8], now)
+		}
+	case '`', '\'':
+		position, err = parseMicE(destination, info[1:])
+	default:
+		return nil, ErrAPRSNoPosition
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	position.Source = strings.ToUpper(source)
+	return position, nil
+}
+
+// parseAPRSPosition decodes an uncompressed or compressed position with the data following it
+func parseAPRSPosition(data string) (*APRSPosition, error) {
+	if len(data) > 0 && (data[0] >= '0' && data[0] <= '9' || data[0] == ' ') {
+		return parseUncompressedPosition(data)
+	}
+	return parseCompressedPosition(data)
+}
+
+// parseUncompressedPosition decodes "DDMM.mmN/DDDMM.mmW-" with optional "CSE/SPD" extension and
+// "/A=FFFFFF" altitude in the comment. Ambiguity spaces are read as zeros.
+func parseUncompressedPosition(data string) (*APRSPosition, error) {
+	if len(data) < 19 {
+		return nil, errors.New("aprs: position too short")
+	}
+	data = strings.Replace(data[:18], " ", "0", -1) + data[18:]
+
+	latitude, err := parseAPRSCoordinate(data[0:7], data[7], 2, 'N', 'S')
+	if err != nil {
+		return nil, err
+	}
+	longitude, err := parseAPRSCoordinate(data[9:17], data[17], 3, 'E', 'W')
+	if err != nil {
+		return nil, err
+	}
+	position := &APRSPosition{Latitude: latitude, Longitude: longitude}
+
+	comment := data[19:]
+	if len(comment) >= 7 && comment[3] == '/' {
+		course, courseErr := strconv.ParseFloat(comment[0:3], 64)
+		speed, speedErr := strconv.ParseFloat(comment[4:7], 64)
+		if courseErr == nil && speedErr == nil {
+			position.Course = &course
+			position.Speed = &speed
+			comment = comment[7:]
+		}
+	}
+	if index := strings.Index(comment, "/A="); index >= 0 && len(comment) >= index+9 {
+		if feet, err := strconv.ParseFloat(comment[index+3:index+9], 64); err == nil {
+			altitude := feet * 0.3048
+			position.Altitude = &altitude
+		}
+	}
+
+	return position, nil
+}
+
+// parseAPRSCoordinate converts (D)DDMM.mm and its hemisphere to decimal degrees
+func parseAPRSCoordinate(value string, hemisphere byte, degreeDigits int, positive, negative byte) (float64, error) {
+	degrees, err := strconv.Atoi(value[:degreeDigits])
+	if err != nil {
+		return 0, fmt.Errorf("aprs: invalid coordinate %q", value)
+	}
+	minutes, err := strconv.ParseFloat(value[degreeDigits:], 64)
+	if err != nil || minutes >= 60 {
+		return 0, fmt.Errorf("aprs: invalid coordinate %q", value)
+	}
+
+	coordinate := float64(degrees) + minutes/60
+	switch hemisphere {
+	case positive:
+		return coordinate, nil
+	case negative:
+		return -coordinate, nil
+	default:
+		return 0, fmt.Errorf("aprs: invalid hemisphere %q", hemisphere)
+	}
+}
+
+// parseCompressedPosition decodes the base-91 "/YYYYXXXX$csT" format
+func parseCompressedPosition(data string) (*APRSPosition, error) {
+	if len(data) < 13 {
+		return nil, errors.New("aprs: compressed position too short")
+	}
+	for i := 1; i < 9; i++ {
+		if data[i] < 33 || data[i] > 123 {
+			return nil, errors.New("aprs: invalid compressed position")
+		}
+	}
+
+	position := &APRSPosition{
+		Latitude:  90 - float64(base91(data[1:5]))/380926,
+		Longitude: -180 + float64(base91(data[5:9]))/190463,
+	}
+
+	c, s, t := data[10], data[11], data[12]
+	switch {
+	case c == ' ':
+		// No course, speed or altitude
+	case (t-33)>>3&0x03 == 0x02:
+		altitude := math.Pow(1.002, float64(int(c-33)*91+int(s-33))) * 0.3048
+		position.Altitude = &altitude
+	case c >= '!' && c <= 'z':
+		course := float64(c-33) * 4
+		speed := math.Pow(1.08, float64(s-33)) - 1
+		position.Course = &course
+		position.Speed = &speed
+	}
+
+	return position, nil
+}
+
+func base91(value string) int {
+	result := 0
+	for i := 0; i < len(value); i++ {
+		result = result*91 + int(value[i]-33)
+	}
+	return result
+}
+
+// parseMicE decodes a Mic-E position, whose latitude is encoded in the destination callsign
+func parseMicE(destination, data string) (*APRSPosition, error) {
+	destination, _, _ = strings.Cut(destination, "-")
+	if len(destination) != 6 || len(data) < 8 {
+		return nil, errors.New("aprs: Mic-E packet too short")
+	}
+
+	var digits [6]int
+	for i := 0; i < 6; i++ {
+		switch c := destination[i]; {
+		case c >= '0' && c <= '9':
+			digits[i] = int(c - '0')
+		case c >= 'A' && c <= 'J':
+			digits[i] = int(c - 'A')
+		case c >= 'P' && c <= 'Y':
+			digits[i] = int(c - 'P')
+		case c == 'K' || c == 'L' || c == 'Z':
+			digits[i] = 0 // Position ambiguity
+		default:
+			return nil, fmt.Errorf("aprs: invalid Mic-E destination %q", destination)
+		}
+	}
+	// The last three destination characters also carry the hemisphere and longitude offset flags
+	flag := func(i int) bool { return destination[i] >= 'P' && destination[i] <= 'Z' }
+
+	latitude := float64(digits[0]*10+digits[1]) + (float64(digits[2]*10+digits[3])+float64(digits[4]*10+digits[5])/100)/60
+	if !flag(3) {
+		latitude = -latitude
+	}
+
+	degrees := int(data[0]) - 28
+	if flag(4) {
+		degrees += 100
+	}
+	if degrees >= 180 && degrees <= 189 {
+		degrees -= 80
+	} else if degrees >= 190 && degrees <= 199 {
+		degrees -= 190
+	}
+	minutes := int(data[1]) - 28
+	if minutes >= 60 {
+		minutes -= 60
+	}
+	hundredths := int(data[2]) - 28
+	longitude := float64(degrees) + (float64(minutes)+float64(hundredths)/100)/60
+	if flag(5) {
+		longitude = -longitude
+	}
+	if math.Abs(latitude) > 90 || math.Abs(longitude) > 180 {
+		return nil, errors.New("aprs: Mic-E position out of range")
+	}
+
+	sp, dc, se := int(data[3])-28, int(data[4])-28, int(data[5])-28
+	speed := float64(sp*10 + dc/10)
+	if speed >= 800 {
+		speed -= 800
+	}
+	course := float64(dc%10*100 + se)
+	if course >= 400 {
+		course -= 400
+	}
+
+	return &APRSPosition{Latitude: latitude, Longitude: longitude, Speed: &speed, Course: &course}, nil
+}
+
+// parseAPRSTimestamp decodes a "DDHHMMz" or "HHMMSSh" UTC timestamp; local time timestamps and
+// invalid values are ignored
+func parseAPRSTimestamp(value string, now time.Time) time.Time {
+	now = now.UTC()
+	switch value[6] {
+	case 'z':
+		day, dayErr := strconv.Atoi(value[0:2])
+		hour, hourErr := strconv.Atoi(value[2:4])
+		minute, minuteErr := strconv.Atoi(value[4:6])
+		if dayErr != nil || hourErr != nil || minuteErr != nil {
+			return time.Time{}
+		}
+		timestamp := time.Date(now.Year(), now.Month(), day, hour, minute, 0, 0, time.UTC)
+		// A day after today is from last month
+		if timestamp.After(now.Add(24 * time.Hour)) {
+			timestamp = timestamp.AddDate(0, -1, 0)
+		}
+		return timestamp
+	case 'h':
+		timestamp, err := time.Parse("150405", value[0:6])
+		if err != nil {
+			return time.Time{}
+		}
+		timestamp = time.Date(now.Year(), now.Month(), now.Day(),
+			timestamp.Hour(), timestamp.Minute(), timestamp.Second(), 0, time.UTC)
+		if timestamp.After(now.Add(time.Hour)) {
+			timestamp = timestamp.AddDate(0, 0, -1)
+		}
+		return timestamp
+	default:
+		return time.Time{}
+	}
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var aprsNow = time.Date(2025, 9, 10, 12, 0, 0, 0, time.UTC)
+
+func TestParseAPRSPacket_Uncompressed(t *testing.T) {
+	position, err := ParseAPRSPacket("n0call-9>APRS,TCPIP*,qAC,T2TEST:!4903.50N/07201.75W-Hiking /A=001234", aprsNow)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "N0CALL-9", position.Source)
+		assert.InDelta(t, 49.058333, position.Latitude, 1e-6)
+		assert.InDelta(t, -72.029167, position.Longitude, 1e-6)
+		assert.InDelta(t, 376.1232, *position.Altitude, 1e-4)
+		assert.Nil(t, position.Speed)
+		assert.True(t, position.Time.IsZero())
+	}
+
+	position, err = ParseAPRSPacket("N0CALL>APRS:=4903.50S/07201.75E>088/036", aprsNow)
+	if assert.NoError(t, err) {
+		assert.InDelta(t, -49.058333, position.Latitude, 1e-6)
+		assert.InDelta(t, 72.029167, position.Longitude, 1e-6)
+		assert.Equal(t, 88.0, *position.Course)
+		assert.Equal(t, 36.0, *position.Speed)
+		assert.Nil(t, position.Altitude)
+	}
+}
+
+func TestParseAPRSPacket_Timestamp(t *testing.T) {
+	position, err := ParseAPRSPacket("N0CALL>APRS:@092345z4903.50N/07201.75W>", aprsNow)
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2025, 9, 9, 23, 45, 0, 0, time.UTC), position.Time)
+	}
+
+	// A day after today is last month's
+	position, err = ParseAPRSPacket("N0CALL>APRS:/302345z4903.50N/07201.75W>", aprsNow)
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2025, 8, 30, 23, 45, 0, 0, time.UTC), position.Time)
+	}
+
+	position, err = ParseAPRSPacket("N0CALL>APRS:@234517h4903.50N/07201.75W>", aprsNow)
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2025, 9, 9, 23, 45, 17, 0, time.UTC), position.Time)
+	}
+
+	// Local time is ignored
+	position, err = ParseAPRSPacket("N0CALL>APRS:@092345/4903.50N/07201.75W>", aprsNow)
+	if assert.NoError(t, err) {
+		assert.True(t, position.Time.IsZero())
+	}
+}
+
+func TestParseAPRSPacket_Compressed(t *testing.T) {
+	position, err := ParseAPRSPacket("N0CALL>APRS:!/5L!!<*e7>7P[", aprsNow)
+	if assert.NoError(t, err) {
+		assert.InDelta(t, 49.5, position.Latitude, 1e-4)
+		assert.InDelta(t, -72.75, position.Longitude, 1e-4)
+		assert.Equal(t, 88.0, *position.Course)
+		assert.InDelta(t, 36.2, *position.Speed, 0.1)
+	}
+
+	position, err = ParseAPRSPacket("N0CALL>APRS:!/5L!!<*e7>S]1", aprsNow)
+	if assert.NoError(t, err) {
+		assert.InDelta(t, 10004*0.3048, *position.Altitude, 1)
+		assert.Nil(t, position.Speed)
+	}
+}
+
+func TestParseAPRSPacket_MicE(t *testing.T) {
+	position, err := ParseAPRSPacket("N0CALL-7>S32U6T,WIDE1-1,qAR,N0GATE:`(_fn\"Oj/", aprsNow)
+	if assert.NoError(t, err) {
+		assert.InDelta(t, 33.427333, position.Latitude, 1e-6)
+		assert.InDelta(t, -12.129, position.Longitude, 1e-6)
+		assert.Equal(t, 20.0, *position.Speed)
+		assert.Equal(t, 251.0, *position.Course)
+	}
+}
+
+func TestParseAPRSPacket_Invalid(t *testing.T) {
+	_, err := ParseAPRSPacket("# aprsc 2.1.19 10 Sep 2025 12:00:00 GMT T2TEST", aprsNow)
+	assert.ErrorIs(t, err, ErrAPRSNoPosition)
+
+	_, err = ParseAPRSPacket("N0CALL>APRS::N1CALL   :Hello", aprsNow)
+	assert.ErrorIs(t, err, ErrAPRSNoPosition)
+
+	_, err = ParseAPRSPacket("N0CALL>APRS:!4903.50X/07201.75W-", aprsNow)
+	assert.Error(t, err, "invalid hemisphere")
+
+	_, err = ParseAPRSPacket("garbage", aprsNow)
+	assert.Error(t, err)
+}
+
+func TestParseAPRSFollows(t *testing.T) {
+	follows, err := ParseAPRSFollows([]string{"oh2abc-7=alice/hiking", " N0CALL=bob ", ""})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []APRSFollow{
+			{Callsign: "OH2ABC-7", Username: "alice", Session: "hiking"},
+			{Callsign: "N0CALL", Username: "bob"},
+		}, follows)
+	}
+
+	_, err = ParseAPRSFollows([]string{"OH2ABC-7"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"strconv"
+
+	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/webp" // WebP avatars are decoded, the standard library only knows the other formats
+
+	"vibe-tracker/constants"
+)
+
+// ProcessAvatar checks the dimensions of an uploaded avatar image and re-encodes it as a square PNG
+// of constants.AvatarSize pixels, cropped from the center. Re-encoding drops metadata and anything
+// appended to the image data, so only the pixels of the upload are ever served. Animated images
+// keep their first frame.
+func ProcessAvatar(data []byte) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ValidationErrors{{Field: "avatar", Tag: "image", Message: "avatar is not a readable image"}}
+	}
+
+	// Checked before decoding, the header alone decides how much memory decoding would take
+	if err := checkAvatarDimensions(config.Width, config.Height); err != nil {
+		return nil, err
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, ValidationErrors{{Field: "avatar", Tag: "image", Message: "avatar is not a readable image"}}
+	}
+
+	size := constants.AvatarSize
+	if bounds := img.Bounds(); bounds.Dx() < size || bounds.Dy() < size {
+		// Small images are cropped to a square but not scaled up
+		size = min(bounds.Dx(), bounds.Dy())
+	}
+	avatar := imaging.Fill(img, size, size, imaging.Center, imaging.Lanczos)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, avatar); err != nil {
+		return nil, fmt.Errorf("failed to encode avatar: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func checkAvatarDimensions(width, height int) error {
+	value := strconv.Itoa(width) + "x" + strconv.Itoa(height)
+
+	if width < constants.AvatarMinDimension || height < constants.AvatarMinDimension {
+		return ValidationErrors{{Field: "avatar", Tag: "min_dimensions", Value: value,
+			Message: fmt.Sprintf("avatar must be at least %dx%d pixels", constants.AvatarMinDimension, constants.AvatarMinDimension)}}
+	}
+	if width > constants.AvatarMaxDimension || height > constants.AvatarMaxDimension {
+		return ValidationErrors{{Field: "avatar", Tag: "max_dimensions", Value: value,
+			Message: fmt.Sprintf("avatar must be at most %dx%d pixels", constants.AvatarMaxDimension, constants.AvatarMaxDimension)}}
+	}
+	return nil
+}
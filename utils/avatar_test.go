@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/constants"
+)
+
+func encodeTestPNG(width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(width/2, height/2, color.RGBA{R: 255, A: 255})
+
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func TestProcessAvatar(t *testing.T) {
+	t.Run("scaled down to a square", func(t *testing.T) {
+		avatar, err := ProcessAvatar(encodeTestPNG(1200, 800))
+		assert.NoError(t, err)
+
+		config, format, err := image.DecodeConfig(bytes.NewReader(avatar))
+		assert.NoError(t, err)
+		assert.Equal(t, "png", format)
+		assert.Equal(t, constants.AvatarSize, config.Width)
+		assert.Equal(t, constants.AvatarSize, config.Height)
+	})
+
+	t.Run("small images are not scaled up", func(t *testing.T) {
+		avatar, err := ProcessAvatar(encodeTestPNG(100, 80))
+		assert.NoError(t, err)
+
+		config, _, err := image.DecodeConfig(bytes.NewReader(avatar))
+		assert.NoError(t, err)
+		assert.Equal(t, 80, config.Width)
+		assert.Equal(t, 80, config.Height)
+	})
+
+	t.Run("appended data is dropped", func(t *testing.T) {
+		upload := append(encodeTestPNG(100, 100), []byte("<script>alert(1)</script>")...)
+		avatar, err := ProcessAvatar(upload)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(avatar), "<script>")
+	})
+
+	t.Run("too small", func(t *testing.T) {
+		_, err := ProcessAvatar(encodeTestPNG(constants.AvatarMinDimension-1, 200))
+		if assert.IsType(t, ValidationErrors{}, err) {
+			assert.Equal(t, "min_dimensions", err.(ValidationErrors)[0].Tag)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		_, err := ProcessAvatar(encodeTestPNG(constants.AvatarMaxDimension+1, 100))
+		if assert.IsType(t, ValidationErrors{}, err) {
+			assert.Equal(t, "max_dimensions", err.(ValidationErrors)[0].Tag)
+		}
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		_, err := ProcessAvatar([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"))
+		if assert.IsType(t, ValidationErrors{}, err) {
+			assert.Equal(t, "image", err.(ValidationErrors)[0].Tag)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"time"
+)
+
+// RenderBadge renders a flat two-part SVG badge in the style of shields.io, with the label on a
+// grey background and the message on the given color
+func RenderBadge(label, message, color string) []byte {
+	labelWidth := badgeTextWidth(label) + 10
+	messageWidth := badgeTextWidth(message) + 10
+	width := labelWidth + messageWidth
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, width, label, message)
+	fmt.Fprintf(&buf, `<title>%s: %s</title>`, label, message)
+	buf.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&buf, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, width)
+	fmt.Fprintf(&buf, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`,
+		labelWidth, labelWidth, messageWidth, html.EscapeString(color), width)
+	buf.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	fmt.Fprintf(&buf, `<text x="%.1f" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%.1f" y="14">%s</text>`,
+		float64(labelWidth)/2, label, float64(labelWidth)/2, label)
+	fmt.Fprintf(&buf, `<text x="%.1f" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%.1f" y="14">%s</text>`,
+		float64(labelWidth)+float64(messageWidth)/2, message, float64(labelWidth)+float64(messageWidth)/2, message)
+	buf.WriteString(`</g></svg>`)
+	return buf.Bytes()
+}
+
+// badgeTextWidth estimates the width in pixels of text in 11px Verdana; SVG badges cannot measure
+// their text, so the widths are approximated per character class
+func badgeTextWidth(text string) int {
+	width := 0.0
+	for _, char := range text {
+		switch {
+		case char == ' ' || char == 'i' || char == 'l' || char == 'j' || char == '.' || char == ',' || char == ':' || char == '\'' || char == '|':
+			width += 3.5
+		case char == 'f' || char == 't' || char == 'r' || char == 'I' || char == '(' || char == ')' || char == '/' || char == '-':
+			width += 4.5
+		case char == 'm' || char == 'w' || char == 'M' || char == 'W' || char == '%':
+			width += 10.5
+		case char >= 'A' && char <= 'Z':
+			width += 7.5
+		default:
+			width += 6.8
+		}
+	}
+	return int(width + 0.5)
+}
+
+// FormatTimeAgo describes how long ago something happened, e.g. "just now", "5 min ago",
+// "3 h ago" or "2 days ago"
+func FormatTimeAgo(then, now time.Time) string {
+	elapsed := now.Sub(then)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%d min ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%d h ago", int(elapsed.Hours()))
+	case elapsed < 48*time.Hour:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", int(elapsed.Hours()/24))
+	}
+}
+
+// FormatDistance formats a distance in meters as "850 m" below a kilometer and "12.3 km" above
+func FormatDistance(meters float64) string {
+	if meters < 1000 {
+		return fmt.Sprintf("%.0f m", meters)
+	}
+	return fmt.Sprintf("%.1f km", meters/1000)
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderBadge(t *testing.T) {
+	svg := string(RenderBadge("last seen", "5 min ago", "#4c1"))
+
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg"`))
+	assert.Contains(t, svg, `aria-label="last seen: 5 min ago"`)
+	assert.Contains(t, svg, `fill="#4c1"`)
+	assert.Contains(t, svg, `>5 min ago</text>`)
+	assert.True(t, strings.HasSuffix(svg, `</svg>`))
+
+	// Text is escaped, labels cannot inject markup
+	svg = string(RenderBadge(`<script>`, `a & "b"`, "#555"))
+	assert.NotContains(t, svg, "<script>")
+	assert.Contains(t, svg, "&lt;script&gt;")
+	assert.Contains(t, svg, "a &amp; &#34;b&#34;")
+}
+
+func TestBadgeTextWidth(t *testing.T) {
+	assert.Equal(t, 0, badgeTextWidth(""))
+	assert.Greater(t, badgeTextWidth("WWW"), badgeTextWidth("iii"))
+	assert.Greater(t, badgeTextWidth("12.3 km today"), badgeTextWidth("12.3 km"))
+}
+
+func TestFormatTimeAgo(t *testing.T) {
+	now := time.Date(2025, 9, 10, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "just now", FormatTimeAgo(now.Add(-30*time.Second), now))
+	assert.Equal(t, "just now", FormatTimeAgo(now.Add(time.Minute), now)) // Clock skew
+	assert.Equal(t, "5 min ago", FormatTimeAgo(now.Add(-5*time.Minute), now))
+	assert.Equal(t, "3 h ago", FormatTimeAgo(now.Add(-3*time.Hour-10*time.Minute), now))
+	assert.Equal(t, "1 day ago", FormatTimeAgo(now.Add(-30*time.Hour), now))
+	assert.Equal(t, "4 days ago", FormatTimeAgo(now.Add(-100*time.Hour), now))
+}
+
+func TestFormatDistance(t *testing.T) {
+	assert.Equal(t, "0 m", FormatDistance(0))
+	assert.Equal(t, "850 m", FormatDistance(849.6))
+	assert.Equal(t, "12.3 km", FormatDistance(12345))
+}
@@ -0,0 +1,11 @@
+package utils
+
+// IsLowBatteryTransition reports whether a battery level crossed below the threshold since the
+// previous reading, so an alert is raised once per discharge rather than for every point. A
+// missing previous reading counts as above the threshold.
+func IsLowBatteryTransition(previous *float64, current, threshold float64) bool {
+	if threshold <= 0 || current >= threshold {
+		return false
+	}
+	return previous == nil || *previous >= threshold
+}
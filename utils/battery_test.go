@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLowBatteryTransition(t *testing.T) {
+	level := func(v float64) *float64 { return &v }
+
+	assert.True(t, IsLowBatteryTransition(level(20), 14, 15))
+	assert.True(t, IsLowBatteryTransition(level(15), 14.9, 15))
+	assert.True(t, IsLowBatteryTransition(nil, 10, 15))
+	assert.False(t, IsLowBatteryTransition(level(14), 12, 15), "already alerted")
+	assert.False(t, IsLowBatteryTransition(level(20), 15, 15))
+	assert.False(t, IsLowBatteryTransition(level(20), 5, 0), "alerts disabled")
+}
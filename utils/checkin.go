@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"vibe-tracker/constants"
+)
+
+// CheckInDeadline returns the time by which the next point of an armed session is expected: one
+// interval after the latest point, or after arming when no point arrived since
+func CheckInDeadline(armedAt, lastPointAt time.Time, interval time.Duration) time.Time {
+	start := armedAt
+	if lastPointAt.After(start) {
+		start = lastPointAt
+	}
+	return start.Add(interval)
+}
+
+// CheckInAlertLevel returns how far a missed check-in has escalated at the given time: the owner
+// is alerted once the deadline passes, the emergency contacts one more interval later
+func CheckInAlertLevel(deadline, now time.Time, interval time.Duration) int {
+	switch {
+	case !now.After(deadline):
+		return constants.CheckInAlertNone
+	case !now.After(deadline.Add(interval)):
+		return constants.CheckInAlertOwner
+	default:
+		return constants.CheckInAlertContacts
+	}
+}
+
+// MapLink returns a web map link centered on a position
+func MapLink(latitude, longitude float64) string {
+	return fmt.Sprintf(constants.MapLinkFormat, latitude, longitude)
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/constants"
+)
+
+func TestCheckInDeadline(t *testing.T) {
+	armedAt := time.Unix(1758000000, 0)
+	interval := 30 * time.Minute
+
+	assert.Equal(t, armedAt.Add(interval), CheckInDeadline(armedAt, time.Time{}, interval))
+	assert.Equal(t, armedAt.Add(interval), CheckInDeadline(armedAt, armedAt.Add(-time.Hour), interval))
+	assert.Equal(t, armedAt.Add(50*time.Minute), CheckInDeadline(armedAt, armedAt.Add(20*time.Minute), interval))
+}
+
+func TestCheckInAlertLevel(t *testing.T) {
+	deadline := time.Unix(1758000000, 0)
+	interval := 30 * time.Minute
+
+	assert.Equal(t, constants.CheckInAlertNone, CheckInAlertLevel(deadline, deadline.Add(-time.Second), interval))
+	assert.Equal(t, constants.CheckInAlertNone, CheckInAlertLevel(deadline, deadline, interval))
+	assert.Equal(t, constants.CheckInAlertOwner, CheckInAlertLevel(deadline, deadline.Add(time.Second), interval))
+	assert.Equal(t, constants.CheckInAlertOwner, CheckInAlertLevel(deadline, deadline.Add(interval), interval))
+	assert.Equal(t, constants.CheckInAlertContacts, CheckInAlertLevel(deadline, deadline.Add(interval+time.Second), interval))
+}
+
+func TestMapLink(t *testing.T) {
+	assert.Equal(t, "https://www.openstreetmap.org/?mlat=47.497900&mlon=19.040200#map=15/47.497900/19.040200", MapLink(47.4979, 19.0402))
+}
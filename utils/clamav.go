@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseClamdAddress parses a clamd socket address: "unix:/run/clamav/clamd.ctl" or an absolute
+// path for a Unix socket, "tcp:127.0.0.1:3310" or "host:port" for TCP
+func ParseClamdAddress(address string) (network, addr string, err error) {
+	switch {
+	case strings.HasPrefix(address, "unix:"):
+		network, addr = "unix", strings.TrimPrefix(address, "unix:")
+	case strings.HasPrefix(address, "tcp:"):
+		network, addr = "tcp", strings.TrimPrefix(address, "tcp:")
+	case strings.HasPrefix(address, "/"):
+		network, addr = "unix", address
+	default:
+		network, addr = "tcp", address
+	}
+
+	if addr == "" || (network == "tcp" && !strings.Contains(addr, ":")) {
+		return "", "", fmt.Errorf("invalid clamd address %q", address)
+	}
+	return network, addr, nil
+}
+
+// WriteClamdStream sends content to clamd with the INSTREAM command: chunks prefixed with their
+// length as a 4 byte big-endian integer, terminated by a zero length chunk
+func WriteClamdStream(w io.Writer, content io.Reader, chunkSize int) error {
+	if _, err := w.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 4+chunkSize)
+	for {
+		n, err := io.ReadFull(content, chunk[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(chunk[:4], uint32(n))
+			if _, werr := w.Write(chunk[:4+n]); werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+// ParseClamdResponse parses clamd's reply to a scan. It returns the signature name for infected
+// content, "" for clean content, and an error when clamd could not scan it.
+func ParseClamdResponse(response string) (string, error) {
+	response = strings.TrimSpace(strings.TrimRight(response, "\x00"))
+	result := strings.TrimSpace(response[strings.Index(response, ":")+1:])
+
+	switch {
+	case result == "OK":
+		return "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return strings.TrimSuffix(result, " FOUND"), nil
+	default:
+		return "", fmt.Errorf("clamd scan failed: %s", response)
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClamdAddress(t *testing.T) {
+	tests := map[string][2]string{
+		"unix:/run/clamav/clamd.ctl": {"unix", "/run/clamav/clamd.ctl"},
+		"/var/run/clamd.sock":        {"unix", "/var/run/clamd.sock"},
+		"tcp:127.0.0.1:3310":         {"tcp", "127.0.0.1:3310"},
+		"clamav:3310":                {"tcp", "clamav:3310"},
+	}
+	for address, expected := range tests {
+		network, addr, err := ParseClamdAddress(address)
+		assert.NoError(t, err, address)
+		assert.Equal(t, expected, [2]string{network, addr}, address)
+	}
+
+	for _, invalid := range []string{"unix:", "tcp:clamav", "clamav"} {
+		_, _, err := ParseClamdAddress(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestWriteClamdStream(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteClamdStream(&out, strings.NewReader("abcde"), 2))
+	assert.Equal(t, "zINSTREAM\x00"+
+		"\x00\x00\x00\x02ab"+
+		"\x00\x00\x00\x02cd"+
+		"\x00\x00\x00\x01e"+
+		"\x00\x00\x00\x00", out.String())
+
+	out.Reset()
+	assert.NoError(t, WriteClamdStream(&out, strings.NewReader(""), 2))
+	assert.Equal(t, "zINSTREAM\x00\x00\x00\x00\x00", out.String())
+}
+
+func TestParseClamdResponse(t *testing.T) {
+	signature, err := ParseClamdResponse("stream: OK\x00")
+	assert.NoError(t, err)
+	assert.Equal(t, "", signature)
+
+	signature, err = ParseClamdResponse("stream: Win.Test.EICAR_HDB-1 FOUND\x00")
+	assert.NoError(t, err)
+	assert.Equal(t, "Win.Test.EICAR_HDB-1", signature)
+
+	_, err = ParseClamdResponse("INSTREAM size limit exceeded. ERROR\x00")
+	assert.Error(t, err)
+
+	_, err = ParseClamdResponse("")
+	assert.Error(t, err)
+}
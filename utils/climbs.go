@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"vibe-tracker/constants"
+	"vibe-tracker/models"
+)
+
+// DetectClimbs finds the categorized climbs along a track. A climb runs from a low point to the
+// highest point reached before the track descends more than the descent tolerance, so short dips
+// do not split it. Climbs flatter than the minimum gradient or scoring below category 4 are
+// dropped. Points without altitude only contribute to the distance.
+func DetectClimbs(points []TimedPoint) []models.Climb {
+	climbs := []models.Climb{}
+
+	// Cumulative distance and altitude of the points that have an altitude
+	var indexes []int
+	var distances, altitudes []float64
+	distance := 0.0
+	for i, point := range points {
+		if i > 0 {
+			distance += HaversineDistance(points[i-1].Latitude, points[i-1].Longitude, point.Latitude, point.Longitude)
+		}
+		if point.Altitude == nil {
+			continue
+		}
+		indexes = append(indexes, i)
+		distances = append(distances, distance)
+		altitudes = append(altitudes, *point.Altitude)
+	}
+	if len(indexes) < 2 {
+		return climbs
+	}
+
+	emit := func(low, high int) {
+		length := distances[high] - distances[low]
+		if length <= 0 {
+			return
+		}
+		gain := altitudes[high] - altitudes[low]
+		gradient := gain / length * 100
+		category := ClimbCategory(length, gradient)
+		if gradient < constants.ClimbMinGradient || category == "" {
+			return
+		}
+
+		start, end := points[indexes[low]], points[indexes[high]]
+		climbs = append(climbs, models.Climb{
+			StartIndex:      indexes[low],
+			EndIndex:        indexes[high],
+			StartDistance:   distances[low],
+			EndDistance:     distances[high],
+			Length:          length,
+			StartAltitude:   altitudes[low],
+			EndAltitude:     altitudes[high],
+			ElevationGain:   gain,
+			AverageGradient: gradient,
+			MaxGradient:     maxGradient(distances[low:high+1], altitudes[low:high+1], gradient),
+			Category:        category,
+			From:            []float64{start.Longitude, start.Latitude},
+			To:              []float64{end.Longitude, end.Latitude},
+		})
+	}
+
+	low, high := 0, 0
+	for i := 1; i < len(altitudes); i++ {
+		if altitudes[i] >= altitudes[high] {
+			high = i
+		} else if altitudes[high]-altitudes[i] > constants.ClimbDescentTolerance {
+			emit(low, high)
+			low, high = i, i
+		}
+		// Dropping below the start before gaining much restarts the climb from the new low, flat
+		// stretches before the climb are not part of it either
+		if altitudes[i] <= altitudes[low] {
+			low, high = i, i
+		}
+	}
+	emit(low, high)
+
+	return climbs
+}
+
+// ClimbCategory returns the category of a climb of the given length in meters and average
+// gradient in percent, or an empty string when it is too easy to be categorized
+func ClimbCategory(length, gradient float64) string {
+	score := length * gradient
+	switch {
+	case score >= constants.ClimbScoreCategoryHC:
+		return constants.ClimbCategoryHC
+	case score >= constants.ClimbScoreCategory1:
+		return constants.ClimbCategory1
+	case score >= constants.ClimbScoreCategory2:
+		return constants.ClimbCategory2
+	case score >= constants.ClimbScoreCategory3:
+		return constants.ClimbCategory3
+	case score >= constants.ClimbScoreCategory4:
+		return constants.ClimbCategory4
+	default:
+		return ""
+	}
+}
+
+// maxGradient returns the steepest gradient in percent over any stretch of at least the span
+// length, or the average gradient for climbs shorter than the span
+func maxGradient(distances, altitudes []float64, average float64) float64 {
+	steepest := average
+	j := 0
+	for i := range distances {
+		for j < len(distances) && distances[j]-distances[i] < constants.ClimbMaxGradientSpan {
+			j++
+		}
+		if j == len(distances) {
+			break
+		}
+		if gradient := (altitudes[j] - altitudes[i]) / (distances[j] - distances[i]) * 100; gradient > steepest {
+			steepest = gradient
+		}
+	}
+	return steepest
+}
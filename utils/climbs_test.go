@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// profilePoints builds a northbound track with about 111 m between points and the given altitudes
+func profilePoints(altitudes ...float64) []TimedPoint {
+	points := make([]TimedPoint, len(altitudes))
+	for i := range altitudes {
+		points[i] = TimedPoint{Latitude: 47 + float64(i)*0.001, Longitude: 19, Altitude: &altitudes[i]}
+	}
+	return points
+}
+
+func TestDetectClimbs(t *testing.T) {
+	t.Run("categorized climb", func(t *testing.T) {
+		altitudes := []float64{100, 100}
+		for i := 1; i <= 20; i++ {
+			altitudes = append(altitudes, 100+float64(i)*5.56)
+		}
+		altitudes = append(altitudes, 150, 100)
+
+		climbs := DetectClimbs(profilePoints(altitudes...))
+		if assert.Len(t, climbs, 1) {
+			climb := climbs[0]
+			assert.Equal(t, 1, climb.StartIndex)
+			assert.Equal(t, 21, climb.EndIndex)
+			assert.InDelta(t, 2224, climb.Length, 5)
+			assert.InDelta(t, 111.2, climb.ElevationGain, 0.1)
+			assert.InDelta(t, 5, climb.AverageGradient, 0.1)
+			assert.Equal(t, "4", climb.Category)
+			assert.Equal(t, []float64{19, 47.001}, climb.From)
+		}
+	})
+
+	t.Run("short dips do not split a climb", func(t *testing.T) {
+		climbs := DetectClimbs(profilePoints(0, 20, 40, 35, 60, 80, 100, 120, 115, 140, 160, 180, 200))
+		if assert.Len(t, climbs, 1) {
+			assert.Equal(t, 0, climbs[0].StartIndex)
+			assert.Equal(t, 12, climbs[0].EndIndex)
+			assert.InDelta(t, 22.5, climbs[0].MaxGradient, 0.1)
+			assert.Equal(t, "3", climbs[0].Category)
+		}
+	})
+
+	t.Run("descents split climbs", func(t *testing.T) {
+		climbs := DetectClimbs(profilePoints(0, 30, 60, 90, 120, 60, 0, 30, 60, 90, 120))
+		if assert.Len(t, climbs, 2) {
+			assert.Equal(t, 4, climbs[0].EndIndex)
+			assert.Equal(t, 6, climbs[1].StartIndex)
+		}
+	})
+
+	t.Run("flat and missing altitudes", func(t *testing.T) {
+		assert.Empty(t, DetectClimbs(profilePoints(100, 101, 102, 103, 104)))
+
+		points := profilePoints(0, 50, 100)
+		points[1].Altitude = nil
+		climbs := DetectClimbs(points)
+		if assert.Len(t, climbs, 1) {
+			assert.Equal(t, 2, climbs[0].EndIndex)
+			assert.InDelta(t, 222.4, climbs[0].Length, 1)
+		}
+
+		assert.NotNil(t, DetectClimbs(nil))
+	})
+}
+
+func TestClimbCategory(t *testing.T) {
+	assert.Equal(t, "", ClimbCategory(1000, 5))
+	assert.Equal(t, "4", ClimbCategory(2000, 4))
+	assert.Equal(t, "3", ClimbCategory(4000, 5))
+	assert.Equal(t, "2", ClimbCategory(5000, 7))
+	assert.Equal(t, "1", ClimbCategory(10000, 7))
+	assert.Equal(t, "HC", ClimbCategory(15000, 7))
+}
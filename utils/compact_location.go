@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"mime"
+	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
+	"google.golang.org/protobuf/proto"
+
+	"vibe-tracker/constants"
+	appmodels "vibe-tracker/models"
+	trackingv1 "vibe-tracker/proto/vibetracker/v1"
+)
+
+// compactLocation is the CBOR encoding of a tracked point: a map with small integer keys, which
+// are the field numbers of the protobuf LocationPoint message. Numbers may be sent as integers or
+// half, single or double precision floats. Unset fields are left out of the map.
+type compactLocation struct {
+	Latitude  *float64 `cbor:"1,keyasint,omitempty"`
+	Longitude *float64 `cbor:"2,keyasint,omitempty"`
+	Timestamp int64    `cbor:"3,keyasint,omitempty"`
+	Altitude  *float64 `cbor:"4,keyasint,omitempty"`
+	Speed     *float64 `cbor:"5,keyasint,omitempty"`
+	SpeedUnit string   `cbor:"6,keyasint,omitempty"`
+	Accuracy  *float64 `cbor:"7,keyasint,omitempty"`
+	Device    string   `cbor:"8,keyasint,omitempty"`
+	Battery   *float64 `cbor:"9,keyasint,omitempty"`
+	HeartRate *float64 `cbor:"10,keyasint,omitempty"`
+	Session   string   `cbor:"11,keyasint,omitempty"`
+	Status    string   `cbor:"12,keyasint,omitempty"`
+	Event     string   `cbor:"13,keyasint,omitempty"`
+
+	VerticalAccuracy *float64 `cbor:"15,keyasint,omitempty"`
+	Bearing          *float64 `cbor:"16,keyasint,omitempty"`
+	ClientID         string   `cbor:"17,keyasint,omitempty"`
+	Cadence          *float64 `cbor:"18,keyasint,omitempty"`
+	Power            *float64 `cbor:"19,keyasint,omitempty"`
+	Temperature      *float64 `cbor:"20,keyasint,omitempty"`
+}
+
+var compactDecoder, _ = cbor.DecOptions{DupMapKey: cbor.DupMapKeyEnforcedAPF}.DecMode()
+
+// compactEncoder writes floats in the shortest precision that keeps their value
+var compactEncoder, _ = cbor.EncOptions{ShortestFloat: cbor.ShortestFloat16}.EncMode()
+
+// IsCompactLocationType reports whether a Content-Type header selects one of the compact
+// encodings of DecodeCompactLocation
+func IsCompactLocationType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case constants.ContentTypeCBOR, constants.ContentTypeProtobuf, constants.ContentTypeProtobufIETF:
+		return true
+	}
+	return false
+}
+
+// DecodeCompactLocation decodes a tracked point sent as CBOR or as a protobuf LocationPoint into
+// the GeoJSON request of POST /api/track, so it is validated and stored like a JSON body.
+// Coordinates are range checked here, the GeoJSON body check does not run for compact bodies.
+func DecodeCompactLocation(contentType string, body []byte) (*appmodels.LocationRequest, error) {
+	var point compactLocation
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case constants.ContentTypeCBOR:
+		if err := compactDecoder.Unmarshal(body, &point); err != nil {
+			return nil, fmt.Errorf("invalid CBOR body: %w", err)
+		}
+	case constants.ContentTypeProtobuf, constants.ContentTypeProtobufIETF:
+		var message trackingv1.LocationPoint
+		if err := proto.Unmarshal(body, &message); err != nil {
+			return nil, fmt.Errorf("invalid protobuf body: %w", err)
+		}
+		point = compactLocation{
+			Latitude:  &message.Latitude,
+			Longitude: &message.Longitude,
+			Timestamp: message.Timestamp,
+			Altitude:  message.Altitude,
+			Speed:     message.Speed,
+			SpeedUnit: message.SpeedUnit,
+			Accuracy:  message.Accuracy,
+			Device:    message.Device,
+			Battery:   message.Battery,
+			HeartRate: message.HeartRate,
+			Session:   message.Session,
+			Status:    message.Status,
+			Event:     message.Event,
+
+			VerticalAccuracy: message.VerticalAccuracy,
+			Bearing:          message.Bearing,
+			ClientID:         message.ClientId,
+			Cadence:          message.Cadence,
+			Power:            message.Power,
+			Temperature:      message.Temperature,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", contentType)
+	}
+
+	if err := validateCompactPosition(point); err != nil {
+		return nil, err
+	}
+
+	coordinates := appmodels.Coordinates{*point.Longitude, *point.Latitude}
+	if point.Altitude != nil {
+		coordinates = append(coordinates, *point.Altitude)
+	}
+
+	return &appmodels.LocationRequest{
+		Type:     "Feature",
+		Geometry: appmodels.Geometry{Type: "Point", Coordinates: coordinates},
+		Properties: appmodels.LocationProperties{
+			Timestamp: point.Timestamp,
+			Speed:     point.Speed,
+			SpeedUnit: point.SpeedUnit,
+			Accuracy:  point.Accuracy,
+			Device:    point.Device,
+			Battery:   point.Battery,
+			HeartRate: point.HeartRate,
+			Session:   point.Session,
+			Status:    point.Status,
+			Event:     point.Event,
+			ClientID:  point.ClientID,
+
+			VerticalAccuracy: point.VerticalAccuracy,
+			Bearing:          point.Bearing,
+
+			Cadence:     point.Cadence,
+			Power:       point.Power,
+			Temperature: point.Temperature,
+		},
+	}, nil
+}
+
+// EncodeCompactLocation encodes a tracked point as CBOR or as a protobuf LocationPoint, the
+// reverse of DecodeCompactLocation for trackers and tests. The session title and username are
+// not part of the compact encodings.
+func EncodeCompactLocation(contentType string, location *appmodels.LocationRequest) ([]byte, error) {
+	coordinates := location.Geometry.Coordinates
+	if len(coordinates) < 2 {
+		return nil, fmt.Errorf("coordinates must be [longitude, latitude] or [longitude, latitude, altitude]")
+	}
+	var altitude *float64
+	if len(coordinates) > 2 {
+		altitude = &coordinates[2]
+	}
+	properties := location.Properties
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case constants.ContentTypeCBOR:
+		return compactEncoder.Marshal(compactLocation{
+			Latitude:  &coordinates[1],
+			Longitude: &coordinates[0],
+			Timestamp: properties.Timestamp,
+			Altitude:  altitude,
+			Speed:     properties.Speed,
+			SpeedUnit: properties.SpeedUnit,
+			Accuracy:  properties.Accuracy,
+			Device:    properties.Device,
+			Battery:   properties.Battery,
+			HeartRate: properties.HeartRate,
+			Session:   properties.Session,
+			Status:    properties.Status,
+			Event:     properties.Event,
+
+			VerticalAccuracy: properties.VerticalAccuracy,
+			Bearing:          properties.Bearing,
+			ClientID:         properties.ClientID,
+			Cadence:          properties.Cadence,
+			Power:            properties.Power,
+			Temperature:      properties.Temperature,
+		})
+	case constants.ContentTypeProtobuf, constants.ContentTypeProtobufIETF:
+		return proto.Marshal(&trackingv1.LocationPoint{
+			Latitude:  coordinates[1],
+			Longitude: coordinates[0],
+			Timestamp: properties.Timestamp,
+			Altitude:  altitude,
+			Speed:     properties.Speed,
+			SpeedUnit: properties.SpeedUnit,
+			Accuracy:  properties.Accuracy,
+			Device:    properties.Device,
+			Battery:   properties.Battery,
+			HeartRate: properties.HeartRate,
+			Session:   properties.Session,
+			Status:    properties.Status,
+			Event:     properties.Event,
+
+			VerticalAccuracy: properties.VerticalAccuracy,
+			Bearing:          properties.Bearing,
+			ClientId:         properties.ClientID,
+			Cadence:          properties.Cadence,
+			Power:            properties.Power,
+			Temperature:      properties.Temperature,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", contentType)
+	}
+}
+
+// validateCompactPosition checks that latitude and longitude are present and within range
+func validateCompactPosition(point compactLocation) error {
+	var errs ValidationErrors
+
+	fields := []struct {
+		name  string
+		value *float64
+		limit float64
+	}{
+		{"latitude", point.Latitude, 90},
+		{"longitude", point.Longitude, 180},
+	}
+	for _, field := range fields {
+		switch {
+		case field.value == nil:
+			errs = append(errs, ValidationError{Field: field.name, Tag: "required",
+				Message: fmt.Sprintf("%s is required", field.name)})
+		case math.IsNaN(*field.value) || math.Abs(*field.value) > field.limit:
+			errs = append(errs, ValidationError{Field: field.name, Tag: field.name,
+				Value:   strconv.FormatFloat(*field.value, 'f', -1, 64),
+				Message: fmt.Sprintf("%s must be between %g and %g", field.name, -field.limit, field.limit)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	appmodels "vibe-tracker/models"
+	trackingv1 "vibe-tracker/proto/vibetracker/v1"
+)
+
+func TestIsCompactLocationType(t *testing.T) {
+	assert.True(t, IsCompactLocationType("application/cbor"))
+	assert.True(t, IsCompactLocationType("application/x-protobuf"))
+	assert.True(t, IsCompactLocationType("application/protobuf; charset=binary"))
+	assert.False(t, IsCompactLocationType("application/json"))
+	assert.False(t, IsCompactLocationType(""))
+}
+
+func TestDecodeCompactLocation_CBOR(t *testing.T) {
+	body, err := cbor.Marshal(map[int]any{
+		1:  47.497913,
+		2:  19.040236,
+		3:  1758430000,
+		4:  float32(112.5),
+		5:  3,
+		9:  87,
+		11: "morning-run",
+		18: 92,
+		20: float32(-3.5),
+	})
+	assert.NoError(t, err)
+	assert.Less(t, len(body), 70)
+
+	location, err := DecodeCompactLocation("application/cbor", body)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Feature", location.Type)
+		assert.Equal(t, "Point", location.Geometry.Type)
+		assert.Equal(t, []float64{19.040236, 47.497913, 112.5}, []float64(location.Geometry.Coordinates))
+		assert.Equal(t, int64(1758430000), location.Properties.Timestamp)
+		assert.Equal(t, 3.0, *location.Properties.Speed)
+		assert.Equal(t, 87.0, *location.Properties.Battery)
+		assert.Nil(t, location.Properties.Accuracy)
+		assert.Equal(t, "morning-run", location.Properties.Session)
+		assert.Equal(t, 92.0, *location.Properties.Cadence)
+		assert.Nil(t, location.Properties.Power)
+		assert.Equal(t, -3.5, *location.Properties.Temperature)
+		assert.NoError(t, ValidateStruct(location))
+	}
+}
+
+func TestDecodeCompactLocation_Protobuf(t *testing.T) {
+	accuracy := 4.0
+	body, err := proto.Marshal(&trackingv1.LocationPoint{
+		Latitude:  -33.856784,
+		Longitude: 151.215297,
+		Timestamp: 1758430000,
+		Accuracy:  &accuracy,
+		Device:    "tracker-1",
+	})
+	assert.NoError(t, err)
+	assert.Less(t, len(body), 60)
+
+	location, err := DecodeCompactLocation("application/x-protobuf", body)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []float64{151.215297, -33.856784}, []float64(location.Geometry.Coordinates))
+		assert.Equal(t, 4.0, *location.Properties.Accuracy)
+		assert.Equal(t, "tracker-1", location.Properties.Device)
+		assert.NoError(t, ValidateStruct(location))
+	}
+}
+
+func TestDecodeCompactLocation_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		point  map[int]any
+		fields []string
+	}{
+		{"missing position", map[int]any{3: 1758430000}, []string{"latitude", "longitude"}},
+		{"latitude out of range", map[int]any{1: 91, 2: 19}, []string{"latitude"}},
+		{"longitude out of range", map[int]any{1: 47, 2: -180.5}, []string{"longitude"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := cbor.Marshal(tt.point)
+			assert.NoError(t, err)
+
+			_, err = DecodeCompactLocation("application/cbor", body)
+			errs, ok := err.(ValidationErrors)
+			if assert.True(t, ok, "expected ValidationErrors, got %v", err) {
+				fields := make([]string, len(errs))
+				for i, e := range errs {
+					fields[i] = e.Field
+				}
+				assert.Equal(t, tt.fields, fields)
+			}
+		})
+	}
+
+	_, err := DecodeCompactLocation("application/cbor", []byte{0xff})
+	assert.Error(t, err)
+
+	_, err = DecodeCompactLocation("application/cbor", []byte{0xa2, 0x01, 0x01, 0x01, 0x02})
+	assert.Error(t, err, "duplicate keys are rejected")
+
+	_, err = DecodeCompactLocation("text/plain", []byte("47,19"))
+	assert.Error(t, err)
+}
+
+func TestEncodeCompactLocation(t *testing.T) {
+	speed, bearing, verticalAccuracy := 4.5, 271.5, 8.0
+	location := &appmodels.LocationRequest{
+		Type:     "Feature",
+		Geometry: appmodels.Geometry{Type: "Point", Coordinates: appmodels.Coordinates{19.040236, 47.497913, 112.5}},
+		Properties: appmodels.LocationProperties{
+			Timestamp:        1758430000,
+			Speed:            &speed,
+			Session:          "morning-run",
+			ClientID:         "5f0c8a3e",
+			VerticalAccuracy: &verticalAccuracy,
+			Bearing:          &bearing,
+		},
+	}
+
+	jsonBody, err := json.Marshal(location)
+	assert.NoError(t, err)
+
+	for _, contentType := range []string{"application/cbor", "application/x-protobuf"} {
+		t.Run(contentType, func(t *testing.T) {
+			body, err := EncodeCompactLocation(contentType, location)
+			assert.NoError(t, err)
+			assert.Less(t, len(body), len(jsonBody)/2)
+
+			decoded, err := DecodeCompactLocation(contentType, body)
+			if assert.NoError(t, err) {
+				assert.Equal(t, location, decoded)
+				assert.NoError(t, ValidateStruct(decoded))
+			}
+		})
+	}
+
+	// Unset fields are left out of the CBOR map
+	body, err := EncodeCompactLocation("application/cbor", &appmodels.LocationRequest{
+		Geometry: appmodels.Geometry{Coordinates: appmodels.Coordinates{19, 47}},
+	})
+	assert.NoError(t, err)
+	var point map[int]any
+	assert.NoError(t, cbor.Unmarshal(body, &point))
+	assert.Len(t, point, 2)
+
+	_, err = EncodeCompactLocation("application/json", location)
+	assert.Error(t, err)
+	_, err = EncodeCompactLocation("application/cbor", &appmodels.LocationRequest{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+)
+
+// ContextDao returns a copy of dao whose queries run with ctx, so they are aborted once ctx is
+// cancelled or times out. Model hooks and retry settings are kept. A nil ctx or a dao bound to a
+// running transaction is returned unchanged, transactions carry the context they were begun with.
+func ContextDao(dao *daos.Dao, ctx context.Context) *daos.Dao {
+	if ctx == nil {
+		return dao
+	}
+
+	concurrentDB, ok := dao.ConcurrentDB().(*dbx.DB)
+	if !ok {
+		return dao
+	}
+	nonconcurrentDB, ok := dao.NonconcurrentDB().(*dbx.DB)
+	if !ok {
+		return dao
+	}
+
+	ctxDao := daos.NewMultiDB(concurrentDB.WithContext(ctx), nonconcurrentDB.WithContext(ctx))
+	ctxDao.MaxLockRetries = dao.MaxLockRetries
+	ctxDao.ModelQueryTimeout = dao.ModelQueryTimeout
+	ctxDao.BeforeCreateFunc = dao.BeforeCreateFunc
+	ctxDao.AfterCreateFunc = dao.AfterCreateFunc
+	ctxDao.BeforeUpdateFunc = dao.BeforeUpdateFunc
+	ctxDao.AfterUpdateFunc = dao.AfterUpdateFunc
+	ctxDao.BeforeDeleteFunc = dao.BeforeDeleteFunc
+	ctxDao.AfterDeleteFunc = dao.AfterDeleteFunc
+	return ctxDao
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextDao(t *testing.T) {
+	db := dbx.NewFromDB(nil, "sqlite")
+	dao := daos.New(db)
+	dao.MaxLockRetries = 3
+	dao.AfterCreateFunc = func(eventDao *daos.Dao, m models.Model) error { return nil }
+
+	t.Run("binds queries to the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ctxDao := ContextDao(dao, ctx)
+		assert.NotSame(t, dao, ctxDao)
+		assert.Equal(t, ctx, ctxDao.ConcurrentDB().(*dbx.DB).Context())
+		assert.Equal(t, ctx, ctxDao.NonconcurrentDB().(*dbx.DB).Context())
+		assert.Equal(t, 3, ctxDao.MaxLockRetries)
+		assert.NotNil(t, ctxDao.AfterCreateFunc)
+
+		// The shared dao is left alone
+		assert.Nil(t, db.Context())
+	})
+
+	t.Run("nil context", func(t *testing.T) {
+		assert.Same(t, dao, ContextDao(dao, nil))
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		txDao := daos.New(&dbx.Tx{})
+		assert.Same(t, txDao, ContextDao(txDao, context.Background()))
+	})
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"math"
+	"time"
+)
+
+// ShouldDownsample reports whether a tracked point adds too little to a track to be stored: it
+// follows the last stored point by less than minInterval or lies less than minDistance meters
+// from it. A zero limit is disabled. Points older than the last stored one, e.g. uploaded from
+// a device buffer, fill gaps in the track and are always kept.
+func ShouldDownsample(last, point TimedPoint, minInterval time.Duration, minDistance float64) bool {
+	if point.Timestamp.Before(last.Timestamp) {
+		return false
+	}
+
+	if minInterval > 0 && point.Timestamp.Sub(last.Timestamp) < minInterval {
+		return true
+	}
+	if minDistance > 0 && HaversineDistance(last.Latitude, last.Longitude, point.Latitude, point.Longitude) < minDistance {
+		return true
+	}
+	return false
+}
+
+// DuplicateThresholds configures when a tracked point repeats a stored point: their timestamps
+// differ by at most MaxInterval and they lie at most MaxDistance meters apart. Zero limits match
+// identical timestamps and coordinates only.
+type DuplicateThresholds struct {
+	MaxInterval time.Duration
+	MaxDistance float64 // Meters
+}
+
+// IsDuplicate reports whether a point repeats a stored point
+func (d DuplicateThresholds) IsDuplicate(stored, point TimedPoint) bool {
+	offset := point.Timestamp.Sub(stored.Timestamp)
+	if offset < 0 {
+		offset = -offset
+	}
+	if offset > d.MaxInterval {
+		return false
+	}
+
+	if d.MaxDistance == 0 {
+		return point.Latitude == stored.Latitude && point.Longitude == stored.Longitude
+	}
+	return HaversineDistance(stored.Latitude, stored.Longitude, point.Latitude, point.Longitude) <= d.MaxDistance
+}
+
+// OutlierThresholds configures when a tracked point is a GPS glitch rather than a position of its
+// track: reaching it from the previous point of the session takes more than MaxSpeed. The
+// reported accuracy radii of both points are allowed for, and the interval counts as at least a
+// second, the resolution of point timestamps.
+type OutlierThresholds struct {
+	MaxSpeed float64 // Meters per second
+}
+
+// ImpliedSpeed returns the speed in meters per second needed to reach a point from the previous
+// point, beyond the accuracy radii of both
+func (o OutlierThresholds) ImpliedSpeed(previous, point TimedPoint, previousAccuracy, accuracy float64) float64 {
+	distance := HaversineDistance(previous.Latitude, previous.Longitude, point.Latitude, point.Longitude)
+	distance = math.Max(0, distance-previousAccuracy-accuracy)
+
+	interval := math.Max(math.Abs(point.Timestamp.Sub(previous.Timestamp).Seconds()), 1)
+	return distance / interval
+}
+
+// IsOutlier reports whether a point cannot follow the previous point at MaxSpeed
+func (o OutlierThresholds) IsOutlier(previous, point TimedPoint, previousAccuracy, accuracy float64) bool {
+	return o.ImpliedSpeed(previous, point, previousAccuracy, accuracy) > o.MaxSpeed
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldDownsample(t *testing.T) {
+	start := time.Date(2025, 9, 10, 8, 0, 0, 0, time.UTC)
+	last := TimedPoint{Timestamp: start, Latitude: 47.0, Longitude: 19.0}
+	// About 11 m north of the last point
+	at := func(seconds int) TimedPoint {
+		return TimedPoint{Timestamp: start.Add(time.Duration(seconds) * time.Second), Latitude: 47.0001, Longitude: 19.0}
+	}
+
+	tests := []struct {
+		name        string
+		point       TimedPoint
+		minInterval time.Duration
+		minDistance float64
+		expected    bool
+	}{
+		{"disabled", at(1), 0, 0, false},
+		{"too soon", at(3), 5 * time.Second, 0, true},
+		{"interval reached", at(5), 5 * time.Second, 0, false},
+		{"too close", at(60), 0, 20, true},
+		{"far enough", at(60), 0, 10, false},
+		{"both limits must be met", at(60), 5 * time.Second, 20, true},
+		{"same timestamp", at(0), time.Second, 0, true},
+		{"older point fills a gap", at(-30), 5 * time.Second, 20, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ShouldDownsample(last, tt.point, tt.minInterval, tt.minDistance))
+		})
+	}
+}
+
+func TestDuplicateThresholds_IsDuplicate(t *testing.T) {
+	start := time.Date(2025, 9, 10, 8, 0, 0, 0, time.UTC)
+	stored := TimedPoint{Timestamp: start, Latitude: 47.0, Longitude: 19.0}
+	// About 11 m north of the stored point
+	nearby := func(seconds int) TimedPoint {
+		return TimedPoint{Timestamp: start.Add(time.Duration(seconds) * time.Second), Latitude: 47.0001, Longitude: 19.0}
+	}
+
+	tests := []struct {
+		name       string
+		point      TimedPoint
+		thresholds DuplicateThresholds
+		expected   bool
+	}{
+		{"identical", stored, DuplicateThresholds{}, true},
+		{"other timestamp", TimedPoint{Timestamp: start.Add(time.Second), Latitude: 47.0, Longitude: 19.0}, DuplicateThresholds{}, false},
+		{"other position", nearby(0), DuplicateThresholds{}, false},
+		{"within both limits", nearby(2), DuplicateThresholds{MaxInterval: 2 * time.Second, MaxDistance: 20}, true},
+		{"earlier within limits", nearby(-2), DuplicateThresholds{MaxInterval: 2 * time.Second, MaxDistance: 20}, true},
+		{"too late", nearby(3), DuplicateThresholds{MaxInterval: 2 * time.Second, MaxDistance: 20}, false},
+		{"too far", nearby(1), DuplicateThresholds{MaxInterval: 2 * time.Second, MaxDistance: 10}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.thresholds.IsDuplicate(stored, tt.point))
+		})
+	}
+}
+
+func TestOutlierThresholds_IsOutlier(t *testing.T) {
+	start := time.Date(2025, 9, 10, 8, 0, 0, 0, time.UTC)
+	previous := TimedPoint{Timestamp: start, Latitude: 47.0, Longitude: 19.0}
+	// About 1112 m north of the previous point
+	jump := func(seconds int) TimedPoint {
+		return TimedPoint{Timestamp: start.Add(time.Duration(seconds) * time.Second), Latitude: 47.01, Longitude: 19.0}
+	}
+	thresholds := OutlierThresholds{MaxSpeed: 300 / 3.6}
+
+	tests := []struct {
+		name             string
+		point            TimedPoint
+		previousAccuracy float64
+		accuracy         float64
+		expected         bool
+	}{
+		{"plausible speed", jump(60), 0, 0, false},
+		{"teleport", jump(5), 0, 0, true},
+		{"earlier point", jump(-5), 0, 0, true},
+		{"same timestamp", TimedPoint{Timestamp: start, Latitude: 47.0005, Longitude: 19.0}, 0, 0, false},
+		{"within the accuracy radii", jump(5), 700, 400, false},
+		{"beyond the accuracy radii", jump(5), 300, 200, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, thresholds.IsOutlier(previous, tt.point, tt.previousAccuracy, tt.accuracy))
+		})
+	}
+
+	assert.InDelta(t, 1112/60.0, thresholds.ImpliedSpeed(previous, jump(60), 0, 0), 0.1)
+}
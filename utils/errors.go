@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/getsentry/sentry-go"
 	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/tools/security"
+
+	"vibe-tracker/constants"
 )
 
 // ErrorType represents different categories of errors in the application
@@ -194,3 +198,23 @@ func LogAndWrapError(err error, errorType ErrorType, message string, userID ...s
 
 	return appErr
 }
+
+// NewIncidentID returns a random ID that ties an error response to its log entry and error report
+func NewIncidentID() string {
+	return security.RandomStringWithAlphabet(constants.IncidentIDLength, "0123456789abcdefghijklmnopqrstuvwxyz")
+}
+
+// NewRequestID returns a random ID for a request that did not bring its own
+func NewRequestID() string {
+	return security.RandomStringWithAlphabet(constants.RequestIDLength, "0123456789abcdefghijklmnopqrstuvwxyz")
+}
+
+// ReportJobError sends the failure of a background job to Sentry, tagged with the job name. It
+// does nothing while error reporting is disabled.
+func ReportJobError(err error, job string) {
+	hub := sentry.CurrentHub().Clone()
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("job", job)
+		hub.CaptureException(err)
+	})
+}
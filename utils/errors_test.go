@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+
+	"vibe-tracker/constants"
 )
 
 func TestAppError_Error(t *testing.T) {
@@ -177,3 +179,19 @@ func TestWrapError(t *testing.T) {
 		})
 	}
 }
+
+func TestNewIncidentID(t *testing.T) {
+	first, second := NewIncidentID(), NewIncidentID()
+	if len(first) != constants.IncidentIDLength {
+		t.Errorf("NewIncidentID length = %d, want %d", len(first), constants.IncidentIDLength)
+	}
+	if first == second {
+		t.Errorf("NewIncidentID returned the same ID twice: %s", first)
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	if id := NewRequestID(); len(id) != constants.RequestIDLength {
+		t.Errorf("NewRequestID length = %d, want %d", len(id), constants.RequestIDLength)
+	}
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"time"
+
+	"vibe-tracker/constants"
+)
+
+// ETAEstimate is the remaining distance to a destination and, when the recent movement allows
+// it, the travel speed and expected arrival
+type ETAEstimate struct {
+	Distance  float64       // Meters in a straight line from the latest point
+	Speed     float64       // Meters per second over the recent movement, 0 when unknown
+	Remaining time.Duration // Travel time from the latest point, 0 when unknown
+	ArrivalAt *time.Time
+}
+
+// EstimateArrival estimates the arrival at a destination from points ordered by timestamp. The
+// speed is the distance travelled over the points within the speed window before the latest one,
+// movement that is too short or too slow gives no ETA.
+func EstimateArrival(points []TimedPoint, latitude, longitude float64) ETAEstimate {
+	if len(points) == 0 {
+		return ETAEstimate{}
+	}
+
+	last := points[len(points)-1]
+	estimate := ETAEstimate{Distance: HaversineDistance(last.Latitude, last.Longitude, latitude, longitude)}
+
+	first := len(points) - 1
+	travelled := 0.0
+	for first > 0 && last.Timestamp.Sub(points[first-1].Timestamp) <= constants.ETASpeedWindow {
+		travelled += HaversineDistance(points[first-1].Latitude, points[first-1].Longitude,
+			points[first].Latitude, points[first].Longitude)
+		first--
+	}
+
+	span := last.Timestamp.Sub(points[first].Timestamp)
+	if span < constants.ETAMinSpeedSpan {
+		return estimate
+	}
+
+	speed := travelled / span.Seconds()
+	if speed < constants.ETAMinSpeed {
+		return estimate
+	}
+
+	estimate.Speed = speed
+	estimate.Remaining = time.Duration(estimate.Distance / speed * float64(time.Second)).Round(time.Second)
+	arrival := last.Timestamp.Add(estimate.Remaining)
+	estimate.ArrivalAt = &arrival
+	return estimate
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateArrival(t *testing.T) {
+	start := time.Unix(1758000000, 0)
+	// Moving north along a meridian, 0.001 degrees of latitude is about 111 meters
+	track := func(count int, step time.Duration, degrees float64) []TimedPoint {
+		points := make([]TimedPoint, count)
+		for i := range points {
+			points[i] = TimedPoint{Timestamp: start.Add(time.Duration(i) * step), Latitude: 47.0 + float64(i)*degrees, Longitude: 19.0}
+		}
+		return points
+	}
+
+	t.Run("no points", func(t *testing.T) {
+		assert.Equal(t, ETAEstimate{}, EstimateArrival(nil, 47.1, 19.0))
+	})
+
+	t.Run("moving towards the destination", func(t *testing.T) {
+		points := track(7, 10*time.Second, 0.001)
+		estimate := EstimateArrival(points, 47.1, 19.0)
+
+		assert.InDelta(t, 10452, estimate.Distance, 5)
+		assert.InDelta(t, 11.1, estimate.Speed, 0.1)
+		if assert.NotNil(t, estimate.ArrivalAt) {
+			assert.Equal(t, points[6].Timestamp.Add(estimate.Remaining), *estimate.ArrivalAt)
+			assert.InDelta(t, 940, estimate.Remaining.Seconds(), 5)
+		}
+	})
+
+	t.Run("only recent movement counts", func(t *testing.T) {
+		points := append(track(2, time.Hour, 0.1), track(7, 10*time.Second, 0.001)...)
+		for i := 2; i < len(points); i++ {
+			points[i].Timestamp = points[i].Timestamp.Add(2 * time.Hour)
+			points[i].Latitude += 0.2
+		}
+
+		assert.InDelta(t, 11.1, EstimateArrival(points, 47.5, 19.0).Speed, 0.1)
+	})
+
+	t.Run("too short movement", func(t *testing.T) {
+		estimate := EstimateArrival(track(3, 10*time.Second, 0.001), 47.1, 19.0)
+		assert.Zero(t, estimate.Speed)
+		assert.Nil(t, estimate.ArrivalAt)
+		assert.Greater(t, estimate.Distance, 0.0)
+	})
+
+	t.Run("standing still", func(t *testing.T) {
+		estimate := EstimateArrival(track(10, time.Minute, 0.000001), 47.1, 19.0)
+		assert.Zero(t, estimate.Speed)
+		assert.Nil(t, estimate.ArrivalAt)
+	})
+}
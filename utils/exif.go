@@ -3,12 +3,12 @@ package utils
 import (
 	"fmt"
 	"io"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/rwcarlsen/goexif/exif"
 	"github.com/rwcarlsen/goexif/mknote"
+	"github.com/rwcarlsen/goexif/tiff"
 )
 
 // PhotoEXIFData contains extracted EXIF data from a photo
@@ -17,7 +17,7 @@ type PhotoEXIFData struct {
 	Latitude    *float64
 	Longitude   *float64
 	Altitude    *float64
-	Timestamp   *time.Time
+	Timestamp   *time.Time // Camera wall clock time, in UTC until corrected with CameraTime
 	Make        string
 	Model       string
 	Orientation int
@@ -94,7 +94,7 @@ func extractGPSCoordinates(x *exif.Exif) (*float64, *float64, error) {
 	}
 
 	// Parse latitude
-	lat, err := parseGPSCoordinate(latTag.String())
+	lat, err := parseGPSCoordinate(latTag)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse latitude: %v", err)
 	}
@@ -109,7 +109,7 @@ func extractGPSCoordinates(x *exif.Exif) (*float64, *float64, error) {
 	}
 
 	// Parse longitude
-	lon, err := parseGPSCoordinate(lonTag.String())
+	lon, err := parseGPSCoordinate(lonTag)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse longitude: %v", err)
 	}
@@ -141,9 +141,8 @@ func extractGPSAltitude(x *exif.Exif) (*float64, error) {
 	altRefTag, _ := x.Get(exif.GPSAltitudeRef)
 	// Altitude reference is optional, assume above sea level if not present
 
-	// Parse altitude (typically stored as a rational number)
-	altStr := altTag.String()
-	alt, err := parseRationalString(altStr)
+	// Parse altitude (stored as a rational number)
+	alt, err := rationalValue(altTag, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse altitude: %v", err)
 	}
@@ -182,6 +181,51 @@ func extractTimestamp(x *exif.Exif) (*time.Time, error) {
 	return nil, fmt.Errorf("no valid timestamp found in EXIF data")
 }
 
+// ParseCameraTimezone parses the time zone a camera clock was set to: an IANA name such as
+// Europe/Budapest, or a fixed UTC offset such as +02:00
+func ParseCameraTimezone(zone string) (*time.Location, error) {
+	if zone == "Z" {
+		return time.UTC, nil
+	}
+	if strings.HasPrefix(zone, "+") || strings.HasPrefix(zone, "-") {
+		layout := "-07:00"
+		if !strings.Contains(zone, ":") {
+			layout = "-0700"
+		}
+		parsed, err := time.Parse(layout, zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UTC offset %q", zone)
+		}
+		_, offset := parsed.Zone()
+		if offset < -12*3600 || offset > 14*3600 {
+			return nil, fmt.Errorf("UTC offset %q out of range", zone)
+		}
+		return time.FixedZone(zone, offset), nil
+	}
+	if zone == "" || zone == "Local" {
+		return nil, fmt.Errorf("invalid time zone %q", zone)
+	}
+	return time.LoadLocation(zone)
+}
+
+// CameraTime converts a wall clock time recorded by a camera, which EXIF stores without a time
+// zone, to the instant it was taken at in the camera's time zone. When clocks go back, a repeated
+// wall time resolves to its first occurrence; when they go forward, a skipped wall time resolves
+// with the offset before the change, as shown by a camera that was not adjusted yet.
+func CameraTime(wall time.Time, location *time.Location) time.Time {
+	wall = time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), time.UTC)
+	_, offsetBefore := wall.Add(-24 * time.Hour).In(location).Zone()
+	_, offsetAfter := wall.Add(24 * time.Hour).In(location).Zone()
+
+	for _, offset := range []int{offsetBefore, offsetAfter} {
+		at := wall.Add(-time.Duration(offset) * time.Second)
+		if _, actual := at.In(location).Zone(); actual == offset {
+			return at.UTC()
+		}
+	}
+	return wall.Add(-time.Duration(offsetBefore) * time.Second).UTC()
+}
+
 // extractStringField extracts a string field from EXIF data
 func extractStringField(x *exif.Exif, field exif.FieldName) string {
 	if tag, err := x.Get(field); err == nil {
@@ -202,100 +246,47 @@ func extractIntField(x *exif.Exif, field exif.FieldName) (int, error) {
 	return 0, fmt.Errorf("field not found or not an integer")
 }
 
-// parseGPSCoordinate parses GPS coordinate from EXIF format
-// Format is typically: "deg/1,min/1,sec/100" representing degrees, minutes, seconds
-func parseGPSCoordinate(coordStr string) (float64, error) {
-	// Remove quotes and clean up the string
-	coordStr = strings.Trim(coordStr, "\"")
-
-	// Split by comma to get degrees, minutes, seconds
-	parts := strings.Split(coordStr, ",")
-	if len(parts) != 3 {
-		return 0, fmt.Errorf("invalid GPS coordinate format: %s", coordStr)
-	}
-
-	// Parse degrees
-	degrees, err := parseRationalString(strings.TrimSpace(parts[0]))
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse degrees: %v", err)
+// parseGPSCoordinate parses a GPS coordinate stored as three rationals: degrees, minutes and
+// seconds
+func parseGPSCoordinate(tag *tiff.Tag) (float64, error) {
+	if tag.Count != 3 {
+		return 0, fmt.Errorf("invalid GPS coordinate format: %s", tag)
 	}
 
-	// Parse minutes
-	minutes, err := parseRationalString(strings.TrimSpace(parts[1]))
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse minutes: %v", err)
-	}
-
-	// Parse seconds
-	seconds, err := parseRationalString(strings.TrimSpace(parts[2]))
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse seconds: %v", err)
+	var parts [3]float64
+	for i := range parts {
+		value, err := rationalValue(tag, i)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse GPS coordinate: %v", err)
+		}
+		parts[i] = value
 	}
 
 	// Convert to decimal degrees
-	decimal := degrees + minutes/60.0 + seconds/3600.0
-
-	return decimal, nil
+	return parts[0] + parts[1]/60.0 + parts[2]/3600.0, nil
 }
 
-// parseRationalString parses a rational number string like "123/456"
-func parseRationalString(rational string) (float64, error) {
-	rational = strings.TrimSpace(rational)
-
-	if strings.Contains(rational, "/") {
-		parts := strings.Split(rational, "/")
-		if len(parts) != 2 {
-			return 0, fmt.Errorf("invalid rational format: %s", rational)
-		}
-
-		numerator, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
-		if err != nil {
-			return 0, fmt.Errorf("failed to parse numerator: %v", err)
-		}
-
-		denominator, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
-		if err != nil {
-			return 0, fmt.Errorf("failed to parse denominator: %v", err)
-		}
-
-		if denominator == 0 {
-			return 0, fmt.Errorf("division by zero in rational: %s", rational)
-		}
-
-		return numerator / denominator, nil
-	} else {
-		// It's already a decimal number
-		return strconv.ParseFloat(rational, 64)
+// rationalValue returns the i-th rational of a tag
+func rationalValue(tag *tiff.Tag, i int) (float64, error) {
+	numerator, denominator, err := tag.Rat2(i)
+	if err != nil {
+		return 0, err
 	}
-}
-
-// IsValidImageFormat checks if the file format is supported for EXIF extraction
-func IsValidImageFormat(filename, contentType string) bool {
-	filename = strings.ToLower(filename)
-	contentType = strings.ToLower(contentType)
-
-	// Check file extensions
-	supportedExtensions := []string{".jpg", ".jpeg", ".tiff", ".tif"}
-	for _, ext := range supportedExtensions {
-		if strings.HasSuffix(filename, ext) {
-			return true
-		}
+	if denominator == 0 {
+		return 0, fmt.Errorf("division by zero in rational: %s", tag)
 	}
+	return float64(numerator) / float64(denominator), nil
+}
 
-	// Check content types
-	supportedTypes := []string{
-		"image/jpeg",
-		"image/jpg",
-		"image/tiff",
-		"image/tif",
+// IsValidImageFormat checks if a sniffed content type (see SniffFileType) is accepted for photo
+// waypoints. EXIF data is only read from JPEG photos, others are positioned from the track.
+func IsValidImageFormat(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/webp", "image/heic", "image/heif":
+		return true
+	default:
+		return false
 	}
-	for _, mimeType := range supportedTypes {
-		if contentType == mimeType {
-			return true
-		}
-	}
-
-	return false
 }
 
 // GetFallbackPosition determines a fallback position for a photo based on the intelligent strategy
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCameraTimezone(t *testing.T) {
+	for _, zone := range []string{"Europe/Budapest", "UTC", "Z", "+02:00", "-0530", "+14:00"} {
+		_, err := ParseCameraTimezone(zone)
+		assert.NoError(t, err, zone)
+	}
+	for _, zone := range []string{"", "Local", "Mars/Base", "2:00", "+15:00", "+02:xx"} {
+		_, err := ParseCameraTimezone(zone)
+		assert.Error(t, err, zone)
+	}
+
+	location, err := ParseCameraTimezone("-0530")
+	assert.NoError(t, err)
+	_, offset := time.Date(2025, 1, 1, 0, 0, 0, 0, location).Zone()
+	assert.Equal(t, -(5*3600 + 30*60), offset)
+}
+
+func TestCameraTime(t *testing.T) {
+	wall := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	zone := func(name string) *time.Location {
+		location, err := ParseCameraTimezone(name)
+		assert.NoError(t, err)
+		return location
+	}
+
+	tests := []struct {
+		name     string
+		zone     string
+		wall     time.Time
+		expected time.Time
+	}{
+		{"summer time", "Europe/Budapest", wall(2025, 7, 1, 12, 0), wall(2025, 7, 1, 10, 0)},
+		{"winter time", "Europe/Budapest", wall(2025, 1, 15, 12, 0), wall(2025, 1, 15, 11, 0)},
+		{"before clocks go forward", "Europe/Budapest", wall(2025, 3, 30, 1, 59), wall(2025, 3, 30, 0, 59)},
+		{"skipped when clocks go forward", "Europe/Budapest", wall(2025, 3, 30, 2, 30), wall(2025, 3, 30, 1, 30)},
+		{"after clocks go forward", "Europe/Budapest", wall(2025, 3, 30, 3, 0), wall(2025, 3, 30, 1, 0)},
+		{"before clocks go back", "Europe/Budapest", wall(2025, 10, 26, 1, 59), wall(2025, 10, 25, 23, 59)},
+		{"repeated when clocks go back", "Europe/Budapest", wall(2025, 10, 26, 2, 30), wall(2025, 10, 26, 0, 30)},
+		{"after clocks go back", "Europe/Budapest", wall(2025, 10, 26, 3, 0), wall(2025, 10, 26, 2, 0)},
+		{"skipped west of UTC", "America/New_York", wall(2025, 3, 9, 2, 30), wall(2025, 3, 9, 7, 30)},
+		{"repeated west of UTC", "America/New_York", wall(2025, 11, 2, 1, 30), wall(2025, 11, 2, 5, 30)},
+		{"repeated in the southern hemisphere", "Australia/Sydney", wall(2025, 4, 6, 2, 30), wall(2025, 4, 5, 15, 30)},
+		{"skipped in the southern hemisphere", "Australia/Sydney", wall(2025, 10, 5, 2, 30), wall(2025, 10, 4, 16, 30)},
+		{"fixed offset", "+05:30", wall(2025, 3, 30, 2, 30), wall(2025, 3, 29, 21, 0)},
+		{"UTC", "UTC", wall(2025, 3, 30, 2, 30), wall(2025, 3, 30, 2, 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CameraTime(tt.wall, zone(tt.zone)))
+		})
+	}
+}
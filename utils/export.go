@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"encoding/xml"
+	"math"
+	"time"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/models"
+)
+
+// ExportPoint is a recorded location with the measures exports carry along
+type ExportPoint struct {
+	TimedPoint
+	Speed       *float64
+	HeartRate   *float64
+	Cadence     *float64
+	Power       *float64
+	Temperature *float64
+	Accuracy    *float64
+}
+
+// The export structs below mirror GPX, GPXTrack, ... but name the extension elements with their
+// namespace prefixes, which encoding/xml does not produce from namespaced names.
+
+type gpxExport struct {
+	XMLName       xml.Name       `xml:"gpx"`
+	Version       string         `xml:"version,attr"`
+	Creator       string         `xml:"creator,attr"`
+	Namespace     string         `xml:"xmlns,attr"`
+	TrackPointNS  string         `xml:"xmlns:gpxtpx,attr"`
+	PowerNS       string         `xml:"xmlns:gpxpx,attr"`
+	VibeTrackerNS string         `xml:"xmlns:vibe,attr"`
+	Metadata      gpxExportMeta  `xml:"metadata"`
+	Track         gpxExportTrack `xml:"trk"`
+}
+
+type gpxExportMeta struct {
+	Name        string `xml:"name"`
+	Description string `xml:"desc,omitempty"`
+	Time        string `xml:"time,omitempty"`
+}
+
+type gpxExportTrack struct {
+	Name        string             `xml:"name"`
+	Description string             `xml:"desc,omitempty"`
+	Segments    []gpxExportSegment `xml:"trkseg"`
+}
+
+type gpxExportSegment struct {
+	Points []gpxExportPoint `xml:"trkpt"`
+}
+
+type gpxExportPoint struct {
+	Latitude   float64              `xml:"lat,attr"`
+	Longitude  float64              `xml:"lon,attr"`
+	Elevation  *float64             `xml:"ele,omitempty"`
+	Time       string               `xml:"time"`
+	Extensions *gpxExportExtensions `xml:"extensions,omitempty"`
+}
+
+type gpxExportExtensions struct {
+	TrackPoint *gpxExportTrackPointExtension `xml:"gpxtpx:TrackPointExtension,omitempty"`
+	Power      *int                          `xml:"gpxpx:PowerInWatts,omitempty"`
+	Accuracy   *float64                      `xml:"vibe:accuracy,omitempty"`
+}
+
+// gpxExportTrackPointExtension lists its elements in the order of the TrackPointExtension schema
+type gpxExportTrackPointExtension struct {
+	Temperature *float64 `xml:"gpxtpx:atemp,omitempty"`
+	HeartRate   *int     `xml:"gpxtpx:hr,omitempty"`
+	Cadence     *int     `xml:"gpxtpx:cad,omitempty"`
+	Speed       *float64 `xml:"gpxtpx:speed,omitempty"`
+}
+
+// BuildSessionGPX writes a session track as a GPX 1.1 file with one track segment per segment
+// index (see SegmentTrack). Heart rate, cadence, temperature and speed go to Garmin's
+// TrackPointExtension, power to its PowerExtension and the fix accuracy to a vibe-tracker
+// extension, so ParseGPX reads all of them back.
+func BuildSessionGPX(name, description string, points []ExportPoint, segments []int) ([]byte, error) {
+	gpx := gpxExport{
+		Version:       "1.1",
+		Creator:       "vibe-tracker",
+		Namespace:     constants.GPXNamespace,
+		TrackPointNS:  constants.GPXTrackPointExtensionNS,
+		PowerNS:       constants.GPXPowerExtensionNS,
+		VibeTrackerNS: constants.GPXVibeTrackerExtensionNS,
+		Metadata:      gpxExportMeta{Name: name, Description: description},
+		Track:         gpxExportTrack{Name: name, Description: description},
+	}
+	if len(points) > 0 {
+		gpx.Metadata.Time = formatExportTime(points[0].Timestamp)
+	}
+
+	for _, segment := range splitExportSegments(points, segments) {
+		exported := gpxExportSegment{Points: make([]gpxExportPoint, len(segment))}
+		for i, point := range segment {
+			exported.Points[i] = gpxExportPoint{
+				Latitude:   point.Latitude,
+				Longitude:  point.Longitude,
+				Elevation:  point.Altitude,
+				Time:       formatExportTime(point.Timestamp),
+				Extensions: gpxExtensionsOf(point),
+			}
+		}
+		gpx.Track.Segments = append(gpx.Track.Segments, exported)
+	}
+
+	body, err := xml.MarshalIndent(gpx, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// BuildSessionGeoJSON writes a session track as a GeoJSON FeatureCollection with one feature per
+// segment index (see SegmentTrack). The recorded measures are kept in a "measures" foreign member
+// of each feature, aligned with its coordinates.
+func BuildSessionGeoJSON(session, title string, points []ExportPoint, segments []int) models.TrackExportCollection {
+	collection := models.TrackExportCollection{Type: "FeatureCollection", Features: []models.TrackExportFeature{}}
+
+	for index, segment := range splitExportSegments(points, segments) {
+		positions := make([][]float64, len(segment))
+		measures := models.TrackMeasures{Time: make([]string, len(segment))}
+		var speed, heartRate, cadence, power, temperature, accuracy []*float64
+
+		for i, point := range segment {
+			positions[i] = []float64{point.Longitude, point.Latitude}
+			if point.Altitude != nil {
+				positions[i] = append(positions[i], *point.Altitude)
+			}
+			measures.Time[i] = formatExportTime(point.Timestamp)
+			speed = append(speed, point.Speed)
+			heartRate = append(heartRate, point.HeartRate)
+			cadence = append(cadence, point.Cadence)
+			power = append(power, point.Power)
+			temperature = append(temperature, point.Temperature)
+			accuracy = append(accuracy, point.Accuracy)
+		}
+		measures.Speed = recordedMeasure(speed)
+		measures.HeartRate = recordedMeasure(heartRate)
+		measures.Cadence = recordedMeasure(cadence)
+		measures.Power = recordedMeasure(power)
+		measures.Temperature = recordedMeasure(temperature)
+		measures.Accuracy = recordedMeasure(accuracy)
+
+		geometry := models.TrackExportGeometry{Type: "LineString", Coordinates: positions}
+		if len(positions) == 1 {
+			geometry = models.TrackExportGeometry{Type: "Point", Coordinates: positions[0]}
+		}
+
+		collection.Features = append(collection.Features, models.TrackExportFeature{
+			Type:     "Feature",
+			Geometry: geometry,
+			Properties: models.TrackExportProperties{
+				Session:   session,
+				Title:     title,
+				Segment:   index,
+				StartTime: measures.Time[0],
+				EndTime:   measures.Time[len(segment)-1],
+			},
+			Measures: measures,
+		})
+	}
+
+	return collection
+}
+
+// splitExportSegments groups points by their segment index
+func splitExportSegments(points []ExportPoint, segments []int) [][]ExportPoint {
+	var split [][]ExportPoint
+	for i, point := range points {
+		if i == 0 || segments[i] != segments[i-1] {
+			split = append(split, nil)
+		}
+		split[len(split)-1] = append(split[len(split)-1], point)
+	}
+	return split
+}
+
+func gpxExtensionsOf(point ExportPoint) *gpxExportExtensions {
+	extensions := &gpxExportExtensions{Power: roundedMeasure(point.Power), Accuracy: point.Accuracy}
+	if point.HeartRate != nil || point.Cadence != nil || point.Temperature != nil || point.Speed != nil {
+		extensions.TrackPoint = &gpxExportTrackPointExtension{
+			Temperature: point.Temperature,
+			HeartRate:   roundedMeasure(point.HeartRate),
+			Cadence:     roundedMeasure(point.Cadence),
+			Speed:       point.Speed,
+		}
+	}
+
+	if extensions.TrackPoint == nil && extensions.Power == nil && extensions.Accuracy == nil {
+		return nil
+	}
+	return extensions
+}
+
+// roundedMeasure rounds measures the GPX extension schemas define as integers
+func roundedMeasure(value *float64) *int {
+	if value == nil {
+		return nil
+	}
+	rounded := int(math.Round(*value))
+	return &rounded
+}
+
+// recordedMeasure returns nil for a measure none of the points recorded
+func recordedMeasure(values []*float64) []*float64 {
+	for _, value := range values {
+		if value != nil {
+			return values
+		}
+	}
+	return nil
+}
+
+func formatExportTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
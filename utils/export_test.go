@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exportPoints() []ExportPoint {
+	start := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	return []ExportPoint{
+		{
+			TimedPoint: TimedPoint{Timestamp: start, Latitude: 47.5, Longitude: 19.04, Altitude: floatPtr(120)},
+			HeartRate:  floatPtr(128), Cadence: floatPtr(84.6), Power: floatPtr(210), Temperature: floatPtr(-2.5), Accuracy: floatPtr(4.2),
+		},
+		{
+			TimedPoint: TimedPoint{Timestamp: start.Add(10 * time.Second), Latitude: 47.501, Longitude: 19.041},
+			Speed:      floatPtr(3.2),
+		},
+		{
+			TimedPoint: TimedPoint{Timestamp: start.Add(2 * time.Hour), Latitude: 47.6, Longitude: 19.1, Altitude: floatPtr(300)},
+			HeartRate:  floatPtr(140),
+		},
+	}
+}
+
+func TestBuildSessionGPX(t *testing.T) {
+	body, err := BuildSessionGPX("Morning ride", "Around the hills", exportPoints(), []int{0, 0, 1})
+	assert.NoError(t, err)
+
+	gpx := string(body)
+	assert.Contains(t, gpx, `xmlns="http://www.topografix.com/GPX/1/1"`)
+	assert.Contains(t, gpx, `xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v2"`)
+	assert.Contains(t, gpx, `<gpxtpx:hr>128</gpxtpx:hr>`)
+	assert.Contains(t, gpx, `<gpxtpx:cad>85</gpxtpx:cad>`)
+	assert.Contains(t, gpx, `<gpxpx:PowerInWatts>210</gpxpx:PowerInWatts>`)
+	assert.Contains(t, gpx, `<vibe:accuracy>4.2</vibe:accuracy>`)
+	assert.Equal(t, 2, strings.Count(gpx, "<trkseg>"))
+
+	t.Run("round trip", func(t *testing.T) {
+		parsed, err := ParseGPX(bytes.NewReader(body))
+		assert.NoError(t, err)
+		assert.Equal(t, "Morning ride", parsed.TrackName)
+		assert.Len(t, parsed.TrackPoints, 3)
+
+		first := parsed.TrackPoints[0]
+		assert.Equal(t, time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC), first.Time)
+		assert.Equal(t, floatPtr(120), first.Altitude)
+		assert.Equal(t, floatPtr(128), first.HeartRate)
+		assert.Equal(t, floatPtr(85), first.Cadence)
+		assert.Equal(t, floatPtr(210), first.Power)
+		assert.Equal(t, floatPtr(-2.5), first.Temperature)
+		assert.Equal(t, floatPtr(4.2), first.Accuracy)
+		assert.Nil(t, first.Speed)
+
+		second := parsed.TrackPoints[1]
+		assert.Equal(t, floatPtr(3.2), second.Speed)
+		assert.Nil(t, second.HeartRate)
+		assert.Nil(t, second.Accuracy)
+	})
+}
+
+func TestParseGPXExtensions(t *testing.T) {
+	gpx := `<?xml version="1.0"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1"
+  xmlns:ns3="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+  <trk><trkseg>
+    <trkpt lat="47.5" lon="19.04"><time>2026-05-01T08:00:00Z</time>
+      <extensions><power>250</power><ns3:TrackPointExtension><ns3:hr>150</ns3:hr><ns3:cad>90</ns3:cad></ns3:TrackPointExtension></extensions>
+    </trkpt>
+  </trkseg></trk>
+</gpx>`
+
+	parsed, err := ParseGPX(strings.NewReader(gpx))
+	assert.NoError(t, err)
+	assert.Len(t, parsed.TrackPoints, 1)
+	assert.Equal(t, floatPtr(150), parsed.TrackPoints[0].HeartRate)
+	assert.Equal(t, floatPtr(90), parsed.TrackPoints[0].Cadence)
+	assert.Equal(t, floatPtr(250), parsed.TrackPoints[0].Power)
+}
+
+func TestBuildSessionGeoJSON(t *testing.T) {
+	collection := BuildSessionGeoJSON("morning", "Morning ride", exportPoints(), []int{0, 0, 1})
+	assert.Len(t, collection.Features, 2)
+
+	first := collection.Features[0]
+	assert.Equal(t, "LineString", first.Geometry.Type)
+	assert.Equal(t, [][]float64{{19.04, 47.5, 120}, {19.041, 47.501}}, first.Geometry.Coordinates)
+	assert.Equal(t, []string{"2026-05-01T08:00:00Z", "2026-05-01T08:00:10Z"}, first.Measures.Time)
+	assert.Equal(t, []*float64{floatPtr(128), nil}, first.Measures.HeartRate)
+	assert.Equal(t, []*float64{nil, floatPtr(3.2)}, first.Measures.Speed)
+
+	second := collection.Features[1]
+	assert.Equal(t, "Point", second.Geometry.Type)
+	assert.Equal(t, 1, second.Properties.Segment)
+	assert.Nil(t, second.Measures.Power)
+
+	body, err := json.Marshal(second)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"Feature","geometry":{"type":"Point","coordinates":[19.1,47.6,300]},
+		"properties":{"session":"morning","title":"Morning ride","segment":1,"start_time":"2026-05-01T10:00:00Z","end_time":"2026-05-01T10:00:00Z"},
+		"measures":{"time":["2026-05-01T10:00:00Z"],"heart_rate":[140]}}`, string(body))
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"vibe-tracker/constants"
+)
+
+// SignedFileURL returns a download URL for a file of a record that is valid until expires. The
+// signature covers the collection, record, file name and expiry, so none of them can be changed.
+func SignedFileURL(secret, collection, recordID, filename string, expires time.Time) string {
+	expiresAt := strconv.FormatInt(expires.Unix(), 10)
+	query := url.Values{
+		constants.SignedFileExpiresParam: {expiresAt},
+		constants.SignedFileSigParam:     {fileSignature(secret, collection, recordID, filename, expiresAt)},
+	}
+
+	return constants.APIPrefix + constants.SignedFilesPath + "/" + url.PathEscape(collection) + "/" +
+		url.PathEscape(recordID) + "/" + url.PathEscape(filename) + "?" + query.Encode()
+}
+
+// VerifyFileSignature checks the expiry and signature of a signed download URL
+func VerifyFileSignature(secret, collection, recordID, filename, expiresAt, signature string, now time.Time) error {
+	expires, err := strconv.ParseInt(expiresAt, 10, 64)
+	if err != nil {
+		return errors.New("malformed download link")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(fileSignature(secret, collection, recordID, filename, expiresAt))) {
+		return errors.New("invalid download link signature")
+	}
+
+	if now.Unix() >= expires {
+		return errors.New("download link expired")
+	}
+
+	return nil
+}
+
+func fileSignature(secret, collection, recordID, filename, expiresAt string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("file:" + collection + "/" + recordID + "/" + filename + ":" + expiresAt))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignedFileURL(t *testing.T) {
+	secret := "test-secret"
+	now := time.Unix(1758000000, 0)
+	link := SignedFileURL(secret, "waypoints", "abc123", "photo 1.jpg", now.Add(15*time.Minute))
+
+	path, rawQuery, _ := strings.Cut(link, "?")
+	assert.Equal(t, "/api/signed-files/waypoints/abc123/photo%201.jpg", path)
+
+	query, err := url.ParseQuery(rawQuery)
+	assert.NoError(t, err)
+	expires, signature := query.Get("expires"), query.Get("signature")
+
+	assert.NoError(t, VerifyFileSignature(secret, "waypoints", "abc123", "photo 1.jpg", expires, signature, now))
+
+	t.Run("expired", func(t *testing.T) {
+		err := VerifyFileSignature(secret, "waypoints", "abc123", "photo 1.jpg", expires, signature, now.Add(15*time.Minute))
+		assert.Error(t, err)
+	})
+
+	t.Run("other file", func(t *testing.T) {
+		assert.Error(t, VerifyFileSignature(secret, "waypoints", "abc123", "photo 2.jpg", expires, signature, now))
+		assert.Error(t, VerifyFileSignature(secret, "waypoints", "abc124", "photo 1.jpg", expires, signature, now))
+		assert.Error(t, VerifyFileSignature(secret, "sessions", "abc123", "photo 1.jpg", expires, signature, now))
+	})
+
+	t.Run("extended expiry", func(t *testing.T) {
+		extended := strconv.FormatInt(now.Add(24*time.Hour).Unix(), 10)
+		assert.Error(t, VerifyFileSignature(secret, "waypoints", "abc123", "photo 1.jpg", extended, signature, now))
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		assert.Error(t, VerifyFileSignature("other-secret", "waypoints", "abc123", "photo 1.jpg", expires, signature, now))
+	})
+
+	t.Run("malformed expiry", func(t *testing.T) {
+		assert.Error(t, VerifyFileSignature(secret, "waypoints", "abc123", "photo 1.jpg", "soon", signature, now))
+	})
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"time"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/models"
+)
+
+// GapThresholds configures when consecutive points are split into separate track segments.
+// A zero value disables the respective check.
+type GapThresholds struct {
+	MaxInterval time.Duration
+	MaxDistance float64 // Meters
+}
+
+// IsGap reports whether two consecutive points are too far apart to be connected
+func (g GapThresholds) IsGap(from, to TimedPoint) bool {
+	if g.MaxInterval > 0 && to.Timestamp.Sub(from.Timestamp) > g.MaxInterval {
+		return true
+	}
+	if g.MaxDistance > 0 && HaversineDistance(from.Latitude, from.Longitude, to.Latitude, to.Longitude) > g.MaxDistance {
+		return true
+	}
+	return false
+}
+
+// ImpliedMotion returns the speed in m/s and the bearing in degrees of travel between two
+// consecutive points, for points that report neither. It reports false for points a gap apart or
+// at the same time; the bearing is nil when they are too close for a meaningful direction.
+func (g GapThresholds) ImpliedMotion(from, to TimedPoint) (float64, *float64, bool) {
+	interval := to.Timestamp.Sub(from.Timestamp).Seconds()
+	if interval <= 0 || g.IsGap(from, to) {
+		return 0, nil, false
+	}
+
+	distance := HaversineDistance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	if distance < constants.MinBearingDistance {
+		return distance / interval, nil, true
+	}
+	bearing := InitialBearing(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	return distance / interval, &bearing, true
+}
+
+// SegmentTrack assigns a segment index to every point (ordered by timestamp), starting a new
+// segment after each gap, and returns the indexes together with the detected gaps
+func SegmentTrack(points []TimedPoint, thresholds GapThresholds) ([]int, []models.TrackGap) {
+	segments := make([]int, len(points))
+	gaps := []models.TrackGap{}
+
+	for i := 1; i < len(points); i++ {
+		from, to := points[i-1], points[i]
+		segments[i] = segments[i-1]
+		if !thresholds.IsGap(from, to) {
+			continue
+		}
+
+		segments[i]++
+		gaps = append(gaps, models.TrackGap{
+			StartTime: from.Timestamp.Unix(),
+			EndTime:   to.Timestamp.Unix(),
+			Duration:  int64(to.Timestamp.Sub(from.Timestamp).Seconds()),
+			Distance:  HaversineDistance(from.Latitude, from.Longitude, to.Latitude, to.Longitude),
+			From:      []float64{from.Longitude, from.Latitude},
+			To:        []float64{to.Longitude, to.Latitude},
+		})
+	}
+
+	return segments, gaps
+}
+
+// ComputeTrackStats summarizes a track (ordered by timestamp). Distance and tracked time are only
+// accumulated within segments, so gaps do not count as straight-line travel. Climbs are detected
+// on points with an altitude, sunrise and sunset are those of the first point's place and day.
+func ComputeTrackStats(points []TimedPoint, thresholds GapThresholds) *models.SessionStatsResponse {
+	segments, gaps := SegmentTrack(points, thresholds)
+
+	stats := &models.SessionStatsResponse{
+		PointCount: len(points),
+		Gaps:       gaps,
+		Climbs:     DetectClimbs(points),
+	}
+	if len(points) == 0 {
+		return stats
+	}
+
+	first, last := points[0], points[len(points)-1]
+	stats.SegmentCount = segments[len(segments)-1] + 1
+	stats.StartTime = first.Timestamp.Unix()
+	stats.EndTime = last.Timestamp.Unix()
+	stats.Duration = int64(last.Timestamp.Sub(first.Timestamp).Seconds())
+
+	daylight := SunTimesAt(first.Latitude, first.Longitude, first.Timestamp)
+	stats.Daylight = &daylight
+
+	for i := 1; i < len(points); i++ {
+		if segments[i] != segments[i-1] {
+			continue
+		}
+		from, to := points[i-1], points[i]
+		stats.Distance += HaversineDistance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+		stats.TrackedDuration += int64(to.Timestamp.Sub(from.Timestamp).Seconds())
+	}
+
+	for _, gap := range gaps {
+		stats.GapDuration += gap.Duration
+	}
+
+	return stats
+}
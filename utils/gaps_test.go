@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentTrack(t *testing.T) {
+	start := time.Unix(1758000000, 0)
+	thresholds := GapThresholds{MaxInterval: 10 * time.Minute, MaxDistance: 5000}
+
+	// Roughly 111 m north per point every minute, with a tracker outage and a flight
+	points := []TimedPoint{
+		{Timestamp: start, Latitude: 47.000, Longitude: 19.0},
+		{Timestamp: start.Add(time.Minute), Latitude: 47.001, Longitude: 19.0},
+		{Timestamp: start.Add(31 * time.Minute), Latitude: 47.002, Longitude: 19.0},
+		{Timestamp: start.Add(32 * time.Minute), Latitude: 48.002, Longitude: 19.0},
+		{Timestamp: start.Add(33 * time.Minute), Latitude: 48.003, Longitude: 19.0},
+	}
+
+	t.Run("splits at time and distance gaps", func(t *testing.T) {
+		segments, gaps := SegmentTrack(points, thresholds)
+		assert.Equal(t, []int{0, 0, 1, 2, 2}, segments)
+		assert.Len(t, gaps, 2)
+		assert.Equal(t, int64(1800), gaps[0].Duration)
+		assert.Equal(t, start.Add(time.Minute).Unix(), gaps[0].StartTime)
+		assert.Equal(t, []float64{19.0, 47.001}, gaps[0].From)
+		assert.InDelta(t, 111195, gaps[1].Distance, 100)
+	})
+
+	t.Run("disabled thresholds keep one segment", func(t *testing.T) {
+		segments, gaps := SegmentTrack(points, GapThresholds{})
+		assert.Equal(t, []int{0, 0, 0, 0, 0}, segments)
+		assert.Empty(t, gaps)
+	})
+}
+
+func TestGapThresholds_ImpliedMotion(t *testing.T) {
+	start := time.Date(2025, 9, 10, 8, 0, 0, 0, time.UTC)
+	from := TimedPoint{Timestamp: start, Latitude: 47.0, Longitude: 19.0}
+	thresholds := GapThresholds{MaxInterval: 10 * time.Minute, MaxDistance: 5000}
+
+	t.Run("north at 18.5 m/s", func(t *testing.T) {
+		speed, bearing, ok := thresholds.ImpliedMotion(from, TimedPoint{Timestamp: start.Add(time.Minute), Latitude: 47.01, Longitude: 19.0})
+		assert.True(t, ok)
+		assert.InDelta(t, 18.53, speed, 0.01)
+		if assert.NotNil(t, bearing) {
+			assert.Zero(t, *bearing)
+		}
+	})
+
+	t.Run("east", func(t *testing.T) {
+		_, bearing, ok := thresholds.ImpliedMotion(from, TimedPoint{Timestamp: start.Add(time.Minute), Latitude: 47.0, Longitude: 19.01})
+		assert.True(t, ok)
+		if assert.NotNil(t, bearing) {
+			assert.InDelta(t, 90, *bearing, 0.1)
+		}
+	})
+
+	t.Run("too close for a bearing", func(t *testing.T) {
+		speed, bearing, ok := thresholds.ImpliedMotion(from, TimedPoint{Timestamp: start.Add(10 * time.Second), Latitude: 47.00001, Longitude: 19.0})
+		assert.True(t, ok)
+		assert.InDelta(t, 0.11, speed, 0.01)
+		assert.Nil(t, bearing)
+	})
+
+	t.Run("gap", func(t *testing.T) {
+		_, _, ok := thresholds.ImpliedMotion(from, TimedPoint{Timestamp: start.Add(time.Hour), Latitude: 47.01, Longitude: 19.0})
+		assert.False(t, ok)
+	})
+
+	t.Run("same time", func(t *testing.T) {
+		_, _, ok := thresholds.ImpliedMotion(from, TimedPoint{Timestamp: start, Latitude: 47.01, Longitude: 19.0})
+		assert.False(t, ok)
+	})
+}
+
+func TestComputeTrackStats(t *testing.T) {
+	start := time.Unix(1758000000, 0)
+	thresholds := GapThresholds{MaxInterval: 10 * time.Minute}
+
+	t.Run("excludes gaps from distance and tracked time", func(t *testing.T) {
+		points := []TimedPoint{
+			{Timestamp: start, Latitude: 47.000, Longitude: 19.0},
+			{Timestamp: start.Add(time.Minute), Latitude: 47.001, Longitude: 19.0},
+			{Timestamp: start.Add(61 * time.Minute), Latitude: 47.101, Longitude: 19.0},
+			{Timestamp: start.Add(62 * time.Minute), Latitude: 47.102, Longitude: 19.0},
+		}
+
+		stats := ComputeTrackStats(points, thresholds)
+		assert.Equal(t, 4, stats.PointCount)
+		assert.Equal(t, 2, stats.SegmentCount)
+		assert.Equal(t, int64(62*60), stats.Duration)
+		assert.Equal(t, int64(120), stats.TrackedDuration)
+		assert.Equal(t, int64(3600), stats.GapDuration)
+		assert.InDelta(t, 222.4, stats.Distance, 1)
+		assert.Len(t, stats.Gaps, 1)
+	})
+
+	t.Run("empty track", func(t *testing.T) {
+		stats := ComputeTrackStats(nil, thresholds)
+		assert.Equal(t, 0, stats.PointCount)
+		assert.Equal(t, 0, stats.SegmentCount)
+		assert.NotNil(t, stats.Gaps)
+	})
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EarthRadiusMeters is the mean Earth radius used for distance calculations
+const EarthRadiusMeters = 6371000.0
+
+// HaversineDistance returns the great-circle distance in meters between two coordinates
+func HaversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	deltaPhi := (lat2 - lat1) * math.Pi / 180
+	deltaLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(deltaPhi/2)*math.Sin(deltaPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(deltaLambda/2)*math.Sin(deltaLambda/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return EarthRadiusMeters * c
+}
+
+// ProjectLocal returns the position of a coordinate in meters east (x) and north (y) of a
+// reference coordinate, on an equirectangular projection around the reference. East-west
+// distances shrink with the cosine of the latitude, unlike raw degree differences; the projection
+// is accurate to well below a percent within some tens of kilometers of the reference.
+func ProjectLocal(lat, lon, refLat, refLon float64) (float64, float64) {
+	deltaLon := math.Remainder(lon-refLon, 360) // The short way across the antimeridian
+	meanLat := (lat + refLat) / 2 * math.Pi / 180
+
+	x := deltaLon * math.Pi / 180 * math.Cos(meanLat) * EarthRadiusMeters
+	y := (lat - refLat) * math.Pi / 180 * EarthRadiusMeters
+	return x, y
+}
+
+// SegmentDistance returns the distance in meters from a coordinate to the closest point of the
+// segment between two coordinates, measured on the local projection of the segment start
+func SegmentDistance(lat, lon, lat1, lon1, lat2, lon2 float64) float64 {
+	px, py := ProjectLocal(lat, lon, lat1, lon1)
+	ex, ey := ProjectLocal(lat2, lon2, lat1, lon1)
+
+	lengthSq := ex*ex + ey*ey
+	if lengthSq == 0 {
+		return math.Hypot(px, py)
+	}
+
+	// Position of the closest point along the segment, clamped to its ends
+	t := math.Max(0, math.Min(1, (px*ex+py*ey)/lengthSq))
+	return math.Hypot(px-t*ex, py-t*ey)
+}
+
+// InitialBearing returns the direction in degrees clockwise from true north, in [0, 360), in which
+// the great circle from the first coordinate to the second starts
+func InitialBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	deltaLambda := (lon2 - lon1) * math.Pi / 180
+
+	y := math.Sin(deltaLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(deltaLambda)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// BoundingBox is a geographic rectangle in degrees
+type BoundingBox struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// Contains reports whether a position lies inside the bounding box, edges included
+func (b *BoundingBox) Contains(lat, lon float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// Center returns the latitude and longitude of the middle of the bounding box
+func (b *BoundingBox) Center() (float64, float64) {
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLon + b.MaxLon) / 2
+}
+
+// ParseBoundingBox parses a "minLon,minLat,maxLon,maxLat" string as used by the bbox query parameter
+func ParseBoundingBox(value string) (*BoundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("bbox must have 4 comma-separated values: minLon,minLat,maxLon,maxLat")
+	}
+
+	values := make([]float64, 4)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox value %q: %v", part, err)
+		}
+		// NaN passes every range check below, and infinities are no coordinates
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("invalid bbox value %q: not a finite number", part)
+		}
+		values[i] = v
+	}
+
+	bbox := &BoundingBox{MinLon: values[0], MinLat: values[1], MaxLon: values[2], MaxLat: values[3]}
+
+	if bbox.MinLat < -90 || bbox.MaxLat > 90 || bbox.MinLon < -180 || bbox.MaxLon > 180 {
+		return nil, fmt.Errorf("bbox coordinates are out of range")
+	}
+	if bbox.MinLon > bbox.MaxLon || bbox.MinLat > bbox.MaxLat {
+		return nil, fmt.Errorf("bbox minimum values must not exceed maximum values")
+	}
+
+	return bbox, nil
+}
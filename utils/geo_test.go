@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHaversineDistance(t *testing.T) {
+	tests := []struct {
+		name      string
+		lat1      float64
+		lon1      float64
+		lat2      float64
+		lon2      float64
+		expected  float64
+		tolerance float64
+	}{
+		{
+			name:      "Same point",
+			lat1:      47.4979,
+			lon1:      19.0402,
+			lat2:      47.4979,
+			lon2:      19.0402,
+			expected:  0,
+			tolerance: 0.001,
+		},
+		{
+			name:      "One degree of latitude",
+			lat1:      0,
+			lon1:      0,
+			lat2:      1,
+			lon2:      0,
+			expected:  111195,
+			tolerance: 1,
+		},
+		{
+			name:      "Budapest to Vienna",
+			lat1:      47.4979,
+			lon1:      19.0402,
+			lat2:      48.2082,
+			lon2:      16.3738,
+			expected:  214500,
+			tolerance: 1000,
+		},
+		{
+			name:      "Across the antimeridian",
+			lat1:      0,
+			lon1:      179.9,
+			lat2:      0,
+			lon2:      -179.9,
+			expected:  22239,
+			tolerance: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := HaversineDistance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			assert.InDelta(t, tt.expected, actual, tt.tolerance)
+			// Distance must be symmetric
+			assert.InDelta(t, actual, HaversineDistance(tt.lat2, tt.lon2, tt.lat1, tt.lon1), 0.001)
+		})
+	}
+}
+
+func TestProjectLocal(t *testing.T) {
+	// A degree of longitude is half as long at 60 degrees north as at the equator
+	x, y := ProjectLocal(0, 1, 0, 0)
+	assert.InDelta(t, 111195, x, 1)
+	assert.InDelta(t, 0, y, 0.001)
+
+	x, y = ProjectLocal(60, 1, 60, 0)
+	assert.InDelta(t, 55597, x, 1)
+	assert.InDelta(t, 0, y, 0.001)
+
+	// Within some kilometers the projected distance matches the great-circle one
+	x, y = ProjectLocal(47.5, 19, 47.4979, 19.0402)
+	assert.Less(t, x, 0.0)
+	assert.Greater(t, y, 0.0)
+	assert.InEpsilon(t, HaversineDistance(47.4979, 19.0402, 47.5, 19), math.Hypot(x, y), 0.001)
+
+	// West of the antimeridian is a short step from its east
+	x, _ = ProjectLocal(0, -179.9, 0, 179.9)
+	assert.InDelta(t, 22239, x, 10)
+}
+
+func TestSegmentDistance(t *testing.T) {
+	tests := []struct {
+		name      string
+		lat, lon  float64
+		lat1      float64
+		lon1      float64
+		lat2      float64
+		lon2      float64
+		expected  float64
+		tolerance float64
+	}{
+		{
+			name: "North of an east-west segment",
+			lat:  0.01, lon: 0.5,
+			lat1: 0, lon1: 0, lat2: 0, lon2: 1,
+			expected: 1112, tolerance: 1,
+		},
+		{
+			name: "East of a north-south segment at 70 degrees north",
+			lat:  70.005, lon: 25.01,
+			lat1: 70, lon1: 25, lat2: 70.01, lon2: 25,
+			expected: 380, tolerance: 1,
+		},
+		{
+			name: "North of an east-west segment at 70 degrees north",
+			lat:  70.01, lon: 25.005,
+			lat1: 70, lon1: 25, lat2: 70, lon2: 25.01,
+			expected: 1112, tolerance: 1,
+		},
+		{
+			name: "Beyond the end of the segment",
+			lat:  60, lon: 1.01,
+			lat1: 60, lon1: 0.99, lat2: 60, lon2: 1,
+			expected: 556, tolerance: 1,
+		},
+		{
+			name: "Segment of a single point",
+			lat:  1, lon: 0,
+			lat1: 0, lon1: 0, lat2: 0, lon2: 0,
+			expected: 111195, tolerance: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := SegmentDistance(tt.lat, tt.lon, tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			assert.InDelta(t, tt.expected, actual, tt.tolerance)
+		})
+	}
+}
+
+func TestInitialBearing(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		expected               float64
+	}{
+		{"north", 47, 19, 47.1, 19, 0},
+		{"east on the equator", 0, 0, 0, 1, 90},
+		{"south", 47, 19, 46.9, 19, 180},
+		{"west on the equator", 0, 1, 0, 0, 270},
+		{"across the antimeridian", 0, 179.9, 0, -179.9, 90},
+		{"Budapest to Vienna", 47.4979, 19.0402, 48.2082, 16.3738, 292.6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, InitialBearing(tt.lat1, tt.lon1, tt.lat2, tt.lon2), 0.1)
+		})
+	}
+}
+
+func TestParseBoundingBox(t *testing.T) {
+	t.Run("Valid bounding box", func(t *testing.T) {
+		bbox, err := ParseBoundingBox("18.9, 47.4,19.2,47.6")
+		assert.NoError(t, err)
+		assert.Equal(t, &BoundingBox{MinLon: 18.9, MinLat: 47.4, MaxLon: 19.2, MaxLat: 47.6}, bbox)
+	})
+
+	t.Run("Whole world", func(t *testing.T) {
+		bbox, err := ParseBoundingBox("-180,-90,180,90")
+		assert.NoError(t, err)
+		assert.Equal(t, -180.0, bbox.MinLon)
+		assert.Equal(t, 90.0, bbox.MaxLat)
+	})
+
+	invalid := map[string]string{
+		"Too few values":    "1,2,3",
+		"Not a number":      "a,2,3,4",
+		"Latitude range":    "0,-91,1,1",
+		"Longitude range":   "0,0,181,1",
+		"Min exceeds max":   "19.2,47.4,18.9,47.6",
+		"Empty string":      "",
+		"Too many values":   "1,2,3,4,5",
+		"Inverted latitude": "18.9,47.6,19.2,47.4",
+		"Not a coordinate":  "NaN,NaN,NaN,NaN",
+		"Infinite":          "-Inf,-90,+Inf,90",
+	}
+	for name, value := range invalid {
+		t.Run(name, func(t *testing.T) {
+			bbox, err := ParseBoundingBox(value)
+			assert.Error(t, err)
+			assert.Nil(t, bbox)
+		})
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	bbox := &BoundingBox{MinLon: 18.9, MinLat: 47.4, MaxLon: 19.2, MaxLat: 47.6}
+
+	assert.True(t, bbox.Contains(47.5, 19.0))
+	assert.True(t, bbox.Contains(47.4, 19.2)) // Edges are inside
+	assert.False(t, bbox.Contains(47.7, 19.0))
+	assert.False(t, bbox.Contains(47.5, 18.8))
+
+	lat, lon := bbox.Center()
+	assert.InDelta(t, 47.5, lat, 1e-9)
+	assert.InDelta(t, 19.05, lon, 1e-9)
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/models"
+)
+
+// Place is the country and region a position lies in; empty outside any country, e.g. at sea
+type Place struct {
+	CountryCode string // ISO 3166-1 alpha-2 code
+	Country     string
+	Region      string
+}
+
+// placeRegionKeys lists the address fields naming a first-level subdivision, in match order
+var placeRegionKeys = []string{"state", "province", "region", "state_district", "county"}
+
+// ParseReverseGeocodeResponse extracts the country and region from a JSON reverse geocoding
+// response. Nominatim style responses with an "address" object are understood, as are flat
+// objects with the same fields; an "error" response (no country here) yields an empty place.
+func ParseReverseGeocodeResponse(body []byte) (Place, error) {
+	var data map[string]any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return Place{}, fmt.Errorf("invalid reverse geocoding response: %w", err)
+	}
+
+	if _, failed := data["error"]; failed {
+		return Place{}, nil
+	}
+
+	address, ok := data["address"].(map[string]any)
+	if !ok {
+		address = data
+	}
+
+	var place Place
+	if code, ok := address["country_code"].(string); ok && len(code) == 2 {
+		place.CountryCode = strings.ToUpper(code)
+	}
+	if place.CountryCode == "" {
+		return Place{}, nil
+	}
+
+	place.Country, _ = address["country"].(string)
+	if place.Country == "" {
+		place.Country = place.CountryCode
+	}
+	for _, key := range placeRegionKeys {
+		if region, ok := address[key].(string); ok && region != "" {
+			place.Region = region
+			break
+		}
+	}
+
+	return place, nil
+}
+
+// GeocodeCell returns the key of the grid cell a position lies in. Positions in one cell are
+// assumed to share their country and region, so each cell is looked up once.
+func GeocodeCell(latitude, longitude float64) string {
+	return fmt.Sprintf("%d:%d",
+		int(math.Floor(latitude/constants.GeocodeCellSize)), int(math.Floor(longitude/constants.GeocodeCellSize)))
+}
+
+// GeocodeCellCenter returns the center of the grid cell a position lies in, the position looked up for the cell
+func GeocodeCellCenter(latitude, longitude float64) (float64, float64) {
+	size := constants.GeocodeCellSize
+	return (math.Floor(latitude/size) + 0.5) * size, (math.Floor(longitude/size) + 0.5) * size
+}
+
+// PlacedPoint is a location with the place it was geocoded to
+type PlacedPoint struct {
+	Timestamp time.Time
+	Latitude  float64
+	Longitude float64
+	Place     Place
+}
+
+// SummarizeCountries computes the visited countries and regions from points ordered by timestamp.
+// A visit to a country lasts until a point in another country; points outside any country do not
+// end a visit. Countries and their regions are ordered by their first visit.
+func SummarizeCountries(points []PlacedPoint) []models.CountryStats {
+	stats := []models.CountryStats{}
+	index := map[string]int{}
+	regions := map[string]map[string]int{}
+	days := map[string]map[string]bool{}
+	current := ""
+
+	for _, p := range points {
+		code := p.Place.CountryCode
+		if code == "" {
+			continue
+		}
+
+		i, seen := index[code]
+		if !seen {
+			i = len(stats)
+			index[code] = i
+			stats = append(stats, models.CountryStats{
+				CountryCode: code,
+				Country:     p.Place.Country,
+				FirstVisit:  p.Timestamp,
+				Visits:      []models.CountryVisit{},
+				Regions:     []models.RegionStats{},
+			})
+			regions[code] = map[string]int{}
+			days[code] = map[string]bool{}
+		}
+
+		country := &stats[i]
+		country.LastVisit = p.Timestamp
+		days[code][p.Timestamp.UTC().Format(constants.TimelineDateFormat)] = true
+
+		if code != current {
+			country.Visits = append(country.Visits, models.CountryVisit{EntryAt: p.Timestamp})
+			current = code
+		}
+		country.Visits[len(country.Visits)-1].ExitAt = p.Timestamp
+
+		if region := p.Place.Region; region != "" {
+			r, seen := regions[code][region]
+			if !seen {
+				r = len(country.Regions)
+				regions[code][region] = r
+				country.Regions = append(country.Regions, models.RegionStats{Name: region, FirstVisit: p.Timestamp})
+			}
+			country.Regions[r].LastVisit = p.Timestamp
+		}
+	}
+
+	for i := range stats {
+		stats[i].Days = len(days[stats[i].CountryCode])
+	}
+
+	return stats
+}
+
+// CountryFeatures returns the visited countries as GeoJSON features, each a point at the center of
+// the country's points with their bounding box
+func CountryFeatures(points []PlacedPoint, stats []models.CountryStats) []models.CountryFeature {
+	type extent struct {
+		latitude, longitude float64
+		count               int
+		bbox                []float64 // minLon, minLat, maxLon, maxLat
+	}
+
+	extents := map[string]*extent{}
+	for _, p := range points {
+		e, ok := extents[p.Place.CountryCode]
+		if !ok {
+			e = &extent{bbox: []float64{p.Longitude, p.Latitude, p.Longitude, p.Latitude}}
+			extents[p.Place.CountryCode] = e
+		}
+		e.latitude += p.Latitude
+		e.longitude += p.Longitude
+		e.count++
+		e.bbox[0] = math.Min(e.bbox[0], p.Longitude)
+		e.bbox[1] = math.Min(e.bbox[1], p.Latitude)
+		e.bbox[2] = math.Max(e.bbox[2], p.Longitude)
+		e.bbox[3] = math.Max(e.bbox[3], p.Latitude)
+	}
+
+	features := []models.CountryFeature{}
+	for _, country := range stats {
+		e, ok := extents[country.CountryCode]
+		if !ok {
+			continue
+		}
+
+		features = append(features, models.CountryFeature{
+			Type: "Feature",
+			BBox: e.bbox,
+			Geometry: models.Geometry{
+				Type: "Point",
+				Coordinates: models.Coordinates{
+					RoundTo(e.longitude/float64(e.count), constants.CoordinatePrecision),
+					RoundTo(e.latitude/float64(e.count), constants.CoordinatePrecision),
+				},
+			},
+			Properties: models.CountryFeatureProperties{
+				CountryCode: country.CountryCode,
+				Country:     country.Country,
+				FirstVisit:  country.FirstVisit,
+				LastVisit:   country.LastVisit,
+				Days:        country.Days,
+				VisitCount:  len(country.Visits),
+			},
+		})
+	}
+	return features
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReverseGeocodeResponse(t *testing.T) {
+	t.Run("nominatim", func(t *testing.T) {
+		place, err := ParseReverseGeocodeResponse([]byte(`{"place_id":1,"address":{"county":"Pest","state":"Central Hungary","country":"Magyarország","country_code":"hu"}}`))
+		assert.NoError(t, err)
+		assert.Equal(t, Place{CountryCode: "HU", Country: "Magyarország", Region: "Central Hungary"}, place)
+	})
+
+	t.Run("flat response", func(t *testing.T) {
+		place, err := ParseReverseGeocodeResponse([]byte(`{"country_code":"AT","region":"Tyrol"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, Place{CountryCode: "AT", Country: "AT", Region: "Tyrol"}, place)
+	})
+
+	t.Run("no country", func(t *testing.T) {
+		for _, body := range []string{`{"error":"Unable to geocode"}`, `{"address":{"body_of_water":"Adriatic Sea"}}`} {
+			place, err := ParseReverseGeocodeResponse([]byte(body))
+			assert.NoError(t, err)
+			assert.Equal(t, Place{}, place)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseReverseGeocodeResponse([]byte(`<html>`))
+		assert.Error(t, err)
+	})
+}
+
+func TestGeocodeCell(t *testing.T) {
+	assert.Equal(t, GeocodeCell(47.501, 19.041), GeocodeCell(47.549, 19.049))
+	assert.NotEqual(t, GeocodeCell(47.501, 19.041), GeocodeCell(47.551, 19.041))
+	assert.NotEqual(t, GeocodeCell(0.01, 0.01), GeocodeCell(-0.01, -0.01))
+
+	latitude, longitude := GeocodeCellCenter(47.501, 19.041)
+	assert.InDelta(t, 47.525, latitude, 1e-9)
+	assert.InDelta(t, 19.025, longitude, 1e-9)
+}
+
+func TestSummarizeCountries(t *testing.T) {
+	start := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	hu := Place{CountryCode: "HU", Country: "Hungary", Region: "Budapest"}
+	huWest := Place{CountryCode: "HU", Country: "Hungary", Region: "Győr-Moson-Sopron"}
+	at := Place{CountryCode: "AT", Country: "Austria", Region: "Vienna"}
+
+	var points []PlacedPoint
+	for i, place := range []Place{hu, huWest, {}, at, at, {}, huWest, hu} {
+		points = append(points, PlacedPoint{Timestamp: start.Add(time.Duration(i) * 12 * time.Hour), Place: place})
+	}
+
+	stats := SummarizeCountries(points)
+	if !assert.Len(t, stats, 2) {
+		return
+	}
+
+	hungary, austria := stats[0], stats[1]
+	assert.Equal(t, "HU", hungary.CountryCode)
+	assert.Equal(t, start, hungary.FirstVisit)
+	assert.Equal(t, points[7].Timestamp, hungary.LastVisit)
+	assert.Equal(t, 2, hungary.Days)
+	if assert.Len(t, hungary.Visits, 2) {
+		assert.Equal(t, points[1].Timestamp, hungary.Visits[0].ExitAt)
+		assert.Equal(t, points[6].Timestamp, hungary.Visits[1].EntryAt)
+	}
+	if assert.Len(t, hungary.Regions, 2) {
+		assert.Equal(t, "Budapest", hungary.Regions[0].Name)
+		assert.Equal(t, points[7].Timestamp, hungary.Regions[0].LastVisit)
+	}
+
+	assert.Equal(t, "AT", austria.CountryCode)
+	assert.Len(t, austria.Visits, 1)
+	assert.Equal(t, 2, austria.Days)
+
+	assert.Empty(t, SummarizeCountries(nil))
+}
+
+func TestCountryFeatures(t *testing.T) {
+	start := time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)
+	hu := Place{CountryCode: "HU", Country: "Hungary"}
+	points := []PlacedPoint{
+		{Timestamp: start, Latitude: 47.5, Longitude: 19.0, Place: hu},
+		{Timestamp: start.Add(time.Hour), Latitude: 47.7, Longitude: 17.6, Place: hu},
+		{Timestamp: start.Add(2 * time.Hour), Latitude: 48.2, Longitude: 16.4, Place: Place{CountryCode: "AT", Country: "Austria"}},
+	}
+
+	features := CountryFeatures(points, SummarizeCountries(points))
+	if !assert.Len(t, features, 2) {
+		return
+	}
+
+	hungary := features[0]
+	assert.Equal(t, "HU", hungary.Properties.CountryCode)
+	assert.Equal(t, 1, hungary.Properties.VisitCount)
+	assert.Equal(t, "Point", hungary.Geometry.Type)
+	assert.InDeltaSlice(t, []float64{18.3, 47.6}, []float64(hungary.Geometry.Coordinates), 1e-9)
+	assert.Equal(t, []float64{17.6, 47.5, 19.0, 47.7}, hungary.BBox)
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"vibe-tracker/constants"
+)
+
+// NetworkLocation is the geo-IP classification of a client address
+type NetworkLocation struct {
+	Country string // ISO 3166-1 alpha-2 code, empty when unknown
+	ASN     int    // Autonomous system number, 0 when unknown
+}
+
+// geoIPCountryKeys and geoIPASNKeys list the response fields of common geo-IP services in match order
+var (
+	geoIPCountryKeys = []string{"country_code", "countryCode", "country"}
+	geoIPASNKeys     = []string{"asn", "as", "org"}
+)
+
+// ParseGeoIPResponse extracts the country and ASN from a JSON geo-IP lookup response. The field
+// names of ipinfo.io, ipapi.co and ip-api.com style responses are understood.
+func ParseGeoIPResponse(body []byte) (NetworkLocation, error) {
+	var data map[string]any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return NetworkLocation{}, fmt.Errorf("invalid geo-IP response: %w", err)
+	}
+
+	var location NetworkLocation
+	for _, key := range geoIPCountryKeys {
+		// Some services put the country name into "country", only accept codes
+		if code, ok := data[key].(string); ok && len(code) == 2 {
+			location.Country = strings.ToUpper(code)
+			break
+		}
+	}
+
+	for _, key := range geoIPASNKeys {
+		switch value := data[key].(type) {
+		case float64:
+			location.ASN = int(value)
+		case string:
+			location.ASN = ParseASN(value)
+		case map[string]any:
+			if asn, ok := value["asn"].(string); ok {
+				location.ASN = ParseASN(asn)
+			}
+		}
+		if location.ASN != 0 {
+			break
+		}
+	}
+
+	return location, nil
+}
+
+// ParseASN parses an autonomous system number from forms such as "AS15169", "AS15169 Google LLC" or "15169"
+func ParseASN(value string) int {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+
+	number := strings.TrimPrefix(strings.ToUpper(fields[0]), "AS")
+	asn, err := strconv.Atoi(number)
+	if err != nil || asn < 0 {
+		return 0
+	}
+	return asn
+}
+
+// IsPublicIP reports whether the address is a routable public address worth a geo-IP lookup
+func IsPublicIP(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsMulticast()
+}
+
+// DetectLoginAnomalies compares the location of a login with the locations of earlier logins and
+// returns the reasons it looks suspicious. A first login, or one whose location is unknown, is not
+// suspicious; a country or network only counts as new when the history records that attribute.
+func DetectLoginAnomalies(history []NetworkLocation, current NetworkLocation) []string {
+	var reasons []string
+
+	if current.Country != "" {
+		seen, known := false, false
+		for _, location := range history {
+			if location.Country != "" {
+				known = true
+				seen = seen || location.Country == current.Country
+			}
+		}
+		if known && !seen {
+			reasons = append(reasons, constants.LoginAnomalyNewCountry)
+		}
+	}
+
+	if current.ASN != 0 {
+		seen, known := false, false
+		for _, location := range history {
+			if location.ASN != 0 {
+				known = true
+				seen = seen || location.ASN == current.ASN
+			}
+		}
+		if known && !seen {
+			reasons = append(reasons, constants.LoginAnomalyNewNetwork)
+		}
+	}
+
+	return reasons
+}
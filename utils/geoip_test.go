@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/constants"
+)
+
+func TestParseGeoIPResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected NetworkLocation
+		wantErr  bool
+	}{
+		{"ipinfo", `{"ip":"8.8.8.8","country":"US","org":"AS15169 Google LLC"}`, NetworkLocation{"US", 15169}, false},
+		{"ipapi.co", `{"ip":"8.8.8.8","country_code":"US","country":"US","asn":"AS15169"}`, NetworkLocation{"US", 15169}, false},
+		{"ip-api.com", `{"country":"United States","countryCode":"US","as":"AS15169 Google LLC"}`, NetworkLocation{"US", 15169}, false},
+		{"numeric ASN", `{"country_code":"hu","asn":5483}`, NetworkLocation{"HU", 5483}, false},
+		{"nested ASN", `{"country":"DE","asn":{"asn":"AS3320","name":"Deutsche Telekom AG"}}`, NetworkLocation{"DE", 3320}, false},
+		{"country name only", `{"country":"Germany"}`, NetworkLocation{}, false},
+		{"invalid JSON", `not json`, NetworkLocation{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, err := ParseGeoIPResponse([]byte(tt.body))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, location)
+		})
+	}
+}
+
+func TestParseASN(t *testing.T) {
+	assert.Equal(t, 15169, ParseASN("AS15169"))
+	assert.Equal(t, 15169, ParseASN("as15169 Google LLC"))
+	assert.Equal(t, 15169, ParseASN("15169"))
+	assert.Equal(t, 0, ParseASN("Google LLC"))
+	assert.Equal(t, 0, ParseASN(""))
+}
+
+func TestIsPublicIP(t *testing.T) {
+	assert.True(t, IsPublicIP("8.8.8.8"))
+	assert.True(t, IsPublicIP("2001:4860:4860::8888"))
+	assert.False(t, IsPublicIP("127.0.0.1"))
+	assert.False(t, IsPublicIP("192.168.1.10"))
+	assert.False(t, IsPublicIP("10.0.0.1"))
+	assert.False(t, IsPublicIP("fe80::1"))
+	assert.False(t, IsPublicIP("not-an-ip"))
+}
+
+func TestDetectLoginAnomalies(t *testing.T) {
+	history := []NetworkLocation{{"HU", 5483}, {"HU", 20845}}
+
+	tests := []struct {
+		name     string
+		history  []NetworkLocation
+		current  NetworkLocation
+		expected []string
+	}{
+		{"known country and network", history, NetworkLocation{"HU", 5483}, nil},
+		{"new network", history, NetworkLocation{"HU", 12301}, []string{constants.LoginAnomalyNewNetwork}},
+		{"new country", history, NetworkLocation{"BR", 5483}, []string{constants.LoginAnomalyNewCountry}},
+		{"new country and network", history, NetworkLocation{"BR", 28573},
+			[]string{constants.LoginAnomalyNewCountry, constants.LoginAnomalyNewNetwork}},
+		{"first login", nil, NetworkLocation{"BR", 28573}, nil},
+		{"unknown location", history, NetworkLocation{}, nil},
+		{"history without locations", []NetworkLocation{{}, {}}, NetworkLocation{"BR", 28573}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectLoginAnomalies(tt.history, tt.current))
+		})
+	}
+}
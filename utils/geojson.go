@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ValidateGeoJSONPointFeature strictly checks a raw GeoJSON Feature with a Point geometry before it
+// is decoded: the geometry must be a Point with two or three finite numeric coordinates within the
+// longitude and latitude ranges. Problems are reported per field, e.g. "geometry.coordinates[1]".
+func ValidateGeoJSONPointFeature(body []byte) error {
+	var feature map[string]json.RawMessage
+	if err := json.Unmarshal(body, &feature); err != nil {
+		return ValidationErrors{{Field: "feature", Tag: "object", Message: "request body must be a GeoJSON Feature object"}}
+	}
+
+	var errs ValidationErrors
+
+	if featureType := jsonString(feature["type"]); featureType != "Feature" {
+		errs = append(errs, ValidationError{Field: "type", Tag: "geojson_type", Value: featureType,
+			Message: fmt.Sprintf("type must be Feature, got %q", featureType)})
+	}
+
+	rawGeometry, ok := feature["geometry"]
+	if !ok || isJSONNull(rawGeometry) {
+		return append(errs, ValidationError{Field: "geometry", Tag: "required", Message: "geometry is required"})
+	}
+
+	var geometry map[string]json.RawMessage
+	if err := json.Unmarshal(rawGeometry, &geometry); err != nil {
+		return append(errs, ValidationError{Field: "geometry", Tag: "object", Message: "geometry must be an object"})
+	}
+
+	// Only single positions are tracked, other geometry types are rejected instead of ignored
+	if geometryType := jsonString(geometry["type"]); geometryType != "Point" {
+		errs = append(errs, ValidationError{Field: "geometry.type", Tag: "geojson_type", Value: geometryType,
+			Message: fmt.Sprintf("geometry.type must be Point, got %q", geometryType)})
+		return errs
+	}
+
+	var coordinates []json.RawMessage
+	if err := json.Unmarshal(geometry["coordinates"], &coordinates); err != nil || coordinates == nil {
+		return append(errs, ValidationError{Field: "geometry.coordinates", Tag: "required",
+			Message: "geometry.coordinates must be an array of [longitude, latitude] or [longitude, latitude, altitude]"})
+	}
+
+	if len(coordinates) < 2 || len(coordinates) > 3 {
+		return append(errs, ValidationError{Field: "geometry.coordinates", Tag: "coordinates_count", Value: strconv.Itoa(len(coordinates)),
+			Message: fmt.Sprintf("geometry.coordinates must have 2 or 3 values, got %d", len(coordinates))})
+	}
+
+	names := []string{"longitude", "latitude", "altitude"}
+	limits := []float64{180, 90, math.Inf(1)}
+	for i, raw := range coordinates {
+		field := fmt.Sprintf("geometry.coordinates[%d]", i)
+
+		value, err := jsonNumber(raw)
+		if err != nil {
+			errs = append(errs, ValidationError{Field: field, Tag: "number", Value: string(raw),
+				Message: fmt.Sprintf("%s (%s) %v", field, names[i], err)})
+			continue
+		}
+
+		if math.Abs(value) > limits[i] {
+			errs = append(errs, ValidationError{Field: field, Tag: names[i], Value: strconv.FormatFloat(value, 'f', -1, 64),
+				Message: fmt.Sprintf("%s (%s) must be between %g and %g", field, names[i], -limits[i], limits[i])})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// jsonString returns the value of a raw JSON string, or the raw JSON for other values
+func jsonString(raw json.RawMessage) string {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	return value
+}
+
+// jsonNumber parses a raw JSON value that must be a finite number
+func jsonNumber(raw json.RawMessage) (float64, error) {
+	// json.Number would also accept numeric strings and null
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) == 0 || trimmed[0] == '"' || isJSONNull(trimmed) {
+		return 0, fmt.Errorf("must be a number")
+	}
+
+	var number json.Number
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&number); err != nil {
+		return 0, fmt.Errorf("must be a number")
+	}
+
+	value, err := strconv.ParseFloat(number.String(), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("must be a finite number")
+	}
+	return value, nil
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return string(bytes.TrimSpace(raw)) == "null"
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateGeoJSONPointFeature(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		fields []string // Fields reported as invalid, nil when valid
+	}{
+		{"valid 2D point", `{"type":"Feature","geometry":{"type":"Point","coordinates":[19.04,47.5]},"properties":{}}`, nil},
+		{"valid 3D point", `{"type":"Feature","geometry":{"type":"Point","coordinates":[-180,-90,-12.5]},"properties":{}}`, nil},
+		{"not an object", `[1,2]`, []string{"feature"}},
+		{"wrong feature type", `{"type":"FeatureCollection","geometry":{"type":"Point","coordinates":[19,47]}}`, []string{"type"}},
+		{"missing geometry", `{"type":"Feature","geometry":null}`, []string{"geometry"}},
+		{"geometry not an object", `{"type":"Feature","geometry":"Point"}`, []string{"geometry"}},
+		{"line string", `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[19,47],[20,48]]}}`, []string{"geometry.type"}},
+		{"missing coordinates", `{"type":"Feature","geometry":{"type":"Point"}}`, []string{"geometry.coordinates"}},
+		{"nested coordinates", `{"type":"Feature","geometry":{"type":"Point","coordinates":[[19,47]]}}`, []string{"geometry.coordinates"}},
+		{"one coordinate", `{"type":"Feature","geometry":{"type":"Point","coordinates":[19]}}`, []string{"geometry.coordinates"}},
+		{"four coordinates", `{"type":"Feature","geometry":{"type":"Point","coordinates":[19,47,100,5]}}`, []string{"geometry.coordinates"}},
+		{"longitude out of range", `{"type":"Feature","geometry":{"type":"Point","coordinates":[180.5,47]}}`, []string{"geometry.coordinates[0]"}},
+		{"latitude out of range", `{"type":"Feature","geometry":{"type":"Point","coordinates":[19,-90.1]}}`, []string{"geometry.coordinates[1]"}},
+		{"swapped out of range", `{"type":"Feature","geometry":{"type":"Point","coordinates":[47,190]}}`, []string{"geometry.coordinates[1]"}},
+		{"numeric string", `{"type":"Feature","geometry":{"type":"Point","coordinates":["19",47]}}`, []string{"geometry.coordinates[0]"}},
+		{"null coordinate", `{"type":"Feature","geometry":{"type":"Point","coordinates":[19,null]}}`, []string{"geometry.coordinates[1]"}},
+		{"overflowing altitude", `{"type":"Feature","geometry":{"type":"Point","coordinates":[19,47,1e400]}}`, []string{"geometry.coordinates[2]"}},
+		{"several errors", `{"type":"Feature","geometry":{"type":"Point","coordinates":[200,true]}}`, []string{"geometry.coordinates[0]", "geometry.coordinates[1]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGeoJSONPointFeature([]byte(tt.body))
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			errs, ok := err.(ValidationErrors)
+			if assert.True(t, ok, "expected ValidationErrors, got %v", err) {
+				fields := make([]string, len(errs))
+				for i, e := range errs {
+					fields[i] = e.Field
+				}
+				assert.Equal(t, tt.fields, fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+var (
+	ErrNotJPEG         = errors.New("not a JPEG image")
+	ErrInvalidEXIF     = errors.New("invalid EXIF data")
+	ErrEXIFTooLarge    = errors.New("EXIF data too large for a JPEG segment")
+	exifHeader         = []byte("Exif\x00\x00")
+	maxJPEGSegmentSize = 0xFFFF - 2 // The segment length counts its own two bytes
+)
+
+// TIFF field types and tags written for geotags
+const (
+	tiffByte     = 1
+	tiffASCII    = 2
+	tiffLong     = 4
+	tiffRational = 5
+
+	tagGPSInfo         = 0x8825
+	tagGPSVersionID    = 0x0000
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+	tagGPSAltitudeRef  = 0x0005
+	tagGPSAltitude     = 0x0006
+	tagGPSTimeStamp    = 0x0007
+	tagGPSDateStamp    = 0x001D
+)
+
+// tiffByteOrder is the byte order of a TIFF structure, "II" (little endian) or "MM" (big endian)
+type tiffByteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// tiffEntry is a 12 byte IFD entry; value holds the inline value or the offset of the data
+type tiffEntry struct {
+	tag, fieldType uint16
+	count          uint32
+	value          [4]byte
+	data           []byte // Data stored after the IFD when it does not fit in value
+}
+
+// EmbedGPSEXIF returns a copy of a JPEG photo geotagged with the given position and UTC time.
+// Existing EXIF data is kept: the original TIFF structure is left untouched, so offsets into it
+// (maker notes, thumbnails) stay valid, and a rewritten IFD0 pointing to the new GPS IFD is
+// appended after it. An existing GPS IFD is replaced.
+func EmbedGPSEXIF(photo []byte, latitude, longitude float64, altitude *float64, at time.Time) ([]byte, error) {
+	segments, imageData, err := jpegSegments(photo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the EXIF segment, or add one first, only after a JFIF header
+	var index int
+	var tiff []byte
+	for i, segment := range segments {
+		if segment[1] == 0xE1 && bytes.HasPrefix(segment[4:], exifHeader) {
+			index, tiff = i, segment[4+len(exifHeader):]
+			break
+		}
+	}
+	if tiff == nil {
+		for index < len(segments) && segments[index][1] == 0xE0 {
+			index++
+		}
+	}
+
+	geotagged, err := geotagTIFF(tiff, latitude, longitude, altitude, at)
+	if err != nil {
+		return nil, err
+	}
+	if len(exifHeader)+len(geotagged) > maxJPEGSegmentSize {
+		return nil, ErrEXIFTooLarge
+	}
+
+	segment := append([]byte{0xFF, 0xE1}, binary.BigEndian.AppendUint16(nil, uint16(2+len(exifHeader)+len(geotagged)))...)
+	segment = append(append(segment, exifHeader...), geotagged...)
+	if tiff != nil {
+		segments[index] = segment
+	} else {
+		segments = append(segments[:index], append([][]byte{segment}, segments[index:]...)...)
+	}
+
+	var out bytes.Buffer
+	out.Write(photo[:2])
+	for _, segment := range segments {
+		out.Write(segment)
+	}
+	out.Write(imageData)
+	return out.Bytes(), nil
+}
+
+// jpegSegments splits a JPEG into its metadata segments, each starting with its marker, and the
+// image data from the start of scan on
+func jpegSegments(photo []byte) ([][]byte, []byte, error) {
+	if len(photo) < 4 || photo[0] != 0xFF || photo[1] != 0xD8 {
+		return nil, nil, ErrNotJPEG
+	}
+
+	var segments [][]byte
+	pos := 2
+	for {
+		if pos+4 > len(photo) || photo[pos] != 0xFF {
+			return nil, nil, ErrNotJPEG
+		}
+		switch photo[pos+1] {
+		case 0xFF: // Fill byte
+			pos++
+			continue
+		case 0xDA:
+			return segments, photo[pos:], nil
+		}
+
+		end := pos + 2 + int(binary.BigEndian.Uint16(photo[pos+2:]))
+		if end > len(photo) || end < pos+4 {
+			return nil, nil, ErrNotJPEG
+		}
+		segments = append(segments, photo[pos:end])
+		pos = end
+	}
+}
+
+// geotagTIFF adds a GPS IFD to the TIFF structure of EXIF data, or creates one when tiff is empty
+func geotagTIFF(tiff []byte, latitude, longitude float64, altitude *float64, at time.Time) ([]byte, error) {
+	var order tiffByteOrder = binary.LittleEndian
+	var entries []tiffEntry
+	var nextIFD uint32
+
+	if len(tiff) == 0 {
+		tiff = []byte{'I', 'I', 42, 0, 0, 0, 0, 0}
+	} else {
+		if len(tiff) < 8 {
+			return nil, ErrInvalidEXIF
+		}
+		switch string(tiff[:2]) {
+		case "II":
+		case "MM":
+			order = binary.BigEndian
+		default:
+			return nil, ErrInvalidEXIF
+		}
+
+		var err error
+		entries, nextIFD, err = readIFD(tiff, order, order.Uint32(tiff[4:]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	out := append([]byte{}, tiff...)
+	if len(out)%2 == 1 { // IFDs start on a word boundary
+		out = append(out, 0)
+	}
+
+	gps := gpsEntries(order, latitude, longitude, altitude, at)
+
+	ifd0 := make([]tiffEntry, 0, len(entries)+1)
+	for _, entry := range entries {
+		if entry.tag != tagGPSInfo {
+			ifd0 = append(ifd0, entry)
+		}
+	}
+	ifd0Offset := uint32(len(out))
+	gpsOffset := ifd0Offset + uint32(ifdSize(len(ifd0)+1))
+	gpsPointer := tiffEntry{tag: tagGPSInfo, fieldType: tiffLong, count: 1}
+	order.PutUint32(gpsPointer.value[:], gpsOffset)
+	ifd0 = append(ifd0, gpsPointer)
+	sort.Slice(ifd0, func(i, j int) bool { return ifd0[i].tag < ifd0[j].tag })
+
+	order.PutUint32(out[4:], ifd0Offset)
+	out = writeIFD(out, order, ifd0, nextIFD)
+	out = writeIFD(out, order, gps, 0)
+	return out, nil
+}
+
+// readIFD reads the entries of the IFD at offset; entries keep their original value offsets
+func readIFD(tiff []byte, order tiffByteOrder, offset uint32) ([]tiffEntry, uint32, error) {
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return nil, 0, ErrInvalidEXIF
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	end := int(offset) + ifdSize(count)
+	if end > len(tiff) {
+		return nil, 0, ErrInvalidEXIF
+	}
+
+	entries := make([]tiffEntry, count)
+	for i := range entries {
+		raw := tiff[int(offset)+2+12*i:]
+		entries[i] = tiffEntry{
+			tag:       order.Uint16(raw),
+			fieldType: order.Uint16(raw[2:]),
+			count:     order.Uint32(raw[4:]),
+		}
+		copy(entries[i].value[:], raw[8:12])
+	}
+	return entries, order.Uint32(tiff[end-4:]), nil
+}
+
+// writeIFD appends an IFD at the end of out, followed by the data of its entries
+func writeIFD(out []byte, order tiffByteOrder, entries []tiffEntry, nextIFD uint32) []byte {
+	dataOffset := uint32(len(out) + ifdSize(len(entries)))
+	var data []byte
+
+	out = order.AppendUint16(out, uint16(len(entries)))
+	for _, entry := range entries {
+		out = order.AppendUint16(out, entry.tag)
+		out = order.AppendUint16(out, entry.fieldType)
+		out = order.AppendUint32(out, entry.count)
+		if entry.data != nil {
+			out = order.AppendUint32(out, dataOffset+uint32(len(data)))
+			data = append(data, entry.data...)
+			if len(data)%2 == 1 {
+				data = append(data, 0)
+			}
+		} else {
+			out = append(out, entry.value[:]...)
+		}
+	}
+	out = order.AppendUint32(out, nextIFD)
+	return append(out, data...)
+}
+
+// ifdSize is the size of an IFD with count entries, without their data
+func ifdSize(count int) int {
+	return 2 + 12*count + 4
+}
+
+// gpsEntries builds the GPS IFD of a position, sorted by tag
+func gpsEntries(order tiffByteOrder, latitude, longitude float64, altitude *float64, at time.Time) []tiffEntry {
+	latitudeRef, longitudeRef := "N", "E"
+	if latitude < 0 {
+		latitudeRef = "S"
+	}
+	if longitude < 0 {
+		longitudeRef = "W"
+	}
+
+	at = at.UTC()
+	entries := []tiffEntry{
+		{tag: tagGPSVersionID, fieldType: tiffByte, count: 4, value: [4]byte{2, 3, 0, 0}},
+		inlineASCII(tagGPSLatitudeRef, latitudeRef),
+		{tag: tagGPSLatitude, fieldType: tiffRational, count: 3, data: degreesRationals(order, latitude)},
+		inlineASCII(tagGPSLongitudeRef, longitudeRef),
+		{tag: tagGPSLongitude, fieldType: tiffRational, count: 3, data: degreesRationals(order, longitude)},
+	}
+	if altitude != nil {
+		var below byte
+		if *altitude < 0 {
+			below = 1
+		}
+		entries = append(entries,
+			tiffEntry{tag: tagGPSAltitudeRef, fieldType: tiffByte, count: 1, value: [4]byte{below}},
+			tiffEntry{tag: tagGPSAltitude, fieldType: tiffRational, count: 1,
+				data: rationals(order, [2]uint32{uint32(math.Round(math.Abs(*altitude) * 100)), 100})},
+		)
+	}
+	entries = append(entries,
+		tiffEntry{tag: tagGPSTimeStamp, fieldType: tiffRational, count: 3,
+			data: rationals(order, [2]uint32{uint32(at.Hour()), 1}, [2]uint32{uint32(at.Minute()), 1}, [2]uint32{uint32(at.Second()), 1})},
+		tiffEntry{tag: tagGPSDateStamp, fieldType: tiffASCII, count: 11, data: []byte(at.Format("2006:01:02") + "\x00")},
+	)
+	return entries
+}
+
+// inlineASCII is a one character ASCII entry, such as a GPS reference
+func inlineASCII(tag uint16, value string) tiffEntry {
+	entry := tiffEntry{tag: tag, fieldType: tiffASCII, count: 2}
+	copy(entry.value[:], value)
+	return entry
+}
+
+// degreesRationals encodes a coordinate as degrees, minutes and seconds to 1/10000 of a second
+func degreesRationals(order tiffByteOrder, coordinate float64) []byte {
+	const secondDenominator = 10000
+	total := uint64(math.Round(math.Abs(coordinate) * 3600 * secondDenominator))
+	degrees := total / (3600 * secondDenominator)
+	minutes := total % (3600 * secondDenominator) / (60 * secondDenominator)
+	seconds := total % (60 * secondDenominator)
+	return rationals(order, [2]uint32{uint32(degrees), 1}, [2]uint32{uint32(minutes), 1}, [2]uint32{uint32(seconds), secondDenominator})
+}
+
+// rationals encodes numerator/denominator pairs as TIFF rationals
+func rationals(order tiffByteOrder, values ...[2]uint32) []byte {
+	data := make([]byte, 0, 8*len(values))
+	for _, value := range values {
+		data = order.AppendUint32(data, value[0])
+		data = order.AppendUint32(data, value[1])
+	}
+	return data
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+	"time"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"github.com/stretchr/testify/assert"
+)
+
+// testJPEG encodes a small image, optionally with an EXIF segment holding the given TIFF data
+func testJPEG(t *testing.T, tiff []byte) []byte {
+	var encoded bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 8, 8)), nil))
+	if tiff == nil {
+		return encoded.Bytes()
+	}
+
+	photo := append([]byte{}, encoded.Bytes()[:2]...)
+	photo = append(photo, 0xFF, 0xE1)
+	photo = binary.BigEndian.AppendUint16(photo, uint16(2+len(exifHeader)+len(tiff)))
+	photo = append(append(photo, exifHeader...), tiff...)
+	return append(photo, encoded.Bytes()[2:]...)
+}
+
+// cameraTIFF is big endian EXIF data with the camera make in IFD0 and a thumbnail IFD1
+func cameraTIFF() []byte {
+	order := binary.BigEndian
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8}
+	tiff = order.AppendUint16(tiff, 1)
+	tiff = order.AppendUint16(tiff, 0x010F) // Make
+	tiff = order.AppendUint16(tiff, tiffASCII)
+	tiff = order.AppendUint32(tiff, 6)
+	tiff = order.AppendUint32(tiff, 8+uint32(ifdSize(1)))
+	tiff = order.AppendUint32(tiff, 8+uint32(ifdSize(1))+6) // IFD1
+	tiff = append(tiff, "Canon\x00"...)
+	tiff = order.AppendUint16(tiff, 1)
+	tiff = order.AppendUint16(tiff, 0x0103) // Compression
+	tiff = order.AppendUint16(tiff, 3)
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = append(tiff, 0, 6, 0, 0)
+	return order.AppendUint32(tiff, 0)
+}
+
+func TestEmbedGPSEXIF(t *testing.T) {
+	at := time.Date(2025, 6, 1, 14, 32, 10, 0, time.UTC)
+	altitude := 1234.5
+
+	t.Run("photo without EXIF", func(t *testing.T) {
+		tagged, err := EmbedGPSEXIF(testJPEG(t, nil), 47.497912, -19.040235, &altitude, at)
+		assert.NoError(t, err)
+
+		data, err := ExtractEXIFData(bytes.NewReader(tagged))
+		assert.NoError(t, err)
+		assert.True(t, data.HasGPS)
+		assert.InDelta(t, 47.497912, *data.Latitude, 1e-6)
+		assert.InDelta(t, -19.040235, *data.Longitude, 1e-6)
+		assert.InDelta(t, altitude, *data.Altitude, 0.01)
+
+		x, err := exif.Decode(bytes.NewReader(tagged))
+		assert.NoError(t, err)
+		date, err := x.Get(exif.GPSDateStamp)
+		assert.NoError(t, err)
+		value, _ := date.StringVal()
+		assert.Equal(t, "2025:06:01", value)
+
+		_, err = jpeg.Decode(bytes.NewReader(tagged))
+		assert.NoError(t, err, "image data must be kept")
+	})
+
+	t.Run("existing EXIF is kept", func(t *testing.T) {
+		tagged, err := EmbedGPSEXIF(testJPEG(t, cameraTIFF()), -33.8688, 151.2093, nil, at)
+		assert.NoError(t, err)
+
+		data, err := ExtractEXIFData(bytes.NewReader(tagged))
+		assert.NoError(t, err)
+		assert.Equal(t, "Canon", data.Make)
+		assert.InDelta(t, -33.8688, *data.Latitude, 1e-6)
+		assert.InDelta(t, 151.2093, *data.Longitude, 1e-6)
+		assert.Nil(t, data.Altitude)
+
+		segments, _, err := jpegSegments(tagged)
+		assert.NoError(t, err)
+		exifSegments := 0
+		for _, segment := range segments {
+			if bytes.HasPrefix(segment[4:], exifHeader) {
+				exifSegments++
+				tiff := segment[4+len(exifHeader):]
+				_, nextIFD, err := readIFD(tiff, binary.BigEndian, binary.BigEndian.Uint32(tiff[4:]))
+				assert.NoError(t, err)
+				assert.Equal(t, uint32(8+ifdSize(1)+6), nextIFD, "thumbnail IFD must stay linked")
+			}
+		}
+		assert.Equal(t, 1, exifSegments)
+	})
+
+	t.Run("GPS is replaced", func(t *testing.T) {
+		tagged, err := EmbedGPSEXIF(testJPEG(t, nil), 10, 20, nil, at)
+		assert.NoError(t, err)
+		tagged, err = EmbedGPSEXIF(tagged, 11, 21, nil, at)
+		assert.NoError(t, err)
+
+		data, err := ExtractEXIFData(bytes.NewReader(tagged))
+		assert.NoError(t, err)
+		assert.InDelta(t, 11, *data.Latitude, 1e-6)
+		assert.InDelta(t, 21, *data.Longitude, 1e-6)
+	})
+
+	t.Run("not a JPEG", func(t *testing.T) {
+		_, err := EmbedGPSEXIF([]byte("\x89PNG\r\n\x1a\n"), 10, 20, nil, at)
+		assert.ErrorIs(t, err, ErrNotJPEG)
+	})
+
+	t.Run("corrupt EXIF", func(t *testing.T) {
+		_, err := EmbedGPSEXIF(testJPEG(t, []byte{'M', 'M', 0, 42, 0, 0, 0xFF, 0}), 10, 20, nil, at)
+		assert.ErrorIs(t, err, ErrInvalidEXIF)
+	})
+}
+
+func TestDegreesRationals(t *testing.T) {
+	// 59.99999 seconds rounds up into the next minute instead of giving 60 seconds
+	data := degreesRationals(binary.LittleEndian, 10+59.0/60+59.99999/3600)
+	values := make([]uint32, 6)
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint32(data[4*i:])
+	}
+	assert.Equal(t, []uint32{11, 1, 0, 1, 0, 10000}, values)
+}
@@ -1,10 +1,17 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
 )
 
 // GPX represents the root element of a GPX file
@@ -12,19 +19,25 @@ type GPX struct {
 	XMLName  xml.Name `xml:"gpx"`
 	Version  string   `xml:"version,attr"`
 	Creator  string   `xml:"creator,attr"`
+	Xmlns    string   `xml:"xmlns,attr,omitempty"`
 	Metadata struct {
 		Name        string `xml:"name"`
-		Description string `xml:"desc"`
-		Time        string `xml:"time"`
+		Description string `xml:"desc,omitempty"`
+		Time        string `xml:"time,omitempty"`
 	} `xml:"metadata"`
+	// GPX 1.0 has no metadata element, the name and description are children of the root
+	Name        string `xml:"name"`
+	Description string `xml:"desc,omitempty"`
+
 	Tracks    []GPXTrack    `xml:"trk"`
+	Routes    []GPXRoute    `xml:"rte"`
 	Waypoints []GPXWaypoint `xml:"wpt"`
 }
 
 // GPXTrack represents a track in the GPX file
 type GPXTrack struct {
 	Name        string       `xml:"name"`
-	Description string       `xml:"desc"`
+	Description string       `xml:"desc,omitempty"`
 	Segments    []GPXSegment `xml:"trkseg"`
 }
 
@@ -33,12 +46,36 @@ type GPXSegment struct {
 	Points []GPXTrackPoint `xml:"trkpt"`
 }
 
-// GPXTrackPoint represents a track point
+// GPXRoute represents a route, a planned sequence of points without segments
+type GPXRoute struct {
+	Name        string          `xml:"name"`
+	Description string          `xml:"desc,omitempty"`
+	Points      []GPXTrackPoint `xml:"rtept"`
+}
+
+// GPXTrackPoint represents a track point, or a route point
 type GPXTrackPoint struct {
-	Latitude  float64  `xml:"lat,attr"`
-	Longitude float64  `xml:"lon,attr"`
-	Elevation *float64 `xml:"ele,omitempty"`
-	Time      string   `xml:"time,omitempty"`
+	Latitude   float64        `xml:"lat,attr"`
+	Longitude  float64        `xml:"lon,attr"`
+	Elevation  *float64       `xml:"ele,omitempty"`
+	Time       string         `xml:"time,omitempty"`
+	Speed      *float64       `xml:"speed,omitempty"` // GPX 1.0 only, in m/s
+	Extensions *GPXExtensions `xml:"extensions,omitempty"`
+}
+
+// GPXExtensions holds the track point extensions read from GPX files. Elements are matched by
+// their local name, so Garmin's TrackPointExtension v1 and v2 are both understood, as is the
+// plain <power> element some tools write instead of the PowerExtension.
+type GPXExtensions struct {
+	TrackPoint *struct {
+		Temperature *float64 `xml:"atemp"`
+		HeartRate   *float64 `xml:"hr"`
+		Cadence     *float64 `xml:"cad"`
+		Speed       *float64 `xml:"speed"`
+	} `xml:"TrackPointExtension"`
+	PowerInWatts *float64 `xml:"PowerInWatts"`
+	Power        *float64 `xml:"power"`
+	Accuracy     *float64 `xml:"accuracy"`
 }
 
 // GPXWaypoint represents a waypoint in the GPX file
@@ -57,16 +94,34 @@ type GPXWaypoint struct {
 type ParsedGPXData struct {
 	TrackName        string
 	TrackDescription string
+	Tracks           []ParsedTrack // The tracks, then the routes of the file, indexed by ParsedTrackPoint.Track
 	TrackPoints      []ParsedTrackPoint
 	Waypoints        []ParsedWaypoint
+	Warnings         []string // What lenient parsing repaired to read the file
+}
+
+// ParsedTrack describes a track or route of a GPX file
+type ParsedTrack struct {
+	Name        string
+	Description string
+	Route       bool // Parsed from a <rte>, which has a single segment
 }
 
 // ParsedTrackPoint represents a track point ready for database storage
 type ParsedTrackPoint struct {
-	Latitude  float64
-	Longitude float64
-	Altitude  *float64
-	Sequence  int
+	Latitude    float64
+	Longitude   float64
+	Altitude    *float64
+	Sequence    int
+	Track       int       // Index of the track or route in ParsedGPXData.Tracks
+	Segment     int       // Index of the segment within its track
+	Time        time.Time // Zero when the point has no valid time
+	Speed       *float64
+	HeartRate   *float64
+	Cadence     *float64
+	Power       *float64
+	Temperature *float64
+	Accuracy    *float64
 }
 
 // ParsedWaypoint represents a waypoint ready for database storage
@@ -81,99 +136,351 @@ type ParsedWaypoint struct {
 	PositionConfidence string
 }
 
+// GPXParseOptions configures how GPX files are read
+type GPXParseOptions struct {
+	// Lenient reads files that break the XML or GPX specification in ways seen from older devices:
+	// undeclared UTF-16 or Windows-1252 text, HTML entities and bare ampersands, files cut off
+	// mid-write and a missing version. What was repaired is listed in ParsedGPXData.Warnings.
+	Lenient bool
+}
+
 // ParseGPX parses a GPX file from an io.Reader and returns structured data
 func ParseGPX(reader io.Reader) (*ParsedGPXData, error) {
+	return GPXParseOptions{}.Parse(reader)
+}
+
+// Parse parses a GPX file from an io.Reader and returns structured data. GPX 1.0 and 1.1 files
+// are both understood; a lenient parse reads the file strictly first and repairs it only when
+// that fails.
+func (o GPXParseOptions) Parse(reader io.Reader) (*ParsedGPXData, error) {
 	// Read the GPX data
 	data, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read GPX data: %v", err)
 	}
 
+	warnings := []string{}
+	transcoded := false
+	if o.Lenient {
+		var warning string
+		if data, warning = transcodeGPXText(data); warning != "" {
+			warnings = append(warnings, warning)
+			transcoded = true
+		}
+	}
+
 	// Parse XML
 	var gpx GPX
-	if err := xml.Unmarshal(data, &gpx); err != nil {
-		return nil, fmt.Errorf("failed to parse GPX XML: %v", err)
+	if err := decodeGPX(data, transcoded, false, &gpx); err != nil {
+		if !o.Lenient {
+			return nil, fmt.Errorf("failed to parse GPX XML: %v", err)
+		}
+
+		warnings = append(warnings, fmt.Sprintf("Read malformed XML leniently: %v", err))
+		if repaired, ok := closeTruncatedGPX(data); ok {
+			data = repaired
+			warnings = append(warnings, "The file ends early, unclosed elements were closed and the incomplete last point dropped")
+		}
+		gpx = GPX{}
+		if err := decodeGPX(data, transcoded, true, &gpx); err != nil {
+			return nil, fmt.Errorf("failed to parse GPX XML: %v", err)
+		}
 	}
 
 	// Validate GPX version
 	if gpx.Version == "" {
-		return nil, fmt.Errorf("invalid GPX file: missing version")
+		if !o.Lenient {
+			return nil, fmt.Errorf("invalid GPX file: missing version")
+		}
+		gpx.Version = "1.1"
+		if strings.Contains(gpx.Xmlns, "/GPX/1/0") {
+			gpx.Version = "1.0"
+		}
+		warnings = append(warnings, fmt.Sprintf("The file has no GPX version, read as GPX %s", gpx.Version))
 	}
 
 	// Extract track data
+	tracks, points := extractTrackPoints(&gpx)
 	parsed := &ParsedGPXData{
 		TrackName:        extractTrackName(&gpx),
 		TrackDescription: extractTrackDescription(&gpx),
-		TrackPoints:      extractTrackPoints(&gpx),
+		Tracks:           tracks,
+		TrackPoints:      points,
 		Waypoints:        extractWaypoints(&gpx),
+		Warnings:         warnings,
 	}
 
 	return parsed, nil
 }
 
-// extractTrackName gets the track name from GPX metadata or first track
+// decodeGPX decodes GPX XML. Text in other encodings is converted as declared, unless it was
+// transcoded to UTF-8 already; a lenient decoder accepts HTML entities and unescaped characters.
+func decodeGPX(data []byte, transcoded, lenient bool, gpx *GPX) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	if transcoded {
+		decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+			return input, nil // The declaration names the encoding before transcoding
+		}
+	}
+	if lenient {
+		decoder.Strict = false
+		decoder.Entity = xml.HTMLEntity
+	}
+	return decoder.Decode(gpx)
+}
+
+// transcodeGPXText converts UTF-16 text with a byte order mark, and text declared or assumed to
+// be UTF-8 that is not, to UTF-8. The latter is usually Windows-1252 written by older software.
+// It returns a warning when the text was converted.
+func transcodeGPXText(data []byte) ([]byte, string) {
+	label := utf16Label(data)
+	if label == "" {
+		if utf8.Valid(data) {
+			return data, ""
+		}
+		if declared := declaredXMLEncoding(data); declared != "" && !strings.EqualFold(declared, "utf-8") {
+			return data, "" // Converted as declared
+		}
+		label = "windows-1252"
+	}
+
+	converted, err := decodeText(data, label)
+	if err != nil {
+		return data, ""
+	}
+	return converted, fmt.Sprintf("Read the file as %s text", strings.ToUpper(label))
+}
+
+// utf16Label returns the encoding label of UTF-16 text by its byte order mark, or ""
+func utf16Label(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return "utf-16le"
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return "utf-16be"
+	}
+	return ""
+}
+
+// decodeText converts text in the encoding with the given label to UTF-8
+func decodeText(data []byte, label string) ([]byte, error) {
+	reader, err := charset.NewReaderLabel(label, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+// declaredXMLEncoding returns the encoding named by the XML declaration, if any
+func declaredXMLEncoding(data []byte) string {
+	end := bytes.Index(data, []byte("?>"))
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("<?xml")) || end < 0 {
+		return ""
+	}
+	match := xmlEncodingPattern.FindSubmatch(data[:end])
+	if match == nil {
+		return ""
+	}
+	return string(match[1])
+}
+
+var xmlEncodingPattern = regexp.MustCompile(`encoding\s*=\s*["']([^"']+)["']`)
+
+// closeTruncatedGPX repairs GPX XML that ends before its elements are closed, e.g. written by a
+// device that lost power: the incomplete last point is dropped and the open elements are closed.
+// It reports false when the XML is not truncated.
+func closeTruncatedGPX(data []byte) ([]byte, bool) {
+	type openElement struct {
+		name  string
+		start int64
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		return input, nil // Only the markup is read
+	}
+
+	var open []openElement
+	end := int64(0) // End of the last complete token
+	for {
+		start := decoder.InputOffset()
+		token, err := decoder.RawToken()
+		var syntaxErr *xml.SyntaxError
+		if errors.As(err, &syntaxErr) && syntaxErr.Msg != "unexpected EOF" {
+			return data, false // Malformed before the end, not cut off
+		}
+		if err != nil {
+			break
+		}
+		end = decoder.InputOffset()
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			open = append(open, openElement{name: rawXMLName(element.Name), start: start})
+		case xml.EndElement:
+			// Unmatched end tags close the elements opened since the matching start tag
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i].name == rawXMLName(element.Name) {
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+	if len(open) == 0 {
+		return data, false
+	}
+
+	// Points may be cut off before their time or elevation is complete
+	for i, element := range open {
+		if name := element.name[strings.LastIndex(element.name, ":")+1:]; name == "trkpt" || name == "rtept" || name == "wpt" {
+			end = element.start
+			open = open[:i]
+			break
+		}
+	}
+
+	repaired := append([]byte{}, data[:end]...)
+	for i := len(open) - 1; i >= 0; i-- {
+		repaired = append(repaired, "</"+open[i].name+">"...)
+	}
+	return repaired, true
+}
+
+// rawXMLName returns an element name as written, with its namespace prefix
+func rawXMLName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// extractTrackName gets the track name from GPX metadata or first track or route
 func extractTrackName(gpx *GPX) string {
-	// Try metadata name first
+	// Try metadata name first, or the root name of GPX 1.0
 	if gpx.Metadata.Name != "" {
 		return strings.TrimSpace(gpx.Metadata.Name)
 	}
+	if gpx.Name != "" {
+		return strings.TrimSpace(gpx.Name)
+	}
 
-	// Try first track name
+	// Try first track name, then first route name
 	if len(gpx.Tracks) > 0 && gpx.Tracks[0].Name != "" {
 		return strings.TrimSpace(gpx.Tracks[0].Name)
 	}
+	if len(gpx.Routes) > 0 && gpx.Routes[0].Name != "" {
+		return strings.TrimSpace(gpx.Routes[0].Name)
+	}
 
 	return "Imported Track"
 }
 
-// extractTrackDescription gets the track description from GPX metadata or first track
+// extractTrackDescription gets the track description from GPX metadata or first track or route
 func extractTrackDescription(gpx *GPX) string {
-	// Try metadata description first
+	// Try metadata description first, or the root description of GPX 1.0
 	if gpx.Metadata.Description != "" {
 		return strings.TrimSpace(gpx.Metadata.Description)
 	}
+	if gpx.Description != "" {
+		return strings.TrimSpace(gpx.Description)
+	}
 
-	// Try first track description
+	// Try first track description, then first route description
 	if len(gpx.Tracks) > 0 && gpx.Tracks[0].Description != "" {
 		return strings.TrimSpace(gpx.Tracks[0].Description)
 	}
+	if len(gpx.Routes) > 0 && gpx.Routes[0].Description != "" {
+		return strings.TrimSpace(gpx.Routes[0].Description)
+	}
 
 	return ""
 }
 
-// extractTrackPoints processes all track points from all tracks and segments
-func extractTrackPoints(gpx *GPX) []ParsedTrackPoint {
+// extractTrackPoints processes all points of all tracks and segments, then of all routes, in one
+// sequence. Each point keeps the index of its track or route and of its segment.
+func extractTrackPoints(gpx *GPX) ([]ParsedTrack, []ParsedTrackPoint) {
 	var points []ParsedTrackPoint
-	sequence := 0
+	tracks := make([]ParsedTrack, 0, len(gpx.Tracks)+len(gpx.Routes))
 
 	for _, track := range gpx.Tracks {
-		for _, segment := range track.Segments {
-			for _, point := range segment.Points {
-				// Validate coordinates
-				if !isValidCoordinate(point.Latitude, point.Longitude) {
-					continue
-				}
+		trackIndex := len(tracks)
+		tracks = append(tracks, ParsedTrack{
+			Name:        strings.TrimSpace(track.Name),
+			Description: strings.TrimSpace(track.Description),
+		})
+		for segmentIndex, segment := range track.Segments {
+			points = appendTrackPoints(points, segment.Points, trackIndex, segmentIndex)
+		}
+	}
 
-				parsedPoint := ParsedTrackPoint{
-					Latitude:  point.Latitude,
-					Longitude: point.Longitude,
-					Sequence:  sequence,
-				}
+	for _, route := range gpx.Routes {
+		trackIndex := len(tracks)
+		tracks = append(tracks, ParsedTrack{
+			Name:        strings.TrimSpace(route.Name),
+			Description: strings.TrimSpace(route.Description),
+			Route:       true,
+		})
+		points = appendTrackPoints(points, route.Points, trackIndex, 0)
+	}
 
-				// Add elevation if present and valid
-				if point.Elevation != nil && *point.Elevation != 0 {
-					parsedPoint.Altitude = point.Elevation
-				}
+	return tracks, points
+}
 
-				points = append(points, parsedPoint)
-				sequence++
-			}
+// appendTrackPoints appends the valid points of a segment or route, continuing the sequence
+func appendTrackPoints(points []ParsedTrackPoint, segment []GPXTrackPoint, track, segmentIndex int) []ParsedTrackPoint {
+	for _, point := range segment {
+		// Validate coordinates
+		if !isValidCoordinate(point.Latitude, point.Longitude) {
+			continue
+		}
+
+		parsedPoint := ParsedTrackPoint{
+			Latitude:  point.Latitude,
+			Longitude: point.Longitude,
+			Sequence:  len(points),
+			Track:     track,
+			Segment:   segmentIndex,
+			Speed:     point.Speed,
+		}
+
+		// Add elevation if present and valid
+		if point.Elevation != nil && *point.Elevation != 0 {
+			parsedPoint.Altitude = point.Elevation
+		}
+
+		if t, err := time.Parse(time.RFC3339, strings.TrimSpace(point.Time)); err == nil {
+			parsedPoint.Time = t
+		}
+		if point.Extensions != nil {
+			applyGPXExtensions(&parsedPoint, point.Extensions)
 		}
+
+		points = append(points, parsedPoint)
 	}
 
 	return points
 }
 
+// applyGPXExtensions copies the measures of a track point's extensions
+func applyGPXExtensions(point *ParsedTrackPoint, extensions *GPXExtensions) {
+	if tpx := extensions.TrackPoint; tpx != nil {
+		point.Temperature = tpx.Temperature
+		point.HeartRate = tpx.HeartRate
+		point.Cadence = tpx.Cadence
+		point.Speed = tpx.Speed
+	}
+
+	point.Power = extensions.PowerInWatts
+	if point.Power == nil {
+		point.Power = extensions.Power
+	}
+	point.Accuracy = extensions.Accuracy
+}
+
 // extractWaypoints processes all waypoints from the GPX file
 func extractWaypoints(gpx *GPX) []ParsedWaypoint {
 	var waypoints []ParsedWaypoint
@@ -343,64 +650,38 @@ func ramerDouglasPeucker(points []ParsedTrackPoint, epsilon float64) []ParsedTra
 	return []ParsedTrackPoint{start, end}
 }
 
-// perpendicularDistance calculates the perpendicular distance from a point to a line segment
+// perpendicularDistance returns the distance in meters from a point to a line segment
 func perpendicularDistance(point, lineStart, lineEnd ParsedTrackPoint) float64 {
-	// Convert to approximate Cartesian coordinates (good enough for small distances)
-	px, py := point.Longitude, point.Latitude
-	x1, y1 := lineStart.Longitude, lineStart.Latitude
-	x2, y2 := lineEnd.Longitude, lineEnd.Latitude
-
-	// Calculate the distance from point to line segment
-	A := px - x1
-	B := py - y1
-	C := x2 - x1
-	D := y2 - y1
-
-	dot := A*C + B*D
-	lenSq := C*C + D*D
-
-	if lenSq == 0 {
-		// Line segment is actually a point
-		dx := px - x1
-		dy := py - y1
-		return dx*dx + dy*dy
-	}
-
-	param := dot / lenSq
-
-	var xx, yy float64
-	if param < 0 {
-		xx, yy = x1, y1
-	} else if param > 1 {
-		xx, yy = x2, y2
-	} else {
-		xx = x1 + param*C
-		yy = y1 + param*D
-	}
-
-	dx := px - xx
-	dy := py - yy
-	return dx*dx + dy*dy
+	return SegmentDistance(point.Latitude, point.Longitude,
+		lineStart.Latitude, lineStart.Longitude, lineEnd.Latitude, lineEnd.Longitude)
 }
 
-// CalculateSimplificationEpsilon suggests an appropriate epsilon value based on track characteristics
+// CalculateSimplificationEpsilon suggests a tolerance in meters for SimplifyTrack: 30% of the
+// average spacing of the points within their segments, more for long tracks
 func CalculateSimplificationEpsilon(points []ParsedTrackPoint) float64 {
 	if len(points) < 10 {
 		return 0 // Don't simplify very short tracks
 	}
 
-	// Calculate average distance between consecutive points
+	// Calculate average distance between consecutive points; the gap between two segments or
+	// tracks is no point spacing
 	totalDistance := 0.0
+	pairs := 0
 	for i := 1; i < len(points); i++ {
-		dx := points[i].Longitude - points[i-1].Longitude
-		dy := points[i].Latitude - points[i-1].Latitude
-		totalDistance += dx*dx + dy*dy
+		if !sameSegment(points[i-1], points[i]) {
+			continue
+		}
+		totalDistance += HaversineDistance(points[i-1].Latitude, points[i-1].Longitude, points[i].Latitude, points[i].Longitude)
+		pairs++
+	}
+	if pairs == 0 {
+		return 0
 	}
 
-	avgDistance := totalDistance / float64(len(points)-1)
+	avgDistance := totalDistance / float64(pairs)
 
 	// Base epsilon on track density and length
-	baseEpsilon := avgDistance * 0.1 // 10% of average point spacing
+	baseEpsilon := avgDistance * 0.3 // 30% of average point spacing
 
 	// Adjust based on total track length
 	if len(points) > 1000 {
@@ -411,3 +692,72 @@ func CalculateSimplificationEpsilon(points []ParsedTrackPoint) float64 {
 
 	return baseEpsilon
 }
+
+// SimplificationOptions configures how planned tracks are simplified. Tracks of at most MinPoints
+// points are kept as they are; longer ones are simplified segment by segment with the epsilon
+// suggested by CalculateSimplificationEpsilon scaled by EpsilonMultiplier, and further until at
+// most MaxPoints points remain, or only the ends of the segments. A MaxPoints of zero sets no limit.
+type SimplificationOptions struct {
+	Enabled           bool
+	MinPoints         int
+	MaxPoints         int
+	EpsilonMultiplier float64
+}
+
+// Simplify returns the points of a track kept by the simplified plan, in track order
+func (o SimplificationOptions) Simplify(points []ParsedTrackPoint) []ParsedTrackPoint {
+	if !o.Enabled || len(points) <= o.MinPoints {
+		return points
+	}
+
+	epsilon := CalculateSimplificationEpsilon(points) * o.EpsilonMultiplier
+	simplified := points
+	if epsilon > 0 {
+		simplified = simplifySegments(points, epsilon)
+	}
+	if o.MaxPoints <= 0 || len(simplified) <= o.MaxPoints {
+		return simplified
+	}
+
+	// Raise the tolerance until the plan fits; the ends of every segment are always kept
+	minPoints := 0
+	forEachSegment(points, func(segment []ParsedTrackPoint) { minPoints += min(len(segment), 2) })
+	if epsilon <= 0 {
+		epsilon = minSimplificationEpsilon
+	}
+	for len(simplified) > max(o.MaxPoints, minPoints) {
+		epsilon *= 2
+		simplified = simplifySegments(points, epsilon)
+	}
+	return simplified
+}
+
+// simplifySegments simplifies every segment of a track on its own, so segments and tracks stay
+// apart and keep their ends
+func simplifySegments(points []ParsedTrackPoint, epsilon float64) []ParsedTrackPoint {
+	simplified := make([]ParsedTrackPoint, 0, len(points))
+	forEachSegment(points, func(segment []ParsedTrackPoint) {
+		simplified = append(simplified, SimplifyTrack(segment, epsilon)...)
+	})
+	return simplified
+}
+
+// forEachSegment calls fn with every run of consecutive points of the same track segment
+func forEachSegment(points []ParsedTrackPoint, fn func(segment []ParsedTrackPoint)) {
+	start := 0
+	for i := 1; i <= len(points); i++ {
+		if i == len(points) || !sameSegment(points[i-1], points[i]) {
+			fn(points[start:i])
+			start = i
+		}
+	}
+}
+
+// sameSegment reports whether two points belong to the same segment of the same track
+func sameSegment(a, b ParsedTrackPoint) bool {
+	return a.Track == b.Track && a.Segment == b.Segment
+}
+
+// minSimplificationEpsilon is the tolerance in meters a point limit starts from when the
+// suggested one is zero
+const minSimplificationEpsilon = 0.01
@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// wigglyTrack returns a track heading east that zigzags a little, so simplification drops points
+// depending on the tolerance
+func wigglyTrack(count int) []ParsedTrackPoint {
+	points := make([]ParsedTrackPoint, count)
+	for i := range points {
+		points[i] = ParsedTrackPoint{
+			Latitude:  47.0 + 0.0001*math.Sin(float64(i)/3),
+			Longitude: 19.0 + 0.0001*float64(i),
+			Sequence:  i,
+		}
+	}
+	return points
+}
+
+func TestSimplificationOptions_Simplify(t *testing.T) {
+	track := wigglyTrack(300)
+	defaults := SimplificationOptions{Enabled: true, MinPoints: 100, EpsilonMultiplier: 1}
+
+	t.Run("disabled", func(t *testing.T) {
+		options := defaults
+		options.Enabled = false
+		assert.Len(t, options.Simplify(track), 300)
+	})
+
+	t.Run("short track", func(t *testing.T) {
+		assert.Len(t, defaults.Simplify(wigglyTrack(80)), 80)
+	})
+
+	t.Run("keeps endpoints in order", func(t *testing.T) {
+		simplified := defaults.Simplify(track)
+		assert.Less(t, len(simplified), 300)
+		assert.Equal(t, 0, simplified[0].Sequence)
+		assert.Equal(t, 299, simplified[len(simplified)-1].Sequence)
+		for i := 1; i < len(simplified); i++ {
+			assert.Greater(t, simplified[i].Sequence, simplified[i-1].Sequence)
+		}
+	})
+
+	t.Run("larger multiplier keeps fewer points", func(t *testing.T) {
+		coarse := defaults
+		coarse.EpsilonMultiplier = 10
+		assert.Less(t, len(coarse.Simplify(track)), len(defaults.Simplify(track)))
+	})
+
+	t.Run("point limit", func(t *testing.T) {
+		limited := defaults
+		limited.MaxPoints = 20
+		assert.LessOrEqual(t, len(limited.Simplify(track)), 20)
+	})
+}
+
+func TestSimplifyTrack_HighLatitude(t *testing.T) {
+	// The same 5 m zigzag on a track heading north and on one heading east at 70 degrees north;
+	// in raw degrees the eastward zigzag would look about three times larger
+	wiggle := func(i int) float64 { return 5 * math.Sin(float64(i)/3) }
+	north := make([]ParsedTrackPoint, 200)
+	east := make([]ParsedTrackPoint, 200)
+	for i := range north {
+		step := 20 * float64(i)
+		north[i] = ParsedTrackPoint{Latitude: 70 + step/111195, Longitude: 25 + wiggle(i)/(111195*math.Cos(70*math.Pi/180)), Sequence: i}
+		east[i] = ParsedTrackPoint{Latitude: 70 + wiggle(i)/111195, Longitude: 25 + step/(111195*math.Cos(70*math.Pi/180)), Sequence: i}
+	}
+
+	assert.InDelta(t, CalculateSimplificationEpsilon(north), CalculateSimplificationEpsilon(east), 0.1)
+	for _, epsilon := range []float64{2, 4, 6} {
+		assert.InDelta(t, len(SimplifyTrack(north, epsilon)), len(SimplifyTrack(east, epsilon)), 2, "epsilon %g", epsilon)
+	}
+	assert.Len(t, SimplifyTrack(east, 12), 2, "the zigzag stays within 12 m of the straight track")
+}
+
+func TestParseGPX_TracksAndRoutes(t *testing.T) {
+	gpx := `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk>
+    <name>Day 1</name>
+    <trkseg>
+      <trkpt lat="47.50" lon="19.00"/>
+      <trkpt lat="47.51" lon="19.01"/>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="47.52" lon="19.02"/>
+    </trkseg>
+  </trk>
+  <trk>
+    <name>Day 2</name>
+    <desc>Back home</desc>
+    <trkseg>
+      <trkpt lat="47.53" lon="19.03"/>
+      <trkpt lat="95" lon="19.04"/>
+    </trkseg>
+  </trk>
+  <rte>
+    <name>Detour</name>
+    <rtept lat="47.60" lon="19.10"><ele>250</ele></rtept>
+    <rtept lat="47.61" lon="19.11"/>
+  </rte>
+</gpx>`
+
+	parsed, err := ParseGPX(strings.NewReader(gpx))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "Day 1", parsed.TrackName)
+	assert.Equal(t, []ParsedTrack{
+		{Name: "Day 1"},
+		{Name: "Day 2", Description: "Back home"},
+		{Name: "Detour", Route: true},
+	}, parsed.Tracks)
+
+	type position struct{ sequence, track, segment int }
+	positions := make([]position, len(parsed.TrackPoints))
+	for i, point := range parsed.TrackPoints {
+		positions[i] = position{point.Sequence, point.Track, point.Segment}
+	}
+	assert.Equal(t, []position{{0, 0, 0}, {1, 0, 0}, {2, 0, 1}, {3, 1, 0}, {4, 2, 0}, {5, 2, 0}}, positions)
+	assert.Equal(t, 250.0, *parsed.TrackPoints[4].Altitude)
+}
+
+func TestParseGPX_RouteOnly(t *testing.T) {
+	gpx := `<gpx version="1.1"><rte><name>Plan</name><desc>Loop</desc>` +
+		`<rtept lat="47.5" lon="19"/><rtept lat="47.6" lon="19.1"/></rte></gpx>`
+
+	parsed, err := ParseGPX(strings.NewReader(gpx))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Plan", parsed.TrackName)
+		assert.Equal(t, "Loop", parsed.TrackDescription)
+		assert.Len(t, parsed.TrackPoints, 2)
+	}
+}
+
+func TestSimplificationOptions_SimplifyKeepsSegments(t *testing.T) {
+	// Two tracks of two segments each, every one a wiggly line of its own
+	var points []ParsedTrackPoint
+	for track := 0; track < 2; track++ {
+		for segment := 0; segment < 2; segment++ {
+			for _, point := range wigglyTrack(100) {
+				point.Latitude += float64(track) * 0.1
+				point.Longitude += float64(segment) * 0.1
+				point.Sequence = len(points)
+				point.Track, point.Segment = track, segment
+				points = append(points, point)
+			}
+		}
+	}
+
+	options := SimplificationOptions{Enabled: true, MinPoints: 100, EpsilonMultiplier: 1}
+	simplified := options.Simplify(points)
+	assert.Less(t, len(simplified), len(points))
+	for _, boundary := range []int{0, 99, 100, 199, 200, 299, 300, 399} {
+		assert.True(t, slices.ContainsFunc(simplified, func(p ParsedTrackPoint) bool { return p.Sequence == boundary }),
+			"segment end %d is kept", boundary)
+	}
+
+	// A point limit below the segment ends keeps the ends only
+	options.MaxPoints = 3
+	assert.Len(t, options.Simplify(points), 8)
+}
+
+func TestParseGPX_Version10(t *testing.T) {
+	data := `<?xml version="1.0" encoding="ISO-8859-1"?>
+<gpx version="1.0" creator="eTrex" xmlns="http://www.topografix.com/GPX/1/0">
+  <name>Sz` + "\xe9" + `kesfeh` + "\xe9" + `rv` + "\xe1" + `r</name>
+  <desc>Lakeside loop</desc>
+  <trk><trkseg>
+    <trkpt lat="46.90" lon="17.90"><ele>110</ele><time>2025-09-01T08:00:00Z</time><speed>4.5</speed></trkpt>
+    <trkpt lat="46.91" lon="17.91"><time>2025-09-01T08:01:00Z</time></trkpt>
+  </trkseg></trk>
+</gpx>`
+
+	parsed, err := ParseGPX(strings.NewReader(data))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Székesfehérvár", parsed.TrackName)
+	assert.Equal(t, "Lakeside loop", parsed.TrackDescription)
+	assert.Len(t, parsed.TrackPoints, 2)
+	assert.Equal(t, 4.5, *parsed.TrackPoints[0].Speed)
+	assert.Nil(t, parsed.TrackPoints[1].Speed)
+	assert.Empty(t, parsed.Warnings)
+}
+
+func TestGPXParseOptions_Lenient(t *testing.T) {
+	lenient := GPXParseOptions{Lenient: true}
+	const points = `<trk><name>Loop</name><trkseg>
+    <trkpt lat="47.50" lon="19.00"><time>2025-09-01T08:00:00Z</time></trkpt>
+    <trkpt lat="47.51" lon="19.01"><time>2025-09-01T08:01:00Z</time></trkpt>`
+
+	tests := []struct {
+		name     string
+		data     string
+		warnings int
+		points   int
+		track    string
+	}{
+		{"well-formed", `<gpx version="1.1">` + points + `</trkseg></trk></gpx>`, 0, 2, "Loop"},
+		{"html entities", `<gpx version="1.1"><metadata><name>Hike&nbsp;&amp; Bike & more</name></metadata>` + points + `</trkseg></trk></gpx>`, 1, 2, "Hike & Bike & more"},
+		{"cut off in a point", `<gpx version="1.1">` + points + `<trkpt lat="47.52" lon="19.02"><time>2025-09-0`, 2, 2, "Loop"},
+		{"cut off in a tag", `<gpx version="1.1">` + points + `<trkpt lat="47.5`, 2, 2, "Loop"},
+		{"missing version", `<gpx xmlns="http://www.topografix.com/GPX/1/0">` + points + `</trkseg></trk></gpx>`, 1, 2, "Loop"},
+		{"windows-1252", `<gpx version="1.1"><trk><name>Caf` + "\xe9" + `</name><trkseg><trkpt lat="47.50" lon="19.00"/></trkseg></trk></gpx>`, 1, 1, "Café"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := lenient.Parse(strings.NewReader(tt.data))
+
+			assert.NoError(t, err)
+			assert.Len(t, parsed.Warnings, tt.warnings, parsed.Warnings)
+			assert.Len(t, parsed.TrackPoints, tt.points)
+			assert.Equal(t, tt.track, parsed.TrackName)
+
+			if tt.warnings > 0 {
+				_, err := ParseGPX(strings.NewReader(tt.data))
+				assert.Error(t, err, "strict parsing rejects the file")
+			}
+		})
+	}
+
+	t.Run("utf-16", func(t *testing.T) {
+		text := `<?xml version="1.0" encoding="UTF-16"?><gpx version="1.1"><trk><name>Túra</name><trkseg><trkpt lat="47.5" lon="19.0"/></trkseg></trk></gpx>`
+		data := []byte{0xFF, 0xFE}
+		for _, unit := range utf16.Encode([]rune(text)) {
+			data = append(data, byte(unit), byte(unit>>8))
+		}
+
+		parsed, err := lenient.Parse(bytes.NewReader(data))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Túra", parsed.TrackName)
+		assert.Len(t, parsed.TrackPoints, 1)
+		assert.Equal(t, []string{"Read the file as UTF-16LE text"}, parsed.Warnings)
+	})
+
+	t.Run("malformed before the end", func(t *testing.T) {
+		_, err := lenient.Parse(strings.NewReader(`<gpx version="1.1"><trk><trkseg><trkpt lat=47.5 lon="19.0"/></trkseg></trk></gpx>`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep is an object key or, when key is empty, an array index
+type jsonPathStep struct {
+	key   string
+	index int
+}
+
+// parseJSONPath parses the JSONPath subset of webhook mappings: "$" followed by ".key",
+// "['key']" and "[index]" steps, e.g. "$.attributes.latitude" or "$.points[0]['lat']"
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path %q must start with $", path)
+	}
+
+	var steps []jsonPathStep
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("path %q has an empty key", path)
+			}
+			steps = append(steps, jsonPathStep{key: key})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("path %q has an unclosed [", path)
+			}
+			selector := rest[1:end]
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				steps = append(steps, jsonPathStep{key: selector[1 : len(selector)-1]})
+			} else if index, err := strconv.Atoi(selector); err == nil && index >= 0 {
+				steps = append(steps, jsonPathStep{index: index})
+			} else {
+				return nil, fmt.Errorf("path %q has an invalid selector [%s]", path, selector)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("path %q is invalid at %q", path, rest)
+		}
+	}
+	return steps, nil
+}
+
+// LookupJSONPath returns the value at a path of a decoded JSON document, see parseJSONPath. The
+// second result is false when the path does not exist.
+func LookupJSONPath(document any, path string) (any, bool, error) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	value := document
+	for _, step := range steps {
+		switch container := value.(type) {
+		case map[string]any:
+			if step.key == "" {
+				return nil, false, nil
+			}
+			next, ok := container[step.key]
+			if !ok {
+				return nil, false, nil
+			}
+			value = next
+		case []any:
+			if step.key != "" || step.index >= len(container) {
+				return nil, false, nil
+			}
+			value = container[step.index]
+		default:
+			return nil, false, nil
+		}
+	}
+	return value, true, nil
+}
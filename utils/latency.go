@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Percentile returns the p-th percentile (0 to 1) of durations by the nearest-rank method. The
+// slice is sorted in place.
+func Percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	rank := int(math.Ceil(p*float64(len(durations)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return durations[rank]
+}
+
+// ParseRouteBudgets parses comma-separated "METHOD /route=duration" pairs such as
+// "GET /api/session/:username/:session=5s". Routes are given as registered, with their
+// parameter placeholders.
+func ParseRouteBudgets(value string) (map[string]time.Duration, error) {
+	budgets := map[string]time.Duration{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		idx := strings.LastIndex(pair, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid route budget %q, expected \"METHOD /route=duration\"", pair)
+		}
+
+		method, path, ok := strings.Cut(strings.TrimSpace(pair[:idx]), " ")
+		path = strings.TrimSpace(path)
+		if !ok || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid route %q in route budget, expected \"METHOD /route\"", pair[:idx])
+		}
+
+		budget, err := time.ParseDuration(strings.TrimSpace(pair[idx+1:]))
+		if err != nil || budget < 0 {
+			return nil, fmt.Errorf("invalid duration in route budget %q", pair)
+		}
+		budgets[RouteKey(method, path)] = budget
+	}
+	return budgets, nil
+}
+
+// RouteKey identifies a route by its method and registered path
+func RouteKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
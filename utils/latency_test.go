@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	assert.Equal(t, time.Duration(0), Percentile(nil, 0.95))
+	assert.Equal(t, 5*time.Millisecond, Percentile([]time.Duration{5 * time.Millisecond}, 0.95))
+
+	durations := make([]time.Duration, 0, 100)
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 95*time.Millisecond, Percentile(durations, 0.95))
+	assert.Equal(t, 50*time.Millisecond, Percentile(durations, 0.5))
+	assert.Equal(t, 100*time.Millisecond, Percentile(durations, 1))
+}
+
+func TestParseRouteBudgets(t *testing.T) {
+	budgets, err := ParseRouteBudgets("get /api/session/:username/:session=5s, POST /api/track=500ms,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{
+		"GET /api/session/:username/:session": 5 * time.Second,
+		"POST /api/track":                     500 * time.Millisecond,
+	}, budgets)
+
+	for _, invalid := range []string{"/api/track=1s", "GET api/track=1s", "GET /api/track", "GET /api/track=fast", "GET /api/track=-1s"} {
+		_, err := ParseRouteBudgets(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// logLevels holds the default log level and the level overrides of modules. Both can be changed
+// at runtime, so loggers consult them for every entry instead of having a fixed level.
+var logLevels = struct {
+	sync.RWMutex
+	base    zerolog.Level
+	modules map[string]zerolog.Level
+}{base: zerolog.InfoLevel, modules: map[string]zerolog.Level{}}
+
+// moduleLevelFilter is a hook that discards entries below the current level of a module, the
+// empty module stands for the default level
+type moduleLevelFilter string
+
+func (module moduleLevelFilter) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level < moduleLevel(string(module)) {
+		e.Discard()
+	}
+}
+
+func moduleLevel(module string) zerolog.Level {
+	logLevels.RLock()
+	defer logLevels.RUnlock()
+
+	if level, ok := logLevels.modules[module]; ok {
+		return level
+	}
+	return logLevels.base
+}
+
+// ModuleLogger returns a logger that tags entries with the module and filters them by the level
+// set for it, falling back to the default level
+func ModuleLogger(module string) zerolog.Logger {
+	return baseLogger.With().Str("module", module).Logger().Hook(moduleLevelFilter(module))
+}
+
+// SetLogLevel changes the level of a module at runtime, the empty module changes the default level
+func SetLogLevel(module string, level zerolog.Level) {
+	logLevels.Lock()
+	defer logLevels.Unlock()
+
+	if module == "" {
+		logLevels.base = level
+	} else {
+		logLevels.modules[module] = level
+	}
+	updateGlobalLevel()
+}
+
+// ResetLogLevel removes the level override of a module, so it follows the default level again
+func ResetLogLevel(module string) {
+	logLevels.Lock()
+	defer logLevels.Unlock()
+
+	delete(logLevels.modules, module)
+	updateGlobalLevel()
+}
+
+// LogLevels returns the default level and the level overrides of modules
+func LogLevels() (zerolog.Level, map[string]zerolog.Level) {
+	logLevels.RLock()
+	defer logLevels.RUnlock()
+
+	modules := make(map[string]zerolog.Level, len(logLevels.modules))
+	for module, level := range logLevels.modules {
+		modules[module] = level
+	}
+	return logLevels.base, modules
+}
+
+// ParseModuleLevels parses comma-separated module=level pairs such as "security=debug,http=warn"
+func ParseModuleLevels(value string) (map[string]zerolog.Level, error) {
+	levels := map[string]zerolog.Level{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		module, name, ok := strings.Cut(pair, "=")
+		module = strings.TrimSpace(module)
+		if !ok || module == "" {
+			return nil, fmt.Errorf("invalid module level %q, expected module=level", pair)
+		}
+
+		level, err := parseLogLevel(name)
+		if err != nil {
+			return nil, err
+		}
+		levels[module] = level
+	}
+	return levels, nil
+}
+
+func parseLogLevel(name string) (zerolog.Level, error) {
+	level, err := zerolog.ParseLevel(strings.ToLower(strings.TrimSpace(name)))
+	if err != nil || name == "" || level == zerolog.NoLevel {
+		return zerolog.NoLevel, fmt.Errorf("unknown log level %q", name)
+	}
+	return level, nil
+}
+
+// updateGlobalLevel lowers zerolog's global level to the most verbose configured level, entries
+// below it are dropped before their fields are built. Callers hold the write lock.
+func updateGlobalLevel() {
+	lowest := logLevels.base
+	for _, level := range logLevels.modules {
+		if level < lowest {
+			lowest = level
+		}
+	}
+	zerolog.SetGlobalLevel(lowest)
+}
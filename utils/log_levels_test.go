@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/constants"
+)
+
+type testLogConfig struct {
+	options LogOptions
+}
+
+func (c testLogConfig) IsDevelopmentMode() bool { return false }
+func (c testLogConfig) LogOptions() LogOptions  { return c.options }
+
+func TestParseModuleLevels(t *testing.T) {
+	levels, err := ParseModuleLevels(" security=debug, http=WARN ,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]zerolog.Level{"security": zerolog.DebugLevel, "http": zerolog.WarnLevel}, levels)
+
+	levels, err = ParseModuleLevels("")
+	assert.NoError(t, err)
+	assert.Empty(t, levels)
+
+	for _, invalid := range []string{"security", "=debug", "security=loud", "security="} {
+		_, err := ParseModuleLevels(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestModuleLogLevels(t *testing.T) {
+	logger := Logger
+	t.Cleanup(func() {
+		Logger = logger
+		ResetLogLevel(constants.LogModuleSecurity)
+		SetLogLevel("", zerolog.InfoLevel)
+	})
+
+	var buf bytes.Buffer
+	InitLogger(testLogConfig{LogOptions{ModuleLevels: "security=debug"}}, &buf)
+
+	buf.Reset()
+	LogDebug().Msg("default debug")
+	securityLogger.Debug().Msg("security debug")
+	assert.NotContains(t, buf.String(), "default debug")
+	assert.Contains(t, buf.String(), "security debug")
+	assert.Contains(t, buf.String(), `"module":"security"`)
+
+	// Raising the default level at runtime leaves the module override in place
+	SetLogLevel("", zerolog.ErrorLevel)
+	buf.Reset()
+	LogWarn().Msg("default warning")
+	securityLogger.Debug().Msg("security debug")
+	assert.NotContains(t, buf.String(), "default warning")
+	assert.Contains(t, buf.String(), "security debug")
+
+	ResetLogLevel(constants.LogModuleSecurity)
+	buf.Reset()
+	securityLogger.Warn().Msg("security warning")
+	assert.Empty(t, buf.String())
+
+	base, modules := LogLevels()
+	assert.Equal(t, zerolog.ErrorLevel, base)
+	assert.Empty(t, modules)
+}
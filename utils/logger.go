@@ -1,54 +1,96 @@
 package utils
 
 import (
+	"fmt"
 	"io"
+	"log/syslog"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"vibe-tracker/constants"
 )
 
 // AppConfigProvider defines the methods of config.AppConfig that InitLogger uses
 type AppConfigProvider interface {
 	IsDevelopmentMode() bool
+	LogOptions() LogOptions
+}
+
+// LogOptions selects the format, outputs and levels of the logger
+type LogOptions struct {
+	Format       string // json or pretty
+	Outputs      []string
+	Level        string // Default level; empty picks debug in development and info in production
+	ModuleLevels string // Comma-separated module=level overrides
+
+	// Rotating log file
+	FilePath       string
+	FileMaxSizeMB  int
+	FileMaxAgeDays int
+	FileMaxBackups int
+
+	// Syslog; an empty address logs to the local syslog daemon
+	SyslogAddress string
+	SyslogTag     string
 }
 
 var Logger zerolog.Logger
 
+var (
+	baseLogger     zerolog.Logger // Logger without level filter, module loggers add their own
+	httpLogger     zerolog.Logger
+	securityLogger zerolog.Logger
+)
+
 // InitLogger initializes the global structured logger
 func InitLogger(cfg AppConfigProvider, outputWriter ...io.Writer) {
-	var output io.Writer
+	options := cfg.LogOptions()
 
+	var output io.Writer
+	var outputErrs []error
 	if len(outputWriter) > 0 && outputWriter[0] != nil {
 		output = outputWriter[0] // Always use provided writer if available
 	} else {
-		// Original logic for development/production mode
-		if cfg.IsDevelopmentMode() {
-			output = zerolog.ConsoleWriter{
-				Out:        os.Stdout,
-				TimeFormat: time.RFC3339,
-			}
-		} else {
-			output = os.Stdout // Default to JSON output for production
-		}
+		output, outputErrs = logOutputs(options)
 	}
 
-	// Set global log level
-	var level zerolog.Level
+	// Set default level and module overrides, invalid values are reported once the logger works
+	level := zerolog.InfoLevel
 	if cfg.IsDevelopmentMode() {
 		level = zerolog.DebugLevel
-	} else {
-		level = zerolog.InfoLevel
+	}
+	var levelErrs []error
+	if options.Level != "" {
+		if parsed, err := parseLogLevel(options.Level); err == nil {
+			level = parsed
+		} else {
+			levelErrs = append(levelErrs, err)
+		}
+	}
+	moduleLevels, err := ParseModuleLevels(options.ModuleLevels)
+	if err != nil {
+		levelErrs = append(levelErrs, err)
 	}
 
-	// Initialize logger
-	Logger = zerolog.New(output).
-		Level(level).
+	SetLogLevel("", level)
+	for module, moduleLevel := range moduleLevels {
+		SetLogLevel(module, moduleLevel)
+	}
+
+	// Initialize logger; levels are checked per entry so they can change at runtime
+	baseLogger = zerolog.New(output).
 		With().
 		Timestamp().
 		Caller().
 		Logger()
+	Logger = baseLogger.Hook(moduleLevelFilter(""))
+	httpLogger = ModuleLogger(constants.LogModuleHTTP)
+	securityLogger = ModuleLogger(constants.LogModuleSecurity)
 
 	// Set as global logger
 	log.Logger = Logger
@@ -58,6 +100,79 @@ func InitLogger(cfg AppConfigProvider, outputWriter ...io.Writer) {
 		Str("level", level.String()).
 		Bool("development", cfg.IsDevelopmentMode()).
 		Msg("Logger initialized")
+
+	for _, err := range outputErrs {
+		Logger.Warn().Err(err).Msg("Log output disabled")
+	}
+	for _, err := range levelErrs {
+		Logger.Warn().Err(err).Msg("Invalid log level ignored")
+	}
+}
+
+// logOutputs builds the writers of the configured outputs. Outputs that cannot be opened are
+// skipped, stdout is used when none is left.
+func logOutputs(options LogOptions) (io.Writer, []error) {
+	var writers []io.Writer
+	var errs []error
+
+	for _, output := range options.Outputs {
+		switch strings.ToLower(strings.TrimSpace(output)) {
+		case constants.LogOutputStdout:
+			writers = append(writers, formatLogOutput(os.Stdout, options.Format, true))
+		case constants.LogOutputFile:
+			writers = append(writers, formatLogOutput(&lumberjack.Logger{
+				Filename:   options.FilePath,
+				MaxSize:    options.FileMaxSizeMB,
+				MaxAge:     options.FileMaxAgeDays,
+				MaxBackups: options.FileMaxBackups,
+			}, options.Format, false))
+		case constants.LogOutputSyslog:
+			writer, err := dialSyslog(options.SyslogAddress, options.SyslogTag)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("syslog: %w", err))
+				continue
+			}
+			// Syslog gets JSON entries with their level mapped to the syslog priority
+			writers = append(writers, zerolog.SyslogLevelWriter(writer))
+		case "":
+		default:
+			errs = append(errs, fmt.Errorf("unknown log output %q", output))
+		}
+	}
+
+	if len(writers) == 0 {
+		writers = append(writers, formatLogOutput(os.Stdout, options.Format, true))
+	}
+	if len(writers) == 1 {
+		return writers[0], errs
+	}
+	return zerolog.MultiLevelWriter(writers...), errs
+}
+
+// formatLogOutput wraps a writer in a console writer for the pretty format
+func formatLogOutput(w io.Writer, format string, color bool) io.Writer {
+	if format != constants.LogFormatPretty {
+		return w
+	}
+	return zerolog.ConsoleWriter{
+		Out:        w,
+		TimeFormat: time.RFC3339,
+		NoColor:    !color,
+	}
+}
+
+// dialSyslog connects to a syslog server given as network:address, or to the local daemon when
+// the address is empty
+func dialSyslog(address, tag string) (*syslog.Writer, error) {
+	if address == "" {
+		return syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	}
+
+	network, addr, ok := strings.Cut(address, ":")
+	if !ok || addr == "" {
+		return nil, fmt.Errorf("invalid syslog address %q, expected network:address", address)
+	}
+	return syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
 }
 
 // GetLogger returns the global logger instance
@@ -65,6 +180,11 @@ func GetLogger() zerolog.Logger {
 	return Logger
 }
 
+// HTTPLogger returns the logger of the http module used for request logs
+func HTTPLogger() zerolog.Logger {
+	return httpLogger
+}
+
 // LogError logs an error with context
 func LogError(err error, msg string) *zerolog.Event {
 	return Logger.Error().Err(err).Str("context", msg)
@@ -87,7 +207,7 @@ func LogWarn() *zerolog.Event {
 
 // RequestLogger creates a logger for HTTP requests
 func RequestLogger(method, path, userID string) *zerolog.Event {
-	return Logger.Info().
+	return httpLogger.Info().
 		Str("method", method).
 		Str("path", path).
 		Str("user_id", userID)
@@ -97,7 +217,7 @@ func RequestLogger(method, path, userID string) *zerolog.Event {
 
 // LogSecurityEvent logs security-related events with standardized fields
 func LogSecurityEvent(eventType, message string) *zerolog.Event {
-	return Logger.Warn().
+	return securityLogger.Warn().
 		Str("event_type", "security").
 		Str("security_event", eventType).
 		Str("message", message)
@@ -105,7 +225,7 @@ func LogSecurityEvent(eventType, message string) *zerolog.Event {
 
 // LogRateLimitViolation logs rate limit violations
 func LogRateLimitViolation(clientIP, endpoint string, limit int) {
-	Logger.Warn().
+	securityLogger.Warn().
 		Str("event_type", "security").
 		Str("security_event", "rate_limit_exceeded").
 		Str("client_ip", clientIP).
@@ -116,7 +236,7 @@ func LogRateLimitViolation(clientIP, endpoint string, limit int) {
 
 // LogBruteForceAttempt logs brute force protection events
 func LogBruteForceAttempt(clientIP string, attemptCount, maxAttempts int) {
-	Logger.Warn().
+	securityLogger.Warn().
 		Str("event_type", "security").
 		Str("security_event", "brute_force_attempt").
 		Str("client_ip", clientIP).
@@ -127,7 +247,7 @@ func LogBruteForceAttempt(clientIP string, attemptCount, maxAttempts int) {
 
 // LogBruteForceBlocked logs when a client is blocked due to brute force protection
 func LogBruteForceBlocked(clientIP string, lockedUntil time.Time) {
-	Logger.Error().
+	securityLogger.Error().
 		Str("event_type", "security").
 		Str("security_event", "brute_force_blocked").
 		Str("client_ip", clientIP).
@@ -137,7 +257,7 @@ func LogBruteForceBlocked(clientIP string, lockedUntil time.Time) {
 
 // LogSuspiciousRequest logs suspicious request patterns
 func LogSuspiciousRequest(clientIP, userAgent, path, reason string) {
-	Logger.Error().
+	securityLogger.Error().
 		Str("event_type", "security").
 		Str("security_event", "suspicious_request").
 		Str("client_ip", clientIP).
@@ -149,7 +269,7 @@ func LogSuspiciousRequest(clientIP, userAgent, path, reason string) {
 
 // LogSecurityViolation logs general security violations
 func LogSecurityViolation(clientIP, violationType, details string) {
-	Logger.Error().
+	securityLogger.Error().
 		Str("event_type", "security").
 		Str("security_event", "security_violation").
 		Str("client_ip", clientIP).
@@ -160,7 +280,7 @@ func LogSecurityViolation(clientIP, violationType, details string) {
 
 // LogAuthenticationFailure logs authentication failures with context
 func LogAuthenticationFailure(clientIP, username, reason string) {
-	Logger.Warn().
+	securityLogger.Warn().
 		Str("event_type", "security").
 		Str("security_event", "auth_failure").
 		Str("client_ip", clientIP).
@@ -171,7 +291,7 @@ func LogAuthenticationFailure(clientIP, username, reason string) {
 
 // LogUnauthorizedAccess logs unauthorized access attempts
 func LogUnauthorizedAccess(clientIP, path, userID, reason string) {
-	Logger.Warn().
+	securityLogger.Warn().
 		Str("event_type", "security").
 		Str("security_event", "unauthorized_access").
 		Str("client_ip", clientIP).
@@ -183,7 +303,7 @@ func LogUnauthorizedAccess(clientIP, path, userID, reason string) {
 
 // LogFileUploadViolation logs file upload security violations
 func LogFileUploadViolation(clientIP, filename, violationType string) {
-	Logger.Error().
+	securityLogger.Error().
 		Str("event_type", "security").
 		Str("security_event", "file_upload_violation").
 		Str("client_ip", clientIP).
@@ -191,3 +311,30 @@ func LogFileUploadViolation(clientIP, filename, violationType string) {
 		Str("violation_type", violationType).
 		Msg("File upload security violation")
 }
+
+// LogQuarantinedUpload logs an uploaded file rejected by the virus scan. The file is not kept, its
+// SHA-256 digest identifies it for follow-up.
+func LogQuarantinedUpload(clientIP, filename, sha256, signature string, size int64) {
+	securityLogger.Error().
+		Str("event_type", "security").
+		Str("security_event", "upload_quarantined").
+		Str("client_ip", clientIP).
+		Str("filename", filename).
+		Str("sha256", sha256).
+		Int64("size", size).
+		Str("signature", signature).
+		Msg("Infected file upload rejected")
+}
+
+// LogSuspiciousLogin logs logins flagged by anomaly detection
+func LogSuspiciousLogin(clientIP, userID, country string, asn int, reasons []string) {
+	securityLogger.Warn().
+		Str("event_type", "security").
+		Str("security_event", "suspicious_login").
+		Str("client_ip", clientIP).
+		Str("user_id", userID).
+		Str("country", country).
+		Int("asn", asn).
+		Strs("reasons", reasons).
+		Msg("Suspicious login detected")
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MatchedPosition is an input point of a map matching request snapped to a way
+type MatchedPosition struct {
+	Latitude  float64
+	Longitude float64
+	Matched   bool // False when the backend could not snap the point
+}
+
+// MatchChunks splits points into [start, end) ranges of at most size points for map matching
+// requests. Ranges never cross a segment boundary, so gaps are not matched as travel, and ranges
+// of the same segment share their boundary point so no stretch between them is lost.
+func MatchChunks(segments []int, size int) [][2]int {
+	chunks := [][2]int{}
+	if size < 2 {
+		size = 2
+	}
+
+	for start := 0; start < len(segments); {
+		end := start + 1
+		for end < len(segments) && segments[end] == segments[start] && end-start < size {
+			end++
+		}
+		chunks = append(chunks, [2]int{start, end})
+
+		if end < len(segments) && segments[end] == segments[start] {
+			end--
+		}
+		start = end
+	}
+
+	return chunks
+}
+
+// ParseValhallaMatchedPoints extracts the snapped input points from a Valhalla trace_attributes
+// response requested with the matched.* attributes
+func ParseValhallaMatchedPoints(body []byte, count int) ([]MatchedPosition, error) {
+	var response struct {
+		MatchedPoints []struct {
+			Lat  float64 `json:"lat"`
+			Lon  float64 `json:"lon"`
+			Type string  `json:"type"` // matched, interpolated or unmatched
+		} `json:"matched_points"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid trace attributes response: %w", err)
+	}
+	if len(response.MatchedPoints) != count {
+		return nil, fmt.Errorf("trace attributes response has %d matched points, expected %d", len(response.MatchedPoints), count)
+	}
+
+	positions := make([]MatchedPosition, count)
+	for i, point := range response.MatchedPoints {
+		positions[i] = MatchedPosition{
+			Latitude:  point.Lat,
+			Longitude: point.Lon,
+			Matched:   point.Type != "unmatched",
+		}
+	}
+	return positions, nil
+}
+
+// ParseOSRMTracepoints extracts the snapped input points from an OSRM match response. Points
+// OSRM dropped as outliers are unmatched, as are all points when no match was found.
+func ParseOSRMTracepoints(body []byte, count int) ([]MatchedPosition, error) {
+	var response struct {
+		Code        string `json:"code"`
+		Message     string `json:"message"`
+		Tracepoints []*struct {
+			Location []float64 `json:"location"` // [lon, lat]
+		} `json:"tracepoints"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid match response: %w", err)
+	}
+
+	positions := make([]MatchedPosition, count)
+	switch response.Code {
+	case "Ok":
+	case "NoMatch", "NoSegment":
+		return positions, nil
+	default:
+		return nil, fmt.Errorf("match failed: %s %s", response.Code, response.Message)
+	}
+
+	if len(response.Tracepoints) != count {
+		return nil, fmt.Errorf("match response has %d tracepoints, expected %d", len(response.Tracepoints), count)
+	}
+	for i, tracepoint := range response.Tracepoints {
+		if tracepoint == nil || len(tracepoint.Location) != 2 {
+			continue
+		}
+		positions[i] = MatchedPosition{
+			Latitude:  tracepoint.Location[1],
+			Longitude: tracepoint.Location[0],
+			Matched:   true,
+		}
+	}
+	return positions, nil
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchChunks(t *testing.T) {
+	t.Run("chunks of one segment overlap", func(t *testing.T) {
+		assert.Equal(t, [][2]int{{0, 3}, {2, 5}, {4, 6}}, MatchChunks([]int{0, 0, 0, 0, 0, 0}, 3))
+	})
+
+	t.Run("chunks end at gaps", func(t *testing.T) {
+		assert.Equal(t, [][2]int{{0, 2}, {2, 3}, {3, 5}}, MatchChunks([]int{0, 0, 1, 2, 2}, 10))
+	})
+
+	t.Run("empty track", func(t *testing.T) {
+		assert.Empty(t, MatchChunks(nil, 10))
+	})
+}
+
+func TestParseValhallaMatchedPoints(t *testing.T) {
+	body := []byte(`{"matched_points":[{"lat":47.1,"lon":19.1,"type":"matched"},{"lat":47.2,"lon":19.2,"type":"unmatched"}]}`)
+
+	positions, err := ParseValhallaMatchedPoints(body, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []MatchedPosition{{Latitude: 47.1, Longitude: 19.1, Matched: true}, {Latitude: 47.2, Longitude: 19.2}}, positions)
+
+	_, err = ParseValhallaMatchedPoints(body, 3)
+	assert.Error(t, err)
+}
+
+func TestParseOSRMTracepoints(t *testing.T) {
+	t.Run("outliers are unmatched", func(t *testing.T) {
+		positions, err := ParseOSRMTracepoints([]byte(`{"code":"Ok","tracepoints":[{"location":[19.1,47.1]},null]}`), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []MatchedPosition{{Latitude: 47.1, Longitude: 19.1, Matched: true}, {}}, positions)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		positions, err := ParseOSRMTracepoints([]byte(`{"code":"NoMatch","message":"Could not match the trace."}`), 2)
+		assert.NoError(t, err)
+		assert.Len(t, positions, 2)
+		assert.False(t, positions[0].Matched)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ParseOSRMTracepoints([]byte(`{"code":"TooBig"}`), 2)
+		assert.Error(t, err)
+
+		_, err = ParseOSRMTracepoints([]byte(`{"code":"Ok","tracepoints":[null]}`), 2)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"vibe-tracker/constants"
+)
+
+// MapSharePoint is a position of a Garmin inReach satellite tracker read from a MapShare feed
+type MapSharePoint struct {
+	Time      time.Time
+	Valid     bool // The device had a GPS fix
+	Latitude  float64
+	Longitude float64
+	Altitude  *float64 // Meters
+	Speed     *float64 // km/h
+	Course    *float64 // Degrees
+	Device    string   // Device type, e.g. "inReach Mini"
+}
+
+// mapShareKML is the subset of the MapShare KML feed that is read. Each message of the device is
+// a placemark with a point; the placemark with the line string of the track is skipped.
+type mapShareKML struct {
+	Placemarks []struct {
+		When        string `xml:"TimeStamp>when"`
+		Coordinates string `xml:"Point>coordinates"`
+		Data        []struct {
+			Name  string `xml:"name,attr"`
+			Value string `xml:"value"`
+		} `xml:"ExtendedData>Data"`
+	} `xml:"Document>Folder>Placemark"`
+}
+
+// ParseMapShareKML reads the positions of a MapShare KML feed in the order of the feed
+func ParseMapShareKML(r io.Reader) ([]MapSharePoint, error) {
+	var feed mapShareKML
+	if err := xml.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("invalid MapShare feed: %w", err)
+	}
+
+	var points []MapSharePoint
+	for _, placemark := range feed.Placemarks {
+		if placemark.Coordinates == "" || placemark.When == "" {
+			continue
+		}
+
+		timestamp, err := time.Parse(time.RFC3339, strings.TrimSpace(placemark.When))
+		if err != nil {
+			return nil, fmt.Errorf("invalid MapShare time %q", placemark.When)
+		}
+		// KML coordinates are longitude,latitude[,altitude]
+		coordinates := strings.Split(strings.TrimSpace(placemark.Coordinates), ",")
+		if len(coordinates) < 2 {
+			return nil, fmt.Errorf("invalid MapShare coordinates %q", placemark.Coordinates)
+		}
+		longitude, lonErr := strconv.ParseFloat(coordinates[0], 64)
+		latitude, latErr := strconv.ParseFloat(coordinates[1], 64)
+		if lonErr != nil || latErr != nil {
+			return nil, fmt.Errorf("invalid MapShare coordinates %q", placemark.Coordinates)
+		}
+
+		point := MapSharePoint{Time: timestamp.UTC(), Valid: true, Latitude: latitude, Longitude: longitude}
+		if len(coordinates) > 2 {
+			point.Altitude = parseMapShareNumber(coordinates[2])
+		}
+		for _, data := range placemark.Data {
+			switch data.Name {
+			case "Valid GPS Fix":
+				point.Valid = strings.EqualFold(strings.TrimSpace(data.Value), "true")
+			case "Velocity":
+				point.Speed = parseMapShareNumber(data.Value) // e.g. "3.0 km/h"
+			case "Course":
+				point.Course = parseMapShareNumber(data.Value) // e.g. "90.00 ° True"
+			case "Device Type":
+				point.Device = strings.TrimSpace(data.Value)
+			}
+		}
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+// parseMapShareNumber reads the number a value with a unit starts with
+func parseMapShareNumber(value string) *float64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil
+	}
+	number, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil
+	}
+	return &number
+}
+
+// MapShareFeedURL returns the KML feed URL of a MapShare page or feed URL, limited to the
+// messages since the given time. Both https://share.garmin.com/NAME and
+// https://share.garmin.com/Feed/Share/NAME are accepted, on the Garmin hosts of MapShareHosts only.
+func MapShareFeedURL(shareURL string, since time.Time) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(shareURL))
+	if err != nil || parsed.Scheme != "https" || parsed.User != nil || !IsMapShareHost(parsed.Host) {
+		return "", fmt.Errorf("invalid MapShare URL %q", shareURL)
+	}
+	parsed.Host = strings.ToLower(parsed.Host)
+
+	name := strings.Trim(parsed.Path, "/")
+	if strings.HasPrefix(strings.ToLower(name), "feed/share/") {
+		name = name[len("feed/share/"):]
+	}
+	if name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("invalid MapShare URL %q", shareURL)
+	}
+
+	parsed.Path = "/Feed/Share/" + name
+	query := url.Values{}
+	if !since.IsZero() {
+		query.Set("d1", since.UTC().Format("2006-01-02T15:04z")) // The feed takes whole minutes
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// IsMapShareHost reports whether a URL host is one of the Garmin hosts serving MapShare feeds;
+// hosts with a port are rejected
+func IsMapShareHost(host string) bool {
+	return slices.Contains(constants.MapShareHosts, strings.ToLower(host))
+}
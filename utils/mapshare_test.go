@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const mapShareFeed = `<?xml version="1.0" encoding="utf-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <name>KML Export 9/10/2025 12:00:00 PM</name>
+    <Folder>
+      <name>Jane Doe</name>
+      <Placemark>
+        <name>Jane Doe</name>
+        <TimeStamp><when>2025-09-10T11:40:00Z</when></TimeStamp>
+        <ExtendedData>
+          <Data name="Device Type"><value>inReach Mini</value></Data>
+          <Data name="Elevation"><value>1234.56 m from MSL</value></Data>
+          <Data name="Velocity"><value>4.0 km/h</value></Data>
+          <Data name="Course"><value>90.00 ° True</value></Data>
+          <Data name="Valid GPS Fix"><value>True</value></Data>
+        </ExtendedData>
+        <Point><coordinates>19.0402360,47.4979130,1234.56</coordinates></Point>
+      </Placemark>
+      <Placemark>
+        <TimeStamp><when>2025-09-10T11:50:00Z</when></TimeStamp>
+        <ExtendedData>
+          <Data name="Valid GPS Fix"><value>False</value></Data>
+        </ExtendedData>
+        <Point><coordinates>19.0402360,47.4979130,0</coordinates></Point>
+      </Placemark>
+      <Placemark>
+        <name>Jane Doe</name>
+        <LineString><coordinates>19.04,47.49 19.05,47.50</coordinates></LineString>
+      </Placemark>
+    </Folder>
+  </Document>
+</kml>`
+
+func TestParseMapShareKML(t *testing.T) {
+	points, err := ParseMapShareKML(strings.NewReader(mapShareFeed))
+	if assert.NoError(t, err) && assert.Len(t, points, 2) {
+		point := points[0]
+		assert.Equal(t, time.Date(2025, 9, 10, 11, 40, 0, 0, time.UTC), point.Time)
+		assert.True(t, point.Valid)
+		assert.Equal(t, 47.497913, point.Latitude)
+		assert.Equal(t, 19.040236, point.Longitude)
+		assert.Equal(t, 1234.56, *point.Altitude)
+		assert.Equal(t, 4.0, *point.Speed)
+		assert.Equal(t, 90.0, *point.Course)
+		assert.Equal(t, "inReach Mini", point.Device)
+
+		assert.False(t, points[1].Valid)
+		assert.Nil(t, points[1].Speed)
+	}
+
+	_, err = ParseMapShareKML(strings.NewReader("<html>Not found</html"))
+	assert.Error(t, err)
+}
+
+func TestMapShareFeedURL(t *testing.T) {
+	since := time.Date(2025, 9, 10, 11, 40, 30, 0, time.UTC)
+
+	feedURL, err := MapShareFeedURL("https://share.garmin.com/JaneDoe", since)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://share.garmin.com/Feed/Share/JaneDoe?d1=2025-09-10T11%3A40z", feedURL)
+
+	feedURL, err = MapShareFeedURL("https://share.garmin.com/Feed/Share/JaneDoe/", time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://share.garmin.com/Feed/Share/JaneDoe", feedURL)
+
+	feedURL, err = MapShareFeedURL("https://Explore.Garmin.com/JaneDoe", time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://explore.garmin.com/Feed/Share/JaneDoe", feedURL)
+
+	_, err = MapShareFeedURL("http://share.garmin.com/JaneDoe", since)
+	assert.Error(t, err)
+	for _, other := range []string{
+		"https://example.com/JaneDoe",
+		"https://share.garmin.com.example.com/JaneDoe",
+		"https://share.garmin.com:8443/JaneDoe",
+		"https://user@share.garmin.com/JaneDoe",
+		"https://127.0.0.1/JaneDoe",
+	} {
+		_, err = MapShareFeedURL(other, since)
+		assert.Error(t, err, other)
+	}
+	_, err = MapShareFeedURL("https://share.garmin.com/", since)
+	assert.Error(t, err)
+}
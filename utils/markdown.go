@@ -0,0 +1,266 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownHeading  = regexp.MustCompile(`^(#{1,6})(?:\s+(.*))?$`)
+	markdownRule     = regexp.MustCompile(`^(?:-{3,}|\*{3,}|_{3,})$`)
+	markdownListItem = regexp.MustCompile(`^(?:[-*+]|(\d{1,9})[.)])\s+(.*)$`)
+)
+
+// markdownEscapable are the characters a backslash turns into literals
+const markdownEscapable = "\\`*_[]()#+-.!>~|"
+
+// RenderMarkdown renders a description written in markdown as HTML that is safe to embed in a
+// page. Raw HTML in the source is escaped instead of passed through, links are limited to http,
+// https, mailto and relative URLs, and images are rendered as links, so the result only contains
+// the tags emitted here and no attributes but a vetted href.
+//
+// The supported subset covers paragraphs, where single line breaks are kept, headings, lists,
+// block quotes, fenced code, rules, emphasis, code spans, links and bare URLs.
+func RenderMarkdown(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+
+	var out strings.Builder
+	renderMarkdownBlocks(&out, strings.Split(source, "\n"))
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func renderMarkdownBlocks(out *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := strings.TrimSpace(lines[i])
+
+		switch {
+		case line == "":
+			i++
+
+		case strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~"):
+			fence := line[:3]
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			i++ // Closing fence
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+
+		case markdownHeading.MatchString(line):
+			match := markdownHeading.FindStringSubmatch(line)
+			level := len(match[1])
+			fmt.Fprintf(out, "<h%d>%s</h%d>\n", level, renderMarkdownInline(strings.TrimSpace(match[2]), true), level)
+			i++
+
+		case markdownRule.MatchString(line):
+			out.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(line, ">"):
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			out.WriteString("<blockquote>\n")
+			renderMarkdownBlocks(out, quoted)
+			out.WriteString("</blockquote>\n")
+
+		case markdownListItem.MatchString(line):
+			first := markdownListItem.FindStringSubmatch(line)
+			ordered := first[1] != ""
+			switch {
+			case !ordered:
+				out.WriteString("<ul>\n")
+			case strings.TrimLeft(first[1], "0") == "1":
+				out.WriteString("<ol>\n")
+			default:
+				// The start number only consists of digits
+				fmt.Fprintf(out, "<ol start=\"%s\">\n", strings.TrimLeft(first[1], "0"))
+			}
+			for ; i < len(lines); i++ {
+				item := markdownListItem.FindStringSubmatch(strings.TrimSpace(lines[i]))
+				if item == nil || (item[1] != "") != ordered {
+					break
+				}
+				out.WriteString("<li>" + renderMarkdownInline(item[2], true) + "</li>\n")
+			}
+			if ordered {
+				out.WriteString("</ol>\n")
+			} else {
+				out.WriteString("</ul>\n")
+			}
+
+		default:
+			var paragraph []string
+			for ; i < len(lines); i++ {
+				line := strings.TrimSpace(lines[i])
+				if line == "" || (len(paragraph) > 0 && markdownBlockStart(line)) {
+					break
+				}
+				paragraph = append(paragraph, renderMarkdownInline(line, true))
+			}
+			out.WriteString("<p>" + strings.Join(paragraph, "<br>\n") + "</p>\n")
+		}
+	}
+}
+
+// markdownBlockStart reports whether a line starts a block other than a paragraph
+func markdownBlockStart(line string) bool {
+	return strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") || strings.HasPrefix(line, ">") ||
+		markdownHeading.MatchString(line) || markdownRule.MatchString(line) || markdownListItem.MatchString(line)
+}
+
+// renderMarkdownInline renders the inline markup of a line; links are not rendered inside link
+// labels
+func renderMarkdownInline(text string, links bool) string {
+	var out strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte(markdownEscapable, text[i+1]) >= 0:
+			out.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(text[i+1:], '`'); end > 0 {
+				out.WriteString("<code>" + html.EscapeString(text[i+1:i+1+end]) + "</code>")
+				i += end + 2
+				continue
+			}
+
+		case c == '[' || (c == '!' && strings.HasPrefix(text[i+1:], "[")):
+			if label, target, length, ok := markdownLink(text[i:]); ok {
+				if label == "" {
+					label = target
+				}
+				switch {
+				case !links:
+					out.WriteString(renderMarkdownInline(label, false))
+				case markdownSafeURL(target):
+					out.WriteString(markdownAnchor(target, renderMarkdownInline(label, false)))
+				default:
+					out.WriteString(renderMarkdownInline(label, false))
+				}
+				i += length
+				continue
+			}
+
+		case c == '*' || c == '_':
+			if inner, length, strong, ok := markdownEmphasis(text, i); ok {
+				tag := "em"
+				if strong {
+					tag = "strong"
+				}
+				out.WriteString("<" + tag + ">" + renderMarkdownInline(inner, links) + "</" + tag + ">")
+				i += length
+				continue
+			}
+
+		case links && (c == 'h' || c == 'H') && (i == 0 || !isMarkdownWordByte(text[i-1])):
+			if target := markdownBareURL(text[i:]); target != "" {
+				out.WriteString(markdownAnchor(target, html.EscapeString(target)))
+				i += len(target)
+				continue
+			}
+		}
+
+		out.WriteString(html.EscapeString(text[i : i+1]))
+		i++
+	}
+	return out.String()
+}
+
+// markdownLink parses "[label](target)" or "![alt](target)" at the start of text
+func markdownLink(text string) (label, target string, length int, ok bool) {
+	start := strings.IndexByte(text, '[') + 1
+	closing := strings.IndexByte(text[start:], ']')
+	if closing < 0 || !strings.HasPrefix(text[start+closing+1:], "(") {
+		return "", "", 0, false
+	}
+	targetStart := start + closing + 2
+	end := strings.IndexByte(text[targetStart:], ')')
+	if end < 0 {
+		return "", "", 0, false
+	}
+
+	fields := strings.Fields(text[targetStart : targetStart+end])
+	if len(fields) == 0 {
+		return "", "", 0, false
+	}
+	return text[start : start+closing], fields[0], targetStart + end + 1, true
+}
+
+// markdownEmphasis parses "*inner*", "_inner_" or their doubled strong variants at text[i].
+// Underscores inside words, as in snake_case names, are no delimiters.
+func markdownEmphasis(text string, i int) (inner string, length int, strong bool, ok bool) {
+	delimiter := text[i : i+1]
+	if strings.HasPrefix(text[i:], delimiter+delimiter) {
+		delimiter += delimiter
+	}
+	underscore := delimiter[0] == '_'
+	if underscore && i > 0 && isMarkdownWordByte(text[i-1]) {
+		return "", 0, false, false
+	}
+
+	start := i + len(delimiter)
+	if start >= len(text) || text[start] == ' ' {
+		return "", 0, false, false
+	}
+	for j := start + 1; j+len(delimiter) <= len(text); j++ {
+		if text[j:j+len(delimiter)] != delimiter || text[j-1] == ' ' {
+			continue
+		}
+		if underscore && j+len(delimiter) < len(text) && isMarkdownWordByte(text[j+len(delimiter)]) {
+			continue
+		}
+		return text[start:j], j + len(delimiter) - i, len(delimiter) == 2, true
+	}
+	return "", 0, false, false
+}
+
+// markdownBareURL returns the http or https URL at the start of text without trailing punctuation
+func markdownBareURL(text string) string {
+	lower := strings.ToLower(text)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return ""
+	}
+
+	end := strings.IndexAny(text, " \t<>\"'`")
+	if end < 0 {
+		end = len(text)
+	}
+	target := strings.TrimRight(text[:end], ".,;:!?)]*_")
+	if !markdownSafeURL(target) || len(target) <= len("https://") {
+		return ""
+	}
+	return target
+}
+
+// markdownSafeURL allows http, https and mailto links and relative ones; javascript: and data:
+// URLs and anything url.Parse rejects, such as control characters, are dropped
+func markdownSafeURL(target string) bool {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+func markdownAnchor(target, label string) string {
+	return `<a href="` + html.EscapeString(target) + `" rel="nofollow noopener noreferrer">` + label + "</a>"
+}
+
+func isMarkdownWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"paragraphs and line breaks", "Day one\nup the hill\n\nDay two", "<p>Day one<br>\nup the hill</p>\n<p>Day two</p>"},
+		{"heading", "## Route *notes*", "<h2>Route <em>notes</em></h2>"},
+		{"hashtag is no heading", "#summit", "<p>#summit</p>"},
+		{"emphasis", "**steep** and *muddy* and __wet__", "<p><strong>steep</strong> and <em>muddy</em> and <strong>wet</strong></p>"},
+		{"snake case", "see track_name and max_speed", "<p>see track_name and max_speed</p>"},
+		{"unclosed emphasis", "5 * 3 km", "<p>5 * 3 km</p>"},
+		{"code", "run `a < b` now", "<p>run <code>a &lt; b</code> now</p>"},
+		{"fenced code", "```\n<b>x</b>\n```", "<pre><code>&lt;b&gt;x&lt;/b&gt;</code></pre>"},
+		{"unordered list", "- water\n- snacks\n\nDone", "<ul>\n<li>water</li>\n<li>snacks</li>\n</ul>\n<p>Done</p>"},
+		{"ordered list", "3. third\n4. fourth", "<ol start=\"3\">\n<li>third</li>\n<li>fourth</li>\n</ol>"},
+		{"quote", "> fast\n> and far", "<blockquote>\n<p>fast<br>\nand far</p>\n</blockquote>"},
+		{"rule", "a\n\n---", "<p>a</p>\n<hr>"},
+		{"link", "[map](https://example.com/a?b=1&c=2)", `<p><a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer">map</a></p>`},
+		{"image becomes link", "![peak](/img/peak.jpg)", `<p><a href="/img/peak.jpg" rel="nofollow noopener noreferrer">peak</a></p>`},
+		{"bare url", "photos: https://example.com/p.", `<p>photos: <a href="https://example.com/p" rel="nofollow noopener noreferrer">https://example.com/p</a>.</p>`},
+		{"escaped delimiters", `\*not\* emphasis`, "<p>*not* emphasis</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RenderMarkdown(tt.source))
+		})
+	}
+}
+
+func TestRenderMarkdownSanitizes(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"script tag", `<script>alert(1)</script>`, "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"event handler", `<img src=x onerror="alert(1)">`, "<p>&lt;img src=x onerror=&#34;alert(1)&#34;&gt;</p>"},
+		{"javascript link", "[click](javascript:alert(1))", "<p>click)</p>"},
+		{"mixed case scheme", "[click](JaVaScRiPt:alert)", "<p>click</p>"},
+		{"data link", "[x](data:text/html;base64,PHNjcmlwdD4=)", "<p>x</p>"},
+		{"attribute breakout", `[x](https://a.example/"onmouseover="alert)`, `<p><a href="https://a.example/&#34;onmouseover=&#34;alert" rel="nofollow noopener noreferrer">x</a></p>`},
+		{"html in link label", "[<b>x</b>](https://a.example)", `<p><a href="https://a.example" rel="nofollow noopener noreferrer">&lt;b&gt;x&lt;/b&gt;</a></p>`},
+		{"no nested links", "[https://a.example](https://b.example)", `<p><a href="https://b.example" rel="nofollow noopener noreferrer">https://a.example</a></p>`},
+		{"html in heading", "# <iframe>", "<h1>&lt;iframe&gt;</h1>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RenderMarkdown(tt.source))
+		})
+	}
+}
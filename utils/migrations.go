@@ -0,0 +1,18 @@
+package utils
+
+// PendingMigrations returns the registered migration files that were not applied yet, in
+// registration order
+func PendingMigrations(registered, applied []string) []string {
+	done := make(map[string]bool, len(applied))
+	for _, file := range applied {
+		done[file] = true
+	}
+
+	var pending []string
+	for _, file := range registered {
+		if !done[file] {
+			pending = append(pending, file)
+		}
+	}
+	return pending
+}
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingMigrations(t *testing.T) {
+	registered := []string{"1_init.go", "2_users.go", "3_sessions.go"}
+
+	assert.Empty(t, PendingMigrations(registered, registered))
+	assert.Equal(t, []string{"2_users.go", "3_sessions.go"}, PendingMigrations(registered, []string{"1_init.go"}))
+	// Applied migrations that are no longer registered do not count
+	assert.Equal(t, []string{"3_sessions.go"}, PendingMigrations(registered, []string{"1_init.go", "2_users.go", "0_removed.go"}))
+}
@@ -0,0 +1,42 @@
+package utils
+
+import "github.com/pocketbase/pocketbase/models"
+
+// SetRecordSpeed stores the speed of a location in m/s; nil leaves it unset. Number fields store a
+// missing speed as 0, so has_speed keeps a reported standstill apart from a missing speed.
+func SetRecordSpeed(record *models.Record, speed *float64) {
+	if speed == nil {
+		return
+	}
+	record.Set("speed", *speed)
+	record.Set("has_speed", true)
+}
+
+// RecordSpeed returns the speed of a location in m/s, or nil when none was reported or derived
+func RecordSpeed(record *models.Record) *float64 {
+	if !record.GetBool("has_speed") {
+		return nil
+	}
+	speed := record.GetFloat("speed")
+	return &speed
+}
+
+// SetRecordBearing stores the direction of travel of a location; nil leaves it unset. The
+// has_bearing flag keeps a heading due north, 0°, apart from a missing bearing.
+func SetRecordBearing(record *models.Record, bearing *float64) {
+	if bearing == nil {
+		return
+	}
+	record.Set("bearing", *bearing)
+	record.Set("has_bearing", true)
+}
+
+// RecordBearing returns the direction of travel of a location, or nil when none was reported or
+// derived
+func RecordBearing(record *models.Record) *float64 {
+	if !record.GetBool("has_bearing") {
+		return nil
+	}
+	bearing := record.GetFloat("bearing")
+	return &bearing
+}
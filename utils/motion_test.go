@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordMotion(t *testing.T) {
+	t.Run("Standstill heading north", func(t *testing.T) {
+		record := models.NewRecord(&models.Collection{})
+		standstill, north := 0.0, 0.0
+		SetRecordSpeed(record, &standstill)
+		SetRecordBearing(record, &north)
+
+		if speed := RecordSpeed(record); assert.NotNil(t, speed) {
+			assert.Zero(t, *speed)
+		}
+		if bearing := RecordBearing(record); assert.NotNil(t, bearing) {
+			assert.Zero(t, *bearing)
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		record := models.NewRecord(&models.Collection{})
+		SetRecordSpeed(record, nil)
+		SetRecordBearing(record, nil)
+
+		assert.Nil(t, RecordSpeed(record))
+		assert.Nil(t, RecordBearing(record))
+	})
+}
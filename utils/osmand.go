@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/models"
+)
+
+// osmAndDateLayouts are the date formats of timestamps that are not Unix times
+var osmAndDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+
+// OsmAndLocation converts a position sent with the osmAnd/Traccar protocol to a tracked point of
+// the given device. The position is either in lat and lon or in location; an alarm is tracked as
+// an alarm event.
+func OsmAndLocation(query *models.OsmAndQueryParams, device string) (*models.TrackingQueryParams, error) {
+	params := &models.TrackingQueryParams{
+		Token:     constants.OsmAndSource, // Authenticated already, the token is only an identifier here
+		Altitude:  query.Altitude,
+		Speed:     query.Speed,
+		SpeedUnit: query.SpeedUnit,
+		Accuracy:  query.Accuracy,
+		Device:    device,
+		Session:   query.Session,
+	}
+	if params.SpeedUnit == "" {
+		params.SpeedUnit = constants.OsmAndSpeedUnit
+	}
+	if query.Battery != nil && *query.Battery >= 0 && *query.Battery <= 100 {
+		params.Battery = query.Battery // Some trackers report -1 or a voltage instead of a percentage
+	}
+	if query.Alarm != "" {
+		params.Event = constants.TrackerEventAlarm
+	}
+	if query.Bearing != nil && *query.Bearing >= 0 && *query.Bearing <= 360 {
+		params.Bearing = query.Bearing
+	}
+
+	switch {
+	case query.Latitude != nil && query.Longitude != nil:
+		params.Latitude, params.Longitude = *query.Latitude, *query.Longitude
+	case query.Location != "":
+		latitude, longitude, found := strings.Cut(query.Location, ",")
+		if !found {
+			return nil, errors.New("location must be latitude,longitude")
+		}
+		var err error
+		if params.Latitude, err = strconv.ParseFloat(strings.TrimSpace(latitude), 64); err != nil {
+			return nil, errors.New("location must be latitude,longitude")
+		}
+		if params.Longitude, err = strconv.ParseFloat(strings.TrimSpace(longitude), 64); err != nil {
+			return nil, errors.New("location must be latitude,longitude")
+		}
+	default:
+		return nil, errors.New("lat and lon or location are required")
+	}
+
+	if query.Timestamp != "" {
+		timestamp, err := parseOsmAndTimestamp(query.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		params.Timestamp = timestamp
+	}
+
+	return params, nil
+}
+
+// parseOsmAndTimestamp reads Unix seconds, Unix milliseconds (sent by OsmAnd) or a UTC date
+func parseOsmAndTimestamp(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return webhookTimestamp(number)
+	}
+	for _, layout := range osmAndDateLayouts {
+		if timestamp, err := time.Parse(layout, value); err == nil {
+			return timestamp.Unix(), nil
+		}
+	}
+	return 0, errors.New("timestamp must be Unix time or a date such as 2006-01-02 15:04:05")
+}
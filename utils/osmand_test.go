@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/models"
+)
+
+func TestOsmAndLocation(t *testing.T) {
+	latitude, longitude, speed, battery, bearing := 47.51, 18.93, 10.0, 81.0, 275.5
+	params, err := OsmAndLocation(&models.OsmAndQueryParams{
+		ID:        "123456",
+		Latitude:  &latitude,
+		Longitude: &longitude,
+		Timestamp: "1757505600",
+		Speed:     &speed,
+		Bearing:   &bearing,
+		Battery:   &battery,
+		Session:   "commute",
+	}, "123456")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 47.51, params.Latitude)
+		assert.Equal(t, 18.93, params.Longitude)
+		assert.Equal(t, int64(1757505600), params.Timestamp)
+		assert.Equal(t, 10.0, *params.Speed)
+		assert.Equal(t, "knots", params.SpeedUnit)
+		assert.Equal(t, 81.0, *params.Battery)
+		assert.Equal(t, 275.5, *params.Bearing)
+		assert.Equal(t, "123456", params.Device)
+		assert.Equal(t, "commute", params.Session)
+		assert.Equal(t, "", params.Event)
+		assert.NoError(t, ValidateStruct(params))
+	}
+
+	battery, bearing = -1, -1
+	params, err = OsmAndLocation(&models.OsmAndQueryParams{
+		Location:  "47.51, 18.93",
+		Timestamp: "1757505600000",
+		SpeedUnit: "m/s",
+		Bearing:   &bearing,
+		Battery:   &battery,
+		Alarm:     "sos",
+	}, "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 47.51, params.Latitude)
+		assert.Equal(t, 18.93, params.Longitude)
+		assert.Equal(t, int64(1757505600), params.Timestamp)
+		assert.Equal(t, "m/s", params.SpeedUnit)
+		assert.Nil(t, params.Battery)
+		assert.Nil(t, params.Bearing)
+		assert.Equal(t, "alarm", params.Event)
+	}
+
+	_, err = OsmAndLocation(&models.OsmAndQueryParams{Latitude: &latitude}, "")
+	assert.Error(t, err)
+	_, err = OsmAndLocation(&models.OsmAndQueryParams{Location: "47.51"}, "")
+	assert.Error(t, err)
+	_, err = OsmAndLocation(&models.OsmAndQueryParams{Location: "47.51,east"}, "")
+	assert.Error(t, err)
+}
+
+func TestParseOsmAndTimestamp(t *testing.T) {
+	for _, value := range []string{"1757505600", "1757505600000", "2025-09-10T12:00:00Z", "2025-09-10T12:00:00", "2025-09-10 12:00:00"} {
+		timestamp, err := parseOsmAndTimestamp(value)
+		if assert.NoError(t, err, value) {
+			assert.Equal(t, int64(1757505600), timestamp, value)
+		}
+	}
+
+	_, err := parseOsmAndTimestamp("yesterday")
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"strings"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/models"
+)
+
+// OwnTracksDevice returns the device an OwnTracks message was sent from: the device ID header of
+// HTTP mode, else the last level of the MQTT style topic, else the two letter tracker ID
+func OwnTracksDevice(deviceHeader string, message *models.OwnTracksMessage) string {
+	if device := strings.TrimSpace(deviceHeader); device != "" {
+		return device
+	}
+	if levels := strings.Split(message.Topic, "/"); len(levels) > 2 && levels[len(levels)-1] != "" {
+		return levels[len(levels)-1]
+	}
+	return message.TrackerID
+}
+
+// OwnTracksLocation converts an OwnTracks location message to a tracked point. Points go to the
+// given session, else to a session named after the device; the first region the device is in is
+// tracked as the point status.
+func OwnTracksLocation(message *models.OwnTracksMessage, device, session string) *models.TrackingQueryParams {
+	if session == "" {
+		session = SanitizeSessionName(device)
+		if len(session) > 100 {
+			session = session[:100]
+		}
+	}
+
+	params := &models.TrackingQueryParams{
+		Token:     constants.OwnTracksSource, // Authenticated already, the token is only an identifier here
+		Latitude:  message.Latitude,
+		Longitude: message.Longitude,
+		Timestamp: message.Timestamp,
+		Altitude:  message.Altitude,
+		Speed:     message.Velocity,
+		SpeedUnit: constants.OwnTracksSpeedUnit,
+		Accuracy:  message.Accuracy,
+		Battery:   message.Battery,
+		Device:    device,
+		Session:   session,
+	}
+	if len(message.InRegions) > 0 {
+		params.Status = message.InRegions[0]
+	}
+	// The iOS app reports -1 when the course or vertical accuracy is unknown
+	if message.Course != nil && *message.Course >= 0 {
+		params.Bearing = message.Course
+	}
+	if message.VAccuracy != nil && *message.VAccuracy >= 0 {
+		params.VerticalAccuracy = message.VAccuracy
+	}
+	return params
+}
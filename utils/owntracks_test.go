@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/models"
+)
+
+func TestOwnTracksDevice(t *testing.T) {
+	message := &models.OwnTracksMessage{Topic: "owntracks/ann/pixel", TrackerID: "px"}
+	assert.Equal(t, "phone", OwnTracksDevice(" phone ", message))
+	assert.Equal(t, "pixel", OwnTracksDevice("", message))
+
+	message.Topic = "owntracks/ann/"
+	assert.Equal(t, "px", OwnTracksDevice("", message))
+	assert.Equal(t, "", OwnTracksDevice("", &models.OwnTracksMessage{}))
+}
+
+func TestOwnTracksLocation(t *testing.T) {
+	altitude, battery, velocity, course, verticalAccuracy := 0.0, 81.0, 36.0, 120.0, 4.0
+	message := &models.OwnTracksMessage{
+		Type:      "location",
+		Timestamp: 1757505600,
+		Latitude:  47.51,
+		Longitude: 18.93,
+		Altitude:  &altitude,
+		VAccuracy: &verticalAccuracy,
+		Course:    &course,
+		Battery:   &battery,
+		Velocity:  &velocity,
+		InRegions: []string{"home", "street"},
+	}
+
+	params := OwnTracksLocation(message, "Ann's phone", "")
+	assert.Equal(t, 47.51, params.Latitude)
+	assert.Equal(t, 18.93, params.Longitude)
+	assert.Equal(t, int64(1757505600), params.Timestamp)
+	assert.Equal(t, 0.0, *params.Altitude)
+	assert.Equal(t, 81.0, *params.Battery)
+	assert.Equal(t, 36.0, *params.Speed)
+	assert.Equal(t, "km/h", params.SpeedUnit)
+	assert.Equal(t, 120.0, *params.Bearing)
+	assert.Equal(t, 4.0, *params.VerticalAccuracy)
+	assert.Equal(t, "Ann's phone", params.Device)
+	assert.Equal(t, "Ann_s_phone", params.Session)
+	assert.Equal(t, "home", params.Status)
+	assert.NoError(t, ValidateStruct(params))
+
+	params = OwnTracksLocation(message, "Ann's phone", "hike")
+	assert.Equal(t, "hike", params.Session)
+
+	course, verticalAccuracy = -1, -1 // Unknown
+	params = OwnTracksLocation(message, "Ann's phone", "")
+	assert.Nil(t, params.Bearing)
+	assert.Nil(t, params.VerticalAccuracy)
+
+	params = OwnTracksLocation(&models.OwnTracksMessage{Type: "location", Latitude: 47.51, Longitude: 18.93}, "", "")
+	assert.Equal(t, "", params.Session) // The automatic session applies
+	assert.Equal(t, "", params.Status)
+	assert.NoError(t, ValidateStruct(params))
+}
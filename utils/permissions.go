@@ -0,0 +1,99 @@
+package utils
+
+import "vibe-tracker/constants"
+
+// PermissionScope describes which resources a permission applies to
+type PermissionScope int
+
+const (
+	ScopeNone PermissionScope = iota // Permission not granted
+	ScopeOwn                         // Granted on resources owned by the user
+	ScopeAny                         // Granted on every user's resources
+)
+
+// RolePermissions declares the permissions of each user role; anything not listed is denied
+var RolePermissions = map[string]map[string]PermissionScope{
+	constants.RoleAdmin: {
+		constants.PermSessionsRead:        ScopeAny,
+		constants.PermSessionsWrite:       ScopeAny,
+		constants.PermWaypointsRead:       ScopeAny,
+		constants.PermWaypointsWrite:      ScopeAny,
+		constants.PermTrackingWrite:       ScopeOwn,
+		constants.PermCommunityPublish:    ScopeOwn,
+		constants.PermCommunityModerate:   ScopeAny,
+		constants.PermOrganizationsCreate: ScopeAny,
+		constants.PermUsersManage:         ScopeAny,
+		constants.PermContentReport:       ScopeAny,
+		constants.PermReportsReview:       ScopeAny,
+		constants.PermSystemManage:        ScopeAny,
+	},
+	constants.RoleModerator: {
+		constants.PermSessionsRead:        ScopeOwn,
+		constants.PermSessionsWrite:       ScopeOwn,
+		constants.PermWaypointsRead:       ScopeOwn,
+		constants.PermWaypointsWrite:      ScopeOwn,
+		constants.PermTrackingWrite:       ScopeOwn,
+		constants.PermCommunityPublish:    ScopeOwn,
+		constants.PermCommunityModerate:   ScopeAny,
+		constants.PermOrganizationsCreate: ScopeAny,
+		constants.PermContentReport:       ScopeAny,
+		constants.PermReportsReview:       ScopeAny,
+	},
+	constants.RoleUser: {
+		constants.PermSessionsRead:        ScopeOwn,
+		constants.PermSessionsWrite:       ScopeOwn,
+		constants.PermWaypointsRead:       ScopeOwn,
+		constants.PermWaypointsWrite:      ScopeOwn,
+		constants.PermTrackingWrite:       ScopeOwn,
+		constants.PermCommunityPublish:    ScopeOwn,
+		constants.PermCommunityModerate:   ScopeOwn,
+		constants.PermOrganizationsCreate: ScopeAny,
+		constants.PermContentReport:       ScopeAny,
+	},
+	constants.RoleReadonly: {
+		constants.PermSessionsRead:  ScopeOwn,
+		constants.PermWaypointsRead: ScopeOwn,
+		constants.PermContentReport: ScopeAny,
+	},
+}
+
+// IsValidRole checks if a role is one of the declared user roles
+func IsValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// NormalizeRole maps a stored role to a declared one; users created before roles existed
+// have no role and are treated as regular users, unknown values get read-only access
+func NormalizeRole(role string) string {
+	if role == "" {
+		return constants.RoleUser
+	}
+	if !IsValidRole(role) {
+		return constants.RoleReadonly
+	}
+	return role
+}
+
+// GetPermissionScope returns the scope in which a role holds a permission
+func GetPermissionScope(role, permission string) PermissionScope {
+	return RolePermissions[NormalizeRole(role)][permission]
+}
+
+// HasPermission reports whether a role holds a permission in any scope
+func HasPermission(role, permission string) bool {
+	return GetPermissionScope(role, permission) != ScopeNone
+}
+
+// CanAccess reports whether a user with the given role and ID may use a permission
+// on a resource owned by ownerID
+func CanAccess(role, permission, userID, ownerID string) bool {
+	switch GetPermissionScope(role, permission) {
+	case ScopeAny:
+		return true
+	case ScopeOwn:
+		return userID != "" && userID == ownerID
+	default:
+		return false
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/constants"
+)
+
+func TestNormalizeRole(t *testing.T) {
+	assert.Equal(t, constants.RoleUser, NormalizeRole(""))
+	assert.Equal(t, constants.RoleAdmin, NormalizeRole(constants.RoleAdmin))
+	assert.Equal(t, constants.RoleModerator, NormalizeRole(constants.RoleModerator))
+	assert.Equal(t, constants.RoleReadonly, NormalizeRole("superuser"))
+}
+
+func TestHasPermission(t *testing.T) {
+	assert.True(t, HasPermission(constants.RoleUser, constants.PermSessionsWrite))
+	assert.True(t, HasPermission("", constants.PermTrackingWrite))
+	assert.False(t, HasPermission(constants.RoleReadonly, constants.PermSessionsWrite))
+	assert.False(t, HasPermission(constants.RoleReadonly, constants.PermTrackingWrite))
+	assert.False(t, HasPermission(constants.RoleModerator, constants.PermUsersManage))
+	assert.True(t, HasPermission(constants.RoleAdmin, constants.PermUsersManage))
+	assert.True(t, HasPermission(constants.RoleReadonly, constants.PermContentReport))
+	assert.True(t, HasPermission(constants.RoleModerator, constants.PermReportsReview))
+	assert.False(t, HasPermission(constants.RoleUser, constants.PermReportsReview))
+	assert.True(t, HasPermission(constants.RoleAdmin, constants.PermSystemManage))
+	assert.False(t, HasPermission(constants.RoleModerator, constants.PermSystemManage))
+	assert.False(t, HasPermission(constants.RoleUser, "unknown:permission"))
+}
+
+func TestCanAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		permission string
+		userID     string
+		ownerID    string
+		expected   bool
+	}{
+		{"User on own session", constants.RoleUser, constants.PermSessionsWrite, "u1", "u1", true},
+		{"User on other's session", constants.RoleUser, constants.PermSessionsWrite, "u1", "u2", false},
+		{"Admin on other's session", constants.RoleAdmin, constants.PermSessionsWrite, "a1", "u2", true},
+		{"Readonly reads own session", constants.RoleReadonly, constants.PermSessionsRead, "u1", "u1", true},
+		{"Readonly writes own session", constants.RoleReadonly, constants.PermSessionsWrite, "u1", "u1", false},
+		{"Moderator moderates any community waypoint", constants.RoleModerator, constants.PermCommunityModerate, "m1", "u2", true},
+		{"User moderates own community waypoint", constants.RoleUser, constants.PermCommunityModerate, "u1", "u1", true},
+		{"Admin publishes only own waypoints", constants.RoleAdmin, constants.PermCommunityPublish, "a1", "u2", false},
+		{"Anonymous never owns resources", constants.RoleUser, constants.PermSessionsRead, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CanAccess(tt.role, tt.permission, tt.userID, tt.ownerID))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"time"
+
+	"vibe-tracker/constants"
+)
+
+// PositionFix is a recorded position reduced to what position selection needs
+type PositionFix struct {
+	Timestamp time.Time
+	Accuracy  float64 // Horizontal accuracy radius in meters, 0 when unknown
+	Device    string
+}
+
+// SelectPosition returns the index of the fix that represents the current position according to
+// the policy. The first fix must be the newest, as recorded. Accuracy-based policies only consider
+// fixes within window of its timestamp and fall back to it when none reports accuracy.
+func SelectPosition(fixes []PositionFix, policy string, window time.Duration) int {
+	if len(fixes) == 0 {
+		return -1
+	}
+	if policy != constants.LocationPolicyMostAccurate && policy != constants.LocationPolicyPerDevice {
+		return 0
+	}
+
+	cutoff := fixes[0].Timestamp.Add(-window)
+	seenDevices := make(map[string]bool)
+	best := 0
+	for i, fix := range fixes {
+		if fix.Timestamp.Before(cutoff) {
+			continue
+		}
+
+		// Older fixes of a device are superseded by its latest one
+		if policy == constants.LocationPolicyPerDevice {
+			if seenDevices[fix.Device] {
+				continue
+			}
+			seenDevices[fix.Device] = true
+		}
+
+		if fix.Accuracy > 0 && (fixes[best].Accuracy <= 0 || fix.Accuracy < fixes[best].Accuracy) {
+			best = i
+		}
+	}
+
+	return best
+}
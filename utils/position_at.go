@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"time"
+
+	"vibe-tracker/constants"
+)
+
+// PositionAt estimates where a track was at the given time from the recorded points just before
+// and after it, either of which may be missing. Between two points of the same segment the
+// position is interpolated linearly. Across a gap, or with only one side recorded, the closer
+// point is used when it is at most maxOffset away in time; otherwise ok is false.
+func PositionAt(before, after *TimedPoint, at time.Time, thresholds GapThresholds, maxOffset time.Duration) (position TimedPoint, method string, ok bool) {
+	if before != nil && before.Timestamp.Equal(at) {
+		return *before, constants.PositionMethodExact, true
+	}
+	if after != nil && after.Timestamp.Equal(at) {
+		return *after, constants.PositionMethodExact, true
+	}
+
+	if before != nil && after != nil && !thresholds.IsGap(*before, *after) {
+		frame := interpolateFrame(0, at, []TimedPoint{*before, *after}, 0)
+		return TimedPoint{Timestamp: at, Latitude: frame.Latitude, Longitude: frame.Longitude, Altitude: frame.Altitude},
+			constants.PositionMethodInterpolated, true
+	}
+
+	nearest := before
+	if after != nil && (before == nil || after.Timestamp.Sub(at) < at.Sub(before.Timestamp)) {
+		nearest = after
+	}
+	if nearest == nil || nearest.Timestamp.Sub(at).Abs() > maxOffset {
+		return TimedPoint{}, "", false
+	}
+	return *nearest, constants.PositionMethodNearest, true
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/constants"
+)
+
+func TestPositionAt(t *testing.T) {
+	start := time.Date(2025, 9, 10, 8, 0, 0, 0, time.UTC)
+	altitude := func(meters float64) *float64 { return &meters }
+	before := &TimedPoint{Timestamp: start, Latitude: 47.0, Longitude: 19.0, Altitude: altitude(100)}
+	after := &TimedPoint{Timestamp: start.Add(time.Minute), Latitude: 47.001, Longitude: 19.002, Altitude: altitude(110)}
+	thresholds := GapThresholds{MaxInterval: 10 * time.Minute}
+
+	t.Run("exact", func(t *testing.T) {
+		position, method, ok := PositionAt(before, after, start.Add(time.Minute), thresholds, time.Minute)
+		assert.True(t, ok)
+		assert.Equal(t, constants.PositionMethodExact, method)
+		assert.Equal(t, *after, position)
+	})
+
+	t.Run("interpolated", func(t *testing.T) {
+		at := start.Add(15 * time.Second)
+		position, method, ok := PositionAt(before, after, at, thresholds, time.Minute)
+		assert.True(t, ok)
+		assert.Equal(t, constants.PositionMethodInterpolated, method)
+		assert.Equal(t, at, position.Timestamp)
+		assert.InDelta(t, 47.00025, position.Latitude, 1e-9)
+		assert.InDelta(t, 19.0005, position.Longitude, 1e-9)
+		if assert.NotNil(t, position.Altitude) {
+			assert.InDelta(t, 102.5, *position.Altitude, 1e-9)
+		}
+	})
+
+	t.Run("nearest across a gap", func(t *testing.T) {
+		later := &TimedPoint{Timestamp: start.Add(time.Hour), Latitude: 48.0, Longitude: 20.0}
+		position, method, ok := PositionAt(before, later, start.Add(2*time.Minute), thresholds, 5*time.Minute)
+		assert.True(t, ok)
+		assert.Equal(t, constants.PositionMethodNearest, method)
+		assert.Equal(t, *before, position)
+
+		position, _, ok = PositionAt(before, later, start.Add(58*time.Minute), thresholds, 5*time.Minute)
+		assert.True(t, ok)
+		assert.Equal(t, *later, position)
+
+		_, _, ok = PositionAt(before, later, start.Add(30*time.Minute), thresholds, 5*time.Minute)
+		assert.False(t, ok)
+	})
+
+	t.Run("one side recorded", func(t *testing.T) {
+		position, method, ok := PositionAt(nil, after, start, thresholds, 5*time.Minute)
+		assert.True(t, ok)
+		assert.Equal(t, constants.PositionMethodNearest, method)
+		assert.Equal(t, *after, position)
+
+		_, _, ok = PositionAt(before, nil, start.Add(time.Hour), thresholds, 5*time.Minute)
+		assert.False(t, ok)
+		_, _, ok = PositionAt(nil, nil, start, thresholds, 5*time.Minute)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/constants"
+)
+
+func TestSelectPosition(t *testing.T) {
+	now := time.Unix(1758000000, 0)
+	window := 2 * time.Minute
+
+	// Newest first: a phone with a coarse fix, an accurate older watch fix and a stale GPS fix
+	fixes := []PositionFix{
+		{Timestamp: now, Accuracy: 50, Device: "phone"},
+		{Timestamp: now.Add(-30 * time.Second), Accuracy: 20, Device: "watch"},
+		{Timestamp: now.Add(-60 * time.Second), Accuracy: 5, Device: "phone"},
+		{Timestamp: now.Add(-10 * time.Minute), Accuracy: 1, Device: "gps"},
+	}
+
+	t.Run("newest", func(t *testing.T) {
+		assert.Equal(t, 0, SelectPosition(fixes, constants.LocationPolicyNewest, window))
+	})
+
+	t.Run("most accurate within window", func(t *testing.T) {
+		assert.Equal(t, 2, SelectPosition(fixes, constants.LocationPolicyMostAccurate, window))
+	})
+
+	t.Run("per device only compares latest fixes", func(t *testing.T) {
+		assert.Equal(t, 1, SelectPosition(fixes, constants.LocationPolicyPerDevice, window))
+	})
+
+	t.Run("unknown accuracy falls back to newest", func(t *testing.T) {
+		unknown := []PositionFix{{Timestamp: now}, {Timestamp: now.Add(-time.Second)}}
+		assert.Equal(t, 0, SelectPosition(unknown, constants.LocationPolicyMostAccurate, window))
+	})
+
+	t.Run("fix with accuracy beats unknown accuracy", func(t *testing.T) {
+		mixed := []PositionFix{{Timestamp: now}, {Timestamp: now.Add(-time.Second), Accuracy: 30}}
+		assert.Equal(t, 1, SelectPosition(mixed, constants.LocationPolicyMostAccurate, window))
+	})
+
+	t.Run("no fixes", func(t *testing.T) {
+		assert.Equal(t, -1, SelectPosition(nil, constants.LocationPolicyNewest, window))
+	})
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// NewPoWChallenge returns a signed, stateless proof-of-work challenge of the form
+// "<expiry unix>.<random>.<signature>" that expires after ttl
+func NewPoWChallenge(secret string, now time.Time, ttl time.Duration) string {
+	payload := strconv.FormatInt(now.Add(ttl).Unix(), 10) + "." + security.RandomString(16)
+	return payload + "." + powSignature(secret, payload)
+}
+
+// VerifyPoWSolution checks a solution token of the form "<challenge>:<nonce>". The challenge must
+// carry a valid signature and not be expired, and the SHA-256 digest of the whole token must start
+// with at least difficulty zero bits. The solved challenge is returned so callers can reject replays.
+func VerifyPoWSolution(secret, token string, difficulty int, now time.Time) (string, error) {
+	idx := strings.LastIndex(token, ":")
+	if idx <= 0 || idx == len(token)-1 {
+		return "", errors.New("malformed proof-of-work solution")
+	}
+	challenge := token[:idx]
+
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed proof-of-work challenge")
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(powSignature(secret, payload))) {
+		return "", errors.New("invalid proof-of-work challenge signature")
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return "", errors.New("malformed proof-of-work challenge")
+	}
+	if now.Unix() >= expiresAt {
+		return "", errors.New("proof-of-work challenge expired")
+	}
+
+	if LeadingZeroBits(sha256.Sum256([]byte(token))) < difficulty {
+		return "", errors.New("insufficient proof-of-work")
+	}
+
+	return challenge, nil
+}
+
+// LeadingZeroBits counts the zero bits at the start of a digest
+func LeadingZeroBits(digest [sha256.Size]byte) int {
+	count := 0
+	for _, b := range digest {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
+
+func powSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("pow:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// solvePoW brute-forces a nonce for the challenge, keep the difficulty low in tests
+func solvePoW(challenge string, difficulty int) string {
+	for nonce := 0; ; nonce++ {
+		token := challenge + ":" + strconv.Itoa(nonce)
+		if LeadingZeroBits(sha256.Sum256([]byte(token))) >= difficulty {
+			return token
+		}
+	}
+}
+
+func TestPoWChallenge(t *testing.T) {
+	secret := "test-secret"
+	now := time.Unix(1758000000, 0)
+	challenge := NewPoWChallenge(secret, now, 5*time.Minute)
+	token := solvePoW(challenge, 8)
+
+	t.Run("valid solution", func(t *testing.T) {
+		solved, err := VerifyPoWSolution(secret, token, 8, now.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, challenge, solved)
+	})
+
+	t.Run("expired challenge", func(t *testing.T) {
+		_, err := VerifyPoWSolution(secret, token, 8, now.Add(5*time.Minute))
+		assert.Error(t, err)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		_, err := VerifyPoWSolution("other-secret", token, 8, now)
+		assert.Error(t, err)
+	})
+
+	t.Run("forged challenge", func(t *testing.T) {
+		forged := solvePoW("9999999999.abc."+challenge[len(challenge)-43:], 8)
+		_, err := VerifyPoWSolution(secret, forged, 8, now)
+		assert.Error(t, err)
+	})
+
+	t.Run("insufficient work", func(t *testing.T) {
+		_, err := VerifyPoWSolution(secret, token, 64, now)
+		assert.Error(t, err)
+	})
+
+	t.Run("malformed tokens", func(t *testing.T) {
+		for _, malformed := range []string{"", "nonce", challenge, challenge + ":", ":123", "a.b:1"} {
+			_, err := VerifyPoWSolution(secret, malformed, 0, now)
+			assert.Error(t, err, malformed)
+		}
+	})
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	var digest [sha256.Size]byte
+	assert.Equal(t, 256, LeadingZeroBits(digest))
+
+	digest[0] = 0x80
+	assert.Equal(t, 0, LeadingZeroBits(digest))
+
+	digest[0] = 0x00
+	digest[1] = 0x1f
+	assert.Equal(t, 11, LeadingZeroBits(digest))
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"vibe-tracker/models"
+)
+
+// TimedPoint is a recorded position with its timestamp
+type TimedPoint struct {
+	Timestamp time.Time
+	Latitude  float64
+	Longitude float64
+	Altitude  *float64
+}
+
+// ResampleTrack linearly interpolates points (ordered by timestamp) at a fixed interval,
+// producing one frame per interval from the first to the last point. It fails if more
+// than maxFrames frames would be produced.
+func ResampleTrack(points []TimedPoint, interval time.Duration, maxFrames int) ([]models.ReplayFrame, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive")
+	}
+	if len(points) == 0 {
+		return []models.ReplayFrame{}, nil
+	}
+
+	start := points[0].Timestamp
+	duration := points[len(points)-1].Timestamp.Sub(start)
+	frameCount := int(duration/interval) + 1
+	if frameCount > maxFrames {
+		return nil, fmt.Errorf("replay would need %d frames, maximum is %d", frameCount, maxFrames)
+	}
+
+	frames := make([]models.ReplayFrame, 0, frameCount)
+	segment := 0
+	for frame := 0; frame < frameCount; frame++ {
+		at := start.Add(time.Duration(frame) * interval)
+
+		// Advance to the segment containing the frame time
+		for segment < len(points)-2 && points[segment+1].Timestamp.Before(at) {
+			segment++
+		}
+
+		frames = append(frames, interpolateFrame(frame, at, points, segment))
+	}
+
+	return frames, nil
+}
+
+// interpolateFrame computes the position at the given time between points[segment] and points[segment+1]
+func interpolateFrame(frame int, at time.Time, points []TimedPoint, segment int) models.ReplayFrame {
+	from := points[segment]
+	result := models.ReplayFrame{
+		Frame:     frame,
+		Timestamp: at.Unix(),
+		Latitude:  from.Latitude,
+		Longitude: from.Longitude,
+		Altitude:  from.Altitude,
+	}
+
+	if segment+1 >= len(points) {
+		return result
+	}
+
+	to := points[segment+1]
+	span := to.Timestamp.Sub(from.Timestamp)
+	if span <= 0 {
+		return result
+	}
+
+	ratio := float64(at.Sub(from.Timestamp)) / float64(span)
+	if ratio <= 0 {
+		return result
+	}
+	if ratio > 1 {
+		ratio = 1
+	}
+
+	result.Latitude = from.Latitude + (to.Latitude-from.Latitude)*ratio
+	result.Longitude = from.Longitude + (to.Longitude-from.Longitude)*ratio
+	if from.Altitude != nil && to.Altitude != nil {
+		altitude := *from.Altitude + (*to.Altitude-*from.Altitude)*ratio
+		result.Altitude = &altitude
+	}
+
+	return result
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResampleTrack(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	t.Run("Interpolates between recorded points", func(t *testing.T) {
+		points := []TimedPoint{
+			{Timestamp: start, Latitude: 47.0, Longitude: 19.0, Altitude: floatPtr(100)},
+			{Timestamp: start.Add(120 * time.Second), Latitude: 47.2, Longitude: 19.4, Altitude: floatPtr(160)},
+		}
+
+		frames, err := ResampleTrack(points, 60*time.Second, 100)
+		assert.NoError(t, err)
+		assert.Len(t, frames, 3)
+
+		assert.Equal(t, 0, frames[0].Frame)
+		assert.Equal(t, start.Unix(), frames[0].Timestamp)
+		assert.Equal(t, 47.0, frames[0].Latitude)
+
+		assert.Equal(t, 1, frames[1].Frame)
+		assert.InDelta(t, 47.1, frames[1].Latitude, 1e-9)
+		assert.InDelta(t, 19.2, frames[1].Longitude, 1e-9)
+		assert.InDelta(t, 130.0, *frames[1].Altitude, 1e-9)
+
+		assert.InDelta(t, 47.2, frames[2].Latitude, 1e-9)
+		assert.Equal(t, start.Add(120*time.Second).Unix(), frames[2].Timestamp)
+	})
+
+	t.Run("Spans multiple segments", func(t *testing.T) {
+		points := []TimedPoint{
+			{Timestamp: start, Latitude: 0, Longitude: 0},
+			{Timestamp: start.Add(10 * time.Second), Latitude: 10, Longitude: 0},
+			{Timestamp: start.Add(20 * time.Second), Latitude: 10, Longitude: 10},
+		}
+
+		frames, err := ResampleTrack(points, 5*time.Second, 100)
+		assert.NoError(t, err)
+		assert.Len(t, frames, 5)
+		assert.InDelta(t, 5.0, frames[1].Latitude, 1e-9)
+		assert.InDelta(t, 10.0, frames[2].Latitude, 1e-9)
+		assert.InDelta(t, 5.0, frames[3].Longitude, 1e-9)
+		assert.Nil(t, frames[3].Altitude)
+	})
+
+	t.Run("Single point", func(t *testing.T) {
+		frames, err := ResampleTrack([]TimedPoint{{Timestamp: start, Latitude: 1, Longitude: 2}}, time.Second, 10)
+		assert.NoError(t, err)
+		assert.Len(t, frames, 1)
+	})
+
+	t.Run("Too many frames", func(t *testing.T) {
+		points := []TimedPoint{
+			{Timestamp: start},
+			{Timestamp: start.Add(time.Hour)},
+		}
+		_, err := ResampleTrack(points, time.Second, 100)
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid interval", func(t *testing.T) {
+		_, err := ResampleTrack(nil, 0, 100)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/models"
+)
+
+// ParseLatLon parses a "lat,lon" position
+func ParseLatLon(value string) (float64, float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("position must be lat,lon")
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || math.IsNaN(lat) || math.Abs(lat) > 90 {
+		return 0, 0, fmt.Errorf("latitude must be a number between -90 and 90")
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || math.IsNaN(lon) || math.Abs(lon) > 180 {
+		return 0, 0, fmt.Errorf("longitude must be a number between -180 and 180")
+	}
+
+	return lat, lon, nil
+}
+
+// ParseValhallaRoute extracts the [lon, lat] geometry, distance in meters and duration in
+// seconds of the first leg from a Valhalla route response
+func ParseValhallaRoute(body []byte) ([][]float64, float64, float64, error) {
+	var response struct {
+		Trip struct {
+			Units   string `json:"units"`
+			Summary struct {
+				Length float64 `json:"length"`
+				Time   float64 `json:"time"`
+			} `json:"summary"`
+			Legs []struct {
+				Shape string `json:"shape"`
+			} `json:"legs"`
+		} `json:"trip"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, 0, 0, fmt.Errorf("invalid route response: %w", err)
+	}
+	if len(response.Trip.Legs) == 0 {
+		return nil, 0, 0, fmt.Errorf("route response has no legs")
+	}
+
+	coordinates, err := DecodePolyline(response.Trip.Legs[0].Shape, 6)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	unit := 1000.0
+	if response.Trip.Units == "miles" {
+		unit = 1609.344
+	}
+	return coordinates, response.Trip.Summary.Length * unit, response.Trip.Summary.Time, nil
+}
+
+// ParseOSRMRoute extracts the [lon, lat] geometry, distance in meters and duration in seconds
+// of the first route from an OSRM route response requested with geojson geometries
+func ParseOSRMRoute(body []byte) ([][]float64, float64, float64, error) {
+	var response struct {
+		Code   string `json:"code"`
+		Routes []struct {
+			Distance float64 `json:"distance"`
+			Duration float64 `json:"duration"`
+			Geometry struct {
+				Coordinates [][]float64 `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"routes"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, 0, 0, fmt.Errorf("invalid route response: %w", err)
+	}
+	if response.Code != "Ok" || len(response.Routes) == 0 {
+		return nil, 0, 0, fmt.Errorf("routing failed: %s", response.Code)
+	}
+
+	route := response.Routes[0]
+	return route.Geometry.Coordinates, route.Distance, route.Duration, nil
+}
+
+// ParseValhallaHeights extracts the elevations of the input positions from a Valhalla height
+// response; positions without elevation data are nil
+func ParseValhallaHeights(body []byte, count int) ([]*float64, error) {
+	var response struct {
+		Height []*float64 `json:"height"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid height response: %w", err)
+	}
+	if len(response.Height) != count {
+		return nil, fmt.Errorf("height response has %d heights, expected %d", len(response.Height), count)
+	}
+	return response.Height, nil
+}
+
+// BuildRouteFeature builds the GeoJSON Feature of a planned route from its [lon, lat] geometry
+// and the elevation of each position, if known. The elevation profile, ascent, descent and
+// climbs are computed from the known elevations.
+func BuildRouteFeature(profile string, coordinates [][]float64, heights []*float64, distance, duration float64) models.RouteFeature {
+	feature := models.RouteFeature{
+		Type: "Feature",
+		Geometry: models.RouteGeometry{
+			Type:        "LineString",
+			Coordinates: make([][]float64, len(coordinates)),
+		},
+		Properties: models.RouteProperties{
+			Profile:          profile,
+			Distance:         distance,
+			Duration:         duration,
+			ElevationProfile: [][2]float64{},
+		},
+	}
+
+	points := make([]TimedPoint, len(coordinates))
+	along := 0.0
+	var previous *float64
+	for i, coordinate := range coordinates {
+		points[i] = TimedPoint{Latitude: coordinate[1], Longitude: coordinate[0]}
+		feature.Geometry.Coordinates[i] = []float64{coordinate[0], coordinate[1]}
+		if i > 0 {
+			along += HaversineDistance(coordinates[i-1][1], coordinates[i-1][0], coordinate[1], coordinate[0])
+		}
+
+		if i >= len(heights) || heights[i] == nil {
+			continue
+		}
+		height := *heights[i]
+		points[i].Altitude = &height
+		feature.Geometry.Coordinates[i] = append(feature.Geometry.Coordinates[i], height)
+		feature.Properties.ElevationProfile = append(feature.Properties.ElevationProfile, [2]float64{along, height})
+
+		if previous != nil {
+			if height > *previous {
+				feature.Properties.Ascent += height - *previous
+			} else {
+				feature.Properties.Descent += *previous - height
+			}
+		}
+		previous = &height
+	}
+
+	feature.Properties.Climbs = DetectClimbs(points)
+	return feature
+}
+
+// BuildRouteGPX renders a planned route as a GPX 1.1 track, ready to upload as a session's
+// planned route
+func BuildRouteGPX(name string, route models.RouteFeature) ([]byte, error) {
+	gpx := GPX{
+		Version: "1.1",
+		Creator: "vibe-tracker",
+		Xmlns:   constants.GPXNamespace,
+	}
+	gpx.Metadata.Name = name
+
+	segment := GPXSegment{Points: make([]GPXTrackPoint, len(route.Geometry.Coordinates))}
+	for i, coordinate := range route.Geometry.Coordinates {
+		segment.Points[i] = GPXTrackPoint{Latitude: coordinate[1], Longitude: coordinate[0]}
+		if len(coordinate) > 2 {
+			elevation := coordinate[2]
+			segment.Points[i].Elevation = &elevation
+		}
+	}
+	gpx.Tracks = []GPXTrack{{Name: name, Segments: []GPXSegment{segment}}}
+
+	body, err := xml.MarshalIndent(gpx, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLatLon(t *testing.T) {
+	lat, lon, err := ParseLatLon("47.4979, 19.0402")
+	assert.NoError(t, err)
+	assert.Equal(t, 47.4979, lat)
+	assert.Equal(t, 19.0402, lon)
+
+	for _, invalid := range []string{"", "47.5", "91,19", "47,181", "a,b", "1,2,3"} {
+		_, _, err := ParseLatLon(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	t.Run("valhalla", func(t *testing.T) {
+		coordinates, distance, duration, err := ParseValhallaRoute([]byte(
+			`{"trip":{"units":"kilometers","summary":{"length":33.36,"time":24000},"legs":[{"shape":"_{ssxA_ktfc@_ibE?_ibE?_ibE?"}]}}`))
+		assert.NoError(t, err)
+		assert.Len(t, coordinates, 4)
+		assert.InDelta(t, 33360, distance, 0.001)
+		assert.Equal(t, 24000.0, duration)
+
+		_, _, _, err = ParseValhallaRoute([]byte(`{"trip":{"legs":[]}}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("osrm", func(t *testing.T) {
+		coordinates, distance, duration, err := ParseOSRMRoute([]byte(
+			`{"code":"Ok","routes":[{"distance":1200.5,"duration":900,"geometry":{"type":"LineString","coordinates":[[19,47],[19.01,47.01]]}}]}`))
+		assert.NoError(t, err)
+		assert.Equal(t, [][]float64{{19, 47}, {19.01, 47.01}}, coordinates)
+		assert.Equal(t, 1200.5, distance)
+		assert.Equal(t, 900.0, duration)
+
+		_, _, _, err = ParseOSRMRoute([]byte(`{"code":"NoRoute","routes":[]}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("heights", func(t *testing.T) {
+		heights, err := ParseValhallaHeights([]byte(`{"height":[100,null,120]}`), 3)
+		assert.NoError(t, err)
+		assert.Nil(t, heights[1])
+		assert.Equal(t, 120.0, *heights[2])
+
+		_, err = ParseValhallaHeights([]byte(`{"height":[100]}`), 3)
+		assert.Error(t, err)
+	})
+}
+
+func TestBuildRouteFeature(t *testing.T) {
+	coordinates := [][]float64{{19, 47}, {19, 47.001}, {19, 47.002}, {19, 47.003}}
+	heights := []*float64{floatPtr(100), floatPtr(110), nil, floatPtr(105)}
+
+	route := BuildRouteFeature("foot", coordinates, heights, 340, 250)
+	assert.Equal(t, "Feature", route.Type)
+	assert.Equal(t, []float64{19, 47.001, 110}, route.Geometry.Coordinates[1])
+	assert.Equal(t, []float64{19, 47.002}, route.Geometry.Coordinates[2])
+	assert.Equal(t, 10.0, route.Properties.Ascent)
+	assert.Equal(t, 5.0, route.Properties.Descent)
+	if assert.Len(t, route.Properties.ElevationProfile, 3) {
+		assert.InDelta(t, 333.6, route.Properties.ElevationProfile[2][0], 1)
+	}
+	assert.NotNil(t, route.Properties.Climbs)
+
+	gpx, err := BuildRouteGPX("Planned route", route)
+	assert.NoError(t, err)
+
+	parsed, err := ParseGPX(strings.NewReader(string(gpx)))
+	assert.NoError(t, err)
+	if assert.Len(t, parsed.TrackPoints, 4) {
+		assert.Equal(t, 110.0, *parsed.TrackPoints[1].Altitude)
+		assert.Nil(t, parsed.TrackPoints[2].Altitude)
+	}
+	assert.Equal(t, "Planned route", parsed.TrackName)
+}
@@ -0,0 +1,42 @@
+package utils
+
+import "github.com/pocketbase/pocketbase/models"
+
+// SetRecordSensors stores the cadence, power and ambient temperature reported with a location by
+// cycling computers and sports watches; nil readings are left unset. Number fields store a missing
+// reading as 0, so has_temperature keeps a temperature of 0 °C apart from a missing one.
+func SetRecordSensors(record *models.Record, cadence, power, temperature *float64) {
+	if cadence != nil {
+		record.Set("cadence", *cadence)
+	}
+	if power != nil {
+		record.Set("power", *power)
+	}
+	if temperature != nil {
+		record.Set("temperature", *temperature)
+		record.Set("has_temperature", true)
+	}
+}
+
+// RecordTemperature returns the ambient temperature of a location, or nil when none was reported
+func RecordTemperature(record *models.Record) *float64 {
+	if !record.GetBool("has_temperature") {
+		return nil
+	}
+	temperature := record.GetFloat("temperature")
+	return &temperature
+}
+
+// AddSensorProperties adds the sensor readings of a location to its GeoJSON properties, leaving
+// out the ones that were not reported
+func AddSensorProperties(properties map[string]any, record *models.Record) {
+	if cadence := record.GetFloat("cadence"); cadence > 0 {
+		properties["cadence"] = cadence
+	}
+	if power := record.GetFloat("power"); power > 0 {
+		properties["power"] = power
+	}
+	if temperature := RecordTemperature(record); temperature != nil {
+		properties["temperature"] = *temperature
+	}
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordSensors(t *testing.T) {
+	t.Run("Freezing", func(t *testing.T) {
+		record := models.NewRecord(&models.Collection{})
+		cadence, power, freezing := 88.0, 215.0, 0.0
+		SetRecordSensors(record, &cadence, &power, &freezing)
+
+		temperature := RecordTemperature(record)
+		if assert.NotNil(t, temperature) {
+			assert.Equal(t, 0.0, *temperature)
+		}
+
+		properties := map[string]any{}
+		AddSensorProperties(properties, record)
+		assert.Equal(t, map[string]any{"cadence": 88.0, "power": 215.0, "temperature": 0.0}, properties)
+	})
+
+	t.Run("Below zero", func(t *testing.T) {
+		record := models.NewRecord(&models.Collection{})
+		cold := -12.5
+		SetRecordSensors(record, nil, nil, &cold)
+
+		properties := map[string]any{}
+		AddSensorProperties(properties, record)
+		assert.Equal(t, map[string]any{"temperature": -12.5}, properties)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		record := models.NewRecord(&models.Collection{})
+		SetRecordSensors(record, nil, nil, nil)
+
+		assert.Nil(t, RecordTemperature(record))
+		properties := map[string]any{}
+		AddSensorProperties(properties, record)
+		assert.Empty(t, properties)
+	})
+}
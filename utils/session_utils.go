@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+
+	"github.com/pocketbase/pocketbase/models"
 )
 
 // GenerateSessionTitle converts a session name to a title case format
@@ -57,3 +59,12 @@ func SanitizeSessionName(name string) string {
 
 	return cleaned
 }
+
+// LocationSessionName returns the name of the session of a location record from its expanded
+// session relation. It is empty when the location has no session or it was not expanded.
+func LocationSessionName(location *models.Record) string {
+	if session := location.ExpandedOne("session"); session != nil {
+		return session.GetString("name")
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"testing"
 
+	"github.com/pocketbase/pocketbase/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -163,3 +164,18 @@ func TestSanitizeSessionName(t *testing.T) {
 		})
 	}
 }
+
+func TestLocationSessionName(t *testing.T) {
+	session := models.NewRecord(&models.Collection{})
+	session.Id = "session1234567"
+	session.Set("name", "morning_run")
+
+	location := models.NewRecord(&models.Collection{})
+	assert.Equal(t, "", LocationSessionName(location))
+
+	location.Set("session", session.Id)
+	assert.Equal(t, "", LocationSessionName(location), "Not expanded")
+
+	location.SetExpand(map[string]any{"session": session})
+	assert.Equal(t, "morning_run", LocationSessionName(location))
+}
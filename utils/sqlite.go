@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SQLitePragmas holds the SQLite tuning applied to every database connection
+type SQLitePragmas struct {
+	JournalMode string        // WAL, DELETE, TRUNCATE, PERSIST, MEMORY or OFF
+	Synchronous string        // OFF, NORMAL, FULL or EXTRA
+	CacheSize   int           // Pages when positive, KiB when negative (0 keeps the default)
+	BusyTimeout time.Duration // How long a connection waits for a locked database
+}
+
+var (
+	sqliteJournalModes = []string{"WAL", "DELETE", "TRUNCATE", "PERSIST", "MEMORY", "OFF"}
+	sqliteSynchronous  = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+)
+
+// Statements returns the PRAGMA statements of the tuning. busy_timeout comes first, so a connection
+// waits for other connections while switching the journal mode. Empty values are skipped and keep
+// the PocketBase defaults.
+func (p SQLitePragmas) Statements() ([]string, error) {
+	var statements []string
+
+	if p.BusyTimeout < 0 {
+		return nil, fmt.Errorf("invalid SQLite busy timeout %s", p.BusyTimeout)
+	}
+	if p.BusyTimeout > 0 {
+		statements = append(statements, fmt.Sprintf("PRAGMA busy_timeout = %d", p.BusyTimeout.Milliseconds()))
+	}
+
+	if p.JournalMode != "" {
+		mode := strings.ToUpper(p.JournalMode)
+		if !slices.Contains(sqliteJournalModes, mode) {
+			return nil, fmt.Errorf("invalid SQLite journal mode %q", p.JournalMode)
+		}
+		statements = append(statements, "PRAGMA journal_mode = "+mode)
+	}
+
+	if p.Synchronous != "" {
+		synchronous := strings.ToUpper(p.Synchronous)
+		if !slices.Contains(sqliteSynchronous, synchronous) {
+			return nil, fmt.Errorf("invalid SQLite synchronous setting %q", p.Synchronous)
+		}
+		statements = append(statements, "PRAGMA synchronous = "+synchronous)
+	}
+
+	if p.CacheSize != 0 {
+		statements = append(statements, fmt.Sprintf("PRAGMA cache_size = %d", p.CacheSize))
+	}
+
+	return statements, nil
+}
@@ -0,0 +1,49 @@
+//go:build cgo
+
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// ApplySQLitePragmas runs the tuning on every connection PocketBase opens, after its own default
+// pragmas. It must be called before the app is bootstrapped.
+func ApplySQLitePragmas(pragmas SQLitePragmas) error {
+	statements, err := pragmas.Statements()
+	if err != nil || len(statements) == 0 {
+		return err
+	}
+
+	// PocketBase registers the cgo driver with a connect hook setting its defaults, opening a
+	// handle does not connect but gives access to the registered driver
+	db, err := sql.Open("pb_sqlite3", "")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	driver, ok := db.Driver().(*sqlite3.SQLiteDriver)
+	if !ok {
+		return fmt.Errorf("unexpected SQLite driver %T", db.Driver())
+	}
+
+	defaults := driver.ConnectHook
+	driver.ConnectHook = func(conn *sqlite3.SQLiteConn) error {
+		if defaults != nil {
+			if err := defaults(conn); err != nil {
+				return err
+			}
+		}
+		for _, statement := range statements {
+			if _, err := conn.Exec(statement, nil); err != nil {
+				return fmt.Errorf("%s: %w", statement, err)
+			}
+		}
+		return nil
+	}
+
+	return nil
+}
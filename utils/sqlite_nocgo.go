@@ -0,0 +1,30 @@
+//go:build !cgo
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"modernc.org/sqlite"
+)
+
+// ApplySQLitePragmas runs the tuning on every connection PocketBase opens, after the default
+// pragmas of its connection string. It must be called before the app is bootstrapped.
+func ApplySQLitePragmas(pragmas SQLitePragmas) error {
+	statements, err := pragmas.Statements()
+	if err != nil || len(statements) == 0 {
+		return err
+	}
+
+	sqlite.RegisterConnectionHook(func(conn sqlite.ExecQuerierContext, dsn string) error {
+		for _, statement := range statements {
+			if _, err := conn.ExecContext(context.Background(), statement, nil); err != nil {
+				return fmt.Errorf("%s: %w", statement, err)
+			}
+		}
+		return nil
+	})
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLitePragmasStatements(t *testing.T) {
+	t.Run("all pragmas", func(t *testing.T) {
+		statements, err := SQLitePragmas{
+			JournalMode: "wal",
+			Synchronous: "normal",
+			CacheSize:   -64000,
+			BusyTimeout: 5 * time.Second,
+		}.Statements()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"PRAGMA busy_timeout = 5000",
+			"PRAGMA journal_mode = WAL",
+			"PRAGMA synchronous = NORMAL",
+			"PRAGMA cache_size = -64000",
+		}, statements)
+	})
+
+	t.Run("empty values keep defaults", func(t *testing.T) {
+		statements, err := SQLitePragmas{}.Statements()
+		assert.NoError(t, err)
+		assert.Empty(t, statements)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, err := SQLitePragmas{JournalMode: "wal; DROP TABLE users"}.Statements()
+		assert.Error(t, err)
+
+		_, err = SQLitePragmas{Synchronous: "sometimes"}.Statements()
+		assert.Error(t, err)
+
+		_, err = SQLitePragmas{BusyTimeout: -time.Second}.Statements()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,44 @@
+package utils
+
+import "strings"
+
+// StoredRecordFile identifies the record file a storage key belongs to
+type StoredRecordFile struct {
+	CollectionID string
+	RecordID     string
+	Name         string // File name in the record's file field; thumbs belong to their original file
+}
+
+// ParseStorageKey splits a key of PocketBase's record file layout, "<collection id>/<record id>/<name>"
+// or "<collection id>/<record id>/thumbs_<name>/<size>_<name>". Keys outside of it, like older GPX
+// uploads at the root of the storage, are reported with ok false.
+func ParseStorageKey(key string) (file StoredRecordFile, ok bool) {
+	parts := strings.Split(key, "/")
+	for _, part := range parts {
+		if part == "" {
+			return StoredRecordFile{}, false
+		}
+	}
+
+	switch len(parts) {
+	case 3:
+		return StoredRecordFile{CollectionID: parts[0], RecordID: parts[1], Name: parts[2]}, true
+	case 4:
+		name, isThumbs := strings.CutPrefix(parts[2], "thumbs_")
+		if !isThumbs || !strings.HasSuffix(parts[3], "_"+name) {
+			return StoredRecordFile{}, false
+		}
+		return StoredRecordFile{CollectionID: parts[0], RecordID: parts[1], Name: name}, true
+	}
+	return StoredRecordFile{}, false
+}
+
+// LegacyGPXTrackKey returns the storage key of a GPX upload made before uploads were stored in the
+// session's gpx_track file field: "<session id>_<file name>" at the root of the storage, kept in
+// gpx_track as is. Names of uploads stored with the session's files return "".
+func LegacyGPXTrackKey(sessionID, name string) string {
+	if sessionID == "" || !strings.HasPrefix(name, sessionID+"_") {
+		return ""
+	}
+	return name
+}
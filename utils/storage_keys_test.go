@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStorageKey(t *testing.T) {
+	file, ok := ParseStorageKey("pbc_waypoints/abc123/photo_k2j4h5.jpg")
+	assert.True(t, ok)
+	assert.Equal(t, StoredRecordFile{CollectionID: "pbc_waypoints", RecordID: "abc123", Name: "photo_k2j4h5.jpg"}, file)
+
+	t.Run("thumb", func(t *testing.T) {
+		file, ok := ParseStorageKey("pbc_users/abc123/thumbs_avatar.png/100x100_avatar.png")
+		assert.True(t, ok)
+		assert.Equal(t, StoredRecordFile{CollectionID: "pbc_users", RecordID: "abc123", Name: "avatar.png"}, file)
+	})
+
+	t.Run("outside the record layout", func(t *testing.T) {
+		for _, key := range []string{
+			"abc123_track.gpx",
+			"pbc_sessions/abc123",
+			"pbc_sessions//track.gpx",
+			"pbc_users/abc123/other/100x100_avatar.png",
+			"pbc_users/abc123/thumbs_avatar.png/100x100_other.png",
+			"a/b/c/d/e",
+		} {
+			_, ok := ParseStorageKey(key)
+			assert.False(t, ok, key)
+		}
+	})
+}
+
+func TestLegacyGPXTrackKey(t *testing.T) {
+	assert.Equal(t, "abc123_morning.gpx", LegacyGPXTrackKey("abc123", "abc123_morning.gpx"))
+	assert.Equal(t, "", LegacyGPXTrackKey("abc123", "morning_k2j4h5l6m7.gpx"))
+	assert.Equal(t, "", LegacyGPXTrackKey("abc123", ""))
+	assert.Equal(t, "", LegacyGPXTrackKey("", "_morning.gpx"))
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"math"
+	"time"
+
+	"vibe-tracker/models"
+)
+
+const (
+	julianUnixEpoch = 2440587.5 // Julian date of the Unix epoch
+	julian2000      = 2451545.0 // Julian date of 2000-01-01 12:00 UTC
+	earthTilt       = 23.4397   // Degrees
+	sunAltitude     = -0.833    // Degrees; the upper limb touches the horizon, refraction included
+)
+
+// SolarDate returns the date at a longitude by mean solar time, which is the local calendar day
+// sunrise and sunset belong to regardless of time zones
+func SolarDate(t time.Time, longitude float64) time.Time {
+	local := t.UTC().Add(time.Duration(longitude / 15 * float64(time.Hour)))
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// SunTimesAt computes sunrise and sunset on the local day of t at a place with the sunrise
+// equation, which is accurate to about a minute outside the polar regions
+func SunTimesAt(latitude, longitude float64, t time.Time) models.SunTimes {
+	date := SolarDate(t, longitude)
+	times := models.SunTimes{Date: date.Format("2006-01-02")}
+
+	// Days since J2000 of the date's noon, shifted to the mean solar noon at the longitude
+	n := math.Ceil(float64(date.Unix())/86400 + julianUnixEpoch - julian2000 + 0.0008)
+	meanNoon := n - longitude/360
+
+	anomaly := math.Mod(357.5291+0.98560028*meanNoon, 360)
+	center := 1.9148*sinDeg(anomaly) + 0.0200*sinDeg(2*anomaly) + 0.0003*sinDeg(3*anomaly)
+	eclipticLongitude := math.Mod(anomaly+center+180+102.9372, 360)
+	transit := julian2000 + meanNoon + 0.0053*sinDeg(anomaly) - 0.0069*sinDeg(2*eclipticLongitude)
+
+	declination := math.Asin(sinDeg(eclipticLongitude) * sinDeg(earthTilt))
+	cosHourAngle := (sinDeg(sunAltitude) - sinDeg(latitude)*math.Sin(declination)) / (cosDeg(latitude) * math.Cos(declination))
+
+	switch {
+	case cosHourAngle < -1:
+		times.PolarDay = true
+		times.DayLength = 86400
+		return times
+	case cosHourAngle > 1:
+		times.PolarNight = true
+		return times
+	}
+
+	hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+	sunrise := julianToTime(transit - hourAngle/360)
+	sunset := julianToTime(transit + hourAngle/360)
+	times.Sunrise = &sunrise
+	times.Sunset = &sunset
+	times.DayLength = int64(sunset.Sub(sunrise).Seconds())
+	return times
+}
+
+func julianToTime(julian float64) time.Time {
+	return time.Unix(int64(math.Round((julian-julianUnixEpoch)*86400)), 0).UTC()
+}
+
+func sinDeg(degrees float64) float64 {
+	return math.Sin(degrees * math.Pi / 180)
+}
+
+func cosDeg(degrees float64) float64 {
+	return math.Cos(degrees * math.Pi / 180)
+}
+
+// EstimateDaylight computes the daylight left at the latest of points (ordered by timestamp) at
+// now, and with the pace of the recent movement how far it gets before sunset and whether it
+// reaches the next waypoint, if any, in daylight
+func EstimateDaylight(points []TimedPoint, now time.Time, next *models.Waypoint) *models.DaylightProgress {
+	if len(points) == 0 {
+		return nil
+	}
+
+	latest := points[len(points)-1]
+	daylight := &models.DaylightProgress{SunTimes: SunTimesAt(latest.Latitude, latest.Longitude, now)}
+
+	var sunset time.Time
+	switch {
+	case daylight.PolarNight:
+		return daylight
+	case daylight.PolarDay:
+		// Daylight lasts at least until the end of the local day
+		sunset = SolarDate(now, latest.Longitude).Add(24*time.Hour - time.Duration(latest.Longitude/15*float64(time.Hour)))
+	default:
+		sunset = *daylight.Sunset
+	}
+	if now.Before(sunset) {
+		daylight.Remaining = int64(sunset.Sub(now).Seconds())
+	}
+
+	target := latest
+	if next != nil {
+		target = TimedPoint{Latitude: next.Latitude, Longitude: next.Longitude}
+	}
+	estimate := EstimateArrival(points, target.Latitude, target.Longitude)
+	if estimate.Speed == 0 {
+		return daylight
+	}
+
+	distance := estimate.Speed * float64(daylight.Remaining)
+	daylight.Pace = &estimate.Speed
+	daylight.DistanceBeforeSunset = &distance
+	if next != nil && estimate.ArrivalAt != nil {
+		beforeSunset := estimate.ArrivalAt.Before(sunset)
+		daylight.NextWaypointBeforeSunset = &beforeSunset
+	}
+	return daylight
+}
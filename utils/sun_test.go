@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/models"
+)
+
+func TestSunTimesAt(t *testing.T) {
+	t.Run("summer solstice in Budapest", func(t *testing.T) {
+		times := SunTimesAt(47.4979, 19.0402, time.Date(2025, 6, 21, 10, 0, 0, 0, time.UTC))
+		assert.Equal(t, "2025-06-21", times.Date)
+		if assert.NotNil(t, times.Sunrise) && assert.NotNil(t, times.Sunset) {
+			// 04:46 and 20:45 CEST
+			assert.WithinDuration(t, time.Date(2025, 6, 21, 2, 46, 0, 0, time.UTC), *times.Sunrise, 3*time.Minute)
+			assert.WithinDuration(t, time.Date(2025, 6, 21, 18, 45, 0, 0, time.UTC), *times.Sunset, 3*time.Minute)
+		}
+		assert.InDelta(t, 16*3600, times.DayLength, 600)
+	})
+
+	t.Run("date follows the local day", func(t *testing.T) {
+		// Already the 22nd in New Zealand
+		times := SunTimesAt(-41.2865, 174.7762, time.Date(2025, 6, 21, 20, 0, 0, 0, time.UTC))
+		assert.Equal(t, "2025-06-22", times.Date)
+		if assert.NotNil(t, times.Sunrise) {
+			// 07:47 NZST
+			assert.WithinDuration(t, time.Date(2025, 6, 21, 19, 47, 0, 0, time.UTC), *times.Sunrise, 3*time.Minute)
+		}
+	})
+
+	t.Run("polar day and night", func(t *testing.T) {
+		summer := SunTimesAt(78.2232, 15.6267, time.Date(2025, 6, 21, 12, 0, 0, 0, time.UTC))
+		assert.True(t, summer.PolarDay)
+		assert.Nil(t, summer.Sunrise)
+		assert.Equal(t, int64(86400), summer.DayLength)
+
+		winter := SunTimesAt(78.2232, 15.6267, time.Date(2025, 12, 21, 12, 0, 0, 0, time.UTC))
+		assert.True(t, winter.PolarNight)
+		assert.Equal(t, int64(0), winter.DayLength)
+	})
+}
+
+func TestEstimateDaylight(t *testing.T) {
+	now := time.Date(2025, 6, 21, 16, 45, 0, 0, time.UTC)
+
+	// Walking north at about 1.85 m/s for ten minutes
+	var points []TimedPoint
+	for i := 0; i <= 10; i++ {
+		points = append(points, TimedPoint{
+			Timestamp: now.Add(time.Duration(i-10) * time.Minute),
+			Latitude:  47.4 + float64(i)*0.001,
+			Longitude: 19.04,
+		})
+	}
+
+	t.Run("pace and distance before sunset", func(t *testing.T) {
+		daylight := EstimateDaylight(points, now, &models.Waypoint{Latitude: 47.45, Longitude: 19.04})
+		if assert.NotNil(t, daylight) {
+			assert.InDelta(t, 2*3600, daylight.Remaining, 300)
+			if assert.NotNil(t, daylight.Pace) && assert.NotNil(t, daylight.DistanceBeforeSunset) {
+				assert.InDelta(t, 1.85, *daylight.Pace, 0.01)
+				assert.InDelta(t, 13300, *daylight.DistanceBeforeSunset, 600)
+			}
+			if assert.NotNil(t, daylight.NextWaypointBeforeSunset) {
+				assert.True(t, *daylight.NextWaypointBeforeSunset)
+			}
+		}
+	})
+
+	t.Run("waypoint out of reach", func(t *testing.T) {
+		daylight := EstimateDaylight(points, now, &models.Waypoint{Latitude: 47.7, Longitude: 19.04})
+		if assert.NotNil(t, daylight) && assert.NotNil(t, daylight.NextWaypointBeforeSunset) {
+			assert.False(t, *daylight.NextWaypointBeforeSunset)
+		}
+	})
+
+	t.Run("after sunset and without movement", func(t *testing.T) {
+		daylight := EstimateDaylight(points[10:], now.Add(4*time.Hour), nil)
+		if assert.NotNil(t, daylight) {
+			assert.Equal(t, int64(0), daylight.Remaining)
+			assert.Nil(t, daylight.Pace)
+		}
+	})
+
+	assert.Nil(t, EstimateDaylight(nil, now, nil))
+}
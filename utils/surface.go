@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/models"
+)
+
+// trailUses are Valhalla edge uses reported as the road type instead of the road class, which
+// Valhalla sets to service_other or similar for them
+var trailUses = map[string]bool{
+	"track":         true,
+	"path":          true,
+	"footway":       true,
+	"cycleway":      true,
+	"bridleway":     true,
+	"steps":         true,
+	"mountain_bike": true,
+	"pedestrian":    true,
+	"living_street": true,
+	"ferry":         true,
+}
+
+// SurfaceType groups a Valhalla surface into paved, unpaved or unknown
+func SurfaceType(surface string) string {
+	switch surface {
+	case "paved_smooth", "paved", "paved_rough":
+		return constants.SurfaceTypePaved
+	case "compacted", "dirt", "gravel", "path", "impassable":
+		return constants.SurfaceTypeUnpaved
+	default:
+		return constants.SurfaceTypeUnknown
+	}
+}
+
+// ParseTraceAttributes converts a Valhalla trace_attributes response into surface segments,
+// merging consecutive edges with the same surface and road type
+func ParseTraceAttributes(body []byte) ([]models.SurfaceSegment, error) {
+	var response struct {
+		Shape string `json:"shape"`
+		Units string `json:"units"`
+		Edges []struct {
+			Length          float64 `json:"length"`
+			Surface         string  `json:"surface"`
+			RoadClass       string  `json:"road_class"`
+			Use             string  `json:"use"`
+			BeginShapeIndex int     `json:"begin_shape_index"`
+			EndShapeIndex   int     `json:"end_shape_index"`
+		} `json:"edges"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid trace attributes response: %w", err)
+	}
+
+	shape, err := DecodePolyline(response.Shape, 6)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := 1000.0
+	if response.Units == "miles" {
+		unit = 1609.344
+	}
+
+	segments := []models.SurfaceSegment{}
+	for _, edge := range response.Edges {
+		if edge.BeginShapeIndex < 0 || edge.EndShapeIndex >= len(shape) || edge.BeginShapeIndex > edge.EndShapeIndex {
+			return nil, fmt.Errorf("edge shape index out of range")
+		}
+
+		highway := edge.RoadClass
+		if trailUses[edge.Use] {
+			highway = edge.Use
+		}
+		surface := edge.Surface
+		if surface == "" {
+			surface = constants.SurfaceTypeUnknown
+		}
+		coordinates := shape[edge.BeginShapeIndex : edge.EndShapeIndex+1]
+
+		if n := len(segments); n > 0 && segments[n-1].Surface == surface && segments[n-1].Highway == highway {
+			// Edges share their boundary point
+			segments[n-1].Coordinates = append(segments[n-1].Coordinates, coordinates[1:]...)
+			segments[n-1].Distance += edge.Length * unit
+			continue
+		}
+		segments = append(segments, models.SurfaceSegment{
+			Surface:     surface,
+			SurfaceType: SurfaceType(surface),
+			Highway:     highway,
+			Distance:    edge.Length * unit,
+			Coordinates: append([][]float64{}, coordinates...),
+		})
+	}
+
+	return segments, nil
+}
+
+// SummarizeSurface sums up the distance of surface segments
+func SummarizeSurface(segments []models.SurfaceSegment) models.SurfaceSummary {
+	summary := models.SurfaceSummary{
+		Surfaces: map[string]float64{},
+		Highways: map[string]float64{},
+	}
+
+	for _, segment := range segments {
+		summary.MatchedDistance += segment.Distance
+		summary.Surfaces[segment.Surface] += segment.Distance
+		summary.Highways[segment.Highway] += segment.Distance
+
+		switch segment.SurfaceType {
+		case constants.SurfaceTypePaved:
+			summary.PavedDistance += segment.Distance
+		case constants.SurfaceTypeUnpaved:
+			summary.UnpavedDistance += segment.Distance
+		default:
+			summary.UnknownDistance += segment.Distance
+		}
+	}
+
+	if summary.MatchedDistance > 0 {
+		summary.PavedPercent = math.Round(summary.PavedDistance/summary.MatchedDistance*1000) / 10
+		summary.UnpavedPercent = math.Round(summary.UnpavedDistance/summary.MatchedDistance*1000) / 10
+	}
+
+	return summary
+}
+
+// DecodePolyline decodes an encoded polyline with the given precision (5 for Google and OSRM,
+// 6 for Valhalla) into [lon, lat] coordinates
+func DecodePolyline(encoded string, precision int) ([][]float64, error) {
+	factor := math.Pow10(precision)
+	coordinates := [][]float64{}
+
+	var lat, lon int
+	for i := 0; i < len(encoded); {
+		var deltas [2]int
+		for j := range deltas {
+			result, shift := 0, 0
+			for {
+				if i >= len(encoded) {
+					return nil, fmt.Errorf("truncated polyline")
+				}
+				b := int(encoded[i]) - 63
+				i++
+				if b < 0 || b > 0x3f {
+					return nil, fmt.Errorf("invalid polyline character")
+				}
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+			}
+			if result&1 != 0 {
+				deltas[j] = ^(result >> 1)
+			} else {
+				deltas[j] = result >> 1
+			}
+		}
+
+		lat += deltas[0]
+		lon += deltas[1]
+		coordinates = append(coordinates, []float64{float64(lon) / factor, float64(lat) / factor})
+	}
+
+	return coordinates, nil
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodePolyline(t *testing.T) {
+	// Example from Google's polyline algorithm documentation
+	coordinates, err := DecodePolyline("_p~iF~ps|U_ulLnnqC_mqNvxq`@", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float64{{-120.2, 38.5}, {-120.95, 40.7}, {-126.453, 43.252}}, coordinates)
+
+	_, err = DecodePolyline("_p~iF~ps|U_ulL", 5)
+	assert.Error(t, err)
+}
+
+func TestParseTraceAttributes(t *testing.T) {
+	// Shape of four points, encoded with precision 6
+	body := []byte(`{"units":"kilometers","shape":"_{ssxA_ktfc@_ibE?_ibE?_ibE?","edges":[
+		{"length":0.1,"surface":"paved_smooth","road_class":"residential","use":"road","begin_shape_index":0,"end_shape_index":1},
+		{"length":0.2,"surface":"paved_smooth","road_class":"residential","use":"road","begin_shape_index":1,"end_shape_index":2},
+		{"length":0.3,"surface":"gravel","road_class":"service_other","use":"track","begin_shape_index":2,"end_shape_index":3}]}`)
+
+	segments, err := ParseTraceAttributes(body)
+	assert.NoError(t, err)
+	if assert.Len(t, segments, 2) {
+		assert.Equal(t, "residential", segments[0].Highway)
+		assert.Equal(t, "paved", segments[0].SurfaceType)
+		assert.InDelta(t, 300, segments[0].Distance, 0.001)
+		assert.Len(t, segments[0].Coordinates, 3)
+		assert.Equal(t, "track", segments[1].Highway)
+		assert.Equal(t, "unpaved", segments[1].SurfaceType)
+		assert.Equal(t, []float64{19, 47.3}, segments[1].Coordinates[1])
+	}
+
+	summary := SummarizeSurface(segments)
+	assert.InDelta(t, 600, summary.MatchedDistance, 0.001)
+	assert.Equal(t, 50.0, summary.PavedPercent)
+	assert.Equal(t, 50.0, summary.UnpavedPercent)
+	assert.InDelta(t, 300, summary.Highways["track"], 0.001)
+
+	_, err = ParseTraceAttributes([]byte(`{"shape":"_{ssxA_ktfc@","edges":[{"begin_shape_index":0,"end_shape_index":3}]}`))
+	assert.Error(t, err)
+}
+
+func TestSurfaceType(t *testing.T) {
+	assert.Equal(t, "paved", SurfaceType("paved_rough"))
+	assert.Equal(t, "unpaved", SurfaceType("dirt"))
+	assert.Equal(t, "unknown", SurfaceType(""))
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"time"
+
+	"vibe-tracker/constants"
+	"vibe-tracker/models"
+)
+
+// BuildTimeline segments points ordered by timestamp into stays and movements. A stay is a run
+// of points within the stay radius of its first point that lasts at least the minimum stay
+// duration; the points between stays form movements, which include the last point of the
+// previous stay and the first of the next so that no travel is lost.
+func BuildTimeline(points []TimedPoint) []models.TimelineEntry {
+	type span struct{ start, end int }
+
+	var stays []span
+	for i := 0; i < len(points); {
+		j := i + 1
+		for j < len(points) && HaversineDistance(points[i].Latitude, points[i].Longitude,
+			points[j].Latitude, points[j].Longitude) <= constants.TimelineStayRadius {
+			j++
+		}
+
+		if points[j-1].Timestamp.Sub(points[i].Timestamp) < constants.TimelineStayMinDuration {
+			i++
+			continue
+		}
+
+		// A stay continuing right after the previous one at the same place is the same stay
+		if n := len(stays); n > 0 && stays[n-1].end == i-1 && HaversineDistance(points[stays[n-1].start].Latitude,
+			points[stays[n-1].start].Longitude, points[i].Latitude, points[i].Longitude) <= constants.TimelineStayRadius {
+			stays[n-1].end = j - 1
+		} else {
+			stays = append(stays, span{i, j - 1})
+		}
+		i = j
+	}
+
+	entries := []models.TimelineEntry{}
+	previousEnd := 0
+	for _, stay := range stays {
+		if stay.start > previousEnd {
+			entries = append(entries, movementEntry(points[previousEnd:stay.start+1]))
+		}
+		entries = append(entries, stayEntry(points[stay.start:stay.end+1]))
+		previousEnd = stay.end
+	}
+	if len(points)-1 > previousEnd {
+		entries = append(entries, movementEntry(points[previousEnd:]))
+	}
+
+	return entries
+}
+
+// DetectTransportMode guesses how a movement was made from its average speed in meters per second
+func DetectTransportMode(averageSpeed float64) string {
+	switch {
+	case averageSpeed <= constants.MaxWalkingSpeed:
+		return constants.TransportModeWalking
+	case averageSpeed <= constants.MaxCyclingSpeed:
+		return constants.TransportModeCycling
+	case averageSpeed <= constants.MaxDrivingSpeed:
+		return constants.TransportModeDriving
+	default:
+		return constants.TransportModeFlying
+	}
+}
+
+func stayEntry(points []TimedPoint) models.TimelineEntry {
+	entry := timelineEntry(constants.TimelineEntryStay, points)
+
+	var latitude, longitude float64
+	for _, p := range points {
+		latitude += p.Latitude
+		longitude += p.Longitude
+	}
+	latitude = RoundTo(latitude/float64(len(points)), constants.CoordinatePrecision)
+	longitude = RoundTo(longitude/float64(len(points)), constants.CoordinatePrecision)
+	entry.Latitude = &latitude
+	entry.Longitude = &longitude
+
+	return entry
+}
+
+func movementEntry(points []TimedPoint) models.TimelineEntry {
+	entry := timelineEntry(constants.TimelineEntryMovement, points)
+
+	for i := 1; i < len(points); i++ {
+		distance := HaversineDistance(points[i-1].Latitude, points[i-1].Longitude, points[i].Latitude, points[i].Longitude)
+		entry.Distance += distance
+		if seconds := points[i].Timestamp.Sub(points[i-1].Timestamp).Seconds(); seconds > 0 && distance/seconds > entry.MaxSpeed {
+			entry.MaxSpeed = distance / seconds
+		}
+	}
+
+	if entry.Duration > 0 {
+		entry.AverageSpeed = entry.Distance / float64(entry.Duration)
+		entry.Mode = DetectTransportMode(entry.AverageSpeed)
+	}
+	entry.Distance = RoundTo(entry.Distance, 1)
+	entry.AverageSpeed = RoundTo(entry.AverageSpeed, 2)
+	entry.MaxSpeed = RoundTo(entry.MaxSpeed, 2)
+
+	return entry
+}
+
+func timelineEntry(entryType string, points []TimedPoint) models.TimelineEntry {
+	start, end := points[0].Timestamp, points[len(points)-1].Timestamp
+	return models.TimelineEntry{
+		Type:       entryType,
+		StartTime:  start.UTC(),
+		EndTime:    end.UTC(),
+		Duration:   int64(end.Sub(start) / time.Second),
+		PointCount: len(points),
+	}
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/constants"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	start := time.Date(2025, 9, 20, 8, 0, 0, 0, time.UTC)
+	var points []TimedPoint
+	add := func(minutes int, latitude float64) {
+		points = append(points, TimedPoint{Timestamp: start.Add(time.Duration(minutes) * time.Minute), Latitude: latitude, Longitude: 19.0})
+	}
+
+	// Half an hour at home with some jitter
+	for m := 0; m <= 30; m += 5 {
+		add(m, 47.0+float64(m%10)*0.00001)
+	}
+	// About 11 km north in 15 minutes
+	for m := 33; m <= 45; m += 3 {
+		add(m, 47.0+float64(m-30)*0.0066)
+	}
+	// An hour at work
+	for m := 48; m <= 108; m += 10 {
+		add(m, 47.1)
+	}
+
+	entries := BuildTimeline(points)
+	if !assert.Len(t, entries, 3) {
+		return
+	}
+
+	home, trip, work := entries[0], entries[1], entries[2]
+	assert.Equal(t, constants.TimelineEntryStay, home.Type)
+	assert.Equal(t, start, home.StartTime)
+	assert.Equal(t, int64(30*60), home.Duration)
+	assert.Equal(t, 7, home.PointCount)
+	if assert.NotNil(t, home.Latitude) {
+		assert.InDelta(t, 47.0, *home.Latitude, 0.0001)
+	}
+
+	assert.Equal(t, constants.TimelineEntryMovement, trip.Type)
+	assert.Equal(t, home.EndTime, trip.StartTime)
+	assert.Equal(t, work.StartTime, trip.EndTime)
+	assert.Equal(t, 7, trip.PointCount)
+	assert.InDelta(t, 11100, trip.Distance, 200)
+	assert.Equal(t, constants.TransportModeDriving, trip.Mode)
+	assert.Nil(t, trip.Latitude)
+
+	assert.Equal(t, constants.TimelineEntryStay, work.Type)
+	assert.Equal(t, int64(60*60), work.Duration)
+}
+
+func TestBuildTimeline_NoStays(t *testing.T) {
+	assert.Empty(t, BuildTimeline(nil))
+
+	start := time.Date(2025, 9, 20, 8, 0, 0, 0, time.UTC)
+	var points []TimedPoint
+	for i := 0; i < 10; i++ {
+		points = append(points, TimedPoint{Timestamp: start.Add(time.Duration(i) * time.Minute), Latitude: 47.0 + float64(i)*0.001, Longitude: 19.0})
+	}
+
+	entries := BuildTimeline(points)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, constants.TimelineEntryMovement, entries[0].Type)
+		assert.Equal(t, 10, entries[0].PointCount)
+		assert.Equal(t, constants.TransportModeWalking, entries[0].Mode)
+	}
+}
+
+func TestDetectTransportMode(t *testing.T) {
+	assert.Equal(t, constants.TransportModeWalking, DetectTransportMode(1.4))
+	assert.Equal(t, constants.TransportModeCycling, DetectTransportMode(5))
+	assert.Equal(t, constants.TransportModeDriving, DetectTransportMode(25))
+	assert.Equal(t, constants.TransportModeFlying, DetectTransportMode(230))
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+
+	"vibe-tracker/constants"
+)
+
+// HashToken returns the hex-encoded SHA-256 digest of a secret token so it can be stored and looked up without keeping the plain value
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenSessionID returns the device session ID (jti claim) of an auth token, or an empty string for
+// tokens issued without one. The signature is not checked, so only use it on verified tokens.
+func TokenSessionID(token string) string {
+	claims, err := security.ParseUnverifiedJWT(token)
+	if err != nil {
+		return ""
+	}
+	jti, _ := claims["jti"].(string)
+	return jti
+}
+
+// GuestClaims identify an anonymous viewer of a shared session
+type GuestClaims struct {
+	ViewerID  string `json:"vid"`
+	SessionID string `json:"sid"`
+	// Owner and name of the session when the token was minted, to recognize the session's routes
+	Username    string `json:"usr,omitempty"`
+	SessionName string `json:"snm,omitempty"`
+	// GuestShareFingerprint of the session when the token was minted
+	ShareFingerprint string `json:"shf,omitempty"`
+	ExpiresAt        int64  `json:"exp"`
+}
+
+// GuestShareFingerprint identifies how a session is shared, or returns an empty string when it is
+// not shared. Guest tokens carry the fingerprint of the session they were minted for and stop
+// working once the session is made private or hidden, or its share token is rotated or revoked.
+func GuestShareFingerprint(session *models.Record) string {
+	if session.GetBool("hidden") {
+		return ""
+	}
+	if session.GetBool("public") && !session.GetBool("draft") {
+		return "public"
+	}
+	if shareToken := session.GetString("share_token"); shareToken != "" {
+		return HashToken(shareToken)[:16]
+	}
+	return ""
+}
+
+// SignGuestToken encodes the claims into a guest token signed with HMAC-SHA256
+func SignGuestToken(secret string, claims GuestClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return constants.GuestTokenPrefix + encoded + "." + guestTokenSignature(secret, encoded), nil
+}
+
+// ParseGuestToken verifies the signature and expiry of a guest token and returns its claims
+func ParseGuestToken(secret, token string, now time.Time) (*GuestClaims, error) {
+	encoded, signature, found := strings.Cut(strings.TrimPrefix(token, constants.GuestTokenPrefix), ".")
+	if !strings.HasPrefix(token, constants.GuestTokenPrefix) || !found {
+		return nil, errors.New("malformed guest token")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(guestTokenSignature(secret, encoded))) {
+		return nil, errors.New("invalid guest token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("malformed guest token")
+	}
+
+	var claims GuestClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ViewerID == "" || claims.SessionID == "" {
+		return nil, errors.New("malformed guest token")
+	}
+
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, errors.New("guest token expired")
+	}
+
+	return &claims, nil
+}
+
+func guestTokenSignature(secret, encodedPayload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encodedPayload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashToken(t *testing.T) {
+	t.Run("known digest", func(t *testing.T) {
+		assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", HashToken(""))
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		assert.Equal(t, HashToken("vtorg_secret"), HashToken("vtorg_secret"))
+	})
+
+	t.Run("different tokens differ", func(t *testing.T) {
+		assert.NotEqual(t, HashToken("vtorg_a"), HashToken("vtorg_b"))
+		assert.Len(t, HashToken("vtorg_a"), 64)
+	})
+}
+
+func TestGuestToken(t *testing.T) {
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	claims := GuestClaims{ViewerID: "viewer1", SessionID: "session1", ExpiresAt: now.Add(time.Hour).Unix()}
+
+	token, err := SignGuestToken("secret", claims)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "vtguest_"))
+
+	t.Run("round trip", func(t *testing.T) {
+		parsed, err := ParseGuestToken("secret", token, now)
+		assert.NoError(t, err)
+		assert.Equal(t, claims, *parsed)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		_, err := ParseGuestToken("other", token, now)
+		assert.Error(t, err)
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		forged, err := SignGuestToken("other", GuestClaims{ViewerID: "viewer1", SessionID: "session2", ExpiresAt: claims.ExpiresAt})
+		assert.NoError(t, err)
+		payload, _, _ := strings.Cut(forged, ".")
+		_, signature, _ := strings.Cut(token, ".")
+
+		_, err = ParseGuestToken("secret", payload+"."+signature, now)
+		assert.Error(t, err)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, err := ParseGuestToken("secret", token, now.Add(2*time.Hour))
+		assert.Error(t, err)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, value := range []string{"", "vtguest_", "vtguest_abc", strings.TrimPrefix(token, "vtguest_")} {
+			_, err := ParseGuestToken("secret", value, now)
+			assert.Error(t, err, value)
+		}
+	})
+}
+
+func TestTokenSessionID(t *testing.T) {
+	withSession, err := security.NewJWT(jwt.MapClaims{"id": "user1", "jti": "device1"}, "secret", 3600)
+	assert.NoError(t, err)
+	assert.Equal(t, "device1", TokenSessionID(withSession))
+
+	legacy, err := security.NewJWT(jwt.MapClaims{"id": "user1"}, "secret", 3600)
+	assert.NoError(t, err)
+	assert.Empty(t, TokenSessionID(legacy))
+
+	assert.Empty(t, TokenSessionID("not-a-jwt"))
+}
+
+func TestGuestShareFingerprint(t *testing.T) {
+	session := models.NewRecord(&models.Collection{})
+	assert.Empty(t, GuestShareFingerprint(session), "Private sessions without share link are not shared")
+
+	session.Set("share_token", "share1")
+	shared := GuestShareFingerprint(session)
+	assert.NotEmpty(t, shared)
+
+	session.Set("share_token", "share2")
+	assert.NotEqual(t, shared, GuestShareFingerprint(session), "Rotating the share token changes the fingerprint")
+
+	session.Set("public", true)
+	assert.Equal(t, "public", GuestShareFingerprint(session))
+
+	session.Set("draft", true)
+	assert.NotEqual(t, "public", GuestShareFingerprint(session), "Drafts are only shared by their link")
+
+	session.Set("hidden", true)
+	assert.Empty(t, GuestShareFingerprint(session), "Hidden sessions are not shared")
+}
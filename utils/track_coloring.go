@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+
+	"vibe-tracker/models"
+)
+
+// Supported track coloring metrics
+const (
+	ColoringMetricSpeed     = "speed"
+	ColoringMetricHeartRate = "heart_rate"
+	ColoringMetricElevation = "elevation"
+)
+
+// MetricPoint is a track position with an optional metric value
+type MetricPoint struct {
+	Latitude  float64
+	Longitude float64
+	Value     *float64
+	Segment   int // Track segment index; points of different segments are not connected
+}
+
+var coloringUnits = map[string]string{
+	ColoringMetricSpeed:     "m/s",
+	ColoringMetricHeartRate: "bpm",
+	ColoringMetricElevation: "m",
+}
+
+// Suggested color ramps from the lowest to the highest value
+var coloringRamps = map[string][]string{
+	ColoringMetricSpeed:     {"#2c7bb6", "#abd9e9", "#ffffbf", "#fdae61", "#d7191c"},
+	ColoringMetricHeartRate: {"#ffffb2", "#fecc5c", "#fd8d3c", "#f03b20", "#bd0026"},
+	ColoringMetricElevation: {"#1a9641", "#a6d96a", "#ffffbf", "#a6611a", "#f5f5f5"},
+}
+
+// IsValidColoringMetric reports whether the metric can be used for track coloring
+func IsValidColoringMetric(metric string) bool {
+	_, ok := coloringRamps[metric]
+	return ok
+}
+
+// BuildTrackColoring computes per-segment values for coloring a track by the given metric.
+// A segment's value is the mean of its endpoints; segments without any value or crossing a gap
+// between track segments are skipped.
+func BuildTrackColoring(metric string, points []MetricPoint) (*models.TrackColoringResponse, error) {
+	if !IsValidColoringMetric(metric) {
+		return nil, fmt.Errorf("unsupported coloring metric: %s", metric)
+	}
+
+	response := &models.TrackColoringResponse{
+		Metric:    metric,
+		Unit:      coloringUnits[metric],
+		ColorRamp: coloringRamps[metric],
+		Segments:  []models.ColoredSegment{},
+	}
+
+	minValue := math.Inf(1)
+	maxValue := math.Inf(-1)
+
+	for i := 1; i < len(points); i++ {
+		start, end := points[i-1], points[i]
+		if start.Segment != end.Segment {
+			continue
+		}
+
+		var value float64
+		switch {
+		case start.Value != nil && end.Value != nil:
+			value = (*start.Value + *end.Value) / 2
+		case start.Value != nil:
+			value = *start.Value
+		case end.Value != nil:
+			value = *end.Value
+		default:
+			continue
+		}
+
+		minValue = math.Min(minValue, value)
+		maxValue = math.Max(maxValue, value)
+
+		response.Segments = append(response.Segments, models.ColoredSegment{
+			Coordinates: [][]float64{
+				{start.Longitude, start.Latitude},
+				{end.Longitude, end.Latitude},
+			},
+			Value: value,
+		})
+	}
+
+	if len(response.Segments) == 0 {
+		return response, nil
+	}
+
+	response.Min = minValue
+	response.Max = maxValue
+
+	valueRange := maxValue - minValue
+	for i := range response.Segments {
+		if valueRange > 0 {
+			response.Segments[i].Normalized = (response.Segments[i].Value - minValue) / valueRange
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestBuildTrackColoring(t *testing.T) {
+	t.Run("Unsupported metric", func(t *testing.T) {
+		result, err := BuildTrackColoring("cadence", nil)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("Segments are normalized between min and max", func(t *testing.T) {
+		points := []MetricPoint{
+			{Latitude: 47.0, Longitude: 19.0, Value: floatPtr(2)},
+			{Latitude: 47.1, Longitude: 19.1, Value: floatPtr(4)},
+			{Latitude: 47.2, Longitude: 19.2, Value: floatPtr(10)},
+		}
+
+		result, err := BuildTrackColoring(ColoringMetricSpeed, points)
+		assert.NoError(t, err)
+		assert.Equal(t, "m/s", result.Unit)
+		assert.Len(t, result.ColorRamp, 5)
+		assert.Len(t, result.Segments, 2)
+		assert.Equal(t, 3.0, result.Min)
+		assert.Equal(t, 7.0, result.Max)
+		assert.Equal(t, 0.0, result.Segments[0].Normalized)
+		assert.Equal(t, 1.0, result.Segments[1].Normalized)
+		assert.Equal(t, [][]float64{{19.0, 47.0}, {19.1, 47.1}}, result.Segments[0].Coordinates)
+	})
+
+	t.Run("Segments across track gaps are skipped", func(t *testing.T) {
+		points := []MetricPoint{
+			{Latitude: 47.0, Longitude: 19.0, Value: floatPtr(2)},
+			{Latitude: 47.1, Longitude: 19.1, Value: floatPtr(4)},
+			{Latitude: 48.0, Longitude: 20.0, Value: floatPtr(6), Segment: 1},
+			{Latitude: 48.1, Longitude: 20.1, Value: floatPtr(8), Segment: 1},
+		}
+
+		result, err := BuildTrackColoring(ColoringMetricSpeed, points)
+		assert.NoError(t, err)
+		assert.Len(t, result.Segments, 2)
+		assert.Equal(t, [][]float64{{20.0, 48.0}, {20.1, 48.1}}, result.Segments[1].Coordinates)
+	})
+
+	t.Run("Missing values", func(t *testing.T) {
+		points := []MetricPoint{
+			{Latitude: 47.0, Longitude: 19.0},
+			{Latitude: 47.1, Longitude: 19.1},
+			{Latitude: 47.2, Longitude: 19.2, Value: floatPtr(120)},
+		}
+
+		result, err := BuildTrackColoring(ColoringMetricHeartRate, points)
+		assert.NoError(t, err)
+		assert.Len(t, result.Segments, 1)
+		assert.Equal(t, 120.0, result.Segments[0].Value)
+		assert.Equal(t, 0.0, result.Segments[0].Normalized)
+	})
+
+	t.Run("Empty track", func(t *testing.T) {
+		result, err := BuildTrackColoring(ColoringMetricElevation, []MetricPoint{})
+		assert.NoError(t, err)
+		assert.Empty(t, result.Segments)
+		assert.Equal(t, 0.0, result.Min)
+		assert.Equal(t, 0.0, result.Max)
+	})
+}
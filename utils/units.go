@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+
+	"vibe-tracker/constants"
+)
+
+// Factors converting supported speed units to meters per second
+var speedUnitFactors = map[string]float64{
+	constants.SpeedUnitMetersPerSecond: 1,
+	constants.SpeedUnitKilometersPerHr: 1000.0 / 3600.0,
+	constants.SpeedUnitMilesPerHour:    1609.344 / 3600.0,
+	constants.SpeedUnitKnots:           1852.0 / 3600.0,
+}
+
+// IsValidSpeedUnit reports whether speeds can be reported in the given unit
+func IsValidSpeedUnit(unit string) bool {
+	_, ok := speedUnitFactors[unit]
+	return ok
+}
+
+// SpeedToMetersPerSecond converts a speed reported in the given unit to m/s
+func SpeedToMetersPerSecond(speed float64, unit string) (float64, error) {
+	factor, ok := speedUnitFactors[unit]
+	if !ok {
+		return 0, fmt.Errorf("unsupported speed unit: %s", unit)
+	}
+	return speed * factor, nil
+}
+
+// RoundTo rounds a value to the given number of decimal places
+func RoundTo(value float64, decimals int) float64 {
+	scale := math.Pow10(decimals)
+	return math.Round(value*scale) / scale
+}
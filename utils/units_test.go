@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"vibe-tracker/constants"
+)
+
+func TestSpeedToMetersPerSecond(t *testing.T) {
+	tests := []struct {
+		unit     string
+		speed    float64
+		expected float64
+	}{
+		{constants.SpeedUnitMetersPerSecond, 5, 5},
+		{constants.SpeedUnitKilometersPerHr, 36, 10},
+		{constants.SpeedUnitMilesPerHour, 10, 4.4704},
+		{constants.SpeedUnitKnots, 10, 5.1444},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			speed, err := SpeedToMetersPerSecond(tt.speed, tt.unit)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.expected, speed, 0.0001)
+			assert.True(t, IsValidSpeedUnit(tt.unit))
+		})
+	}
+
+	t.Run("unsupported unit", func(t *testing.T) {
+		_, err := SpeedToMetersPerSecond(10, "furlongs/fortnight")
+		assert.Error(t, err)
+		assert.False(t, IsValidSpeedUnit(""))
+	})
+}
+
+func TestRoundTo(t *testing.T) {
+	assert.Equal(t, 47.4979123, RoundTo(47.49791234567, 7))
+	assert.Equal(t, -122.4194156, RoundTo(-122.41941555555, 7))
+	assert.Equal(t, 123.46, RoundTo(123.456, 2))
+}
@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FileRule describes an expected file of a multipart form, parsed from a `file` struct tag such as
+// `file:"required,max_size=5MB,types=image/jpeg image/png"`
+type FileRule struct {
+	Required bool
+	MaxSize  int64    // Bytes, 0 for no limit besides the request size limit
+	Types    []string // MIME types detected from the file content, empty accepts any type
+}
+
+// ParseFileRule parses a `file` struct tag
+func ParseFileRule(tag string) (FileRule, error) {
+	var rule FileRule
+	for _, option := range strings.Split(tag, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch name {
+		case "":
+		case "required":
+			rule.Required = true
+		case "max_size":
+			size, err := parseByteSize(value)
+			if err != nil {
+				return FileRule{}, fmt.Errorf("invalid max_size %q: %w", value, err)
+			}
+			rule.MaxSize = size
+		case "types":
+			rule.Types = strings.Fields(value)
+		default:
+			return FileRule{}, fmt.Errorf("unknown file rule option %q", name)
+		}
+	}
+	return rule, nil
+}
+
+// FileRules parses the `file` tags of the *multipart.FileHeader fields of a multipart form struct,
+// keyed by their `form` field name
+func FileRules(form interface{}) (map[string]FileRule, error) {
+	formType := reflect.TypeOf(form)
+	if formType.Kind() == reflect.Pointer {
+		formType = formType.Elem()
+	}
+
+	rules := map[string]FileRule{}
+	for i := 0; i < formType.NumField(); i++ {
+		field := formType.Field(i)
+		if field.Type != reflect.TypeOf(&multipart.FileHeader{}) {
+			continue
+		}
+		rule, err := ParseFileRule(field.Tag.Get("file"))
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", formType.Name(), field.Name, err)
+		}
+		rules[field.Tag.Get("form")] = rule
+	}
+	return rules, nil
+}
+
+// Check validates the files uploaded for a form field against the rule. The file type is sniffed
+// from the content, the client's Content-Type header and file name are not trusted.
+func (r FileRule) Check(field string, files []*multipart.FileHeader) error {
+	if len(files) == 0 {
+		if r.Required {
+			return ValidationErrors{{Field: field, Tag: "required", Message: fmt.Sprintf("%s is required", field)}}
+		}
+		return nil
+	}
+	if len(files) > 1 {
+		return ValidationErrors{{Field: field, Tag: "max_files", Value: strconv.Itoa(len(files)),
+			Message: fmt.Sprintf("%s accepts a single file", field)}}
+	}
+
+	file := files[0]
+	if r.MaxSize > 0 && file.Size > r.MaxSize {
+		return ValidationErrors{{Field: field, Tag: "max_size", Value: strconv.FormatInt(file.Size, 10),
+			Message: fmt.Sprintf("%s must be at most %s", field, formatByteSize(r.MaxSize))}}
+	}
+
+	if len(r.Types) == 0 {
+		return nil
+	}
+
+	detected, err := SniffMultipartFile(file)
+	if err != nil {
+		return ValidationErrors{{Field: field, Tag: "file", Message: fmt.Sprintf("%s could not be read", field)}}
+	}
+	for _, allowed := range r.Types {
+		if detected == allowed {
+			return nil
+		}
+	}
+	return ValidationErrors{{Field: field, Tag: "file_type", Value: detected,
+		Message: fmt.Sprintf("%s must be one of: %s", field, strings.Join(r.Types, ", "))}}
+}
+
+// SniffMultipartFile detects the type of an uploaded file from its first bytes
+func SniffMultipartFile(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	return SniffFileType(head[:n]), nil
+}
+
+// extensionContentTypes lists the content types files with a known extension must sniff as
+var extensionContentTypes = map[string][]string{
+	".gpx":  {"application/gpx+xml"},
+	".xml":  {"application/xml", "application/gpx+xml"},
+	".jpg":  {"image/jpeg"},
+	".jpeg": {"image/jpeg"},
+	".png":  {"image/png"},
+	".gif":  {"image/gif"},
+	".webp": {"image/webp"},
+	".tif":  {"image/tiff"},
+	".tiff": {"image/tiff"},
+	".heic": {"image/heic", "image/heif"},
+	".heif": {"image/heif", "image/heic"},
+	".svg":  {"image/svg+xml"},
+	".mp4":  {"video/mp4"},
+	".m4v":  {"video/mp4"},
+	".mov":  {"video/quicktime"},
+}
+
+// ContentMatchesExtension reports whether the sniffed content type of an uploaded file fits its
+// file name, so e.g. a script renamed to photo.jpg is caught. HTML is never accepted as browsers
+// would render it when the file is served; other files with unknown extensions are accepted.
+func ContentMatchesExtension(filename, detected string) bool {
+	if detected == "text/html" {
+		return false
+	}
+
+	allowed, known := extensionContentTypes[strings.ToLower(filepath.Ext(filename))]
+	if !known {
+		return true
+	}
+	for _, contentType := range allowed {
+		if detected == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// sniffLength is how much of a file SniffFileType looks at, XML files may start with a long
+// prolog of comments before their root element
+const sniffLength = 4096
+
+// SniffFileType detects the MIME type of a file from its magic numbers, or from the root element
+// of XML documents, so GPX tracks and SVG images are told apart from other XML. Unknown content
+// falls back to http.DetectContentType without parameters, e.g. "text/plain".
+func SniffFileType(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(head, []byte("GIF87a")), bytes.HasPrefix(head, []byte("GIF89a")):
+		return "image/gif"
+	case len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WEBP":
+		return "image/webp"
+	case bytes.HasPrefix(head, []byte("II*\x00")), bytes.HasPrefix(head, []byte("MM\x00*")):
+		return "image/tiff"
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		switch string(head[8:12]) {
+		case "heic", "heix", "hevc", "hevx":
+			return "image/heic"
+		case "mif1", "msf1", "heif":
+			return "image/heif"
+		case "isom", "iso2", "mp41", "mp42", "avc1", "M4V ":
+			return "video/mp4"
+		case "qt  ":
+			return "video/quicktime"
+		}
+	}
+
+	if root := xmlRootElement(head); root != "" {
+		switch root {
+		case "gpx":
+			return "application/gpx+xml"
+		case "svg":
+			return "image/svg+xml"
+		default:
+			return "application/xml"
+		}
+	}
+
+	detected, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	return detected
+}
+
+// xmlRootElement returns the local name of the root element of an XML document, or "" if the
+// content does not start like one
+func xmlRootElement(head []byte) string {
+	if label := utf16Label(head); label != "" {
+		if decoded, err := decodeText(head, label); err == nil {
+			head = decoded
+		}
+	}
+	content := bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xEF\xBB\xBF")), " \t\r\n")
+	if !bytes.HasPrefix(content, []byte("<")) {
+		return ""
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = false
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		return input, nil // Element names are ASCII in the encodings XML documents declare
+	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			return t.Name.Local
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return ""
+			}
+		}
+	}
+}
+
+func parseByteSize(value string) (int64, error) {
+	multiplier := int64(1)
+	upper := strings.ToUpper(value)
+	switch {
+	case strings.HasSuffix(upper, "MB"):
+		multiplier, upper = 1024*1024, strings.TrimSuffix(upper, "MB")
+	case strings.HasSuffix(upper, "KB"):
+		multiplier, upper = 1024, strings.TrimSuffix(upper, "KB")
+	}
+
+	size, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("must be a positive number of bytes, KB or MB")
+	}
+	return size * multiplier, nil
+}
+
+func formatByteSize(size int64) string {
+	switch {
+	case size%(1024*1024) == 0:
+		return fmt.Sprintf("%d MB", size/(1024*1024))
+	case size%1024 == 0:
+		return fmt.Sprintf("%d KB", size/1024)
+	default:
+		return fmt.Sprintf("%d bytes", size)
+	}
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const gpxContent = `<?xml version="1.0" encoding="UTF-8"?>
+<!-- exported track -->
+<gpx version="1.1" creator="test"><trk><trkseg><trkpt lat="47.5" lon="19.04"/></trkseg></trk></gpx>`
+
+// multipartFiles builds a parsed multipart form with the given file contents under field "file"
+func multipartFiles(t *testing.T, filename string, contents ...string) []*multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, content := range contents {
+		part, err := writer.CreateFormFile("file", filename)
+		assert.NoError(t, err)
+		part.Write([]byte(content))
+	}
+	assert.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	assert.NoError(t, err)
+	return form.File["file"]
+}
+
+func TestParseFileRule(t *testing.T) {
+	rule, err := ParseFileRule("required,max_size=5MB,types=image/jpeg image/png")
+	assert.NoError(t, err)
+	assert.Equal(t, FileRule{Required: true, MaxSize: 5 * 1024 * 1024, Types: []string{"image/jpeg", "image/png"}}, rule)
+
+	rule, err = ParseFileRule("max_size=512KB")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(512*1024), rule.MaxSize)
+
+	for _, invalid := range []string{"max_size=big", "max_size=-1", "mime=image/png"} {
+		_, err := ParseFileRule(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestFileRules(t *testing.T) {
+	type form struct {
+		Name  string                `form:"name"`
+		Track *multipart.FileHeader `form:"gpx_file" file:"required,max_size=5MB,types=application/gpx+xml"`
+		Photo *multipart.FileHeader `form:"photo" file:"max_size=1MB"`
+	}
+
+	rules, err := FileRules(&form{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]FileRule{
+		"gpx_file": {Required: true, MaxSize: 5 * 1024 * 1024, Types: []string{"application/gpx+xml"}},
+		"photo":    {MaxSize: 1024 * 1024},
+	}, rules)
+
+	type invalidForm struct {
+		File *multipart.FileHeader `form:"file" file:"max_size=big"`
+	}
+	_, err = FileRules(invalidForm{})
+	assert.Error(t, err)
+}
+
+func TestSniffFileType(t *testing.T) {
+	tests := map[string]string{
+		"\xFF\xD8\xFF\xE0\x00\x10JFIF":             "image/jpeg",
+		"\x89PNG\r\n\x1a\n\x00\x00":                "image/png",
+		"GIF89a\x01\x00":                           "image/gif",
+		"RIFF\x24\x00\x00\x00WEBPVP8 ":             "image/webp",
+		"II*\x00\x08\x00\x00\x00":                  "image/tiff",
+		"\x00\x00\x00\x18ftypheic\x00\x00\x00\x00": "image/heic",
+		"\x00\x00\x00\x20ftypisom\x00\x00\x02\x00": "video/mp4",
+		"\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00": "video/quicktime",
+		gpxContent: "application/gpx+xml",
+		"\xEF\xBB\xBF<gpx version=\"1.0\"></gpx>":                 "application/gpx+xml",
+		"\xFF\xFE<\x00g\x00p\x00x\x00>\x00":                       "application/gpx+xml",
+		`<?xml version="1.0" encoding="ISO-8859-1"?><gpx></gpx>`:  "application/gpx+xml",
+		`<!DOCTYPE svg><svg xmlns="http://www.w3.org/2000/svg"/>`: "image/svg+xml",
+		`<?xml version="1.0"?><kml></kml>`:                        "application/xml",
+		"#!/bin/sh\nrm -rf /\n":                                   "text/plain",
+		"<?php echo 'hi'; ?>":                                     "text/plain",
+		"MZ\x90\x00\x03\x00\x00\x00":                              "application/octet-stream",
+	}
+
+	for content, expected := range tests {
+		assert.Equal(t, expected, SniffFileType([]byte(content)), content)
+	}
+}
+
+func TestFileRuleCheck(t *testing.T) {
+	rule := FileRule{Required: true, MaxSize: 1024, Types: []string{"application/gpx+xml"}}
+
+	assert.NoError(t, rule.Check("gpx_file", multipartFiles(t, "track.gpx", gpxContent)))
+
+	t.Run("missing file", func(t *testing.T) {
+		err := rule.Check("gpx_file", nil)
+		assert.Equal(t, "required", err.(ValidationErrors)[0].Tag)
+		assert.NoError(t, FileRule{}.Check("gpx_file", nil))
+	})
+
+	t.Run("renamed file", func(t *testing.T) {
+		err := rule.Check("gpx_file", multipartFiles(t, "track.gpx", "<?php system($_GET['c']); ?>"))
+		assert.Equal(t, "file_type", err.(ValidationErrors)[0].Tag)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		err := rule.Check("gpx_file", multipartFiles(t, "track.gpx", gpxContent+string(make([]byte, 1024))))
+		assert.Equal(t, "max_size", err.(ValidationErrors)[0].Tag)
+	})
+
+	t.Run("multiple files", func(t *testing.T) {
+		err := rule.Check("gpx_file", multipartFiles(t, "track.gpx", gpxContent, gpxContent))
+		assert.Equal(t, "max_files", err.(ValidationErrors)[0].Tag)
+	})
+}
+
+func TestContentMatchesExtension(t *testing.T) {
+	assert.True(t, ContentMatchesExtension("track.GPX", "application/gpx+xml"))
+	assert.True(t, ContentMatchesExtension("photo.jpeg", "image/jpeg"))
+	assert.True(t, ContentMatchesExtension("photo.heic", "image/heif"))
+	assert.True(t, ContentMatchesExtension("clip.MOV", "video/quicktime"))
+	assert.True(t, ContentMatchesExtension("notes.txt", "text/plain"))
+
+	assert.False(t, ContentMatchesExtension("photo.jpg", "text/plain"))
+	assert.False(t, ContentMatchesExtension("track.gpx", "application/xml"))
+	assert.False(t, ContentMatchesExtension("avatar.png", "image/svg+xml"))
+	assert.False(t, ContentMatchesExtension("page.txt", "text/html"))
+}
@@ -0,0 +1,60 @@
+package utils
+
+import "strings"
+
+// userAgentBrowsers lists browser markers in match order; Chromium based browsers also
+// advertise Chrome and Safari, so the more specific markers come first
+var userAgentBrowsers = []struct{ marker, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"okhttp/", "Android app"},
+	{"curl/", "curl"},
+}
+
+// userAgentPlatforms lists operating system markers in match order
+var userAgentPlatforms = []struct{ marker, name string }{
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iOS"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// DescribeUserAgent returns a short human readable device description such as "Firefox on Linux"
+func DescribeUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := ""
+	for _, b := range userAgentBrowsers {
+		if strings.Contains(userAgent, b.marker) {
+			browser = b.name
+			break
+		}
+	}
+
+	platform := ""
+	for _, p := range userAgentPlatforms {
+		if strings.Contains(userAgent, p.marker) {
+			platform = p.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	default:
+		return "Unknown device"
+	}
+}
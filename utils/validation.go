@@ -2,9 +2,14 @@ package utils
 
 import (
 	"fmt"
+	"math"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+
+	"vibe-tracker/constants"
 )
 
 // Validator instance using go-playground/validator
@@ -57,6 +62,25 @@ func ValidateStruct(s interface{}) error {
 	return nil
 }
 
+// ValidateTimestamp checks that a point timestamp is neither older than maxAge nor more than maxSkew
+// in the future. A zero limit disables the respective check; allowHistorical skips the age check
+// for intentional imports of old tracks.
+func ValidateTimestamp(timestamp, now time.Time, maxAge, maxSkew time.Duration, allowHistorical bool) error {
+	value := timestamp.UTC().Format(time.RFC3339)
+
+	if maxSkew > 0 && timestamp.After(now.Add(maxSkew)) {
+		return ValidationErrors{{Field: "timestamp", Tag: "max_skew", Value: value,
+			Message: fmt.Sprintf("timestamp must not be more than %s in the future", maxSkew)}}
+	}
+
+	if maxAge > 0 && !allowHistorical && timestamp.Before(now.Add(-maxAge)) {
+		return ValidationErrors{{Field: "timestamp", Tag: "max_age", Value: value,
+			Message: fmt.Sprintf("timestamp must not be older than %s; set allow_historical=true to import old points", maxAge)}}
+	}
+
+	return nil
+}
+
 // GetValidator returns the validator instance for custom validations
 func GetValidator() *validator.Validate {
 	return validate
@@ -83,16 +107,30 @@ func getErrorMessage(fe validator.FieldError) string {
 		return fmt.Sprintf("%s must be a valid longitude (-180 to 180)", field)
 	case "positive":
 		return fmt.Sprintf("%s must be a positive number", field)
+	case "finite":
+		return fmt.Sprintf("%s must be a finite number", field)
 	case "session_name":
 		return fmt.Sprintf("%s must be a valid session name (alphanumeric, hyphens, underscores)", field)
+	case "slug":
+		return fmt.Sprintf("%s must be a valid slug (alphanumeric, hyphens, underscores)", field)
 	case "username":
 		return fmt.Sprintf("%s must be a valid username (alphanumeric, hyphens, underscores)", field)
+	case "activity":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.Join(constants.SessionActivities, ", "))
 	case "gte":
 		return fmt.Sprintf("%s must be greater than or equal to %s", field, fe.Param())
 	case "lte":
 		return fmt.Sprintf("%s must be less than or equal to %s", field, fe.Param())
 	case "len":
 		return fmt.Sprintf("%s must be exactly %s characters long", field, fe.Param())
+	case "required_if":
+		return fmt.Sprintf("%s is required when %s", field, strings.Replace(strings.ToLower(fe.Param()), " ", " is ", 1))
+	case "timezone":
+		return fmt.Sprintf("%s must be an IANA time zone name, e.g. Europe/Budapest", field)
+	case "camera_timezone":
+		return fmt.Sprintf("%s must be an IANA time zone name or a UTC offset, e.g. Europe/Budapest or +02:00", field)
+	case "e164":
+		return fmt.Sprintf("%s must be a phone number in international format, e.g. +14155550123", field)
 	default:
 		return fmt.Sprintf("%s is not valid", field)
 	}
@@ -112,6 +150,12 @@ func registerCustomValidators() {
 		return val >= -180 && val <= 180
 	})
 
+	// Custom finite number validator, rejects NaN and infinities that pass range checks
+	validate.RegisterValidation("finite", func(fl validator.FieldLevel) bool {
+		val := fl.Field().Float()
+		return !math.IsNaN(val) && !math.IsInf(val, 0)
+	})
+
 	// Custom positive number validator
 	validate.RegisterValidation("positive", func(fl validator.FieldLevel) bool {
 		val := fl.Field().Float()
@@ -127,6 +171,15 @@ func registerCustomValidators() {
 		return isValidSessionName(val)
 	})
 
+	// Custom slug validator for organization URLs (same character set as session names)
+	validate.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		val := fl.Field().String()
+		if val == "" {
+			return true // Let required handle empty values
+		}
+		return isValidSessionName(val)
+	})
+
 	// Custom username validator (alphanumeric, hyphens, underscores)
 	validate.RegisterValidation("username", func(fl validator.FieldLevel) bool {
 		val := fl.Field().String()
@@ -135,6 +188,25 @@ func registerCustomValidators() {
 		}
 		return isValidUsername(val)
 	})
+
+	// Custom session activity validator
+	validate.RegisterValidation("activity", func(fl validator.FieldLevel) bool {
+		val := fl.Field().String()
+		if val == "" {
+			return true // Let required handle empty values
+		}
+		return slices.Contains(constants.SessionActivities, val)
+	})
+
+	// Custom camera time zone validator: an IANA name or a UTC offset
+	validate.RegisterValidation("camera_timezone", func(fl validator.FieldLevel) bool {
+		val := fl.Field().String()
+		if val == "" {
+			return true // Let required handle empty values
+		}
+		_, err := ParseCameraTimezone(val)
+		return err == nil
+	})
 }
 
 // Helper function to validate session names
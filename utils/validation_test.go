@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
@@ -94,6 +96,86 @@ func TestValidateStruct_Failure(t *testing.T) {
 	assert.Contains(t, validationErrors, ValidationError{Field: "LenField", Tag: "len", Value: "short", Message: "lenfield must be exactly 7 characters long"})
 }
 
+func TestValidateStruct_Finite(t *testing.T) {
+	type finiteStruct struct {
+		Value       float64   `validate:"finite,gte=0"`
+		Coordinates []float64 `validate:"dive,finite"`
+	}
+
+	assert.Nil(t, ValidateStruct(finiteStruct{Value: 12.5, Coordinates: []float64{19.04, 47.5}}))
+
+	for _, value := range []float64{math.NaN(), math.Inf(1)} {
+		err := ValidateStruct(finiteStruct{Value: value})
+		if assert.Error(t, err) {
+			assert.Equal(t, "finite", err.(ValidationErrors)[0].Tag)
+		}
+	}
+
+	err := ValidateStruct(finiteStruct{Coordinates: []float64{19.04, math.Inf(-1)}})
+	if assert.Error(t, err) {
+		assert.Equal(t, "Coordinates[1]", err.(ValidationErrors)[0].Field)
+	}
+}
+
+func TestValidateStruct_PhoneChannel(t *testing.T) {
+	type contactStruct struct {
+		Phone   string `validate:"required_if=Channel sms,omitempty,e164"`
+		Channel string
+	}
+
+	assert.Nil(t, ValidateStruct(contactStruct{Channel: "email"}))
+	assert.Nil(t, ValidateStruct(contactStruct{Phone: "+14155550123", Channel: "sms"}))
+
+	err := ValidateStruct(contactStruct{Channel: "sms"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "phone is required when channel is sms", err.(ValidationErrors)[0].Message)
+	}
+
+	err = ValidateStruct(contactStruct{Phone: "0612345678", Channel: "email"})
+	if assert.Error(t, err) {
+		assert.Equal(t, "e164", err.(ValidationErrors)[0].Tag)
+	}
+}
+
+func TestValidateTimestamp(t *testing.T) {
+	now := time.Date(2025, 9, 20, 12, 0, 0, 0, time.UTC)
+	maxAge := 7 * 24 * time.Hour
+	maxSkew := 5 * time.Minute
+
+	tests := []struct {
+		name            string
+		timestamp       time.Time
+		maxAge          time.Duration
+		maxSkew         time.Duration
+		allowHistorical bool
+		tag             string // Expected error tag, empty when valid
+	}{
+		{"current", now, maxAge, maxSkew, false, ""},
+		{"within skew", now.Add(4 * time.Minute), maxAge, maxSkew, false, ""},
+		{"future", now.Add(time.Hour), maxAge, maxSkew, false, "max_skew"},
+		{"future historical import", now.Add(time.Hour), maxAge, maxSkew, true, "max_skew"},
+		{"within age", now.Add(-6 * 24 * time.Hour), maxAge, maxSkew, false, ""},
+		{"epoch", time.Unix(1, 0), maxAge, maxSkew, false, "max_age"},
+		{"historical import", now.Add(-365 * 24 * time.Hour), maxAge, maxSkew, true, ""},
+		{"checks disabled", time.Unix(1, 0), 0, 0, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTimestamp(tt.timestamp, now, tt.maxAge, tt.maxSkew, tt.allowHistorical)
+			if tt.tag == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				errs := err.(ValidationErrors)
+				assert.Equal(t, "timestamp", errs[0].Field)
+				assert.Equal(t, tt.tag, errs[0].Tag)
+			}
+		})
+	}
+}
+
 func TestGetValidator(t *testing.T) {
 	v := GetValidator()
 	assert.NotNil(t, v)
@@ -164,11 +246,21 @@ func TestGetErrorMessage(t *testing.T) {
 			fe:       MockFieldError{field: "SName", tag: "session_name"},
 			expected: "sname must be a valid session name (alphanumeric, hyphens, underscores)",
 		},
+		{
+			name:     "Slug tag",
+			fe:       MockFieldError{field: "Slug", tag: "slug"},
+			expected: "slug must be a valid slug (alphanumeric, hyphens, underscores)",
+		},
 		{
 			name:     "Username tag",
 			fe:       MockFieldError{field: "UName", tag: "username"},
 			expected: "uname must be a valid username (alphanumeric, hyphens, underscores)",
 		},
+		{
+			name:     "Activity tag",
+			fe:       MockFieldError{field: "Activity", tag: "activity"},
+			expected: "activity must be one of: walking, running, hiking, cycling, skiing, paddling, driving, flying, other",
+		},
 		{
 			name:     "Gte tag",
 			fe:       MockFieldError{field: "Age", tag: "gte", param: "18"},
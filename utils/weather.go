@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"vibe-tracker/models"
+)
+
+// HourlyForecast is the hourly forecast of one place from an Open-Meteo response
+type HourlyForecast struct {
+	Time                     []int64    `json:"time"` // Unix timestamps, requested with timeformat=unixtime
+	Temperature              []*float64 `json:"temperature_2m"`
+	PrecipitationProbability []*float64 `json:"precipitation_probability"`
+	Precipitation            []*float64 `json:"precipitation"`
+	WindSpeed                []*float64 `json:"wind_speed_10m"`
+	WindDirection            []*float64 `json:"wind_direction_10m"`
+	WeatherCode              []*float64 `json:"weather_code"`
+}
+
+// ParseTimeParam parses a time given as RFC 3339 or as a Unix timestamp in seconds
+func ParseTimeParam(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("time must be RFC 3339 or a Unix timestamp")
+	}
+	return parsed, nil
+}
+
+// SampleRoute picks points along a route (ordered along it) about spacing meters apart, always
+// including the start and the end. When that would give more than maxSamples points the spacing
+// is widened to the route length divided evenly.
+func SampleRoute(points []TimedPoint, spacing float64, maxSamples int) []models.RouteWeatherSample {
+	samples := []models.RouteWeatherSample{}
+	if len(points) == 0 {
+		return samples
+	}
+
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += HaversineDistance(points[i-1].Latitude, points[i-1].Longitude, points[i].Latitude, points[i].Longitude)
+	}
+	if maxSamples > 1 && total/spacing > float64(maxSamples-1) {
+		spacing = total / float64(maxSamples-1)
+	}
+
+	samples = append(samples, models.RouteWeatherSample{Latitude: points[0].Latitude, Longitude: points[0].Longitude})
+	along, next := 0.0, spacing
+	for i := 1; i < len(points); i++ {
+		along += HaversineDistance(points[i-1].Latitude, points[i-1].Longitude, points[i].Latitude, points[i].Longitude)
+		// The end is added below, a sample just before it would be redundant
+		if along >= next && total-along >= spacing/2 {
+			samples = append(samples, models.RouteWeatherSample{Distance: along, Latitude: points[i].Latitude, Longitude: points[i].Longitude})
+			next = along + spacing
+		}
+	}
+
+	if len(points) > 1 {
+		last := points[len(points)-1]
+		samples = append(samples, models.RouteWeatherSample{Distance: total, Latitude: last.Latitude, Longitude: last.Longitude})
+	}
+	return samples
+}
+
+// ParseOpenMeteoForecasts extracts the hourly forecasts from an Open-Meteo forecast response,
+// which is a list for several places and a single object for one
+func ParseOpenMeteoForecasts(body []byte) ([]HourlyForecast, error) {
+	type place struct {
+		Hourly HourlyForecast `json:"hourly"`
+	}
+
+	var places []place
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		var single place
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return nil, fmt.Errorf("invalid forecast response: %w", err)
+		}
+		places = []place{single}
+	} else if err := json.Unmarshal(trimmed, &places); err != nil {
+		return nil, fmt.Errorf("invalid forecast response: %w", err)
+	}
+
+	forecasts := make([]HourlyForecast, len(places))
+	for i, p := range places {
+		forecasts[i] = p.Hourly
+	}
+	return forecasts, nil
+}
+
+// At returns the forecast of the hour closest to t, or nil when t is more than an hour away from
+// every forecast hour
+func (f HourlyForecast) At(t time.Time) *models.WeatherForecast {
+	best := -1
+	for i, hour := range f.Time {
+		if best < 0 || math.Abs(float64(hour-t.Unix())) < math.Abs(float64(f.Time[best]-t.Unix())) {
+			best = i
+		}
+	}
+	if best < 0 || math.Abs(float64(f.Time[best]-t.Unix())) > time.Hour.Seconds() {
+		return nil
+	}
+
+	forecast := &models.WeatherForecast{
+		Time:                     time.Unix(f.Time[best], 0).UTC(),
+		Temperature:              hourlyValue(f.Temperature, best),
+		PrecipitationProbability: hourlyValue(f.PrecipitationProbability, best),
+		Precipitation:            hourlyValue(f.Precipitation, best),
+		WindSpeed:                hourlyValue(f.WindSpeed, best),
+		WindDirection:            hourlyValue(f.WindDirection, best),
+	}
+	if code := hourlyValue(f.WeatherCode, best); code != nil {
+		weatherCode := int(*code)
+		forecast.WeatherCode = &weatherCode
+	}
+	return forecast
+}
+
+func hourlyValue(values []*float64, i int) *float64 {
+	if i >= len(values) {
+		return nil
+	}
+	return values[i]
+}
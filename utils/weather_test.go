@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeParam(t *testing.T) {
+	parsed, err := ParseTimeParam("1758000000")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1758000000), parsed.Unix())
+
+	parsed, err = ParseTimeParam("2025-09-16T07:30:00+02:00")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 9, 16, 5, 30, 0, 0, time.UTC), parsed.UTC())
+
+	_, err = ParseTimeParam("tomorrow")
+	assert.Error(t, err)
+}
+
+func TestSampleRoute(t *testing.T) {
+	// About 11.1 km between points, 44.5 km in total
+	points := []TimedPoint{
+		{Latitude: 47.0, Longitude: 19}, {Latitude: 47.1, Longitude: 19}, {Latitude: 47.2, Longitude: 19},
+		{Latitude: 47.3, Longitude: 19}, {Latitude: 47.4, Longitude: 19},
+	}
+
+	t.Run("samples every spacing with start and end", func(t *testing.T) {
+		samples := SampleRoute(points, 10000, 25)
+		if assert.Len(t, samples, 5) {
+			assert.Equal(t, 0.0, samples[0].Distance)
+			assert.InDelta(t, 11120, samples[1].Distance, 10)
+			assert.InDelta(t, 44478, samples[4].Distance, 10)
+			assert.Equal(t, 47.4, samples[4].Latitude)
+		}
+	})
+
+	t.Run("widens spacing for long routes", func(t *testing.T) {
+		samples := SampleRoute(points, 1000, 3)
+		if assert.Len(t, samples, 3) {
+			assert.Equal(t, 47.2, samples[1].Latitude)
+		}
+	})
+
+	t.Run("no route", func(t *testing.T) {
+		assert.Empty(t, SampleRoute(nil, 10000, 25))
+	})
+}
+
+func TestParseOpenMeteoForecasts(t *testing.T) {
+	hourly := `{"hourly":{"time":[1758000000,1758003600],"temperature_2m":[14.2,15.1],"precipitation_probability":[10,null],` +
+		`"precipitation":[0,0.4],"wind_speed_10m":[3.1,4.2],"wind_direction_10m":[270,280],"weather_code":[1,61]}}`
+
+	forecasts, err := ParseOpenMeteoForecasts([]byte(`[` + hourly + `,` + hourly + `]`))
+	assert.NoError(t, err)
+	assert.Len(t, forecasts, 2)
+
+	forecasts, err = ParseOpenMeteoForecasts([]byte(hourly))
+	assert.NoError(t, err)
+	if assert.Len(t, forecasts, 1) {
+		forecast := forecasts[0].At(time.Unix(1758003000, 0))
+		if assert.NotNil(t, forecast) {
+			assert.Equal(t, int64(1758003600), forecast.Time.Unix())
+			assert.Equal(t, 15.1, *forecast.Temperature)
+			assert.Nil(t, forecast.PrecipitationProbability)
+			assert.Equal(t, 61, *forecast.WeatherCode)
+		}
+
+		assert.Nil(t, forecasts[0].At(time.Unix(1758000000-7200, 0)))
+	}
+
+	_, err = ParseOpenMeteoForecasts([]byte(`{"error":true`))
+	assert.Error(t, err)
+}
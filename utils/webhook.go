@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// SignWebhookPayload returns the signature header value for a webhook body, "sha256=<hex HMAC>",
+// which receivers recompute with the shared secret to verify the sender
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vibe-tracker/models"
+)
+
+// WebhookMappingFields are the point fields a webhook mapping can fill, named like the
+// GET /api/track query parameters
+var WebhookMappingFields = []string{
+	"latitude", "longitude", "timestamp", "altitude", "speed", "speed_unit", "accuracy",
+	"vertical_accuracy", "bearing", "device", "battery", "heart_rate", "cadence", "power", "temperature",
+	"session", "status", "event",
+}
+
+// ValidateWebhookMapping checks the field mapping of a webhook source. Mapped values are
+// JSONPath-like paths starting with "$" (see LookupJSONPath) or constants used as they are.
+func ValidateWebhookMapping(mapping map[string]string) error {
+	var errs ValidationErrors
+	for _, field := range []string{"latitude", "longitude"} {
+		if mapping[field] == "" {
+			errs = append(errs, ValidationError{Field: field, Tag: "required", Message: field + " must be mapped"})
+		}
+	}
+
+	fields := make([]string, 0, len(mapping))
+	for field := range mapping {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		template := mapping[field]
+		if !isWebhookMappingField(field) {
+			errs = append(errs, ValidationError{Field: field, Tag: "field", Value: template,
+				Message: fmt.Sprintf("%s is not a point field, expected one of %s", field, strings.Join(WebhookMappingFields, ", "))})
+			continue
+		}
+		if strings.HasPrefix(template, "$") {
+			if _, err := parseJSONPath(template); err != nil {
+				errs = append(errs, ValidationError{Field: field, Tag: "path", Value: template, Message: err.Error()})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func isWebhookMappingField(field string) bool {
+	for _, name := range WebhookMappingFields {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// MapWebhookLocation builds the tracked point of a webhook body from the source's field mapping.
+// Numbers may be sent as JSON numbers or strings; timestamps as Unix seconds or milliseconds or as
+// RFC 3339 strings. Fields whose path does not exist in the body are left unset.
+func MapWebhookLocation(body []byte, mapping map[string]string) (*models.TrackingQueryParams, error) {
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %w", err)
+	}
+
+	params := &models.TrackingQueryParams{}
+	var errs ValidationErrors
+	for field, template := range mapping {
+		value, found, err := webhookValue(document, template)
+		if err != nil {
+			errs = append(errs, ValidationError{Field: field, Tag: "path", Value: template, Message: err.Error()})
+			continue
+		}
+		if !found {
+			if field == "latitude" || field == "longitude" {
+				errs = append(errs, ValidationError{Field: field, Tag: "required", Value: template,
+					Message: fmt.Sprintf("%s not found at %s", field, template)})
+			}
+			continue
+		}
+
+		if err := setWebhookField(params, field, value); err != nil {
+			errs = append(errs, ValidationError{Field: field, Tag: "type", Value: fmt.Sprint(value), Message: err.Error()})
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+		return nil, errs
+	}
+	return params, nil
+}
+
+// webhookValue resolves a mapping template against the body; constants are always found
+func webhookValue(document any, template string) (any, bool, error) {
+	if !strings.HasPrefix(template, "$") {
+		return template, true, nil
+	}
+	value, found, err := LookupJSONPath(document, template)
+	if value == nil {
+		found = false // JSON null is treated as missing
+	}
+	return value, found, err
+}
+
+func setWebhookField(params *models.TrackingQueryParams, field string, value any) error {
+	switch field {
+	case "latitude", "longitude", "altitude", "speed", "accuracy", "vertical_accuracy", "bearing", "battery", "heart_rate",
+		"cadence", "power", "temperature":
+		number, err := webhookNumber(value)
+		if err != nil {
+			return err
+		}
+		switch field {
+		case "latitude":
+			params.Latitude = number
+		case "longitude":
+			params.Longitude = number
+		case "altitude":
+			params.Altitude = &number
+		case "speed":
+			params.Speed = &number
+		case "accuracy":
+			params.Accuracy = &number
+		case "vertical_accuracy":
+			params.VerticalAccuracy = &number
+		case "bearing":
+			params.Bearing = &number
+		case "battery":
+			params.Battery = &number
+		case "heart_rate":
+			params.HeartRate = &number
+		case "cadence":
+			params.Cadence = &number
+		case "power":
+			params.Power = &number
+		case "temperature":
+			params.Temperature = &number
+		}
+	case "timestamp":
+		timestamp, err := webhookTimestamp(value)
+		if err != nil {
+			return err
+		}
+		params.Timestamp = timestamp
+	default:
+		text, ok := value.(string)
+		if !ok {
+			text = fmt.Sprint(value)
+		}
+		switch field {
+		case "speed_unit":
+			params.SpeedUnit = text
+		case "device":
+			params.Device = text
+		case "session":
+			params.Session = text
+		case "status":
+			params.Status = text
+		case "event":
+			params.Event = text
+		}
+	}
+	return nil
+}
+
+func webhookNumber(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return 0, fmt.Errorf("%q is not a number", v)
+		}
+		return number, nil
+	default:
+		return 0, fmt.Errorf("expected a number, got %T", value)
+	}
+}
+
+func webhookTimestamp(value any) (int64, error) {
+	if text, ok := value.(string); ok {
+		if timestamp, err := time.Parse(time.RFC3339, strings.TrimSpace(text)); err == nil {
+			return timestamp.Unix(), nil
+		}
+	}
+
+	number, err := webhookNumber(value)
+	if err != nil {
+		return 0, fmt.Errorf("expected Unix time or RFC 3339, got %v", value)
+	}
+	if number > 1e11 {
+		number /= 1000 // Milliseconds
+	}
+	return int64(number), nil
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupJSONPath(t *testing.T) {
+	document := map[string]any{
+		"attributes": map[string]any{"latitude": 47.5, "gps accuracy": 12.0},
+		"points":     []any{map[string]any{"lat": 1.0}},
+	}
+
+	value, found, err := LookupJSONPath(document, "$.attributes.latitude")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 47.5, value)
+
+	value, found, _ = LookupJSONPath(document, "$.attributes['gps accuracy']")
+	assert.True(t, found)
+	assert.Equal(t, 12.0, value)
+
+	value, found, _ = LookupJSONPath(document, "$.points[0].lat")
+	assert.True(t, found)
+	assert.Equal(t, 1.0, value)
+
+	_, found, err = LookupJSONPath(document, "$.points[1].lat")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, found, _ = LookupJSONPath(document, "$.attributes[0]")
+	assert.False(t, found)
+
+	for _, path := range []string{"attributes.latitude", "$.", "$.points[", "$.points[-1]", "$x"} {
+		_, _, err = LookupJSONPath(document, path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestValidateWebhookMapping(t *testing.T) {
+	assert.NoError(t, ValidateWebhookMapping(map[string]string{
+		"latitude":  "$.lat",
+		"longitude": "$.lon",
+		"device":    "ifttt",
+	}))
+
+	err := ValidateWebhookMapping(map[string]string{"latitude": "$.lat[", "color": "$.color"})
+	if assert.IsType(t, ValidationErrors{}, err) {
+		errs := err.(ValidationErrors)
+		assert.Len(t, errs, 3)
+		assert.Equal(t, "longitude", errs[0].Field)
+		assert.Equal(t, "color", errs[1].Field)
+		assert.Equal(t, "latitude", errs[2].Field)
+	}
+}
+
+func TestMapWebhookLocation(t *testing.T) {
+	mapping := map[string]string{
+		"latitude":  "$.attributes.latitude",
+		"longitude": "$.attributes.longitude",
+		"accuracy":  "$.attributes.gps_accuracy",
+		"bearing":   "$.attributes.course",
+		"battery":   "$.attributes.battery_level",
+		"timestamp": "$.last_updated",
+		"device":    "home-assistant",
+		"status":    "$.state",
+	}
+	body := `{"state":"not_home","last_updated":"2025-09-10T12:00:00+00:00",
+		"attributes":{"latitude":"47.4979","longitude":19.0402,"gps_accuracy":15,"course":210,"battery_level":null}}`
+
+	params, err := MapWebhookLocation([]byte(body), mapping)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 47.4979, params.Latitude)
+		assert.Equal(t, 19.0402, params.Longitude)
+		assert.Equal(t, 15.0, *params.Accuracy)
+		assert.Equal(t, 210.0, *params.Bearing)
+		assert.Nil(t, params.Battery)
+		assert.Equal(t, int64(1757505600), params.Timestamp)
+		assert.Equal(t, "home-assistant", params.Device)
+		assert.Equal(t, "not_home", params.Status)
+	}
+
+	params, err = MapWebhookLocation([]byte(`{"lat":1,"lon":2,"time":1757505600000}`),
+		map[string]string{"latitude": "$.lat", "longitude": "$.lon", "timestamp": "$.time"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(1757505600), params.Timestamp)
+	}
+
+	params, err = MapWebhookLocation([]byte(`{"lat":1,"lon":2,"bike":{"watts":250,"temp":0}}`),
+		map[string]string{"latitude": "$.lat", "longitude": "$.lon", "power": "$.bike.watts", "temperature": "$.bike.temp"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 250.0, *params.Power)
+		assert.Equal(t, 0.0, *params.Temperature)
+		assert.Nil(t, params.Cadence)
+	}
+
+	_, err = MapWebhookLocation([]byte(`{"lat":"north"}`), map[string]string{"latitude": "$.lat", "longitude": "$.lon"})
+	if assert.IsType(t, ValidationErrors{}, err) {
+		assert.Len(t, err.(ValidationErrors), 2)
+	}
+
+	_, err = MapWebhookLocation([]byte(`not json`), mapping)
+	assert.Error(t, err)
+}
@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"event":"session.ended"}`)
+
+	signature := SignWebhookPayload("secret", body)
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	assert.Equal(t, signature, SignWebhookPayload("secret", body))
+	assert.NotEqual(t, signature, SignWebhookPayload("other", body))
+	assert.NotEqual(t, signature, SignWebhookPayload("secret", []byte(`{}`)))
+}
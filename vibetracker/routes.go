@@ -0,0 +1,304 @@
+package vibetracker
+
+import (
+	"github.com/labstack/echo/v5"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	"vibe-tracker/container"
+	"vibe-tracker/models"
+)
+
+// setupGlobalMiddleware configures global middleware in the correct order
+func setupGlobalMiddleware(router *echo.Echo, di *container.Container, cfg *config.AppConfig) {
+	router.Use(di.ErrorHandler.RequestID())
+	router.Use(di.ErrorHandler.RecoveryMiddleware())
+	if di.ErrorReporter != nil {
+		router.Use(di.ErrorReporter.Middleware())
+	}
+	if di.LatencyMonitor != nil {
+		router.Use(di.LatencyMonitor.Middleware())
+	}
+	router.Use(di.ErrorHandler.SecurityHeaders(cfg.Security.HSTSEnabled, cfg.Security.CSPEnabled))
+	router.Use(di.ErrorHandler.CORSMiddleware(cfg.Security.CORSAllowedOrigins, cfg.Security.CORSAllowAll))
+	router.Use(di.InjectMiddleware())
+
+	// Reject writes in read-only mode, after CORS so browsers can read the maintenance message
+	if di.ReadOnlyMiddleware != nil {
+		router.Use(di.ReadOnlyMiddleware.Middleware())
+	}
+
+	// 404 Protection middleware - should be early in the chain
+	if di.NotFoundProtection != nil {
+		router.Use(di.NotFoundProtection.Middleware())
+	}
+
+	if di.SecurityMiddleware != nil {
+		router.Use(di.SecurityMiddleware.RequestSizeLimit())
+		router.Use(di.SecurityMiddleware.RequestTimeout())
+		router.Use(di.SecurityMiddleware.UserAgentFilter())
+		router.Use(di.SecurityMiddleware.FileUploadSecurity())
+		if cfg.Security.EnableRequestLogs {
+			router.Use(di.SecurityMiddleware.RequestLogging())
+		}
+	}
+
+	// Revoked device sessions are rejected by PocketBase's own APIs too
+	router.Use(di.AuthMiddleware.RejectRevokedTokens())
+
+	// CAPTCHA on PocketBase's registration and password reset routes, brute force protection of its login
+	if di.AuthSecurityMiddleware != nil {
+		router.Use(di.AuthSecurityMiddleware.CaptchaProtection())
+	}
+}
+
+// setupAPIRoutes configures all API endpoints
+func setupAPIRoutes(router *echo.Echo, di *container.Container) {
+	api := router.Group(constants.APIPrefix)
+
+	// Location endpoints
+	// Guest tokens are resolved before rate limiting so guest viewers get their own limits
+	publicMiddleware := []echo.MiddlewareFunc{di.GuestMiddleware.LoadGuestToken()}
+	if di.RateLimitMiddleware != nil {
+		publicMiddleware = append(publicMiddleware, di.RateLimitMiddleware.PublicEndpoints())
+	}
+
+	api.GET(constants.EndpointLocation, di.PublicHandler.GetLocation, append(publicMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.GET(constants.EndpointBranding, di.BrandingHandler.GetBranding, publicMiddleware...)
+	api.GET(constants.EndpointCapabilities, di.DiscoveryHandler.GetCapabilities, publicMiddleware...)
+	api.GET(constants.EndpointPublicLocation, di.PublicHandler.GetPublicLocations, append(publicMiddleware, di.ValidationMiddleware.ValidateQueryParams(&models.PublicLocationsQueryParams{}))...)
+	api.GET("/session/:username/:session", di.PublicHandler.GetSessionData, append(publicMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.GET("/session/:username/:session/coloring", di.PublicHandler.GetSessionColoring, append(publicMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.GET("/session/:username/:session/stats", di.PublicHandler.GetSessionStats, append(publicMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.GET("/session/:username/:session/surface", di.PublicHandler.GetSessionSurface, append(publicMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.GET("/session/:username/:session/export", di.PublicHandler.ExportSession, append(publicMiddleware, di.UserMiddleware.LoadUserFromPath(), di.ValidationMiddleware.ValidateQueryParams(&models.ExportQueryParams{}))...)
+	api.GET("/session/:username/:session/stream", di.LiveHandler.StreamSession, append(publicMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.GET("/users/:username/badge.svg", di.PublicHandler.GetBadge, append(publicMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.POST("/session/:username/:session/guest-token", di.PublicHandler.CreateGuestToken, append(publicMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+
+	// Optional GraphQL facade of the read API, with the access rules of the public endpoints
+	if di.GraphQLHandler != nil {
+		api.GET(constants.EndpointGraphQL, di.GraphQLHandler.Query, append(publicMiddleware, di.AuthMiddleware.OptionalAuth())...)
+		api.POST(constants.EndpointGraphQL, di.GraphQLHandler.Query, append(publicMiddleware, di.AuthMiddleware.OptionalAuth())...)
+	}
+
+	// Session management endpoints
+	sessionMiddleware := []echo.MiddlewareFunc{di.GuestMiddleware.LoadGuestToken()}
+	if di.RateLimitMiddleware != nil {
+		sessionMiddleware = append(sessionMiddleware, di.RateLimitMiddleware.SessionEndpoints())
+	}
+
+	api.GET("/sessions/:username", di.SessionHandler.ListSessions, append(sessionMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.GET("/sessions/:username/:name", di.SessionHandler.GetSession, append(sessionMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.POST("/sessions", di.SessionHandler.CreateSession, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermSessionsWrite), di.ValidationMiddleware.ValidateJSON(&models.CreateSessionRequest{}))...)
+	api.PUT("/sessions/:username/:name", di.SessionHandler.UpdateSession, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite), di.ValidationMiddleware.ValidateJSON(&models.UpdateSessionRequest{}))...)
+	api.POST("/sessions/:username/:name/rename", di.SessionHandler.RenameSession, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite), di.ValidationMiddleware.ValidateJSON(&models.RenameSessionRequest{}))...)
+	api.POST("/sessions/:username/:name/publish", di.SessionHandler.PublishSession, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite))...)
+	api.DELETE("/sessions/:username/:name", di.SessionHandler.DeleteSession, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite))...)
+
+	// GPX track endpoints
+	api.POST("/sessions/:username/:name/gpx", di.SessionHandler.UploadGPXTrack, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite), di.ValidationMiddleware.ValidateMultipart(&models.UploadGPXTrackRequest{}))...)
+	api.POST("/sessions/:username/:name/gpx/reprocess", di.SessionHandler.ReprocessGPXTrack, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite))...)
+	api.GET("/sessions/:username/:name/gpx", di.SessionHandler.DownloadGPXTrack, append(sessionMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.PUT("/sessions/:username/:name/cover", di.SessionHandler.UploadSessionCover, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite), di.ValidationMiddleware.ValidateMultipart(&models.UploadSessionCoverRequest{}))...)
+	api.DELETE("/sessions/:username/:name/cover", di.SessionHandler.DeleteSessionCover, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite))...)
+	api.GET("/sessions/:username/:name/media", di.SessionHandler.ListSessionMedia, append(sessionMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.POST("/sessions/:username/:name/media", di.SessionHandler.UploadSessionMedia, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite), di.ValidationMiddleware.ValidateMultipart(&models.UploadSessionMediaRequest{}))...)
+	api.DELETE("/sessions/:username/:name/media/:id", di.SessionHandler.DeleteSessionMedia, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite))...)
+	api.GET("/sessions/:username/:name/track", di.SessionHandler.GetTrackData, append(sessionMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.GET("/sessions/:username/:name/weather", di.WeatherHandler.GetRouteWeather, append(sessionMiddleware, di.UserMiddleware.LoadUserFromPath(), di.ValidationMiddleware.ValidateQueryParams(&models.RouteWeatherQueryParams{}))...)
+	api.GET("/sessions/:username/:name/progress", di.SessionHandler.GetSessionProgress, append(sessionMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.POST("/sessions/:username/:name/snapshot", di.SessionHandler.CreateSessionSnapshot, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite))...)
+	api.POST(constants.EndpointSessionGeotag, di.SessionHandler.GeotagPhoto, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.LoadUserFromPath(), di.ValidationMiddleware.ValidateMultipart(&models.GeotagPhotoRequest{}))...)
+	api.POST("/sessions/:username/:name/map-match", di.SessionHandler.MapMatchSession, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite))...)
+	api.GET("/sessions/:username/:name/replay", di.SessionHandler.GetSessionReplay, append(sessionMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.GET("/sessions/:username/:name/checkin", di.SessionHandler.GetCheckIn, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite))...)
+	api.PUT("/sessions/:username/:name/checkin", di.SessionHandler.ArmCheckIn, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite), di.ValidationMiddleware.ValidateJSON(&models.ArmCheckInRequest{}))...)
+	api.DELETE("/sessions/:username/:name/checkin", di.SessionHandler.DisarmCheckIn, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite))...)
+	api.GET("/sessions/:username/:name/viewers", di.LiveHandler.GetViewerCount, append(sessionMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.RequireUserPermission(constants.PermSessionsWrite))...)
+
+	// Waypoint endpoints
+	var waypointMiddleware []echo.MiddlewareFunc
+	if di.RateLimitMiddleware != nil {
+		waypointMiddleware = append(waypointMiddleware, di.RateLimitMiddleware.SessionEndpoints()) // Use same rate limiting as sessions
+	}
+
+	api.GET("/waypoints/:username", di.WaypointHandler.ListWaypoints, append(waypointMiddleware, di.UserMiddleware.LoadUserFromPath())...)
+	api.GET("/waypoints/by-session/:sessionId", di.WaypointHandler.ListWaypointsBySession, waypointMiddleware...)
+	api.GET("/waypoints/detail/:id", di.WaypointHandler.GetWaypoint, waypointMiddleware...)
+	api.POST("/waypoints", di.WaypointHandler.CreateWaypoint, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermWaypointsWrite), di.ValidationMiddleware.ValidateJSON(&models.CreateWaypointRequest{}), di.UserMiddleware.RequireSessionOwnershipByID(constants.PermWaypointsWrite))...)
+	api.PUT("/waypoints/reorder", di.WaypointHandler.ReorderWaypoints, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermWaypointsWrite), di.ValidationMiddleware.ValidateJSON(&models.ReorderWaypointsRequest{}), di.UserMiddleware.RequireSessionOwnershipByID(constants.PermWaypointsWrite))...)
+	api.PUT("/waypoints/:id", di.WaypointHandler.UpdateWaypoint, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermWaypointsWrite), di.ValidationMiddleware.ValidateJSON(&models.UpdateWaypointRequest{}), di.UserMiddleware.RequireWaypointOwnership())...)
+	api.PUT("/waypoints/:id/visited", di.WaypointHandler.SetWaypointVisited, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermWaypointsWrite), di.ValidationMiddleware.ValidateJSON(&models.SetWaypointVisitedRequest{}), di.UserMiddleware.RequireWaypointOwnership())...)
+	api.DELETE("/waypoints/:id", di.WaypointHandler.DeleteWaypoint, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermWaypointsWrite), di.UserMiddleware.RequireWaypointOwnership())...)
+	api.POST("/waypoints/photo", di.WaypointHandler.UploadPhotoWaypoint, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermWaypointsWrite), di.ValidationMiddleware.ValidateMultipart(&models.UploadPhotoWaypointRequest{}), di.UserMiddleware.RequireSessionOwnershipByID(constants.PermWaypointsWrite))...)
+
+	// Community waypoint layer endpoints
+	api.GET("/community/waypoints", di.CommunityHandler.ListCommunityWaypoints, publicMiddleware...)
+	api.POST("/community/waypoints", di.CommunityHandler.PublishCommunityWaypoint, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermCommunityPublish), di.ValidationMiddleware.ValidateJSON(&models.PublishCommunityWaypointRequest{}))...)
+	api.DELETE("/community/waypoints/:id", di.CommunityHandler.UnpublishCommunityWaypoint, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth())...)
+	api.POST("/community/waypoints/:id/flag", di.CommunityHandler.FlagCommunityWaypoint, append(waypointMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermCommunityPublish), di.ValidationMiddleware.ValidateJSON(&models.FlagCommunityWaypointRequest{}))...)
+
+	// Moderation endpoints
+	var moderationMiddleware []echo.MiddlewareFunc
+	if di.RateLimitMiddleware != nil {
+		moderationMiddleware = append(moderationMiddleware, di.RateLimitMiddleware.SessionEndpoints()) // Use same rate limiting as sessions
+	}
+
+	api.POST("/report", di.ModerationHandler.CreateReport, append(moderationMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermContentReport), di.ValidationMiddleware.ValidateJSON(&models.CreateReportRequest{}))...)
+	api.GET("/admin/reports", di.ModerationHandler.ListReports, append(moderationMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermReportsReview))...)
+	api.PUT("/admin/reports/:id", di.ModerationHandler.ReviewReport, append(moderationMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermReportsReview), di.ValidationMiddleware.ValidateJSON(&models.ReviewReportRequest{}))...)
+
+	// Runtime log level changes
+	api.GET("/admin/log-levels", di.LoggingHandler.GetLogLevels, append(moderationMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermSystemManage))...)
+	api.PUT("/admin/log-levels", di.LoggingHandler.UpdateLogLevel, append(moderationMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermSystemManage), di.ValidationMiddleware.ValidateJSON(&models.UpdateLogLevelRequest{}))...)
+
+	// Files left in the storage by deleted records
+	api.GET("/admin/files/orphans", di.FileHandler.ListOrphanedFiles, append(moderationMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermSystemManage))...)
+
+	// Organization endpoints; members authenticate with JWT, integrations with an organization API key
+	var orgMiddleware []echo.MiddlewareFunc
+	if di.RateLimitMiddleware != nil {
+		orgMiddleware = append(orgMiddleware, di.RateLimitMiddleware.SessionEndpoints()) // Use same rate limiting as sessions
+	}
+	orgMemberMiddleware := append(orgMiddleware, di.AuthMiddleware.OptionalAuth(), di.OrgMiddleware.RequireOrgRole(constants.OrgRoleMember))
+	orgAdminMiddleware := append(orgMiddleware, di.AuthMiddleware.OptionalAuth(), di.OrgMiddleware.RequireOrgRole(constants.OrgRoleAdmin))
+
+	api.GET("/orgs", di.OrganizationHandler.ListOrganizations, append(orgMiddleware, di.AuthMiddleware.RequireJWTAuth())...)
+	api.POST("/orgs", di.OrganizationHandler.CreateOrganization, append(orgMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermOrganizationsCreate), di.ValidationMiddleware.ValidateJSON(&models.CreateOrganizationRequest{}))...)
+	api.GET("/orgs/:org", di.OrganizationHandler.GetOrganization, orgMemberMiddleware...)
+	api.GET("/orgs/:org/members", di.OrganizationHandler.ListMembers, orgMemberMiddleware...)
+	api.POST("/orgs/:org/members", di.OrganizationHandler.AddMember, append(orgAdminMiddleware, di.ValidationMiddleware.ValidateJSON(&models.AddOrganizationMemberRequest{}))...)
+	api.PUT("/orgs/:org/members/:userId", di.OrganizationHandler.UpdateMember, append(orgAdminMiddleware, di.ValidationMiddleware.ValidateJSON(&models.UpdateOrganizationMemberRequest{}))...)
+	api.DELETE("/orgs/:org/members/:userId", di.OrganizationHandler.RemoveMember, orgAdminMiddleware...)
+	api.GET("/orgs/:org/api-keys", di.OrganizationHandler.ListAPIKeys, orgAdminMiddleware...)
+	api.POST("/orgs/:org/api-keys", di.OrganizationHandler.CreateAPIKey, append(orgAdminMiddleware, di.ValidationMiddleware.ValidateJSON(&models.CreateOrganizationAPIKeyRequest{}))...)
+	api.DELETE("/orgs/:org/api-keys/:keyId", di.OrganizationHandler.RevokeAPIKey, orgAdminMiddleware...)
+	api.GET("/orgs/:org/sessions", di.OrganizationHandler.ListOrganizationSessions, orgMemberMiddleware...)
+	api.POST("/orgs/:org/sessions", di.OrganizationHandler.CreateOrganizationSession, append(orgAdminMiddleware, di.ValidationMiddleware.ValidateJSON(&models.CreateOrganizationSessionRequest{}))...)
+
+	// Authentication endpoints
+	var authMiddleware []echo.MiddlewareFunc
+	if di.RateLimitMiddleware != nil {
+		authMiddleware = append(authMiddleware, di.RateLimitMiddleware.AuthEndpoints())
+	}
+	if di.AuthSecurityMiddleware != nil {
+		authMiddleware = append(authMiddleware, di.AuthSecurityMiddleware.BruteForceProtection())
+	}
+
+	api.POST(constants.EndpointLogin, di.AuthHandler.Login, append(authMiddleware, di.ValidationMiddleware.ValidateJSON(&models.LoginRequest{}))...)
+	api.POST("/auth/refresh", di.AuthHandler.RefreshToken, authMiddleware...)
+	captchaMiddleware := []echo.MiddlewareFunc{}
+	if di.RateLimitMiddleware != nil {
+		captchaMiddleware = append(captchaMiddleware, di.RateLimitMiddleware.PublicEndpoints())
+	}
+	api.GET("/auth/captcha", di.CaptchaHandler.GetCaptchaChallenge, captchaMiddleware...)
+	api.POST("/auth/reauth", di.AuthHandler.Reauthenticate, append(authMiddleware, di.AuthMiddleware.RequireJWTAuth(), di.ValidationMiddleware.ValidateJSON(&models.ReauthRequest{}))...)
+	api.GET("/me", di.AuthHandler.GetMe, di.AuthMiddleware.RequireJWTAuth())
+
+	// Sensitive profile actions require re-authentication for a while after a suspicious login
+	recentAuth := di.AuthMiddleware.RequireRecentAuth(di.Config.Security.SuspiciousLoginReauthWindow)
+	api.PUT("/profile", di.AuthHandler.UpdateProfile, di.AuthMiddleware.RequireJWTAuth(), recentAuth, di.ValidationMiddleware.ValidateJSON(&models.UpdateProfileRequest{}))
+	api.POST("/profile/avatar", di.AuthHandler.UploadAvatar, di.AuthMiddleware.RequireJWTAuth(), di.ValidationMiddleware.ValidateMultipart(&models.UploadAvatarRequest{}))
+	api.PUT("/profile/regenerate-token", di.AuthHandler.RegenerateToken, di.AuthMiddleware.RequireJWTAuth(), recentAuth)
+	api.GET("/profile/sessions", di.AuthHandler.ListDeviceSessions, di.AuthMiddleware.RequireJWTAuth())
+	api.DELETE("/profile/sessions", di.AuthHandler.RevokeOtherDeviceSessions, di.AuthMiddleware.RequireJWTAuth(), recentAuth)
+	api.DELETE("/profile/sessions/:id", di.AuthHandler.RevokeDeviceSession, di.AuthMiddleware.RequireJWTAuth())
+	api.GET("/profile/tracking-defaults", di.AuthHandler.GetTrackingDefaults, di.AuthMiddleware.RequireJWTAuth())
+	api.PUT("/profile/tracking-defaults", di.AuthHandler.UpdateTrackingDefaults, di.AuthMiddleware.RequireJWTAuth(), di.ValidationMiddleware.ValidateJSON(&models.UpdateTrackingDefaultsRequest{}))
+	api.GET("/profile/emergency-contacts", di.EmergencyContactHandler.ListEmergencyContacts, di.AuthMiddleware.RequireJWTAuth())
+	api.POST("/profile/emergency-contacts", di.EmergencyContactHandler.CreateEmergencyContact, di.AuthMiddleware.RequireJWTAuth(), recentAuth, di.ValidationMiddleware.ValidateJSON(&models.EmergencyContactRequest{}))
+	api.PUT("/profile/emergency-contacts/:id", di.EmergencyContactHandler.UpdateEmergencyContact, di.AuthMiddleware.RequireJWTAuth(), recentAuth, di.ValidationMiddleware.ValidateJSON(&models.EmergencyContactRequest{}))
+	api.DELETE("/profile/emergency-contacts/:id", di.EmergencyContactHandler.DeleteEmergencyContact, di.AuthMiddleware.RequireJWTAuth())
+	api.POST("/profile/emergency-contacts/:id/verify", di.EmergencyContactHandler.ResendEmergencyContactVerification, di.AuthMiddleware.RequireJWTAuth())
+	// Contacts confirm their email address from the verification email without an account
+	api.GET("/emergency-contacts/verify/:token", di.EmergencyContactHandler.VerifyEmergencyContact, publicMiddleware...)
+	api.GET("/profile/eta-shares", di.ETAShareHandler.ListETAShares, di.AuthMiddleware.RequireJWTAuth())
+	api.POST("/profile/eta-shares", di.ETAShareHandler.CreateETAShare, di.AuthMiddleware.RequireJWTAuth(), di.ValidationMiddleware.ValidateJSON(&models.CreateETAShareRequest{}))
+	api.DELETE("/profile/eta-shares/:id", di.ETAShareHandler.DeleteETAShare, di.AuthMiddleware.RequireJWTAuth())
+	api.GET("/profile/ingest-sources", di.IngestSourceHandler.ListIngestSources, di.AuthMiddleware.RequireJWTAuth())
+	api.POST("/profile/ingest-sources", di.IngestSourceHandler.CreateIngestSource, di.AuthMiddleware.RequireJWTAuth(), di.ValidationMiddleware.ValidateJSON(&models.IngestSourceRequest{}))
+	api.PUT("/profile/ingest-sources/:id", di.IngestSourceHandler.UpdateIngestSource, di.AuthMiddleware.RequireJWTAuth(), di.ValidationMiddleware.ValidateJSON(&models.IngestSourceRequest{}))
+	api.DELETE("/profile/ingest-sources/:id", di.IngestSourceHandler.DeleteIngestSource, di.AuthMiddleware.RequireJWTAuth())
+	api.GET("/eta/:token", di.ETAShareHandler.GetETAShare, publicMiddleware...)
+	api.GET(constants.SignedFilesPath+"/:collection/:record/:filename", di.FileHandler.ServeSignedFile, publicMiddleware...)
+	api.GET("/users/:username/timeline", di.TimelineHandler.GetTimeline, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.LoadUserFromPath())
+	api.GET("/users/:username/position-at", di.TimelineHandler.GetPositionAt, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.LoadUserFromPath(), di.ValidationMiddleware.ValidateQueryParams(&models.PositionAtQueryParams{}))
+	api.GET("/users/:username/countries", di.CountryHandler.GetCountries, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.LoadUserFromPath())
+	api.GET("/users/:username/countries/geojson", di.CountryHandler.GetCountryLayer, di.AuthMiddleware.RequireJWTAuth(), di.UserMiddleware.LoadUserFromPath())
+	api.GET("/route", di.RouteHandler.PlanRoute, di.AuthMiddleware.RequireJWTAuth(), di.ValidationMiddleware.ValidateQueryParams(&models.RouteQueryParams{}))
+	api.PUT("/users/:username/role", di.AuthHandler.UpdateUserRole, di.AuthMiddleware.RequireJWTAuth(), di.AuthMiddleware.RequirePermission(constants.PermUsersManage), di.UserMiddleware.LoadUserFromPath(), di.ValidationMiddleware.ValidateJSON(&models.UpdateUserRoleRequest{}))
+
+	// Tracking endpoints
+	var trackingMiddleware []echo.MiddlewareFunc
+	if di.RateLimitMiddleware != nil {
+		trackingMiddleware = append(trackingMiddleware, di.RateLimitMiddleware.TrackingEndpoints())
+	}
+
+	api.GET(constants.EndpointTrack, di.TrackingHandler.TrackLocationGET, append(trackingMiddleware, di.AuthMiddleware.RequireFlexibleAuth(), di.AuthMiddleware.RequirePermission(constants.PermTrackingWrite), di.ValidationMiddleware.ValidateQueryParams(&models.TrackingQueryParams{}))...)
+	// Clients on metered connections may gzip the body, it is inflated before validation
+	api.POST(constants.EndpointTrack, di.TrackingHandler.TrackLocationPOST, append(trackingMiddleware, di.AuthMiddleware.RequireFlexibleAuth(), di.AuthMiddleware.RequirePermission(constants.PermTrackingWrite), di.SecurityMiddleware.DecompressRequest(), di.ValidationMiddleware.ValidateLocation())...)
+	// Streams points over one connection, the handshake is rate limited like a tracking request
+	api.GET(constants.EndpointTrackWebSocket, di.TrackingHandler.TrackWebSocket, append(trackingMiddleware, di.AuthMiddleware.RequireFlexibleAuth(), di.AuthMiddleware.RequirePermission(constants.PermTrackingWrite))...)
+	api.POST(constants.EndpointHomeAssistantWebhook, di.TrackingHandler.TrackHomeAssistantLocation, append(trackingMiddleware, di.AuthMiddleware.RequireFlexibleAuth(), di.AuthMiddleware.RequirePermission(constants.PermTrackingWrite), di.ValidationMiddleware.ValidateJSON(&models.HomeAssistantWebhookRequest{}))...)
+	api.POST(constants.EndpointOwnTracks, di.TrackingHandler.TrackOwnTracksLocation, append(trackingMiddleware, di.AuthMiddleware.RequireFlexibleAuth(), di.AuthMiddleware.RequirePermission(constants.PermTrackingWrite), di.ValidationMiddleware.ValidateJSON(&models.OwnTracksMessage{}))...)
+	// osmAnd/Traccar clients send the same query parameters with GET or POST
+	api.GET(constants.EndpointOsmAnd, di.TrackingHandler.TrackOsmAndLocation, append(trackingMiddleware, di.AuthMiddleware.RequireDeviceIDAuth(), di.AuthMiddleware.RequirePermission(constants.PermTrackingWrite), di.ValidationMiddleware.ValidateQueryParams(&models.OsmAndQueryParams{}))...)
+	api.POST(constants.EndpointOsmAnd, di.TrackingHandler.TrackOsmAndLocation, append(trackingMiddleware, di.AuthMiddleware.RequireDeviceIDAuth(), di.AuthMiddleware.RequirePermission(constants.PermTrackingWrite), di.ValidationMiddleware.ValidateQueryParams(&models.OsmAndQueryParams{}))...)
+	// Third-party services authenticate with the secret of their ingest source
+	api.POST("/ingest/webhook/:source_id", di.IngestSourceHandler.ReceiveWebhook, trackingMiddleware...)
+}
+
+// setupDocumentationRoutes configures API documentation endpoints
+func setupDocumentationRoutes(router *echo.Echo, di *container.Container) {
+	var docsMiddleware []echo.MiddlewareFunc
+	if di.RateLimitMiddleware != nil {
+		docsMiddleware = append(docsMiddleware, di.RateLimitMiddleware.DocsEndpoints())
+	}
+
+	router.GET("/swagger/json", di.DocsHandler.ServeSwaggerJSON, docsMiddleware...)
+	router.GET("/swagger", di.DocsHandler.ServeSwaggerUI, docsMiddleware...)
+}
+
+// setupWellKnownRoutes configures the discovery documents clients fetch from the server root
+func setupWellKnownRoutes(router *echo.Echo, di *container.Container) {
+	var wellKnownMiddleware []echo.MiddlewareFunc
+	if di.RateLimitMiddleware != nil {
+		wellKnownMiddleware = append(wellKnownMiddleware, di.RateLimitMiddleware.PublicEndpoints())
+	}
+
+	router.GET(constants.WellKnownClientConfig, di.DiscoveryHandler.GetClientConfig, wellKnownMiddleware...)
+}
+
+// setupHealthRoutes configures health check endpoints
+func setupHealthRoutes(router *echo.Echo, di *container.Container, cfg *config.AppConfig) {
+	if cfg.Health.Enabled {
+		router.GET(constants.HealthLivenessEndpoint, di.HealthHandler.GetLiveness)
+		router.GET(constants.HealthReadinessEndpoint, di.HealthHandler.GetReadiness)
+		if cfg.Health.DetailedEnabled {
+			router.GET(constants.HealthEndpoint, di.HealthHandler.GetDetailedHealth)
+		}
+	}
+}
+
+// setupStaticRoutes configures frontend static file serving
+func setupStaticRoutes(router *echo.Echo) {
+	router.GET("/u/:username", func(c echo.Context) error {
+		return c.File("dist/index.html")
+	})
+
+	router.GET("/u/:username/s/:session", func(c echo.Context) error {
+		return c.File("dist/index.html")
+	})
+
+	// SPA fallback routes - serve index.html for frontend routes
+	router.GET("/profile", func(c echo.Context) error {
+		return c.File("dist/index.html")
+	})
+
+	router.GET("/profile/sessions", func(c echo.Context) error {
+		return c.File("dist/index.html")
+	})
+
+	router.Static("/", "dist")
+}
@@ -0,0 +1,189 @@
+// Package vibetracker wires the tracker into a PocketBase app. It is used by the vibe-tracker
+// binary and lets other Go programs embed the tracker into their own PocketBase project:
+//
+//	app := pocketbase.New()
+//	vibetracker.New(config.NewAppConfig()).WithoutFrontend().Attach(app)
+//	// custom collections, hooks and routes of the host project
+//	if err := app.Start(); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The tracker's collections are created by its migrations, which are registered with PocketBase
+// when this package is imported and run with the host project's migrations.
+package vibetracker
+
+import (
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+
+	"vibe-tracker/config"
+	"vibe-tracker/constants"
+	"vibe-tracker/container"
+	_ "vibe-tracker/docs/api"
+	_ "vibe-tracker/migrations"
+	"vibe-tracker/plugins"
+	"vibe-tracker/utils"
+)
+
+// Tracker is the tracker application, attached to a PocketBase app with Attach
+type Tracker struct {
+	config        *config.AppConfig
+	serveFrontend bool
+	container     *container.Container
+}
+
+// New creates the tracker with the given configuration, or with the configuration from the
+// environment when cfg is nil
+func New(cfg *config.AppConfig) *Tracker {
+	if cfg == nil {
+		cfg = config.NewAppConfig()
+	}
+	return &Tracker{config: cfg, serveFrontend: true}
+}
+
+// WithoutFrontend skips serving the web frontend from ./dist, for hosts serving their own
+func (t *Tracker) WithoutFrontend() *Tracker {
+	t.serveFrontend = false
+	return t
+}
+
+// Container returns the dependencies of the attached tracker, or nil before Attach
+func (t *Tracker) Container() *container.Container {
+	return t.container
+}
+
+// Attach registers the tracker's services, background jobs and routes with app. It must be
+// called before app is started, so the SQLite tuning applies to every database connection.
+func (t *Tracker) Attach(app *pocketbase.PocketBase) *Tracker {
+	cfg := t.config
+
+	// Initialize structured logger
+	utils.InitLogger(cfg)
+
+	// Tune the SQLite connections before the app opens the database
+	if err := utils.ApplySQLitePragmas(cfg.SQLite); err != nil {
+		utils.LogWarn().Err(err).Msg("Invalid SQLite tuning, using the PocketBase defaults")
+	}
+
+	// Initialize dependency injection container
+	di := container.NewContainer(app, cfg)
+	t.container = di
+
+	// Push new locations to live session streams
+	app.OnModelAfterCreate(constants.CollectionLocations).Add(di.LiveHandler.BroadcastLocation)
+
+	// End ETA shares once their owner arrives
+	app.OnModelAfterCreate(constants.CollectionLocations).Add(di.ETAShareService.CheckArrival)
+
+	// Delete older GPX uploads of deleted sessions; PocketBase deletes the files of record file fields
+	app.OnModelAfterDelete(constants.CollectionSessions).Add(di.FileCleanupService.SessionDeleted)
+
+	// Let registered plugins subscribe to the extension hooks
+	app.OnModelAfterCreate(constants.CollectionLocations).Add(di.PluginHooks.LocationCreated)
+	app.OnModelAfterCreate(constants.CollectionWaypoints).Add(di.PluginHooks.WaypointCreated)
+	app.OnBeforeServe().Add(func(e *core.ServeEvent) error {
+		return plugins.Setup(app, di.PluginHooks)
+	})
+
+	// Keep revoked device sessions rejected across restarts
+	app.OnBeforeServe().Add(di.AuthHandler.RestoreRevokedDeviceSessions)
+
+	// Bind the tokens of PocketBase's auth APIs to device sessions, so they can be revoked
+	app.OnRecordBeforeAuthRefreshRequest(constants.CollectionUsers).Add(di.AuthHandler.CheckAuthRefresh)
+	app.OnRecordAuthRequest(constants.CollectionUsers).Add(di.AuthHandler.IssueDeviceSessionToken)
+
+	// Background jobs write to the database, they are paused in read-only mode
+	if !cfg.ReadOnly {
+		// End sessions whose trackers stopped reporting
+		app.OnBeforeServe().Add(di.SessionCloserService.Start)
+		app.OnTerminate().Add(di.SessionCloserService.Stop)
+
+		// Alert owners and emergency contacts when armed sessions miss their check-in
+		app.OnBeforeServe().Add(di.CheckInService.Start)
+		app.OnTerminate().Add(di.CheckInService.Stop)
+
+		// Keep daily timelines up to date with new locations
+		app.OnBeforeServe().Add(di.TimelineService.Start)
+		app.OnTerminate().Add(di.TimelineService.Stop)
+
+		// Assign countries to new locations for the visited countries statistics
+		app.OnBeforeServe().Add(di.GeocodingService.Start)
+		app.OnTerminate().Add(di.GeocodingService.Stop)
+
+		// Match ended sessions to OSM ways for their surface statistics
+		app.OnBeforeServe().Add(di.SurfaceService.Start)
+		app.OnTerminate().Add(di.SurfaceService.Stop)
+
+		// Report, and if configured delete, stored files of deleted records
+		app.OnBeforeServe().Add(di.FileCleanupService.Start)
+		app.OnTerminate().Add(di.FileCleanupService.Stop)
+	}
+
+	// Serve the gRPC ingestion service; it is stopped before the last batch is written
+	if di.TrackingGRPCServer != nil {
+		app.OnBeforeServe().Add(di.TrackingGRPCServer.Start)
+		app.OnTerminate().Add(di.TrackingGRPCServer.Stop)
+	}
+
+	// Accept hardware trackers, which cannot be told about maintenance, only while writes are allowed
+	if di.HardwareTrackerListener != nil && !cfg.ReadOnly {
+		app.OnBeforeServe().Add(di.HardwareTrackerListener.Start)
+		app.OnTerminate().Add(di.HardwareTrackerListener.Stop)
+	}
+
+	// Follow APRS stations; beacons received in read-only mode would be lost, so it is not started
+	if di.APRSBridge != nil && !cfg.ReadOnly {
+		app.OnBeforeServe().Add(di.APRSBridge.Start)
+		app.OnTerminate().Add(di.APRSBridge.Stop)
+	}
+
+	// Import inReach positions from MapShare feeds
+	if di.MapShareImporter != nil && !cfg.ReadOnly {
+		app.OnBeforeServe().Add(di.MapShareImporter.Start)
+		app.OnTerminate().Add(di.MapShareImporter.Stop)
+	}
+
+	// Publish the latest locations to Home Assistant; it only reads, so it runs in read-only mode too
+	if di.HomeAssistantService != nil {
+		app.OnModelAfterCreate(constants.CollectionLocations).Add(di.HomeAssistantService.LocationCreated)
+		app.OnBeforeServe().Add(di.HomeAssistantService.Start)
+		app.OnTerminate().Add(di.HomeAssistantService.Stop)
+	}
+
+	// Write batched locations periodically, and the last batch on shutdown
+	if di.IngestService != nil {
+		app.OnBeforeServe().Add(di.IngestService.Start)
+		app.OnTerminate().Add(di.IngestService.Stop)
+	}
+
+	// Deliver queued error reports before exiting
+	if di.ErrorReporter != nil {
+		app.OnTerminate().Add(di.ErrorReporter.Stop)
+	}
+
+	app.OnBeforeServe().Add(func(e *core.ServeEvent) error {
+		// Apply global middleware
+		setupGlobalMiddleware(e.Router, di, cfg)
+
+		// Setup API routes
+		setupAPIRoutes(e.Router, di)
+
+		// Setup documentation routes
+		setupDocumentationRoutes(e.Router, di)
+
+		// Setup client auto-configuration
+		setupWellKnownRoutes(e.Router, di)
+
+		// Setup health check routes
+		setupHealthRoutes(e.Router, di, cfg)
+
+		// Setup static routes
+		if t.serveFrontend {
+			setupStaticRoutes(e.Router)
+		}
+
+		return nil
+	})
+
+	return t
+}